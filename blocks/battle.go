@@ -131,6 +131,12 @@ func NewBattlePlanBlock(b GenericBlock) *BattlePlanBlock {
 	return bpb
 }
 
+// Refresh re-encodes the block fields into the generic block data,
+// so that the block can be written to a file after being modified.
+func (bpb *BattlePlanBlock) Refresh() {
+	bpb.GenericBlock = newOrderBlock(BattlePlanBlockType, bpb.Encode())
+}
+
 func (bpb *BattlePlanBlock) decode() {
 	data := bpb.Decrypted
 	if len(data) < 4 {
@@ -922,6 +928,20 @@ func NewSetFleetBattlePlanBlock(b GenericBlock) *SetFleetBattlePlanBlock {
 	return sfbp
 }
 
+// NewSetFleetBattlePlanOrder creates a SetFleetBattlePlanBlock giving a
+// fleet one of its owner's battle plans.
+func NewSetFleetBattlePlanOrder(fleetNumber, planIndex int) *SetFleetBattlePlanBlock {
+	sfbp := &SetFleetBattlePlanBlock{FleetNumber: fleetNumber, BattlePlanIndex: planIndex}
+	sfbp.Refresh()
+	return sfbp
+}
+
+// Refresh re-encodes the block fields into the generic block data,
+// so that the block can be written to a file after being modified.
+func (sfbp *SetFleetBattlePlanBlock) Refresh() {
+	sfbp.GenericBlock = newOrderBlock(SetFleetBattlePlanBlockType, sfbp.Encode())
+}
+
 func (sfbp *SetFleetBattlePlanBlock) decode() {
 	data := sfbp.Decrypted
 	if len(data) < 4 {
@@ -679,6 +679,34 @@ func TestObjectBlockMinefield(t *testing.T) {
 	}
 }
 
+// TestMinefieldDetonateOrder tests building a detonation order from a minefield
+func TestMinefieldDetonateOrder(t *testing.T) {
+	blocks, _ := parseBlocksFromFileWithObjects(t, "../testdata/scenario-map/minefields/game.m1")
+
+	var minefield *ObjectBlock
+	for _, b := range blocks {
+		if ob, ok := b.(ObjectBlock); ok && ob.IsMinefield() {
+			minefield = &ob
+			break
+		}
+	}
+	require.NotNil(t, minefield)
+
+	order := NewMinefieldDetonateOrder(minefield, true)
+	assert.False(t, minefield.Detonating, "source minefield must not change")
+	assert.Equal(t, ObjectBlockType, order.BlockTypeID())
+
+	decoded := NewObjectBlock(order.GenericBlock)
+	assert.True(t, decoded.Detonating)
+	assert.Equal(t, minefield.Number, decoded.Number)
+	assert.Equal(t, minefield.Owner, decoded.Owner)
+	assert.Equal(t, minefield.MineCount, decoded.MineCount)
+
+	// Clearing the flag restores the original bytes
+	cleared := NewMinefieldDetonateOrder(decoded, false)
+	assert.Equal(t, []byte(minefield.Decrypted), []byte(cleared.Decrypted))
+}
+
 // TestObjectBlockCountObject tests count object parsing
 func TestObjectBlockCountObject(t *testing.T) {
 	blocks, _ := parseBlocksFromFileWithObjects(t, "../testdata/scenario-map/minefields/game.m1")
@@ -771,6 +799,10 @@ func TestObjectBlockTraderItems(t *testing.T) {
 	assert.True(t, ob.TraderHasItem(TraderItemGenesisDevice))
 	assert.False(t, ob.TraderHasItem(TraderItemLangstonShield))
 	assert.False(t, ob.TraderHasItem(TraderItemShip))
+
+	assert.Equal(t, "Langston Shield", TraderItemName(TraderItemLangstonShield))
+	assert.Equal(t, "Hush-a-Boom", TraderItemName(TraderItemHushABoom))
+	assert.Equal(t, "Unknown(0x4000)", TraderItemName(1<<14))
 }
 
 // TestObjectBlockMinerals tests packet mineral helpers
@@ -23,6 +23,25 @@ func NewResearchChangeBlock(b GenericBlock) *ResearchChangeBlock {
 	return rcb
 }
 
+// NewResearchChangeOrder creates a ResearchChangeBlock setting the share of
+// resources spent on research and the fields researched now and next
+// (ResearchField*, ResearchFieldSameField for the next field).
+func NewResearchChangeOrder(budgetPercent, currentField, nextField int) *ResearchChangeBlock {
+	rcb := &ResearchChangeBlock{
+		BudgetPercent: budgetPercent,
+		CurrentField:  currentField,
+		NextField:     nextField,
+	}
+	rcb.Refresh()
+	return rcb
+}
+
+// Refresh re-encodes the block fields into the generic block data,
+// so that the block can be written to a file after being modified.
+func (rcb *ResearchChangeBlock) Refresh() {
+	rcb.GenericBlock = newOrderBlock(ResearchChangeBlockType, rcb.Encode())
+}
+
 func (rcb *ResearchChangeBlock) decode() {
 	data := rcb.Decrypted
 	if len(data) < 2 {
@@ -49,8 +68,8 @@ func (rcb *ResearchChangeBlock) Encode() []byte {
 //	Bytes 0-1: Planet ID (uint16 LE)
 //	Bytes 2-3: Packed settings (uint16 LE)
 //	           Bit 0:      fNoResearch - Contribute leftover to research (1=yes)
-//	           Bits 1-10:  pctDp - Driver/packet percentage (10 bits, 0-1023)
-//	           Bits 11-14: iWarpFling - Packet fling warp speed (0-15)
+//	           Bits 1-10:  pctDp - Mass driver destination (display ID = planet ID + 1, 0 = none)
+//	           Bits 11-14: iWarpFling - Packet fling warp speed minus PacketWarpOffset (0-15)
 //	           Bit 15:     unused
 //	Bytes 4-5: Route destination (uint16 LE, left-shifted by 1)
 //	           Actual planet ID = value >> 1
@@ -60,11 +79,15 @@ type PlanetChangeBlock struct {
 
 	PlanetId            int  // Planet ID
 	ContributeLeftover  bool // "Contribute only leftover resources to research" (fNoResearch bit 0)
-	DriverPacketPercent int  // Mass driver/packet percentage (0-1023, typically 0-100)
-	PacketWarpSpeed     int  // Packet fling warp speed (0-15)
+	DriverPacketPercent int  // Mass driver destination, display ID (planet ID + 1, 0 = none); see MassDriverTarget
+	PacketWarpSpeed     int  // Raw packet fling warp (0-15); see PacketWarp
 	RouteDestinationId  int  // Route destination planet ID (0 = no route)
 }
 
+// PacketWarpOffset is added to the stored iWarpFling value to get the packet
+// warp speed. A fling setting of warp 7 is stored as 3.
+const PacketWarpOffset = 4
+
 // NewPlanetChangeBlock creates a PlanetChangeBlock from a GenericBlock
 func NewPlanetChangeBlock(b GenericBlock) *PlanetChangeBlock {
 	pcb := &PlanetChangeBlock{GenericBlock: b}
@@ -72,6 +95,20 @@ func NewPlanetChangeBlock(b GenericBlock) *PlanetChangeBlock {
 	return pcb
 }
 
+// NewMassDriverTargetOrder creates a PlanetChangeBlock that sets the mass driver
+// destination and packet warp of a planet. A negative destination clears it.
+//
+// The order also carries the planet's route and research contribution
+// settings; set RouteDestinationId and ContributeLeftover and call Refresh
+// when those must be preserved.
+func NewMassDriverTargetOrder(planetID, destinationID, warp int) *PlanetChangeBlock {
+	pcb := &PlanetChangeBlock{PlanetId: planetID}
+	pcb.SetMassDriverTarget(destinationID)
+	pcb.SetPacketWarp(warp)
+	pcb.Refresh()
+	return pcb
+}
+
 func (pcb *PlanetChangeBlock) decode() {
 	data := pcb.Decrypted
 	if len(data) < 6 {
@@ -118,6 +155,41 @@ func (pcb *PlanetChangeBlock) Encode() []byte {
 	return data
 }
 
+// Refresh re-encodes the block fields into the generic block data,
+// so that the block can be written to a file after being modified.
+func (pcb *PlanetChangeBlock) Refresh() {
+	pcb.GenericBlock = newOrderBlock(PlanetChangeBlockType, pcb.Encode())
+}
+
+// MassDriverTarget returns the planet ID packets are flung to,
+// or false if no destination is set.
+func (pcb *PlanetChangeBlock) MassDriverTarget() (int, bool) {
+	if pcb.DriverPacketPercent == 0 {
+		return 0, false
+	}
+	return pcb.DriverPacketPercent - 1, true
+}
+
+// SetMassDriverTarget sets the planet ID packets are flung to.
+// A negative ID clears the destination.
+func (pcb *PlanetChangeBlock) SetMassDriverTarget(planetID int) {
+	if planetID < 0 {
+		pcb.DriverPacketPercent = 0
+		return
+	}
+	pcb.DriverPacketPercent = planetID + 1
+}
+
+// PacketWarp returns the warp speed packets are flung at.
+func (pcb *PlanetChangeBlock) PacketWarp() int {
+	return pcb.PacketWarpSpeed + PacketWarpOffset
+}
+
+// SetPacketWarp sets the warp speed packets are flung at.
+func (pcb *PlanetChangeBlock) SetPacketWarp(warp int) {
+	pcb.PacketWarpSpeed = max(warp-PacketWarpOffset, 0)
+}
+
 // ChangePasswordBlock represents a password change request (Type 36)
 //
 // Format (4 bytes):
@@ -194,6 +194,19 @@ func (db *DesignBlock) ShipCount() int64 {
 	return db.TotalRemaining
 }
 
+// Refresh re-encodes the block fields into the generic block data and
+// decodes them back, so that the block can be written to a file after being
+// modified and its decoded fields (slot count, bug flags) match what is
+// written.
+func (db *DesignBlock) Refresh() {
+	refreshed := DesignBlock{
+		GenericBlock: newOrderBlock(DesignBlockType, db.Encode()),
+		Slots:        make([]DesignSlot, 0),
+	}
+	_ = refreshed.decode()
+	*db = refreshed
+}
+
 // Encode returns the raw block data bytes (without the 2-byte block header).
 func (db *DesignBlock) Encode() []byte {
 	// Encode the design name
@@ -289,6 +302,30 @@ type DesignChangeBlock struct {
 
 	// If IsDelete is false, the design data follows (same as DesignBlock)
 	Design *DesignBlock
+
+	// Prefix holds the first two bytes of a change, kept for re-encoding:
+	// byte 0 is 0x01 and byte 1 the design number (bits 0-3) with the
+	// starbase flag (bit 4). Stars! also sets bits 5-6, and most of the
+	// time bit 7, whose meaning is unknown.
+	Prefix [2]byte
+}
+
+// NewDesignChangeOrder creates a DesignChangeBlock saving a full design, as
+// Stars! writes it when a design is edited in the ship designer.
+func NewDesignChangeOrder(design *DesignBlock) *DesignChangeBlock {
+	prefix := byte(0xE0) | byte(design.DesignNumber&0x0F)
+	if design.IsStarbase {
+		prefix |= 0x10
+	}
+	dcb := &DesignChangeBlock{Design: design, Prefix: [2]byte{0x01, prefix}}
+	dcb.Refresh()
+	return dcb
+}
+
+// Refresh re-encodes the block fields into the generic block data,
+// so that the block can be written to a file after being modified.
+func (dcb *DesignChangeBlock) Refresh() {
+	dcb.GenericBlock = newOrderBlock(DesignChangeBlockType, dcb.Encode())
 }
 
 // NewDesignChangeBlock creates a DesignChangeBlock from a GenericBlock
@@ -320,6 +357,7 @@ func (dcb *DesignChangeBlock) decode() error {
 	if len(data) < 4 {
 		return nil
 	}
+	dcb.Prefix = [2]byte{data[0], data[1]}
 
 	// Create a modified data slice without the first 2 bytes
 	designData := make([]byte, len(data)-2)
@@ -368,9 +406,11 @@ func (dcb *DesignChangeBlock) Encode() []byte {
 	data := make([]byte, 2+len(designData))
 
 	// Prefix bytes - the first byte's low nibble is non-zero for design changes
-	// Using 0x03 as a reasonable default
-	data[0] = 0x03
-	data[1] = 0x00
+	data[0] = dcb.Prefix[0]
+	data[1] = dcb.Prefix[1]
+	if data[0]&0x0F == 0 {
+		data[0] = 0x01
+	}
 
 	copy(data[2:], designData)
 	return data
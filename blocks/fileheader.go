@@ -3,9 +3,9 @@ package blocks
 import (
 	"errors"
 	"fmt"
-	"math/rand"
 
 	"github.com/neper-stars/houston/encoding"
+	"github.com/neper-stars/houston/reproducible"
 )
 
 var ErrInvalidFileHeaderBlock = errors.New("invalid file header")
@@ -163,6 +163,25 @@ func (fh *FileHeader) Crippled() bool {
 	return (fh.Flags & FlagCrippled) != 0
 }
 
+// SharewareFlag returns the shareware value of the encryption key: 1 for a
+// file written by a shareware copy of Stars!, else 0.
+func (fh *FileHeader) SharewareFlag() int {
+	if fh.Crippled() {
+		return 1
+	}
+	return 0
+}
+
+// SetCrippled sets or clears the fCrippled flag. The blocks of the file must
+// then be encrypted again, the flag being part of the encryption key.
+func (fh *FileHeader) SetCrippled(crippled bool) {
+	if crippled {
+		fh.Flags |= FlagCrippled
+	} else {
+		fh.Flags &^= FlagCrippled
+	}
+}
+
 // Shareware is an alias for Crippled() for backward compatibility.
 //
 // Deprecated: Use Crippled() instead.
@@ -237,10 +256,10 @@ func (fh *FileHeader) Encode() []byte {
 
 // NewFileHeaderForRaceFile creates a FileHeader configured for race files.
 // Race files use GameID=0, Turn=0, playerIndex=31 for encryption.
-// A random salt is generated for the encryption.
+// A random salt is generated for the encryption (0 in reproducible mode).
 func NewFileHeaderForRaceFile() *FileHeader {
 	// Generate random salt (11 bits)
-	salt := uint16(rand.Intn(MaxSaltValue))
+	salt := uint16(reproducible.Salt(MaxSaltValue))
 	playerData := (salt << SaltShift) | uint16(RaceFilePlayerIndex)
 
 	return &FileHeader{
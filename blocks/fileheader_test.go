@@ -225,6 +225,22 @@ func TestFileHeaderGeneration(t *testing.T) {
 	}
 }
 
+func TestFileHeaderSetCrippled(t *testing.T) {
+	header := loadFileHeader(t, "../testdata/scenario-basic/game.m1")
+	flags := header.Flags
+	require.False(t, header.Crippled())
+	assert.Equal(t, 0, header.SharewareFlag())
+
+	header.SetCrippled(true)
+	assert.True(t, header.Crippled())
+	assert.Equal(t, 1, header.SharewareFlag())
+	assert.Equal(t, flags|FlagCrippled, header.Flags, "other flags should be kept")
+	assert.Equal(t, byte(flags|FlagCrippled), header.Encode()[15])
+
+	header.SetCrippled(false)
+	assert.Equal(t, flags, header.Flags)
+}
+
 // loadFileHeader reads a Stars! file and returns its FileHeader.
 func loadFileHeader(t *testing.T, path string) *FileHeader {
 	t.Helper()
@@ -265,6 +265,21 @@ func NewFleetSplitBlock(b GenericBlock) *FleetSplitBlock {
 	return fsb
 }
 
+// NewFleetSplitOrder creates a FleetSplitBlock splitting a new fleet off a
+// fleet. Stars! follows it with a MoveShipsBlock moving the ships from the
+// fleet to the new one.
+func NewFleetSplitOrder(fleetNumber int) *FleetSplitBlock {
+	fsb := &FleetSplitBlock{FleetNumber: fleetNumber}
+	fsb.Refresh()
+	return fsb
+}
+
+// Refresh re-encodes the block fields into the generic block data,
+// so that the block can be written to a file after being modified.
+func (fsb *FleetSplitBlock) Refresh() {
+	fsb.GenericBlock = newOrderBlock(FleetSplitBlockType, fsb.Encode())
+}
+
 func (fsb *FleetSplitBlock) decode() {
 	data := fsb.Decrypted
 	if len(data) < 2 {
@@ -291,6 +306,20 @@ func NewFleetsMergeBlock(b GenericBlock) *FleetsMergeBlock {
 	return fmb
 }
 
+// NewFleetsMergeOrder creates a FleetsMergeBlock merging fleets into a
+// fleet.
+func NewFleetsMergeOrder(fleetNumber int, fleetsToMerge []int) *FleetsMergeBlock {
+	fmb := &FleetsMergeBlock{FleetNumber: fleetNumber, FleetsToMerge: append([]int(nil), fleetsToMerge...)}
+	fmb.Refresh()
+	return fmb
+}
+
+// Refresh re-encodes the block fields into the generic block data,
+// so that the block can be written to a file after being modified.
+func (fmb *FleetsMergeBlock) Refresh() {
+	fmb.GenericBlock = newOrderBlock(FleetsMergeBlockType, fmb.Encode())
+}
+
 func (fmb *FleetsMergeBlock) decode() {
 	data := fmb.Decrypted
 	if len(data) < 2 {
@@ -377,6 +406,46 @@ func NewMoveShipsBlock(b GenericBlock) *MoveShipsBlock {
 	return block
 }
 
+// NewMoveShipsOrder creates a MoveShipsBlock moving ships between two
+// fleets. Counts are from the destination fleet's perspective: positive
+// for ships arriving from the source, negative for ships leaving to it.
+// Transfers of the same design slot are added up.
+func NewMoveShipsOrder(destFleetNumber, sourceFleetNumber int, transfers []ShipTransfer) *MoveShipsBlock {
+	var counts [16]int
+	for _, t := range transfers {
+		counts[t.DesignSlot&0x0F] += t.Count
+	}
+	var mask uint16
+	for slot, count := range counts {
+		if count != 0 {
+			mask |= 1 << slot
+		}
+	}
+
+	b := &MoveShipsBlock{
+		DestFleetNumber:   destFleetNumber,
+		SourceFleetNumber: sourceFleetNumber,
+		ShipTypeMask:      mask,
+	}
+	// Flags byte as written by Stars!, then the design slot mask
+	info := []byte{0x22, byte(mask), byte(mask >> 8)}
+	for slot := 0; slot < 16; slot++ {
+		if mask&(1<<slot) != 0 {
+			info = append(info, byte(counts[slot]), byte(counts[slot]>>8))
+			b.ShipTransfers = append(b.ShipTransfers, ShipTransfer{DesignSlot: slot, Count: counts[slot]})
+		}
+	}
+	b.TransferInfo = info
+	b.Refresh()
+	return b
+}
+
+// Refresh re-encodes the block fields into the generic block data,
+// so that the block can be written to a file after being modified.
+func (b *MoveShipsBlock) Refresh() {
+	b.GenericBlock = newOrderBlock(MoveShipsBlockType, b.Encode())
+}
+
 func (b *MoveShipsBlock) decode() {
 	data := b.Decrypted
 	if len(data) < 4 {
@@ -29,3 +29,13 @@ func (b GenericBlock) BlockData() BlockData {
 func (b GenericBlock) DecryptedData() DecryptedData {
 	return b.Decrypted
 }
+
+// newOrderBlock builds the generic part of an order block created in memory.
+// Data stays empty: blocks are encrypted when written by a file writer.
+func newOrderBlock(typeID BlockTypeID, decrypted []byte) GenericBlock {
+	return GenericBlock{
+		Type:      typeID,
+		Size:      BlockSize(len(decrypted)),
+		Decrypted: DecryptedData(decrypted),
+	}
+}
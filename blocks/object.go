@@ -1,6 +1,8 @@
 package blocks
 
 import (
+	"fmt"
+
 	"github.com/neper-stars/houston/encoding"
 )
 
@@ -45,6 +47,29 @@ const (
 	TraderItemShip                   = 1 << 12
 )
 
+// TraderItemName returns the human-readable name of a mystery trader item bit
+func TraderItemName(itemBit uint16) string {
+	names := map[uint16]string{
+		TraderItemMultiCargoPod:          "Multi Cargo Pod",
+		TraderItemMultiFunctionPod:       "Multi Function Pod",
+		TraderItemLangstonShield:         "Langston Shield",
+		TraderItemMegaPolyShell:          "Mega Poly Shell",
+		TraderItemAlienMiner:             "Alien Miner",
+		TraderItemHushABoom:              "Hush-a-Boom",
+		TraderItemAntiMatterTorpedo:      "Anti-Matter Torpedo",
+		TraderItemMultiContainedMunition: "Multi Contained Munition",
+		TraderItemMiniMorph:              "Mini Morph",
+		TraderItemEnigmaPulsar:           "Enigma Pulsar",
+		TraderItemGenesisDevice:          "Genesis Device",
+		TraderItemJumpGate:               "Jump Gate",
+		TraderItemShip:                   "Ship",
+	}
+	if name, ok := names[itemBit]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown(0x%04X)", itemBit)
+}
+
 // ObjectBlock represents various game objects (Type 43)
 // This is a multipurpose block with different subtypes
 type ObjectBlock struct {
@@ -115,6 +140,25 @@ func NewObjectBlock(b GenericBlock) *ObjectBlock {
 	return ob
 }
 
+// NewMinefieldDetonateOrder creates the ObjectBlock an SD player sends to set
+// or clear the detonate flag of one of their standard minefields. The order
+// repeats the minefield as last reported, with only the flag changed.
+//
+// No order file in testdata carries one yet: the layout is the minefield
+// layout of the M file, which is what the game echoes back the next turn.
+func NewMinefieldDetonateOrder(minefield *ObjectBlock, detonate bool) *ObjectBlock {
+	order := *minefield
+	order.Detonating = detonate
+	order.Refresh()
+	return &order
+}
+
+// Refresh re-encodes the block fields into the generic block data,
+// so that the block can be written to a file after being modified.
+func (ob *ObjectBlock) Refresh() {
+	ob.GenericBlock = newOrderBlock(ObjectBlockType, ob.Encode())
+}
+
 func (ob *ObjectBlock) decode() {
 	data := ob.Decrypted
 
@@ -86,12 +86,14 @@ type PartialPlanetBlock struct {
 	StarbaseBytes      []byte // Full starbase data (4 bytes for full planet)
 	MassDriverDest     int    // Mass driver destination planet (display ID, 0 = none)
 	MassDriverDestZero bool   // True if destination is explicitly planet 0 vs no destination
+	PacketWarpSpeed    int    // Raw packet fling warp (warp - PacketWarpOffset), same as PlanetChangeBlock
 
 	// Route (if HasRoute)
 	RouteTarget int // Route destination
 
 	// Turn number (optional, last 2 bytes if present)
-	Turn int
+	Turn    int
+	HasTurn bool // The block carries the turn number
 }
 
 // NewPartialPlanetBlock creates a PartialPlanetBlock from a GenericBlock
@@ -252,9 +254,13 @@ func (pb *PartialPlanetBlock) decode(isPlanet bool) {
 			pb.StarbaseBytes = make([]byte, 4)
 			copy(pb.StarbaseBytes, data[index:index+4])
 			pb.StarbaseDesign = int(data[index] & 0x0F)
-			// Byte 2 contains mass driver destination (display ID)
-			pb.MassDriverDest = int(data[index+2])
+			// Bytes 2-3 hold the mass driver settings, laid out like the
+			// PlanetChangeBlock settings shifted right by one bit:
+			// bits 0-9 destination (display ID), bits 10-13 packet warp
+			driver := encoding.Read16(data, index+2)
+			pb.MassDriverDest = int(driver & 0x3FF)
 			pb.MassDriverDestZero = pb.MassDriverDest == 0 && data[index+2] == 0
+			pb.PacketWarpSpeed = int((driver >> 10) & 0x0F)
 			index += 4
 		} else {
 			// Partial planet has 1 byte with design number
@@ -272,6 +278,7 @@ func (pb *PartialPlanetBlock) decode(isPlanet bool) {
 	// Optional turn number (last 2 bytes if present)
 	if index+2 == len(data) {
 		pb.Turn = int(encoding.Read16(data, index))
+		pb.HasTurn = true
 	}
 }
 
@@ -3,11 +3,19 @@ package blocks
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
 
 	"github.com/neper-stars/houston/data"
 	"github.com/neper-stars/houston/encoding"
 )
 
+var (
+	ErrInvalidPlanetID     = errors.New("invalid planet ID")
+	ErrInvalidPlanetNameID = errors.New("invalid planet name ID")
+)
+
 // Planet represents a single planet in the Stars! universe.
 //
 // Each planet has a unique ID, a name (from a predefined list of 999 names),
@@ -255,6 +263,27 @@ func (p *PlanetsBlock) GetPlanets() []Planet {
 	return p.Planets
 }
 
+// SetPlanetName renames a planet to an entry of data.PlanetNames and
+// re-encodes the trailing planet data, so writers emit the new name.
+func (p *PlanetsBlock) SetPlanetName(planetID int, nameID uint32) error {
+	if planetID < 0 || planetID >= len(p.Planets) {
+		return fmt.Errorf("%w: %d", ErrInvalidPlanetID, planetID)
+	}
+	name, ok := data.PlanetNames[nameID]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrInvalidPlanetNameID, nameID)
+	}
+
+	// Copy before editing: block values share the slice with their copies
+	p.Planets = slices.Clone(p.Planets)
+	p.Planets[planetID].NameID = nameID
+	p.Planets[planetID].Name = name
+
+	p.RawPlanetsData = nil
+	p.RawPlanetsData = p.EncodePlanetsData()
+	return nil
+}
+
 // HasGameSetting checks if a specific game setting flag is enabled.
 // Use with data.GameSetting* constants.
 //
@@ -0,0 +1,43 @@
+package blocks
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func packPlanet(nameID, y, xOffset uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, nameID<<22|y<<10|xOffset)
+	return b
+}
+
+func TestPlanetsBlock_SetPlanetName(t *testing.T) {
+	var raw []byte
+	raw = append(raw, packPlanet(5, 1100, 40)...)
+	raw = append(raw, packPlanet(998, 1200, 15)...)
+
+	pb := &PlanetsBlock{PlanetCount: 2}
+	pb.ParsePlanetsData(raw)
+	require.Len(t, pb.Planets, 2)
+	assert.Equal(t, uint32(1040), pb.Planets[0].X)
+	assert.Equal(t, uint32(1055), pb.Planets[1].X)
+
+	copied := *pb
+	require.NoError(t, pb.SetPlanetName(1, 0))
+	assert.Equal(t, "007", pb.Planets[1].Name)
+	assert.Equal(t, "Zulu", copied.Planets[1].Name, "copies of the block are not affected")
+
+	// Only the name bits of the renamed planet change
+	assert.Equal(t, raw[:4], pb.RawPlanetsData[:4])
+	assert.Equal(t, packPlanet(0, 1200, 15), pb.RawPlanetsData[4:8])
+
+	reparsed := &PlanetsBlock{PlanetCount: 2}
+	reparsed.ParsePlanetsData(pb.EncodePlanetsData())
+	assert.Equal(t, pb.Planets, reparsed.Planets)
+
+	assert.ErrorIs(t, pb.SetPlanetName(2, 0), ErrInvalidPlanetID)
+	assert.ErrorIs(t, pb.SetPlanetName(0, 999), ErrInvalidPlanetNameID)
+}
@@ -2,10 +2,14 @@ package blocks
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/neper-stars/houston/encoding"
 )
 
+// ErrInvalidPlayerBlock is returned when the decrypted player data does not
+// hold the constant bits of a player block, typically because it was
+// decrypted with the wrong key.
 var ErrInvalidPlayerBlock = errors.New("invalid player block")
 
 // Primary Race Traits (PRT)
@@ -347,7 +351,7 @@ func (p *PlayerBlock) IsHumanInactive() bool {
 func (p *PlayerBlock) decode() error {
 	// Ensure that there is enough data to decode
 	if len(p.Decrypted) < 8 {
-		return errors.New("unexpected player data size")
+		return fmt.Errorf("%w: unexpected player data size", ErrInvalidPlayerBlock)
 	}
 
 	p.PlayerNumber = int(p.Decrypted[0])
@@ -355,21 +359,21 @@ func (p *PlayerBlock) decode() error {
 	p.Planets = int(p.Decrypted[2]) + (int(p.Decrypted[3]) & 0x03 << 8)
 
 	if int(p.Decrypted[3])&0xFC != 0 {
-		return errors.New("unexpected player values")
+		return fmt.Errorf("%w: unexpected player values", ErrInvalidPlayerBlock)
 	}
 
 	p.Fleets = int(p.Decrypted[4]) + (int(p.Decrypted[5]) & 0x03 << 8)
 	p.StarbaseDesignCount = int(p.Decrypted[5]) >> 4
 
 	if int(p.Decrypted[5])&0x0C != 0 {
-		return errors.New("unexpected player values")
+		return fmt.Errorf("%w: unexpected player values", ErrInvalidPlayerBlock)
 	}
 
 	p.Logo = int(p.Decrypted[6]) >> 3
 	p.FullDataFlag = (int(p.Decrypted[6]) & 0x04) != 0
 
 	if int(p.Decrypted[6])&0x03 != 3 {
-		return errors.New("unexpected player values")
+		return fmt.Errorf("%w: unexpected player values", ErrInvalidPlayerBlock)
 	}
 
 	p.Byte7 = p.Decrypted[7]
@@ -435,9 +439,10 @@ func (p *PlayerBlock) decode() error {
 		p.TechProgress.Biotech = encoding.Read32(p.Decrypted, 52)
 
 		// Research settings (bytes 56-57, FDB 48-49)
+		// Byte 57 matches ResearchChangeBlock: high nibble = next, low nibble = current
 		p.ResearchPercentage = int(p.Decrypted[56])
-		p.CurrentResearchField = int(p.Decrypted[57] >> 4)
-		p.NextResearchField = int(p.Decrypted[57] & 0x0F)
+		p.NextResearchField = int(p.Decrypted[57] >> 4)
+		p.CurrentResearchField = int(p.Decrypted[57] & 0x0F)
 
 		// Research points spent in previous year (bytes 58-61, FDB 50-53)
 		p.ResearchPointsPrevYear = encoding.Read32(p.Decrypted, 58)
@@ -708,7 +713,7 @@ func (p *PlayerBlock) Encode() ([]byte, error) {
 
 		// Bytes 56-57: Research settings
 		data[fullDataStart+48] = byte(p.ResearchPercentage)
-		data[fullDataStart+49] = byte((p.CurrentResearchField&0x0F)<<4) | byte(p.NextResearchField&0x0F)
+		data[fullDataStart+49] = byte((p.NextResearchField&0x0F)<<4) | byte(p.CurrentResearchField&0x0F)
 
 		// Bytes 58-61: Research points spent in previous year
 		encoding.Write32(data, fullDataStart+50, p.ResearchPointsPrevYear)
@@ -327,3 +327,21 @@ func TestPlayerBlockConstants(t *testing.T) {
 	assert.Equal(t, 1, StoredRelationFriend)
 	assert.Equal(t, 2, StoredRelationEnemy)
 }
+
+func TestNewPlayerBlockGarbled(t *testing.T) {
+	tests := []struct {
+		name      string
+		decrypted []byte
+	}{
+		{"too short", []byte{0, 1, 2}},
+		{"planet count high bits", []byte{0, 0, 0, 0x04, 0, 0, 0x03, 0}},
+		{"fleet count reserved bits", []byte{0, 0, 0, 0, 0, 0x04, 0x03, 0}},
+		{"constant bits cleared", []byte{0, 0, 0, 0, 0, 0, 0x00, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPlayerBlock(GenericBlock{Type: PlayerBlockType, Decrypted: tt.decrypted})
+			assert.ErrorIs(t, err, ErrInvalidPlayerBlock)
+		})
+	}
+}
@@ -48,6 +48,34 @@ func (qi *QueueItem) IsShipDesign() bool {
 	return qi.ItemType == ProductionItemTypeCustom
 }
 
+// IsPacket returns true if this item launches mineral packets
+// (not counting the auto-packets item)
+func (qi *QueueItem) IsPacket() bool {
+	return qi.ItemType == ProductionItemTypeStandard &&
+		qi.ItemId >= ProductionItemPacketIronium && qi.ItemId <= ProductionItemPacketMixed
+}
+
+// NewPacketQueueItem creates a queue item launching count mineral packets.
+// itemID must be one of the ProductionItemPacket* constants.
+func NewPacketQueueItem(itemID, count int) QueueItem {
+	return QueueItem{
+		ItemId:   itemID,
+		Count:    count,
+		ItemType: ProductionItemTypeStandard,
+	}
+}
+
+// PacketCount returns the total number of mineral packets queued for launch.
+func PacketCount(items []QueueItem) int {
+	total := 0
+	for _, item := range items {
+		if item.IsPacket() {
+			total += item.Count
+		}
+	}
+	return total
+}
+
 // ProductionQueueBlock represents a planet's production queue (Type 28)
 type ProductionQueueBlock struct {
 	GenericBlock
@@ -133,6 +161,18 @@ func NewProductionQueueChangeBlock(b GenericBlock) *ProductionQueueChangeBlock {
 	return pqcb
 }
 
+// NewProductionQueueOrder creates a ProductionQueueChangeBlock replacing the
+// production queue of a planet. The order always carries the whole queue, so
+// to launch packets, append NewPacketQueueItem entries to the current queue.
+func NewProductionQueueOrder(planetID int, items []QueueItem) *ProductionQueueChangeBlock {
+	pqcb := &ProductionQueueChangeBlock{
+		PlanetId: planetID,
+		Items:    append([]QueueItem(nil), items...),
+	}
+	pqcb.Refresh()
+	return pqcb
+}
+
 func (pqcb *ProductionQueueChangeBlock) decode() {
 	data := pqcb.Decrypted
 	if len(data) < 2 {
@@ -190,3 +230,9 @@ func (pqcb *ProductionQueueChangeBlock) Encode() []byte {
 	}
 	return data
 }
+
+// Refresh re-encodes the block fields into the generic block data,
+// so that the block can be written to a file after being modified.
+func (pqcb *ProductionQueueChangeBlock) Refresh() {
+	pqcb.GenericBlock = newOrderBlock(ProductionQueueChangeBlockType, pqcb.Encode())
+}
@@ -462,6 +462,12 @@ func (wctb *WaypointChangeTaskBlock) Encode() []byte {
 	return data
 }
 
+// Refresh re-encodes the block fields into the generic block data,
+// so that the block can be written to a file after being modified.
+func (wctb *WaypointChangeTaskBlock) Refresh() {
+	wctb.GenericBlock = newOrderBlock(WaypointChangeTaskBlockType, wctb.Encode())
+}
+
 // UsesStargate returns true if this waypoint uses stargate travel
 func (wctb *WaypointChangeTaskBlock) UsesStargate() bool {
 	return wctb.Warp == WarpStargate
@@ -488,6 +494,12 @@ func (wab *WaypointAddBlock) Encode() []byte {
 	return wab.WaypointChangeTaskBlock.Encode()
 }
 
+// Refresh re-encodes the block fields into the generic block data,
+// so that the block can be written to a file after being modified.
+func (wab *WaypointAddBlock) Refresh() {
+	wab.GenericBlock = newOrderBlock(WaypointAddBlockType, wab.Encode())
+}
+
 // WaypointDeleteBlock represents deleting a waypoint from a fleet (Type 3)
 type WaypointDeleteBlock struct {
 	GenericBlock
@@ -505,6 +517,20 @@ func NewWaypointDeleteBlock(b GenericBlock) *WaypointDeleteBlock {
 	return wdb
 }
 
+// NewWaypointDeleteOrder creates a WaypointDeleteBlock removing a waypoint
+// of a fleet.
+func NewWaypointDeleteOrder(fleetNumber, waypointNumber int) *WaypointDeleteBlock {
+	wdb := &WaypointDeleteBlock{FleetNumber: fleetNumber, WaypointNumber: waypointNumber}
+	wdb.Refresh()
+	return wdb
+}
+
+// Refresh re-encodes the block fields into the generic block data,
+// so that the block can be written to a file after being modified.
+func (wdb *WaypointDeleteBlock) Refresh() {
+	wdb.GenericBlock = newOrderBlock(WaypointDeleteBlockType, wdb.Encode())
+}
+
 func (wdb *WaypointDeleteBlock) decode() {
 	data := wdb.Decrypted
 	if len(data) < 3 {
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/report"
+	"github.com/neper-stars/houston/lib/tools/startbalance"
+	"github.com/neper-stars/houston/store"
+)
+
+type balanceCommand struct {
+	Radius    float64 `short:"r" long:"radius" description:"Distance in light years around a homeworld counted as its neighbourhood" default:"150"`
+	Threshold float64 `short:"t" long:"threshold" description:"Fail when the fairness (0-100) is below this value"`
+	Format    string  `short:"f" long:"format" description:"Output format: text, markdown or html" default:"text"`
+	Args      struct {
+		File string `positional-arg-name:"file" description:"Host file of the new game (.hst)" required:"true"`
+	} `positional-args:"yes"`
+}
+
+func (c *balanceCommand) Execute(args []string) error {
+	gs := store.New()
+	if err := gs.AddFileWithXY(c.Args.File); err != nil {
+		return fmt.Errorf("failed to load %s: %w", c.Args.File, err)
+	}
+	printStoreWarnings(gs)
+
+	r := startbalance.Analyze(gs, c.Radius)
+	if len(r.Positions) == 0 {
+		return fmt.Errorf("no homeworld found in %s", c.Args.File)
+	}
+
+	doc := &report.Document{
+		Title:    "Starting position balance",
+		Subtitle: fmt.Sprintf("%s, year %d, within %.0f ly of each homeworld", c.Args.File, r.Year, r.Radius),
+	}
+	section := doc.AddSection("Positions")
+	table := section.AddTable(
+		report.Column{Header: "Player"},
+		report.Column{Header: "Homeworld"},
+		report.Column{Header: "Neighbor ly", Numeric: true},
+		report.Column{Header: "Planets", Numeric: true},
+		report.Column{Header: "Green", Numeric: true},
+		report.Column{Header: "Green %", Numeric: true},
+		report.Column{Header: "Minerals", Numeric: true},
+		report.Column{Header: "Score", Numeric: true},
+	)
+	for _, p := range r.Positions {
+		table.AddRow(fmt.Sprintf("%d %s", p.Player+1, p.Name), p.Homeworld,
+			fmt.Sprintf("%.0f", p.NearestNeighbor), strconv.Itoa(p.Planets), strconv.Itoa(p.Green),
+			strconv.Itoa(p.GreenValue), fmt.Sprintf("%.0f", p.Minerals), fmt.Sprintf("%.2f", p.Score))
+	}
+	section.AddFields(report.F("Fairness", "%.0f%% (lowest score over highest)", r.Fairness))
+
+	if err := renderReport(c.Format, doc); err != nil {
+		return err
+	}
+	if r.Fairness < c.Threshold {
+		return fmt.Errorf("fairness %.0f%% is below the threshold of %.0f%%", r.Fairness, c.Threshold)
+	}
+	return nil
+}
+
+func addBalanceCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("balance",
+		"Score the fairness of starting positions",
+		"Rates each player's starting position from the host file of a new game:\n"+
+			"distance to the nearest other homeworld, planets habitable for the\n"+
+			"player's race (Green, and the sum of their habitability in Green %)\n"+
+			"and mean mineral concentration within the radius. Scores are relative\n"+
+			"to the average position (1.00); fairness is the lowest score over the\n"+
+			"highest.\n\n"+
+			"With --threshold the command fails below the given fairness, so that a\n"+
+			"host script can regenerate the universe in Stars! until it passes:\n"+
+			"  houston balance game.hst --threshold 85",
+		&balanceCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
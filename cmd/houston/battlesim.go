@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/battle"
+	"github.com/neper-stars/houston/lib/tools/montecarlo"
+	"github.com/neper-stars/houston/store"
+)
+
+type battleSimCommand struct {
+	Fleets []string `short:"f" long:"fleet" description:"Fleet as player:design=count[,design=count...][@plan] (repeatable)" required:"true"`
+	Runs   int      `short:"r" long:"runs" default:"1000" description:"Battles played for the odds"`
+	Seed   uint64   `short:"s" long:"seed" description:"Seed of the battles; the same seed gives the same results"`
+	Log    bool     `short:"l" long:"log" description:"Print the rounds of one battle"`
+	Args   struct {
+		File string `positional-arg-name:"file" description:"Game file with the designs and battle plans (.m, .hst)" required:"true"`
+	} `positional-args:"yes"`
+}
+
+func (c *battleSimCommand) Execute(args []string) error {
+	ctx, err := loadGameContext(c.Args.File)
+	if err != nil {
+		return err
+	}
+	var fleets []battle.Fleet
+	for _, spec := range c.Fleets {
+		fleet, err := parseBattleFleet(ctx.Store, spec)
+		if err != nil {
+			return fmt.Errorf("fleet %q: %w", spec, err)
+		}
+		fleets = append(fleets, fleet)
+	}
+
+	if c.Log {
+		result, err := battle.Simulate(fleets, rand.New(rand.NewPCG(c.Seed, 0)))
+		if err != nil {
+			return err
+		}
+		for _, event := range result.Events {
+			fmt.Println(result.Describe(event))
+		}
+		fmt.Println()
+		for _, s := range result.Stacks {
+			status := fmt.Sprintf("%d of %d left", s.Remaining, s.Ships)
+			if s.Escaped {
+				status += ", escaped"
+			}
+			fmt.Printf("%s (player %d, %s): %s\n", s.Fleet, s.Player+1, s.Design, status)
+		}
+		fmt.Println()
+	}
+
+	report, err := battle.Expect(context.Background(), fleets, montecarlo.Config{Runs: c.Runs, Seed: c.Seed})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Over %d battles:\n", c.Runs)
+	var players []int
+	for _, fleet := range fleets {
+		if !slices.Contains(players, fleet.Player) {
+			players = append(players, fleet.Player)
+		}
+	}
+	for _, player := range players {
+		won := report.Metric(battle.WonMetric(player))
+		lost := report.Metric(battle.LostMetric(player))
+		fmt.Printf("  player %-2d wins %5.1f%% (%.1f-%.1f), loses %.1f ships on average\n",
+			player+1, 100*won.Mean, 100*won.Low, 100*won.High, lost.Mean)
+	}
+	if rounds := report.Metric("rounds"); rounds != nil {
+		fmt.Printf("  battles last %.1f rounds on average\n", rounds.Mean)
+	}
+	return nil
+}
+
+// parseBattleFleet reads a fleet given as player:design=count[,...][@plan],
+// with the player numbered from 1 and designs and plans by name. A design
+// written owner/design is another player's.
+func parseBattleFleet(gs *store.GameStore, spec string) (battle.Fleet, error) {
+	playerText, rest, ok := strings.Cut(spec, ":")
+	if !ok {
+		return battle.Fleet{}, errors.New("expected player:design=count")
+	}
+	player, err := strconv.Atoi(playerText)
+	if err != nil || player < 1 || player > 16 {
+		return battle.Fleet{}, fmt.Errorf("invalid player %q (must be 1-16)", playerText)
+	}
+	fleet := battle.Fleet{Player: player - 1}
+
+	shipsText, planName, hasPlan := strings.Cut(rest, "@")
+	if hasPlan {
+		plan, err := findBattlePlan(gs, fleet.Player, planName)
+		if err != nil {
+			return battle.Fleet{}, err
+		}
+		fleet.Plan = plan
+	}
+
+	for _, part := range strings.Split(shipsText, ",") {
+		name, countText, ok := strings.Cut(part, "=")
+		if !ok {
+			return battle.Fleet{}, fmt.Errorf("expected design=count, got %q", part)
+		}
+		count, err := strconv.Atoi(countText)
+		if err != nil || count < 1 {
+			return battle.Fleet{}, fmt.Errorf("invalid ship count %q", countText)
+		}
+		// A design may be borrowed from another player as owner/design
+		owner := fleet.Player
+		if ownerText, designName, ok := strings.Cut(name, "/"); ok {
+			if n, err := strconv.Atoi(ownerText); err == nil && n >= 1 && n <= 16 {
+				owner, name = n-1, designName
+			}
+		}
+		design, err := findBattleDesign(gs, owner, strings.TrimSpace(name))
+		if err != nil {
+			return battle.Fleet{}, err
+		}
+		fleet.Ships = append(fleet.Ships, battle.Ships{Design: design, Count: count})
+	}
+	return fleet, nil
+}
+
+func findBattleDesign(gs *store.GameStore, player int, name string) (*blocks.DesignBlock, error) {
+	for _, design := range gs.DesignsByOwner(player) {
+		if !strings.EqualFold(design.Name, name) {
+			continue
+		}
+		for _, block := range design.RawBlocks() {
+			if db, ok := block.(blocks.DesignBlock); ok {
+				if !db.IsFullDesign {
+					return nil, fmt.Errorf("the components of %q are unknown in this file", design.Name)
+				}
+				return &db, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("player %d has no design named %q", player+1, name)
+}
+
+func findBattlePlan(gs *store.GameStore, player int, name string) (*blocks.BattlePlanBlock, error) {
+	for _, plan := range gs.BattlePlansByOwner(player) {
+		if plan.Deleted || !strings.EqualFold(plan.Name, name) {
+			continue
+		}
+		for _, block := range plan.RawBlocks() {
+			if bp, ok := block.(blocks.BattlePlanBlock); ok {
+				return &bp, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("player %d has no battle plan named %q", player+1, name)
+}
+
+func addBattleSimCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("battlesim",
+		"Simulate battles between fleets",
+		"Plays battles between fleets built from the designs and battle plans of a\n"+
+			"game file, and prints the odds of each player over many battles. Designs\n"+
+			"need their components, so those of other players are only known from\n"+
+			"the host file. The simulator follows the documented battle rules, not\n"+
+			"the game's code: expect the odds to be close, not exact.\n\n"+
+			"A design written owner/design is borrowed from another player, to try\n"+
+			"designs against each other.\n\n"+
+			"Examples:\n"+
+			"  houston battlesim game.hst --fleet 1:Destroyer=8@Kill --fleet 2:Frigate=12,Scout=2\n"+
+			"  houston battlesim game.m1 --fleet 1:Destroyer=8 --fleet 1:Starbase=1 --fleet 3:Raider=5 --log\n"+
+			"  houston battlesim game.m1 --fleet 1:Destroyer=8 --fleet 2:1/Cruiser=4",
+		&battleSimCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -106,10 +106,14 @@ func FormatPlanetChange(block blocks.Block, index int) string {
 	}
 	fields = append(fields, fmt.Sprintf("           %s bit 0:      fNoResearch = %s (contribute leftover to research)",
 		TreeBranch, contributeStr))
-	fields = append(fields, fmt.Sprintf("           %s bits 1-10:  pctDp = %d (driver/packet %%)",
-		TreeBranch, pcb.DriverPacketPercent))
-	fields = append(fields, fmt.Sprintf("           %s bits 11-14: iWarpFling = %d (packet warp speed)",
-		TreeEnd, pcb.PacketWarpSpeed))
+	driverDesc := "no destination"
+	if target, ok := pcb.MassDriverTarget(); ok {
+		driverDesc = fmt.Sprintf("-> Planet #%d", target+1)
+	}
+	fields = append(fields, fmt.Sprintf("           %s bits 1-10:  pctDp = %d (mass driver destination, %s)",
+		TreeBranch, pcb.DriverPacketPercent, driverDesc))
+	fields = append(fields, fmt.Sprintf("           %s bits 11-14: iWarpFling = %d (packet warp %d)",
+		TreeEnd, pcb.PacketWarpSpeed, pcb.PacketWarp()))
 
 	// Bytes 4-5: Route destination (uint16 LE, left-shifted by 1)
 	routeWord := encoding.Read16(d, 4)
@@ -126,8 +130,12 @@ func FormatPlanetChange(block blocks.Block, index int) string {
 	fields = append(fields, "── Summary ──")
 	fields = append(fields, fmt.Sprintf("  Planet #%d settings change:", pcb.PlanetId+1))
 	fields = append(fields, fmt.Sprintf("    %s Contribute leftover to research: %s", TreeBranch, contributeStr))
-	fields = append(fields, fmt.Sprintf("    %s Driver/Packet %%: %d%%", TreeBranch, pcb.DriverPacketPercent))
-	fields = append(fields, fmt.Sprintf("    %s Packet Warp Speed: %d", TreeBranch, pcb.PacketWarpSpeed))
+	if target, ok := pcb.MassDriverTarget(); ok {
+		fields = append(fields, fmt.Sprintf("    %s Mass Driver Destination: Planet #%d", TreeBranch, target+1))
+	} else {
+		fields = append(fields, fmt.Sprintf("    %s Mass Driver Destination: None", TreeBranch))
+	}
+	fields = append(fields, fmt.Sprintf("    %s Packet Warp: %d", TreeBranch, pcb.PacketWarp()))
 	if pcb.RouteDestinationId > 0 {
 		fields = append(fields, fmt.Sprintf("    %s Route Destination: Planet #%d", TreeEnd, pcb.RouteDestinationId+1))
 	} else {
@@ -1,6 +1,9 @@
 package blockdetail
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/neper-stars/houston/blocks"
 )
 
@@ -12,9 +15,14 @@ func FormatGeneric(block blocks.Block, index int) string {
 	header := FormatBlockHeader(block, index, width)
 	hexSection := FormatHexSection(data, width)
 
-	// Add note that detailed view is not implemented
+	// Blocks decoded by an external decoder (parser.RegisterBlockType)
+	// describe themselves through fmt.Stringer
 	var fields []string
-	fields = append(fields, "(detailed view not yet implemented for this block type)")
+	if s, ok := block.(fmt.Stringer); ok {
+		fields = strings.Split(s.String(), "\n")
+	} else {
+		fields = append(fields, "(detailed view not yet implemented for this block type)")
+	}
 
 	fieldsSection := FormatFieldsSection(fields, width)
 
@@ -38,29 +38,6 @@ func minefieldTypeName(mfType int) string {
 	return fmt.Sprintf("Unknown(%d)", mfType)
 }
 
-// traderItemName returns human-readable name for trader item bit
-func traderItemName(itemBit uint16) string {
-	names := map[uint16]string{
-		blocks.TraderItemMultiCargoPod:          "Multi Cargo Pod",
-		blocks.TraderItemMultiFunctionPod:       "Multi Function Pod",
-		blocks.TraderItemLangstonShield:         "Langston Shield",
-		blocks.TraderItemMegaPolyShell:          "Mega Poly Shell",
-		blocks.TraderItemAlienMiner:             "Alien Miner",
-		blocks.TraderItemHushABoom:              "Hush-a-Boom",
-		blocks.TraderItemAntiMatterTorpedo:      "Anti-Matter Torpedo",
-		blocks.TraderItemMultiContainedMunition: "Multi Contained Munition",
-		blocks.TraderItemMiniMorph:              "Mini Morph",
-		blocks.TraderItemEnigmaPulsar:           "Enigma Pulsar",
-		blocks.TraderItemGenesisDevice:          "Genesis Device",
-		blocks.TraderItemJumpGate:               "Jump Gate",
-		blocks.TraderItemShip:                   "Ship",
-	}
-	if name, ok := names[itemBit]; ok {
-		return name
-	}
-	return fmt.Sprintf("Unknown(0x%04X)", itemBit)
-}
-
 // FormatObject provides detailed view for ObjectBlock (type 43)
 func FormatObject(block blocks.Block, index int) string {
 	width := DefaultWidth
@@ -450,7 +427,7 @@ func formatMysteryTrader(fields *[]string, ob blocks.ObjectBlock, d []byte) {
 	var carriedItems []string
 	for _, itemBit := range traderItems {
 		if ob.TraderHasItem(itemBit) {
-			carriedItems = append(carriedItems, traderItemName(itemBit))
+			carriedItems = append(carriedItems, blocks.TraderItemName(itemBit))
 		}
 	}
 	if len(carriedItems) > 0 {
@@ -140,10 +140,10 @@ func FormatPlayer(block blocks.Block, index int) string {
 		fields = append(fields, FormatFieldRaw(0x39, 0x39, "Research Fields",
 			fmt.Sprintf("0x%02X", data[57]),
 			fmt.Sprintf("0b%08b", data[57])))
-		fields = append(fields, fmt.Sprintf("           %s bits4-7: currentField = %d (%s)",
-			TreeBranch, pb.CurrentResearchField, blocks.ResearchFieldName(pb.CurrentResearchField)))
-		fields = append(fields, fmt.Sprintf("           %s bits0-3: nextField = %d (%s)",
-			TreeEnd, pb.NextResearchField, blocks.ResearchFieldName(pb.NextResearchField)))
+		fields = append(fields, fmt.Sprintf("           %s bits4-7: nextField = %d (%s)",
+			TreeBranch, pb.NextResearchField, blocks.ResearchFieldName(pb.NextResearchField)))
+		fields = append(fields, fmt.Sprintf("           %s bits0-3: currentField = %d (%s)",
+			TreeEnd, pb.CurrentResearchField, blocks.ResearchFieldName(pb.CurrentResearchField)))
 
 		// PRT (offset 0x4C = byte 76)
 		prtName := "Unknown"
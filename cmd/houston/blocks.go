@@ -17,7 +17,14 @@ import (
 type blocksCommand struct {
 	Detailed bool   `short:"d" long:"detailed" description:"Show detailed ASCII schema for each block"`
 	Filter   string `short:"f" long:"filter" description:"Filter by block type IDs (comma-separated, e.g. '8,6' for FileHeader and Player)"`
-	Args     struct {
+
+	// Decryption overrides, for files with a damaged header
+	ForceSalt        *int    `long:"force-salt" description:"Decrypt with this salt (0-2047) instead of the header's"`
+	ForceGameID      *uint32 `long:"force-game-id" description:"Decrypt with this game ID instead of the header's"`
+	ForceTurn        *uint16 `long:"force-turn" description:"Decrypt with this turn (year - 2400) instead of the header's"`
+	ForcePlayerIndex *int    `long:"force-player-index" description:"Decrypt with this player index (0-15, 31 for race files) instead of the header's"`
+
+	Args struct {
 		File string `positional-arg-name:"file" description:"Stars! game file to read" required:"true"`
 	} `positional-args:"yes"`
 }
@@ -30,7 +37,14 @@ func (c *blocksCommand) Execute(args []string) error {
 
 	fd := parser.FileData(fileBytes)
 
-	blockList, err := fd.BlockList()
+	if c.ForceSalt != nil && (*c.ForceSalt < 0 || *c.ForceSalt >= blocks.MaxSaltValue) {
+		return fmt.Errorf("invalid --force-salt %d: salts are 0-%d", *c.ForceSalt, blocks.MaxSaltValue-1)
+	}
+	if c.ForcePlayerIndex != nil && (*c.ForcePlayerIndex < 0 || *c.ForcePlayerIndex > blocks.PlayerIndexMask) {
+		return fmt.Errorf("invalid --force-player-index %d", *c.ForcePlayerIndex)
+	}
+
+	blockList, err := fd.BlockListWith(c.overrideDecryption)
 	if err != nil {
 		return fmt.Errorf("failed to parse blocks: %w", err)
 	}
@@ -89,11 +103,28 @@ func (c *blocksCommand) Execute(args []string) error {
 	return nil
 }
 
+// overrideDecryption replaces the decryption parameters given on the
+// command line.
+func (c *blocksCommand) overrideDecryption(params *parser.DecryptionParams) {
+	if c.ForceSalt != nil {
+		params.Salt = *c.ForceSalt
+	}
+	if c.ForceGameID != nil {
+		params.GameID = int(*c.ForceGameID)
+	}
+	if c.ForceTurn != nil {
+		params.Turn = int(*c.ForceTurn)
+	}
+	if c.ForcePlayerIndex != nil {
+		params.PlayerIndex = *c.ForcePlayerIndex
+	}
+}
+
 func printBlockDetails(block blocks.Block) {
 	switch b := block.(type) {
 	case blocks.FileHeader:
-		fmt.Printf("  GameID: %d, Turn: %d (Year %d), Player: %d\n",
-			b.GameID, b.Turn, b.Year(), b.PlayerIndex())
+		fmt.Printf("  GameID: %d, Turn: %d (Year %d), Player: %d, Salt: %d\n",
+			b.GameID, b.Turn, b.Year(), b.PlayerIndex(), b.Salt())
 	case blocks.PlanetsBlock:
 		fmt.Printf("  PlanetCount: %d\n", b.GetPlanetCount())
 	case blocks.PlanetBlock:
@@ -115,6 +146,9 @@ func printBlockDetails(block blocks.Block) {
 		fmt.Printf("  From: %d, To: %d\n", b.SenderId, b.ReceiverId)
 	case blocks.ObjectBlock:
 		fmt.Printf("  ObjectType: %d, Owner: %d\n", b.ObjectType, b.Owner)
+	case fmt.Stringer:
+		// Blocks decoded by an external decoder (parser.RegisterBlockType)
+		fmt.Printf("  %s\n", b)
 	}
 }
 
@@ -125,7 +159,11 @@ func addBlocksCommand(parser *flags.Parser) {
 			"This tool is useful for debugging and understanding Stars! file structure.\n"+
 			"It displays each block with its type ID and hex-encoded decrypted data.\n"+
 			"For certain block types (FileHeader, Planets, Planet, Fleet, Design),\n"+
-			"it also shows the parsed structure.",
+			"it also shows the parsed structure.\n\n"+
+			"The header's salt, game ID, turn and player index seed the decryption of\n"+
+			"the other blocks. When a header is damaged, --force-salt, --force-game-id,\n"+
+			"--force-turn and --force-player-index decrypt with the right values instead,\n"+
+			"taken from sibling files of the same game and turn.",
 		&blocksCommand{})
 	if err != nil {
 		panic(err)
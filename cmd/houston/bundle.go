@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/filenames"
+	"github.com/neper-stars/houston/lib/tools/turnbundle"
+	"github.com/neper-stars/houston/remotefs"
+)
+
+type bundleCommand struct{}
+
+type bundleCreateCommand struct {
+	Player int    `short:"p" long:"player" description:"Player the bundle is for (1-16); other players' files are left out"`
+	Output string `short:"o" long:"output" description:"Bundle file (default: <game>-<year>.zip, or <game>-<year>-p<N>.zip with --player)"`
+	Args   struct {
+		Files []string `positional-arg-name:"file" description:"Stars! game files to bundle" required:"true"`
+	} `positional-args:"yes"`
+}
+
+func (c *bundleCreateCommand) Execute(args []string) error {
+	fsys := gameFS()
+
+	var files []turnbundle.File
+	seen := make(map[string]string)
+	for _, filename := range c.Args.Files {
+		name := filenames.Parse(filename)
+		if name.Kind == filenames.Unknown {
+			return fmt.Errorf("%s is not a Stars! game file", filename)
+		}
+		if c.Player != 0 && name.Kind.PerPlayer() && name.Player != c.Player {
+			continue
+		}
+		base := filepath.Base(filename)
+		if remotefs.IsRemote(base) {
+			base = base[strings.IndexByte(base, ':')+1:]
+		}
+		if other, ok := seen[strings.ToLower(base)]; ok {
+			return fmt.Errorf("%s and %s have the same name", other, filename)
+		}
+		seen[strings.ToLower(base)] = filename
+
+		data, err := fsys.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", filename, err)
+		}
+		files = append(files, turnbundle.File{Name: base, Data: data})
+	}
+
+	var buf bytes.Buffer
+	manifest, err := turnbundle.Create(&buf, c.Player, files...)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+
+	output := c.Output
+	if output == "" {
+		name := filenames.Parse(c.Args.Files[0])
+		output = fmt.Sprintf("%s%s-%d", name.Dir, name.Base, manifest.Year)
+		if c.Player != 0 {
+			output += fmt.Sprintf("-p%d", c.Player)
+		}
+		output += ".zip"
+	}
+	if err := fsys.WriteFile(output, buf.Bytes()); err != nil {
+		return fmt.Errorf("error writing %s: %w", output, err)
+	}
+
+	fmt.Printf("Created %s\n", output)
+	fmt.Printf("  Game ID: %d\n", manifest.GameID)
+	fmt.Printf("  Year: %d\n", manifest.Year)
+	for _, entry := range manifest.Files {
+		fmt.Printf("  %-12s %8d bytes  sha256:%s\n", entry.Name, entry.Size, entry.SHA256)
+	}
+	return nil
+}
+
+type bundleVerifyCommand struct {
+	Extract string `short:"x" long:"extract" description:"Extract the files to this directory once verified"`
+	Args    struct {
+		Bundle string `positional-arg-name:"bundle" description:"Bundle to verify (.zip)" required:"true"`
+	} `positional-args:"yes"`
+}
+
+func (c *bundleVerifyCommand) Execute(args []string) error {
+	fsys := gameFS()
+
+	data, err := fsys.ReadFile(c.Args.Bundle)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", c.Args.Bundle, err)
+	}
+	manifest, files, err := turnbundle.Verify(data)
+	if manifest != nil {
+		fmt.Printf("Game ID: %d\n", manifest.GameID)
+		fmt.Printf("Year: %d\n", manifest.Year)
+		if manifest.Player != 0 {
+			fmt.Printf("Player: %d\n", manifest.Player)
+		}
+		for _, f := range files {
+			fmt.Printf("  OK  %s\n", f.Name)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s failed verification:\n%w", c.Args.Bundle, err)
+	}
+	fmt.Println("Bundle verified.")
+
+	if c.Extract == "" {
+		return nil
+	}
+	remote := remotefs.IsRemote(c.Extract)
+	if !remote {
+		if err := os.MkdirAll(c.Extract, 0755); err != nil {
+			return fmt.Errorf("error creating %s: %w", c.Extract, err)
+		}
+	}
+	for _, f := range files {
+		path := filepath.Join(c.Extract, f.Name)
+		if remote {
+			path = strings.TrimSuffix(c.Extract, "/") + "/" + f.Name
+		}
+		if err := fsys.WriteFile(path, f.Data); err != nil {
+			return fmt.Errorf("error writing %s: %w", path, err)
+		}
+		fmt.Printf("Extracted %s\n", path)
+	}
+	return nil
+}
+
+func addBundleCommand(parser *flags.Parser) {
+	cmd, err := parser.AddCommand("bundle",
+		"Package turn files with a checksum manifest",
+		"Commands for sending turn files with a manifest of their SHA-256 hashes,\n"+
+			"so that files damaged by email or cloud transfer are caught before\n"+
+			"Stars! loads them.",
+		&bundleCommand{})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = cmd.AddCommand("create",
+		"Bundle a turn's files",
+		"Writes the given game files and a manifest of their hashes to a ZIP\n"+
+			"archive. With --player, the files of other players (.m, .x, .h, .r)\n"+
+			"are left out, so the host can bundle each player's turn from the\n"+
+			"whole game directory:\n\n"+
+			"  houston bundle create -p 2 game.xy game.m*\n\n"+
+			"Bundles can be loaded by the other commands without unpacking them.",
+		&bundleCreateCommand{})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = cmd.AddCommand("verify",
+		"Check a bundle against its manifest",
+		"Checks that every file listed in the manifest of a bundle is present\n"+
+			"with the right size and hash, and that no other file was added.\n"+
+			"With --extract, the verified files are written to a directory.",
+		&bundleVerifyCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
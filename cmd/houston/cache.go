@@ -0,0 +1,21 @@
+package main
+
+import (
+	"github.com/neper-stars/houston/parser"
+)
+
+// parseCache returns the parse cache enabled with --cache or --cache-dir,
+// or nil when caching is off.
+func parseCache() (*parser.Cache, error) {
+	if !globals.Cache && globals.CacheDir == "" {
+		return nil, nil
+	}
+	dir := globals.CacheDir
+	if dir == "" {
+		var err error
+		if dir, err = parser.DefaultCacheDir(); err != nil {
+			return nil, err
+		}
+	}
+	return parser.NewCache(dir)
+}
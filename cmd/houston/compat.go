@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/compat"
+)
+
+type compatCommand struct {
+	Corpus []string `long:"corpus" description:"YAML corpus of known files to check against, besides the built-in one (repeatable)"`
+	Args   struct {
+		Files []string `positional-arg-name:"file" description:"Stars! files to check" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (c *compatCommand) Execute(args []string) error {
+	registry := compat.Default()
+	for _, path := range c.Corpus {
+		if err := registry.Load(path); err != nil {
+			return err
+		}
+	}
+
+	for i, file := range c.Args.Files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		printCompatReport(registry.Check(file, data))
+	}
+	return nil
+}
+
+func printCompatReport(r *compat.Report) {
+	fmt.Printf("%s (sha256 %s)\n", r.File, r.SHA256)
+	if r.Profile != nil {
+		fmt.Printf("  Known file: %s, %s\n", r.Profile.File, r.Profile.Report)
+	}
+	if len(r.Findings) == 0 {
+		fmt.Println("  No known quirk")
+		return
+	}
+	for _, f := range r.Findings {
+		fmt.Printf("  %s: %s\n", f.Quirk.ID, f.Quirk.Description)
+		if f.Detail != "" {
+			fmt.Printf("    Found: %s\n", f.Detail)
+		} else if !f.Detected {
+			fmt.Println("    Registered for this file, not detected")
+		}
+		if f.Workaround != "" {
+			fmt.Printf("    Workaround: %s\n", f.Workaround)
+		} else {
+			fmt.Println("    No workaround: get a new copy of the file")
+		}
+	}
+}
+
+func addCompatCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("compat",
+		"Check files for known quirks and their workarounds",
+		"Tells whether files are known problem files, registered from bug reports\n"+
+			"by their SHA-256, or show one of the quirks of real-world files, and\n"+
+			"which houston command works around each:\n\n"+
+			"  truncated       the file is cut short or holds a broken block\n"+
+			"  damaged-header  the header does not decrypt the blocks (houston blocks --force-*)\n"+
+			"  race-checksum   Stars! reports the race file as corrupted (houston race)\n"+
+			"  misnamed        the file is named after another player (houston fix-name)\n\n"+
+			"Known files come with the flags their workaround needs. --corpus adds a\n"+
+			"YAML list of known files, in the format of the built-in corpus:\n\n"+
+			"  - sha256: <hash of the file>\n"+
+			"    file: game.m1\n"+
+			"    report: header damaged by a mail client\n"+
+			"    quirks:\n"+
+			"      - id: damaged-header\n"+
+			"        flags: [--force-salt, \"1234\"]\n\n"+
+			"Examples:\n"+
+			"  houston compat game.r1\n"+
+			"  houston compat --corpus reports.yaml *.m*",
+		&compatCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
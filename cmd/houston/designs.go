@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/designdiff"
+	"github.com/neper-stars/houston/lib/tools/report"
+	"github.com/neper-stars/houston/store"
+)
+
+type designsCommand struct{}
+
+type designsDiffCommand struct {
+	Design string `short:"d" long:"design" description:"Design name (case-insensitive)" required:"true"`
+	Player int    `short:"p" long:"player" description:"Design owner (1-16); required if several players use the name"`
+	Format string `short:"f" long:"format" description:"Output format: text, markdown or html" default:"text"`
+	Args   struct {
+		Before string `positional-arg-name:"before" description:"Older Stars! game file"`
+		After  string `positional-arg-name:"after" description:"Newer Stars! game file"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *designsDiffCommand) Execute(args []string) error {
+	owner := c.Player - 1
+
+	before, err := loadDesignForDiff(c.Args.Before, c.Design, owner)
+	if err != nil {
+		return err
+	}
+	after, err := loadDesignForDiff(c.Args.After, c.Design, owner)
+	if err != nil {
+		return err
+	}
+
+	diff := designdiff.Compare(before, after)
+
+	doc := &report.Document{
+		Title:    fmt.Sprintf("Design %q (player %d)", after.Name, after.Owner+1),
+		Subtitle: fmt.Sprintf("%s -> %s", c.Args.Before, c.Args.After),
+	}
+
+	if diff.IsEmpty() {
+		doc.AddSection("").AddParagraph("No changes.")
+		return renderReport(c.Format, doc)
+	}
+
+	if diff.HullChanged {
+		doc.AddSection("Hull").AddParagraph("%s -> %s", hullName(before), hullName(after))
+	}
+
+	if len(diff.Slots) > 0 {
+		slots := doc.AddSection("Slots").AddTable(
+			report.Column{Header: "Slot", Numeric: true},
+			report.Column{Header: "Before"},
+			report.Column{Header: "After"},
+		)
+		for _, s := range diff.Slots {
+			slots.AddRow(strconv.Itoa(s.SlotIndex+1), slotDescription(s.Before), slotDescription(s.After))
+		}
+	}
+
+	if changed := diff.ChangedStats(); len(changed) > 0 {
+		stats := doc.AddSection("Stats").AddTable(statColumns("Stat")...)
+		for _, s := range changed {
+			stats.AddRow(s.Name, strconv.Itoa(s.Before), strconv.Itoa(s.After), fmt.Sprintf("%+d", s.Delta()))
+		}
+	}
+
+	cd := diff.CostDelta()
+	cost := doc.AddSection("Cost").AddTable(statColumns("Cost")...)
+	cost.AddRow("Resources", strconv.Itoa(diff.CostBefore.Resources), strconv.Itoa(diff.CostAfter.Resources), fmt.Sprintf("%+d", cd.Resources))
+	cost.AddRow("Ironium", strconv.Itoa(diff.CostBefore.Ironium), strconv.Itoa(diff.CostAfter.Ironium), fmt.Sprintf("%+d", cd.Ironium))
+	cost.AddRow("Boranium", strconv.Itoa(diff.CostBefore.Boranium), strconv.Itoa(diff.CostAfter.Boranium), fmt.Sprintf("%+d", cd.Boranium))
+	cost.AddRow("Germanium", strconv.Itoa(diff.CostBefore.Germanium), strconv.Itoa(diff.CostAfter.Germanium), fmt.Sprintf("%+d", cd.Germanium))
+
+	return renderReport(c.Format, doc)
+}
+
+// statColumns returns the columns of a before/after comparison table.
+func statColumns(name string) []report.Column {
+	return []report.Column{
+		{Header: name},
+		{Header: "Before", Numeric: true},
+		{Header: "After", Numeric: true},
+		{Header: "Change", Numeric: true},
+	}
+}
+
+func loadDesignForDiff(filename, name string, owner int) (*store.DesignEntity, error) {
+	gs := store.New()
+	if err := gs.AddFileWithXY(filename); err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", filename, err)
+	}
+	printStoreWarnings(gs)
+	design, err := designdiff.FindDesign(gs, name, owner)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return design, nil
+}
+
+func hullName(d *store.DesignEntity) string {
+	if hull := d.Hull(); hull != nil {
+		return hull.Name
+	}
+	return fmt.Sprintf("Hull %d", d.HullId)
+}
+
+func slotDescription(item *store.EquippedItem) string {
+	if item == nil {
+		return "(empty)"
+	}
+	name := item.Name()
+	if name == "" {
+		name = fmt.Sprintf("Item %d (category 0x%04x)", item.ItemID, item.Category)
+	}
+	return fmt.Sprintf("%d x %s", item.Count, name)
+}
+
+func addDesignsCommand(parser *flags.Parser) {
+	cmd, err := parser.AddCommand("designs",
+		"Inspect ship and starbase designs",
+		"Commands for inspecting ship and starbase designs.",
+		&designsCommand{})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = cmd.AddCommand("diff",
+		"Compare a design between two turns or files",
+		"Compares a design (looked up by name) between two game files, showing\n"+
+			"slot-by-slot component changes, stat deltas and cost deltas.\n\n"+
+			"Useful both for tracking your own design iterations and for following\n"+
+			"the evolution of enemy designs. Enemy designs seen only from a scan\n"+
+			"carry no component data, so only hull changes are reported for them.",
+		&designsDiffCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/host"
+	"github.com/neper-stars/houston/lib/tools/summary"
+	"github.com/neper-stars/houston/lib/tools/turnbundle"
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/password"
+	"github.com/neper-stars/houston/store"
+)
+
+// Errors shared by several commands, explained by presentError.
+var (
+	errNoPlayerDetected = errors.New("could not auto-detect player number: no M-file loaded")
+	errInvalidPlayer    = errors.New("invalid player number")
+	errNoUniverse       = errors.New("no universe definition loaded")
+)
+
+// Outcomes reported through the exit code rather than as failures: the
+// command has already printed what it found.
+var (
+	errExploitsFound = errors.New("exploits found")
+	errNothingToDo   = errors.New("nothing to do")
+)
+
+// Exit codes of houston, one per class of failure, so that scripts and CI
+// jobs can tell them apart.
+const (
+	exitOK          = 0 // the command succeeded
+	exitFailure     = 1 // any error not classified below
+	exitUsage       = 2 // invalid command line
+	exitParse       = 3 // a file is missing, not a Stars! file, or corrupted
+	exitInvalid     = 4 // the files are valid but do not fit together or fail a check
+	exitExploits    = 5 // exploits were found
+	exitNothingToDo = 6 // nothing to do: the files are already as asked
+)
+
+// errorClass names the class of an error in the JSON output.
+var errorClass = map[int]string{
+	exitFailure:     "error",
+	exitUsage:       "usage",
+	exitParse:       "parse",
+	exitInvalid:     "validation",
+	exitExploits:    "exploits-found",
+	exitNothingToDo: "nothing-to-do",
+}
+
+// exitCode returns the exit code for an error returned by a command.
+func exitCode(err error) int {
+	var flagsErr *flags.Error
+	var malformed *parser.ErrMalformedBlock
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errExploitsFound):
+		return exitExploits
+	case errors.Is(err, errNothingToDo):
+		return exitNothingToDo
+	case errors.As(err, &flagsErr),
+		errors.Is(err, password.ErrInvalidMask),
+		errors.Is(err, password.ErrInvalidRule):
+		return exitUsage
+	case errors.As(err, &malformed),
+		errors.Is(err, fs.ErrNotExist),
+		errors.Is(err, store.ErrNoHeader),
+		errors.Is(err, parser.ErrNoFileHeaderFound),
+		errors.Is(err, blocks.ErrInvalidFileHeaderBlock),
+		errors.Is(err, blocks.ErrInvalidPlayerBlock),
+		errors.Is(err, store.ErrNotRaceFile),
+		errors.Is(err, store.ErrNoPlayerBlock),
+		errors.Is(err, host.ErrNotHostFile):
+		return exitParse
+	case errors.Is(err, store.ErrGameIDMismatch),
+		errors.Is(err, store.ErrNoSourceForPlayer),
+		errors.Is(err, errInvalidPlayer),
+		errors.Is(err, errNoPlayerDetected),
+		errors.Is(err, errNoUniverse),
+		errors.Is(err, password.ErrCheckpointMismatch),
+		errors.Is(err, summary.ErrPlayerNotFound),
+		errors.Is(err, host.ErrWrongGame),
+		errors.Is(err, host.ErrWrongTurn),
+		errors.Is(err, turnbundle.ErrGameIDMismatch),
+		errors.Is(err, turnbundle.ErrNoManifest),
+		errors.Is(err, turnbundle.ErrMissingFile),
+		errors.Is(err, turnbundle.ErrSizeMismatch),
+		errors.Is(err, turnbundle.ErrHashMismatch),
+		errors.Is(err, turnbundle.ErrUnlistedFile):
+		return exitInvalid
+	}
+	return exitFailure
+}
+
+// errorHint explains a class of errors in plain language and suggests
+// what to do about it.
+type errorHint struct {
+	match   func(err error) bool
+	explain string
+	hint    string
+}
+
+func is(target error) func(error) bool {
+	return func(err error) bool { return errors.Is(err, target) }
+}
+
+// errorHints is checked in order: the first match wins.
+var errorHints = []errorHint{
+	{
+		match: is(errNoPlayerDetected),
+		explain: "The player could not be guessed because no player turn file (.m1 to .m16)\n" +
+			"was given. XY, HST and H files are shared by all players.",
+		hint: "Add the player's .m file, or pick a player with --player N (1-16).",
+	},
+	{
+		match: func(err error) bool {
+			return errors.Is(err, errInvalidPlayer) || errors.Is(err, summary.ErrPlayerNotFound)
+		},
+		explain: "No player with that number was found in the loaded files.",
+		hint:    "Run 'houston settings <game>.xy' to see how many players the game has.",
+	},
+	{
+		match: is(store.ErrNoSourceForPlayer),
+		explain: "None of the loaded files belongs to that player. A player's files end in\n" +
+			"their player number: game.m3 is player 3's turn file.",
+		hint: "Check the --player value against the file extensions.",
+	},
+	{
+		match: is(errNoUniverse),
+		explain: "The planet table and game options only live in the .xy file (and the host's\n" +
+			".hst file). M and H files find their .xy when it sits next to them with the\n" +
+			"same base name.",
+		hint: "Copy the game's .xy file next to the other files, or pass it explicitly.",
+	},
+	{
+		match: is(store.ErrGameIDMismatch),
+		explain: "The files come from different games. Every file of a game carries the same\n" +
+			"game ID and they cannot be mixed.",
+		hint: "Run 'houston blocks --filter 8 <file>' on each file to compare their game IDs.",
+	},
+	{
+		match: func(err error) bool {
+			return errors.Is(err, store.ErrNoHeader) || errors.Is(err, parser.ErrNoFileHeaderFound) ||
+				errors.Is(err, blocks.ErrInvalidFileHeaderBlock)
+		},
+		explain: "This does not look like a Stars! game file: it does not start with a file header.",
+		hint:    "Check the file name; Stars! files end in .xy, .hst, .m#, .h#, .x# or .r#.",
+	},
+	{
+		match: func(err error) bool {
+			var malformed *parser.ErrMalformedBlock
+			return errors.As(err, &malformed)
+		},
+		explain: "The file ends in the middle of a block: it was truncated or corrupted, often\n" +
+			"by an interrupted download or a mail client rewrapping attachments. A valid\n" +
+			"file ends with a footer block.",
+		hint: "Fetch the file again from the host. 'houston blocks <file>' shows how far it parses;\n" +
+			"'houston compat <file>' checks it against known problem files.",
+	},
+	{
+		match: is(blocks.ErrInvalidPlayerBlock),
+		explain: "The player data decrypted to garbage. This happens when the shareware flag in\n" +
+			"the file header does not match how the file was encrypted, when the header\n" +
+			"is damaged, or when the file was edited by another tool.",
+		hint: "Run 'houston blocks --filter 8 <file>' to compare the header with a sibling file;\n" +
+			"'houston blocks --force-salt N' and the other --force options decrypt with other values,\n" +
+			"and 'houston compat <file>' gives them for known problem files.",
+	},
+	{
+		match:   is(store.ErrNotRaceFile),
+		explain: "Race files end in .r1 to .r16 and hold a single player block.",
+		hint:    "Use the race file saved by the race wizard, not a game turn file.",
+	},
+	{
+		match:   is(fs.ErrNotExist),
+		explain: "A file given on the command line does not exist.",
+		hint:    "Check the path; quote file names containing spaces.",
+	},
+}
+
+// findHint returns the explanation of a known error, or nil.
+func findHint(err error) *errorHint {
+	for i := range errorHints {
+		if errorHints[i].match(err) {
+			return &errorHints[i]
+		}
+	}
+	return nil
+}
+
+// presentError prints a command error followed by a plain-language
+// explanation and a hint when the error is a known one. Outcomes such as
+// exploits found are not printed: the command has reported them.
+func presentError(w io.Writer, err error) {
+	if code := exitCode(err); code == exitExploits || code == exitNothingToDo {
+		return
+	}
+	fmt.Fprintf(w, "Error: %v\n", err)
+	if h := findHint(err); h != nil {
+		fmt.Fprintf(w, "\n%s\n", h.explain)
+		if h.hint != "" {
+			fmt.Fprintf(w, "Hint: %s\n", h.hint)
+		}
+	}
+}
+
+// errorReport is the error written by --error-format json.
+type errorReport struct {
+	Error       string `json:"error"`
+	Class       string `json:"class"`
+	ExitCode    int    `json:"exit_code"`
+	Explanation string `json:"explanation,omitempty"`
+	Hint        string `json:"hint,omitempty"`
+}
+
+// presentErrorJSON prints a command error as a single JSON object on one
+// line, for tools reading the output of houston.
+func presentErrorJSON(w io.Writer, err error) {
+	code := exitCode(err)
+	report := errorReport{Error: err.Error(), Class: errorClass[code], ExitCode: code}
+	if h := findHint(err); h != nil {
+		report.Explanation, report.Hint = h.explain, h.hint
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false) // Hints hold <placeholders>
+	_ = enc.Encode(report)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/host"
+	"github.com/neper-stars/houston/lib/tools/summary"
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/password"
+	"github.com/neper-stars/houston/store"
+)
+
+func TestExitCode(t *testing.T) {
+	_, notExist := os.Open("does-not-exist.m1")
+	require.ErrorIs(t, notExist, fs.ErrNotExist)
+	_, badMask := password.ParseMask("?z")
+	require.Error(t, badMask)
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, exitOK},
+		{"unclassified", errors.New("boom"), exitFailure},
+		{"permission denied", fmt.Errorf("failed to open file: %w", fs.ErrPermission), exitFailure},
+		{"unknown flag", &flags.Error{Type: flags.ErrUnknownFlag, Message: "unknown flag `x'"}, exitUsage},
+		{"invalid mask", badMask, exitUsage},
+		{"invalid rule", fmt.Errorf("error reading rules: %w", password.ErrInvalidRule), exitUsage},
+		{"missing file", fmt.Errorf("failed to open file: %w", notExist), exitParse},
+		{"truncated block", fmt.Errorf("failed to parse: %w", &parser.ErrMalformedBlock{Msg: "block overruns file"}), exitParse},
+		{"no header", store.ErrNoHeader, exitParse},
+		{"not a host file", host.ErrNotHostFile, exitParse},
+		{"game ID mismatch", fmt.Errorf("game-2401.m1: %w", store.ErrGameIDMismatch), exitInvalid},
+		{"invalid player", errInvalidPlayer, exitInvalid},
+		{"checkpoint of another search", password.ErrCheckpointMismatch, exitInvalid},
+		{"exploits found", errExploitsFound, exitExploits},
+		{"nothing to do", fmt.Errorf("game.h1: %w", errNothingToDo), exitNothingToDo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exitCode(tt.err))
+		})
+	}
+}
+
+func TestPresentError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "unknown error",
+			err:  errors.New("boom"),
+			want: "Error: boom\n",
+		},
+		{
+			name: "known error",
+			err:  fmt.Errorf("loading game.m1: %w", errNoUniverse),
+			want: "Error: loading game.m1: no universe definition loaded\n\n" +
+				findHint(errNoUniverse).explain + "\n" +
+				"Hint: Copy the game's .xy file next to the other files, or pass it explicitly.\n",
+		},
+		{
+			name: "exploits found",
+			err:  errExploitsFound,
+			want: "",
+		},
+		{
+			name: "nothing to do",
+			err:  errNothingToDo,
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			presentError(&buf, tt.err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestPresentErrorJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "unknown error",
+			err:  errors.New("boom"),
+			want: `{"error":"boom","class":"error","exit_code":1}` + "\n",
+		},
+		{
+			name: "usage",
+			err:  &flags.Error{Type: flags.ErrRequired, Message: "the required flag `-o' was not specified"},
+			want: `{"error":"the required flag ` + "`-o'" + ` was not specified","class":"usage","exit_code":2}` + "\n",
+		},
+		{
+			name: "missing file",
+			err:  fmt.Errorf("failed to open file: %w", fs.ErrNotExist),
+			want: `{"error":"failed to open file: file does not exist","class":"parse","exit_code":3,` +
+				`"explanation":"A file given on the command line does not exist.",` +
+				`"hint":"Check the path; quote file names containing spaces."}` + "\n",
+		},
+		{
+			name: "validation",
+			err:  errInvalidPlayer,
+			want: `{"error":"invalid player number","class":"validation","exit_code":4,` +
+				`"explanation":"No player with that number was found in the loaded files.",` +
+				`"hint":"Run 'houston settings <game>.xy' to see how many players the game has."}` + "\n",
+		},
+		{
+			name: "exploits found",
+			err:  errExploitsFound,
+			want: `{"error":"exploits found","class":"exploits-found","exit_code":5}` + "\n",
+		},
+		{
+			name: "nothing to do",
+			err:  errNothingToDo,
+			want: `{"error":"nothing to do","class":"nothing-to-do","exit_code":6}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			presentErrorJSON(&buf, tt.err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestFindHint(t *testing.T) {
+	// A player file whose shareware flag no longer matches its encryption
+	garbled, err := os.ReadFile("../../testdata/scenario-map/history/game-2470.m1")
+	require.NoError(t, err)
+	garbled[2+15] ^= blocks.FlagCrippled
+	garbledErr := store.New().AddFile("game-2470.m1", garbled)
+	require.Error(t, garbledErr)
+
+	tests := []struct {
+		name string
+		err  error
+		want error // The error the hint is for, nil for none
+	}{
+		{"unknown error", errors.New("boom"), nil},
+		{"no player detected", errNoPlayerDetected, errNoPlayerDetected},
+		{"invalid player", fmt.Errorf("--player 9: %w", errInvalidPlayer), errInvalidPlayer},
+		{"player not in summary", summary.ErrPlayerNotFound, errInvalidPlayer},
+		{"no universe", errNoUniverse, errNoUniverse},
+		{"game ID mismatch", fmt.Errorf("game.m2: %w", store.ErrGameIDMismatch), store.ErrGameIDMismatch},
+		{"not a Stars! file", parser.ErrNoFileHeaderFound, store.ErrNoHeader},
+		{"truncated file", &parser.ErrMalformedBlock{Msg: "block overruns file"}, &parser.ErrMalformedBlock{}},
+		{"garbled player block", garbledErr, blocks.ErrInvalidPlayerBlock},
+		{"missing file", fmt.Errorf("failed to open file: %w", fs.ErrNotExist), fs.ErrNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findHint(tt.err)
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Same(t, findHint(tt.want), got)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/eventlog"
+)
+
+type eventsCommand struct {
+	Output string `short:"o" long:"output" description:"Write the log to this file instead of standard output"`
+	Append bool   `short:"a" long:"append" description:"Append to the output file instead of replacing it"`
+	Args   struct {
+		Files []string `positional-arg-name:"file" description:"M files of the players (.m1-.m16), from one or several turns" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (c *eventsCommand) Execute(args []string) error {
+	stores, err := loadTurnStores(c.Args.Files)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if c.Output != "" {
+		mode := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if c.Append {
+			mode = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		}
+		f, err := os.OpenFile(c.Output, mode, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", c.Output, err)
+		}
+		defer f.Close()
+		w = f
+	} else if c.Append {
+		return fmt.Errorf("--append requires --output")
+	}
+
+	for _, gs := range stores {
+		if err := eventlog.Write(w, eventlog.FromStore(gs)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addEventsCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("events",
+		"Write the events of generated turns as a JSON lines log",
+		"Writes the events Stars! reported to each player (battles, colonies,\n"+
+			"comet strikes, strange artifacts, mineral packets, research, scrapped\n"+
+			"fleets...) as one JSON object per line, so that reports and\n"+
+			"notifications can read them without comparing turns. Events are taken\n"+
+			"from the players' M files; give all of them for a complete log. An\n"+
+			"event seen by several players, such as a battle, is logged once for\n"+
+			"each of them.\n\n"+
+			"Example, after each turn generation:\n"+
+			"  houston events game/game.m* -o game/events.jsonl --append",
+		&eventsCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
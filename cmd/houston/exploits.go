@@ -1,46 +1,88 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/jessevdk/go-flags"
 
+	"github.com/neper-stars/houston/filenames"
 	"github.com/neper-stars/houston/lib/tools/exploits"
+	"github.com/neper-stars/houston/lib/tools/hooks"
+	"github.com/neper-stars/houston/parser"
 )
 
 type exploitsCommand struct {
-	Fix    bool   `short:"f" long:"fix" description:"Apply fixes for detected exploits"`
-	Output string `short:"o" long:"output" description:"Output file for fixed data (default: overwrite input)"`
-	Args   struct {
-		Files []string `positional-arg-name:"FILE" description:"Stars! files to scan (.m, .x, .hst)" required:"1"`
+	Fix     bool     `short:"f" long:"fix" description:"Apply fixes for detected exploits"`
+	Output  string   `short:"o" long:"output" description:"Output file for fixed data (default: overwrite input)"`
+	Hooks   []string `long:"hook" description:"Program run with each detection as JSON on stdin (repeatable)"`
+	Report  string   `long:"report" description:"Write the findings per player and turn as a JSON report"`
+	SignKey string   `long:"sign-key" description:"File holding the secret key used to sign the report"`
+	History bool     `long:"history" description:"Also compare consecutive turns of the M files (pop drops, impossible cargo) and the score history"`
+
+	ScoreZ          float64 `long:"score-z" description:"Deviations from a player's trend for a score jump to be reported with --history (default 6)"`
+	ScoreMinJump    int     `long:"score-min-jump" description:"Smallest score jump reported with --history (default 100)"`
+	ResourceMinJump int64   `long:"resource-min-jump" description:"Smallest resource jump reported with --history (default 2000)"`
+	Args            struct {
+		Files []string `positional-arg-name:"FILE" description:"Stars! files or host directories to scan (.m, .x, .hst)" required:"1"`
 	} `positional-args:"yes"`
 }
 
 func (c *exploitsCommand) Execute(args []string) error {
+	files, err := expandScanFiles(c.Args.Files)
+	if err != nil {
+		return err
+	}
+	if c.Output != "" && len(files) > 1 {
+		return fmt.Errorf("--output requires a single input file, got %d", len(files))
+	}
+	if c.SignKey != "" && c.Report == "" {
+		return fmt.Errorf("--sign-key requires --report")
+	}
+
 	// Use a shared scanner for all files so M file context is available when scanning X files
 	// This allows detecting exploits like 32k Merge that require fleet info from M files
 	scanner := exploits.NewScanner()
 
 	// First pass: scan all files to build context
 	fileData := make(map[string][]byte)
-	for _, filename := range c.Args.Files {
+	fileDetections := make(map[string][]*exploits.Detection)
+	for _, filename := range files {
 		data, err := os.ReadFile(filename)
 		if err != nil {
 			return fmt.Errorf("failed to read %s: %w", filename, err)
 		}
 		fileData[filename] = data
 
+		known := scanner.Result().Count()
 		if err := scanner.ScanFile(filename, data); err != nil {
 			return fmt.Errorf("failed to scan %s: %w", filename, err)
 		}
+		fileDetections[filename] = scanner.Result().Detections[known:]
+	}
+
+	var history []*exploits.Detection
+	if c.History {
+		if history, err = c.checkHistory(files, fileData); err != nil {
+			return err
+		}
 	}
 
 	result := scanner.Result()
+	for _, d := range history {
+		result.Add(d)
+	}
+	c.fireHooks(result)
 
 	if c.Fix {
 		// Fix mode: apply fixes to each file that has exploits
-		for _, filename := range c.Args.Files {
+		for _, filename := range files {
 			data := fileData[filename]
 			fixedData, fileResult, err := exploits.FixBytes(filename, data)
 			if err != nil {
@@ -48,6 +90,7 @@ func (c *exploitsCommand) Execute(args []string) error {
 			}
 
 			printResult(filename, fileResult)
+			markFixed(fileDetections[filename], fileResult)
 
 			if fileResult.FixableCount() > 0 || (fileResult.HasExploits() && anyFixed(fileResult)) {
 				outputFile := c.Output
@@ -61,12 +104,18 @@ func (c *exploitsCommand) Execute(args []string) error {
 				fmt.Printf("  Wrote fixed file to: %s\n", outputFile)
 			}
 		}
+		if len(history) > 0 {
+			fmt.Printf("%d exploit(s) detected across turns (not fixable):\n", len(history))
+			for _, d := range history {
+				fmt.Printf("  %d: %s\n", d.Year, d)
+			}
+		}
 	} else {
 		// Scan mode: report combined results
 		if !result.HasExploits() {
-			fmt.Printf("No exploits detected in %d file(s)\n", len(c.Args.Files))
+			fmt.Printf("No exploits detected in %d file(s)\n", len(files))
 		} else {
-			fmt.Printf("%d exploit(s) detected across %d file(s):\n", result.Count(), len(c.Args.Files))
+			fmt.Printf("%d exploit(s) detected across %d file(s):\n", result.Count(), len(files))
 			for _, d := range result.Detections {
 				fmt.Printf("  %s\n", d)
 			}
@@ -74,9 +123,190 @@ func (c *exploitsCommand) Execute(args []string) error {
 		}
 	}
 
+	if c.Report != "" {
+		if err := c.writeReport(files, fileData, fileDetections, history); err != nil {
+			return err
+		}
+	}
+	if result.HasExploits() && (!c.Fix || len(history) > 0 || !allFixed(result)) {
+		return errExploitsFound
+	}
+	return nil
+}
+
+// writeReport writes the JSON report artifact, signed when a key is given.
+func (c *exploitsCommand) writeReport(files []string, fileData map[string][]byte, fileDetections map[string][]*exploits.Detection, history []*exploits.Detection) error {
+	report := exploits.NewReport()
+	for _, filename := range files {
+		if err := report.AddFile(filename, fileData[filename], fileDetections[filename]); err != nil {
+			return err
+		}
+	}
+	report.AddDetections(history)
+	if c.SignKey != "" {
+		key, err := os.ReadFile(c.SignKey)
+		if err != nil {
+			return fmt.Errorf("failed to read signing key: %w", err)
+		}
+		key = bytes.TrimSpace(key)
+		if len(key) == 0 {
+			return fmt.Errorf("signing key %s is empty", c.SignKey)
+		}
+		if err := report.Sign(key); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	if err := os.WriteFile(c.Report, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", c.Report, err)
+	}
+	fmt.Printf("Wrote report to: %s\n", c.Report)
 	return nil
 }
 
+// expandScanFiles replaces directories by the Stars! files they contain.
+// Files from a directory are ordered with state files (M, H, HST) before
+// X files, so that orders are scanned with their context.
+func expandScanFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		dirFiles, err := findMFilesMap(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", arg, err)
+		}
+		var orders []string
+		for _, f := range dirFiles {
+			switch filenames.KindOf(f) {
+			case filenames.XY:
+				// Universe definition, nothing to scan
+			case filenames.X:
+				orders = append(orders, f)
+			default:
+				files = append(files, f)
+			}
+		}
+		files = append(files, orders...)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Stars! files found in %s", strings.Join(args, ", "))
+	}
+	return files, nil
+}
+
+// checkHistory compares consecutive turns of the M files among files, and
+// looks for score anomalies in the scores of the M and H files.
+func (c *exploitsCommand) checkHistory(files []string, fileData map[string][]byte) ([]*exploits.Detection, error) {
+	// Files of several games are checked game by game
+	var mFiles []string
+	records := make(map[uint32][]exploits.ScoreRecord)
+	for _, f := range files {
+		switch filenames.KindOf(f) {
+		case filenames.M:
+			mFiles = append(mFiles, f)
+		case filenames.H:
+			header, err := parser.FileData(fileData[f]).FileHeader()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read header of %s: %w", f, err)
+			}
+			scores, err := exploits.ScoreRecordsFromFile(fileData[f])
+			if err != nil {
+				return nil, fmt.Errorf("failed to read scores of %s: %w", f, err)
+			}
+			records[header.GameID] = append(records[header.GameID], scores...)
+		}
+	}
+	games, err := loadGameTurns(mFiles)
+	if err != nil {
+		return nil, err
+	}
+	paired := false
+	for _, turns := range games {
+		paired = paired || len(turns) >= 2
+	}
+	if !paired && len(records) == 0 {
+		fmt.Fprintf(os.Stderr, "warning: --history needs M files from at least two turns or H files\n")
+	}
+
+	thresholds := exploits.DefaultAnomalyThresholds()
+	if c.ScoreZ > 0 {
+		thresholds.ZScore = c.ScoreZ
+	}
+	if c.ScoreMinJump > 0 {
+		thresholds.MinScoreJump = c.ScoreMinJump
+	}
+	if c.ResourceMinJump > 0 {
+		thresholds.MinResourceJump = c.ResourceMinJump
+	}
+
+	ids := slices.Collect(maps.Keys(records))
+	for id := range games {
+		if _, ok := records[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	var detections []*exploits.Detection
+	for _, id := range ids {
+		turns := games[id]
+		gameRecords := append(records[id], exploits.ScoreRecordsFromStores(turns)...)
+		detections = append(detections, exploits.CheckHistory(turns).Detections...)
+		detections = append(detections, exploits.CheckScoreAnomalies(gameRecords, thresholds).Detections...)
+	}
+	return detections, nil
+}
+
+// markFixed flags the context scan detections that the fixer repaired.
+func markFixed(detections []*exploits.Detection, fixed *exploits.Result) {
+	for _, d := range detections {
+		for _, f := range fixed.Detections {
+			if f.FixApplied && f.Type == d.Type && f.Player == d.Player && f.BlockIndex == d.BlockIndex {
+				d.FixApplied = true
+			}
+		}
+	}
+}
+
+// fireHooks sends an exploit.detected event per detection to the --hook
+// programs. Hook failures are reported but do not fail the command.
+func (c *exploitsCommand) fireHooks(result *exploits.Result) {
+	if len(c.Hooks) == 0 {
+		return
+	}
+	d := hooks.NewDispatcher()
+	for _, path := range c.Hooks {
+		d.Register(&hooks.Command{Path: path})
+	}
+	for _, det := range result.Detections {
+		err := d.Fire(context.Background(), hooks.Event{
+			Type:   hooks.EventExploitDetected,
+			Player: det.Player + 1,
+			Data: map[string]any{
+				"exploit":     det.Type.String(),
+				"severity":    det.Severity.String(),
+				"description": det.Description,
+				"details":     det.Details,
+				"fixable":     det.CanFix,
+			},
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}
+}
+
 func printResult(filename string, result *exploits.Result) {
 	if !result.HasExploits() {
 		fmt.Printf("%s: No exploits detected\n", filename)
@@ -122,6 +352,16 @@ func printSummary(result *exploits.Result) {
 	}
 }
 
+// allFixed reports whether every detection was fixed.
+func allFixed(result *exploits.Result) bool {
+	for _, d := range result.Detections {
+		if !d.FixApplied {
+			return false
+		}
+	}
+	return true
+}
+
 func anyFixed(result *exploits.Result) bool {
 	for _, d := range result.Detections {
 		if d.FixApplied {
@@ -143,11 +383,30 @@ func addExploitsCommand(parser *flags.Parser) {
 			"  - SS Pop Steal: Robber Baron stealing enemy colonists\n"+
 			"  - 32k Merge: Merging fleets exceeding 32,767 ships\n"+
 			"  - Mineral Upload: Uploading minerals to enemy fleet beyond capacity\n"+
-			"  - Cheap Starbase: Editing starbase design while under construction\n\n"+
+			"  - Cheap Starbase: Editing starbase design while under construction\n"+
+			"  - Pop Drop, Impossible Cargo, Score Anomaly: Cross-turn checks (--history)\n\n"+
 			"To scan order files (X files), include the matching M file first to\n"+
 			"provide fleet/design context for detecting order-based exploits:\n"+
 			"  houston exploits game.m1 game.x1\n\n"+
-			"Use --fix to automatically apply fixes where possible.",
+			"A directory scans all its files, M and H files before X files, e.g. a\n"+
+			"whole host directory in one run:\n"+
+			"  houston exploits --fix --report report.json --sign-key league.key game/\n\n"+
+			"--history also compares consecutive turns of the M files, e.g. a game\n"+
+			"archive, for populations growing faster than colonist deliveries allow\n"+
+			"and fleets gaining cargo they could not have loaded. It also flags score\n"+
+			"and resource jumps far out of a player's trend, read from the H files\n"+
+			"and the M files; tune them with --score-z, --score-min-jump and\n"+
+			"--resource-min-jump. Such jumps are warnings: a large battle won or a\n"+
+			"gift from an ally can explain them. The files of several games, e.g. a\n"+
+			"league's host directories, are checked game by game in one run.\n\n"+
+			"--report writes the findings per player and turn as JSON with SHA-256\n"+
+			"digests of the scanned files. With --sign-key the report carries an\n"+
+			"HMAC-SHA256 signature anyone holding the key can check.\n\n"+
+			"Use --fix to automatically apply fixes where possible.\n\n"+
+			"The command exits with code 5 when exploits are found, unless --fix\n"+
+			"fixed all of them, so that CI jobs can fail on them.\n\n"+
+			"Use --hook to run a program for each detection, e.g. to notify a league\n"+
+			"channel. The program receives an exploit.detected event as JSON on stdin.",
 		&exploitsCommand{})
 	if err != nil {
 		panic(err)
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/export"
+	"github.com/neper-stars/houston/store"
+)
+
+type exportCommand struct {
+	Format string `short:"f" long:"format" description:"Output format: json or csv" default:"json"`
+	Entity string `short:"e" long:"entity" description:"Only export one kind of entity: players, planets, fleets, designs, minefields, wormholes, messages or scores"`
+	Output string `short:"o" long:"output" description:"Output file (default stdout); a directory for a CSV export of every entity"`
+	Args   struct {
+		File string `positional-arg-name:"file" description:"Stars! game file (.m, .h, .hst)" required:"true"`
+	} `positional-args:"yes"`
+}
+
+func (c *exportCommand) Execute(args []string) error {
+	format := strings.ToLower(c.Format)
+	if format != "json" && format != "csv" {
+		return fmt.Errorf("unknown format %q (want json or csv)", c.Format)
+	}
+	if format == "csv" && c.Entity == "" && c.Output == "" {
+		return errors.New("a CSV export of every entity needs --output DIR, or pick one with --entity")
+	}
+
+	ctx, err := loadGameContext(c.Args.File)
+	if err != nil {
+		return err
+	}
+	state := ctx.Store.ExportState()
+
+	if format == "csv" && c.Entity == "" {
+		if err := os.MkdirAll(c.Output, 0o755); err != nil {
+			return err
+		}
+		for _, entity := range export.Entities {
+			path := filepath.Join(c.Output, entity+".csv")
+			if err := writeOutput(path, func(w io.Writer) error { return exportCSV(w, state, entity) }); err != nil {
+				return err
+			}
+			fmt.Printf("Wrote %s\n", path)
+		}
+		return nil
+	}
+
+	return writeOutput(c.Output, func(w io.Writer) error {
+		if format == "csv" {
+			return exportCSV(w, state, c.Entity)
+		}
+		var v any = state
+		if c.Entity != "" {
+			if v, err = export.Select(state, c.Entity); err != nil {
+				return err
+			}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	})
+}
+
+func exportCSV(w io.Writer, state *store.GameState, entity string) error {
+	table, err := export.NewTable(state, entity)
+	if err != nil {
+		return err
+	}
+	return table.WriteCSV(w)
+}
+
+// writeOutput runs write on the named file, or on stdout when name is empty.
+func writeOutput(name string, write func(w io.Writer) error) error {
+	if name == "" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func addExportCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("export",
+		"Export the game data as JSON or CSV",
+		"Dumps the players, planets, fleets, designs, minefields, wormholes,\n"+
+			"messages and scores of a game file for scripts and spreadsheets.\n\n"+
+			"JSON holds the whole game state (see store.GameState), or the list of one\n"+
+			"kind of entity with --entity. CSV writes one table per kind of entity:\n"+
+			"nested values are flattened (tech_energy) and lists are written as JSON in\n"+
+			"a single cell. Players are numbered from 0; unowned planets have owner -1.\n\n"+
+			"The universe and history files and the turn files of other players of\n"+
+			"the same year found next to the file are exported too.\n\n"+
+			"Examples:\n"+
+			"  houston export game.m1 -o game.json\n"+
+			"  houston export game.m1 -f csv -e planets -o planets.csv\n"+
+			"  houston export game.hst -f csv -o export/",
+		&exportCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
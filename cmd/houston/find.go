@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/query"
+	"github.com/neper-stars/houston/lib/tools/report"
+)
+
+type findCommand struct {
+	Format string `short:"f" long:"format" description:"Output format: text, markdown, html or json" default:"text"`
+	Args   struct {
+		File  string `positional-arg-name:"file" description:"Stars! game file (.m, .h, .hst)" required:"true"`
+		Query string `positional-arg-name:"query" description:"Expression, e.g. 'planet.owner==2 && planet.population>200000'" required:"true"`
+	} `positional-args:"yes"`
+}
+
+func (c *findCommand) Execute(args []string) error {
+	q, err := query.Parse(c.Args.Query)
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	ctx, err := loadGameContext(c.Args.File)
+	if err != nil {
+		return err
+	}
+	gs := ctx.Store
+
+	if strings.EqualFold(c.Format, "json") {
+		records := q.Find(gs)
+		if records == nil {
+			records = []query.Record{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+
+	doc := &report.Document{
+		Title:    fmt.Sprintf("Search: %s", q),
+		Subtitle: c.Args.File,
+	}
+	if q.Kind == query.KindFleet {
+		fleets := q.Fleets(gs)
+		section := doc.AddSection(fmt.Sprintf("Fleets (%d)", len(fleets)))
+		table := section.AddTable(
+			report.Column{Header: "Fleet"},
+			report.Column{Header: "Owner", Numeric: true},
+			report.Column{Header: "X", Numeric: true},
+			report.Column{Header: "Y", Numeric: true},
+			report.Column{Header: "Ships", Numeric: true},
+			report.Column{Header: "Warp", Numeric: true},
+			report.Column{Header: "Design"},
+		)
+		for _, f := range fleets {
+			design := ""
+			if f.PrimaryDesign != nil {
+				design = f.PrimaryDesign.Name
+			}
+			table.AddRow(f.Name(), strconv.Itoa(f.Owner+1), strconv.Itoa(f.X), strconv.Itoa(f.Y),
+				strconv.Itoa(f.TotalShips()), strconv.Itoa(f.Warp), design)
+		}
+	} else {
+		planets := q.Planets(gs)
+		section := doc.AddSection(fmt.Sprintf("Planets (%d)", len(planets)))
+		table := section.AddTable(
+			report.Column{Header: "#", Numeric: true},
+			report.Column{Header: "Planet"},
+			report.Column{Header: "Owner", Numeric: true},
+			report.Column{Header: "X", Numeric: true},
+			report.Column{Header: "Y", Numeric: true},
+			report.Column{Header: "Population", Numeric: true},
+			report.Column{Header: "Mines", Numeric: true},
+			report.Column{Header: "Factories", Numeric: true},
+			report.Column{Header: "Defenses", Numeric: true},
+			report.Column{Header: "Starbase"},
+		)
+		for _, p := range planets {
+			owner, starbase := "-", ""
+			if p.Owner >= 0 {
+				owner = strconv.Itoa(p.Owner + 1)
+			}
+			if p.HasStarbase {
+				starbase = "yes"
+			}
+			table.AddRow(strconv.Itoa(p.PlanetNumber), p.Name, owner, strconv.Itoa(p.X), strconv.Itoa(p.Y),
+				itoa64(p.Population), strconv.Itoa(p.Mines), strconv.Itoa(p.Factories), strconv.Itoa(p.Defenses), starbase)
+		}
+	}
+	return renderReport(c.Format, doc)
+}
+
+// fieldHelp lists the query fields of a kind for the command help.
+func fieldHelp(kind query.Kind) string {
+	var b strings.Builder
+	for _, f := range query.Fields(kind) {
+		fmt.Fprintf(&b, "  %s.%-16s %s\n", kind, f.Name, f.Doc)
+	}
+	return b.String()
+}
+
+func addFindCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("find",
+		"Search planets or fleets with an expression",
+		"Lists the planets or fleets of a game file matching an expression, as a\n"+
+			"table or as JSON with every field. Expressions use planet.* or fleet.*\n"+
+			"fields, numbers, \"strings\", true and false with || && ! == != < <= > >=\n"+
+			"+ - * / % and ~ (case-insensitive substring match). Owners are player\n"+
+			"numbers (1-16, 0 for unowned planets).\n\n"+
+			"The universe and history files and the turn files of other players of\n"+
+			"the same year found next to the file are searched too.\n\n"+
+			"Examples:\n"+
+			"  houston find game.m1 'planet.owner==2 && planet.population>200000'\n"+
+			"  houston find game.m1 'fleet.ships >= 10 && !fleet.moving' -f json\n\n"+
+			"Planet fields:\n"+fieldHelp(query.KindPlanet)+"\n"+
+			"Fleet fields:\n"+fieldHelp(query.KindFleet),
+		&findCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
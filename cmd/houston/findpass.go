@@ -1,23 +1,36 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"os/signal"
 	"runtime"
+	"slices"
 	"time"
 
 	"github.com/jessevdk/go-flags"
 
 	hs "github.com/neper-stars/houston"
+	"github.com/neper-stars/houston/password"
 )
 
 type findpassCommand struct {
-	MaxLength int    `short:"l" long:"length" description:"Maximum password length to try" default:"8"`
-	Charset   string `short:"c" long:"charset" description:"Characters to use for brute force" default:"abcdefghijklmnopqrstuvwxyz"`
-	Matches   int    `short:"m" long:"matches" description:"Stop after this many matches" default:"1"`
-	Workers   int    `short:"w" long:"workers" description:"Number of parallel workers (0 = all CPUs)" default:"0"`
-	Progress  bool   `short:"p" long:"progress" description:"Show progress while searching"`
-	Args      struct {
+	MaxLength       int           `short:"l" long:"length" description:"Maximum password length to try" default:"8"`
+	Charset         string        `short:"c" long:"charset" description:"Characters to use for brute force" default:"abcdefghijklmnopqrstuvwxyz"`
+	Matches         int           `short:"m" long:"matches" description:"Stop after this many matches" default:"1"`
+	Workers         int           `short:"w" long:"workers" description:"Number of parallel workers (0 = all CPUs)" default:"0"`
+	Mask            string        `long:"mask" description:"Hashcat-style mask of the passwords to try (?l ?u ?d ?s ?a), or of the suffixes of the words"`
+	Wordlist        string        `long:"wordlist" description:"File of words to try, one per line"`
+	Rules           string        `long:"rules" description:"File of hashcat-style rules mutating the words, one per line"`
+	Rule            []string      `short:"r" long:"rule" description:"Hashcat-style rule mutating the words (repeatable)"`
+	Progress        bool          `short:"p" long:"progress" description:"Show progress while searching"`
+	Checkpoint      string        `long:"checkpoint" description:"File saving the state of the search, resumed from when it exists"`
+	CheckpointEvery time.Duration `long:"checkpoint-every" description:"Interval between two saves of the checkpoint file" default:"1m"`
+	Args            struct {
 		File string `positional-arg-name:"file" description:"Stars! file containing player data" required:"true"`
 	} `positional-args:"yes"`
 }
@@ -38,6 +51,19 @@ func (c *findpassCommand) Execute(args []string) error {
 		workers = runtime.NumCPU()
 	}
 
+	search, err := c.searchOptions()
+	if err != nil {
+		return err
+	}
+	space, err := search.Space()
+	if err != nil {
+		return err
+	}
+
+	// Ctrl-C stops the search, saving the checkpoint
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	for _, b := range bl {
 		if b.BlockTypeID() != hs.PlayerBlockType {
 			continue
@@ -53,38 +79,47 @@ func (c *findpassCommand) Execute(args []string) error {
 			continue
 		}
 
+		hash := pb.HashedPass().Uint32()
 		fmt.Printf("Player Block found: %s\n", pb.NameSingular)
-		fmt.Printf("Hashed password: %d\n", pb.HashedPass().Uint32())
+		fmt.Printf("Hashed password: %d\n", hash)
 		fmt.Printf("Using %d workers\n", workers)
 
-		// Progress callback
-		var progressCb hs.ProgressCallback
-		var lastUpdate time.Time
+		opts := search
+		opts.Workers = workers
+		var bar *progressBar
 		if c.Progress {
-			lastUpdate = time.Now()
-			progressCb = func(tried uint64) {
-				now := time.Now()
-				if now.Sub(lastUpdate) >= 500*time.Millisecond {
-					fmt.Printf("\rTried: %d passwords...", tried)
-					lastUpdate = now
+			bar = newProgressBar("Tried", space)
+			opts.Progress = bar.Update
+		}
+		if c.Checkpoint != "" {
+			if opts.Resume, err = readCheckpoint(c.Checkpoint, hash); err != nil {
+				return err
+			}
+			if opts.Resume != nil {
+				fmt.Printf("Resuming from %s: %d passwords tried\n", c.Checkpoint, opts.Resume.Next)
+			}
+			opts.OnCheckpoint = func(cp password.Checkpoint) {
+				if err := writeCheckpoint(c.Checkpoint, cp); err != nil {
+					fmt.Fprintf(os.Stderr, "warning: %v\n", err)
 				}
 			}
 		}
 
 		start := time.Now()
-		matches := hs.GuessRacePasswordParallel(
-			pb.HashedPass().Uint32(),
-			c.MaxLength,
-			c.Matches,
-			c.Charset,
-			workers,
-			progressCb,
-		)
+		cp, err := password.Search(ctx, hash, opts)
 		elapsed := time.Since(start)
-
-		if c.Progress {
-			fmt.Println() // newline after progress
+		if bar != nil {
+			bar.Finish()
+		}
+		if errors.Is(err, context.Canceled) {
+			fmt.Printf("Interrupted after %d passwords\n", cp.Next)
+			if c.Checkpoint != "" {
+				fmt.Printf("Resume with --checkpoint %s\n", c.Checkpoint)
+			}
+		} else if err != nil {
+			return err
 		}
+		matches := cp.Matches
 
 		if len(matches) > 0 {
 			fmt.Println("Found passwords:")
@@ -95,17 +130,138 @@ func (c *findpassCommand) Execute(args []string) error {
 			fmt.Println("No passwords found")
 		}
 		fmt.Printf("Time: %v\n", elapsed)
+		if ctx.Err() != nil {
+			return fmt.Errorf("search interrupted: %w", ctx.Err())
+		}
 	}
 
 	return nil
 }
 
+// searchOptions returns the options of the search the flags select: a
+// wordlist, a mask or brute force.
+func (c *findpassCommand) searchOptions() (password.SearchOptions, error) {
+	opts := password.SearchOptions{
+		Charset:            c.Charset,
+		MaxLength:          c.MaxLength,
+		Mask:               c.Mask,
+		Matches:            c.Matches,
+		CheckpointInterval: c.CheckpointEvery,
+	}
+	if c.Wordlist == "" {
+		if c.Rules != "" || len(c.Rule) > 0 {
+			return opts, errors.New("rules need a --wordlist")
+		}
+		return opts, nil
+	}
+
+	f, err := os.Open(c.Wordlist)
+	if err != nil {
+		return opts, fmt.Errorf("error reading wordlist: %w", err)
+	}
+	defer f.Close()
+	if opts.Words, err = password.ReadWords(f); err != nil {
+		return opts, fmt.Errorf("error reading wordlist: %w", err)
+	}
+	if c.Rules != "" {
+		f, err := os.Open(c.Rules)
+		if err != nil {
+			return opts, fmt.Errorf("error reading rules: %w", err)
+		}
+		defer f.Close()
+		if opts.Rules, err = password.ParseRules(f); err != nil {
+			return opts, fmt.Errorf("error reading rules: %w", err)
+		}
+	}
+	for _, r := range c.Rule {
+		rule, err := password.ParseRule(r)
+		if err != nil {
+			return opts, err
+		}
+		opts.Rules = append(opts.Rules, rule)
+	}
+	return opts, nil
+}
+
+// readCheckpoint reads the state of the search of a hash from a checkpoint
+// file, which holds one per hash searched. It returns nil when there is
+// none.
+func readCheckpoint(path string, hash uint32) (*password.Checkpoint, error) {
+	checkpoints, err := readCheckpoints(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range checkpoints {
+		if checkpoints[i].Hash == hash {
+			return &checkpoints[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func readCheckpoints(path string) ([]password.Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading checkpoint: %w", err)
+	}
+	var checkpoints []password.Checkpoint
+	if err := json.Unmarshal(data, &checkpoints); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %s: %w", path, err)
+	}
+	return checkpoints, nil
+}
+
+// writeCheckpoint saves the state of a search in a checkpoint file,
+// replacing the previous state of the same hash. The file is replaced
+// atomically so that a crash never leaves it half written.
+func writeCheckpoint(path string, cp password.Checkpoint) error {
+	checkpoints, err := readCheckpoints(path)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(checkpoints, func(c password.Checkpoint) bool { return c.Hash == cp.Hash })
+	if i < 0 {
+		checkpoints = append(checkpoints, cp)
+	} else {
+		checkpoints[i] = cp
+	}
+	data, err := json.MarshalIndent(checkpoints, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("error writing checkpoint: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
 func addFindPassCommand(parser *flags.Parser) {
 	_, err := parser.AddCommand("findpass",
 		"Find race passwords by brute force",
 		"Attempts to find race passwords by brute force hashing.\n\n"+
 			"Because the hashing algorithm is weak, this will often find\n"+
-			"alternative strings that work instead of the original password.",
+			"alternative strings that work instead of the original password.\n\n"+
+			"By default every password of --charset up to --length characters is\n"+
+			"tried, shortest first. --mask tries the passwords of a hashcat-style\n"+
+			"mask instead: ?l, ?u, ?d and ?s stand for a lowercase letter, an\n"+
+			"uppercase letter, a digit and a special character, ?a for any of them.\n"+
+			"--wordlist tries the words of a file, each mutated by every rule of\n"+
+			"--rules and --rule (hashcat syntax: c capitalizes, $1 appends 1...) and,\n"+
+			"with --mask, completed by every password of the mask.\n\n"+
+			"The search runs on all CPU cores. For searches lasting days,\n"+
+			"--checkpoint saves the state of the search to a file periodically and\n"+
+			"when interrupted with Ctrl-C; running the same command again resumes\n"+
+			"from it. The length of a brute force search can be raised when\n"+
+			"resuming.\n\n"+
+			"Examples:\n"+
+			"  houston findpass race.r1 -l 6 -p\n"+
+			"  houston findpass race.r1 --mask '?u?l?l?l?l?d?d'\n"+
+			"  houston findpass race.r1 --wordlist words.txt -r : -r c -r u --mask '?d?d'\n"+
+			"  houston findpass race.r1 -l 10 -c abcdefghijklmnopqrstuvwxyz0123456789 --checkpoint race.search",
 		&findpassCommand{})
 	if err != nil {
 		panic(err)
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/filenames"
+	"github.com/neper-stars/houston/lib/tools/namefixer"
+)
+
+type fixNameCommand struct {
+	Rewrite  bool `short:"w" long:"rewrite" description:"Rewrite the header for the player of the file name instead of renaming the file"`
+	DryRun   bool `long:"dry-run" description:"Report misnamed files without changing them"`
+	NoBackup bool `short:"n" long:"no-backup" description:"Don't create backup file when rewriting"`
+	Args     struct {
+		Files []string `positional-arg-name:"file" description:"Turn, orders or history files (.mN, .xN, .hN)" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (c *fixNameCommand) Execute(args []string) error {
+	failed := 0
+	for _, filename := range c.Args.Files {
+		if err := c.fix(filename); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", filename, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files could not be fixed", failed, len(c.Args.Files))
+	}
+	return nil
+}
+
+func (c *fixNameCommand) fix(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	m, err := namefixer.CheckBytes(filename, data)
+	if errors.Is(err, namefixer.ErrNotPlayerFile) {
+		// Universe, host and race files are not named by player
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		fmt.Printf("%s: ok\n", filename)
+		return nil
+	}
+	fmt.Println(m)
+
+	if !c.Rewrite {
+		target := m.RenamedPath()
+		if existing, ok := filenames.Find(target); ok {
+			return fmt.Errorf("cannot rename to %s: %s exists (use --rewrite to fix the header instead)", target, existing)
+		}
+		if c.DryRun {
+			fmt.Printf("  would rename to %s\n", target)
+			return nil
+		}
+		if err := os.Rename(filename, target); err != nil {
+			return err
+		}
+		fmt.Printf("  renamed to %s\n", target)
+		return nil
+	}
+
+	rewritten, err := namefixer.RewriteBytes(data, m.NamePlayer)
+	if err != nil {
+		return err
+	}
+	if c.DryRun {
+		fmt.Printf("  would rewrite the header for player %d\n", m.NamePlayer)
+		return nil
+	}
+	if !c.NoBackup {
+		backupFile := filename + ".backup"
+		if err := os.WriteFile(backupFile, data, 0644); err != nil {
+			return fmt.Errorf("error creating backup: %w", err)
+		}
+		fmt.Printf("  created backup: %s\n", backupFile)
+	}
+	if err := os.WriteFile(filename, rewritten, 0644); err != nil {
+		return fmt.Errorf("error writing rewritten file: %w", err)
+	}
+	fmt.Printf("  rewrote the header for player %d\n", m.NamePlayer)
+	return nil
+}
+
+func addFixNameCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("fix-name",
+		"Fix files named after the wrong player",
+		"Checks that the player number in the name of turn, orders and history\n"+
+			"files matches the player in their header. Stars! goes by the header:\n"+
+			"a game.m2 copied to game.m1 is opened as player 2's turn.\n\n"+
+			"Misnamed files are renamed after the player of their header. With\n"+
+			"--rewrite, the header is rewritten for the player of the name instead\n"+
+			"and the file re-encrypted; a backup is created unless --no-backup is\n"+
+			"specified. Only do this when the name is right and the header wrong.\n\n"+
+			"Other commands warn about misnamed files as they load them.\n\n"+
+			"Examples:\n"+
+			"  houston fix-name --dry-run *.m* *.x* *.h*\n"+
+			"  houston fix-name game.m1",
+		&fixNameCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/neper-stars/houston/gamectx"
+)
+
+// loadGameContext loads a game file with the universe, history and turn
+// files of the same game and year found next to it (see gamectx).
+func loadGameContext(filename string) (*gamectx.Context, error) {
+	cache, err := parseCache()
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := gamectx.LoadWithOptions(filename, &gamectx.Options{FS: gameFS(), Cache: cache})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", filename, err)
+	}
+	printStoreWarnings(ctx.Store)
+	return ctx, nil
+}
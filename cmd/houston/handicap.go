@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/handicap"
+	"github.com/neper-stars/houston/store"
+)
+
+type handicapCommand struct {
+	DryRun   bool `short:"d" long:"dry-run" description:"Show the changes without writing the file"`
+	NoBackup bool `short:"n" long:"no-backup" description:"Don't create backup file"`
+	Args     struct {
+		File     string `positional-arg-name:"file" description:"Host file of the new game (.hst)" required:"true"`
+		Manifest string `positional-arg-name:"manifest" description:"JSON manifest of the handicaps" required:"true"`
+	} `positional-args:"yes"`
+}
+
+func (c *handicapCommand) Execute(args []string) error {
+	m, err := handicap.LoadManifest(c.Args.Manifest)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(c.Args.File)
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+
+	modified, changes, err := handicap.ApplyBytes(data, m)
+	if err != nil {
+		return err
+	}
+
+	for _, ch := range changes {
+		home := ch.Homeworld
+		if home == "" {
+			home = fmt.Sprintf("#%d", ch.Planet)
+		}
+		fmt.Printf("Player %d (%s), homeworld %s:\n", ch.Player+1, ch.Name, home)
+		if ch.Minerals > 0 {
+			fmt.Printf("  minerals   +%d kT of each\n", ch.Minerals)
+		}
+		if ch.TechAfter != ch.TechBefore {
+			fmt.Printf("  tech       %s -> %s\n", formatTech(ch.TechBefore), formatTech(ch.TechAfter))
+		}
+		if ch.PopulationAfter != ch.PopulationBefore {
+			fmt.Printf("  population %d -> %d\n", ch.PopulationBefore, ch.PopulationAfter)
+		}
+		if ch.Note != "" {
+			fmt.Printf("  note       %s\n", ch.Note)
+		}
+	}
+
+	if c.DryRun {
+		fmt.Println("\nDry run, file not modified.")
+		return nil
+	}
+
+	if !c.NoBackup {
+		backupFile := c.Args.File + ".backup"
+		if err := copyFilePlayer(c.Args.File, backupFile); err != nil {
+			return fmt.Errorf("error creating backup: %w", err)
+		}
+		fmt.Printf("\nCreated backup: %s\n", backupFile)
+	}
+	if err := os.WriteFile(c.Args.File, modified, 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	fmt.Println("File updated successfully.")
+	return nil
+}
+
+// formatTech formats tech levels in the order of the research screen.
+func formatTech(t store.TechLevels) string {
+	return fmt.Sprintf("En %d We %d Pr %d Co %d El %d Bi %d",
+		t.Energy, t.Weapons, t.Propulsion, t.Construction, t.Electronics, t.Biotech)
+}
+
+func addHandicapCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("handicap",
+		"Apply per-player handicaps to a new game",
+		"Applies the handicaps of a JSON manifest to the host file Stars! writes\n"+
+			"for the first year of a game, before the first turn is generated.\n"+
+			"Each player can be given extra minerals on the homeworld, extra levels\n"+
+			"in every tech field, or a reduced starting population:\n\n"+
+			"  {\"game_id\": 1234567, \"players\": [\n"+
+			"    {\"player\": 1, \"minerals\": 200, \"tech\": 1, \"note\": \"newcomer\"},\n"+
+			"    {\"player\": 4, \"population_percent\": 20}\n"+
+			"  ]}\n\n"+
+			"Keep the manifest with the game as the record of what each player was\n"+
+			"given. A backup of the original file will be created unless\n"+
+			"--no-backup is specified.\n\n"+
+			"Example:\n"+
+			"  houston handicap game.hst handicaps.json --dry-run",
+		&handicapCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/filenames"
+	"github.com/neper-stars/houston/lib/tools/host"
+	"github.com/neper-stars/houston/parser"
+)
+
+type hostCommand struct{}
+
+type hostGenerateCommand struct {
+	NoBackup bool `short:"n" long:"no-backup" description:"Don't create backup files"`
+	Args     struct {
+		File string `positional-arg-name:"file" description:"Host file (.hst)" required:"true"`
+	} `positional-args:"yes"`
+}
+
+// readHostGame reads a host file and the universe, X and M files next to
+// it, returning the paths of the M files by player. The X files found are
+// listed on w.
+func readHostGame(w io.Writer, file string) (host.Game, map[int]string, error) {
+	if filenames.KindOf(file) != filenames.HST {
+		return host.Game{}, nil, fmt.Errorf("%s does not appear to be a host file", file)
+	}
+	hst, err := os.ReadFile(file)
+	if err != nil {
+		return host.Game{}, nil, fmt.Errorf("error reading file: %w", err)
+	}
+	game := host.Game{HST: hst, MFiles: make(map[int][]byte)}
+	if path, ok := filenames.Find(filenames.Companion(file, filenames.XY, 0)); ok {
+		if game.XY, err = os.ReadFile(path); err != nil {
+			return host.Game{}, nil, fmt.Errorf("error reading file: %w", err)
+		}
+	}
+
+	mPaths := make(map[int]string)
+	for player := 1; player <= 16; player++ {
+		if path, ok := filenames.Find(filenames.Companion(file, filenames.X, player)); ok {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return host.Game{}, nil, fmt.Errorf("error reading file: %w", err)
+			}
+			game.Orders = append(game.Orders, data)
+			fmt.Fprintf(w, "Orders: %s\n", path)
+		}
+		if path, ok := filenames.Find(filenames.Companion(file, filenames.M, player)); ok {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return host.Game{}, nil, fmt.Errorf("error reading file: %w", err)
+			}
+			game.MFiles[player-1] = data
+			mPaths[player-1] = path
+		}
+	}
+	return game, mPaths, nil
+}
+
+func (c *hostGenerateCommand) Execute(args []string) error {
+	game, mPaths, err := readHostGame(os.Stdout, c.Args.File)
+	if err != nil {
+		return err
+	}
+	hst := game.HST
+
+	result, err := host.Generate(game)
+	if err != nil {
+		return fmt.Errorf("failed to generate the turn: %w", err)
+	}
+	for _, event := range result.Events {
+		fmt.Printf("  %s\n", event)
+	}
+
+	if err := c.write(c.Args.File, hst, result.HST); err != nil {
+		return err
+	}
+	for player, data := range result.MFiles {
+		if err := c.write(mPaths[player], game.MFiles[player], data); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Generated year %d.\n", result.Year)
+
+	fmt.Println("Phases skipped:")
+	for _, phase := range host.Phases {
+		if !phase.Supported {
+			fmt.Printf("  %s\n", phase.Name)
+		}
+	}
+	return nil
+}
+
+// write replaces a game file, keeping a backup of the old one.
+func (c *hostGenerateCommand) write(path string, old, data []byte) error {
+	if !c.NoBackup {
+		if err := os.WriteFile(path+".backup", old, 0644); err != nil {
+			return fmt.Errorf("error creating backup: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	fmt.Printf("Wrote %s\n", path)
+	return nil
+}
+
+type hostVerifyCommand struct {
+	Expected   string `short:"e" long:"expected" description:"Directory of the files Stars! generated from the same files" required:"true"`
+	Format     string `short:"f" long:"format" description:"Output format: text or json" default:"text"`
+	Mismatches int    `short:"m" long:"mismatches" default:"20" description:"Mismatches listed in text output (0 for all)"`
+	Args       struct {
+		File string `positional-arg-name:"file" description:"Host file (.hst)" required:"true"`
+	} `positional-args:"yes"`
+}
+
+func (c *hostVerifyCommand) Execute(args []string) error {
+	game, _, err := readHostGame(os.Stderr, c.Args.File)
+	if err != nil {
+		return err
+	}
+	expectedPath := filepath.Join(c.Expected, filepath.Base(c.Args.File))
+	expected, err := os.ReadFile(expectedPath)
+	if err != nil {
+		return fmt.Errorf("error reading expected file: %w", err)
+	}
+	report, err := host.Verify(game, expected)
+	if err != nil {
+		return fmt.Errorf("failed to verify the turn: %w", err)
+	}
+
+	if strings.EqualFold(c.Format, "json") {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	fmt.Printf("Year %d against %s:\n", report.Year, expectedPath)
+	for _, f := range report.Fields {
+		fmt.Printf("  %-22s %5d/%-5d %6.1f%%\n", f.Name, f.Matched, f.Compared, 100*f.Accuracy())
+	}
+	fmt.Printf("Overall: %.1f%%\n", 100*report.Accuracy())
+	if len(report.Mismatches) > 0 {
+		fmt.Println("\nMismatches:")
+		for i, m := range report.Mismatches {
+			if c.Mismatches > 0 && i == c.Mismatches {
+				fmt.Printf("  ... and %d more\n", len(report.Mismatches)-i)
+				break
+			}
+			fmt.Printf("  %s\n", m)
+		}
+	}
+	return nil
+}
+
+type hostForkCommand struct {
+	At     int    `long:"at" description:"Year of the turn to fork" required:"true"`
+	Out    string `short:"o" long:"out" description:"Directory of the forked game" required:"true"`
+	Game   uint32 `short:"g" long:"game" description:"Only fork the game with this ID, for archives holding several games"`
+	GameID uint32 `long:"game-id" description:"Game ID of the fork (random if not specified)"`
+	Args   struct {
+		Dir string `positional-arg-name:"archive" description:"Directory holding the game files, searched recursively"`
+	} `positional-args:"yes"`
+}
+
+func (c *hostForkCommand) Execute(args []string) error {
+	dir := c.Args.Dir
+	if dir == "" {
+		dir = "."
+	}
+	files, err := c.findTurn(dir)
+	if err != nil {
+		return err
+	}
+
+	gameID := c.GameID
+	if gameID == 0 {
+		gameID = host.NewGameID()
+	}
+	if err := os.MkdirAll(c.Out, 0755); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		out := filepath.Join(c.Out, name)
+		if _, err := os.Stat(out); err == nil {
+			return fmt.Errorf("%s already exists", out)
+		}
+		data, err := os.ReadFile(files[name])
+		if err != nil {
+			return fmt.Errorf("error reading file: %w", err)
+		}
+		forked, err := host.ForkFile(data, gameID)
+		if err != nil {
+			return fmt.Errorf("failed to fork %s: %w", files[name], err)
+		}
+		if err := os.WriteFile(out, forked, 0644); err != nil {
+			return fmt.Errorf("error writing file: %w", err)
+		}
+		fmt.Printf("Wrote %s (from %s)\n", out, files[name])
+	}
+	fmt.Printf("Forked %d into game %d\n", c.At, gameID)
+	return nil
+}
+
+// findTurn returns the paths of the files of the game in year c.At, and
+// of its universe, by file name. A name found several times is taken from
+// the first directory holding it.
+func (c *hostForkCommand) findTurn(dir string) (map[string]string, error) {
+	type gameFile struct {
+		path   string
+		header *blocks.FileHeader
+	}
+	var found []gameFile
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !isStarsGameFile(d.Name()) {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		header, err := parser.FileData(data).FileHeader()
+		if err != nil {
+			return nil
+		}
+		if c.Game == 0 || header.GameID == c.Game {
+			found = append(found, gameFile{path, header})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	files := make(map[string]string)
+	games := make(map[uint32]bool)
+	for _, f := range found {
+		if filenames.KindOf(f.path) == filenames.XY || f.header.Year() != c.At {
+			continue
+		}
+		games[f.header.GameID] = true
+		if _, ok := files[filepath.Base(f.path)]; !ok {
+			files[filepath.Base(f.path)] = f.path
+		}
+	}
+	switch {
+	case len(games) == 0:
+		return nil, fmt.Errorf("no files of %d found in %s", c.At, dir)
+	case len(games) > 1:
+		return nil, fmt.Errorf("files of %d games found for %d, choose one with --game", len(games), c.At)
+	}
+	for _, f := range found {
+		if filenames.KindOf(f.path) == filenames.XY && games[f.header.GameID] {
+			if _, ok := files[filepath.Base(f.path)]; !ok {
+				files[filepath.Base(f.path)] = f.path
+			}
+		}
+	}
+	return files, nil
+}
+
+type hostPhasesCommand struct{}
+
+func (c *hostPhasesCommand) Execute(args []string) error {
+	for _, phase := range host.Phases {
+		status := "skipped"
+		if phase.Supported {
+			status = "run"
+		}
+		fmt.Printf("%-22s %-8s %s\n", phase.Name, status, phase.Notes)
+	}
+	return nil
+}
+
+func addHostCommand(parser *flags.Parser) {
+	cmd, err := parser.AddCommand("host",
+		"Generate turns without Stars!",
+		"Commands for hosting games headlessly, without the Stars! executable.\n"+
+			"The turn generation engine is partial: 'houston host phases' lists\n"+
+			"what it runs and what it leaves out, and 'houston host verify'\n"+
+			"measures how close it comes to Stars!.",
+		&hostCommand{})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = cmd.AddCommand("generate",
+		"Generate the next turn",
+		"Generates the next turn of a game from its host file and the X files\n"+
+			"next to it, and rewrites the host file and the M files of the players.\n"+
+			"Players without an X file keep last year's orders.\n\n"+
+			"Backups of the rewritten files are created unless --no-backup is\n"+
+			"specified.\n\n"+
+			"Example:\n"+
+			"  houston host generate game.hst",
+		&hostGenerateCommand{})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = cmd.AddCommand("verify",
+		"Compare a generated turn with the one of Stars!",
+		"Generates the next turn of a game like 'houston host generate', without\n"+
+			"writing anything, and compares the host file with the one Stars!\n"+
+			"generated from the same files, found under the same name in the\n"+
+			"--expected directory. The report gives the share of values the engine\n"+
+			"got right for each field of the players, planets and fleets, and lists\n"+
+			"the values it got wrong; -f json writes it for tracking the engine's\n"+
+			"accuracy over time.\n\n"+
+			"Example:\n"+
+			"  houston host verify game.hst --expected stars-generated/",
+		&hostVerifyCommand{})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = cmd.AddCommand("fork",
+		"Clone a game at a given year into a sandbox",
+		"Copies the files of a game in a given year, found in an archive directory\n"+
+			"and its subdirectories, with its universe file into a sandbox directory,\n"+
+			"as a new game: the files are given a new game ID, random unless\n"+
+			"--game-id is specified, and the passwords of the human players are\n"+
+			"reset. Hosts and players can try out what-if scenarios in the sandbox,\n"+
+			"generating turns there, without touching the live game. Race files are\n"+
+			"not copied.\n\n"+
+			"Files already in the sandbox directory are never overwritten.\n\n"+
+			"Example:\n"+
+			"  houston host fork --at 2445 --out sandbox/ archive/",
+		&hostForkCommand{})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = cmd.AddCommand("phases",
+		"List the phases of a turn",
+		"Lists the phases of a turn generation and whether the engine runs them.",
+		&hostPhasesCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -7,16 +7,62 @@
 // Commands:
 //
 //	blocks     Display blocks in a Stars! file
-//	xfile      Read and validate X (turn order) files
+//	xfile      Read, validate and diff X (turn order) files
 //	findpass   Find race passwords by brute force
 //	race       Fix corrupted race files
-//	race-password  Remove password from race files
+//	race-password  Remove or set the password of race files
 //	player     View and modify player attributes
 //	merge-m    Merge M files between allied players
 //	merge-h    Merge H (history) files
+//	prune-h    Shrink H (history) files
 //	map        Render galaxy maps as PNG or animated GIF
 //	exploits   Detect and fix known exploits
 //	report     Generate analysis report as ODS spreadsheet
+//	warfront   Track contested systems and front lines across turns
+//	threats    Predict enemy fleet arrivals and rank planets by threat
+//	starbases  Suggest starbase design upgrades
+//	designs    Inspect ship and starbase designs (diff)
+//	summary    Print an overview of your empire
+//	research   Plan research and list the next tech breakpoints
+//	settings   Print the game setup options
+//	minefields Report expected damage of minefield detonations
+//	publish    Generate a static website for a game archive
+//	thumbnail  Render small map thumbnails per turn for forum embedding
+//	find       Search planets or fleets with an expression
+//	review     Review and approve submitted orders
+//	trader     Track Mystery Trader encounters and items received
+//	balance    Score the fairness of starting positions
+//	handicap   Apply per-player handicaps to a new game
+//	planet-names  Rename the planets of a new game from a dictionary
+//	events     Write the events of generated turns as a JSON lines log
+//	replay     Tell the story of a game from its archive
+//	bundle     Package turn files with a checksum manifest (create, verify)
+//	ratings    Rate league players from completed games (Elo, Glicko)
+//	fix-name   Fix files named after the wrong player
+//	messages   Export player messages as Markdown conversation threads
+//	newsletter Write a public newsletter of a game year
+//	stats      Export the statistics of a player year by year
+//	scores     Export and chart the score history of a game
+//	compat     Check files for known quirks and their workarounds
+//	script     Run a Starlark analysis script over game files
+//	export     Export the game data as JSON or CSV
+//	note       Keep notes about planets and fleets
+//	host       Generate turns without Stars!
+//	battlesim  Simulate battles between fleets
+//	pop-transport  Plan colonist shipments from crowded to green planets
+//
+// Exit codes:
+//
+//	0  success
+//	1  error
+//	2  invalid command line
+//	3  a file is missing, not a Stars! file, or corrupted
+//	4  the files do not fit together or fail a check
+//	5  exploits found (exploits)
+//	6  nothing to do (prune-h)
+//
+// With --error-format json, errors are printed on stderr as one JSON
+// object: {"error", "class", "exit_code", "explanation", "hint"}.
 package main
 
 import (
@@ -25,24 +71,58 @@ import (
 	"os"
 
 	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/reproducible"
 )
 
 var version = "dev"
 
 type globalOptions struct {
-	Version func() `short:"V" long:"version" description:"Print version and exit"`
+	Version      func() `short:"V" long:"version" description:"Print version and exit"`
+	Verbose      bool   `short:"v" long:"verbose" description:"Print warnings about files that disagree when merged"`
+	Cache        bool   `long:"cache" description:"Cache parsed files to speed up repeated runs over the same files"`
+	CacheDir     string `long:"cache-dir" env:"HOUSTON_CACHE_DIR" description:"Directory of the parse cache (implies --cache)"`
+	Mod          string `long:"mod" env:"HOUSTON_MOD" description:"Component balance mod (YAML) used by design calculations"`
+	Reproducible bool   `long:"reproducible" env:"HOUSTON_REPRODUCIBLE" description:"Write byte-identical files for identical inputs (fixed salts, timestamps from SOURCE_DATE_EPOCH)"`
+	Rclone       string `long:"rclone" env:"HOUSTON_RCLONE" description:"rclone command used for files in cloud folders, given as remote:path (default: rclone in PATH)"`
+	ErrorFormat  string `long:"error-format" env:"HOUSTON_ERROR_FORMAT" choice:"text" choice:"json" default:"text" description:"Format of errors printed on stderr"`
 }
 
+// globals holds the options shared by all commands, set while parsing.
+var globals globalOptions
+
 func main() {
-	var globals globalOptions
 	globals.Version = func() {
 		fmt.Printf("houston %s\n", version)
 		os.Exit(0)
 	}
 
-	parser := flags.NewParser(&globals, flags.Default)
+	// Errors are printed below, so that command errors get their hints
+	parser := flags.NewParser(&globals, flags.HelpFlag|flags.PassDoubleDash)
 	parser.Name = "houston"
-	parser.LongDescription = "A toolkit for working with Stars! game files"
+	parser.LongDescription = "A toolkit for working with Stars! game files\n\n" +
+		"Exit codes:\n" +
+		"  0  success\n" +
+		"  1  error\n" +
+		"  2  invalid command line\n" +
+		"  3  a file is missing, not a Stars! file, or corrupted\n" +
+		"  4  the files do not fit together or fail a check\n" +
+		"  5  exploits found (exploits)\n" +
+		"  6  nothing to do (prune-h)\n\n" +
+		"With --error-format json, errors are printed on stderr as one JSON object\n" +
+		"with the error, its class and exit code, and the explanation and hint."
+	parser.CommandHandler = func(command flags.Commander, args []string) error {
+		if globals.Reproducible {
+			reproducible.Enable()
+		}
+		if err := applyBalanceMod(); err != nil {
+			return err
+		}
+		if command == nil {
+			return nil
+		}
+		return command.Execute(args)
+	}
 
 	// Add subcommands
 	addBlocksCommand(parser)
@@ -56,19 +136,58 @@ func main() {
 	addMapCommand(parser)
 	addExploitsCommand(parser)
 	addReportCommand(parser)
+	addWarfrontCommand(parser)
+	addThreatsCommand(parser)
+	addStarbasesCommand(parser)
+	addDesignsCommand(parser)
+	addSummaryCommand(parser)
+	addResearchCommand(parser)
+	addSettingsCommand(parser)
+	addMinefieldsCommand(parser)
+	addPublishCommand(parser)
+	addThumbnailCommand(parser)
+	addFindCommand(parser)
+	addScriptCommand(parser)
+	addExportCommand(parser)
+	addNoteCommand(parser)
+	addHostCommand(parser)
+	addBattleSimCommand(parser)
+	addPopTransportCommand(parser)
+	addReviewCommand(parser)
+	addTraderCommand(parser)
+	addBalanceCommand(parser)
+	addHandicapCommand(parser)
+	addPlanetNamesCommand(parser)
+	addEventsCommand(parser)
+	addReplayCommand(parser)
+	addPruneHCommand(parser)
+	addBundleCommand(parser)
+	addRatingsCommand(parser)
+	addFixNameCommand(parser)
+	addMessagesCommand(parser)
+	addNewsletterCommand(parser)
+	addStatsCommand(parser)
+	addScoresCommand(parser)
+	addCompatCommand(parser)
 
 	_, err := parser.Parse()
-	if err != nil {
-		flagsErr := &flags.Error{}
-		if errors.As(err, &flagsErr) {
-			if flagsErr.Type == flags.ErrHelp {
-				os.Exit(0)
-			}
-			if flagsErr.Type == flags.ErrCommandRequired {
-				parser.WriteHelp(os.Stderr)
-				os.Exit(1)
-			}
+	if err == nil {
+		return
+	}
+	flagsErr := &flags.Error{}
+	if errors.As(err, &flagsErr) && flagsErr.Type == flags.ErrHelp {
+		fmt.Fprintln(os.Stdout, err)
+		os.Exit(exitOK)
+	}
+	if globals.ErrorFormat == "json" {
+		presentErrorJSON(os.Stderr, err)
+	} else if errors.As(err, &flagsErr) {
+		fmt.Fprintln(os.Stderr, err)
+		if flagsErr.Type == flags.ErrCommandRequired {
+			parser.WriteHelp(os.Stderr)
 		}
-		os.Exit(1)
+	} else {
+		presentError(os.Stderr, err)
 	}
+	os.Exit(exitCode(err))
 }
@@ -2,31 +2,47 @@ package main
 
 import (
 	"fmt"
+	"image/png"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jessevdk/go-flags"
 
+	"github.com/neper-stars/houston/filenames"
 	"github.com/neper-stars/houston/lib/tools/maprenderer"
+	"github.com/neper-stars/houston/lib/tools/notes"
 )
 
 type mapCommand struct {
-	Output       string `short:"o" long:"output" description:"Output filename (default: input.png or animation.gif)"`
+	Output       string `short:"o" long:"output" description:"Output filename (default: input.png, animation.gif or grid.png)"`
 	Width        int    `short:"W" long:"width" description:"Image width in pixels" default:"800"`
 	Height       int    `short:"H" long:"height" description:"Image height in pixels" default:"600"`
 	SVG          bool   `short:"s" long:"svg" description:"Output as SVG instead of PNG"`
 	GIF          bool   `short:"g" long:"gif" description:"Create animated GIF from multiple files"`
 	Dir          string `short:"d" long:"dir" description:"Load all M files from directory for animation"`
 	Delay        int    `long:"delay" description:"Delay between frames in milliseconds" default:"1000"`
+	Smooth       int    `long:"smooth" description:"Frames to draw between turns of a GIF, moving fleets smoothly" default:"0"`
+	Grid         bool   `long:"grid" description:"Lay out the turns as a grid of maps in one PNG instead of a GIF"`
+	Columns      int    `long:"columns" description:"Maps per row of the grid (default: near-square)"`
+	Years        string `long:"years" description:"Comma-separated years to show in the grid (default: all)"`
 	ShowNames    bool   `short:"n" long:"names" description:"Show planet names"`
+	Names        string `long:"name" description:"Comma-separated planet names to label (e.g. \"Sol,Rigel\")"`
 	ShowFleets   bool   `short:"f" long:"fleets" description:"Show fleet indicators"`
 	FleetPaths   int    `short:"p" long:"fleet-paths" description:"Show fleet projected paths (number of years)" default:"0"`
+	ETA          bool   `long:"eta" description:"Label fleet paths with the years to each waypoint (with --fleet-paths)"`
+	Trails       int    `short:"t" long:"trails" description:"Draw fleet trails over the last N turns of the given files" default:"0"`
+	PlainFleets  bool   `long:"plain-fleets" description:"Draw all fleets as triangles instead of role glyphs"`
 	ShowMines    bool   `short:"m" long:"mines" description:"Show minefields"`
 	ShowWH       bool   `short:"w" long:"wormholes" description:"Show wormholes"`
 	ShowLegend   bool   `short:"l" long:"legend" description:"Show player legend"`
 	ShowScanners bool   `short:"c" long:"scanners" description:"Show scanner coverage circles"`
+	ScannersOf   string `long:"scanners-of" description:"Comma-separated players (1-16) whose scanner coverage is shown (implies --scanners)"`
+	AsPlayer     int    `long:"as-player" description:"Only draw what this player (1-16) could see with their scanners"`
+	Colors       string `long:"colors" description:"Player colors: palette name (default, colorblind) or comma-separated hex list"`
+	Notes        bool   `long:"notes" description:"Label the planets and fleets noted with 'houston note' (PNG of a single turn)"`
 	Args         struct {
 		Files []string `positional-arg-name:"file" description:"Stars! game files to render"`
 	} `positional-args:"yes"`
@@ -43,28 +59,66 @@ func (c *mapCommand) Execute(args []string) error {
 		return fmt.Errorf("no input files specified")
 	}
 
-	// Set defaults for boolean options not explicitly set
-	showFleets := c.ShowFleets
-	showWH := c.ShowWH
-	showLegend := c.ShowLegend
+	var layers []string
+	for layer, show := range map[string]bool{
+		maprenderer.LayerFleets:     c.ShowFleets,
+		maprenderer.LayerMinefields: c.ShowMines,
+		maprenderer.LayerWormholes:  c.ShowWH,
+		maprenderer.LayerLegend:     c.ShowLegend,
+		maprenderer.LayerScanners:   c.ShowScanners || c.ScannersOf != "",
+	} {
+		if show {
+			layers = append(layers, layer)
+		}
+	}
 	// If none of the display options are set, use sensible defaults
 	if !c.ShowFleets && !c.ShowMines && !c.ShowWH && !c.ShowLegend && !c.ShowNames {
-		showFleets = true
-		showWH = true
-		showLegend = true
+		layers = append(layers, maprenderer.LayerFleets, maprenderer.LayerWormholes, maprenderer.LayerLegend)
+	}
+
+	renderOpts := maprenderer.NewOptions(
+		maprenderer.WithSize(c.Width, c.Height),
+		maprenderer.WithOnlyLayers(layers...),
+		maprenderer.WithPlanetNames(c.ShowNames),
+		maprenderer.WithFleetPaths(c.FleetPaths),
+		maprenderer.WithFleetETAs(c.ETA),
+		maprenderer.WithFleetTrails(c.Trails),
+		maprenderer.WithPlainFleets(c.PlainFleets),
+		maprenderer.WithViewAsPlayer(c.AsPlayer),
+		maprenderer.WithPadding(20),
+	)
+	if c.Names != "" {
+		renderOpts.Apply(maprenderer.WithNamedPlanets(strings.Split(c.Names, ",")...))
+	}
+	if c.ScannersOf != "" {
+		var players []int
+		for _, field := range strings.Split(c.ScannersOf, ",") {
+			player, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || player < 1 || player > 16 {
+				return fmt.Errorf("invalid --scanners-of: %q is not a player number (1-16)", field)
+			}
+			players = append(players, player)
+		}
+		renderOpts.Apply(maprenderer.WithScannerPlayers(players...))
+	}
+	if c.Colors != "" {
+		colors, err := maprenderer.ParsePlayerColors(c.Colors)
+		if err != nil {
+			return fmt.Errorf("invalid --colors: %w", err)
+		}
+		renderOpts.Apply(maprenderer.WithPlayerColors(colors))
+	}
+
+	if c.Notes && (c.SVG || c.GIF || c.Grid || c.Dir != "" || c.Trails > 0) {
+		return fmt.Errorf("--notes only applies to a PNG map of a single turn")
 	}
 
-	renderOpts := &maprenderer.RenderOptions{
-		Width:               c.Width,
-		Height:              c.Height,
-		ShowNames:           c.ShowNames,
-		ShowFleets:          showFleets,
-		ShowFleetPaths:      c.FleetPaths,
-		ShowMines:           c.ShowMines,
-		ShowWormholes:       showWH,
-		ShowLegend:          showLegend,
-		ShowScannerCoverage: c.ShowScanners,
-		Padding:             20,
+	// Trails draw the latest turn over the earlier ones
+	if c.Trails > 0 && !c.GIF && !c.Grid && c.Dir == "" {
+		return c.createTrailImage(renderOpts)
+	}
+	if c.Grid {
+		return c.createGrid(renderOpts)
 	}
 
 	// Determine if we're creating a GIF or a single merged image
@@ -110,7 +164,11 @@ func (c *mapCommand) createSingleImage(renderOpts *maprenderer.RenderOptions) er
 		if output == "" {
 			output = c.Args.Files[0] + ".png"
 		}
-		if err := renderer.SavePNG(output, renderOpts); err != nil {
+		if c.Notes {
+			if err := c.saveNotesPNG(renderer, output, renderOpts); err != nil {
+				return err
+			}
+		} else if err := renderer.SavePNG(output, renderOpts); err != nil {
 			return fmt.Errorf("failed to save PNG: %w", err)
 		}
 	}
@@ -124,49 +182,157 @@ func (c *mapCommand) createSingleImage(renderOpts *maprenderer.RenderOptions) er
 	return nil
 }
 
-func (c *mapCommand) createAnimation(renderOpts *maprenderer.RenderOptions) error {
+// saveNotesPNG saves the map with a callout on each noted planet or fleet
+// found in the game.
+func (c *mapCommand) saveNotesPNG(renderer *maprenderer.Renderer, output string, renderOpts *maprenderer.RenderOptions) error {
+	img, err := renderer.RenderSVGToImage(renderOpts)
+	if err != nil {
+		img = renderer.Render(renderOpts)
+	}
+	gs := renderer.Store()
+	if book := loadNotes(c.Args.Files[0], gs.GameID); book != nil {
+		var annotations []maprenderer.Annotation
+		for _, n := range book.Notes {
+			if x, y, ok := notes.Locate(gs, n); ok {
+				annotations = append(annotations, maprenderer.Callout(x, y, n.Text))
+			}
+		}
+		renderer.Annotate(img, renderOpts, annotations)
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to save PNG: %w", err)
+	}
+	return f.Close()
+}
+
+// createTrailImage renders the latest turn of the files, merging the files
+// of each turn, with fleet trails drawn from the earlier turns.
+func (c *mapCommand) createTrailImage(renderOpts *maprenderer.RenderOptions) error {
+	stores, err := loadTurnStores(c.Args.Files)
+	if err != nil {
+		return err
+	}
+	if len(stores) == 0 {
+		return fmt.Errorf("no input file specified")
+	}
+
+	latest := stores[len(stores)-1]
+	renderer := maprenderer.NewFromStore(latest)
+	renderer.SetHistory(stores[:len(stores)-1])
+
+	output := c.Output
+	if c.SVG {
+		if output == "" {
+			output = c.Args.Files[len(c.Args.Files)-1] + ".svg"
+		}
+		if err := renderer.SaveSVG(output, renderOpts); err != nil {
+			return fmt.Errorf("failed to save SVG: %w", err)
+		}
+	} else {
+		if output == "" {
+			output = c.Args.Files[len(c.Args.Files)-1] + ".png"
+		}
+		if err := renderer.SavePNG(output, renderOpts); err != nil {
+			return fmt.Errorf("failed to save PNG: %w", err)
+		}
+	}
+
+	fmt.Printf("Created %s\n", output)
+	fmt.Printf("  Year: %d (Turn %d), trails from %d earlier turns\n", renderer.Year(), renderer.Turn(), len(stores)-1)
+	fmt.Printf("  Fleets: %d\n", renderer.FleetCount())
+
+	return nil
+}
+
+// loadAnimator loads the files and the directory into an animator, one
+// frame per year, sorted by year.
+func (c *mapCommand) loadAnimator(renderOpts *maprenderer.RenderOptions) (*maprenderer.Animator, error) {
+	cache, err := parseCache()
+	if err != nil {
+		return nil, err
+	}
+
 	animator := maprenderer.NewAnimator()
 	animator.SetOptions(renderOpts)
+	animator.SetCache(cache)
 
 	// Load files from directory if specified
 	if c.Dir != "" {
 		fmt.Printf("Loading M files from %s...\n", c.Dir)
 		files, err := findMFilesMap(c.Dir)
 		if err != nil {
-			return fmt.Errorf("failed to scan directory: %w", err)
+			return nil, fmt.Errorf("failed to scan directory: %w", err)
 		}
-		for _, file := range files {
-			fmt.Printf("Loading %s...\n", file)
+		bar := newProgressBar("Loading", uint64(len(files)))
+		for i, file := range files {
+			if !bar.enabled {
+				fmt.Printf("Loading %s...\n", file)
+			}
 			if err := animator.AddFile(file); err != nil {
-				return fmt.Errorf("failed to load %s: %w", file, err)
+				bar.Finish()
+				return nil, fmt.Errorf("failed to load %s: %w", file, err)
 			}
+			bar.Update(uint64(i + 1))
 		}
+		bar.Finish()
 	}
 
 	// Load explicitly specified files
 	for _, file := range c.Args.Files {
 		fmt.Printf("Loading %s...\n", file)
 		if err := animator.AddFile(file); err != nil {
-			return fmt.Errorf("failed to load %s: %w", file, err)
+			return nil, fmt.Errorf("failed to load %s: %w", file, err)
 		}
 	}
 
 	if animator.FrameCount() == 0 {
-		return fmt.Errorf("no frames to animate")
+		return nil, fmt.Errorf("no frames to animate")
 	}
 
 	// Sort frames by year
 	animator.SortByYear()
+	return animator, nil
+}
+
+func (c *mapCommand) createAnimation(renderOpts *maprenderer.RenderOptions) error {
+	animator, err := c.loadAnimator(renderOpts)
+	if err != nil {
+		return err
+	}
 
 	output := c.Output
 	if output == "" {
 		output = "animation.gif"
 	}
 
-	fmt.Printf("Creating animation with %d frames...\n", animator.FrameCount())
-
-	if err := animator.SaveGIF(output, c.Delay); err != nil {
-		return fmt.Errorf("failed to save GIF: %w", err)
+	frames := animator.FrameCount()
+	if c.Smooth > 0 {
+		animator.SetInBetweenFrames(c.Smooth)
+		frames += (frames - 1) * c.Smooth
+	}
+	fmt.Printf("Creating animation with %d frames...\n", frames)
+
+	bar := newProgressBar("Rendering", uint64(frames))
+	animator.SetProgress(func(done, total int) { bar.Update(uint64(done)) })
+	// The extension picks the format: WebP and APNG keep the full colors
+	format := "GIF"
+	save := animator.SaveGIF
+	switch strings.ToLower(filepath.Ext(output)) {
+	case ".webp":
+		format, save = "WebP", animator.SaveWebP
+	case ".png", ".apng":
+		format, save = "APNG", animator.SaveAPNG
+	}
+	err = save(output, c.Delay)
+	bar.Finish()
+	if err != nil {
+		return fmt.Errorf("failed to save %s: %w", format, err)
 	}
 
 	fmt.Printf("Created %s\n", output)
@@ -176,6 +342,49 @@ func (c *mapCommand) createAnimation(renderOpts *maprenderer.RenderOptions) erro
 	return nil
 }
 
+// createGrid renders the turns side by side in a single PNG image.
+func (c *mapCommand) createGrid(renderOpts *maprenderer.RenderOptions) error {
+	grid := maprenderer.GridOptions{Columns: c.Columns, Gap: 4}
+	if c.Years != "" {
+		for _, field := range strings.Split(c.Years, ",") {
+			year, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				return fmt.Errorf("invalid --years: %q is not a year", field)
+			}
+			grid.Years = append(grid.Years, year)
+		}
+	}
+
+	animator, err := c.loadAnimator(renderOpts)
+	if err != nil {
+		return err
+	}
+
+	output := c.Output
+	if output == "" {
+		output = "grid.png"
+	}
+
+	maps := animator.FrameCount()
+	if len(grid.Years) > 0 {
+		maps = len(grid.Years)
+	}
+	fmt.Printf("Creating grid of %d maps...\n", maps)
+
+	bar := newProgressBar("Rendering", uint64(maps))
+	animator.SetProgress(func(done, total int) { bar.Update(uint64(done)) })
+	err = animator.SaveGridPNG(output, grid)
+	bar.Finish()
+	if err != nil {
+		return fmt.Errorf("failed to save grid: %w", err)
+	}
+
+	fmt.Printf("Created %s\n", output)
+	fmt.Printf("  Maps: %d of %dx%d pixels\n", maps, c.Width, c.Height)
+
+	return nil
+}
+
 func findMFilesMap(dir string) ([]string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -188,8 +397,7 @@ func findMFilesMap(dir string) ([]string, error) {
 			continue
 		}
 		name := entry.Name()
-		ext := strings.ToLower(filepath.Ext(name))
-		if isStarsFileExt(ext) {
+		if isStarsGameFile(name) || filenames.IsZip(name) {
 			files = append(files, filepath.Join(dir, name))
 		}
 	}
@@ -197,36 +405,12 @@ func findMFilesMap(dir string) ([]string, error) {
 	return files, nil
 }
 
-// isStarsFileExt returns true if the extension is a Stars! game file.
-// Matches: .m1-.m16, .x1-.x16, .h1-.h16, .xy, .hst
+// isStarsGameFile returns true if the file is a Stars! game file: .m1-.m16,
+// .x1-.x16, .h1-.h16, .xy or .hst.
 // Note: .r files (race files) are excluded as they have different game IDs.
-func isStarsFileExt(ext string) bool {
-	if len(ext) < 2 {
-		return false
-	}
-
-	// Check for .xy and .hst
-	if ext == ".xy" || ext == ".hst" {
-		return true
-	}
-
-	// Check for player files: .m1-.m16, .x1-.x16, .h1-.h16
-	// Note: .r files excluded - they have different game IDs
-	if len(ext) >= 3 && len(ext) <= 4 {
-		letter := ext[1]
-		if letter == 'm' || letter == 'x' || letter == 'h' {
-			// Check that remaining characters are digits (1-16)
-			numPart := ext[2:]
-			for _, c := range numPart {
-				if c < '0' || c > '9' {
-					return false
-				}
-			}
-			return len(numPart) > 0
-		}
-	}
-
-	return false
+func isStarsGameFile(name string) bool {
+	kind := filenames.KindOf(name)
+	return kind != filenames.Unknown && kind != filenames.R
 }
 
 func addMapCommand(parser *flags.Parser) {
@@ -237,7 +421,38 @@ func addMapCommand(parser *flags.Parser) {
 			"For multiple files or with --gif, creates an animated GIF showing the galaxy\n"+
 			"over multiple turns.\n\n"+
 			"Player colors are automatically assigned. Owned planets are shown in player colors,\n"+
-			"while unowned planets are gray. Fleets are shown as directional triangles.",
+			"while unowned planets are gray. Fleets are shown as glyphs for their role,\n"+
+			"inferred from their designs: triangle for warships, chevron for bombers,\n"+
+			"pentagon for colonizers, star for minelayers, hexagon for miners, square for\n"+
+			"freighters, dart for scouts and diamond for the rest. Moving fleets point\n"+
+			"where they head; the legend lists the glyphs on the map. --plain-fleets\n"+
+			"draws directional triangles instead.\n\n"+
+			"--eta labels the waypoints of the fleet paths drawn with --fleet-paths with\n"+
+			"the years the fleet needs to reach them at the warp of each leg, e.g.\n"+
+			"houston map -p 3 --eta game.m1.\n\n"+
+			"--trails N draws where each fleet has been over the last N turns, fading with\n"+
+			"age: the files are grouped by turn and the latest turn is rendered over the\n"+
+			"earlier ones, e.g. houston map --trails 5 game.m1 backup/*.m1.\n\n"+
+			"--smooth N draws N frames between turns of a GIF, moving the fleets part of\n"+
+			"the way to their next position, e.g. houston map -d backups --smooth 4.\n\n"+
+			"Animations are GIFs, whose 256 colors band the anti-aliased rendering; an\n"+
+			"output ending in .webp or .png (or .apng) writes an animated WebP or PNG\n"+
+			"with full colors instead, e.g. houston map -d backups -o galaxy.webp.\n\n"+
+			"Files may also be ZIP archives as sent by hosts; with --gif each turn in an\n"+
+			"archive becomes a frame.\n\n"+
+			"--as-player N draws the map as player N saw it, from the scanners of their\n"+
+			"planets and fleets: fleets out of range are left out and planets they could\n"+
+			"not scan are drawn unowned, e.g. houston map --as-player 2 game.hst.\n\n"+
+			"--scanners draws the normal scanner ranges of the planets and fleets as faint\n"+
+			"discs in the player colors, and the penetrating ranges as stronger dashed\n"+
+			"ones; --scanners-of limits them to some players, e.g. for a screenshot of\n"+
+			"an alliance: houston map --scanners-of 1,3 game.hst.\n\n"+
+			"--grid lays the turns out as a grid of small maps in one PNG, for comparing\n"+
+			"turns where an animation cannot be posted; -W and -H size each map, e.g.\n"+
+			"houston map --grid -W 320 -H 240 --years 2410,2420,2430,2440 -d backups.\n\n"+
+			"--colors overrides the player colors, either with a built-in palette\n"+
+			"(\"colorblind\" is safe for the common forms of color blindness) or with a\n"+
+			"list of hex colors starting with player 1, e.g. --colors \"#e69f00,#56b4e9\".",
 		&mapCommand{})
 	if err != nil {
 		panic(err)
@@ -2,14 +2,14 @@ package main
 
 import (
 	"fmt"
-	"io"
-	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/jessevdk/go-flags"
 
+	"github.com/neper-stars/houston/filenames"
 	"github.com/neper-stars/houston/lib/tools/hfilemerger"
+	"github.com/neper-stars/houston/remotefs"
 )
 
 type mergeHCommand struct {
@@ -23,22 +23,22 @@ func (c *mergeHCommand) Execute(args []string) error {
 	// Classify files by type
 	var hFiles, mFiles []string
 	for _, filename := range c.Args.Files {
-		ext := strings.ToLower(filepath.Ext(filename))
-		switch {
-		case len(ext) >= 2 && ext[1] == 'h':
+		switch filenames.KindOf(filename) {
+		case filenames.H:
 			hFiles = append(hFiles, filename)
-		case len(ext) >= 2 && ext[1] == 'm':
+		case filenames.M:
 			mFiles = append(mFiles, filename)
 		default:
 			return fmt.Errorf("unknown file type: %s", filename)
 		}
 	}
 
+	fsys := gameFS()
 	merger := hfilemerger.New()
 
 	// Read and add H files
 	for _, filename := range hFiles {
-		data, err := os.ReadFile(filename)
+		data, err := fsys.ReadFile(filename)
 		if err != nil {
 			return fmt.Errorf("error reading %s: %w", filename, err)
 		}
@@ -50,7 +50,7 @@ func (c *mergeHCommand) Execute(args []string) error {
 
 	// Read and add M files
 	for _, filename := range mFiles {
-		data, err := os.ReadFile(filename)
+		data, err := fsys.ReadFile(filename)
 		if err != nil {
 			return fmt.Errorf("error reading %s: %w", filename, err)
 		}
@@ -72,7 +72,7 @@ func (c *mergeHCommand) Execute(args []string) error {
 		// Create backup if requested
 		if !c.NoBackup {
 			backupName := backupFilenameMergeH(filename)
-			if err := copyFileMergeH(filename, backupName); err != nil {
+			if err := copyFileMergeH(fsys, filename, backupName); err != nil {
 				return fmt.Errorf("error creating backup for %s: %w", filename, err)
 			}
 			backupFiles = append(backupFiles, backupName)
@@ -84,7 +84,7 @@ func (c *mergeHCommand) Execute(args []string) error {
 			return fmt.Errorf("error getting merged data for %s: %w", filename, err)
 		}
 
-		if err := os.WriteFile(filename, mergedData, 0644); err != nil {
+		if err := fsys.WriteFile(filename, mergedData); err != nil {
 			return fmt.Errorf("error writing %s: %w", filename, err)
 		}
 	}
@@ -113,32 +113,19 @@ func (c *mergeHCommand) Execute(args []string) error {
 }
 
 func backupFilenameMergeH(filename string) string {
-	ext := filepath.Ext(filename)
-	if len(ext) >= 2 && (ext[1] == 'h' || ext[1] == 'H') {
+	if filenames.KindOf(filename) == filenames.H {
+		ext := filepath.Ext(filename)
 		return strings.TrimSuffix(filename, ext) + ".backup-" + ext[1:]
 	}
 	return filename + ".backup-h"
 }
 
-func copyFileMergeH(src, dst string) error {
-	source, err := os.Open(src)
+func copyFileMergeH(fsys remotefs.FS, src, dst string) error {
+	data, err := fsys.ReadFile(src)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = source.Close() }()
-
-	dest, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if cerr := dest.Close(); cerr != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to close %s: %v\n", dst, cerr)
-		}
-	}()
-
-	_, err = io.Copy(dest, source)
-	return err
+	return fsys.WriteFile(dst, data)
 }
 
 func addMergeHCommand(parser *flags.Parser) {
@@ -149,6 +136,7 @@ func addMergeHCommand(parser *flags.Parser) {
 			"M files supplied on the command line will have their data incorporated\n"+
 			"but will not be changed. M files are needed for accurately determining\n"+
 			"the latest ship designs.\n\n"+
+			"Files in cloud folders can be given as remote:path, using rclone.\n\n"+
 			"Backups of each input H file will be retained with suffix .backup-h#.",
 		&mergeHCommand{})
 	if err != nil {
@@ -2,14 +2,14 @@ package main
 
 import (
 	"fmt"
-	"io"
-	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/jessevdk/go-flags"
 
+	"github.com/neper-stars/houston/filenames"
 	"github.com/neper-stars/houston/lib/tools/mfilemerger"
+	"github.com/neper-stars/houston/remotefs"
 )
 
 type mergeMCommand struct {
@@ -22,17 +22,17 @@ type mergeMCommand struct {
 func (c *mergeMCommand) Execute(args []string) error {
 	// Validate file extensions
 	for _, filename := range c.Args.Files {
-		ext := strings.ToLower(filepath.Ext(filename))
-		if len(ext) < 2 || ext[1] != 'm' {
+		if filenames.KindOf(filename) != filenames.M {
 			return fmt.Errorf("%s does not appear to be an M file", filename)
 		}
 	}
 
+	fsys := gameFS()
 	merger := mfilemerger.New()
 
 	// Read all files into memory
 	for _, filename := range c.Args.Files {
-		data, err := os.ReadFile(filename)
+		data, err := fsys.ReadFile(filename)
 		if err != nil {
 			return fmt.Errorf("error reading %s: %w", filename, err)
 		}
@@ -54,7 +54,7 @@ func (c *mergeMCommand) Execute(args []string) error {
 		// Create backup if requested
 		if !c.NoBackup {
 			backupName := backupFilenameMergeM(filename)
-			if err := copyFileMergeM(filename, backupName); err != nil {
+			if err := copyFileMergeM(fsys, filename, backupName); err != nil {
 				return fmt.Errorf("error creating backup for %s: %w", filename, err)
 			}
 			backupFiles = append(backupFiles, backupName)
@@ -66,7 +66,7 @@ func (c *mergeMCommand) Execute(args []string) error {
 			return fmt.Errorf("error getting merged data for %s: %w", filename, err)
 		}
 
-		if err := os.WriteFile(filename, mergedData, 0644); err != nil {
+		if err := fsys.WriteFile(filename, mergedData); err != nil {
 			return fmt.Errorf("error writing %s: %w", filename, err)
 		}
 	}
@@ -96,32 +96,19 @@ func (c *mergeMCommand) Execute(args []string) error {
 }
 
 func backupFilenameMergeM(filename string) string {
-	ext := filepath.Ext(filename)
-	if len(ext) >= 2 && (ext[1] == 'm' || ext[1] == 'M') {
+	if filenames.KindOf(filename) == filenames.M {
+		ext := filepath.Ext(filename)
 		return strings.TrimSuffix(filename, ext) + ".backup-" + ext[1:]
 	}
 	return filename + ".backup-m"
 }
 
-func copyFileMergeM(src, dst string) error {
-	source, err := os.Open(src)
+func copyFileMergeM(fsys remotefs.FS, src, dst string) error {
+	data, err := fsys.ReadFile(src)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = source.Close() }()
-
-	dest, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if cerr := dest.Close(); cerr != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to close %s: %v\n", dst, cerr)
-		}
-	}()
-
-	_, err = io.Copy(dest, source)
-	return err
+	return fsys.WriteFile(dst, data)
 }
 
 func addMergeMCommand(parser *flags.Parser) {
@@ -130,6 +117,7 @@ func addMergeMCommand(parser *flags.Parser) {
 		"All M files supplied on the command line will have their data augmented\n"+
 			"with the data on each planet, player, design, fleet, minefield, packet,\n"+
 			"salvage, or wormhole from any of the files.\n\n"+
+			"Files in cloud folders can be given as remote:path, using rclone.\n\n"+
 			"Backups of each input M file will be retained with suffix .backup-m#.",
 		&mergeMCommand{})
 	if err != nil {
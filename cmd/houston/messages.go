@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/msgthreads"
+	"github.com/neper-stars/houston/store"
+)
+
+type messagesCommand struct {
+	Player int    `short:"p" long:"player" description:"Player number (1-16) to show the conversations of (default: player of the first M or X file)"`
+	Output string `short:"o" long:"output" description:"Write the Markdown to this file instead of standard output"`
+	Args   struct {
+		Files []string `positional-arg-name:"file" description:"M and X files (.m1-.m16, .x1-.x16) of one or several turns" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (c *messagesCommand) Execute(args []string) error {
+	stores, err := loadTurnStores(c.Args.Files)
+	if err != nil {
+		return err
+	}
+
+	player := c.Player
+	if player == 0 {
+		player = filePlayer(stores)
+	}
+	if player < 1 || player > 16 {
+		return fmt.Errorf("no player given and no M or X file to take it from, use --player")
+	}
+
+	var w io.Writer = os.Stdout
+	if c.Output != "" {
+		f, err := os.Create(c.Output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", c.Output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	return msgthreads.WriteMarkdown(w, msgthreads.FromStores(stores, player), player, playerNames(stores))
+}
+
+// filePlayer returns the player number (1-16) of the first M or X file of
+// the stores, 0 if there is none.
+func filePlayer(stores []*store.GameStore) int {
+	for _, gs := range stores {
+		for _, source := range gs.Sources() {
+			if source.Type == store.SourceTypeMFile || source.Type == store.SourceTypeXFile {
+				return source.PlayerIndex + 1
+			}
+		}
+	}
+	return 0
+}
+
+// playerNames returns the latest known plural name of a player number.
+func playerNames(stores []*store.GameStore) func(int) string {
+	return func(number int) string {
+		for i := len(stores) - 1; i >= 0; i-- {
+			if p, ok := stores[i].Player(number - 1); ok && p.NamePlural != "" {
+				return p.NamePlural
+			}
+		}
+		return ""
+	}
+}
+
+func addMessagesCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("messages",
+		"Export player messages as Markdown conversation threads",
+		"Rebuilds the conversations of a player from the messages of several\n"+
+			"turns and writes them as Markdown: one section per correspondent, plus\n"+
+			"one for the messages to everyone, with replies nested under the message\n"+
+			"they answer.\n\n"+
+			"M files hold the messages received each year and X files those written\n"+
+			"in reply; give both, from as many turns as available. A reply whose\n"+
+			"message is not among the files starts a conversation of its own.\n\n"+
+			"Example:\n"+
+			"  houston messages archive/*.m1 archive/*.x1 -o messages.md",
+		&messagesCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
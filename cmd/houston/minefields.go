@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/minefields"
+	"github.com/neper-stars/houston/store"
+)
+
+type minefieldsCommand struct {
+	Player int  `short:"p" long:"player" description:"Player number (1-16, auto-detected from M-file if not specified)"`
+	All    bool `short:"a" long:"all" description:"Also list minefields with no fleet inside"`
+	Routes bool `short:"r" long:"routes" description:"Suggest minelaying routes across enemy approach lanes"`
+	Stops  int  `long:"stops" description:"Maximum stops per minelaying route" default:"3"`
+	Warp   int  `long:"warp" description:"Warp the minelayers travel at" default:"6"`
+	Args   struct {
+		Files []string `positional-arg-name:"file" description:"Stars! .m files from one or more turns" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (c *minefieldsCommand) Execute(args []string) error {
+	stores, err := loadTurnStores(c.Args.Files)
+	if err != nil {
+		return err
+	}
+	gs := stores[len(stores)-1]
+
+	playerNumber := c.Player - 1
+	if c.Player == 0 {
+		playerNumber = detectPlayerNumber(gs)
+		if playerNumber < 0 {
+			return errNoPlayerDetected
+		}
+	}
+
+	fmt.Printf("Minefield detonation report for player %d, year %d\n", playerNumber+1, int(gs.Turn)+blocks.StarsBaseYear)
+	fmt.Println(strings.Repeat("=", 60))
+	if !minefields.CanDetonate(gs, playerNumber) {
+		fmt.Println("\nNote: only Space Demolition races can detonate their minefields.")
+	}
+
+	shown := 0
+	for _, d := range minefields.Detonations(gs, playerNumber) {
+		if !c.All && len(d.Fleets) == 0 {
+			continue
+		}
+		shown++
+		mf := d.Minefield
+		state := ""
+		if mf.Detonating {
+			state = ", detonating"
+		}
+		fmt.Printf("\n  Minefield #%d at (%d, %d), radius %.0f ly%s\n", mf.Number, mf.X, mf.Y, mf.Radius(), state)
+		for _, e := range d.Fleets {
+			owner := fmt.Sprintf("player %d", e.Fleet.Owner+1)
+			if e.Own {
+				owner = "own"
+			}
+			marker := " "
+			if e.Destroyed() {
+				marker = "x"
+			}
+			fmt.Printf("   %s %-24s %-10s %5.1f ly  %5d damage, %5d armor\n",
+				marker, e.Fleet.Name(), owner, e.Distance, e.Damage, e.Armor)
+		}
+		fmt.Printf("    Total: %d damage to enemies, %d to own fleets\n", d.EnemyDamage, d.OwnDamage)
+	}
+	if shown == 0 {
+		fmt.Println("\n  No fleets inside your standard minefields.")
+	}
+
+	if c.Routes {
+		c.printRoutes(stores, playerNumber)
+	}
+	return nil
+}
+
+func (c *minefieldsCommand) printRoutes(stores []*store.GameStore, playerNumber int) {
+	opts := minefields.DefaultRouteOptions()
+	opts.Stops = c.Stops
+	opts.Warp = c.Warp
+
+	fmt.Println("\nMinelaying routes:")
+	routes := minefields.PlanRoutes(stores, playerNumber, opts)
+	if len(routes) == 0 {
+		fmt.Println("  No minelayer can cover an enemy approach lane.")
+		return
+	}
+	for _, r := range routes {
+		fmt.Printf("\n  %s at (%d, %d): %d %s mines a year (%.0f ly), %.0f%% cloaked\n",
+			r.Fleet.Name(), r.Fleet.X, r.Fleet.Y, r.Rate, mineTypeNames[r.Type], r.Radius, r.Cloak*100)
+		for i, s := range r.Stops {
+			marker := " "
+			if s.InTime {
+				marker = "*"
+			}
+			fmt.Printf("   %s %d. (%d, %d) year %d: %4.0f ly of %d lane(s), %3.0f%% detection risk\n",
+				marker, i+1, s.X, s.Y, int(stores[len(stores)-1].Turn)+blocks.StarsBaseYear+s.Arrival, s.Coverage, s.Lanes, s.Detection*100)
+		}
+		fmt.Printf("    Total: %.0f ly covered, %.0f%% risk of being seen\n", r.Coverage(), r.Detection()*100)
+	}
+}
+
+var mineTypeNames = map[int]string{
+	blocks.MinefieldTypeStandard:  "standard",
+	blocks.MinefieldTypeHeavy:     "heavy",
+	blocks.MinefieldTypeSpeedBump: "speed bump",
+}
+
+func addMinefieldsCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("minefields",
+		"Report expected damage of minefield detonations",
+		"Lists the fleets inside each of your standard minefields with the\n"+
+			"damage they would take if the field were detonated. Detonation hits\n"+
+			"every fleet in the field, your own included. Fleets marked 'x' would\n"+
+			"lose all their armor (shields not counted).\n\n"+
+			"With --routes, suggests stops for each minelayer along the lanes\n"+
+			"of enemy fleets heading for your planets: where a field laid for a\n"+
+			"year covers the most of the lanes, away from the enemy scanners\n"+
+			"your cloak does not beat. Give files from earlier turns to project\n"+
+			"the lanes from observed movement. Stops marked '*' catch the enemy\n"+
+			"fleet seen before it passes.",
+		&minefieldsCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"github.com/neper-stars/houston/lib/tools/balancemod"
+)
+
+// applyBalanceMod patches the component tables with the mod given with
+// --mod, before the command runs.
+func applyBalanceMod() error {
+	if globals.Mod == "" {
+		return nil
+	}
+	mod, err := balancemod.Load(globals.Mod)
+	if err != nil {
+		return err
+	}
+	_, err = balancemod.Apply(mod)
+	return err
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/newsletter"
+	"github.com/neper-stars/houston/lib/tools/report"
+)
+
+type newsletterCommand struct {
+	Dir    string `short:"d" long:"dir" description:"Host directory holding the files of the year and of the year before" default:"."`
+	Year   int    `short:"y" long:"year" description:"Year of the newsletter (default: latest year found)"`
+	Game   uint32 `short:"g" long:"game" description:"Only use the files of the game with this ID, for directories holding several games"`
+	Format string `short:"f" long:"format" description:"Output format: text, markdown or html" default:"markdown"`
+}
+
+func (c *newsletterCommand) Execute(args []string) error {
+	files, err := findMFilesMap(c.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %w", c.Dir, err)
+	}
+	stores, err := loadTurnStoresOfGame(files, c.Game)
+	if err != nil {
+		return err
+	}
+	if len(stores) == 0 {
+		return fmt.Errorf("no game files found in %s", c.Dir)
+	}
+
+	year := c.Year
+	if year == 0 {
+		year = int(stores[len(stores)-1].Turn) + blocks.StarsBaseYear
+	}
+	news, err := newsletter.Build(stores, year)
+	if err != nil {
+		return err
+	}
+	return renderReport(c.Format, newsletterDocument(news))
+}
+
+func newsletterDocument(news *newsletter.Newsletter) *report.Document {
+	title := fmt.Sprintf("News of %d", news.Year)
+	if news.GameName != "" {
+		title = fmt.Sprintf("%s: news of %d", news.GameName, news.Year)
+	}
+	doc := &report.Document{Title: title}
+
+	headlines := doc.AddSection("Headlines")
+	if len(news.Headlines) == 0 {
+		headlines.AddParagraph("A quiet year across the galaxy.")
+	} else {
+		headlines.AddList(news.Headlines...)
+	}
+
+	if len(news.Standings) > 0 {
+		section := doc.AddSection("Standings")
+		table := section.AddTable(
+			report.Column{Header: "Rank", Numeric: true},
+			report.Column{Header: "Player"},
+			report.Column{Header: "Score", Numeric: true},
+			report.Column{Header: "Planets", Numeric: true},
+			report.Column{Header: "Starbases", Numeric: true},
+			report.Column{Header: "Ships", Numeric: true},
+			report.Column{Header: "Tech", Numeric: true},
+			report.Column{Header: "Resources", Numeric: true},
+		)
+		for _, s := range news.Standings {
+			table.AddRow(strconv.Itoa(s.Rank), fmt.Sprintf("%d %s", s.Player, s.Name),
+				withChange(int64(s.Score), int64(s.ScoreChange), news.HasPrevious),
+				withChange(int64(s.Planets), int64(s.PlanetsChange), news.HasPrevious),
+				strconv.Itoa(s.Starbases),
+				withChange(int64(s.Ships), int64(s.ShipsChange), news.HasPrevious),
+				withChange(int64(s.TechLevels), int64(s.TechChange), news.HasPrevious),
+				withChange(s.Resources, s.ResourcesChange, news.HasPrevious))
+		}
+	}
+
+	if len(news.Clashes) > 0 {
+		var lines []string
+		for _, c := range news.Clashes {
+			line := fmt.Sprintf("%s vs %s", newsPlayer(news, c.Players[0]), newsPlayer(news, c.Players[1]))
+			if c.Battles > 1 {
+				line += fmt.Sprintf(" (%d battles)", c.Battles)
+			}
+			lines = append(lines, line)
+		}
+		doc.AddSection("Fighting").AddList(lines...)
+	}
+
+	if len(news.Announcements) > 0 {
+		var lines []string
+		for _, a := range news.Announcements {
+			lines = append(lines, fmt.Sprintf("%s: %s", newsPlayer(news, a.From), a.Text))
+		}
+		doc.AddSection("Announcements").AddList(lines...)
+	}
+	return doc
+}
+
+// newsPlayer names a player number (1-16) for a list item.
+func newsPlayer(news *newsletter.Newsletter, player int) string {
+	if name := news.Players[player]; name != "" {
+		return fmt.Sprintf("%s (player %d)", name, player)
+	}
+	return fmt.Sprintf("Player %d", player)
+}
+
+// withChange formats a value followed by its change, when known.
+func withChange(value, change int64, known bool) string {
+	if !known || change == 0 {
+		return strconv.FormatInt(value, 10)
+	}
+	return fmt.Sprintf("%d (%+d)", value, change)
+}
+
+func addNewsletterCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("newsletter",
+		"Write a public newsletter of a game year",
+		"Writes the news of a year for the host to post where all players read\n"+
+			"it. Only what every player could know is told: the score table and\n"+
+			"its changes since the year before when the game has public scores,\n"+
+			"which players fought (not where, nor their losses) and the messages\n"+
+			"sent to everyone. Planets, fleets, research and private messages are\n"+
+			"never revealed.\n\n"+
+			"The directory should hold the players' M files of the year, and of\n"+
+			"the year before for the changes; each M file has only its player's\n"+
+			"score.\n\n"+
+			"Example:\n"+
+			"  houston newsletter --dir hostdir --year 2450 > news-2450.md",
+		&newsletterCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
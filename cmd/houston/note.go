@@ -0,0 +1,225 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/gamectx"
+	"github.com/neper-stars/houston/lib/tools/notes"
+)
+
+type noteCommand struct{}
+
+// noteGame selects the game whose notes a note command works on.
+type noteGame struct {
+	Game string `short:"g" long:"game" description:"Game file (.xy, .m, .h, .hst) whose notes to use (default: the only .xy file in the current directory)"`
+}
+
+// open loads the game and its notes.
+func (g *noteGame) open() (*gamectx.Context, *notes.Book, error) {
+	file := g.Game
+	if file == "" {
+		matches, _ := filepath.Glob("*.xy")
+		if len(matches) != 1 {
+			return nil, nil, errors.New("cannot tell which game to use: pass a game file with --game")
+		}
+		file = matches[0]
+	}
+	ctx, err := loadGameContext(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	book, err := notes.Load(notes.Path(file))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := book.CheckGame(ctx.Store.GameID); err != nil {
+		return nil, nil, err
+	}
+	return ctx, book, nil
+}
+
+type noteAddCommand struct {
+	noteGame
+	Planet string `long:"planet" description:"Planet the note is about"`
+	Fleet  string `long:"fleet" description:"Fleet the note is about, as [player:]number (e.g. 3 or 2:14; player defaults to the M file's)"`
+	Args   struct {
+		Text string `positional-arg-name:"text" description:"Note text" required:"true"`
+	} `positional-args:"yes"`
+}
+
+func (c *noteAddCommand) Execute(args []string) error {
+	if (c.Planet == "") == (c.Fleet == "") {
+		return errors.New("give either --planet or --fleet")
+	}
+	ctx, book, err := c.open()
+	if err != nil {
+		return err
+	}
+	gs := ctx.Store
+
+	n := notes.Note{Text: c.Args.Text, Year: int(gs.Turn) + blocks.StarsBaseYear}
+	if c.Planet != "" {
+		n.Planet = c.Planet
+		for _, p := range gs.AllPlanets() {
+			if strings.EqualFold(p.Name, c.Planet) {
+				number := p.PlanetNumber
+				n.Planet, n.PlanetNumber = p.Name, &number
+				break
+			}
+		}
+		if n.PlanetNumber == nil && len(gs.AllPlanets()) > 0 {
+			return fmt.Errorf("no planet named %q in this game", c.Planet)
+		}
+	} else {
+		owner, number, err := parseFleetRef(c.Fleet, ctx.Player)
+		if err != nil {
+			return err
+		}
+		n.FleetOwner, n.Fleet = owner, number
+	}
+
+	n, err = book.Add(n)
+	if err != nil {
+		return err
+	}
+	if err := book.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("Added note #%d about %s\n", n.ID, n.Subject())
+	return nil
+}
+
+// parseFleetRef parses [player:]number into a player (1-16) and a fleet
+// number (1-based). player is the default, 0-based, or -1 when unknown.
+func parseFleetRef(ref string, player int) (owner, number int, err error) {
+	ownerText, numberText, hasOwner := strings.Cut(ref, ":")
+	if !hasOwner {
+		ownerText, numberText = "", ownerText
+	}
+	number, err = strconv.Atoi(numberText)
+	if err != nil || number < 1 {
+		return 0, 0, fmt.Errorf("invalid fleet number %q", numberText)
+	}
+	if !hasOwner {
+		if player < 0 {
+			return 0, 0, errNoPlayerDetected
+		}
+		return player + 1, number, nil
+	}
+	owner, err = strconv.Atoi(ownerText)
+	if err != nil || owner < 1 || owner > 16 {
+		return 0, 0, fmt.Errorf("%w: %q", errInvalidPlayer, ownerText)
+	}
+	return owner, number, nil
+}
+
+type noteListCommand struct {
+	noteGame
+	Planet string `long:"planet" description:"Only list the notes about this planet"`
+}
+
+func (c *noteListCommand) Execute(args []string) error {
+	_, book, err := c.open()
+	if err != nil {
+		return err
+	}
+	shown := 0
+	for _, n := range book.Notes {
+		if c.Planet != "" && !strings.EqualFold(n.Planet, c.Planet) {
+			continue
+		}
+		year := ""
+		if n.Year != 0 {
+			year = fmt.Sprintf(" (%d)", n.Year)
+		}
+		fmt.Printf("#%-3d %s%s: %s\n", n.ID, n.Subject(), year, n.Text)
+		shown++
+	}
+	if shown == 0 {
+		fmt.Println("No notes.")
+	}
+	return nil
+}
+
+type noteRemoveCommand struct {
+	noteGame
+	Args struct {
+		ID int `positional-arg-name:"id" description:"Number of the note, as listed" required:"true"`
+	} `positional-args:"yes"`
+}
+
+func (c *noteRemoveCommand) Execute(args []string) error {
+	_, book, err := c.open()
+	if err != nil {
+		return err
+	}
+	if err := book.Remove(c.Args.ID); err != nil {
+		return err
+	}
+	if err := book.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("Removed note #%d\n", c.Args.ID)
+	return nil
+}
+
+// loadNotes returns the notes kept next to a game file, or nil when there
+// are none for this game.
+func loadNotes(file string, gameID uint32) *notes.Book {
+	book, err := notes.Load(notes.Path(file))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil
+	}
+	if len(book.Notes) == 0 || book.CheckGame(gameID) != nil {
+		return nil
+	}
+	return book
+}
+
+func addNoteCommand(parser *flags.Parser) {
+	cmd, err := parser.AddCommand("note",
+		"Keep notes about planets and fleets",
+		"Commands for keeping notes about planets and fleets in a notes file next\n"+
+			"to the game files (game.notes.json for game.xy), so they carry over from\n"+
+			"turn to turn. Notes show up in custom reports (houston report --template,\n"+
+			"as .Notes and on each planet and fleet) and on maps with 'houston map --notes'.",
+		&noteCommand{})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = cmd.AddCommand("add",
+		"Add a note",
+		"Adds a note about a planet or a fleet:\n\n"+
+			"  houston note add --planet Rigel \"staging area\"\n"+
+			"  houston note add --fleet 2:14 \"scout, moves every 3 turns\"",
+		&noteAddCommand{})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = cmd.AddCommand("list",
+		"List the notes",
+		"Lists the notes of the game with their numbers.",
+		&noteListCommand{})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = cmd.AddCommand("remove",
+		"Remove a note",
+		"Removes a note by the number shown by 'houston note list'.",
+		&noteRemoveCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/planetnames"
+	"github.com/neper-stars/houston/store"
+)
+
+type planetNamesCommand struct {
+	Dictionary string `short:"D" long:"dictionary" description:"Dictionary file, one name per line (default: the classic Stars! names)"`
+	Seed       uint64 `short:"s" long:"seed" description:"Seed of the draw; the same seed gives the same names"`
+	DryRun     bool   `short:"d" long:"dry-run" description:"Show the new names without writing the file"`
+	NoBackup   bool   `short:"n" long:"no-backup" description:"Don't create backup file"`
+	Args       struct {
+		File string `positional-arg-name:"file" description:"Universe file of the new game (.xy)" required:"true"`
+	} `positional-args:"yes"`
+}
+
+func (c *planetNamesCommand) Execute(args []string) error {
+	dict := planetnames.Classic()
+	if c.Dictionary != "" {
+		var err error
+		if dict, err = planetnames.Load(c.Dictionary); err != nil {
+			return err
+		}
+	}
+
+	data, err := os.ReadFile(c.Args.File)
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+	gs := store.New()
+	if err := gs.AddFile(c.Args.File, data); err != nil {
+		return fmt.Errorf("failed to load %s: %w", c.Args.File, err)
+	}
+	printStoreWarnings(gs)
+
+	renames, err := planetnames.Apply(gs, dict, c.Seed)
+	if err != nil {
+		return err
+	}
+	for _, r := range renames {
+		fmt.Printf("#%-4d %-16s -> %s\n", r.Planet+1, r.OldName, r.NewName)
+	}
+	fmt.Printf("\n%d planets named from the %s dictionary (%d names).\n", len(renames), dict.Name, len(dict.Names))
+
+	if c.DryRun {
+		fmt.Println("Dry run, file not modified.")
+		return nil
+	}
+
+	modified, err := gs.GenerateXYFile()
+	if err != nil {
+		return fmt.Errorf("error generating file: %w", err)
+	}
+	if !c.NoBackup {
+		backupFile := c.Args.File + ".backup"
+		if err := copyFilePlayer(c.Args.File, backupFile); err != nil {
+			return fmt.Errorf("error creating backup: %w", err)
+		}
+		fmt.Printf("Created backup: %s\n", backupFile)
+	}
+	if err := os.WriteFile(c.Args.File, modified, 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	fmt.Println("File updated successfully.")
+	return nil
+}
+
+func addPlanetNamesCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("planet-names",
+		"Rename the planets of a new game from a dictionary",
+		"Gives every planet of a new universe a name drawn from a dictionary:\n"+
+			"the classic Stars! names, or a themed list of your own, one name per\n"+
+			"line ('#' starts a comment).\n\n"+
+			"Stars! files only store the index of a name in the table built into\n"+
+			"the game, so a dictionary can only hold names from that table; other\n"+
+			"names are rejected. The dictionary needs at least as many names as\n"+
+			"the universe has planets.\n\n"+
+			"Run it on the .xy file before the first turn is generated. A backup\n"+
+			"of the original file will be created unless --no-backup is specified.\n\n"+
+			"Examples:\n"+
+			"  houston planet-names game.xy --seed 42 --dry-run\n"+
+			"  houston planet-names game.xy --dictionary constellations.txt",
+		&planetNamesCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
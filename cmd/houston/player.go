@@ -86,7 +86,7 @@ func (c *playerCommand) Execute(args []string) error {
 	}
 
 	if c.Player < 0 || c.Player > 15 {
-		return fmt.Errorf("invalid player number: %d (must be 0-15)", c.Player)
+		return fmt.Errorf("%w: %d (must be 0-15)", errInvalidPlayer, c.Player)
 	}
 
 	// Parse AI type if specified
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/filenames"
+	"github.com/neper-stars/houston/lib/tools/orders"
+	"github.com/neper-stars/houston/lib/tools/poptransport"
+)
+
+type popTransportCommand struct {
+	Player     int  `short:"p" long:"player" description:"Player number (1-16, auto-detected from M-file if not specified)"`
+	Warp       int  `short:"w" long:"warp" default:"6" description:"Warp of the freighters"`
+	MaxYears   int  `long:"max-years" description:"Longest trip planned, in years (0 for no limit)"`
+	SourceFill int  `long:"source-fill" default:"100" description:"Percentage of capacity kept on crowded planets"`
+	Fill       int  `long:"fill" default:"25" description:"Percentage of capacity green planets are filled to"`
+	Write      bool `long:"write" description:"Add the waypoints to the player's X file"`
+	NoBackup   bool `short:"n" long:"no-backup" description:"Don't create a backup of the X file"`
+	Args       struct {
+		File string `positional-arg-name:"file" description:"Player's turn file (.m)" required:"true"`
+	} `positional-args:"yes"`
+}
+
+func (c *popTransportCommand) Execute(args []string) error {
+	ctx, err := loadGameContext(c.Args.File)
+	if err != nil {
+		return err
+	}
+	player := c.Player - 1
+	if c.Player == 0 {
+		if player = detectPlayerNumber(ctx.Store); player < 0 {
+			return errNoPlayerDetected
+		}
+	}
+
+	plan, err := poptransport.Plan(ctx.Store, player, poptransport.Options{
+		Warp:            c.Warp,
+		MaxYears:        c.MaxYears,
+		SourceFill:      float64(c.SourceFill) / 100,
+		DestinationFill: float64(c.Fill) / 100,
+	})
+	if err != nil {
+		return err
+	}
+	if len(plan.Shipments) == 0 {
+		fmt.Println("No colonist shipments to plan.")
+	}
+	for _, s := range plan.Shipments {
+		fmt.Println(s)
+	}
+	if plan.Surplus > 0 {
+		fmt.Printf("%d colonists left on crowded planets\n", plan.Surplus)
+	}
+	if !c.Write || len(plan.Shipments) == 0 {
+		return nil
+	}
+	return c.writeOrders(plan, player)
+}
+
+// writeOrders adds the waypoints of a plan to the X file of the player,
+// keeping the orders it already holds.
+func (c *popTransportCommand) writeOrders(plan *poptransport.Result, player int) error {
+	xFile := filenames.Companion(c.Args.File, filenames.X, player+1)
+	turnFile := c.Args.File
+	if path, ok := filenames.Find(xFile); ok {
+		xFile, turnFile = path, path
+	}
+	data, err := os.ReadFile(turnFile)
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+	b, err := orders.New(data)
+	if err != nil {
+		return fmt.Errorf("failed to read the orders of %s: %w", turnFile, err)
+	}
+	if err := plan.Apply(b); err != nil {
+		return err
+	}
+	out, err := b.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to write the orders: %w", err)
+	}
+
+	if turnFile == xFile && !c.NoBackup {
+		backupFile := xFile + ".backup"
+		if err := copyFilePlayer(xFile, backupFile); err != nil {
+			return fmt.Errorf("error creating backup: %w", err)
+		}
+		fmt.Printf("Created backup: %s\n", backupFile)
+	}
+	if err := os.WriteFile(xFile, out, 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	fmt.Printf("Wrote %s\n", xFile)
+	return nil
+}
+
+func addPopTransportCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("pop-transport",
+		"Plan colonist shipments from crowded to green planets",
+		"Plans shipments of colonists from your planets over capacity, which\n"+
+			"lose colonists every year, to your green planets below a quarter of\n"+
+			"their capacity, which grow at the full rate. Shipments are matched for\n"+
+			"the most growth per year of travel and given to idle freighters: fleets\n"+
+			"with cargo holds, no waypoints and no colonists aboard.\n\n"+
+			"--write adds the shipments to your X file as two transport waypoints\n"+
+			"per freighter, loading at the crowded planet and unloading at the green\n"+
+			"one. An existing X file keeps its orders and is backed up unless\n"+
+			"--no-backup is specified.\n\n"+
+			"Examples:\n"+
+			"  houston pop-transport game.m1\n"+
+			"  houston pop-transport game.m1 --warp 8 --max-years 3 --fill 30 --write",
+		&popTransportCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
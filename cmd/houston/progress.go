@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	progressBarWidth    = 30
+	progressRedrawEvery = 200 * time.Millisecond
+)
+
+// progressBar draws a single-line progress bar with an ETA on a terminal.
+// When the output is not a terminal, it stays silent so that redirected
+// output is not cluttered with carriage returns.
+type progressBar struct {
+	w        io.Writer
+	label    string
+	total    uint64
+	done     uint64
+	start    time.Time
+	lastDraw time.Time
+	enabled  bool
+	drawn    bool
+}
+
+// newProgressBar creates a progress bar on stderr. A total of 0 means the
+// amount of work is unknown: only the count and rate are shown.
+func newProgressBar(label string, total uint64) *progressBar {
+	return &progressBar{
+		w:       os.Stderr,
+		label:   label,
+		total:   total,
+		start:   time.Now(),
+		enabled: isTerminal(os.Stderr),
+	}
+}
+
+// Update redraws the bar for the given amount of work done. Redraws are
+// throttled, so Update is cheap to call in a tight loop.
+func (p *progressBar) Update(done uint64) {
+	p.done = done
+	if !p.enabled {
+		return
+	}
+	now := time.Now()
+	if p.drawn && now.Sub(p.lastDraw) < progressRedrawEvery && done != p.total {
+		return
+	}
+	p.lastDraw = now
+	p.drawn = true
+	fmt.Fprintf(p.w, "\r%s\033[K", formatProgress(p.label, done, p.total, now.Sub(p.start)))
+}
+
+// Finish draws the last reported state and moves to the next line.
+func (p *progressBar) Finish() {
+	if !p.enabled || !p.drawn {
+		return
+	}
+	fmt.Fprintf(p.w, "\r%s\033[K\n", formatProgress(p.label, p.done, p.total, time.Since(p.start)))
+}
+
+// formatProgress renders one progress line: label, bar, percentage, counts
+// and the estimated time left.
+func formatProgress(label string, done, total uint64, elapsed time.Duration) string {
+	if total == 0 {
+		return fmt.Sprintf("%s %d (%s)", label, done, formatRate(done, elapsed))
+	}
+
+	fraction := min(float64(done)/float64(total), 1)
+	filled := int(fraction * progressBarWidth)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+
+	eta := "--"
+	if done > 0 && done < total {
+		remaining := time.Duration(float64(elapsed) * (float64(total-done) / float64(done)))
+		eta = formatDuration(remaining)
+	} else if done >= total {
+		eta = "0s"
+	}
+	return fmt.Sprintf("%s [%s] %3.0f%% %d/%d ETA %s", label, bar, fraction*100, done, total, eta)
+}
+
+func formatRate(done uint64, elapsed time.Duration) string {
+	if elapsed <= 0 {
+		return "--/s"
+	}
+	return fmt.Sprintf("%.0f/s", float64(done)/elapsed.Seconds())
+}
+
+// formatDuration rounds a duration to a readable precision: seconds under
+// an hour, minutes beyond.
+func formatDuration(d time.Duration) string {
+	if d >= time.Hour {
+		return d.Round(time.Minute).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// isTerminal reports whether f is an interactive terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/hfilepruner"
+)
+
+type pruneHCommand struct {
+	ScoreYears    int  `short:"y" long:"score-years" description:"Keep the scores of the last N years (0 keeps them all)"`
+	ScoreInterval int  `short:"i" long:"score-interval" description:"Of the older scores, keep one year out of N (0 drops them all)"`
+	PlanetYears   int  `short:"p" long:"planet-years" description:"Drop planet records last seen more than N years ago (0 keeps them all)"`
+	DryRun        bool `short:"d" long:"dry-run" description:"Show what would be dropped without writing the file"`
+	NoBackup      bool `short:"n" long:"no-backup" description:"Don't create backup file"`
+	Args          struct {
+		File string `positional-arg-name:"file" description:"History file (.h1-.h16)" required:"true"`
+	} `positional-args:"yes"`
+}
+
+func (c *pruneHCommand) Execute(args []string) error {
+	data, err := os.ReadFile(c.Args.File)
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+
+	pruned, stats, err := hfilepruner.Prune(data, hfilepruner.Policy{
+		ScoreYears:    c.ScoreYears,
+		ScoreInterval: c.ScoreInterval,
+		PlanetYears:   c.PlanetYears,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prune %s: %w", c.Args.File, err)
+	}
+	fmt.Printf("Scores dropped:     %d\n", stats.ScoresDropped)
+	fmt.Printf("Planets dropped:    %d\n", stats.PlanetsDropped)
+	fmt.Printf("Duplicates dropped: %d\n", stats.DuplicatesDropped)
+	fmt.Printf("Size: %d -> %d bytes\n", stats.SizeBefore, stats.SizeAfter)
+
+	if c.DryRun {
+		fmt.Println("Dry run, file not modified.")
+		return nil
+	}
+	if stats.SizeAfter == stats.SizeBefore {
+		fmt.Println("Nothing to prune.")
+		return errNothingToDo
+	}
+	if !c.NoBackup {
+		backupFile := c.Args.File + ".backup"
+		if err := copyFilePlayer(c.Args.File, backupFile); err != nil {
+			return fmt.Errorf("error creating backup: %w", err)
+		}
+		fmt.Printf("Created backup: %s\n", backupFile)
+	}
+	if err := os.WriteFile(c.Args.File, pruned, 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	fmt.Println("File updated successfully.")
+	return nil
+}
+
+func addPruneHCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("prune-h",
+		"Shrink H (history) files",
+		"Drops old records from an H file and writes it back. The scores of\n"+
+			"every player for every year make most of the file in long games:\n"+
+			"keep the recent ones, and optionally one year out of N of the older\n"+
+			"ones so the score graphs keep their shape. Planet records not\n"+
+			"refreshed for many years can be dropped too; the client then shows\n"+
+			"those planets as unexplored. Duplicated records are always removed.\n\n"+
+			"A backup of the original file will be created unless --no-backup is\n"+
+			"specified. When there is nothing to prune the file is left alone\n"+
+			"and the command exits with code 6.\n\n"+
+			"Examples:\n"+
+			"  houston prune-h game.h1 --score-years 50 --score-interval 10 --dry-run\n"+
+			"  houston prune-h game.h1 --planet-years 100",
+		&pruneHCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
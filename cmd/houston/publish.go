@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/maprenderer"
+	"github.com/neper-stars/houston/lib/tools/publish"
+)
+
+type publishCommand struct {
+	Dir       string `short:"d" long:"dir" description:"Directory holding the game files of every turn" required:"true"`
+	Out       string `short:"o" long:"out" description:"Output directory for the site" default:"site"`
+	ShowNames bool   `short:"n" long:"names" description:"Show planet names on the maps"`
+	ShowMines bool   `short:"m" long:"mines" description:"Show minefields on the maps"`
+	Colors    string `long:"colors" description:"Player colors: palette name (default, colorblind) or comma-separated hex list"`
+}
+
+func (c *publishCommand) Execute(args []string) error {
+	files, err := findMFilesMap(c.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %w", c.Dir, err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no Stars! game files found in %s", c.Dir)
+	}
+
+	stores, err := loadTurnStores(files)
+	if err != nil {
+		return err
+	}
+
+	mapOpts := maprenderer.DefaultOptions()
+	mapOpts.ShowNames = c.ShowNames
+	mapOpts.ShowMines = c.ShowMines
+	if c.Colors != "" {
+		if mapOpts.PlayerColors, err = maprenderer.ParsePlayerColors(c.Colors); err != nil {
+			return fmt.Errorf("invalid --colors: %w", err)
+		}
+	}
+
+	archive := publish.Build(stores)
+	bar := newProgressBar("Writing pages", uint64(len(archive.Turns)))
+	err = archive.Write(c.Out, &publish.Options{
+		Map:      mapOpts,
+		Progress: func(done, total int) { bar.Update(uint64(done)) },
+	})
+	bar.Finish()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Published %d turns to %s\n", len(archive.Turns), c.Out)
+	return nil
+}
+
+func addPublishCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("publish",
+		"Generate a static website for a game archive",
+		"Generates a browsable static website from a directory holding the files\n"+
+			"of a whole game (M, H, HST and XY files of every turn): an index page with\n"+
+			"a score chart, final standings and a list of turns, and one page per turn\n"+
+			"with the galaxy map, the scores and the battles reported that year.\n\n"+
+			"Files from the same turn are merged, so including every player's M files\n"+
+			"(or the host files) gives the most complete picture.\n\n"+
+			"Example:\n"+
+			"  houston publish --dir archive/ --out site/",
+		&publishCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -4,12 +4,12 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/jessevdk/go-flags"
 
+	"github.com/neper-stars/houston/filenames"
 	"github.com/neper-stars/houston/lib/tools/racefixer"
+	"github.com/neper-stars/houston/race"
 )
 
 type raceCommand struct {
@@ -23,8 +23,7 @@ func (c *raceCommand) Execute(args []string) error {
 	filename := c.Args.File
 
 	// Validate file extension
-	ext := strings.ToLower(filepath.Ext(filename))
-	if len(ext) < 2 || ext[1] != 'r' {
+	if filenames.KindOf(filename) != filenames.R {
 		return fmt.Errorf("%s does not appear to be a race file", filename)
 	}
 
@@ -113,9 +112,10 @@ func addRaceCommand(parser *flags.Parser) {
 }
 
 type racePasswordCommand struct {
-	NoBackup bool `short:"n" long:"no-backup" description:"Don't create backup file"`
+	Set      string `long:"set" value-name:"PASSWORD" description:"Set or change the password instead of removing it"`
+	NoBackup bool   `short:"n" long:"no-backup" description:"Don't create backup file"`
 	Args     struct {
-		File string `positional-arg-name:"file" description:"Race file to remove password from" required:"true"`
+		File string `positional-arg-name:"file" description:"Race file to remove or set the password of" required:"true"`
 	} `positional-args:"yes"`
 }
 
@@ -123,8 +123,7 @@ func (c *racePasswordCommand) Execute(args []string) error {
 	filename := c.Args.File
 
 	// Validate file extension
-	ext := strings.ToLower(filepath.Ext(filename))
-	if len(ext) < 2 || ext[1] != 'r' {
+	if filenames.KindOf(filename) != filenames.R {
 		return fmt.Errorf("%s does not appear to be a race file", filename)
 	}
 
@@ -143,6 +142,10 @@ func (c *racePasswordCommand) Execute(args []string) error {
 	fmt.Printf("File: %s\n", info.Filename)
 	fmt.Printf("Race: %s (%s)\n", info.SingularName, info.PluralName)
 
+	if c.Set != "" {
+		return c.setPassword(filename, data, info.HasPassword)
+	}
+
 	if !info.HasPassword {
 		fmt.Println("This race file does not have a password.")
 		return nil
@@ -180,13 +183,44 @@ func (c *racePasswordCommand) Execute(args []string) error {
 	return nil
 }
 
+// setPassword protects the race file with the --set password.
+func (c *racePasswordCommand) setPassword(filename string, data []byte, hadPassword bool) error {
+	out, err := race.SetPassword(data, c.Set)
+	if err != nil {
+		return fmt.Errorf("error setting password: %w", err)
+	}
+
+	if !c.NoBackup {
+		backupFile := filename + ".backup"
+		if err := copyFileRace(filename, backupFile); err != nil {
+			return fmt.Errorf("error creating backup: %w", err)
+		}
+		fmt.Printf("Created backup: %s\n", backupFile)
+	}
+
+	if err := os.WriteFile(filename, out, 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	if hadPassword {
+		fmt.Println("Password changed successfully")
+	} else {
+		fmt.Println("Password set successfully")
+	}
+	return nil
+}
+
 func addRacePasswordCommand(parser *flags.Parser) {
 	_, err := parser.AddCommand("race-password",
-		"Remove password from race files",
+		"Remove or set the password of race files",
 		"Removes the password from a Stars! race file.\n\n"+
 			"This allows the race to be used without entering a password.\n"+
+			"With --set, the race is protected by the given password instead,\n"+
+			"replacing the one it had.\n"+
 			"The file checksum is automatically recalculated.\n\n"+
-			"A backup of the original file will be created unless --no-backup is specified.",
+			"A backup of the original file will be created unless --no-backup is specified.\n\n"+
+			"Examples:\n"+
+			"  houston race-password MyRace.r1\n"+
+			"  houston race-password --set secret MyRace.r1",
 		&racePasswordCommand{})
 	if err != nil {
 		panic(err)
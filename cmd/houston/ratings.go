@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/rating"
+	"github.com/neper-stars/houston/lib/tools/report"
+	"github.com/neper-stars/houston/store"
+)
+
+type ratingsCommand struct {
+	DB      string   `short:"d" long:"db" description:"Ratings file (JSON) to update; created when missing"`
+	Players []string `short:"p" long:"player" description:"League name of a player number in the games given, as number=name (repeatable)"`
+	K       float64  `short:"k" long:"k-factor" description:"Elo K-factor of a new ratings file (default 32)"`
+	Format  string   `short:"f" long:"format" description:"Output format: text, markdown or html" default:"text"`
+	Args    struct {
+		Files []string `positional-arg-name:"file" description:"Final turn files of the games: M files or ZIP archives"`
+	} `positional-args:"yes"`
+}
+
+func (c *ratingsCommand) Execute(args []string) error {
+	if c.DB == "" && len(c.Args.Files) == 0 {
+		return fmt.Errorf("give games to rate, a ratings file (--db), or both")
+	}
+	names, err := parseRatingNames(c.Players)
+	if err != nil {
+		return err
+	}
+	fsys := gameFS()
+
+	config := rating.DefaultConfig()
+	if c.K > 0 {
+		config.K = c.K
+	}
+	league := rating.New(config)
+	if c.DB != "" {
+		data, err := fsys.ReadFile(c.DB)
+		switch {
+		case err == nil:
+			if league, err = rating.Load(bytes.NewReader(data)); err != nil {
+				return fmt.Errorf("%s: %w", c.DB, err)
+			}
+		case !errors.Is(err, os.ErrNotExist):
+			return fmt.Errorf("error reading %s: %w", c.DB, err)
+		}
+	}
+
+	// Each player's M file holds only that player's score: merge the files
+	// of each game
+	games := make(map[uint32]*store.GameStore)
+	var order []uint32
+	for _, filename := range c.Args.Files {
+		gs := store.New()
+		if err := gs.AddFileWithXYFromFS(filename, fsys); err != nil {
+			return fmt.Errorf("failed to load %s: %w", filename, err)
+		}
+		if existing, ok := games[gs.GameID]; ok {
+			if err := existing.AddFileWithXYFromFS(filename, fsys); err != nil {
+				return fmt.Errorf("failed to load %s: %w", filename, err)
+			}
+			continue
+		}
+		games[gs.GameID] = gs
+		order = append(order, gs.GameID)
+	}
+
+	for _, id := range order {
+		printStoreWarnings(games[id])
+	}
+
+	rated := 0
+	for _, id := range order {
+		game := rating.GameFromStore(games[id], names)
+		if err := league.Rate(game); err != nil {
+			if errors.Is(err, rating.ErrAlreadyRated) {
+				fmt.Fprintf(os.Stderr, "warning: game %s already rated, skipped\n", game.ID)
+				continue
+			}
+			return fmt.Errorf("failed to rate game %s: %w", game.ID, err)
+		}
+		rated++
+	}
+
+	if c.DB != "" && rated > 0 {
+		var buf bytes.Buffer
+		if err := league.Save(&buf); err != nil {
+			return err
+		}
+		if err := fsys.WriteFile(c.DB, buf.Bytes()); err != nil {
+			return fmt.Errorf("error writing %s: %w", c.DB, err)
+		}
+	}
+
+	doc := &report.Document{
+		Title:    "Ratings",
+		Subtitle: fmt.Sprintf("%d game(s) rated", len(league.Games)),
+	}
+	table := doc.AddSection("").AddTable(
+		report.Column{Header: "#", Numeric: true},
+		report.Column{Header: "Player"},
+		report.Column{Header: "Elo", Numeric: true},
+		report.Column{Header: "Glicko", Numeric: true},
+		report.Column{Header: "RD", Numeric: true},
+		report.Column{Header: "Games", Numeric: true},
+		report.Column{Header: "Wins", Numeric: true},
+	)
+	for i, r := range league.Ratings() {
+		table.AddRow(strconv.Itoa(i+1), r.Player,
+			fmt.Sprintf("%.0f", r.Elo), fmt.Sprintf("%.0f", r.Glicko), fmt.Sprintf("%.0f", r.RD),
+			strconv.Itoa(r.Games), strconv.Itoa(r.Wins))
+	}
+	return renderReport(c.Format, doc)
+}
+
+// parseRatingNames parses the number=name values of --player.
+func parseRatingNames(values []string) (map[int]string, error) {
+	names := make(map[int]string)
+	for _, v := range values {
+		number, name, ok := strings.Cut(v, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(number))
+		if !ok || err != nil || n < 1 || n > 16 || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid --player %q, expected number=name with a number from 1 to 16", v)
+		}
+		names[n-1] = strings.TrimSpace(name)
+	}
+	return names, nil
+}
+
+func addRatingsCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("ratings",
+		"Rate league players from completed games",
+		"Updates Elo and Glicko ratings of league players from the final\n"+
+			"standings of completed games, ranked by score, and prints the table.\n\n"+
+			"Games are given by the M files of their final turn, or ZIP archives of\n"+
+			"them. An M file only holds the score of its player, so give the files of\n"+
+			"every player; files are grouped by game, and players without a score are\n"+
+			"left out. Players are known by their race name; --player names them\n"+
+			"otherwise, e.g. when a player changes races between games:\n\n"+
+			"  houston ratings --db league.json -p 1=alice -p 2=bob final/*.m*\n\n"+
+			"The ratings file keeps the games already rated, so that a game given\n"+
+			"twice is counted once. Without games, the table of the file is printed.",
+		&ratingsCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"github.com/neper-stars/houston/remotefs"
+)
+
+// gameFS returns the file system of the commands accepting remote paths
+// ("gdrive:stars/game.m1"), read and written with the rclone of --rclone.
+func gameFS() remotefs.FS {
+	r := remotefs.NewRclone()
+	if globals.Rclone != "" {
+		r.Binary = globals.Rclone
+	}
+	return remotefs.FS{Remote: r}
+}
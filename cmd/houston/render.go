@@ -0,0 +1,16 @@
+package main
+
+import (
+	"os"
+
+	"github.com/neper-stars/houston/lib/tools/report"
+)
+
+// renderReport writes a report to stdout in the requested format.
+func renderReport(format string, doc *report.Document) error {
+	r, err := report.NewRenderer(format)
+	if err != nil {
+		return err
+	}
+	return r.Render(os.Stdout, doc)
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/eventlog"
+	"github.com/neper-stars/houston/lib/tools/replay"
+	"github.com/neper-stars/houston/lib/tools/report"
+	"github.com/neper-stars/houston/parser"
+)
+
+type replayCommand struct {
+	Dir    string   `short:"d" long:"dir" description:"Directory holding the game files of every turn and the event logs (*.jsonl)" default:"."`
+	Game   uint32   `short:"g" long:"game" description:"Only replay the game with this ID, for archives holding several games"`
+	Events []string `short:"e" long:"events" description:"Additional event log (JSON lines) to include (repeatable)"`
+	Format string   `short:"f" long:"format" description:"Output format: text, markdown or html" default:"text"`
+	Step   bool     `short:"s" long:"step" description:"Step through the years, pressing Enter for the next one"`
+}
+
+func (c *replayCommand) Execute(args []string) error {
+	files, err := findMFilesMap(c.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %w", c.Dir, err)
+	}
+	stores, err := loadTurnStoresOfGame(files, c.Game)
+	if err != nil {
+		return err
+	}
+
+	logs, err := filepath.Glob(filepath.Join(c.Dir, "*.jsonl"))
+	if err != nil {
+		return err
+	}
+	var events []eventlog.Event
+	for _, path := range append(logs, c.Events...) {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		logged, err := eventlog.Read(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		for _, e := range logged {
+			if c.Game == 0 || e.GameID == 0 || e.GameID == c.Game {
+				events = append(events, e)
+			}
+		}
+	}
+
+	story := replay.Build(stores, events)
+	if len(story.Chapters) == 0 {
+		return fmt.Errorf("no turns or events found in %s", c.Dir)
+	}
+	if c.Step {
+		return c.step(story)
+	}
+
+	doc := storyDocument(story)
+	for _, ch := range story.Chapters {
+		if len(ch.Narration) > 0 {
+			addChapter(doc, ch)
+		}
+	}
+	if last := story.Chapters[len(story.Chapters)-1]; len(last.Standings) > 0 {
+		addStandings(doc.AddSection(fmt.Sprintf("Standings in %d", last.Year)), story, last)
+	}
+	return renderReport(c.Format, doc)
+}
+
+// step prints one year at a time, waiting for Enter between years.
+func (c *replayCommand) step(story *replay.Story) error {
+	input := bufio.NewReader(os.Stdin)
+	for i, ch := range story.Chapters {
+		doc := &report.Document{}
+		if i == 0 {
+			doc = storyDocument(story)
+		}
+		addChapter(doc, ch)
+		if len(ch.Standings) > 0 {
+			addStandings(doc.Sections[len(doc.Sections)-1], story, ch)
+		}
+		if err := renderReport("text", doc); err != nil {
+			return err
+		}
+		if i == len(story.Chapters)-1 {
+			break
+		}
+		fmt.Printf("\n[%d/%d] Enter for %d, q to quit: ", i+1, len(story.Chapters), story.Chapters[i+1].Year)
+		line, err := input.ReadString('\n')
+		if err != nil || strings.EqualFold(strings.TrimSpace(line), "q") {
+			fmt.Println()
+			return nil
+		}
+	}
+	return nil
+}
+
+func storyDocument(story *replay.Story) *report.Document {
+	title := "The story of the game"
+	if story.GameName != "" {
+		title = "The story of " + story.GameName
+	}
+	first, last := story.Chapters[0].Year, story.Chapters[len(story.Chapters)-1].Year
+	return &report.Document{
+		Title:    title,
+		Subtitle: fmt.Sprintf("Game ID %d, %d to %d", story.GameID, first, last),
+	}
+}
+
+func addChapter(doc *report.Document, ch *replay.Chapter) {
+	section := doc.AddSection(strconv.Itoa(ch.Year))
+	if len(ch.Narration) == 0 {
+		section.AddParagraph("A quiet year.")
+		return
+	}
+	section.AddList(ch.Narration...)
+}
+
+func addStandings(section *report.Section, story *replay.Story, ch *replay.Chapter) {
+	table := section.AddTable(
+		report.Column{Header: "Player"},
+		report.Column{Header: "Score", Numeric: true},
+	)
+	for _, s := range ch.Standings {
+		table.AddRow(fmt.Sprintf("%d %s", s.Player, story.Players[s.Player]), strconv.Itoa(s.Score))
+	}
+}
+
+// filesOfGame keeps the files whose header carries the game ID.
+func filesOfGame(files []string, gameID uint32) []string {
+	var kept []string
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		header, err := parser.FileData(data).FileHeader()
+		if err != nil || header.GameID != gameID {
+			continue
+		}
+		kept = append(kept, path)
+	}
+	return kept
+}
+
+func addReplayCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("replay",
+		"Tell the story of a game from its archive",
+		"Reconstructs the story of a game, year by year, from a directory holding\n"+
+			"the files of every turn and the event logs written by 'houston events'\n"+
+			"(*.jsonl): score leader changes, battles, colonies, random events,\n"+
+			"bombardments, research milestones and new starbases. An event seen by\n"+
+			"several players is told once.\n\n"+
+			"The story is rendered as text, Markdown or an HTML timeline; with\n"+
+			"--step, the years are shown one at a time on the terminal.\n\n"+
+			"Examples:\n"+
+			"  houston replay --dir archive/ --game 1234 --step\n"+
+			"  houston replay --dir archive/ -f html > story.html",
+		&replayCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -4,19 +4,23 @@ import (
 	_ "embed"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/jessevdk/go-flags"
 
+	"github.com/neper-stars/houston/lib/tools/report"
 	"github.com/neper-stars/houston/lib/tools/reporter"
+	"github.com/neper-stars/houston/store"
 )
 
 //go:embed resources/empty.ods
 var embeddedTemplate []byte
 
 type reportCommand struct {
-	Output    string `short:"o" long:"output" description:"Output ODS filename" default:"report.ods"`
-	Template  string `short:"t" long:"template" description:"Template ODS file (uses embedded template by default)"`
+	Output    string `short:"o" long:"output" description:"Output filename (default report.ods, or stdout with a text template)"`
+	Template  string `short:"t" long:"template" description:"Template ODS file, or Go text/template file for a custom report"`
 	Player    int    `short:"p" long:"player" description:"Player number (1-16, auto-detected from M-file if not specified)"`
 	Threshold int64  `long:"threshold" description:"Mineral threshold for shuffle analysis" default:"500"`
 	Args      struct {
@@ -25,6 +29,13 @@ type reportCommand struct {
 }
 
 func (c *reportCommand) Execute(args []string) error {
+	if c.Template != "" && !strings.EqualFold(filepath.Ext(c.Template), ".ods") {
+		return c.executeTemplate()
+	}
+	if c.Output == "" {
+		c.Output = "report.ods"
+	}
+
 	startTime := time.Now()
 	defer func() {
 		fmt.Printf("  Generated in: %v\n", time.Since(startTime))
@@ -70,7 +81,7 @@ func (c *reportCommand) Execute(args []string) error {
 		// Auto-detect from M-file
 		detected := rep.DetectedPlayerNumber()
 		if detected < 0 {
-			return fmt.Errorf("could not auto-detect player number: no M-file loaded")
+			return errNoPlayerDetected
 		}
 		playerNumber = detected
 		fmt.Printf("Auto-detected player %d from M-file\n", playerNumber+1)
@@ -94,6 +105,53 @@ func (c *reportCommand) Execute(args []string) error {
 	return nil
 }
 
+// executeTemplate renders a custom text report from a Go text/template
+// file over the report.Data model.
+func (c *reportCommand) executeTemplate() error {
+	tmpl, err := report.ParseTemplateFile(c.Template)
+	if err != nil {
+		return fmt.Errorf("failed to load template: %w", err)
+	}
+
+	gs := store.New()
+	for _, filename := range c.Args.Files {
+		if err := gs.AddFileWithXY(filename); err != nil {
+			return fmt.Errorf("failed to load %s: %w", filename, err)
+		}
+	}
+	printStoreWarnings(gs)
+
+	playerNumber := c.Player - 1
+	if c.Player == 0 {
+		playerNumber = detectPlayerNumber(gs)
+		if playerNumber < 0 {
+			return errNoPlayerDetected
+		}
+	}
+
+	data, err := report.NewData(gs, playerNumber)
+	if err != nil {
+		return err
+	}
+	if book := loadNotes(c.Args.Files[0], gs.GameID); book != nil {
+		data.AddNotes(book)
+	}
+
+	if c.Output == "" {
+		return report.Execute(os.Stdout, tmpl, data)
+	}
+
+	f, err := os.Create(c.Output)
+	if err != nil {
+		return err
+	}
+	if err := report.Execute(f, tmpl, data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func addReportCommand(parser *flags.Parser) {
 	_, err := parser.AddCommand("report",
 		"Generate analysis report as ODS spreadsheet",
@@ -110,9 +168,15 @@ func addReportCommand(parser *flags.Parser) {
 			"The player number is automatically detected from the M-file.\n"+
 			"If the output file already exists, it will be updated with the new turn's data\n"+
 			"while preserving historical information.\n\n"+
+			"Custom reports: when --template is not an .ods file, it is read as a Go\n"+
+			"text/template and executed over the report data model (game, player,\n"+
+			"players, planets, fleets, empire summary and the notes kept with\n"+
+			"'houston note'; see the report.Data documentation). The result is\n"+
+			"written to stdout unless -o is given.\n\n"+
 			"Example:\n"+
 			"  houston report game.m1 -o game-report.ods\n"+
-			"  houston report game.m1 game.h1 -o game-report.ods",
+			"  houston report game.m1 game.h1 -o game-report.ods\n"+
+			"  houston report --template bulletin.tmpl game.m1 > bulletin.txt",
 		&reportCommand{})
 	if err != nil {
 		panic(err)
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/lib/tools/report"
+	"github.com/neper-stars/houston/lib/tools/research"
+	"github.com/neper-stars/houston/store"
+)
+
+type researchCommand struct {
+	Player  int    `short:"p" long:"player" description:"Player number (1-16, auto-detected from M-file if not specified)"`
+	Format  string `short:"f" long:"format" description:"Output format: text, markdown or html" default:"text"`
+	PerKind int    `short:"n" long:"per-kind" description:"Breakpoints listed per kind of tech (0 for all)" default:"2"`
+	Args    struct {
+		Files []string `positional-arg-name:"file" description:"Stars! game files (.m, .xy)" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (c *researchCommand) Execute(args []string) error {
+	gs := store.New()
+	for _, filename := range c.Args.Files {
+		if err := gs.AddFileWithXY(filename); err != nil {
+			return fmt.Errorf("failed to load %s: %w", filename, err)
+		}
+	}
+	printStoreWarnings(gs)
+
+	playerNumber := c.Player - 1
+	if c.Player == 0 {
+		playerNumber = detectPlayerNumber(gs)
+		if playerNumber < 0 {
+			return errNoPlayerDetected
+		}
+	}
+
+	plan, err := research.NewPlan(gs, playerNumber)
+	if err != nil {
+		return err
+	}
+
+	doc := &report.Document{
+		Title:    fmt.Sprintf("%s (player %d), year %d", plan.Player.NamePlural, playerNumber+1, int(gs.Turn)+blocks.StarsBaseYear),
+		Subtitle: "Research plan",
+	}
+
+	fields := doc.AddSection("Research")
+	fields.AddFields(
+		report.F("Budget", "%d resources per year", plan.Budget),
+		report.F("Researching", "%s (next: %s)", blocks.ResearchFieldName(plan.Current), blocks.ResearchFieldName(plan.Next)),
+	)
+	table := fields.AddTable(
+		report.Column{Header: "Field"},
+		report.Column{Header: "Level", Numeric: true},
+		report.Column{Header: "Cost"},
+		report.Column{Header: "Spent", Numeric: true},
+		report.Column{Header: "To next level", Numeric: true},
+		report.Column{Header: "Years", Numeric: true},
+	)
+	for _, f := range plan.Fields {
+		table.AddRow(blocks.ResearchFieldName(f.Field), strconv.Itoa(f.Level), costFactorName(f.Factor),
+			strconv.Itoa(f.Progress), strconv.Itoa(f.NextCost), researchYears(f.Years))
+	}
+
+	breakpoints := doc.AddSection("Breakpoints")
+	list := plan.Breakpoints(&research.BreakpointOptions{PerKind: c.PerKind})
+	if len(list) == 0 {
+		breakpoints.AddFields(report.F("Breakpoints", "none left"))
+	} else {
+		table := breakpoints.AddTable(
+			report.Column{Header: "Tech"},
+			report.Column{Header: "Kind"},
+			report.Column{Header: "Requires"},
+			report.Column{Header: "Levels", Numeric: true},
+			report.Column{Header: "Cost", Numeric: true},
+			report.Column{Header: "Years", Numeric: true},
+		)
+		for _, b := range list {
+			table.AddRow(b.Name, b.Kind, formatRequirements(b.Tech), strconv.Itoa(b.Levels),
+				strconv.Itoa(b.Cost), researchYears(b.Years))
+		}
+	}
+
+	return renderReport(c.Format, doc)
+}
+
+func costFactorName(factor float64) string {
+	switch factor {
+	case data.ResearchFactorExpensive:
+		return "expensive"
+	case data.ResearchFactorCheap:
+		return "cheap"
+	}
+	return "normal"
+}
+
+func researchYears(years int) string {
+	if years < 0 {
+		return "-"
+	}
+	return strconv.Itoa(years)
+}
+
+// formatRequirements lists the fields a tech needs, in the order of the
+// research screen.
+func formatRequirements(t data.TechRequirements) string {
+	var parts []string
+	for _, f := range []struct {
+		name  string
+		level int
+	}{{"En", t.Energy}, {"We", t.Weapons}, {"Pr", t.Propulsion}, {"Co", t.Construction}, {"El", t.Electronics}, {"Bi", t.Biotech}} {
+		if f.level > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", f.name, f.level))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func addResearchCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("research",
+		"Plan research and list the next tech breakpoints",
+		"Prints the research plan of a player: for each field its level, cost\n"+
+			"setting, the resources spent toward the next level and what is left,\n"+
+			"and the years it takes on the research budget (what was spent on\n"+
+			"research last year).\n\n"+
+			"The breakpoints are the closest techs that change what you can build:\n"+
+			"new ship and starbase hulls, beam weapons and torpedoes hitting harder\n"+
+			"or further than your best, better ship and planetary scanners and\n"+
+			"faster terraforming. Each comes with the research left to reach it\n"+
+			"and the years if the whole budget goes to it. Techs your race can\n"+
+			"never use are left out.",
+		&researchCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
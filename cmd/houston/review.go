@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/houserules"
+	"github.com/neper-stars/houston/lib/tools/review"
+)
+
+type reviewCommand struct {
+	Pending string `long:"pending" description:"Directory of the submitted X files awaiting review (default: <hostdir>/pending)"`
+	Approve []int  `short:"a" long:"approve" description:"Approve a player's orders, moving them into the host directory (repeatable)"`
+	Bounce  []int  `short:"b" long:"bounce" description:"Bounce a player's orders, moving them to <hostdir>/bounced (repeatable)"`
+	Reason  string `short:"r" long:"reason" description:"Reason for bouncing, saved next to the bounced file"`
+	Orders  bool   `long:"orders" description:"List every decoded order"`
+	Rules   string `long:"rules" description:"YAML file of house rules to check each submission against"`
+	Archive string `long:"archive" description:"Directory of earlier turns' M files, for the house rules spanning several years"`
+	Enforce bool   `long:"enforce" description:"Bounce the submissions breaking house rules, with the broken rules as reason"`
+	Args    struct {
+		Dir string `positional-arg-name:"hostdir" description:"Host directory containing the .hst and M files" required:"true"`
+	} `positional-args:"yes"`
+}
+
+func (c *reviewCommand) Execute(args []string) error {
+	q := review.New(c.Args.Dir, c.Pending)
+
+	for _, player := range c.Approve {
+		if slices.Contains(c.Bounce, player) {
+			return fmt.Errorf("player %d cannot be both approved and bounced", player)
+		}
+	}
+	if len(c.Approve) > 0 || len(c.Bounce) > 0 {
+		return c.decide(q)
+	}
+	if c.Enforce && c.Rules == "" {
+		return fmt.Errorf("--enforce requires --rules")
+	}
+	var rules *houserules.Rules
+	if c.Rules != "" {
+		r, err := houserules.Load(c.Rules)
+		if err != nil {
+			return err
+		}
+		rules = r
+	}
+
+	subs, header, err := q.Review()
+	if err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		fmt.Printf("No orders pending review in %s\n", q.Pending)
+		return nil
+	}
+
+	host, _ := q.HostFile()
+	fmt.Printf("Host file: %s (Game ID %d, Year %d)\n", host, header.GameID, header.Year())
+	fmt.Printf("%d submission(s) pending review:\n", len(subs))
+	flagged := 0
+	var broken []*review.Submission
+	for _, s := range subs {
+		if rules != nil && s.Info != nil {
+			c.checkRules(q, s, rules)
+		}
+		for _, w := range s.Warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+		if s.Flagged() {
+			flagged++
+		}
+		if len(s.Broken) > 0 {
+			broken = append(broken, s)
+		}
+		c.printSubmission(s)
+	}
+
+	fmt.Println()
+	if c.Enforce && len(broken) > 0 {
+		for _, s := range broken {
+			reasons := make([]string, 0, len(s.Broken))
+			for _, v := range s.Broken {
+				reasons = append(reasons, "House rule "+v.String())
+			}
+			dest, err := q.Bounce(s.Player, strings.Join(reasons, "\n"))
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Bounced player %d: %s\n", s.Player, dest)
+			flagged--
+		}
+		fmt.Println()
+	}
+	if flagged > 0 {
+		fmt.Printf("%d submission(s) flagged. ", flagged)
+	}
+	fmt.Println("Approve with --approve N or bounce with --bounce N --reason TEXT.")
+	return nil
+}
+
+// checkRules checks a submission against the house rules, using the
+// player's M file and those of earlier turns from the archive.
+func (c *reviewCommand) checkRules(q *review.Queue, s *review.Submission, rules *houserules.Rules) {
+	files, err := q.MFiles(s.Player, c.Archive)
+	if err != nil {
+		s.Violations = append(s.Violations, err.Error())
+		return
+	}
+	if len(files) == 0 {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("no M file of player %d, house rules not checked", s.Player))
+		return
+	}
+
+	turns, err := loadTurnStores(files)
+	if err != nil {
+		s.Violations = append(s.Violations, fmt.Sprintf("house rules not checked: %v", err))
+		return
+	}
+	s.Broken = rules.Check(&houserules.Submission{Player: s.Player - 1, Orders: s.Info, Turns: turns})
+}
+
+func (c *reviewCommand) printSubmission(s *review.Submission) {
+	fmt.Println()
+	status := "OK"
+	if s.Flagged() {
+		status = "FLAGGED"
+	}
+	fmt.Printf("Player %d: %s [%s]\n", s.Player, filepath.Base(s.Path), status)
+
+	if s.Info != nil {
+		fmt.Printf("  Year %d, %d order(s)\n", s.Info.Year, len(s.Info.Orders))
+		if c.Orders {
+			for _, order := range s.Info.Orders {
+				fmt.Printf("    %s\n", order.Description)
+			}
+		} else {
+			counts := make(map[string]int)
+			var types []string
+			for _, order := range s.Info.Orders {
+				if counts[order.Type] == 0 {
+					types = append(types, order.Type)
+				}
+				counts[order.Type]++
+			}
+			slices.Sort(types)
+			for _, t := range types {
+				fmt.Printf("    %s: %d\n", t, counts[t])
+			}
+		}
+	}
+	for _, v := range s.Violations {
+		fmt.Printf("  ! %s\n", v)
+	}
+	for _, d := range s.Detections {
+		fmt.Printf("  ! %s\n", d)
+	}
+	for _, v := range s.Broken {
+		fmt.Printf("  ! house rule %s\n", v)
+	}
+}
+
+// decide moves the approved orders into the host directory and the bounced
+// ones into <hostdir>/bounced.
+func (c *reviewCommand) decide(q *review.Queue) error {
+	for _, player := range c.Approve {
+		dest, err := q.Approve(player)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Approved player %d: %s\n", player, dest)
+	}
+	for _, player := range c.Bounce {
+		dest, err := q.Bounce(player, c.Reason)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Bounced player %d: %s\n", player, dest)
+	}
+	return nil
+}
+
+func addReviewCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("review",
+		"Review and approve submitted orders before generating a turn",
+		"Stages submitted X files for host approval. Players' files are collected\n"+
+			"in <hostdir>/pending; without options, each submission is decoded and\n"+
+			"checked against the host file (game, year, player, submit flag) and\n"+
+			"scanned for exploits using the player's M file.\n\n"+
+			"The host then approves orders, moving them next to the .hst file where\n"+
+			"turn generation picks them up, or bounces them to <hostdir>/bounced\n"+
+			"with an optional reason for the player.\n\n"+
+			"With --rules, submissions are also checked against the house rules of\n"+
+			"a YAML file (max_chaff, no_pop_drop, min_nap_years); the rules spanning\n"+
+			"several years need the players' earlier M files from --archive. With\n"+
+			"--enforce, submissions breaking a house rule are bounced right away,\n"+
+			"the broken rules being saved as the reason.\n\n"+
+			"Examples:\n"+
+			"  houston review game/\n"+
+			"  houston review game/ --rules rules.yaml --archive game/archive --enforce\n"+
+			"  houston review game/ --approve 1 --approve 3\n"+
+			"  houston review game/ --bounce 2 --reason 'friendly fire battle plan'",
+		&reviewCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
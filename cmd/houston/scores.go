@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/export"
+	"github.com/neper-stars/houston/lib/tools/maprenderer"
+	"github.com/neper-stars/houston/lib/tools/scores"
+)
+
+type scoresCommand struct {
+	Dir     string `short:"d" long:"dir" description:"Archive directory holding the files of every turn"`
+	Game    uint32 `short:"g" long:"game" description:"Only use the files of the game with this ID, for directories holding several games"`
+	Players string `short:"p" long:"players" description:"Comma-separated players (1-16) to score (default: all)"`
+	Format  string `short:"f" long:"format" description:"Output format: csv or json" default:"csv"`
+	Output  string `short:"o" long:"output" description:"Output file (default stdout)"`
+	Chart   string `long:"chart" description:"Also chart score, resources and tech levels over time to this file, SVG when it ends with .svg, PNG otherwise"`
+	Width   int    `short:"W" long:"width" description:"Chart width in pixels" default:"800"`
+	Height  int    `short:"H" long:"height" description:"Chart height in pixels" default:"600"`
+	Colors  string `long:"colors" description:"Player colors of the chart: palette name (default, colorblind) or comma-separated hex list"`
+	Args    struct {
+		Files []string `positional-arg-name:"file" description:"Stars! game files, one or more per turn"`
+	} `positional-args:"yes"`
+}
+
+func (c *scoresCommand) Execute(args []string) error {
+	format := strings.ToLower(c.Format)
+	if format != "json" && format != "csv" {
+		return fmt.Errorf("unknown format %q (want csv or json)", c.Format)
+	}
+	var players []int
+	if c.Players != "" {
+		for _, field := range strings.Split(c.Players, ",") {
+			player, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || player < 1 || player > 16 {
+				return fmt.Errorf("invalid --players: %q is not a player number (1-16)", field)
+			}
+			players = append(players, player-1)
+		}
+	}
+	chartOpts := scores.ChartOptions{Width: c.Width, Height: c.Height}
+	if c.Colors != "" {
+		colors, err := maprenderer.ParsePlayerColors(c.Colors)
+		if err != nil {
+			return fmt.Errorf("invalid --colors: %w", err)
+		}
+		chartOpts.Colors = colors
+	}
+
+	files := c.Args.Files
+	if c.Dir != "" {
+		found, err := findMFilesMap(c.Dir)
+		if err != nil {
+			return fmt.Errorf("failed to read directory %s: %w", c.Dir, err)
+		}
+		files = append(files, found...)
+	}
+	if len(files) == 0 {
+		return errors.New("no input files specified (use --dir or list the files)")
+	}
+	stores, err := loadTurnStoresOfGame(files, c.Game)
+	if err != nil {
+		return err
+	}
+	points, err := scores.Collect(stores, players...)
+	if err != nil {
+		return err
+	}
+
+	err = writeOutput(c.Output, func(w io.Writer) error {
+		if format == "json" {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(points)
+		}
+		table, err := export.TableOf("scores", points)
+		if err != nil {
+			return err
+		}
+		return table.WriteCSV(w)
+	})
+	if err != nil {
+		return err
+	}
+	if c.Output != "" {
+		fmt.Printf("Wrote %d scores (%d-%d) to %s\n", len(points), points[0].Year, points[len(points)-1].Year, c.Output)
+	}
+
+	if c.Chart != "" {
+		err = writeOutput(c.Chart, func(w io.Writer) error {
+			if strings.EqualFold(filepath.Ext(c.Chart), ".svg") {
+				_, err := io.WriteString(w, scores.ChartSVG(points, chartOpts))
+				return err
+			}
+			return scores.WriteChartPNG(w, points, chartOpts)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write chart: %w", err)
+		}
+		if c.Output != "" {
+			fmt.Printf("Wrote chart to %s\n", c.Chart)
+		}
+	}
+	return nil
+}
+
+func addScoresCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("scores",
+		"Export and chart the score history of a game",
+		"Writes the score of every player year by year from the files of a game\n"+
+			"archive, broken down into the components of the Stars! formula: planet\n"+
+			"population, resources, starbases, tech levels and ships, with the\n"+
+			"resources, planets, starbases, tech levels and ship counts they come\n"+
+			"from, and the score Stars! wrote in the file when there is one.\n\n"+
+			"A player is scored in the years holding their full data: their own M\n"+
+			"files, or the host files.\n\n"+
+			"--chart draws the score, resources and tech levels of the players over\n"+
+			"time as line charts in the player colors of the maps.\n\n"+
+			"Examples:\n"+
+			"  houston scores --dir archive/ -o scores.csv --chart scores.png\n"+
+			"  houston scores --dir archive/ --players 1,3 -f json\n"+
+			"  houston scores game-24*.hst --chart scores.svg > scores.csv",
+		&scoresCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+	"go.starlark.net/starlark"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/script"
+)
+
+type scriptCommand struct {
+	Player int `short:"p" long:"player" description:"Player number (1-16, auto-detected from M-file if not specified)"`
+	Args   struct {
+		Script string   `positional-arg-name:"script" description:"Starlark script (.star)" required:"true"`
+		Files  []string `positional-arg-name:"file" description:"Stars! game files from one or more turns" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (c *scriptCommand) Execute(args []string) error {
+	src, err := os.ReadFile(c.Args.Script)
+	if err != nil {
+		return fmt.Errorf("failed to read script: %w", err)
+	}
+
+	stores, err := loadTurnStores(c.Args.Files)
+	if err != nil {
+		return err
+	}
+
+	for i, gs := range stores {
+		printStoreWarnings(gs)
+		env := script.Env{Game: gs, Player: c.Player - 1}
+		if c.Player == 0 {
+			env.Player = detectPlayerNumber(gs)
+		}
+		if i > 0 {
+			env.Previous = stores[i-1]
+		}
+		if len(stores) > 1 {
+			fmt.Printf("== Year %d ==\n", int(gs.Turn)+blocks.StarsBaseYear)
+		}
+		if err := script.Run(os.Stdout, c.Args.Script, src, env); err != nil {
+			var evalErr *starlark.EvalError
+			if errors.As(err, &evalErr) {
+				fmt.Fprint(os.Stderr, evalErr.CallStack)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func addScriptCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("script",
+		"Run a Starlark analysis script over game files",
+		"Runs a script written in Starlark, a small dialect of Python, over the game\n"+
+			"state of each turn given, oldest first. Scripts read the game through these\n"+
+			"predeclared names:\n\n"+
+			"  game          the turn: game.turn, game.year, game.players, game.planets,\n"+
+			"                game.fleets, game.designs, game.minefields, game.wormholes\n"+
+			"                and game.messages, with the fields of the JSON game state\n"+
+			"  previous      the previous turn given, or None for the first\n"+
+			"  player        the player of the M file (0-15, as in owner fields), or -1\n"+
+			"  distance(a,b) distance between two values with x and y\n"+
+			"  json, math    the Starlark json and math modules\n\n"+
+			"Players are numbered from 0 in the game state; unowned planets have owner -1.\n"+
+			"Output goes to stdout with print(); fail(\"message\") stops the script.\n\n"+
+			"Example script:\n"+
+			"  for p in game.planets:\n"+
+			"      if p.owner == player and p.population < 10000:\n"+
+			"          print(\"%s is underpopulated\" % p.name)\n\n"+
+			"Examples:\n"+
+			"  houston script rules.star game.m1\n"+
+			"  houston script growth.star turns/*.m1",
+		&scriptCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/gamesetup"
+	"github.com/neper-stars/houston/store"
+)
+
+type settingsCommand struct {
+	Args struct {
+		File string `positional-arg-name:"file" description:"Stars! .xy or .hst file (or an .m/.h file next to its .xy)" required:"true"`
+	} `positional-args:"yes"`
+}
+
+func (c *settingsCommand) Execute(args []string) error {
+	gs := store.New()
+	if err := gs.AddFileWithXY(c.Args.File); err != nil {
+		return fmt.Errorf("failed to load %s: %w", c.Args.File, err)
+	}
+	printStoreWarnings(gs)
+	if gs.PlanetCount == 0 {
+		return fmt.Errorf("%s: %w", c.Args.File, errNoUniverse)
+	}
+
+	s := gamesetup.Describe(gs)
+
+	fmt.Printf("%s (game ID %d)\n", s.GameName, s.GameID)
+	fmt.Println(strings.Repeat("=", 60))
+
+	fmt.Printf("\nUniverse size:    %s\n", s.UniverseSize)
+	fmt.Printf("Density:          %s\n", s.Density)
+	fmt.Printf("Player positions: %s\n", s.StartingDistance)
+	fmt.Printf("Players:          %d\n", s.Players)
+	fmt.Printf("Planets:          %d\n", s.Planets)
+
+	fmt.Println("\nGame options:")
+	for _, o := range s.Options {
+		state := "off"
+		if o.Enabled {
+			state = "on"
+		}
+		fmt.Printf("  %-22s %s\n", o.Name, state)
+	}
+
+	fmt.Println("\nVictory conditions:")
+	for _, vc := range s.VictoryConditions {
+		if vc.Enabled {
+			fmt.Printf("  %s\n", vc.Description)
+		}
+	}
+	fmt.Printf("  Winner must meet %d of the above criteria\n", s.CriteriaToWin)
+	fmt.Printf("  At least %d years must pass before a winner is declared\n", s.MinYearsToWin)
+
+	return nil
+}
+
+func addSettingsCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("settings",
+		"Print the game setup options",
+		"Decodes and prints the options a game was created with: universe size,\n"+
+			"density, player positions, game options such as max minerals, slow tech,\n"+
+			"accelerated BBS play, public scores and random events, and the victory\n"+
+			"conditions with their thresholds.",
+		&settingsCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/starbaseadvisor"
+	"github.com/neper-stars/houston/store"
+)
+
+type starbasesCommand struct {
+	Player int `short:"p" long:"player" description:"Player number (1-16, auto-detected from M-file if not specified)"`
+	Top    int `short:"t" long:"top" description:"Number of upgrade options to show per planet (0 for all)" default:"3"`
+	Args   struct {
+		Files []string `positional-arg-name:"file" description:"Stars! game files (.m, .h, .xy)" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (c *starbasesCommand) Execute(args []string) error {
+	gs := store.New()
+	for _, filename := range c.Args.Files {
+		if err := gs.AddFileWithXY(filename); err != nil {
+			return fmt.Errorf("failed to load %s: %w", filename, err)
+		}
+	}
+	printStoreWarnings(gs)
+
+	playerNumber := c.Player - 1
+	if c.Player == 0 {
+		playerNumber = detectPlayerNumber(gs)
+		if playerNumber < 0 {
+			return errNoPlayerDetected
+		}
+	}
+
+	advice := starbaseadvisor.Advise(gs, playerNumber)
+	if len(advice) == 0 {
+		fmt.Println("No starbase upgrades available at current tech.")
+		return nil
+	}
+
+	for _, a := range advice {
+		fmt.Printf("%s (current: %s)\n", a.Planet.Name, a.Current.Name)
+		for i, u := range a.Upgrades {
+			if c.Top > 0 && i >= c.Top {
+				break
+			}
+			fmt.Printf("  -> %-24s %+6d firepower %+6d armor  cost %d res, %d/%d/%d kT  (%.2f per res)\n",
+				u.Design.Name, u.AddedFirepower, u.AddedArmor,
+				u.Cost.Resources, u.Cost.Ironium, u.Cost.Boranium, u.Cost.Germanium, u.Value)
+		}
+	}
+
+	return nil
+}
+
+func addStarbasesCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("starbases",
+		"Suggest starbase design upgrades",
+		"Compares each of your starbases against your other starbase designs\n"+
+			"that can be built at current tech, and lists upgrades ranked by\n"+
+			"strength (firepower plus armor and shields) gained per resource.\n\n"+
+			"Upgrade costs are estimated as the difference between the two designs.",
+		&starbasesCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/export"
+	"github.com/neper-stars/houston/lib/tools/stats"
+)
+
+type statsCommand struct {
+	Dir    string `short:"d" long:"dir" description:"Archive directory holding the files of every turn"`
+	Player int    `short:"p" long:"player" description:"Player number (1-16)" required:"true"`
+	Game   uint32 `short:"g" long:"game" description:"Only use the files of the game with this ID, for directories holding several games"`
+	Output string `short:"o" long:"output" description:"Output file, .xlsx for an Excel workbook, CSV otherwise (default: CSV on stdout)"`
+	Args   struct {
+		Files []string `positional-arg-name:"file" description:"Stars! game files, one or more per turn"`
+	} `positional-args:"yes"`
+}
+
+func (c *statsCommand) Execute(args []string) error {
+	if c.Player < 1 || c.Player > 16 {
+		return fmt.Errorf("invalid player number %d (want 1-16)", c.Player)
+	}
+	files := c.Args.Files
+	if c.Dir != "" {
+		found, err := findMFilesMap(c.Dir)
+		if err != nil {
+			return fmt.Errorf("failed to read directory %s: %w", c.Dir, err)
+		}
+		files = append(files, found...)
+	}
+	if len(files) == 0 {
+		return errors.New("no input files specified (use --dir or list the files)")
+	}
+	stores, err := loadTurnStoresOfGame(files, c.Game)
+	if err != nil {
+		return err
+	}
+	years, err := stats.Collect(stores, c.Player-1)
+	if err != nil {
+		return err
+	}
+	table, err := export.TableOf(fmt.Sprintf("Player %d", c.Player), years)
+	if err != nil {
+		return err
+	}
+
+	xlsx := strings.EqualFold(filepath.Ext(c.Output), ".xlsx")
+	err = writeOutput(c.Output, func(w io.Writer) error {
+		if xlsx {
+			return export.WriteXLSX(w, table)
+		}
+		return table.WriteCSV(w)
+	})
+	if err == nil && c.Output != "" {
+		fmt.Printf("Wrote %d years (%d-%d) to %s\n", len(years), years[0].Year, years[len(years)-1].Year, c.Output)
+	}
+	return err
+}
+
+func addStatsCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("stats",
+		"Export the statistics of a player year by year",
+		"Writes one row per year of a game archive with the statistics of a player:\n"+
+			"planets, starbases, population, resources, minerals mined and on hand,\n"+
+			"fleets and ships, ships built and lost, tech levels, score and rank.\n\n"+
+			"The years are those holding the player's own data: their M files, or the\n"+
+			"host files. Minerals mined are estimated from the mines and mineral\n"+
+			"concentrations of the year; ships built are counted from the designs, so\n"+
+			"they need the turn before; ships lost are those of the battles reported.\n\n"+
+			"The output is an Excel workbook when --output ends with .xlsx, CSV\n"+
+			"otherwise.\n\n"+
+			"Examples:\n"+
+			"  houston stats --dir archive/ --player 3 -o me.xlsx\n"+
+			"  houston stats --player 1 game-24*.m1 > me.csv",
+		&statsCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
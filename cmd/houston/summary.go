@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/report"
+	"github.com/neper-stars/houston/lib/tools/summary"
+	"github.com/neper-stars/houston/store"
+)
+
+type summaryCommand struct {
+	Player int    `short:"p" long:"player" description:"Player number (1-16, auto-detected from M-file if not specified)"`
+	Format string `short:"f" long:"format" description:"Output format: text, markdown or html" default:"text"`
+	Sort   string `short:"s" long:"sort-defenses" description:"Order of the defense table" choice:"capture" choice:"coverage" choice:"name" default:"capture"`
+	Args   struct {
+		Files []string `positional-arg-name:"file" description:"Stars! game files (.m, .h, .xy)" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (c *summaryCommand) Execute(args []string) error {
+	gs := store.New()
+	for _, filename := range c.Args.Files {
+		if err := gs.AddFileWithXY(filename); err != nil {
+			return fmt.Errorf("failed to load %s: %w", filename, err)
+		}
+	}
+	printStoreWarnings(gs)
+
+	playerNumber := c.Player - 1
+	if c.Player == 0 {
+		playerNumber = detectPlayerNumber(gs)
+		if playerNumber < 0 {
+			return errNoPlayerDetected
+		}
+	}
+
+	s, err := summary.Summarize(gs, playerNumber)
+	if err != nil {
+		return err
+	}
+	if err := summary.SortDefenses(s.Defenses, c.Sort); err != nil {
+		return err
+	}
+
+	doc := &report.Document{
+		Title:    fmt.Sprintf("%s (player %d), year %d", s.Player.NamePlural, playerNumber+1, s.Year),
+		Subtitle: "Empire summary",
+	}
+
+	economy := doc.AddSection("Economy")
+	economy.AddFields(
+		report.F("Planets", "%d (%d with starbase)", s.Planets, s.Starbases),
+		report.F("Population", "%d", s.Population),
+		report.F("Resources", "%d per year", s.Resources),
+	)
+	minerals := economy.AddTable(
+		report.Column{Header: "Minerals"},
+		report.Column{Header: "Ironium", Numeric: true},
+		report.Column{Header: "Boranium", Numeric: true},
+		report.Column{Header: "Germanium", Numeric: true},
+	)
+	minerals.AddRow("On hand", itoa64(s.Minerals.Ironium), itoa64(s.Minerals.Boranium), itoa64(s.Minerals.Germanium))
+	minerals.AddRow("Mined/year", itoa64(s.MineralIncome.Ironium), itoa64(s.MineralIncome.Boranium), itoa64(s.MineralIncome.Germanium))
+	addDepletionTable(economy, s.Depletion)
+
+	fleets := doc.AddSection("Fleets")
+	fleets.AddFields(report.F("Fleets", "%d (%d ships)", s.Fleets, s.Ships))
+	roles := fleets.AddTable(
+		report.Column{Header: "Role"},
+		report.Column{Header: "Fleets", Numeric: true},
+		report.Column{Header: "Ships", Numeric: true},
+	)
+	for _, role := range summary.Roles {
+		rc, ok := s.Roles[role]
+		if !ok {
+			continue
+		}
+		roles.AddRow(role.String(), strconv.Itoa(rc.Fleets), strconv.Itoa(rc.Ships))
+	}
+
+	if len(s.Defenses) > 0 {
+		defense := doc.AddSection("Defense")
+		defense.AddFields(report.F("Defense type", "%s", s.Defenses[0].Defense.Name))
+		defenses := defense.AddTable(
+			report.Column{Header: "Planet"},
+			report.Column{Header: "Defenses", Numeric: true},
+			report.Column{Header: "Coverage", Numeric: true},
+			report.Column{Header: "Population", Numeric: true},
+			report.Column{Header: "To capture", Numeric: true},
+		)
+		for _, pd := range s.Defenses {
+			defenses.AddRow(pd.Planet.Name, strconv.Itoa(pd.Defenses), fmt.Sprintf("%.1f%%", 100*pd.Coverage),
+				itoa64(pd.Planet.Population), itoa64(pd.ColonistsToCapture))
+		}
+	}
+
+	tech := doc.AddSection("Technology")
+	tech.AddFields(report.F("Tech", "Ene %d  Wea %d  Pro %d  Con %d  Ele %d  Bio %d",
+		s.Tech.Energy, s.Tech.Weapons, s.Tech.Propulsion, s.Tech.Construction, s.Tech.Electronics, s.Tech.Biotech))
+	if s.Player.HasFullData {
+		tech.AddFields(report.F("Research", "%d%% of resources on %s (next: %s)", s.ResearchPercent,
+			blocks.ResearchFieldName(s.ResearchField), blocks.ResearchFieldName(s.NextResearchField)))
+	}
+
+	source := "computed"
+	if s.ScoreFromFile {
+		source = "from file"
+	}
+	score := fmt.Sprintf("%d (%s)", s.Score, source)
+	if s.Rank > 0 {
+		score += fmt.Sprintf(", rank %d", s.Rank)
+	}
+	doc.AddSection("Score").AddFields(report.Field{Name: "Score", Value: score})
+
+	return renderReport(c.Format, doc)
+}
+
+// maxDepletionRows limits the depletion table to the planets depleted
+// soonest.
+const maxDepletionRows = 10
+
+// addDepletionTable lists the planets whose concentrations fall within ten
+// years and those whose production queues need minerals they won't have.
+func addDepletionTable(section *report.Section, depletion []summary.PlanetDepletion) {
+	var rows []summary.PlanetDepletion
+	for _, pd := range depletion {
+		drops := false
+		for _, m := range pd.Minerals {
+			drops = drops || m.After10Years < m.Concentration
+		}
+		if (drops && len(rows) < maxDepletionRows) || pd.Short() {
+			rows = append(rows, pd)
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	section.AddParagraph("Mineral concentrations now -> in 10 years:")
+	table := section.AddTable(
+		report.Column{Header: "Planet"},
+		report.Column{Header: "Ironium", Numeric: true},
+		report.Column{Header: "Boranium", Numeric: true},
+		report.Column{Header: "Germanium", Numeric: true},
+		report.Column{Header: "Floor in", Numeric: true},
+		report.Column{Header: "Queue short by"},
+	)
+	for _, pd := range rows {
+		cells := []string{pd.Planet.Name}
+		floor := -1
+		for _, m := range pd.Minerals {
+			cells = append(cells, fmt.Sprintf("%d%% -> %d%%", m.Concentration, m.After10Years))
+			if m.YearsToFloor >= 0 && (floor < 0 || m.YearsToFloor < floor) {
+				floor = m.YearsToFloor
+			}
+		}
+		if floor >= 0 {
+			cells = append(cells, fmt.Sprintf("%d years", floor))
+		} else {
+			cells = append(cells, fmt.Sprintf("> %d years", summary.DepletionHorizon))
+		}
+		short := ""
+		if pd.Short() {
+			short = fmt.Sprintf("%d/%d/%d kT over %d years", pd.Shortfall.Ironium, pd.Shortfall.Boranium,
+				pd.Shortfall.Germanium, pd.QueueYears)
+		}
+		table.AddRow(append(cells, short)...)
+	}
+}
+
+func itoa64(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+func addSummaryCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("summary",
+		"Print an overview of your empire",
+		"Prints a one-screen overview of a player's empire: planets, population,\n"+
+			"yearly resources, mineral stockpiles and mining income, fleets by role,\n"+
+			"planetary defenses, tech levels, research settings and score.\n\n"+
+			"The depletion table forecasts the mineral concentrations of the planets\n"+
+			"mined fastest ten years ahead, at the current number of operating mines,\n"+
+			"and flags production queues needing more minerals (iron/bor/germ) than\n"+
+			"the planet holds and will mine while building them. Minerals shipped in\n"+
+			"are not counted.\n\n"+
+			"The defense table gives each planet's defense coverage and the colonists\n"+
+			"an attacker must land to capture it: more than the population, raised by\n"+
+			"the coverage and by half again for Inner-Strength defenders (War Monger\n"+
+			"attackers need a third less). --sort-defenses orders it by colonists to\n"+
+			"capture (easiest first, the default), coverage or name.\n\n"+
+			"--format markdown produces a forum-ready post, --format html a page\n"+
+			"suitable for mailing.",
+		&summaryCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
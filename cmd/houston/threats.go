@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/maprenderer"
+	"github.com/neper-stars/houston/lib/tools/threat"
+	"github.com/neper-stars/houston/store"
+)
+
+type threatsCommand struct {
+	Player    int     `short:"p" long:"player" description:"Player number (1-16, auto-detected from M-file if not specified)"`
+	Years     int     `short:"y" long:"years" description:"Only report arrivals within this many years" default:"5"`
+	Deviation float64 `long:"deviation" description:"Maximum distance in ly between a planet and a fleet's projected track" default:"20"`
+	All       bool    `short:"a" long:"all" description:"Also list fleets not heading for your planets"`
+	Radius    float64 `short:"r" long:"radius" description:"Ignore enemy fleets further than this many ly from a planet when scoring" default:"150"`
+	Top       int     `short:"t" long:"top" description:"Number of planets in the defense priority list (0 for all)" default:"10"`
+	Map       string  `short:"o" long:"map" description:"Write a threat map (.svg or .png) for the latest turn"`
+	Width     int     `short:"W" long:"width" description:"Map width in pixels" default:"800"`
+	Height    int     `short:"H" long:"height" description:"Map height in pixels" default:"600"`
+	Args      struct {
+		Files []string `positional-arg-name:"file" description:"Stars! game files from one or more turns" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (c *threatsCommand) Execute(args []string) error {
+	stores, err := loadTurnStores(c.Args.Files)
+	if err != nil {
+		return err
+	}
+	latest := stores[len(stores)-1]
+
+	playerNumber := c.Player - 1
+	if c.Player == 0 {
+		playerNumber = detectPlayerNumber(latest)
+		if playerNumber < 0 {
+			return errNoPlayerDetected
+		}
+	}
+
+	opts := &threat.PredictOptions{
+		MaxYears:     c.Years,
+		MaxDeviation: c.Deviation,
+	}
+	predictions := threat.PredictArrivals(stores, playerNumber, opts)
+
+	fmt.Printf("Threat report for player %d, year %d\n", playerNumber+1, int(latest.Turn)+blocks.StarsBaseYear)
+	fmt.Println(strings.Repeat("=", 60))
+
+	fmt.Println("\nIncoming fleets:")
+	shown := 0
+	for _, p := range predictions {
+		if !c.All && !p.Threatens() {
+			continue
+		}
+		shown++
+		source := "heading"
+		if p.FromHistory {
+			source = "observed"
+		}
+		fmt.Printf("\n  %s (player %d) at (%d, %d), %.0f ly/yr, %s %.0f°\n",
+			p.Fleet.Name(), p.Fleet.Owner+1, p.Fleet.X, p.Fleet.Y, p.Speed, source, headingDegrees(p.Heading))
+		for _, d := range p.Destinations {
+			marker := " "
+			if d.Threatened {
+				marker = "!"
+			}
+			fmt.Printf("   %s %-20s %6.1f ly, %4.1f ly off track, ETA %s\n",
+				marker, d.Planet.Name, d.Distance, d.Deviation, etaRange(d.EarliestYears, d.LatestYears))
+		}
+	}
+	if shown == 0 {
+		fmt.Println("  None detected.")
+	}
+
+	threats := threat.Assess(latest, playerNumber, &threat.AssessOptions{Radius: c.Radius})
+
+	fmt.Println("\nDefend these first:")
+	listed := 0
+	for _, pt := range threats {
+		if pt.Score <= 0 {
+			break
+		}
+		if c.Top > 0 && listed >= c.Top {
+			break
+		}
+		listed++
+		fmt.Printf("  %2d. %-20s score %7.0f  (enemy %.0f in %d fleet(s), nearest %.0f ly; starbase %d, garrison %d, defenses %.0f%%)\n",
+			listed, pt.Planet.Name, pt.Score, pt.EnemyPower, pt.EnemyFleets, pt.NearestEnemy,
+			pt.StarbaseStrength, pt.GarrisonStrength, pt.DefenseCoverage*100)
+	}
+	if listed == 0 {
+		fmt.Println("  All planets adequately defended.")
+	}
+
+	if c.Map != "" {
+		if err := c.writeMap(latest, threats); err != nil {
+			return err
+		}
+		fmt.Printf("\nCreated %s\n", c.Map)
+	}
+
+	return nil
+}
+
+func (c *threatsCommand) writeMap(gs *store.GameStore, threats []*threat.PlanetThreat) error {
+	renderer := maprenderer.NewFromStore(gs)
+	opts := maprenderer.DefaultOptions()
+	opts.Width = c.Width
+	opts.Height = c.Height
+
+	maxScore := 0.0
+	for _, pt := range threats {
+		maxScore = math.Max(maxScore, pt.Score)
+	}
+	for _, pt := range threats {
+		t := 0.0
+		if maxScore > 0 {
+			t = pt.Score / maxScore
+		}
+		opts.Hotspots = append(opts.Hotspots, maprenderer.Hotspot{
+			X:      pt.Planet.X,
+			Y:      pt.Planet.Y,
+			Radius: 6,
+			Color:  maprenderer.RampColor(t),
+		})
+	}
+
+	var err error
+	if strings.HasSuffix(strings.ToLower(c.Map), ".svg") {
+		err = renderer.SaveSVG(c.Map, opts)
+	} else {
+		err = renderer.SavePNG(c.Map, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save map: %w", err)
+	}
+	return nil
+}
+
+// detectPlayerNumber returns the player index of the first M-file in the store,
+// or -1 if no M-file was loaded.
+func detectPlayerNumber(gs *store.GameStore) int {
+	for _, source := range gs.Sources() {
+		if source.Type == store.SourceTypeMFile {
+			return source.PlayerIndex
+		}
+	}
+	return -1
+}
+
+// headingDegrees converts a heading in radians to a compass-like bearing
+// in degrees (0 = up, clockwise).
+func headingDegrees(heading float64) float64 {
+	deg := 90 - heading*180/math.Pi
+	for deg < 0 {
+		deg += 360
+	}
+	for deg >= 360 {
+		deg -= 360
+	}
+	return deg
+}
+
+func etaRange(earliest, latest int) string {
+	if earliest == latest {
+		return fmt.Sprintf("%d yr", earliest)
+	}
+	return fmt.Sprintf("%d-%d yr", earliest, latest)
+}
+
+func addThreatsCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("threats",
+		"Predict enemy fleet arrivals and rank planets by threat",
+		"Projects the course of visible enemy fleets and lists the planets along\n"+
+			"their track with an arrival estimate.\n\n"+
+			"When files from several turns are given, a fleet's observed movement\n"+
+			"between the last two turns is used; otherwise its current heading and\n"+
+			"warp are used. Planets owned by you are flagged with '!'.\n\n"+
+			"Each of your planets is then scored by combining nearby enemy firepower\n"+
+			"with its starbase, orbiting fleets and planetary defenses, producing a\n"+
+			"\"defend these first\" list. --map renders the scores as a color ramp.",
+		&threatsCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/thumbnails"
+	"github.com/neper-stars/houston/store"
+)
+
+// Timeouts of the thumbnail server, so that slow or idle clients do not
+// hold connections forever.
+const (
+	thumbnailReadTimeout  = 10 * time.Second
+	thumbnailWriteTimeout = 30 * time.Second
+	thumbnailIdleTimeout  = 2 * time.Minute
+)
+
+type thumbnailCommand struct {
+	Dir          string        `short:"d" long:"dir" description:"Directory holding the game files of every turn"`
+	Out          string        `short:"o" long:"out" description:"Output directory for the thumbnails" default:"thumbnails"`
+	Width        int           `short:"W" long:"width" description:"Thumbnail width in pixels" default:"200"`
+	Height       int           `short:"H" long:"height" description:"Thumbnail height in pixels" default:"150"`
+	Force        bool          `long:"force" description:"Render thumbnails that already exist again"`
+	Serve        string        `long:"serve" description:"Serve the thumbnails over HTTP on this address (e.g. :8080)"`
+	RefreshEvery time.Duration `long:"refresh-every" description:"With --serve, interval between two checks of the input files for new turns" default:"1m"`
+	Args         struct {
+		Files []string `positional-arg-name:"file" description:"Stars! game files, one or more per turn"`
+	} `positional-args:"yes"`
+
+	state string // Names, sizes and times of the input files last rendered
+
+	mu     sync.RWMutex
+	thumbs map[string]bool // Paths of the thumbnails served
+	index  []byte          // Index page linking to them
+}
+
+func (c *thumbnailCommand) Execute(args []string) error {
+	if c.Dir == "" && len(c.Args.Files) == 0 {
+		return fmt.Errorf("no input files specified")
+	}
+	if c.Serve != "" && c.RefreshEvery <= 0 {
+		return fmt.Errorf("--refresh-every must be positive")
+	}
+	if err := c.refresh(true); err != nil {
+		return err
+	}
+	if c.Serve == "" {
+		return nil
+	}
+
+	go func() {
+		ticker := time.NewTicker(c.RefreshEvery)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := c.refresh(false); err != nil {
+				fmt.Fprintf(os.Stderr, "thumbnail: %v\n", err)
+			}
+		}
+	}()
+
+	server := &http.Server{
+		Addr:         c.Serve,
+		Handler:      c,
+		ReadTimeout:  thumbnailReadTimeout,
+		WriteTimeout: thumbnailWriteTimeout,
+		IdleTimeout:  thumbnailIdleTimeout,
+	}
+	fmt.Printf("Serving %s on %s, e.g. http://%s/GAMEID/latest.png\n", c.Out, c.Serve, c.Serve)
+	return server.ListenAndServe()
+}
+
+// ServeHTTP serves the index page and the thumbnails rendered by the last
+// refresh. Anything else in the output directory is not served.
+func (c *thumbnailCommand) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.RLock()
+	index, served := c.index, c.thumbs[strings.TrimPrefix(r.URL.Path, "/")]
+	c.mu.RUnlock()
+
+	switch {
+	case r.URL.Path == "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		_, _ = w.Write(index)
+	case !served:
+		http.NotFound(w, r)
+	default:
+		// Turn thumbnails never change; the latest one does
+		if path.Base(r.URL.Path) == "latest.png" {
+			w.Header().Set("Cache-Control", "no-cache")
+		} else {
+			w.Header().Set("Cache-Control", "max-age=86400")
+		}
+		http.ServeFile(w, r, filepath.Join(c.Out, filepath.FromSlash(r.URL.Path)))
+	}
+}
+
+// refresh renders the thumbnails of the input files if their names, sizes
+// or modification times changed since the last call.
+func (c *thumbnailCommand) refresh(verbose bool) error {
+	files := c.Args.Files
+	if c.Dir != "" {
+		found, err := findMFilesMap(c.Dir)
+		if err != nil {
+			return fmt.Errorf("failed to read directory %s: %w", c.Dir, err)
+		}
+		files = append(found, files...)
+	}
+	var state strings.Builder
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f, err)
+		}
+		fmt.Fprintf(&state, "%s %d %d\n", f, info.Size(), info.ModTime().UnixNano())
+	}
+	if state.String() == c.state {
+		return nil
+	}
+
+	stores, err := loadTurnStores(files)
+	if err != nil {
+		return err
+	}
+	written, err := thumbnails.Write(c.Out, stores, &thumbnails.Options{
+		Width:  c.Width,
+		Height: c.Height,
+		Force:  c.Force,
+	})
+	if err != nil {
+		return err
+	}
+	c.state = state.String()
+	// --force applies to the first rendering only
+	c.Force = false
+	c.setServed(stores)
+
+	if verbose || len(written) > 0 {
+		fmt.Printf("Wrote %d thumbnails to %s (%d turns)\n", len(written), c.Out, len(stores))
+		for _, name := range written {
+			fmt.Printf("  %s\n", name)
+		}
+	}
+	return nil
+}
+
+// setServed sets the thumbnails of the turns as the ones served, and the
+// index page linking to them.
+func (c *thumbnailCommand) setServed(stores []*store.GameStore) {
+	thumbs := make(map[string]bool)
+	years := make(map[uint32][]int)
+	for _, gs := range stores {
+		year := int(gs.Turn) + blocks.StarsBaseYear
+		thumbs[thumbnails.Path(gs.GameID, year)] = true
+		thumbs[thumbnails.LatestPath(gs.GameID)] = true
+		years[gs.GameID] = append(years[gs.GameID], year)
+	}
+
+	var index strings.Builder
+	index.WriteString("<!DOCTYPE html>\n<html><head><title>Thumbnails</title></head><body>\n")
+	for _, id := range slices.Sorted(maps.Keys(years)) {
+		fmt.Fprintf(&index, "<h2>Game %d</h2>\n<p><img src=\"%s\" alt=\"latest turn\"></p>\n<p>", id, thumbnails.LatestPath(id))
+		for _, year := range slices.Sorted(slices.Values(years[id])) {
+			fmt.Fprintf(&index, "<a href=\"%s\">%d</a> ", thumbnails.Path(id, year), year)
+		}
+		index.WriteString("</p>\n")
+	}
+	index.WriteString("</body></html>\n")
+
+	c.mu.Lock()
+	c.thumbs, c.index = thumbs, []byte(index.String())
+	c.mu.Unlock()
+}
+
+func addThumbnailCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("thumbnail",
+		"Render small map thumbnails per turn for forum embedding",
+		"Renders a small galaxy map (200x150 by default) for every turn of the\n"+
+			"given files, for embedding in forum posts. Files from the same turn are\n"+
+			"merged. Thumbnails are cached in the output directory under stable names:\n\n"+
+			"  GAMEID/YEAR.png    the map of one turn\n"+
+			"  GAMEID/latest.png  the map of the latest turn\n\n"+
+			"Existing thumbnails are not rendered again unless --force is given, so\n"+
+			"the command can run after every turn generation.\n\n"+
+			"With --serve the thumbnails are served over HTTP with the same names as\n"+
+			"URLs, e.g. http://host:8080/1234/latest.png, and an index page at /.\n"+
+			"The input files are checked every --refresh-every for new turns, which\n"+
+			"are rendered then.\n\n"+
+			"Examples:\n"+
+			"  houston thumbnail --dir archive/\n"+
+			"  houston thumbnail --dir games/mygame --serve :8080",
+		&thumbnailCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/mysterytrader"
+	"github.com/neper-stars/houston/lib/tools/report"
+)
+
+type traderCommand struct {
+	Format string `short:"f" long:"format" description:"Output format: text, markdown or html" default:"text"`
+	Args   struct {
+		Files []string `positional-arg-name:"file" description:"Stars! game files from the turns to cover (.hst or .m)" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (c *traderCommand) Execute(args []string) error {
+	stores, err := loadTurnStores(c.Args.Files)
+	if err != nil {
+		return err
+	}
+	r := mysterytrader.Analyze(stores)
+
+	playerName := func(p int) string {
+		for _, s := range r.Players {
+			if s.Player == p && s.Name != "" {
+				return fmt.Sprintf("%d %s", p+1, s.Name)
+			}
+		}
+		return strconv.Itoa(p + 1)
+	}
+
+	doc := &report.Document{
+		Title:    "Mystery Trader",
+		Subtitle: fmt.Sprintf("%d-%d, %d turn(s), %d trader(s) sighted", r.FirstYear, r.LastYear, len(stores), r.Traders),
+	}
+
+	players := doc.AddSection("Players")
+	table := players.AddTable(
+		report.Column{Header: "Player"},
+		report.Column{Header: "Encounters", Numeric: true},
+		report.Column{Header: "Items", Numeric: true},
+		report.Column{Header: "Owned"},
+	)
+	for _, p := range r.Players {
+		count, owned := "?", "unknown"
+		if p.ItemsKnown {
+			count, owned = strconv.Itoa(len(p.Items)), strings.Join(p.Items, ", ")
+		}
+		table.AddRow(playerName(p.Player), strconv.Itoa(p.Encounters), count, owned)
+	}
+
+	f := r.Fairness
+	fairness := doc.AddSection("Fairness")
+	if f.Players == 0 {
+		fairness.AddParagraph("No player's items are known; load the host file or the players' M files.")
+	} else {
+		empty := make([]string, 0, len(f.Empty))
+		for _, p := range f.Empty {
+			empty = append(empty, playerName(p))
+		}
+		fairness.AddFields(
+			report.F("Players compared", "%d", f.Players),
+			report.F("Items per player", "%.1f on average (%d to %d)", f.MeanItems, f.MinItems, f.MaxItems),
+			report.F("Gini coefficient", "%.2f (0 is perfectly even)", f.Gini),
+		)
+		if len(empty) > 0 {
+			fairness.AddFields(report.F("Without items", "%s", strings.Join(empty, ", ")))
+		}
+	}
+
+	if len(r.Gifts) > 0 {
+		gifts := doc.AddSection("Items received").AddTable(
+			report.Column{Header: "Year", Numeric: true},
+			report.Column{Header: "Player"},
+			report.Column{Header: "Item"},
+		)
+		for _, g := range r.Gifts {
+			gifts.AddRow(strconv.Itoa(g.Year), playerName(g.Player), g.Item)
+		}
+	}
+
+	if len(r.Encounters) > 0 {
+		encounters := doc.AddSection("Encounters").AddTable(
+			report.Column{Header: "Year", Numeric: true},
+			report.Column{Header: "Player"},
+			report.Column{Header: "Trader", Numeric: true},
+			report.Column{Header: "Position"},
+		)
+		for _, e := range r.Encounters {
+			encounters.AddRow(strconv.Itoa(e.Year), playerName(e.Player), strconv.Itoa(e.Trader),
+				fmt.Sprintf("(%d, %d)", e.X, e.Y))
+		}
+	}
+
+	return renderReport(c.Format, doc)
+}
+
+func addTraderCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("trader",
+		"Track Mystery Trader encounters and items received",
+		"Lists which players met the Mystery Trader and which items they received\n"+
+			"over the given turns, with a fairness summary of how the items were\n"+
+			"spread. Items are only known for the players whose M files are given,\n"+
+			"or for everyone from the host files; gifts are dated to the first turn\n"+
+			"they appear, so give every turn for exact years.\n\n"+
+			"Example:\n"+
+			"  houston trader archive/*.hst -f markdown",
+		&traderCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"sort"
+
+	"github.com/neper-stars/houston/filenames"
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/store"
+)
+
+// loadTurnStores loads Stars! files into one GameStore per turn.
+// Files from the same turn are merged; the result is sorted by turn.
+// Companion XY files are loaded automatically for M and H files, and ZIP
+// archives are expanded, their XY file going into each of their turns. Files
+// may be in cloud folders (remote:path). All files must be of one game (see
+// loadGameTurns for several).
+func loadTurnStores(files []string) ([]*store.GameStore, error) {
+	return loadOneGameTurns(files, "use a directory per game")
+}
+
+// loadTurnStoresOfGame loads the files of the game with the given ID as
+// loadTurnStores does, for commands with a --game option. A zero ID takes
+// every file, which must then be of one game.
+func loadTurnStoresOfGame(files []string, gameID uint32) ([]*store.GameStore, error) {
+	if gameID != 0 {
+		files = filesOfGame(files, gameID)
+	}
+	return loadOneGameTurns(files, "pick one with --game")
+}
+
+// loadOneGameTurns loads the turns of files of a single game, telling how
+// to pick one when they are of several.
+func loadOneGameTurns(files []string, hint string) ([]*store.GameStore, error) {
+	games, err := loadGameTurns(files)
+	if err != nil {
+		return nil, err
+	}
+	if len(games) > 1 {
+		return nil, fmt.Errorf("%w: the files are from %d games (IDs %v); %s",
+			store.ErrGameIDMismatch, len(games), slices.Sorted(maps.Keys(games)), hint)
+	}
+	for _, turns := range games {
+		return turns, nil
+	}
+	return nil, nil
+}
+
+// loadGameTurns loads Stars! files of any number of games into one
+// GameStore per game and turn, as loadTurnStores does for one game: the
+// turns of each game ID are sorted by turn.
+func loadGameTurns(files []string) (map[uint32][]*store.GameStore, error) {
+	cache, err := parseCache()
+	if err != nil {
+		return nil, err
+	}
+
+	fsys := gameFS()
+	byTurn := make(map[uint16]*store.MultiStore)
+	storeFor := func(turn uint16) *store.MultiStore {
+		ms, ok := byTurn[turn]
+		if !ok {
+			ms = store.NewMulti()
+			ms.SetCache(cache)
+			byTurn[turn] = ms
+		}
+		return ms
+	}
+
+	for _, filename := range files {
+		fileBytes, err := fsys.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+		if filenames.IsZip(filename) {
+			if err := addZipTurns(filename, fileBytes, cache, storeFor); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		source, err := store.ParseSourceCached(filename, fileBytes, cache)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+
+		ms := storeFor(source.Turn)
+		if err := ms.AddFileWithXYFromFS(filename, fsys); err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", filename, err)
+		}
+	}
+
+	games := make(map[uint32][]*store.GameStore)
+	for _, ms := range byTurn {
+		for _, id := range ms.GameIDs() {
+			gs, _ := ms.Game(id)
+			games[id] = append(games[id], gs)
+		}
+	}
+	for _, turns := range games {
+		sort.Slice(turns, func(i, j int) bool {
+			return turns[i].Turn < turns[j].Turn
+		})
+		for _, gs := range turns {
+			printStoreWarnings(gs)
+		}
+	}
+	return games, nil
+}
+
+// addZipTurns splits the files of a ZIP archive by turn for loadGameTurns.
+func addZipTurns(filename string, data []byte, cache *parser.Cache, storeFor func(uint16) *store.MultiStore) error {
+	members, err := store.ReadZip(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+
+	var universe []store.ArchiveFile
+	withUniverse := make(map[*store.MultiStore]bool)
+	for _, member := range members {
+		id := path.Join(filename, member.Name)
+		if filenames.KindOf(member.Name) == filenames.XY {
+			universe = append(universe, store.ArchiveFile{Name: id, Data: member.Data})
+			continue
+		}
+		source, err := store.ParseSourceCached(id, member.Data, cache)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", id, err)
+		}
+
+		ms := storeFor(source.Turn)
+		if !withUniverse[ms] {
+			for _, xy := range universe {
+				if err := ms.AddFile(xy.Name, xy.Data); err != nil {
+					return fmt.Errorf("failed to load %s: %w", xy.Name, err)
+				}
+			}
+			withUniverse[ms] = true
+		}
+		if err := ms.AddFile(id, member.Data); err != nil {
+			return fmt.Errorf("failed to load %s: %w", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/neper-stars/houston/lib/tools/maprenderer"
+	"github.com/neper-stars/houston/lib/tools/warfront"
+	"github.com/neper-stars/houston/store"
+)
+
+type warfrontCommand struct {
+	Radius float64 `short:"r" long:"radius" description:"Distance in light years around an incident marking planets as contested" default:"50"`
+	Top    int     `short:"t" long:"top" description:"Number of contested systems to list (0 for all)" default:"20"`
+	Map    string  `short:"o" long:"map" description:"Write a front line map (.svg or .png) for the latest turn"`
+	Width  int     `short:"W" long:"width" description:"Map width in pixels" default:"800"`
+	Height int     `short:"H" long:"height" description:"Map height in pixels" default:"600"`
+	Args   struct {
+		Files []string `positional-arg-name:"file" description:"Stars! game files from consecutive turns" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (c *warfrontCommand) Execute(args []string) error {
+	stores, err := loadTurnStores(c.Args.Files)
+	if err != nil {
+		return err
+	}
+
+	snapshots := make([]*warfront.Snapshot, 0, len(stores))
+	for _, gs := range stores {
+		snapshots = append(snapshots, warfront.SnapshotFromStore(gs))
+	}
+	report := warfront.Analyze(snapshots, c.Radius)
+	latest := stores[len(stores)-1]
+
+	playerName := func(n int) string {
+		if p, ok := latest.Player(n); ok && p.NamePlural != "" {
+			return p.NamePlural
+		}
+		return fmt.Sprintf("Player %d", n+1)
+	}
+
+	fmt.Printf("Front line report %d-%d (%d turns, %d incidents)\n",
+		report.FirstYear, report.LastYear, len(stores), len(report.Incidents))
+	fmt.Println(strings.Repeat("=", 60))
+
+	if len(report.Wars) == 0 {
+		fmt.Println("No hostilities detected.")
+	}
+	for _, war := range report.Wars {
+		fmt.Printf("\n%s vs %s: %d battle(s), %d bombing(s)\n",
+			playerName(war.PlayerA), playerName(war.PlayerB), war.Battles, war.Bombings)
+		for _, ch := range war.GainsA {
+			fmt.Printf("  %d: %s captured %s\n", ch.Year, playerName(war.PlayerA), ch.Name)
+		}
+		for _, ch := range war.GainsB {
+			fmt.Printf("  %d: %s captured %s\n", ch.Year, playerName(war.PlayerB), ch.Name)
+		}
+		fmt.Printf("  Net: %s %+d planet(s)\n", playerName(war.PlayerA), war.NetGain())
+	}
+
+	if len(report.Contested) > 0 {
+		fmt.Printf("\nContested systems (within %.0f ly of an incident):\n", c.Radius)
+		for i, cs := range report.Contested {
+			if c.Top > 0 && i >= c.Top {
+				fmt.Printf("  ... and %d more\n", len(report.Contested)-i)
+				break
+			}
+			owner := "unowned"
+			if cs.Owner >= 0 {
+				owner = playerName(cs.Owner)
+			}
+			fmt.Printf("  %-20s %-16s %3d incident(s), last %d\n", cs.Name, owner, cs.Incidents, cs.LastYear)
+		}
+	}
+
+	if c.Map != "" {
+		if err := c.writeMap(latest, report); err != nil {
+			return err
+		}
+		fmt.Printf("\nCreated %s\n", c.Map)
+	}
+
+	return nil
+}
+
+func (c *warfrontCommand) writeMap(gs *store.GameStore, report *warfront.Report) error {
+	renderer := maprenderer.NewFromStore(gs)
+	opts := maprenderer.DefaultOptions()
+	opts.Width = c.Width
+	opts.Height = c.Height
+
+	red := color.RGBA{255, 40, 40, 255}
+	for _, cs := range report.Contested {
+		opts.Hotspots = append(opts.Hotspots, maprenderer.Hotspot{
+			X:      cs.X,
+			Y:      cs.Y,
+			Radius: float64(4 + cs.Incidents),
+			Color:  red,
+		})
+	}
+
+	var err error
+	if strings.HasSuffix(strings.ToLower(c.Map), ".svg") {
+		err = renderer.SaveSVG(c.Map, opts)
+	} else {
+		err = renderer.SavePNG(c.Map, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save map: %w", err)
+	}
+	return nil
+}
+
+func addWarfrontCommand(parser *flags.Parser) {
+	_, err := parser.AddCommand("warfront",
+		"Track contested systems and front lines across turns",
+		"Compares game files from several turns to find contested systems.\n\n"+
+			"A system is contested when a capture, battle or bombing happened within\n"+
+			"--radius light years of it. The report lists territorial gains and losses\n"+
+			"for each pair of warring players, and --map renders the latest turn with\n"+
+			"contested systems highlighted.",
+		&warfrontCommand{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/neper-stars/houston/store"
+)
+
+// printStoreWarnings prints the inconsistencies found between the files
+// merged into a store when --verbose is given. Misnamed files are always
+// reported: Stars! would open them as another player's.
+func printStoreWarnings(gs *store.GameStore) {
+	for _, w := range gs.Warnings() {
+		if globals.Verbose || w.Kind == store.WarningFileName {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+	}
+}
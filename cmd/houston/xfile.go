@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/jessevdk/go-flags"
 
@@ -10,12 +11,25 @@ import (
 
 type xfileCommand struct {
 	Args struct {
-		File string `positional-arg-name:"file" description:"X file to read" required:"true"`
+		Files []string `positional-arg-name:"file" description:"X file to read, or diff and two X files" required:"1"`
 	} `positional-args:"yes"`
 }
 
 func (c *xfileCommand) Execute(args []string) error {
-	info, err := xfilereader.ReadFile(c.Args.File)
+	// go-flags hands the first word to the positional arguments rather than
+	// to subcommands, so "diff" is read here
+	files := c.Args.Files
+	if files[0] == "diff" {
+		if len(files) != 3 {
+			return fmt.Errorf("xfile diff needs two X files, the previous one first")
+		}
+		return diffXFiles(files[1], files[2])
+	}
+	if len(files) != 1 {
+		return fmt.Errorf("expected one X file, got %d", len(files))
+	}
+
+	info, err := xfilereader.ReadFile(files[0])
 	if err != nil {
 		return err
 	}
@@ -51,11 +65,42 @@ func (c *xfileCommand) Execute(args []string) error {
 	return nil
 }
 
+// diffXFiles prints the orders changed from one X file to another.
+func diffXFiles(before, after string) error {
+	old, err := xfilereader.ReadFile(before)
+	if err != nil {
+		return err
+	}
+	updated, err := xfilereader.ReadFile(after)
+	if err != nil {
+		return err
+	}
+	if old.GameID != updated.GameID || old.PlayerIndex != updated.PlayerIndex {
+		fmt.Fprintf(os.Stderr, "warning: %s and %s are not from the same game and player\n", before, after)
+	} else if old.Turn != updated.Turn {
+		fmt.Fprintf(os.Stderr, "warning: %s is for %d, %s for %d\n", before, old.Year, after, updated.Year)
+	}
+
+	changes := xfilereader.Diff(old, updated)
+	if len(changes) == 0 {
+		fmt.Println("No changes in orders.")
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	fmt.Printf("\n%d order(s) changed\n", len(changes))
+	return nil
+}
+
 func addXFileCommand(parser *flags.Parser) {
 	_, err := parser.AddCommand("xfile",
 		"Read and validate X (turn order) files",
 		"Reads a Stars! X file (player turn orders) and displays its contents.\n"+
-			"Can be used to validate X files before submitting them to the host.",
+			"Can be used to validate X files before submitting them to the host.\n\n"+
+			"xfile diff compares two X files of a turn, e.g. a resubmission with the\n"+
+			"previous one, listing the orders added (+), removed (-) and changed (~):\n\n"+
+			"  houston xfile diff old.x1 new.x1",
 		&xfileCommand{})
 	if err != nil {
 		panic(err)
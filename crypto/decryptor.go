@@ -1,54 +1,28 @@
 package crypto
 
+import "github.com/neper-stars/houston/rng"
+
 // StarsRandom is a pseudo-random number generator used by Stars!
+// It wraps rng.Source, seeding it and drawing the initialization rounds.
 type StarsRandom struct {
-	seedA  int
-	seedB  int
+	source *rng.Source
 	rounds int
 }
 
 // NewStarsRandom creates a new StarsRandom with the given seeds and initial rounds
 func NewStarsRandom(seed1, seed2, initRounds int) *StarsRandom {
 	random := &StarsRandom{
-		seedA:  seed1,
-		seedB:  seed2,
+		source: rng.New(seed1, seed2),
 		rounds: initRounds,
 	}
-
-	for i := 0; i < initRounds; i++ {
-		random.NextRandom()
-	}
+	random.source.Skip(initRounds)
 
 	return random
 }
 
 // NextRandom generates the next random number in the sequence
 func (r *StarsRandom) NextRandom() int {
-	seedApartA := (r.seedA % 53668) * 40014
-	seedApartB := (r.seedA / 53668) * 12211
-	newSeedA := seedApartA - seedApartB
-
-	seedBpartA := (r.seedB % 52774) * 40692
-	seedBpartB := (r.seedB / 52774) * 3791
-	newSeedB := seedBpartA - seedBpartB
-
-	if newSeedA < 0 {
-		newSeedA += 0x7fffffab
-	}
-
-	if newSeedB < 0 {
-		newSeedB += 0x7fffff07
-	}
-
-	r.seedA = newSeedA
-	r.seedB = newSeedB
-
-	randomNumber := r.seedA - r.seedB
-	if r.seedA < r.seedB {
-		randomNumber += 0x100000000
-	}
-
-	return randomNumber
+	return int(r.source.Next())
 }
 
 // Decryptor handles decryption of Stars! file data
@@ -127,6 +127,32 @@ var UniverseDensityNames = map[UniverseDensity]string{
 	UniverseDensityPacked: "Packed",
 }
 
+// StartingDistance represents how far apart player homeworlds are placed
+// (the "Player Positions" option, mdStartDist in the PlanetsBlock).
+type StartingDistance int
+
+const (
+	// StartingDistanceClose places homeworlds near each other.
+	StartingDistanceClose StartingDistance = iota
+
+	// StartingDistanceModerate is the default homeworld separation.
+	StartingDistanceModerate
+
+	// StartingDistanceFarther places homeworlds further apart.
+	StartingDistanceFarther
+
+	// StartingDistanceDistant gives the greatest initial separation.
+	StartingDistanceDistant
+)
+
+// StartingDistanceNames maps starting distance values to human-readable names.
+var StartingDistanceNames = map[StartingDistance]string{
+	StartingDistanceClose:    "Close",
+	StartingDistanceModerate: "Moderate",
+	StartingDistanceFarther:  "Farther",
+	StartingDistanceDistant:  "Distant",
+}
+
 // PlayerRelation represents the diplomatic relationship between two players.
 type PlayerRelation int
 
@@ -171,10 +171,15 @@ type Hull struct {
 	Name          string
 	Mass          int
 	Armor         int
+	Initiative    int // Battle initiative of the hull, before computers and weapons
 	FuelCapacity  int
 	CargoCapacity int
 	IsStarbase    bool
 	Slots         []HullSlot
+
+	// Cost is currently only populated for starbase hulls.
+	Tech TechRequirements
+	Cost Cost
 }
 
 // Accepts returns true if this slot accepts the given item category
@@ -196,6 +201,7 @@ var Hulls = map[int]*Hull{
 	HullMediumFreighter: {
 		ID: HullMediumFreighter, Name: "Medium Freighter",
 		Mass: 60, Armor: 50, FuelCapacity: 450, CargoCapacity: 210,
+		Tech: TechRequirements{Construction: 3},
 		Slots: []HullSlot{
 			{SlotEngine, 1},
 			{SlotShieldArmor, 1},
@@ -205,6 +211,7 @@ var Hulls = map[int]*Hull{
 	HullLargeFreighter: {
 		ID: HullLargeFreighter, Name: "Large Freighter",
 		Mass: 125, Armor: 150, FuelCapacity: 2600, CargoCapacity: 1200,
+		Tech: TechRequirements{Construction: 8},
 		Slots: []HullSlot{
 			{SlotEngine, 2},
 			{SlotShieldArmor, 2},
@@ -214,6 +221,7 @@ var Hulls = map[int]*Hull{
 	HullSuperFreighter: {
 		ID: HullSuperFreighter, Name: "Super Freighter",
 		Mass: 175, Armor: 400, FuelCapacity: 8000, CargoCapacity: 3000,
+		Tech: TechRequirements{Construction: 13},
 		Slots: []HullSlot{
 			{SlotEngine, 3},
 			{SlotShieldArmor, 5},
@@ -222,7 +230,7 @@ var Hulls = map[int]*Hull{
 	},
 	HullScout: {
 		ID: HullScout, Name: "Scout",
-		Mass: 8, Armor: 20, FuelCapacity: 50, CargoCapacity: 0,
+		Mass: 8, Armor: 20, Initiative: 1, FuelCapacity: 50, CargoCapacity: 0,
 		Slots: []HullSlot{
 			{SlotEngine, 1},
 			{SlotScanner, 1},
@@ -231,7 +239,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullFrigate: {
 		ID: HullFrigate, Name: "Frigate",
-		Mass: 8, Armor: 45, FuelCapacity: 125, CargoCapacity: 0,
+		Mass: 8, Armor: 45, Initiative: 4, FuelCapacity: 125, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 6},
 		Slots: []HullSlot{
 			{SlotEngine, 1},
 			{SlotScanner, 2},
@@ -241,7 +250,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullDestroyer: {
 		ID: HullDestroyer, Name: "Destroyer",
-		Mass: 30, Armor: 200, FuelCapacity: 280, CargoCapacity: 0,
+		Mass: 30, Armor: 200, Initiative: 3, FuelCapacity: 280, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 3},
 		Slots: []HullSlot{
 			{SlotEngine, 1},
 			{SlotWeapon, 1},
@@ -254,7 +264,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullCruiser: {
 		ID: HullCruiser, Name: "Cruiser",
-		Mass: 90, Armor: 700, FuelCapacity: 600, CargoCapacity: 0,
+		Mass: 90, Armor: 700, Initiative: 5, FuelCapacity: 600, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 9},
 		Slots: []HullSlot{
 			{SlotEngine, 2},
 			{SlotShieldArmor, 1},
@@ -267,7 +278,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullBattleCruiser: {
 		ID: HullBattleCruiser, Name: "Battle Cruiser",
-		Mass: 120, Armor: 1000, FuelCapacity: 1400, CargoCapacity: 0,
+		Mass: 120, Armor: 1000, Initiative: 5, FuelCapacity: 1400, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 10},
 		Slots: []HullSlot{
 			{SlotEngine, 2},
 			{SlotShieldArmor, 2},
@@ -280,7 +292,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullBattleship: {
 		ID: HullBattleship, Name: "Battleship",
-		Mass: 222, Armor: 2000, FuelCapacity: 2800, CargoCapacity: 0,
+		Mass: 222, Armor: 2000, Initiative: 10, FuelCapacity: 2800, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 13},
 		Slots: []HullSlot{
 			{SlotEngine, 4},
 			{SlotShieldArmor, 1},
@@ -297,7 +310,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullDreadnought: {
 		ID: HullDreadnought, Name: "Dreadnought",
-		Mass: 250, Armor: 4500, FuelCapacity: 4500, CargoCapacity: 0,
+		Mass: 250, Armor: 4500, Initiative: 10, FuelCapacity: 4500, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 16},
 		Slots: []HullSlot{
 			{SlotEngine, 5},
 			{SlotShieldArmor, 4},
@@ -316,7 +330,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullPrivateer: {
 		ID: HullPrivateer, Name: "Privateer",
-		Mass: 65, Armor: 150, FuelCapacity: 650, CargoCapacity: 250,
+		Mass: 65, Armor: 150, Initiative: 3, FuelCapacity: 650, CargoCapacity: 250,
+		Tech: TechRequirements{Construction: 4},
 		Slots: []HullSlot{
 			{SlotEngine, 1},
 			{SlotShieldArmor, 2},
@@ -327,7 +342,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullRogue: {
 		ID: HullRogue, Name: "Rogue",
-		Mass: 75, Armor: 450, FuelCapacity: 2250, CargoCapacity: 500,
+		Mass: 75, Armor: 450, Initiative: 4, FuelCapacity: 2250, CargoCapacity: 500,
+		Tech: TechRequirements{Construction: 8},
 		Slots: []HullSlot{
 			{SlotEngine, 2},
 			{SlotShieldArmor, 3},
@@ -342,7 +358,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullGalleon: {
 		ID: HullGalleon, Name: "Galleon",
-		Mass: 125, Armor: 900, FuelCapacity: 2500, CargoCapacity: 1000,
+		Mass: 125, Armor: 900, Initiative: 4, FuelCapacity: 2500, CargoCapacity: 1000,
+		Tech: TechRequirements{Construction: 11},
 		Slots: []HullSlot{
 			{SlotEngine, 4},
 			{SlotShieldArmor, 2},
@@ -373,6 +390,7 @@ var Hulls = map[int]*Hull{
 	HullMiniBomber: {
 		ID: HullMiniBomber, Name: "Mini Bomber",
 		Mass: 28, Armor: 50, FuelCapacity: 120, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 1},
 		Slots: []HullSlot{
 			{SlotEngine, 1},
 			{SlotBomb, 2},
@@ -381,6 +399,7 @@ var Hulls = map[int]*Hull{
 	HullB17Bomber: {
 		ID: HullB17Bomber, Name: "B-17 Bomber",
 		Mass: 69, Armor: 175, FuelCapacity: 400, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 6},
 		Slots: []HullSlot{
 			{SlotEngine, 2},
 			{SlotBomb, 4},
@@ -391,6 +410,7 @@ var Hulls = map[int]*Hull{
 	HullStealthBomber: {
 		ID: HullStealthBomber, Name: "Stealth Bomber",
 		Mass: 70, Armor: 225, FuelCapacity: 750, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 8},
 		Slots: []HullSlot{
 			{SlotEngine, 2},
 			{SlotBomb, 4},
@@ -402,6 +422,7 @@ var Hulls = map[int]*Hull{
 	HullB52Bomber: {
 		ID: HullB52Bomber, Name: "B-52 Bomber",
 		Mass: 110, Armor: 450, FuelCapacity: 750, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 15},
 		Slots: []HullSlot{
 			{SlotEngine, 3},
 			{SlotBomb, 4},
@@ -423,6 +444,7 @@ var Hulls = map[int]*Hull{
 	HullMiniMiner: {
 		ID: HullMiniMiner, Name: "Mini-Miner",
 		Mass: 80, Armor: 130, FuelCapacity: 210, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 2},
 		Slots: []HullSlot{
 			{SlotEngine, 1},
 			{SlotShieldArmor, 1},
@@ -433,6 +455,7 @@ var Hulls = map[int]*Hull{
 	HullMiner: {
 		ID: HullMiner, Name: "Miner",
 		Mass: 110, Armor: 475, FuelCapacity: 500, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 6},
 		Slots: []HullSlot{
 			{SlotEngine, 2},
 			{SlotShieldArmor | SlotScanner, 2},
@@ -445,6 +468,7 @@ var Hulls = map[int]*Hull{
 	HullMaxiMiner: {
 		ID: HullMaxiMiner, Name: "Maxi-Miner",
 		Mass: 110, Armor: 1400, FuelCapacity: 850, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 11},
 		Slots: []HullSlot{
 			{SlotEngine, 3},
 			{SlotShieldArmor | SlotScanner, 2},
@@ -457,6 +481,7 @@ var Hulls = map[int]*Hull{
 	HullUltraMiner: {
 		ID: HullUltraMiner, Name: "Ultra-Miner",
 		Mass: 100, Armor: 1500, FuelCapacity: 1300, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 14},
 		Slots: []HullSlot{
 			{SlotEngine, 2},
 			{SlotShieldArmor | SlotScanner, 3},
@@ -469,6 +494,7 @@ var Hulls = map[int]*Hull{
 	HullFuelTransport: {
 		ID: HullFuelTransport, Name: "Fuel Transport",
 		Mass: 12, Armor: 5, FuelCapacity: 750, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 4},
 		Slots: []HullSlot{
 			{SlotEngine, 1},
 			{SlotShield, 1},
@@ -477,6 +503,7 @@ var Hulls = map[int]*Hull{
 	HullSuperFuelXport: {
 		ID: HullSuperFuelXport, Name: "Super-Fuel Xport",
 		Mass: 111, Armor: 12, FuelCapacity: 2250, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 7},
 		Slots: []HullSlot{
 			{SlotEngine, 2},
 			{SlotShield, 2},
@@ -496,6 +523,7 @@ var Hulls = map[int]*Hull{
 	HullSuperMineLayer: {
 		ID: HullSuperMineLayer, Name: "Super Mine Layer",
 		Mass: 30, Armor: 1200, FuelCapacity: 2200, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 15},
 		Slots: []HullSlot{
 			{SlotEngine, 3},
 			{SlotMineLayer, 8},
@@ -507,7 +535,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullNubian: {
 		ID: HullNubian, Name: "Nubian",
-		Mass: 100, Armor: 5000, FuelCapacity: 5000, CargoCapacity: 0,
+		Mass: 100, Armor: 5000, Initiative: 2, FuelCapacity: 5000, CargoCapacity: 0,
+		Tech: TechRequirements{Construction: 26},
 		Slots: []HullSlot{
 			{SlotEngine, 3},
 			{SlotGeneralPurpose, 3},
@@ -526,7 +555,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullMiniMorph: {
 		ID: HullMiniMorph, Name: "Mini Morph",
-		Mass: 70, Armor: 250, FuelCapacity: 400, CargoCapacity: 150,
+		Mass: 70, Armor: 250, Initiative: 2, FuelCapacity: 400, CargoCapacity: 150,
+		Tech: TechRequirements{Construction: 8},
 		Slots: []HullSlot{
 			{SlotEngine, 2},
 			{SlotGeneralPurpose, 3},
@@ -539,7 +569,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullMetaMorph: {
 		ID: HullMetaMorph, Name: "Meta Morph",
-		Mass: 85, Armor: 500, FuelCapacity: 700, CargoCapacity: 300,
+		Mass: 85, Armor: 500, Initiative: 2, FuelCapacity: 700, CargoCapacity: 300,
+		Tech: TechRequirements{Construction: 10},
 		Slots: []HullSlot{
 			{SlotEngine, 3},
 			{SlotGeneralPurpose, 8},
@@ -554,7 +585,8 @@ var Hulls = map[int]*Hull{
 	// Starbases
 	HullOrbitalFort: {
 		ID: HullOrbitalFort, Name: "Orbital Fort",
-		Mass: 0, Armor: 100, FuelCapacity: 0, CargoCapacity: 0,
+		Mass: 0, Armor: 100, Initiative: 10, FuelCapacity: 0, CargoCapacity: 0,
+		Tech: TechRequirements{}, Cost: Cost{80, 24, 0, 34},
 		IsStarbase: true,
 		Slots: []HullSlot{
 			{SlotOrbital, 1},
@@ -566,7 +598,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullSpaceDock: {
 		ID: HullSpaceDock, Name: "Space Dock",
-		Mass: 0, Armor: 250, FuelCapacity: 0, CargoCapacity: 200,
+		Mass: 0, Armor: 250, Initiative: 12, FuelCapacity: 0, CargoCapacity: 200,
+		Tech: TechRequirements{Construction: 4}, Cost: Cost{40, 20, 5, 25},
 		IsStarbase: true,
 		Slots: []HullSlot{
 			{SlotOrbital, 1},
@@ -581,7 +614,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullSpaceStation: {
 		ID: HullSpaceStation, Name: "Space Station",
-		Mass: 0, Armor: 500, FuelCapacity: 0, CargoCapacity: 65535,
+		Mass: 0, Armor: 500, Initiative: 14, FuelCapacity: 0, CargoCapacity: 65535,
+		Tech: TechRequirements{}, Cost: Cost{600, 120, 80, 250},
 		IsStarbase: true,
 		Slots: []HullSlot{
 			{SlotOrbital, 1},
@@ -600,7 +634,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullUltraStation: {
 		ID: HullUltraStation, Name: "Ultra Station",
-		Mass: 0, Armor: 1000, FuelCapacity: 0, CargoCapacity: 65535,
+		Mass: 0, Armor: 1000, Initiative: 16, FuelCapacity: 0, CargoCapacity: 65535,
+		Tech: TechRequirements{Construction: 12}, Cost: Cost{600, 120, 80, 300},
 		IsStarbase: true,
 		Slots: []HullSlot{
 			{SlotOrbital, 1},
@@ -623,7 +658,8 @@ var Hulls = map[int]*Hull{
 	},
 	HullDeathStar: {
 		ID: HullDeathStar, Name: "Death Star",
-		Mass: 0, Armor: 1500, FuelCapacity: 0, CargoCapacity: 65535,
+		Mass: 0, Armor: 1500, Initiative: 18, FuelCapacity: 0, CargoCapacity: 65535,
+		Tech: TechRequirements{Construction: 17}, Cost: Cost{750, 120, 80, 350},
 		IsStarbase: true,
 		Slots: []HullSlot{
 			{SlotOrbital, 1},
@@ -129,3 +129,14 @@ func TestHullStarbaseFlag(t *testing.T) {
 	assert.NotNil(t, station)
 	assert.True(t, station.IsStarbase, "Space Station should be a starbase")
 }
+
+func TestHullInitiative(t *testing.T) {
+	assert.Equal(t, 10, GetHull(HullBattleship).Initiative)
+	assert.Equal(t, 4, GetHull(HullFrigate).Initiative)
+	assert.Zero(t, GetHull(HullSmallFreighter).Initiative, "Freighters have no initiative")
+
+	// Each starbase hull is 2 above the previous one
+	for id := HullSpaceDock; id <= HullDeathStar; id++ {
+		assert.Equal(t, GetHull(id-1).Initiative+2, GetHull(id).Initiative, HullNames[id])
+	}
+}
@@ -1,6 +1,8 @@
 // Package data contains static game data and constants for Stars! file parsing.
 package data
 
+import "math"
+
 // ItemCategory represents the category of a ship component.
 type ItemCategory int
 
@@ -1366,6 +1368,35 @@ var PlanetaryDefenses = map[int]*PlanetaryDefense{
 // GetPlanetaryDefense returns a planetary defense by ID
 func GetPlanetaryDefense(id int) *PlanetaryDefense { return PlanetaryDefenses[id] }
 
+// BestPlanetaryDefense returns the planetary defense a race installs at the
+// given tech: the one with the highest DefenseValue it can build. Races that
+// cannot build advanced defenses (WM) only get SDI.
+func BestPlanetaryDefense(tech TechRequirements, advanced bool) *PlanetaryDefense {
+	if !advanced {
+		return PlanetaryDefenses[DefenseSDI]
+	}
+	var best *PlanetaryDefense
+	for _, def := range PlanetaryDefenses {
+		if def.IsGenesisDevice || !def.Tech.CanBuildWith(tech) {
+			continue
+		}
+		if best == nil || def.DefenseValue > best.DefenseValue {
+			best = def
+		}
+	}
+	return best
+}
+
+// Coverage returns the fraction of bombing blocked by the given number of
+// defenses of this type. Each defense blocks DefenseValue/1000 of the
+// remaining damage.
+func (d *PlanetaryDefense) Coverage(defenses int) float64 {
+	if defenses <= 0 {
+		return 0
+	}
+	return 1 - math.Pow(1-float64(d.DefenseValue)/1000, float64(defenses))
+}
+
 // Lookup functions
 
 // GetEngine returns an engine by ID
@@ -422,3 +422,19 @@ func TestGetPlanetaryDefense(t *testing.T) {
 	// Invalid
 	assert.Nil(t, GetPlanetaryDefense(9999))
 }
+
+func TestBestPlanetaryDefense(t *testing.T) {
+	assert.Equal(t, DefenseSDI, BestPlanetaryDefense(TechRequirements{}, true).ID)
+	assert.Equal(t, DefenseLaserBattery, BestPlanetaryDefense(TechRequirements{Energy: 12}, true).ID)
+	assert.Equal(t, DefenseNeutronShield, BestPlanetaryDefense(TechRequirements{Energy: 26, Weapons: 26}, true).ID)
+
+	// No advanced defenses for War Mongers
+	assert.Equal(t, DefenseSDI, BestPlanetaryDefense(TechRequirements{Energy: 26}, false).ID)
+}
+
+func TestPlanetaryDefenseCoverage(t *testing.T) {
+	sdi := GetPlanetaryDefense(DefenseSDI)
+	assert.Zero(t, sdi.Coverage(0))
+	assert.InDelta(t, 0.01, sdi.Coverage(1), 1e-9)
+	assert.InDelta(t, 0.634, sdi.Coverage(100), 0.001)
+}
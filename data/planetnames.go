@@ -1,5 +1,7 @@
 package data
 
+import "strings"
+
 // PlanetNames are a mapping of NameIds (found in the XY file)
 // and real planet names.
 var PlanetNames = map[uint32]string{
@@ -1003,3 +1005,13 @@ var PlanetNames = map[uint32]string{
 	997: "Zucchini",
 	998: "Zulu",
 }
+
+// PlanetNameID returns the NameId of a planet name, ignoring case.
+func PlanetNameID(name string) (uint32, bool) {
+	for id, n := range PlanetNames {
+		if strings.EqualFold(n, name) {
+			return id, true
+		}
+	}
+	return 0, false
+}
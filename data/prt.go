@@ -57,6 +57,8 @@ type PRT struct {
 	CanRemoteDetonateMines            bool    // SD: true
 	MaxPacketWarp                     int     // PP: 13 (default is lower)
 	StargateSafetyBonus               bool    // IT: true (safer stargate exceeding)
+	CanGateCargo                      bool    // IT: true (other races can't gate with cargo)
+	PacketsTerraform                  bool    // PP: true (uncaught packets may terraform the target)
 
 	// Starting tech bonuses (added to base starting tech)
 	StartingTechEnergy       int
@@ -253,6 +255,7 @@ var AllPRTs = []PRT{
 		CargoAffectsCloak:        true,
 		MineTravelBonus:          0,
 		PacketsHavePenScanner:    true,
+		PacketsTerraform:         true,
 		MaxPacketWarp:            13,
 		CanBuildMineFields:       true,
 		CanBuildAdvancedDefenses: true,
@@ -278,6 +281,7 @@ var AllPRTs = []PRT{
 		MineTravelBonus:          0,
 		CanScanEnemyStargates:    true,
 		StargateSafetyBonus:      true,
+		CanGateCargo:             true,
 		CanBuildMineFields:       true,
 		CanBuildAdvancedDefenses: true,
 		CanBuildSmartBombs:       true,
@@ -392,3 +396,25 @@ func (p *PRT) FleetIntrinsicScannerRange(electronicsLevel int) ScannerStats {
 	}
 	return p.FleetIntrinsicScannerRangeFunc(electronicsLevel)
 }
+
+// PacketTerraformMass is the mass (kT) of an uncaught packet giving a PP race
+// one chance of terraforming the target planet.
+const PacketTerraformMass = 100
+
+// PacketScanRange returns the penetrating scanner range of a mineral packet
+// flung at the given warp: warp squared for PP races, 0 for the others.
+func (p *PRT) PacketScanRange(warp int) int {
+	if !p.PacketsHavePenScanner {
+		return 0
+	}
+	return warp * warp
+}
+
+// PacketTerraformChances returns how many chances of terraforming the target
+// planet an uncaught packet mass (kT) gives, 0 unless the race is PP.
+func (p *PRT) PacketTerraformChances(uncaught int) int {
+	if !p.PacketsTerraform || uncaught <= 0 {
+		return 0
+	}
+	return uncaught / PacketTerraformMass
+}
@@ -254,6 +254,23 @@ func TestPPAbilities(t *testing.T) {
 	if pp.StartingTechEnergy != 4 {
 		t.Errorf("PP StartingTechEnergy = %d, want 4", pp.StartingTechEnergy)
 	}
+	if !pp.PacketsTerraform {
+		t.Error("PP uncaught packets should terraform")
+	}
+	if got := pp.PacketScanRange(10); got != 100 {
+		t.Errorf("PP PacketScanRange(10) = %d, want 100", got)
+	}
+	if got := pp.PacketTerraformChances(250); got != 2 {
+		t.Errorf("PP PacketTerraformChances(250) = %d, want 2", got)
+	}
+
+	other := GetPRTByCode("JOAT")
+	if got := other.PacketScanRange(10); got != 0 {
+		t.Errorf("JOAT PacketScanRange(10) = %d, want 0", got)
+	}
+	if got := other.PacketTerraformChances(250); got != 0 {
+		t.Errorf("JOAT PacketTerraformChances(250) = %d, want 0", got)
+	}
 }
 
 func TestITAbilities(t *testing.T) {
@@ -271,6 +288,9 @@ func TestITAbilities(t *testing.T) {
 	if !it.StargateSafetyBonus {
 		t.Error("IT should have stargate safety bonus")
 	}
+	if !it.CanGateCargo {
+		t.Error("IT should be able to gate cargo")
+	}
 	if it.StartingTechPropulsion != 5 {
 		t.Errorf("IT StartingTechPropulsion = %d, want 5", it.StartingTechPropulsion)
 	}
@@ -0,0 +1,34 @@
+package data
+
+import "math"
+
+// TechBaseCosts lists the base research cost of each tech level: the
+// resources needed to reach level n from level n-1 in a field.
+var TechBaseCosts = [27]int{
+	0, 50, 80, 130, 210, 340, 550, 890, 1440, 2330,
+	3770, 6100, 9870, 13850, 18040, 22440, 27050, 31870, 36900, 42140,
+	47590, 53250, 59120, 65200, 71490, 77990, 84700,
+}
+
+// Research cost factors of a field, by race setting.
+const (
+	ResearchFactorExpensive = 1.75 // Costs 75% extra
+	ResearchFactorNormal    = 1.0
+	ResearchFactorCheap     = 0.5 // Costs 50% less
+)
+
+// TechCost returns the research resources needed to reach a tech level in
+// a field: the base cost of the level plus 10 resources for each level the
+// player has in all fields (totalLevels), times the cost factor of the
+// field, and doubled in games with slow tech advances. It returns 0 beyond
+// the last level.
+func TechCost(level, totalLevels int, factor float64, slowTech bool) int {
+	if level <= 0 || level >= len(TechBaseCosts) {
+		return 0
+	}
+	cost := float64(TechBaseCosts[level]+10*totalLevels) * factor
+	if slowTech {
+		cost *= 2
+	}
+	return int(math.Round(cost))
+}
@@ -0,0 +1,19 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTechCost(t *testing.T) {
+	assert.Equal(t, 50, TechCost(1, 0, ResearchFactorNormal, false))
+	assert.Equal(t, 2330+10*30, TechCost(9, 30, ResearchFactorNormal, false))
+	assert.Equal(t, 490, TechCost(2, 20, ResearchFactorExpensive, false)) // (80+200) × 1.75
+	assert.Equal(t, 50, TechCost(2, 2, ResearchFactorCheap, false))
+	assert.Equal(t, 100, TechCost(1, 0, ResearchFactorNormal, true))
+
+	// No level 0 to research, none beyond 26
+	assert.Zero(t, TechCost(0, 0, ResearchFactorNormal, false))
+	assert.Zero(t, TechCost(27, 150, ResearchFactorNormal, false))
+}
@@ -16,6 +16,54 @@ const (
 	encodesE         = "wxyz+-,!.?:;'*%$"
 )
 
+// Stars! is a Windows program: characters outside ASCII are stored as
+// Windows-1252 (ANSI) bytes. Bytes 0xA0-0xFF match Unicode; cp1252High maps
+// the bytes 0x80-0x9F, 0 marking the five bytes Windows-1252 leaves undefined.
+var cp1252High = [32]rune{
+	0x20AC, 0, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0, 0x017D, 0,
+	0, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0, 0x017E, 0x0178,
+}
+
+// DecodeWindows1252 returns the character of a Windows-1252 byte. Undefined
+// bytes decode to themselves, as Windows does.
+func DecodeWindows1252(b byte) rune {
+	if b >= 0x80 && b < 0xA0 && cp1252High[b-0x80] != 0 {
+		return cp1252High[b-0x80]
+	}
+	return rune(b)
+}
+
+// EncodeWindows1252 returns the Windows-1252 byte of a character, or false
+// if Stars! cannot store it.
+func EncodeWindows1252(r rune) (byte, bool) {
+	switch {
+	case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+		return byte(r), true
+	case r >= 0x80 && r < 0xA0:
+		// Undefined bytes stand for themselves, see DecodeWindows1252
+		return byte(r), cp1252High[r-0x80] == 0
+	}
+	for i, c := range cp1252High {
+		if c == r {
+			return byte(0x80 + i), true
+		}
+	}
+	return 0, false
+}
+
+// IsStarsText returns true if every character of the text can be stored in
+// a Stars! file. EncodeStarsString replaces the others with '?'.
+func IsStarsText(text string) bool {
+	for _, r := range text {
+		if _, ok := EncodeWindows1252(r); !ok {
+			return false
+		}
+	}
+	return true
+}
+
 // DecodeHexStarsString decodes a hex-encoded Stars! string
 func DecodeHexStarsString(hexChars string, byteSize int) (string, error) {
 	var result strings.Builder
@@ -59,7 +107,7 @@ func DecodeHexStarsString(hexChars string, byteSize int) (string, error) {
 			}
 
 			theChar := byte(parsed & 0xff)
-			result.WriteByte(theChar)
+			result.WriteRune(DecodeWindows1252(theChar))
 			// Advance passed the two characters we decoded
 			t += 2
 		default:
@@ -154,12 +202,16 @@ func charToNibble(ch byte) byte {
 // hexDigits for encoding
 const hexDigits = "0123456789ABCDEF"
 
-// EncodeHexStarsString encodes a string using Stars! text encoding and returns the hex-encoded string
+// EncodeHexStarsString encodes a string using Stars! text encoding and returns the hex-encoded string.
+// Characters outside Windows-1252 are replaced with '?'.
 func EncodeHexStarsString(text string) string {
 	var hexChars strings.Builder
 
-	for i := 0; i < len(text); i++ {
-		thisChar := text[i]
+	for _, r := range text {
+		thisChar, ok := EncodeWindows1252(r)
+		if !ok {
+			thisChar = '?'
+		}
 
 		// Check if this character is one that will be encoded with 1 nibble
 		index := strings.IndexByte(encodesOneNibble, thisChar)
@@ -22,6 +22,8 @@ func TestDecodeEncodeStarsString(t *testing.T) {
 		{"numbers only", "1234567890"},
 		{"ship name style", "Scout #1"},
 		{"planet name", "Alpha Centauri"},
+		{"accented latin", "Éléonore Ångström Ñandú"},
+		{"windows-1252 punctuation", "Zoë’s “Œuvre” – €5"},
 	}
 
 	for _, tt := range tests {
@@ -101,6 +103,43 @@ func TestEncodeHexStarsString(t *testing.T) {
 	}
 }
 
+func TestEncodeStarsStringWindows1252(t *testing.T) {
+	// é is stored as the Windows-1252 byte 0xE9, nibble-swapped after an F
+	if got := EncodeHexStarsString("é"); got != "F9E" {
+		t.Errorf("EncodeHexStarsString(%q) = %q, want %q", "é", got, "F9E")
+	}
+	// € is 0x80 in Windows-1252
+	if got := EncodeHexStarsString("€"); got != "F08" {
+		t.Errorf("EncodeHexStarsString(%q) = %q, want %q", "€", got, "F08")
+	}
+
+	decoded, err := DecodeStarsString(EncodeStarsString("Kōbe 日本"))
+	if err != nil {
+		t.Fatalf("DecodeStarsString failed: %v", err)
+	}
+	if decoded != "K?be ??" {
+		t.Errorf("characters outside Windows-1252: got %q, want %q", decoded, "K?be ??")
+	}
+}
+
+func TestIsStarsText(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"Alpha Centauri", true},
+		{"Café Ñ €", true},
+		{"Kōbe", false},
+		{"日本", false},
+		{"\xff", false},
+	}
+	for _, tt := range tests {
+		if got := IsStarsText(tt.text); got != tt.want {
+			t.Errorf("IsStarsText(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
 func TestHexConversions(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -0,0 +1,206 @@
+// Package filenames handles the naming conventions of Stars! files.
+//
+// A game named "game" is made of game.xy (the universe), game.hst (the host
+// file) and, for each player N from 1 to 16, game.mN (the turn), game.xN
+// (the orders) and game.hN (the history); races live in .rN files. Stars!
+// is a DOS/Windows program: names are case-insensitive, often written in
+// upper case (GAME.M1) and limited to 8 characters before the extension.
+//
+//	n := filenames.Parse("turns/GAME.M3")
+//	n.Kind   // filenames.M
+//	n.Player // 3
+//	xy, ok := filenames.Find(filenames.Companion("turns/GAME.M3", filenames.XY, 0))
+//	// "turns/GAME.XY", true
+package filenames
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Kind is the kind of a Stars! file, given by its extension.
+type Kind int
+
+const (
+	Unknown Kind = iota
+	M            // Player turn (.m1-.m16)
+	X            // Player orders (.x1-.x16)
+	H            // Player history (.h1-.h16)
+	XY           // Universe (.xy)
+	R            // Race (.r1-.r16)
+	HST          // Host (.hst)
+)
+
+// MaxBaseLength is the longest name Stars! accepts before the extension.
+const MaxBaseLength = 8
+
+// String returns the extension of the kind without the player number, in
+// lower case ("m", "xy", ...).
+func (k Kind) String() string {
+	switch k {
+	case M:
+		return "m"
+	case X:
+		return "x"
+	case H:
+		return "h"
+	case XY:
+		return "xy"
+	case R:
+		return "r"
+	case HST:
+		return "hst"
+	}
+	return "unknown"
+}
+
+// PerPlayer returns true for the kinds of files numbered by player.
+func (k Kind) PerPlayer() bool {
+	return k == M || k == X || k == H || k == R
+}
+
+// Name is a Stars! file name split in its parts.
+type Name struct {
+	Dir    string // Directory, as given ("" for none)
+	Base   string // Name before the extension, as given ("GAME")
+	Kind   Kind
+	Player int // 1-16 for per-player kinds, 0 otherwise
+}
+
+// Parse splits a file path. Kind is Unknown when the extension is not one of
+// Stars!, including player numbers outside 1-16.
+func Parse(path string) Name {
+	dir, file := filepath.Split(path)
+	ext := filepath.Ext(file)
+	n := Name{Dir: dir, Base: strings.TrimSuffix(file, ext)}
+
+	lower := strings.ToLower(strings.TrimPrefix(ext, "."))
+	switch lower {
+	case "xy":
+		n.Kind = XY
+		return n
+	case "hst":
+		n.Kind = HST
+		return n
+	}
+	if len(lower) < 2 {
+		return n
+	}
+	player, err := strconv.Atoi(lower[1:])
+	if err != nil || player < 1 || player > 16 || lower[1] == '0' || lower[1] == '+' {
+		return n
+	}
+	switch lower[0] {
+	case 'm':
+		n.Kind = M
+	case 'x':
+		n.Kind = X
+	case 'h':
+		n.Kind = H
+	case 'r':
+		n.Kind = R
+	default:
+		return n
+	}
+	n.Player = player
+	return n
+}
+
+// MatchesPlayer reports whether the player number of the name agrees with
+// the player index (0-15) in the header of the file. Stars! reads the player
+// from the header, so a turn file copied to the wrong name is opened as
+// another player's. Kinds not numbered by player, and race files, whose
+// header player index is always 31, always match.
+func (n Name) MatchesPlayer(playerIndex int) bool {
+	if !n.Kind.PerPlayer() || n.Kind == R {
+		return true
+	}
+	return n.Player == playerIndex+1
+}
+
+// KindOf returns the kind of a file from its name.
+func KindOf(path string) Kind {
+	return Parse(path).Kind
+}
+
+// Ext returns the extension of the name in lower case, with its dot
+// (".m3", ".xy"), or "" for an unknown kind.
+func (n Name) Ext() string {
+	switch {
+	case n.Kind == Unknown:
+		return ""
+	case n.Kind.PerPlayer():
+		return "." + n.Kind.String() + strconv.Itoa(n.Player)
+	}
+	return "." + n.Kind.String()
+}
+
+// String returns the path of the file.
+func (n Name) String() string {
+	return n.Dir + n.Base + n.Ext()
+}
+
+// Normalize returns the path with the file name in lower case and its base
+// cut to the 8 characters Stars! accepts: "turns/MyGame2401.M1" becomes
+// "turns/mygame24.m1". Paths of unknown kinds are returned unchanged.
+func Normalize(path string) string {
+	n := Parse(path)
+	if n.Kind == Unknown {
+		return path
+	}
+	n.Base = strings.ToLower(n.Base)
+	if len(n.Base) > MaxBaseLength {
+		n.Base = n.Base[:MaxBaseLength]
+	}
+	return n.String()
+}
+
+// Companion returns the path of another file of the same game, next to the
+// given one: the XY file of game.m1 is game.xy. The player number is ignored
+// for kinds not numbered by player. When the given file name is in upper
+// case, so is the companion's extension.
+func Companion(path string, kind Kind, player int) string {
+	n := Parse(path)
+	n.Kind = kind
+	n.Player = 0
+	if kind.PerPlayer() {
+		n.Player = player
+	}
+	name := n.String()
+	if ext := filepath.Ext(path); ext != "" && ext == strings.ToUpper(ext) && ext != strings.ToLower(ext) {
+		name = n.Dir + n.Base + strings.ToUpper(n.Ext())
+	}
+	return name
+}
+
+// Find looks for a file regardless of the case of its name, as Stars! does
+// on Windows. It returns the path of the file found, trying the path as
+// given first.
+func Find(path string) (string, bool) {
+	if _, err := os.Stat(path); err == nil {
+		return path, true
+	}
+	dir, file := filepath.Split(path)
+	readDir := dir
+	if readDir == "" {
+		readDir = "."
+	}
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return "", false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(entry.Name(), file) {
+			return dir + entry.Name(), true
+		}
+	}
+	return "", false
+}
+
+// IsZip returns true if the path names a ZIP archive, the usual way hosts
+// send a turn's files.
+func IsZip(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".zip")
+}
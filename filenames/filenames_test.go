@@ -0,0 +1,75 @@
+package filenames
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		path   string
+		kind   Kind
+		player int
+		base   string
+	}{
+		{"game.m1", M, 1, "game"},
+		{"turns/GAME.M16", M, 16, "GAME"},
+		{"Game.X3", X, 3, "Game"},
+		{"game.h2", H, 2, "game"},
+		{"player.R1", R, 1, "player"},
+		{"game.XY", XY, 0, "game"},
+		{"game-2400.hst", HST, 0, "game-2400"},
+		{"game.m17", Unknown, 0, "game"},
+		{"game.m0", Unknown, 0, "game"},
+		{"game.m01", Unknown, 0, "game"},
+		{"game.m", Unknown, 0, "game"},
+		{"game.mx", Unknown, 0, "game"},
+		{"game.txt", Unknown, 0, "game"},
+		{"game", Unknown, 0, "game"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			n := Parse(tt.path)
+			assert.Equal(t, tt.kind, n.Kind)
+			assert.Equal(t, tt.player, n.Player)
+			assert.Equal(t, tt.base, n.Base)
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "turns/mygame24.m1", Normalize("turns/MyGame2401.M1"))
+	assert.Equal(t, "game.xy", Normalize("GAME.XY"))
+	assert.Equal(t, "notes.TXT", Normalize("notes.TXT"))
+}
+
+func TestMatchesPlayer(t *testing.T) {
+	assert.True(t, Parse("game.m1").MatchesPlayer(0))
+	assert.False(t, Parse("GAME.M1").MatchesPlayer(1))
+	assert.False(t, Parse("game.x16").MatchesPlayer(0))
+	assert.True(t, Parse("game.hst").MatchesPlayer(3))
+	assert.True(t, Parse("race.r2").MatchesPlayer(31))
+}
+
+func TestCompanion(t *testing.T) {
+	assert.Equal(t, "turns/game.xy", Companion("turns/game.m1", XY, 0))
+	assert.Equal(t, "GAME.XY", Companion("GAME.M1", XY, 0))
+	assert.Equal(t, "game.x2", Companion("game.m2", X, 2))
+	assert.Equal(t, "game.hst", Companion("game.xy", HST, 5))
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "GAME.XY"), nil, 0o644))
+
+	path, ok := Find(filepath.Join(dir, "game.xy"))
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "GAME.XY"), path)
+
+	_, ok = Find(filepath.Join(dir, "game.hst"))
+	assert.False(t, ok)
+}
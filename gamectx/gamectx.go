@@ -0,0 +1,272 @@
+// Package gamectx assembles everything known about a game from any one of
+// its files.
+//
+// Tools are usually given a single file, game.m1 say, but need more than it
+// holds: planet names and positions are in the universe file, planets seen
+// in earlier years in the player's history file, and allies' scans in their
+// own turn files. Load finds these files next to the given one and merges
+// them into one GameStore:
+//
+//   - the universe file (.xy)
+//   - the given file
+//   - the history file of the given file's player (.hN)
+//   - every turn file (.mN) and the host file (.hst) of the same game and
+//     year in the directory
+//
+// Files are told apart by the game ID and year in their headers, not by
+// their names, so a directory may hold several games or an archive of
+// years.
+//
+// Example usage:
+//
+//	ctx, err := gamectx.Load("games/game.m1")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("%s, year %d, from %v\n", ctx.Store.GameName, ctx.Year(), ctx.Files)
+package gamectx
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/filenames"
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/remotefs"
+	"github.com/neper-stars/houston/store"
+)
+
+// ErrNotGameFile is returned for files that do not belong to a game, such
+// as race files.
+var ErrNotGameFile = errors.New("not a Stars! game file")
+
+// FileSystem reads game files; remotefs.FS implements it.
+type FileSystem interface {
+	ReadFile(path string) ([]byte, error)
+	ReadDir(dir string) ([]string, error)
+}
+
+// Options controls how a context is loaded.
+type Options struct {
+	// FS reads the files. If nil, local files are read.
+	FS FileSystem
+
+	// Cache keeps parsed files across runs (see parser.Cache). If nil,
+	// files are parsed every time.
+	Cache *parser.Cache
+}
+
+// Context is a game as seen from one of its files.
+type Context struct {
+	Store *store.GameStore
+
+	File   string   // File given to Load
+	Player int      // Player index of the file (0-15), -1 for universe and host files
+	Files  []string // Files merged into Store, in load order
+}
+
+// Year returns the game year of the context.
+func (c *Context) Year() int {
+	return int(c.Store.Turn) + blocks.StarsBaseYear
+}
+
+// Load assembles the context of a game file with default options.
+func Load(path string) (*Context, error) {
+	return LoadWithOptions(path, nil)
+}
+
+// LoadWithOptions assembles the context of a game file: the file itself,
+// then the files of the same game found in its directory (see the package
+// documentation). Errors reading the given file are returned; companion
+// files that cannot be read or parsed are skipped.
+//
+// A ZIP archive is loaded with all the files it holds, as by
+// store.GameStore.AddZip.
+func LoadWithOptions(filename string, opts *Options) (*Context, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	fsys := opts.FS
+	if fsys == nil {
+		fsys = remotefs.FS{}
+	}
+
+	gs := store.New()
+	gs.SetCache(opts.Cache)
+	ctx := &Context{Store: gs, File: filename, Player: -1}
+
+	data, err := fsys.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if filenames.IsZip(filename) {
+		if err := gs.AddZip(filename, data); err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", filename, err)
+		}
+		ctx.Files = []string{filename}
+		return ctx, nil
+	}
+
+	name := filenames.Parse(filename)
+	kind := name.Kind
+	if kind == filenames.Unknown || kind == filenames.R {
+		return nil, fmt.Errorf("%w: %s", ErrNotGameFile, filename)
+	}
+	header, err := parser.FileData(data).FileHeader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	if kind.PerPlayer() {
+		ctx.Player = header.PlayerIndex()
+	}
+
+	siblings := findSiblings(fsys, filename, header.GameID)
+
+	// The universe goes first for planet names and positions
+	if xy, ok := siblings.companion(filenames.XY, 0, name.Base); ok && kind != filenames.XY {
+		ctx.add(xy.path, xy.data)
+	}
+	if err := gs.AddFile(filename, data); err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", filename, err)
+	}
+	ctx.Files = append(ctx.Files, filename)
+
+	if ctx.Player >= 0 && kind != filenames.H {
+		if h, ok := siblings.companion(filenames.H, ctx.Player+1, name.Base); ok {
+			ctx.add(h.path, h.data)
+		}
+	}
+
+	// Universe and history files are not tied to a year: take the latest
+	// of the directory
+	turn := header.Turn
+	if kind == filenames.XY || kind == filenames.H {
+		turn = siblings.latestTurn()
+	}
+	for _, f := range siblings {
+		if f.isTurn() && f.turn == turn {
+			ctx.add(f.path, f.data)
+		}
+	}
+	return ctx, nil
+}
+
+// add merges a companion file, skipping it if the store rejects it.
+func (c *Context) add(path string, data []byte) {
+	if err := c.Store.AddFile(path, data); err == nil {
+		c.Files = append(c.Files, path)
+	}
+}
+
+// sibling is a file of the same game next to the file given to Load.
+type sibling struct {
+	path string
+	name filenames.Name
+	turn uint16
+	data []byte
+}
+
+// isTurn returns true for the files holding the state of one year: turn
+// and host files.
+func (f sibling) isTurn() bool {
+	return f.name.Kind == filenames.M || f.name.Kind == filenames.HST
+}
+
+type siblingList []sibling
+
+// companion returns the sibling of a kind and player (0 for kinds not
+// numbered by player), preferring the one named after base.
+func (l siblingList) companion(kind filenames.Kind, player int, base string) (sibling, bool) {
+	var found sibling
+	ok := false
+	for _, f := range l {
+		if f.name.Kind != kind || f.name.Player != player {
+			continue
+		}
+		if strings.EqualFold(f.name.Base, base) {
+			return f, true
+		}
+		if !ok {
+			found, ok = f, true
+		}
+	}
+	return found, ok
+}
+
+// latestTurn returns the latest turn of the turn and host files.
+func (l siblingList) latestTurn() uint16 {
+	var turn uint16
+	for _, f := range l {
+		if f.isTurn() {
+			turn = max(turn, f.turn)
+		}
+	}
+	return turn
+}
+
+// findSiblings reads the game files of a game in the directory of filename,
+// other than filename itself, sorted by name. Race files, ZIP archives and
+// orders files are left out.
+func findSiblings(fsys FileSystem, filename string, gameID uint32) siblingList {
+	dir := dirOf(filename)
+	names, err := fsys.ReadDir(dirOrDot(dir))
+	if err != nil {
+		return nil
+	}
+	slices.Sort(names)
+
+	var list siblingList
+	for _, name := range names {
+		p := dir + name
+		n := filenames.Parse(name)
+		switch n.Kind {
+		case filenames.M, filenames.H, filenames.XY, filenames.HST:
+		default:
+			continue
+		}
+		if samePath(p, filename) {
+			continue
+		}
+		data, err := fsys.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		header, err := parser.FileData(data).FileHeader()
+		if err != nil || header.GameID != gameID {
+			continue
+		}
+		list = append(list, sibling{path: p, name: n, turn: header.Turn, data: data})
+	}
+	return list
+}
+
+// dirOf returns the directory part of a local or remote path, with its
+// trailing separator ("" for none).
+func dirOf(p string) string {
+	if remotefs.IsRemote(p) {
+		dir, _ := path.Split(p)
+		if dir == "" {
+			return p[:strings.IndexByte(p, ':')+1]
+		}
+		return dir
+	}
+	dir, _ := filepath.Split(p)
+	return dir
+}
+
+func dirOrDot(dir string) string {
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
+
+// samePath compares paths the way Stars! does, regardless of case.
+func samePath(a, b string) bool {
+	return strings.EqualFold(filepath.Clean(a), filepath.Clean(b))
+}
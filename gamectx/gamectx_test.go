@@ -0,0 +1,80 @@
+package gamectx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const history = "../testdata/scenario-map/history/"
+
+func TestLoad(t *testing.T) {
+	ctx, err := Load(history + "game-2440.m1")
+	require.NoError(t, err)
+
+	// Files of other years in the directory are left out
+	assert.Equal(t, []string{
+		history + "game-2440.xy",
+		history + "game-2440.m1",
+		history + "game-2440.hst",
+		history + "game-2440.m2",
+	}, ctx.Files)
+	assert.Equal(t, 0, ctx.Player)
+	assert.Equal(t, 2440, ctx.Year())
+
+	// Both players' planets are known, with names from the universe
+	owners := make(map[int]bool)
+	for _, planet := range ctx.Store.AllPlanets() {
+		owners[planet.Owner] = true
+		assert.NotEmpty(t, planet.Name)
+	}
+	assert.True(t, owners[0] && owners[1])
+}
+
+func TestLoadHistoryFile(t *testing.T) {
+	ctx, err := Load("../testdata/scenario-map/game.m1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"../testdata/scenario-map/game.xy",
+		"../testdata/scenario-map/game.m1",
+		"../testdata/scenario-map/game.h1",
+	}, ctx.Files, "orders files are not loaded")
+}
+
+func TestLoadUniverse(t *testing.T) {
+	// The universe is not tied to a year: the latest one is loaded
+	ctx, err := Load(history + "game-2400.xy")
+	require.NoError(t, err)
+	assert.Equal(t, -1, ctx.Player)
+	assert.Equal(t, 2482, ctx.Year())
+	assert.Contains(t, ctx.Files, history+"game-2482.m2")
+	assert.NotContains(t, ctx.Files, history+"game-2481.m2")
+}
+
+func TestLoadOtherGames(t *testing.T) {
+	// Files of another game in the same directory are skipped
+	dir := t.TempDir()
+	for src, dst := range map[string]string{
+		history + "game-2440.m1":                   "game.m1",
+		history + "game-2440.m2":                   "game.m2",
+		"../testdata/scenario-map/game.xy":         "game.xy",
+		"../testdata/scenario-basic/game.m1":       "other.m1",
+		"../testdata/scenario-map/history/game.r1": "game.r1",
+	} {
+		data, err := os.ReadFile(src)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, dst), data, 0644))
+	}
+
+	ctx, err := Load(filepath.Join(dir, "game.m1"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "game.m1"), filepath.Join(dir, "game.m2")}, ctx.Files)
+
+	_, err = Load(filepath.Join(dir, "game.r1"))
+	assert.ErrorIs(t, err, ErrNotGameFile)
+	_, err = Load(filepath.Join(dir, "missing.m1"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
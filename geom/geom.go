@@ -0,0 +1,158 @@
+// Package geom provides the distance, travel time and bounding box
+// calculations shared by navigation, threat assessment and map rendering.
+//
+// Stars! coordinates are integer light years on a flat plane. A fleet at
+// warp w travels w² light years per year.
+package geom
+
+import "math"
+
+// Distance returns the distance in light years between two points.
+func Distance(x1, y1, x2, y2 int) float64 {
+	return math.Hypot(float64(x2-x1), float64(y2-y1))
+}
+
+// WarpSpeed returns the distance in light years travelled in one year at
+// the given warp.
+func WarpSpeed(warp int) float64 {
+	return float64(warp * warp)
+}
+
+// YearsAtWarp returns the number of years needed to travel a distance at
+// the given warp, rounded up, or -1 if the warp is not positive. No time is
+// needed to travel no distance.
+func YearsAtWarp(distance float64, warp int) int {
+	if distance <= 0 {
+		return 0
+	}
+	if warp <= 0 {
+		return -1
+	}
+	return int(math.Ceil(distance / WarpSpeed(warp)))
+}
+
+// SegmentDistance returns the distance from point (px, py) to the segment
+// from (ax, ay) to (bx, by).
+func SegmentDistance(px, py, ax, ay, bx, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	lengthSq := dx*dx + dy*dy
+	if lengthSq == 0 {
+		return math.Hypot(px-ax, py-ay)
+	}
+	t := ((px-ax)*dx + (py-ay)*dy) / lengthSq
+	t = max(0, min(1, t))
+	return math.Hypot(px-(ax+t*dx), py-(ay+t*dy))
+}
+
+// Intercept returns where and when a pursuer starting at (px, py) and
+// travelling at speed ly/year can first meet a target at (tx, ty) moving
+// (vx, vy) ly per year in a straight line. It returns ok=false when the
+// target cannot be caught.
+func Intercept(px, py, speed, tx, ty, vx, vy float64) (x, y, years float64, ok bool) {
+	// Solve |T + V*t - P| = speed*t for the smallest t >= 0
+	dx, dy := tx-px, ty-py
+	a := vx*vx + vy*vy - speed*speed
+	b := 2 * (dx*vx + dy*vy)
+	c := dx*dx + dy*dy
+
+	if c == 0 {
+		return tx, ty, 0, true
+	}
+
+	var t float64
+	if math.Abs(a) < 1e-9 {
+		// Same speed: a single root, when the target is closing in
+		if b >= 0 {
+			return 0, 0, 0, false
+		}
+		t = -c / b
+	} else {
+		disc := b*b - 4*a*c
+		if disc < 0 {
+			return 0, 0, 0, false
+		}
+		sq := math.Sqrt(disc)
+		t1, t2 := (-b-sq)/(2*a), (-b+sq)/(2*a)
+		t = math.Inf(1)
+		for _, root := range []float64{t1, t2} {
+			if root >= 0 && root < t {
+				t = root
+			}
+		}
+		if math.IsInf(t, 1) {
+			return 0, 0, 0, false
+		}
+	}
+
+	return tx + vx*t, ty + vy*t, t, true
+}
+
+// Bounds is an axis-aligned bounding box in game coordinates. The zero
+// value is empty; use Add to grow it.
+type Bounds struct {
+	MinX, MinY int
+	MaxX, MaxY int
+	valid      bool
+}
+
+// NewBounds returns the bounding box of a rectangle.
+func NewBounds(minX, minY, maxX, maxY int) Bounds {
+	return Bounds{MinX: minX, MinY: minY, MaxX: maxX, MaxY: maxY, valid: true}
+}
+
+// Empty reports whether no point was added to the box.
+func (b Bounds) Empty() bool {
+	return !b.valid
+}
+
+// Add grows the box to include a point.
+func (b *Bounds) Add(x, y int) {
+	if !b.valid {
+		*b = NewBounds(x, y, x, y)
+		return
+	}
+	b.MinX = min(b.MinX, x)
+	b.MinY = min(b.MinY, y)
+	b.MaxX = max(b.MaxX, x)
+	b.MaxY = max(b.MaxY, y)
+}
+
+// Union returns the smallest box containing both boxes.
+func (b Bounds) Union(o Bounds) Bounds {
+	if !o.valid {
+		return b
+	}
+	if !b.valid {
+		return o
+	}
+	return NewBounds(min(b.MinX, o.MinX), min(b.MinY, o.MinY), max(b.MaxX, o.MaxX), max(b.MaxY, o.MaxY))
+}
+
+// Expand returns the box grown by margin light years on every side.
+func (b Bounds) Expand(margin int) Bounds {
+	if !b.valid {
+		return b
+	}
+	return NewBounds(b.MinX-margin, b.MinY-margin, b.MaxX+margin, b.MaxY+margin)
+}
+
+// Width returns the horizontal extent of the box (0 if empty).
+func (b Bounds) Width() int {
+	if !b.valid {
+		return 0
+	}
+	return b.MaxX - b.MinX
+}
+
+// Height returns the vertical extent of the box (0 if empty).
+func (b Bounds) Height() int {
+	if !b.valid {
+		return 0
+	}
+	return b.MaxY - b.MinY
+}
+
+// Contains reports whether a point lies inside the box, edges included.
+func (b Bounds) Contains(x, y int) bool {
+	return b.valid && x >= b.MinX && x <= b.MaxX && y >= b.MinY && y <= b.MaxY
+}
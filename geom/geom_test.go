@@ -0,0 +1,85 @@
+package geom
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 5.0, Distance(0, 0, 3, 4))
+	assert.Equal(t, 5.0, Distance(3, 4, 0, 0))
+	assert.Equal(t, 0.0, Distance(7, 7, 7, 7))
+}
+
+func TestYearsAtWarp(t *testing.T) {
+	assert.Equal(t, 81.0, WarpSpeed(9))
+	assert.Equal(t, 1, YearsAtWarp(81, 9))
+	assert.Equal(t, 2, YearsAtWarp(82, 9))
+	assert.Equal(t, 0, YearsAtWarp(0, 0))
+	assert.Equal(t, -1, YearsAtWarp(10, 0))
+}
+
+func TestSegmentDistance(t *testing.T) {
+	assert.Equal(t, 3.0, SegmentDistance(5, 3, 0, 0, 10, 0), "perpendicular foot inside segment")
+	assert.Equal(t, 5.0, SegmentDistance(13, 4, 0, 0, 10, 0), "closest to an endpoint")
+	assert.Equal(t, 5.0, SegmentDistance(3, 4, 0, 0, 0, 0), "degenerate segment")
+	assert.InDelta(t, 5.0, SegmentDistance(15, 0, 0, 0, 10, 0), 1e-9, "beyond the end")
+}
+
+func TestIntercept(t *testing.T) {
+	// Stationary target: straight line at full speed
+	x, y, years, ok := Intercept(0, 0, 10, 30, 40, 0, 0)
+	assert.True(t, ok)
+	assert.Equal(t, 30.0, x)
+	assert.Equal(t, 40.0, y)
+	assert.InDelta(t, 5.0, years, 1e-9)
+
+	// Target fleeing at 50 ly/year along X, pursuer at 100 ly/year
+	x, y, years, ok = Intercept(0, 0, 100, 100, 0, 50, 0)
+	assert.True(t, ok)
+	assert.InDelta(t, 2.0, years, 1e-9)
+	assert.InDelta(t, 200.0, x, 1e-9)
+	assert.Equal(t, 0.0, y)
+
+	// Head-on at the same speed: meet half way
+	_, _, years, ok = Intercept(0, 0, 10, 100, 0, -10, 0)
+	assert.True(t, ok)
+	assert.InDelta(t, 5.0, years, 1e-9)
+
+	// Faster target running away cannot be caught
+	_, _, _, ok = Intercept(0, 0, 10, 100, 0, 20, 0)
+	assert.False(t, ok)
+
+	// Same speed running away cannot be caught either
+	_, _, _, ok = Intercept(0, 0, 10, 100, 0, 10, 0)
+	assert.False(t, ok)
+
+	// Already there
+	_, _, years, ok = Intercept(5, 5, 0, 5, 5, 3, 3)
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, years)
+	assert.False(t, math.IsNaN(years))
+}
+
+func TestBounds(t *testing.T) {
+	var b Bounds
+	assert.True(t, b.Empty())
+	assert.False(t, b.Contains(0, 0))
+	assert.Equal(t, 0, b.Width())
+
+	b.Add(10, 20)
+	b.Add(-5, 40)
+	assert.False(t, b.Empty())
+	assert.Equal(t, NewBounds(-5, 20, 10, 40), b)
+	assert.Equal(t, 15, b.Width())
+	assert.Equal(t, 20, b.Height())
+	assert.True(t, b.Contains(0, 30))
+	assert.False(t, b.Contains(0, 41))
+
+	assert.Equal(t, NewBounds(-6, 19, 11, 41), b.Expand(1))
+	assert.Equal(t, NewBounds(-5, 0, 10, 40), b.Union(NewBounds(0, 0, 1, 1)))
+	assert.Equal(t, b, b.Union(Bounds{}))
+	assert.Equal(t, b, Bounds{}.Union(b))
+}
@@ -202,6 +202,7 @@ var (
 	HashRacePasswordBytes     = password.HashRacePasswordBytes
 	GuessRacePassword         = password.GuessRacePassword
 	GuessRacePasswordParallel = password.GuessRacePasswordParallel
+	PasswordSearchSpace       = password.SearchSpace
 )
 
 // ProgressCallback is called periodically during parallel password search
@@ -0,0 +1,178 @@
+// Package balancemod loads community balance mods: changes to the cost, tech
+// requirements and stats of components and hulls, declared in YAML.
+//
+// A mod patches the component tables of the data package in place, so every
+// calculation reading them (design cost, mass and combat stats, starbase
+// advice, threat estimates) honors it. Stars! itself keeps its own tables:
+// a mod only makes sense for games whose turns are not generated by Stars!.
+//
+//	name: Cheaper lasers
+//	items:
+//	  Laser:
+//	    cost: {resources: 4, boranium: 5}
+//	    tech: {weapons: 1}
+//	    stats: {power: 12}
+//	  Tritanium:
+//	    stats: {armor_value: 60, mass: 50}
+//
+// Stats are the integer fields of the component, named in snake case (see
+// the structs of the data package, e.g. data.BeamWeapon).
+//
+// Example usage:
+//
+//	mod, _ := balancemod.Load("mod.yaml")
+//	restore, err := balancemod.Apply(mod)
+//	defer restore()
+package balancemod
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/neper-stars/houston/data"
+)
+
+// ErrUnknownItem is returned for a component or hull not in the data tables.
+var ErrUnknownItem = errors.New("unknown component")
+
+// Mod is a set of component changes.
+type Mod struct {
+	Name  string             `yaml:"name"`
+	Items map[string]ItemMod `yaml:"items"` // Keyed by component or hull name
+}
+
+// ItemMod changes one component. Values left out are kept.
+type ItemMod struct {
+	Cost  map[string]int `yaml:"cost"`  // resources, ironium, boranium, germanium
+	Tech  map[string]int `yaml:"tech"`  // energy, weapons, propulsion, construction, electronics, biotech
+	Stats map[string]int `yaml:"stats"` // Integer stats, e.g. mass, power, range, armor_value
+}
+
+// tables lists the component tables a mod can change.
+func tables() []any {
+	return []any{
+		data.Engines, data.Scanners, data.Shields, data.Armors, data.BeamWeapons,
+		data.Torpedoes, data.Bombs, data.MiningRobots, data.MineLayers, data.Orbitals,
+		data.Electricals, data.Mechanicals, data.Terraformers, data.PlanetaryScanners,
+		data.PlanetaryDefenses, data.Hulls,
+	}
+}
+
+// Parse decodes a mod from YAML. Unknown keys are rejected.
+func Parse(data []byte) (*Mod, error) {
+	var m Mod
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse balance mod: %w", err)
+	}
+	if _, err := m.resolve(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Load reads a mod from a YAML file.
+func Load(path string) (*Mod, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read balance mod: %w", err)
+	}
+	return Parse(raw)
+}
+
+// change is a field of a component set to a value.
+type change struct {
+	field reflect.Value
+	value int
+}
+
+// resolve finds the fields changed by the mod, checking every name.
+func (m *Mod) resolve() ([]change, error) {
+	index := make(map[string]reflect.Value)
+	for _, table := range tables() {
+		iter := reflect.ValueOf(table).MapRange()
+		for iter.Next() {
+			item := iter.Value().Elem()
+			index[strings.ToLower(item.FieldByName("Name").String())] = item
+		}
+	}
+
+	names := make([]string, 0, len(m.Items))
+	for name := range m.Items {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var changes []change
+	for _, name := range names {
+		item, ok := index[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownItem, name)
+		}
+		mod := m.Items[name]
+		for _, group := range []struct {
+			target reflect.Value
+			values map[string]int
+		}{
+			{item.FieldByName("Cost"), mod.Cost},
+			{item.FieldByName("Tech"), mod.Tech},
+			{item, mod.Stats},
+		} {
+			for key, value := range group.values {
+				field, err := intField(group.target, key)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", name, err)
+				}
+				if value < 0 {
+					return nil, fmt.Errorf("%s: %s must not be negative", name, key)
+				}
+				changes = append(changes, change{field, value})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// intField returns the integer field of a struct named by a snake case key.
+func intField(v reflect.Value, key string) (reflect.Value, error) {
+	if !v.IsValid() {
+		return reflect.Value{}, fmt.Errorf("%s cannot be changed", key)
+	}
+	want := strings.ReplaceAll(key, "_", "")
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if f.Name == "ID" || f.Type.Kind() != reflect.Int || !strings.EqualFold(f.Name, want) {
+			continue
+		}
+		return v.Field(i), nil
+	}
+	return reflect.Value{}, fmt.Errorf("no %s to change", key)
+}
+
+// Apply patches the component tables with the mod. The returned function
+// restores the original values.
+func Apply(m *Mod) (restore func(), err error) {
+	changes, err := m.resolve()
+	if err != nil {
+		return nil, err
+	}
+	originals := make([]int, len(changes))
+	for i, c := range changes {
+		originals[i] = int(c.field.Int())
+		c.field.SetInt(int64(c.value))
+	}
+	return func() {
+		for i := len(changes) - 1; i >= 0; i-- {
+			changes[i].field.SetInt(int64(originals[i]))
+		}
+	}, nil
+}
@@ -0,0 +1,75 @@
+package balancemod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/data"
+)
+
+const cheaperLasers = `
+name: Cheaper lasers
+items:
+  laser:
+    cost: {resources: 4, boranium: 5}
+    tech: {weapons: 1}
+    stats: {power: 12}
+  Tritanium:
+    stats: {armor_value: 60, mass: 50}
+  Orbital Fort:
+    cost: {resources: 60}
+`
+
+func TestParse(t *testing.T) {
+	m, err := Parse([]byte(cheaperLasers))
+	require.NoError(t, err)
+	assert.Equal(t, "Cheaper lasers", m.Name)
+	assert.Len(t, m.Items, 3)
+
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"unknown item", "items: {Death Ray: {stats: {power: 1}}}", "unknown component"},
+		{"unknown stat", "items: {Laser: {stats: {armor_value: 1}}}", "no armor_value"},
+		{"unknown cost", "items: {Laser: {cost: {gold: 1}}}", "no gold"},
+		{"no mass", "items: {SDI: {stats: {mass: 1}}}", "no mass"},
+		{"negative", "items: {Laser: {stats: {power: -1}}}", "must not be negative"},
+		{"unknown key", "items: {Laser: {damage: 1}}", "field damage not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	m, err := Parse([]byte(cheaperLasers))
+	require.NoError(t, err)
+
+	laser := *data.GetBeamWeapon(data.BeamLaser)
+	tritanium := *data.GetArmor(data.ArmorTritanium)
+	fort := *data.GetHull(data.HullOrbitalFort)
+
+	restore, err := Apply(m)
+	require.NoError(t, err)
+
+	modded := data.GetBeamWeapon(data.BeamLaser)
+	assert.Equal(t, 12, modded.Power)
+	assert.Equal(t, data.Cost{Resources: 4, Ironium: laser.Cost.Ironium, Boranium: 5, Germanium: laser.Cost.Germanium}, modded.Cost)
+	assert.Equal(t, 1, modded.Tech.Weapons)
+	assert.Equal(t, laser.Range, modded.Range, "values left out are kept")
+	assert.Equal(t, 60, data.GetArmor(data.ArmorTritanium).ArmorValue)
+	assert.Equal(t, 50, data.GetArmor(data.ArmorTritanium).Mass)
+	assert.Equal(t, 60, data.GetHull(data.HullOrbitalFort).Cost.Resources)
+
+	restore()
+	assert.Equal(t, laser, *data.GetBeamWeapon(data.BeamLaser))
+	assert.Equal(t, tritanium, *data.GetArmor(data.ArmorTritanium))
+	assert.Equal(t, fort.Cost, data.GetHull(data.HullOrbitalFort).Cost)
+}
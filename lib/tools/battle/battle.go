@@ -0,0 +1,769 @@
+// Package battle simulates Stars! battles between fleets from their ship
+// designs and battle plans.
+//
+// Each design of a fleet fights as a stack on the 10x10 battle board for at
+// most 16 rounds. In a round the stacks move, heaviest first, as their
+// tactics ask, then every weapon slot fires in initiative order (hull,
+// battle computers and weapon), ties broken at random. Beams lose 10% of
+// their power at their longest range, are boosted by capacitors and weakened
+// by deflectors, and gatling beams hit every enemy stack in range. Torpedoes
+// hit with their accuracy raised by battle computers and lowered by the
+// target's jammers; a hit deals half its damage to shields and the rest to
+// armor, a miss an eighth to shields.
+//
+// The simulator follows the rules as documented for players, not the
+// game's code: damage kills whole ships rather than spreading over the
+// stack, every other player counts as an enemy, and disengaging stacks leave
+// the board after moving 7 squares. Simulate plays one battle; Expect plays
+// many to give the odds of each side.
+//
+//	result, err := battle.Simulate([]battle.Fleet{
+//		{Player: 0, Ships: []battle.Ships{{Design: destroyer, Count: 8}}, Plan: plan},
+//		{Player: 1, Ships: []battle.Ships{{Design: frigate, Count: 12}}},
+//	}, rand.New(rand.NewPCG(1, 2)))
+//	...
+//	for _, event := range result.Events {
+//		fmt.Println(result.Describe(event))
+//	}
+package battle
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+)
+
+const (
+	BoardSize = 10 // Squares on each side of the battle board
+	Rounds    = 16 // Rounds of a battle
+
+	// escapeDistance is how far a disengaging stack moves before leaving
+	// the board.
+	escapeDistance = 7
+)
+
+var (
+	ErrNoBattle   = errors.New("a battle needs fleets of at least two players")
+	ErrNoSlots    = errors.New("design has no components")
+	ErrNoShips    = errors.New("fleet has no ships")
+	ErrBadPlayers = errors.New("player out of range")
+)
+
+// DefaultPlan is the plan of fleets without one: the Default plan of
+// Stars!.
+var DefaultPlan = blocks.BattlePlanBlock{
+	Name:            "Default",
+	Tactic:          blocks.TacticMaximizeDamageRatio,
+	PrimaryTarget:   blocks.TargetArmedShips,
+	SecondaryTarget: blocks.TargetAny,
+	AttackWho:       blocks.AttackEnemies,
+}
+
+// Ships are ships of one design.
+type Ships struct {
+	Design *blocks.DesignBlock // Full design, with its components
+	Count  int
+}
+
+// Fleet is a fleet, or a starbase, taking part in a battle.
+type Fleet struct {
+	Player int    // Player index (0-15)
+	Name   string // Name in the logs (default: the design names)
+	Ships  []Ships
+	Plan   *blocks.BattlePlanBlock // Battle plan (default: DefaultPlan)
+}
+
+// EventKind is the kind of a battle event.
+type EventKind int
+
+const (
+	Moved EventKind = iota
+	Fired
+	Escaped
+)
+
+// Event is something a stack did in a battle.
+type Event struct {
+	Round        int // From 1
+	Stack        int
+	Kind         EventKind
+	X, Y         int    // Position of the stack after moving
+	Target       int    // Stack fired at
+	Weapon       string // Weapon fired
+	ShieldDamage int
+	ArmorDamage  int
+	Killed       int // Ships of the target destroyed
+}
+
+// Stack is a stack of a battle and how it fared.
+type Stack struct {
+	Player    int
+	Fleet     string
+	Design    string
+	Starbase  bool
+	Ships     int // Ships at the start of the battle
+	Remaining int // Ships left at the end
+	Escaped   bool
+}
+
+// Result is a battle.
+type Result struct {
+	Stacks []Stack
+	Events []Event
+	Rounds int // Rounds fought
+	Winner int // Only player left on the board, -1 if none or several
+}
+
+// ShipsLost returns the ships a player lost.
+func (r *Result) ShipsLost(player int) int {
+	lost := 0
+	for _, s := range r.Stacks {
+		if s.Player == player {
+			lost += s.Ships - s.Remaining
+		}
+	}
+	return lost
+}
+
+// Players returns the players of the battle in order.
+func (r *Result) Players() []int {
+	var players []int
+	for _, s := range r.Stacks {
+		if !slices.Contains(players, s.Player) {
+			players = append(players, s.Player)
+		}
+	}
+	slices.Sort(players)
+	return players
+}
+
+// Describe returns an event as a log line, with stacks named after their
+// fleet and design.
+func (r *Result) Describe(e Event) string {
+	name := func(i int) string {
+		s := r.Stacks[i]
+		if s.Fleet == s.Design {
+			return fmt.Sprintf("%s (player %d)", s.Fleet, s.Player+1)
+		}
+		return fmt.Sprintf("%s (player %d, %s)", s.Fleet, s.Player+1, s.Design)
+	}
+	switch e.Kind {
+	case Moved:
+		return fmt.Sprintf("round %2d: %s moves to (%d,%d)", e.Round, name(e.Stack), e.X, e.Y)
+	case Escaped:
+		return fmt.Sprintf("round %2d: %s leaves the battle", e.Round, name(e.Stack))
+	}
+	text := fmt.Sprintf("round %2d: %s fires %s at %s: %d shield, %d armor damage",
+		e.Round, name(e.Stack), e.Weapon, name(e.Target), e.ShieldDamage, e.ArmorDamage)
+	if e.Killed > 0 {
+		text += fmt.Sprintf(", %d destroyed", e.Killed)
+	}
+	return text
+}
+
+// Simulate plays a battle.
+func Simulate(fleets []Fleet, r *rand.Rand) (*Result, error) {
+	b, err := newBoard(fleets, r)
+	if err != nil {
+		return nil, err
+	}
+	b.fight()
+	return b.result(), nil
+}
+
+// weapon is a weapon slot of a stack.
+type weapon struct {
+	name       string
+	beam       bool
+	power      int
+	rng        int
+	initiative int
+	accuracy   int // Torpedo accuracy, percent
+	count      int // Weapons per ship
+	gatling    bool
+	sapper     bool
+	capital    bool
+}
+
+// stack is a stack on the board.
+type stack struct {
+	Stack
+	index      int
+	plan       blocks.BattlePlanBlock
+	count      int
+	armor      int // Armor of a ship
+	damage     int // Armor damage of the damaged ship
+	shields    int // Shields left, of the whole stack
+	maxShields int // Shields of a ship
+	x, y       int
+	speed      int // Quarter squares a round
+	moved      int
+	initiative int // Hull and computers
+	weapons    []weapon
+	computer   float64
+	jammer     float64
+	capacitor  float64
+	deflector  float64
+	cost       int
+	bombs      bool
+	cargo      bool
+	fuelHull   bool
+	mass       int
+	gone       bool
+	firedAt    bool
+}
+
+func (s *stack) alive() bool {
+	return s.count > 0 && !s.gone
+}
+
+func (s *stack) armed() bool {
+	return len(s.weapons) > 0
+}
+
+// newStack builds the stack of ships of a fleet.
+func newStack(fleet Fleet, ships Ships) (*stack, error) {
+	design := ships.Design
+	if design == nil || !design.IsFullDesign {
+		return nil, ErrNoSlots
+	}
+	hull := data.GetHull(design.HullId)
+	if hull == nil {
+		return nil, fmt.Errorf("design %q: unknown hull %d", design.Name, design.HullId)
+	}
+	s := &stack{
+		Stack: Stack{
+			Player:    fleet.Player,
+			Fleet:     fleet.Name,
+			Design:    design.Name,
+			Starbase:  design.IsStarbase,
+			Ships:     ships.Count,
+			Remaining: ships.Count,
+		},
+		plan:       DefaultPlan,
+		count:      ships.Count,
+		armor:      hull.Armor,
+		initiative: hull.Initiative,
+		cargo:      hull.CargoCapacity > 0,
+		fuelHull:   design.HullId == data.HullFuelTransport || design.HullId == data.HullSuperFuelXport,
+		mass:       design.Mass,
+	}
+	if fleet.Plan != nil {
+		s.plan = *fleet.Plan
+	}
+	if s.Fleet == "" {
+		s.Fleet = design.Name
+	}
+
+	// Computers, jammers, capacitors and deflectors stack multiplicatively
+	missed, unjammed, capacitors, undeflected := 1.0, 1.0, 1.0, 1.0
+	engines, idealSpeed, bonus := 0, 0, 0
+	for _, slot := range design.Slots {
+		if slot.Count == 0 {
+			continue
+		}
+		id, n := slot.ItemId+1, slot.Count
+		switch slot.Category {
+		case blocks.ItemCategoryEngine:
+			if e := data.GetEngine(id); e != nil {
+				engines += n
+				idealSpeed = e.SafeSpeed
+				bonus = max(bonus, e.BattleSpeed)
+				s.cost += costOf(e.Cost, n)
+			}
+		case blocks.ItemCategoryArmor:
+			if a := data.GetArmor(id); a != nil {
+				s.armor += a.ArmorValue * n
+				s.cost += costOf(a.Cost, n)
+			}
+		case blocks.ItemCategoryShield:
+			if sh := data.GetShield(id); sh != nil {
+				s.maxShields += sh.ShieldValue * n
+				s.armor += sh.ArmorValue * n
+				s.cost += costOf(sh.Cost, n)
+			}
+		case blocks.ItemCategoryBeamWeapon:
+			if w := data.GetBeamWeapon(id); w != nil {
+				s.weapons = append(s.weapons, weapon{name: w.Name, beam: true, power: w.Power, rng: w.Range,
+					initiative: w.Initiative, count: n, gatling: w.IsGatling, sapper: w.IsSapper})
+				s.cost += costOf(w.Cost, n)
+			}
+		case blocks.ItemCategoryTorpedo:
+			if w := data.GetTorpedo(id); w != nil {
+				s.weapons = append(s.weapons, weapon{name: w.Name, power: w.Power, rng: w.Range,
+					initiative: w.Initiative, accuracy: w.Accuracy, count: n, capital: w.IsCapital})
+				s.cost += costOf(w.Cost, n)
+			}
+		case blocks.ItemCategoryElectrical:
+			if e := data.GetElectrical(id); e != nil {
+				s.initiative += e.InitiativeBonus * n
+				missed *= math.Pow(1-float64(e.TorpedoAccuracy)/100, float64(n))
+				unjammed *= math.Pow(1-float64(e.BeamDeflection)/100, float64(n))
+				capacitors *= math.Pow(1+float64(e.CapacitorBonus)/100, float64(n))
+				s.cost += costOf(e.Cost, n)
+			}
+		case blocks.ItemCategoryMechanical:
+			if m := data.GetMechanical(id); m != nil {
+				bonus += m.SpeedBonus * n
+				undeflected *= math.Pow(1-float64(m.BeamDeflect)/100, float64(n))
+				s.armor += m.ArmorValue * n
+				s.cost += costOf(m.Cost, n)
+			}
+		case blocks.ItemCategoryBomb:
+			s.bombs = true
+		}
+	}
+	s.computer = 1 - missed
+	s.jammer = 1 - unjammed
+	s.capacitor = min(capacitors, 2.55) - 1
+	s.deflector = 1 - undeflected
+	s.shields = s.maxShields * s.count
+
+	// Battle speed in quarter squares: (ideal warp - 4) / 4 squares, less a
+	// quarter per 70 kT of mass per engine, plus jets and overthrusters,
+	// between 1/2 and 2 1/2.
+	if engines > 0 && !s.Starbase {
+		speed := idealSpeed - 4 - s.mass/(70*engines) + bonus
+		s.speed = min(max(speed, 2), 10)
+	}
+	return s, nil
+}
+
+func costOf(cost data.Cost, count int) int {
+	return (cost.Resources + cost.Boranium) * count
+}
+
+// board is a battle being fought.
+type board struct {
+	stacks  []*stack
+	rand    *rand.Rand
+	round   int
+	events  []Event
+	players []int
+}
+
+// startSquares are the starting squares of the players, in order.
+var startSquares = [][2]int{{1, 4}, {8, 5}, {4, 1}, {5, 8}, {1, 1}, {8, 8}, {1, 8}, {8, 1}}
+
+func newBoard(fleets []Fleet, r *rand.Rand) (*board, error) {
+	b := &board{rand: r}
+	for i, fleet := range fleets {
+		if fleet.Player < 0 || fleet.Player > 15 {
+			return nil, fmt.Errorf("fleet %d: %w: %d", i+1, ErrBadPlayers, fleet.Player)
+		}
+		if len(fleet.Ships) == 0 {
+			return nil, fmt.Errorf("fleet %d: %w", i+1, ErrNoShips)
+		}
+		for _, ships := range fleet.Ships {
+			if ships.Count <= 0 {
+				continue
+			}
+			s, err := newStack(fleet, ships)
+			if err != nil {
+				return nil, fmt.Errorf("fleet %d: %w", i+1, err)
+			}
+			s.index = len(b.stacks)
+			b.stacks = append(b.stacks, s)
+			if !slices.Contains(b.players, s.Player) {
+				b.players = append(b.players, s.Player)
+			}
+		}
+	}
+	if len(b.players) < 2 {
+		return nil, ErrNoBattle
+	}
+	for _, s := range b.stacks {
+		square := startSquares[slices.Index(b.players, s.Player)%len(startSquares)]
+		s.x, s.y = square[0], square[1]
+	}
+	return b, nil
+}
+
+// hostile reports whether a stack attacks another.
+func hostile(s, other *stack) bool {
+	if s.Player == other.Player {
+		return false
+	}
+	switch who := s.plan.AttackWho; {
+	case who == blocks.AttackNobody:
+		return false
+	case who >= blocks.AttackPlayerBase:
+		return other.Player == who-blocks.AttackPlayerBase
+	}
+	return true
+}
+
+func distance(a, b *stack) int {
+	return max(abs(a.x-b.x), abs(a.y-b.y))
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
+
+// fight plays the rounds of the battle.
+func (b *board) fight() {
+	for b.round = 1; b.round <= Rounds; b.round++ {
+		if !b.engaged() {
+			b.round--
+			return
+		}
+		b.move()
+		b.fire()
+	}
+	b.round = Rounds
+}
+
+// engaged reports whether an armed stack still has something to attack.
+func (b *board) engaged() bool {
+	for _, s := range b.stacks {
+		if !s.alive() || !s.armed() {
+			continue
+		}
+		for _, other := range b.stacks {
+			if other.alive() && hostile(s, other) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matches reports whether a stack is of a target type of battle plans.
+func matches(s *stack, target int) bool {
+	switch target {
+	case blocks.TargetAny:
+		return true
+	case blocks.TargetStarbase:
+		return s.Starbase
+	case blocks.TargetArmedShips:
+		return !s.Starbase && s.armed()
+	case blocks.TargetBombers:
+		return !s.Starbase && (s.bombs || s.cargo)
+	case blocks.TargetUnarmedShips:
+		return !s.Starbase && !s.armed()
+	case blocks.TargetFuelTransports:
+		return s.fuelHull
+	case blocks.TargetFreighters:
+		return s.cargo
+	}
+	return false
+}
+
+// attractiveness returns how much a stack is worth shooting: the cost of
+// its ships for the damage needed to destroy them.
+func attractiveness(s *stack) float64 {
+	return float64(s.cost+1) / float64(s.armor+s.maxShields+1)
+}
+
+// targets returns the stacks a stack attacks within a range, most
+// attractive first, primary targets before secondary ones. A range below 0
+// takes the whole board.
+func (b *board) targets(s *stack, within int) []*stack {
+	var primary, secondary []*stack
+	for _, other := range b.stacks {
+		if !other.alive() || !hostile(s, other) || (within >= 0 && distance(s, other) > within) {
+			continue
+		}
+		switch {
+		case matches(other, s.plan.PrimaryTarget):
+			primary = append(primary, other)
+		case matches(other, s.plan.SecondaryTarget):
+			secondary = append(secondary, other)
+		}
+	}
+	byValue := func(a, c *stack) int {
+		if d := attractiveness(c) - attractiveness(a); d != 0 {
+			return int(math.Copysign(1, d))
+		}
+		return distance(s, a) - distance(s, c)
+	}
+	slices.SortStableFunc(primary, byValue)
+	slices.SortStableFunc(secondary, byValue)
+	return append(primary, secondary...)
+}
+
+// move moves the stacks, heaviest first.
+func (b *board) move() {
+	order := slices.Clone(b.stacks)
+	b.rand.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	slices.SortStableFunc(order, func(a, c *stack) int { return c.mass - a.mass })
+
+	for _, s := range order {
+		if !s.alive() || s.speed == 0 {
+			continue
+		}
+		// Squares this round, so that quarter speeds add up over rounds
+		squares := b.round*s.speed/4 - (b.round-1)*s.speed/4
+		moved := false
+		for range squares {
+			if !b.step(s) {
+				break
+			}
+			moved = true
+			if s.gone {
+				break
+			}
+		}
+		if moved && !s.gone {
+			b.events = append(b.events, Event{Round: b.round, Stack: s.index, Kind: Moved, X: s.x, Y: s.y})
+		}
+	}
+}
+
+// step moves a stack one square as its tactic asks, and reports whether it
+// moved.
+func (b *board) step(s *stack) bool {
+	flee := !s.armed() || s.plan.Tactic == blocks.TacticDisengage ||
+		(s.plan.Tactic == blocks.TacticDisengageIfChallenged && s.firedAt)
+
+	var enemy *stack
+	if flee {
+		// Away from the closest stack attacking it
+		for _, other := range b.stacks {
+			if other.alive() && other.armed() && hostile(other, s) && (enemy == nil || distance(s, other) < distance(s, enemy)) {
+				enemy = other
+			}
+		}
+	} else if targets := b.targets(s, -1); len(targets) > 0 {
+		enemy = targets[0]
+	}
+	if enemy == nil {
+		return false
+	}
+
+	direction := 1
+	if flee {
+		direction = -1
+	} else {
+		reach := s.weapons[0].rng
+		for _, w := range s.weapons {
+			if s.plan.Tactic == blocks.TacticMinimizeDamage {
+				reach = max(reach, w.rng)
+			} else {
+				reach = min(reach, w.rng)
+			}
+		}
+		d := distance(s, enemy)
+		switch {
+		case d > reach:
+		case d < reach && s.plan.Tactic == blocks.TacticMinimizeDamage:
+			direction = -1
+		default:
+			return false
+		}
+	}
+
+	x := min(max(s.x+direction*sign(enemy.x-s.x), 0), BoardSize-1)
+	y := min(max(s.y+direction*sign(enemy.y-s.y), 0), BoardSize-1)
+	if flee && enemy.x == s.x && enemy.y == s.y {
+		// Sharing the square: run for the nearest edge
+		x = min(max(s.x+sign(s.x*2-BoardSize+1), 0), BoardSize-1)
+	}
+	if x == s.x && y == s.y && !flee {
+		return false
+	}
+	// Fleeing stacks cornered at an edge slip along it
+	s.x, s.y = x, y
+	s.moved++
+	if flee && s.moved >= escapeDistance {
+		b.escape(s)
+	}
+	return true
+}
+
+func (b *board) escape(s *stack) {
+	s.gone = true
+	s.Escaped = true
+	b.events = append(b.events, Event{Round: b.round, Stack: s.index, Kind: Escaped, X: s.x, Y: s.y})
+}
+
+// shot is a weapon slot firing in a round.
+type shot struct {
+	stack      *stack
+	weapon     weapon
+	initiative int
+}
+
+// fire fires the weapons of the stacks in initiative order.
+func (b *board) fire() {
+	var shots []shot
+	for _, s := range b.stacks {
+		if !s.alive() {
+			continue
+		}
+		for _, w := range s.weapons {
+			shots = append(shots, shot{stack: s, weapon: w, initiative: s.initiative + w.initiative})
+		}
+	}
+	b.rand.Shuffle(len(shots), func(i, j int) { shots[i], shots[j] = shots[j], shots[i] })
+	slices.SortStableFunc(shots, func(a, c shot) int { return c.initiative - a.initiative })
+
+	for _, sh := range shots {
+		if !sh.stack.alive() {
+			continue
+		}
+		if sh.weapon.beam {
+			b.fireBeam(sh.stack, sh.weapon)
+		} else {
+			b.fireTorpedoes(sh.stack, sh.weapon)
+		}
+	}
+}
+
+// fireBeam fires a beam slot. The damage left after destroying a stack goes
+// to the next target in range; gatling beams hit every target in range.
+func (b *board) fireBeam(s *stack, w weapon) {
+	targets := b.targets(s, w.rng)
+	if len(targets) == 0 {
+		return
+	}
+	power := float64(w.power*w.count*s.count) * (1 + s.capacitor)
+	if !w.gatling {
+		targets = targets[:1]
+	}
+	left := -1.0
+	for len(targets) > 0 {
+		target := targets[0]
+		targets = targets[1:]
+		damage := power
+		if left >= 0 {
+			damage = left
+		}
+		if w.rng > 0 {
+			damage *= 1 - 0.1*float64(distance(s, target))/float64(w.rng)
+		}
+		damage *= 1 - target.deflector
+
+		shield := min(int(damage), target.shields)
+		armor := 0
+		if !w.sapper {
+			armor = int(damage) - shield
+		}
+		killed, spent := b.hit(target, shield, armor)
+		b.fired(s, target, w, shield, spent, killed)
+
+		if !w.gatling && !w.sapper && armor > spent {
+			// Overkill carries over
+			left = float64(armor-spent) / (1 - target.deflector)
+			if next := b.targets(s, w.rng); len(next) > 0 {
+				targets = next[:1]
+			}
+		}
+	}
+}
+
+// fireTorpedoes fires a torpedo slot, one torpedo at a time.
+func (b *board) fireTorpedoes(s *stack, w weapon) {
+	var target *stack
+	shield, armor, killed := 0, 0, 0
+	for range w.count * s.count {
+		if target == nil || !target.alive() {
+			if target != nil {
+				b.fired(s, target, w, shield, armor, killed)
+				shield, armor, killed = 0, 0, 0
+			}
+			targets := b.targets(s, w.rng)
+			if len(targets) == 0 {
+				return
+			}
+			target = targets[0]
+		}
+
+		sh, ar := 0, 0
+		if b.rand.Float64()*100 < accuracy(w, s, target) {
+			sh = min(w.power/2, target.shields)
+			ar = w.power - sh
+			if w.capital && target.shields == 0 {
+				ar *= 2
+			}
+		} else {
+			sh = min(w.power/8, target.shields)
+		}
+		k, spent := b.hit(target, sh, ar)
+		shield += sh
+		armor += spent
+		killed += k
+	}
+	if target != nil {
+		b.fired(s, target, w, shield, armor, killed)
+	}
+}
+
+// accuracy returns the chance in percent of a torpedo hitting a target. The
+// battle computers of the shooter and the jammers of the target cancel each
+// other out.
+func accuracy(w weapon, s, target *stack) float64 {
+	net := s.computer - target.jammer
+	base := float64(w.accuracy)
+	if net >= 0 {
+		return base + (100-base)*net
+	}
+	return base * (1 + net)
+}
+
+// hit deals damage to a stack and returns the ships destroyed and the armor
+// damage absorbed.
+func (b *board) hit(target *stack, shield, armor int) (killed, spent int) {
+	target.firedAt = true
+	target.shields -= shield
+	if armor <= 0 || target.armor <= 0 {
+		return 0, 0
+	}
+	total := target.damage + armor
+	killed = min(total/target.armor, target.count)
+	spent = armor
+	target.count -= killed
+	if target.count == 0 {
+		spent = killed*target.armor - target.damage
+		target.damage = 0
+	} else {
+		target.damage = total % target.armor
+	}
+	target.shields = min(target.shields, target.maxShields*target.count)
+	return killed, spent
+}
+
+func (b *board) fired(s, target *stack, w weapon, shield, armor, killed int) {
+	b.events = append(b.events, Event{
+		Round: b.round, Stack: s.index, Kind: Fired, X: s.x, Y: s.y,
+		Target: target.index, Weapon: w.name,
+		ShieldDamage: shield, ArmorDamage: armor, Killed: killed,
+	})
+}
+
+func (b *board) result() *Result {
+	r := &Result{Events: b.events, Rounds: b.round, Winner: -1}
+	standing := make(map[int]bool)
+	for _, s := range b.stacks {
+		s.Remaining = s.count
+		r.Stacks = append(r.Stacks, s.Stack)
+		if s.alive() {
+			standing[s.Player] = true
+		}
+	}
+	if len(standing) == 1 {
+		for player := range standing {
+			r.Winner = player
+		}
+	}
+	return r
+}
@@ -0,0 +1,153 @@
+package battle
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/lib/tools/montecarlo"
+)
+
+func slot(category uint16, item, count int) blocks.DesignSlot {
+	return blocks.DesignSlot{Category: category, ItemId: item - 1, Count: count}
+}
+
+// warship is a Destroyer with two slots of beams and armor.
+func warship(beam int) *blocks.DesignBlock {
+	return &blocks.DesignBlock{
+		IsFullDesign: true, HullId: data.HullDestroyer, Name: "Warship", Mass: 120,
+		Slots: []blocks.DesignSlot{
+			slot(blocks.ItemCategoryEngine, data.EngineTransGalacticDrive, 1),
+			slot(blocks.ItemCategoryBeamWeapon, beam, 1),
+			slot(blocks.ItemCategoryBeamWeapon, beam, 1),
+			slot(blocks.ItemCategoryArmor, data.ArmorTritanium, 2),
+		},
+	}
+}
+
+func scout() *blocks.DesignBlock {
+	return &blocks.DesignBlock{
+		IsFullDesign: true, HullId: data.HullScout, Name: "Scout", Mass: 12,
+		Slots: []blocks.DesignSlot{slot(blocks.ItemCategoryEngine, data.EngineLongHump6, 1)},
+	}
+}
+
+func newRand() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
+func TestSimulate_ArmedBeatsUnarmed(t *testing.T) {
+	plan := DefaultPlan
+	plan.Tactic = blocks.TacticMaximizeDamage
+	result, err := Simulate([]Fleet{
+		{Player: 0, Ships: []Ships{{Design: warship(data.BeamLaser), Count: 5}}, Plan: &plan},
+		{Player: 2, Ships: []Ships{{Design: warship(data.BeamLaser), Count: 1}, {Design: scout(), Count: 3}}},
+	}, newRand())
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, result.Winner)
+	assert.Equal(t, []int{0, 2}, result.Players())
+	assert.Zero(t, result.ShipsLost(0))
+	for _, s := range result.Stacks[1:] {
+		assert.True(t, s.Remaining == 0 || s.Escaped, "%s destroyed or fled", s.Design)
+	}
+	assert.Zero(t, result.Stacks[1].Remaining, "armed ships fight")
+	require.NotEmpty(t, result.Events)
+	assert.Contains(t, result.Describe(result.Events[0]), "Warship")
+}
+
+func TestSimulate_UnarmedStacksFlee(t *testing.T) {
+	result, err := Simulate([]Fleet{
+		{Player: 0, Ships: []Ships{{Design: scout(), Count: 2}}},
+		{Player: 1, Ships: []Ships{{Design: scout(), Count: 2}}},
+	}, newRand())
+	require.NoError(t, err)
+	assert.Equal(t, -1, result.Winner)
+	assert.Zero(t, result.Rounds, "nobody can fire, so nothing happens")
+}
+
+func TestSimulate_Errors(t *testing.T) {
+	_, err := Simulate([]Fleet{{Player: 0, Ships: []Ships{{Design: scout(), Count: 1}}}}, newRand())
+	assert.ErrorIs(t, err, ErrNoBattle)
+
+	_, err = Simulate([]Fleet{
+		{Player: 0, Ships: []Ships{{Design: &blocks.DesignBlock{Name: "Seen"}, Count: 1}}},
+		{Player: 1, Ships: []Ships{{Design: scout(), Count: 1}}},
+	}, newRand())
+	assert.ErrorIs(t, err, ErrNoSlots)
+
+	_, err = Simulate([]Fleet{{Player: 0}, {Player: 1}}, newRand())
+	assert.ErrorIs(t, err, ErrNoShips)
+}
+
+func TestNewStack(t *testing.T) {
+	design := warship(data.BeamLaser)
+	design.Slots = append(design.Slots,
+		slot(blocks.ItemCategoryElectrical, data.ElecBattleComputer, 1),
+		slot(blocks.ItemCategoryElectrical, data.ElecEnergyCapacitor, 2),
+		slot(blocks.ItemCategoryMechanical, data.MechManeuveringJet, 1))
+	s, err := newStack(Fleet{Player: 1}, Ships{Design: design, Count: 4})
+	require.NoError(t, err)
+
+	assert.Equal(t, 200+2*50, s.armor)
+	assert.Equal(t, 3+1, s.initiative, "hull and computer")
+	assert.InDelta(t, 0.2, s.computer, 1e-9)
+	assert.InDelta(t, 0.21, s.capacitor, 1e-9)
+	// Trans-Galactic Drive: 5 quarters, less 1 for 120 kT on one engine, plus the jet
+	assert.Equal(t, 5, s.speed)
+	assert.Len(t, s.weapons, 2)
+	assert.Equal(t, "Warship", s.Fleet)
+}
+
+func TestAccuracy(t *testing.T) {
+	torpedo := weapon{accuracy: 35}
+	plain := &stack{}
+	assert.InDelta(t, 35, accuracy(torpedo, plain, plain), 1e-9)
+	assert.InDelta(t, 48, accuracy(torpedo, &stack{computer: 0.2}, plain), 1e-9)
+	assert.InDelta(t, 31.5, accuracy(torpedo, plain, &stack{jammer: 0.1}), 1e-9)
+}
+
+func TestHit(t *testing.T) {
+	b := &board{}
+	target := &stack{count: 3, armor: 100, maxShields: 50, shields: 150}
+
+	killed, spent := b.hit(target, 40, 150)
+	assert.Equal(t, 1, killed)
+	assert.Equal(t, 150, spent)
+	assert.Equal(t, 2, target.count)
+	assert.Equal(t, 50, target.damage)
+	assert.Equal(t, 100, target.shields, "shields of the dead ship go with it")
+
+	killed, spent = b.hit(target, 0, 500)
+	assert.Equal(t, 2, killed)
+	assert.Equal(t, 150, spent, "overkill is not absorbed")
+	assert.False(t, target.alive())
+}
+
+func TestExpect(t *testing.T) {
+	fleets := []Fleet{
+		{Player: 0, Ships: []Ships{{Design: warship(data.BeamXRayLaser), Count: 3}}},
+		{Player: 1, Ships: []Ships{{Design: warship(data.BeamLaser), Count: 3}}},
+	}
+	config := montecarlo.Config{Runs: 50, Seed: 7}
+	report, err := Expect(context.Background(), fleets, config)
+	require.NoError(t, err)
+
+	stronger, weaker := report.Metric(WonMetric(0)), report.Metric(WonMetric(1))
+	require.NotNil(t, stronger)
+	require.NotNil(t, weaker)
+	assert.Greater(t, stronger.Mean, weaker.Mean)
+	assert.NotNil(t, report.Metric(LostMetric(1)))
+
+	again, err := Expect(context.Background(), fleets, config)
+	require.NoError(t, err)
+	assert.Equal(t, stronger.Mean, again.Metric(WonMetric(0)).Mean)
+
+	_, err = Expect(context.Background(), fleets[:1], config)
+	assert.ErrorIs(t, err, ErrNoBattle)
+}
@@ -0,0 +1,45 @@
+package battle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neper-stars/houston/lib/tools/montecarlo"
+)
+
+// WonMetric is the name of the measurement of Expect telling whether a
+// player won, 1 or 0, so that its mean is the player's chance of winning.
+func WonMetric(player int) string {
+	return fmt.Sprintf("player %d won", player+1)
+}
+
+// LostMetric is the name of the measurement of Expect counting the ships a
+// player lost.
+func LostMetric(player int) string {
+	return fmt.Sprintf("player %d ships lost", player+1)
+}
+
+// Expect plays a battle many times and sums up the outcomes: for each
+// player, WonMetric and LostMetric, and "rounds", the length of the battle.
+func Expect(ctx context.Context, fleets []Fleet, config montecarlo.Config) (*montecarlo.Report, error) {
+	// Check the fleets once rather than in every trial
+	if _, err := newBoard(fleets, nil); err != nil {
+		return nil, err
+	}
+	return montecarlo.Run(ctx, func(t *montecarlo.Trial) (montecarlo.Sample, error) {
+		result, err := Simulate(fleets, t.Rand)
+		if err != nil {
+			return nil, err
+		}
+		sample := montecarlo.Sample{"rounds": float64(result.Rounds)}
+		for _, player := range result.Players() {
+			won := 0.0
+			if result.Winner == player {
+				won = 1
+			}
+			sample[WonMetric(player)] = won
+			sample[LostMetric(player)] = float64(result.ShipsLost(player))
+		}
+		return sample, nil
+	}, config)
+}
@@ -0,0 +1,187 @@
+// Package compat tells whether a Stars! file is one of the known
+// problematic files reported by players, or shows one of their quirks, and
+// which houston command works around it.
+//
+// Quirks are the ways real-world files depart from what the parser expects:
+// a race file whose checksum Stars! rejects, a turn file named after the
+// wrong player, a damaged header, a truncated download. Each quirk has a
+// detector probing the file and a workaround command.
+//
+// Files sent with bug reports are registered by SHA-256 in a YAML corpus,
+// with their quirks and the flags that make houston read them. The corpus
+// shipped with houston is in corpus.yaml; more can be loaded with
+// Registry.Load:
+//
+//	# corpus.yaml
+//	- sha256: d3be9c40...
+//	  file: game.r1
+//	  report: password-protected race file Stars! reports as corrupted
+//	  quirks:
+//	    - id: race-checksum
+//
+// Example usage:
+//
+//	data, _ := os.ReadFile("game.m1")
+//	report := compat.Default().Check("game.m1", data)
+//	for _, f := range report.Findings {
+//	    fmt.Println(f.Quirk.Description, f.Workaround)
+//	}
+package compat
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Errors returned when registering profiles.
+var (
+	ErrInvalidHash  = errors.New("invalid SHA-256")
+	ErrUnknownQuirk = errors.New("unknown quirk")
+	ErrDuplicate    = errors.New("file already registered")
+)
+
+//go:embed corpus.yaml
+var corpus []byte
+
+// KnownQuirk is a quirk of a registered file, with the flags specific to
+// the file that the workaround needs (e.g. the salt of a damaged header).
+type KnownQuirk struct {
+	ID    QuirkID  `yaml:"id"`
+	Flags []string `yaml:"flags,omitempty"`
+}
+
+// Profile is a registered file.
+type Profile struct {
+	SHA256 string       `yaml:"sha256"`
+	File   string       `yaml:"file"`   // Name of the file as reported
+	Report string       `yaml:"report"` // What was reported, or where
+	Quirks []KnownQuirk `yaml:"quirks"`
+}
+
+// Registry holds the registered files by hash.
+type Registry struct {
+	profiles map[string]Profile
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{profiles: make(map[string]Profile)}
+}
+
+// Default returns a registry holding the corpus shipped with houston.
+func Default() *Registry {
+	r := NewRegistry()
+	if err := r.Parse(corpus); err != nil {
+		panic(fmt.Sprintf("compat: invalid built-in corpus: %v", err))
+	}
+	return r
+}
+
+// Register adds a file to the registry.
+func (r *Registry) Register(p Profile) error {
+	p.SHA256 = strings.ToLower(p.SHA256)
+	if b, err := hex.DecodeString(p.SHA256); err != nil || len(b) != sha256.Size {
+		return fmt.Errorf("%w %q", ErrInvalidHash, p.SHA256)
+	}
+	for _, q := range p.Quirks {
+		if _, ok := quirkByID(q.ID); !ok {
+			return fmt.Errorf("%w %q for %s", ErrUnknownQuirk, q.ID, p.File)
+		}
+	}
+	if _, ok := r.profiles[p.SHA256]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicate, p.SHA256)
+	}
+	r.profiles[p.SHA256] = p
+	return nil
+}
+
+// Parse registers the files of a YAML corpus: a list of profiles. Unknown
+// keys are rejected so that a typo does not lose a workaround.
+func (r *Registry) Parse(data []byte) error {
+	var profiles []Profile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&profiles); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse corpus: %w", err)
+	}
+	for _, p := range profiles {
+		if err := r.Register(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load registers the files of a YAML corpus file.
+func (r *Registry) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read corpus: %w", err)
+	}
+	return r.Parse(data)
+}
+
+// Lookup returns the registered file with the given content.
+func (r *Registry) Lookup(data []byte) (Profile, bool) {
+	p, ok := r.profiles[hashOf(data)]
+	return p, ok
+}
+
+// Finding is a quirk of a checked file.
+type Finding struct {
+	Quirk      Quirk
+	Registered bool   // The file is registered with this quirk
+	Detected   bool   // The detector found the quirk
+	Detail     string // What the detector found
+	Workaround string // Command line working around it, empty when none
+}
+
+// Report is the result of checking a file.
+type Report struct {
+	File     string
+	SHA256   string
+	Profile  *Profile // The registered file, nil when not registered
+	Findings []Finding
+}
+
+// Check looks a file up in the registry and runs the quirk detectors on
+// it. The name is used to tell the kind of file and in the workarounds.
+func (r *Registry) Check(name string, data []byte) *Report {
+	report := &Report{File: name, SHA256: hashOf(data)}
+	var known []KnownQuirk
+	if p, ok := r.profiles[report.SHA256]; ok {
+		report.Profile = &p
+		known = p.Quirks
+	}
+
+	for _, q := range quirks {
+		f := Finding{Quirk: q}
+		var flags []string
+		if i := slices.IndexFunc(known, func(k KnownQuirk) bool { return k.ID == q.ID }); i >= 0 {
+			f.Registered = true
+			flags = known[i].Flags
+		}
+		f.Detected, f.Detail = q.detect(name, data)
+		if !f.Registered && !f.Detected {
+			continue
+		}
+		f.Workaround = q.command(name, flags)
+		report.Findings = append(report.Findings, f)
+	}
+	return report
+}
+
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,99 @@
+package compat
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	brokenRace = "../../../testdata/scenario-racefixer/game.r1"
+	turnFile   = "../../../testdata/scenario-map/history/game-2440.m1"
+)
+
+func findingIDs(r *Report) []QuirkID {
+	var ids []QuirkID
+	for _, f := range r.Findings {
+		ids = append(ids, f.Quirk.ID)
+	}
+	return ids
+}
+
+func TestDefaultRegistersCorpus(t *testing.T) {
+	data, err := os.ReadFile(brokenRace)
+	require.NoError(t, err)
+
+	report := Default().Check("race.r1", data)
+	require.NotNil(t, report.Profile)
+	assert.Equal(t, "game.r1", report.Profile.File)
+	require.Equal(t, []QuirkID{QuirkRaceChecksum}, findingIDs(report))
+	f := report.Findings[0]
+	assert.True(t, f.Registered)
+	assert.True(t, f.Detected)
+	assert.Equal(t, "houston race race.r1", f.Workaround)
+}
+
+func TestCheckDetectsQuirks(t *testing.T) {
+	data, err := os.ReadFile(turnFile)
+	require.NoError(t, err)
+	r := NewRegistry()
+
+	report := r.Check("game.m1", data)
+	assert.Nil(t, report.Profile)
+	assert.Empty(t, report.Findings)
+
+	report = r.Check("my game.m2", data)
+	require.Equal(t, []QuirkID{QuirkMisnamed}, findingIDs(report))
+	assert.Equal(t, "houston fix-name 'my game.m2'", report.Findings[0].Workaround)
+
+	report = r.Check("game.m1", data[:len(data)-10])
+	assert.Equal(t, []QuirkID{QuirkTruncated}, findingIDs(report))
+	assert.Empty(t, report.Findings[0].Workaround)
+}
+
+func TestRegisteredFlags(t *testing.T) {
+	data, err := os.ReadFile(turnFile)
+	require.NoError(t, err)
+
+	r := NewRegistry()
+	require.NoError(t, r.Parse([]byte(`
+- sha256: `+hashOf(data)+`
+  file: game.m1
+  report: header damaged by a mail client
+  quirks:
+    - id: damaged-header
+      flags: [--force-salt, "1234"]
+`)))
+	report := r.Check("game.m1", data)
+	require.Equal(t, []QuirkID{QuirkDamagedHeader}, findingIDs(report))
+	f := report.Findings[0]
+	assert.True(t, f.Registered)
+	assert.False(t, f.Detected)
+	assert.Equal(t, "houston blocks --force-salt 1234 game.m1", f.Workaround)
+}
+
+func TestRegisterErrors(t *testing.T) {
+	r := NewRegistry()
+	hash := "d3be9c407600dfbb5859678aa59ebdd28398e53c345723767a66caf5b6e038ef"
+
+	assert.ErrorIs(t, r.Register(Profile{SHA256: "abc"}), ErrInvalidHash)
+	assert.ErrorIs(t, r.Register(Profile{SHA256: hash, Quirks: []KnownQuirk{{ID: "nope"}}}), ErrUnknownQuirk)
+	require.NoError(t, r.Register(Profile{SHA256: hash}))
+	assert.ErrorIs(t, r.Register(Profile{SHA256: hash}), ErrDuplicate)
+
+	assert.Error(t, r.Parse([]byte("- sha256: "+hash+"\n  typo: x\n")))
+}
+
+func TestDamagedHeader(t *testing.T) {
+	data, err := os.ReadFile(turnFile)
+	require.NoError(t, err)
+	data[6] ^= 0x5a // Game ID
+
+	report := NewRegistry().Check("game.m1", data)
+	require.Equal(t, []QuirkID{QuirkDamagedHeader}, findingIDs(report))
+	assert.Equal(t,
+		"houston blocks --force-salt SALT --force-game-id ID --force-turn TURN --force-player-index INDEX game.m1",
+		report.Findings[0].Workaround)
+}
@@ -0,0 +1,144 @@
+package compat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/neper-stars/houston/filenames"
+	"github.com/neper-stars/houston/lib/tools/namefixer"
+	"github.com/neper-stars/houston/lib/tools/racefixer"
+	"github.com/neper-stars/houston/parser"
+)
+
+// QuirkID names a quirk, as used in the corpus.
+type QuirkID string
+
+// Known quirks.
+const (
+	QuirkRaceChecksum  QuirkID = "race-checksum"  // Race file with a checksum Stars! rejects
+	QuirkMisnamed      QuirkID = "misnamed"       // Turn, orders or history file named after another player
+	QuirkDamagedHeader QuirkID = "damaged-header" // Header decrypting the blocks into garbage
+	QuirkTruncated     QuirkID = "truncated"      // File cut short or with a broken block
+)
+
+// Quirk is a known way files depart from what the parser expects.
+type Quirk struct {
+	ID          QuirkID
+	Description string
+	// Workaround is the houston command reading or fixing the file, FILE
+	// standing for the file and its flags; empty when there is no
+	// workaround.
+	Workaround string
+	// Flags are the flags of the workaround for files not registered with
+	// their own, with placeholders in capitals.
+	Flags []string
+
+	detect func(name string, data []byte) (bool, string)
+}
+
+// command returns the workaround command line for a file.
+func (q Quirk) command(name string, flags []string) string {
+	if q.Workaround == "" {
+		return ""
+	}
+	if flags == nil {
+		flags = q.Flags
+	}
+	args := append(append([]string{}, flags...), quoteArg(name))
+	return strings.Replace(q.Workaround, "FILE", strings.Join(args, " "), 1)
+}
+
+// quirks are checked in order.
+var quirks = []Quirk{
+	{
+		ID:          QuirkTruncated,
+		Description: "The file is cut short or holds a broken block, as left by an interrupted download or a full disk.",
+		detect:      detectTruncated,
+	},
+	{
+		ID:          QuirkDamagedHeader,
+		Description: "The header reads but does not decrypt the blocks; the right values come from a file of the same game and turn.",
+		Workaround:  "houston blocks FILE",
+		Flags:       []string{"--force-salt", "SALT", "--force-game-id", "ID", "--force-turn", "TURN", "--force-player-index", "INDEX"},
+		detect:      detectDamagedHeader,
+	},
+	{
+		ID:          QuirkRaceChecksum,
+		Description: "The checksum of the race file does not match its content, and Stars! reports it as corrupted.",
+		Workaround:  "houston race FILE",
+		detect:      detectRaceChecksum,
+	},
+	{
+		ID:          QuirkMisnamed,
+		Description: "The file is named after another player than the one of its header, and Stars! goes by the header.",
+		Workaround:  "houston fix-name FILE",
+		detect:      detectMisnamed,
+	},
+}
+
+// Quirks returns the known quirks.
+func Quirks() []Quirk {
+	return append([]Quirk(nil), quirks...)
+}
+
+func quirkByID(id QuirkID) (Quirk, bool) {
+	for _, q := range quirks {
+		if q.ID == id {
+			return q, true
+		}
+	}
+	return Quirk{}, false
+}
+
+func detectTruncated(_ string, data []byte) (bool, string) {
+	fd := parser.FileData(data)
+	if _, err := fd.FileHeader(); err != nil {
+		return false, "" // Not a Stars! file
+	}
+	if _, err := fd.DecryptedBlocks(); err != nil {
+		return true, err.Error()
+	}
+	return false, ""
+}
+
+func detectDamagedHeader(_ string, data []byte) (bool, string) {
+	fd := parser.FileData(data)
+	if _, err := fd.FileHeader(); err != nil {
+		return false, ""
+	}
+	decrypted, err := fd.DecryptedBlocks()
+	if err != nil {
+		return false, ""
+	}
+	if _, err := parser.BuildBlockList(decrypted); err != nil {
+		return true, err.Error()
+	}
+	return false, ""
+}
+
+func detectRaceChecksum(name string, data []byte) (bool, string) {
+	if filenames.KindOf(name) != filenames.R {
+		return false, ""
+	}
+	info, err := racefixer.AnalyzeBytes(name, data)
+	if err != nil || !info.NeedsRepair {
+		return false, ""
+	}
+	return true, fmt.Sprintf("footer 0x%04X, expected 0x%04X", info.CurrentFooter, info.ExpectedFooter)
+}
+
+func detectMisnamed(name string, data []byte) (bool, string) {
+	m, err := namefixer.CheckBytes(name, data)
+	if err != nil || m == nil {
+		return false, ""
+	}
+	return true, m.String()
+}
+
+// quoteArg quotes a file name for a shell when needed.
+func quoteArg(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t'\"\\$`*?[]&;|<>()") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
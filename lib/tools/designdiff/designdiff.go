@@ -0,0 +1,197 @@
+// Package designdiff compares two versions of a ship or starbase design.
+//
+// It is meant for tracking design iterations across turns, either your own
+// or an enemy's: slot-by-slot component changes, stat deltas and cost deltas.
+//
+// Example usage:
+//
+//	before, _ := designdiff.FindDesign(oldStore, "Battlecruiser", -1)
+//	after, _ := designdiff.FindDesign(newStore, "Battlecruiser", -1)
+//	diff := designdiff.Compare(before, after)
+//	for _, s := range diff.ChangedStats() {
+//	    fmt.Printf("%s: %d -> %d\n", s.Name, s.Before, s.After)
+//	}
+package designdiff
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+var (
+	ErrDesignNotFound  = errors.New("design not found")
+	ErrAmbiguousDesign = errors.New("design name matches several players")
+)
+
+// SlotChange describes a hull slot whose contents differ.
+// Before or After is nil when the slot is empty on that side.
+type SlotChange struct {
+	SlotIndex int
+	Before    *store.EquippedItem
+	After     *store.EquippedItem
+}
+
+// StatDelta is a single design statistic on both sides.
+type StatDelta struct {
+	Name   string
+	Before int
+	After  int
+}
+
+// Delta returns After minus Before.
+func (s StatDelta) Delta() int {
+	return s.After - s.Before
+}
+
+// Diff is the comparison of two designs.
+type Diff struct {
+	Before, After *store.DesignEntity
+
+	HullChanged bool
+	Slots       []SlotChange // Only slots that changed, by slot index
+	Stats       []StatDelta  // All compared statistics, in a fixed order
+
+	CostBefore data.Cost
+	CostAfter  data.Cost
+}
+
+// Compare computes the differences between two designs.
+func Compare(before, after *store.DesignEntity) *Diff {
+	d := &Diff{
+		Before:      before,
+		After:       after,
+		HullChanged: before.HullId != after.HullId,
+		CostBefore:  before.GetCost(),
+		CostAfter:   after.GetCost(),
+	}
+
+	beforeSlots := slotMap(before)
+	afterSlots := slotMap(after)
+	indexes := make(map[int]bool)
+	for i := range beforeSlots {
+		indexes[i] = true
+	}
+	for i := range afterSlots {
+		indexes[i] = true
+	}
+	for i := range indexes {
+		b, a := beforeSlots[i], afterSlots[i]
+		if b != nil && a != nil && *b == *a {
+			continue
+		}
+		d.Slots = append(d.Slots, SlotChange{SlotIndex: i, Before: b, After: a})
+	}
+	sort.Slice(d.Slots, func(i, j int) bool {
+		return d.Slots[i].SlotIndex < d.Slots[j].SlotIndex
+	})
+
+	sb, sa := designStats(before), designStats(after)
+	for i := range sb {
+		d.Stats = append(d.Stats, StatDelta{Name: sb[i].name, Before: sb[i].value, After: sa[i].value})
+	}
+
+	return d
+}
+
+// ChangedStats returns only the statistics whose value differs.
+func (d *Diff) ChangedStats() []StatDelta {
+	var result []StatDelta
+	for _, s := range d.Stats {
+		if s.Delta() != 0 {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// CostDelta returns the cost of the new design minus the cost of the old one.
+func (d *Diff) CostDelta() data.Cost {
+	return data.Cost{
+		Resources: d.CostAfter.Resources - d.CostBefore.Resources,
+		Ironium:   d.CostAfter.Ironium - d.CostBefore.Ironium,
+		Boranium:  d.CostAfter.Boranium - d.CostBefore.Boranium,
+		Germanium: d.CostAfter.Germanium - d.CostBefore.Germanium,
+	}
+}
+
+// IsEmpty returns true if the designs are identical in hull, slots and stats.
+func (d *Diff) IsEmpty() bool {
+	return !d.HullChanged && len(d.Slots) == 0 && len(d.ChangedStats()) == 0
+}
+
+func slotMap(d *store.DesignEntity) map[int]*store.EquippedItem {
+	result := make(map[int]*store.EquippedItem)
+	for _, item := range d.EquippedItems() {
+		result[item.SlotIndex] = &item
+	}
+	return result
+}
+
+type namedStat struct {
+	name  string
+	value int
+}
+
+func designStats(d *store.DesignEntity) []namedStat {
+	normal, pen := d.GetScannerRanges()
+	minesNormal, minesHeavy, minesSpeed := d.GetMinelayingRate()
+	return []namedStat{
+		{"Firepower", d.GetCombatPower()},
+		{"Armor", d.GetTotalArmorValue()},
+		{"Shields", d.GetTotalShieldValue()},
+		{"Cloak", d.GetCloakPercent()},
+		{"Scanner", normal},
+		{"Pen. scanner", pen},
+		{"Cargo", d.GetCargoCapacity()},
+		{"Fuel", d.GetFuelCapacity()},
+		{"Mining", d.GetMiningRate()},
+		{"Minesweep", d.GetMinesweepRate()},
+		{"Mines (normal)", minesNormal},
+		{"Mines (heavy)", minesHeavy},
+		{"Mines (speed)", minesSpeed},
+	}
+}
+
+// FindDesign looks up a design by name (case-insensitive). If owner is
+// negative, designs of every player are searched and the name must be
+// unique among them.
+func FindDesign(gs *store.GameStore, name string, owner int) (*store.DesignEntity, error) {
+	var matches []*store.DesignEntity
+	for _, d := range gs.AllDesigns() {
+		if owner >= 0 && d.Owner != owner {
+			continue
+		}
+		if strings.EqualFold(d.Name, name) {
+			matches = append(matches, d)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %q", ErrDesignNotFound, name)
+	case 1:
+		return matches[0], nil
+	}
+
+	owners := make(map[int]bool)
+	for _, m := range matches {
+		owners[m.Owner] = true
+	}
+	if len(owners) > 1 {
+		return nil, fmt.Errorf("%w: %q (use a player filter)", ErrAmbiguousDesign, name)
+	}
+
+	// A player may reuse a name for a ship and a starbase design; prefer the ship
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].IsStarbase != matches[j].IsStarbase {
+			return !matches[i].IsStarbase
+		}
+		return matches[i].DesignNumber < matches[j].DesignNumber
+	})
+	return matches[0], nil
+}
@@ -0,0 +1,63 @@
+package designdiff
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+func loadStore(t *testing.T, filename string) *store.GameStore {
+	t.Helper()
+	fileData, err := os.ReadFile("../../../testdata/scenario-map/history/" + filename)
+	require.NoError(t, err)
+	gs := store.New()
+	require.NoError(t, gs.AddFile(filename, fileData))
+	return gs
+}
+
+func TestCompare_SlotAndCostChanges(t *testing.T) {
+	gs := loadStore(t, "game-2471.m1")
+
+	before, err := FindDesign(gs, "starbase", 0)
+	require.NoError(t, err)
+	after, err := FindDesign(gs, "Starbase MD ST", 0)
+	require.NoError(t, err)
+
+	diff := Compare(before, after)
+	assert.False(t, diff.HullChanged)
+	assert.False(t, diff.IsEmpty())
+
+	// Mass driver added in slot 0, stargate in slot 10
+	require.Len(t, diff.Slots, 2)
+	assert.Equal(t, 0, diff.Slots[0].SlotIndex)
+	assert.Nil(t, diff.Slots[0].Before)
+	require.NotNil(t, diff.Slots[0].After)
+	assert.Equal(t, "Mass Driver 7", diff.Slots[0].After.Name())
+	assert.Equal(t, 10, diff.Slots[1].SlotIndex)
+	assert.Equal(t, "Stargate 100/250", diff.Slots[1].After.Name())
+
+	assert.Equal(t, data.Cost{Resources: 1424, Ironium: 300, Boranium: 240, Germanium: 240}, diff.CostDelta())
+}
+
+func TestCompare_AcrossTurns(t *testing.T) {
+	before, err := FindDesign(loadStore(t, "game-2470.m1"), "Armed Probe", 0)
+	require.NoError(t, err)
+	after, err := FindDesign(loadStore(t, "game-2471.m1"), "Armed Probe", 0)
+	require.NoError(t, err)
+
+	diff := Compare(before, after)
+	assert.True(t, diff.IsEmpty())
+	assert.Empty(t, diff.ChangedStats())
+	assert.NotEmpty(t, diff.Stats)
+}
+
+func TestFindDesign_NotFound(t *testing.T) {
+	gs := loadStore(t, "game-2471.m1")
+	_, err := FindDesign(gs, "No Such Design", -1)
+	assert.ErrorIs(t, err, ErrDesignNotFound)
+}
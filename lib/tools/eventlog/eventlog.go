@@ -0,0 +1,233 @@
+// Package eventlog turns the events Stars! reports to each player after a
+// turn generation into a machine-readable log, one JSON object per line, so
+// that reports and notifications do not need to infer events by diffing
+// turns.
+//
+// Events come from the events block of each player's M file: a battle
+// fought by two players is reported once by each of them, from their own
+// point of view. The host file carries no events, so the M files of every
+// player are needed for a complete log.
+//
+// Example usage:
+//
+//	events := eventlog.FromStore(gs)
+//	f, _ := os.Create("game-2401.events.jsonl")
+//	defer f.Close()
+//	err := eventlog.Write(f, events)
+package eventlog
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+// maxPlanets is the most planets a Stars! universe holds.
+const maxPlanets = 999
+
+// Type names a kind of event.
+type Type string
+
+const (
+	TypeBattle            Type = "battle"
+	TypeColony            Type = "colony"
+	TypeCometStrike       Type = "comet.strike"
+	TypeStrangeArtifact   Type = "artifact.found"
+	TypePacketBombardment Type = "packet.bombardment"
+	TypePacketCaptured    Type = "packet.captured"
+	TypePacketLaunched    Type = "packet.launched"
+	TypePopulationChange  Type = "population.change"
+	TypeResearch          Type = "research.complete"
+	TypeStarbaseBuilt     Type = "starbase.built"
+	TypeFleetScrapped     Type = "fleet.scrapped"
+)
+
+// Event is one thing that happened during a turn generation.
+type Event struct {
+	GameID   uint32 `json:"game_id,omitempty"`
+	Year     int    `json:"year"`
+	Type     Type   `json:"type"`
+	Player   int    `json:"player"`             // Player number (1-16) the event was reported to
+	Planet   int    `json:"planet,omitempty"`   // Planet number (1-based), 0 when not at a planet
+	Location string `json:"location,omitempty"` // Planet name, when known
+	Enemy    int    `json:"enemy,omitempty"`    // Player number (1-16) fought in a battle
+	Amount   int    `json:"amount,omitempty"`   // Event specific quantity, see Text
+	Text     string `json:"text"`               // Human-readable description
+
+	Battle *Battle `json:"battle,omitempty"`
+}
+
+// Battle details a battle from the reporting player's point of view.
+type Battle struct {
+	Forces        int  `json:"forces"`
+	EnemyForces   int  `json:"enemy_forces"`
+	Losses        int  `json:"losses"`
+	EnemyLosses   int  `json:"enemy_losses"`
+	Survived      bool `json:"survived"`
+	EnemySurvived bool `json:"enemy_survived"`
+}
+
+// Result returns victory, defeat or draw.
+func (b *Battle) Result() string {
+	switch {
+	case b.Survived && !b.EnemySurvived:
+		return "victory"
+	case !b.Survived && b.EnemySurvived:
+		return "defeat"
+	default:
+		return "draw"
+	}
+}
+
+// FromStore returns the events of the store's turn, sorted by player.
+func FromStore(gs *store.GameStore) []Event {
+	var events []Event
+	for _, evt := range gs.EventsForTurn(gs.Turn) {
+		events = append(events, fromEntity(gs, evt)...)
+	}
+	slices.SortStableFunc(events, func(a, b Event) int {
+		return cmp.Compare(a.Player, b.Player)
+	})
+	return events
+}
+
+func fromEntity(gs *store.GameStore, evt *store.EventsEntity) []Event {
+	year := blocks.StarsBaseYear + int(evt.Turn)
+	player := 0
+	if evt.Source != nil {
+		player = evt.Source.PlayerIndex + 1
+	}
+
+	// Some events carry values that are not planet numbers (e.g. 0x2000 in
+	// starbase events); they are logged without a location
+	known := func(planet int) bool {
+		return planet >= 0 && planet < maxPlanets
+	}
+
+	var events []Event
+	add := func(t Type, planet, amount int, text string) *Event {
+		e := Event{GameID: gs.GameID, Year: year, Type: t, Player: player, Amount: amount, Text: text}
+		if known(planet) {
+			e.Planet = planet + 1
+			e.Location = gs.PlanetName(planet)
+		}
+		events = append(events, e)
+		return &events[len(events)-1]
+	}
+	at := func(planet int) string {
+		if !known(planet) {
+			return "an unknown location"
+		}
+		if name := gs.PlanetName(planet); name != "" {
+			return name
+		}
+		return fmt.Sprintf("planet #%d", planet+1)
+	}
+
+	for _, b := range evt.Battles {
+		battle := &Battle{
+			Forces:        b.YourForces,
+			EnemyForces:   b.EnemyForces,
+			Losses:        b.YourLosses,
+			EnemyLosses:   b.EnemyLosses,
+			Survived:      b.YouSurvived,
+			EnemySurvived: b.EnemySurvived,
+		}
+		e := add(TypeBattle, b.PlanetID, b.YourLosses+b.EnemyLosses,
+			fmt.Sprintf("Battle at %s against player %d: %s, %d ship(s) lost, %d destroyed",
+				at(b.PlanetID), b.EnemyPlayer+1, battle.Result(), b.YourLosses, b.EnemyLosses))
+		e.Enemy = b.EnemyPlayer + 1
+		e.Battle = battle
+	}
+	for _, c := range evt.NewColonies {
+		add(TypeColony, c.PlanetID, 0, fmt.Sprintf("Colony established on %s", at(c.PlanetID)))
+	}
+	for _, c := range evt.CometStrikes {
+		text := fmt.Sprintf("%s comet struck %s", c.CometSizeName(), at(c.PlanetID))
+		if c.IsOwnedPlanet() {
+			text += fmt.Sprintf(", killing %d%% of the colonists", c.DeathPercent())
+		}
+		add(TypeCometStrike, c.PlanetID, c.DeathPercent(), text)
+	}
+	for _, a := range evt.StrangeArtifacts {
+		add(TypeStrangeArtifact, a.PlanetID, a.BoostAmount,
+			fmt.Sprintf("Strange artifact found on %s: %d resources of %s research",
+				at(a.PlanetID), a.BoostAmount, blocks.ResearchFieldName(a.ResearchField)))
+	}
+	for _, p := range evt.PacketBombardments {
+		add(TypePacketBombardment, p.PlanetID, p.ColonistsKilled,
+			fmt.Sprintf("%d kT mineral packet struck %s, killing %d colonists",
+				p.MineralAmount, at(p.PlanetID), p.ColonistsKilled))
+	}
+	for _, p := range evt.PacketsCaptured {
+		add(TypePacketCaptured, p.PlanetID, p.MineralAmount,
+			fmt.Sprintf("%d kT mineral packet caught at %s", p.MineralAmount, at(p.PlanetID)))
+	}
+	for _, p := range evt.PacketsProduced {
+		add(TypePacketLaunched, p.DestinationPlanetID, 0,
+			fmt.Sprintf("Mineral packet launched toward %s", at(p.DestinationPlanetID)))
+	}
+	for _, p := range evt.PopulationChanges {
+		add(TypePopulationChange, p.PlanetID, p.Amount,
+			fmt.Sprintf("%d colonists died on %s", p.Amount, at(p.PlanetID)))
+	}
+	for _, r := range evt.ResearchEvents {
+		add(TypeResearch, -1, r.Level,
+			fmt.Sprintf("%s level %d reached", blocks.ResearchFieldName(r.Field), r.Level))
+	}
+	for _, s := range evt.StarbasesBuilt {
+		add(TypeStarbaseBuilt, s.PlanetID, 0, fmt.Sprintf("Starbase built at %s", at(s.PlanetID)))
+	}
+	for _, f := range evt.FleetsScrapped {
+		add(TypeFleetScrapped, f.PlanetID, f.MineralAmount,
+			fmt.Sprintf("Fleet #%d scrapped at %s, %d kT recovered", f.FleetIndex+1, at(f.PlanetID), f.MineralAmount))
+	}
+	for _, f := range evt.FleetsScrappedAtStarbase {
+		add(TypeFleetScrapped, f.PlanetID, f.FleetMass,
+			fmt.Sprintf("Fleet #%d of %d kT scrapped at the starbase of %s", f.FleetIndex+1, f.FleetMass, at(f.PlanetID)))
+	}
+	for range evt.FleetsScrappedInSpace {
+		add(TypeFleetScrapped, -1, 0, "Fleet scrapped in deep space")
+	}
+	return events
+}
+
+// Write writes events as JSON lines.
+func Write(w io.Writer, events []Event) error {
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to write event: %w", err)
+		}
+	}
+	return nil
+}
+
+// Read reads events written by Write. Blank lines are skipped.
+func Read(r io.Reader) ([]Event, error) {
+	var events []Event
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+	return events, nil
+}
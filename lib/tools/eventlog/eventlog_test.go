@@ -0,0 +1,78 @@
+package eventlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func loadTurn(t *testing.T, names ...string) *store.GameStore {
+	t.Helper()
+	gs := store.New()
+	for _, name := range names {
+		require.NoError(t, gs.AddFileWithXY("../../../testdata/scenario-map/history/"+name))
+	}
+	return gs
+}
+
+func TestFromStore(t *testing.T) {
+	events := FromStore(loadTurn(t, "game-2411.m2", "game-2411.m1"))
+	require.Len(t, events, 4)
+
+	// Sorted by reporting player
+	assert.Equal(t, []int{1, 1, 2, 2}, []int{events[0].Player, events[1].Player, events[2].Player, events[3].Player})
+
+	artifact := events[2]
+	assert.NotZero(t, artifact.GameID)
+	artifact.GameID = 0
+	assert.Equal(t, Event{
+		Year:     2411,
+		Type:     TypeStrangeArtifact,
+		Player:   2,
+		Planet:   371,
+		Location: "Ball Bearing",
+		Amount:   227,
+		Text:     "Strange artifact found on Ball Bearing: 227 resources of Energy research",
+	}, artifact)
+	assert.Equal(t, TypeFleetScrapped, events[0].Type)
+	assert.Equal(t, 28, events[0].Amount)
+}
+
+func TestFromStore_SharedEvent(t *testing.T) {
+	// Both players see the comet striking the same unowned planet
+	events := FromStore(loadTurn(t, "game-2418.m1", "game-2418.m2"))
+	require.Len(t, events, 2)
+	for i, e := range events {
+		assert.Equal(t, TypeCometStrike, e.Type)
+		assert.Equal(t, i+1, e.Player)
+		assert.Equal(t, "Applegate", e.Location)
+		assert.Zero(t, e.Amount, "nobody died")
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	events := []Event{
+		{Year: 2450, Type: TypeBattle, Player: 1, Planet: 12, Location: "Rigel", Enemy: 3, Amount: 5, Text: "Battle",
+			Battle: &Battle{Forces: 2, EnemyForces: 1, Losses: 1, EnemyLosses: 4, Survived: true}},
+		{Year: 2450, Type: TypeResearch, Player: 3, Amount: 7, Text: "Weapons level 7 reached"},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, events))
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), `"type":"battle"`)
+	assert.NotContains(t, strings.Split(buf.String(), "\n")[1], "battle", "empty details are omitted")
+
+	buf.WriteString("\n")
+	read, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, events, read)
+	assert.Equal(t, "victory", read[0].Battle.Result())
+
+	_, err = Read(strings.NewReader("{\"year\":2450}\nnot json\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
@@ -0,0 +1,199 @@
+package exploits
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/store"
+)
+
+// ScoreRecord is the score of a player in a year, as shown in the score
+// screen of the Stars! client.
+type ScoreRecord struct {
+	Player    int // Player index (0-15)
+	Year      int
+	Score     int
+	Resources int64
+}
+
+// AnomalyThresholds sets how sharp a jump must be to be reported by
+// CheckScoreAnomalies.
+type AnomalyThresholds struct {
+	// ZScore is how many deviations from the player's usual yearly change a
+	// jump must be, using the median and median absolute deviation of the
+	// player's changes so that the jumps themselves do not hide each other.
+	ZScore float64
+
+	// MinScoreJump and MinResourceJump ignore smaller jumps, whatever their
+	// deviation: steady early games make tiny deviations.
+	MinScoreJump    int
+	MinResourceJump int64
+
+	// MinYears is the number of yearly changes needed to judge a player.
+	MinYears int
+}
+
+// DefaultAnomalyThresholds returns thresholds reporting only jumps far out of
+// a player's trend.
+func DefaultAnomalyThresholds() AnomalyThresholds {
+	return AnomalyThresholds{
+		ZScore:          6,
+		MinScoreJump:    100,
+		MinResourceJump: 2000,
+		MinYears:        5,
+	}
+}
+
+// ScoreRecordsFromFile returns the score records of a Stars! file. H files
+// hold the records of every year; M files only those of their year.
+func ScoreRecordsFromFile(data []byte) ([]ScoreRecord, error) {
+	blockList, err := parser.FileData(data).BlockList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse blocks: %w", err)
+	}
+	var records []ScoreRecord
+	for _, block := range blockList {
+		if b, ok := block.(blocks.PlayerScoresBlock); ok {
+			records = append(records, ScoreRecord{
+				Player:    b.PlayerID,
+				Year:      blocks.StarsBaseYear + b.Turn,
+				Score:     b.Score,
+				Resources: b.Resources,
+			})
+		}
+	}
+	return records, nil
+}
+
+// ScoreRecordsFromStores returns the latest score of each player of each
+// store, typically one store per turn.
+func ScoreRecordsFromStores(turns []*store.GameStore) []ScoreRecord {
+	var records []ScoreRecord
+	for _, gs := range turns {
+		for _, player := range gs.AllPlayers() {
+			if s := player.StoredScore; s != nil {
+				records = append(records, ScoreRecord{
+					Player:    player.PlayerNumber,
+					Year:      blocks.StarsBaseYear + s.Turn,
+					Score:     s.Score,
+					Resources: s.Resources,
+				})
+			}
+		}
+	}
+	return records
+}
+
+// CheckScoreAnomalies reports score and resource jumps far out of each
+// player's trend, which a hacked file or a host error could explain.
+// Records may come from several sources and overlap. Only rises are
+// reported: losses happen in normal play.
+func CheckScoreAnomalies(records []ScoreRecord, t AnomalyThresholds) *Result {
+	result := NewResult()
+
+	byPlayer := make(map[int]map[int]ScoreRecord)
+	for _, r := range records {
+		if byPlayer[r.Player] == nil {
+			byPlayer[r.Player] = make(map[int]ScoreRecord)
+		}
+		byPlayer[r.Player][r.Year] = r
+	}
+
+	players := make([]int, 0, len(byPlayer))
+	for player := range byPlayer {
+		players = append(players, player)
+	}
+	slices.Sort(players)
+
+	for _, player := range players {
+		years := make([]int, 0, len(byPlayer[player]))
+		for year := range byPlayer[player] {
+			years = append(years, year)
+		}
+		slices.Sort(years)
+
+		// Yearly changes, over consecutive years only
+		var scoreDeltas, resourceDeltas []float64
+		var pairs [][2]ScoreRecord
+		for i := 1; i < len(years); i++ {
+			if years[i] != years[i-1]+1 {
+				continue
+			}
+			prev, next := byPlayer[player][years[i-1]], byPlayer[player][years[i]]
+			pairs = append(pairs, [2]ScoreRecord{prev, next})
+			scoreDeltas = append(scoreDeltas, float64(next.Score-prev.Score))
+			resourceDeltas = append(resourceDeltas, float64(next.Resources-prev.Resources))
+		}
+		if len(pairs) < max(t.MinYears, 1) {
+			continue
+		}
+
+		scoreMedian, scoreSpread := robustSpread(scoreDeltas)
+		resourceMedian, resourceSpread := robustSpread(resourceDeltas)
+		for i, pair := range pairs {
+			prev, next := pair[0], pair[1]
+			if jump := next.Score - prev.Score; jump >= t.MinScoreJump {
+				if z := deviation(scoreDeltas[i], scoreMedian, scoreSpread); z >= t.ZScore {
+					result.Add(scoreAnomaly(player, next.Year, "score", int64(prev.Score), int64(next.Score), scoreMedian, z))
+				}
+			}
+			if jump := next.Resources - prev.Resources; jump >= t.MinResourceJump {
+				if z := deviation(resourceDeltas[i], resourceMedian, resourceSpread); z >= t.ZScore {
+					result.Add(scoreAnomaly(player, next.Year, "resources", prev.Resources, next.Resources, resourceMedian, z))
+				}
+			}
+		}
+	}
+	return result
+}
+
+func scoreAnomaly(player, year int, what string, before, after int64, usual, z float64) *Detection {
+	deviations := "far"
+	if !math.IsInf(z, 1) {
+		deviations = fmt.Sprintf("%.1f deviations", z)
+	}
+	return &Detection{
+		Type:        ExploitScoreAnomaly,
+		Severity:    SeverityWarning,
+		Player:      player,
+		Description: fmt.Sprintf("%s jumped from %d to %d (+%d)", what, before, after, after-before),
+		Details:     fmt.Sprintf("usual yearly change %+.0f, %s above it", usual, deviations),
+		Year:        year,
+	}
+}
+
+// robustSpread returns the median of the values and their median absolute
+// deviation, scaled to match the standard deviation of normal data.
+func robustSpread(values []float64) (median, spread float64) {
+	median = medianOf(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	return median, 1.4826 * medianOf(deviations)
+}
+
+func medianOf(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// deviation returns how many spreads the value is above the median; any
+// rise is infinitely far when the values never vary.
+func deviation(value, median, spread float64) float64 {
+	if value <= median {
+		return 0
+	}
+	if spread == 0 {
+		return math.Inf(1)
+	}
+	return (value - median) / spread
+}
@@ -0,0 +1,83 @@
+package exploits
+
+import (
+	"os"
+	"testing"
+)
+
+// steadyRecords returns 20 years of a player's scores growing by 10 to 14
+// points and 100 to 140 resources a year.
+func steadyRecords(player int) []ScoreRecord {
+	var records []ScoreRecord
+	score, resources := 100, int64(1000)
+	for year := 2401; year <= 2420; year++ {
+		records = append(records, ScoreRecord{Player: player, Year: year, Score: score, Resources: resources})
+		score += 10 + year%5
+		resources += int64(100 + 10*(year%5))
+	}
+	return records
+}
+
+func TestCheckScoreAnomalies_Steady(t *testing.T) {
+	result := CheckScoreAnomalies(steadyRecords(0), DefaultAnomalyThresholds())
+	for _, d := range result.Detections {
+		t.Errorf("Unexpected detection: %s (%s)", d, d.Details)
+	}
+}
+
+func TestCheckScoreAnomalies_Jump(t *testing.T) {
+	records := steadyRecords(2)
+	for i := range records {
+		if records[i].Year >= 2410 {
+			records[i].Score += 500
+		}
+	}
+	// Overlapping records from another file change nothing
+	records = append(records, records[3])
+
+	result := CheckScoreAnomalies(records, DefaultAnomalyThresholds())
+	if result.Count() != 1 {
+		t.Fatalf("Expected 1 detection, got %d", result.Count())
+	}
+	d := result.Detections[0]
+	if d.Type != ExploitScoreAnomaly || d.Player != 2 || d.Year != 2410 {
+		t.Errorf("Unexpected detection: %+v", d)
+	}
+
+	// Thresholds above the jump report nothing
+	thresholds := DefaultAnomalyThresholds()
+	thresholds.MinScoreJump = 1000
+	if got := CheckScoreAnomalies(records, thresholds).Count(); got != 0 {
+		t.Errorf("Expected no detection above the minimum jump, got %d", got)
+	}
+
+	// Too short a history is not judged
+	thresholds = DefaultAnomalyThresholds()
+	thresholds.MinYears = 50
+	if got := CheckScoreAnomalies(records, thresholds).Count(); got != 0 {
+		t.Errorf("Expected no detection with a short history, got %d", got)
+	}
+}
+
+func TestScoreRecordsFromFile(t *testing.T) {
+	data, err := os.ReadFile("../../../testdata/scenario-orders/waypoint-load/game.h1")
+	if err != nil {
+		t.Skipf("Test file not found: %v", err)
+	}
+	records, err := ScoreRecordsFromFile(data)
+	if err != nil {
+		t.Fatalf("ScoreRecordsFromFile failed: %v", err)
+	}
+	if len(records) != 81 {
+		t.Fatalf("Expected 81 records, got %d", len(records))
+	}
+	if records[len(records)-1].Year != 2481 {
+		t.Errorf("Expected the last record in 2481, got %d", records[len(records)-1].Year)
+	}
+
+	// A normal game raises no anomaly
+	result := CheckScoreAnomalies(records, DefaultAnomalyThresholds())
+	for _, d := range result.Detections {
+		t.Errorf("Unexpected detection: %s (%s)", d, d.Details)
+	}
+}
@@ -9,6 +9,13 @@
 //   - 32k Merge: Merging fleets exceeding 32,767 ships of one type
 //   - Mineral Upload: Uploading minerals to enemy fleet beyond capacity
 //   - Cheap Starbase: Editing starbase design while under construction
+//
+// Comparing consecutive turns (see CheckHistory) also detects:
+//   - Pop Drop: Population growing by more than growth and colonist deliveries allow
+//   - Impossible Cargo: Fleet cargo over capacity or appearing in deep space
+//
+// CheckScoreAnomalies flags score and resource jumps far out of a player's
+// trend (Score Anomaly), a warning rather than a proof.
 package exploits
 
 import (
@@ -27,6 +34,9 @@ const (
 	Exploit32kMerge
 	ExploitMineralUpload
 	ExploitCheapStarbase
+	ExploitPopDrop
+	ExploitImpossibleCargo
+	ExploitScoreAnomaly
 )
 
 // String returns the human-readable name of the exploit type.
@@ -40,6 +50,9 @@ func (e ExploitType) String() string {
 		"32k Merge",
 		"Mineral Upload",
 		"Cheap Starbase",
+		"Pop Drop",
+		"Impossible Cargo",
+		"Score Anomaly",
 	}
 	if int(e) < len(names) {
 		return names[e]
@@ -80,6 +93,7 @@ type Detection struct {
 	CanFix      bool        // True if this detection can be auto-fixed
 	BlockIndex  int         // Index of the problematic block (for fixing)
 	FixApplied  bool        // True if fix was applied
+	Year        int         // Game year, set by checks spanning several turns
 }
 
 // String returns a formatted string representation of the detection.
@@ -81,6 +81,8 @@ func TestExploitType_String(t *testing.T) {
 		{Exploit32kMerge, "32k Merge"},
 		{ExploitMineralUpload, "Mineral Upload"},
 		{ExploitCheapStarbase, "Cheap Starbase"},
+		{ExploitPopDrop, "Pop Drop"},
+		{ExploitImpossibleCargo, "Impossible Cargo"},
 	}
 
 	for _, tt := range tests {
@@ -392,12 +392,8 @@ func regenerateFile(originalData []byte, blockList []blocks.Block, header *block
 
 			// Initialize encryptor from header for subsequent blocks
 			if header != nil {
-				var sw int
-				if header.Crippled() {
-					sw = 1
-				}
 				encryptor = crypto.NewEncryptor()
-				encryptor.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), sw)
+				encryptor.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), header.SharewareFlag())
 				encryptorInitialized = true
 			}
 		case blocks.FileFooterBlockType:
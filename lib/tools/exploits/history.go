@@ -0,0 +1,259 @@
+package exploits
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+// CheckHistory runs the checks needing several turns of a game, such as a
+// planet gaining more colonists than could have been delivered. Each store
+// holds the files of one turn; turns are sorted by year before comparing.
+//
+// Only fully known planets and fleets (the players' own, from M files) are
+// compared, and every bound errs on the generous side: a detection means the
+// files cannot be explained by normal play.
+func CheckHistory(turns []*store.GameStore) *Result {
+	result := NewResult()
+	sorted := slices.Clone(turns)
+	slices.SortFunc(sorted, func(a, b *store.GameStore) int {
+		return int(a.Turn) - int(b.Turn)
+	})
+
+	for i, gs := range sorted {
+		checkCargoCapacity(gs, result)
+		if i == 0 || gs.Turn != sorted[i-1].Turn+1 {
+			continue
+		}
+		checkPopDrop(sorted[i-1], gs, result)
+		checkDeepSpaceCargo(sorted[i-1], gs, result)
+	}
+	return result
+}
+
+func storeYear(gs *store.GameStore) int {
+	return blocks.StarsBaseYear + int(gs.Turn)
+}
+
+// checkPopDrop flags planets whose population grew by more than their own
+// growth plus the colonists the owner's fleets could have dropped there:
+// those of the fleets at or in orbit of the planet on the previous turn, and
+// of the fleets there now, which arrived with the cargo they had then.
+// Growth stops at the planet's capacity, so that a planet already full can
+// only gain what was delivered. Planets where the owner's fleets merged or
+// loaded colonists during the turn are skipped, as the previous turn's
+// cargo does not tell what they delivered.
+func checkPopDrop(prev, next *store.GameStore, result *Result) {
+	for _, p := range next.AllPlanets() {
+		if p.Owner < 0 || p.Meta().Quality < store.QualityFull {
+			continue
+		}
+		before, ok := prev.Planet(p.PlanetNumber)
+		if !ok || before.Owner != p.Owner || before.Meta().Quality < store.QualityFull {
+			continue
+		}
+		if mergedOrLoadedAt(prev, next, before) {
+			continue
+		}
+
+		// Growth rates are at most 20% per year
+		growthRate := 20
+		capacity := int64(0)
+		if player, ok := next.Player(p.Owner); ok {
+			if player.GrowthRate > 0 {
+				growthRate = player.GrowthRate
+			}
+			capacity = int64(next.MaxPopulation(p, player))
+		}
+		// Files store population in 100s, so round growth up and allow for
+		// one more unit of rounding
+		growth := (before.Population*int64(growthRate)/100+99)/100*100 + 100
+		if capacity > 0 {
+			growth = min(growth, max(capacity-before.Population, 0)+100)
+		}
+		delivered := colonistsAt(prev, next, before)
+		gain := p.Population - before.Population
+		excess := gain - growth - delivered
+		if excess <= 0 {
+			continue
+		}
+
+		details := fmt.Sprintf("population %d -> %d, at most %d from growth and %d carried by fleets at the planet",
+			before.Population, p.Population, growth, delivered)
+		if capacity > 0 {
+			details += fmt.Sprintf(", capacity %d", capacity)
+		}
+		result.Add(&Detection{
+			Type:        ExploitPopDrop,
+			Severity:    SeverityCritical,
+			Player:      p.Owner,
+			Description: fmt.Sprintf("Planet %s gained %d colonists that could not have been delivered", p.Name, excess),
+			Details:     details,
+			Year:        storeYear(next),
+		})
+	}
+}
+
+// colonistsAt sums the colonists the planet owner's fleets carried on the
+// previous turn, for the fleets at or in orbit of the planet on either
+// turn.
+func colonistsAt(prev, next *store.GameStore, planet *store.PlanetEntity) int64 {
+	at := func(f *store.FleetEntity) bool {
+		return f.Owner == planet.Owner &&
+			(f.PositionObjectId == planet.PlanetNumber || f.X == planet.X && f.Y == planet.Y)
+	}
+	var total int64
+	for _, f := range prev.AllFleets() {
+		if f.Meta().Quality < store.QualityFull {
+			continue
+		}
+		if !at(f) {
+			now, ok := next.Fleet(f.Owner, f.FleetNumber)
+			if !ok || !at(now) {
+				continue
+			}
+		}
+		total += f.GetCargo().Population
+	}
+	return total
+}
+
+// mergedOrLoadedAt reports whether a fleet of the planet owner at, or
+// bound for, the planet on the previous turn merged or loaded colonists
+// during the turn: it is gone on the next turn, it has more ships, or it
+// was ordered to load colonists where it was before flying to the planet.
+// Waypoint 0 is where the fleet was; its task runs before the fleet moves
+// to waypoint 1.
+func mergedOrLoadedAt(prev, next *store.GameStore, planet *store.PlanetEntity) bool {
+	at := func(x, y int) bool { return x == planet.X && y == planet.Y }
+	for _, f := range prev.AllFleets() {
+		if f.Owner != planet.Owner || f.Meta().Quality < store.QualityFull {
+			continue
+		}
+		now, ok := next.Fleet(f.Owner, f.FleetNumber)
+		here := f.PositionObjectId == planet.PlanetNumber || at(f.X, f.Y)
+		involved := here || ok && at(now.X, now.Y) || len(f.Waypoints) > 1 && at(f.Waypoints[1].X, f.Waypoints[1].Y)
+		if !involved {
+			continue
+		}
+		if !ok || now.TotalShips() > f.TotalShips() {
+			return true
+		}
+		if len(f.Waypoints) > 0 && !here {
+			wp := f.Waypoints[0]
+			if wp.Task == blocks.WaypointTaskTransport && loadsColonists(wp.TransportOrders[blocks.CargoColonists]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loadsColonists reports whether a transport order can take colonists on
+// board.
+func loadsColonists(order blocks.TransportOrder) bool {
+	switch order.Action {
+	case blocks.TransportTaskLoadAll, blocks.TransportTaskLoadExactly, blocks.TransportTaskFillToPercent,
+		blocks.TransportTaskWaitForPercent, blocks.TransportTaskDropAndLoad, blocks.TransportTaskSetAmountTo:
+		return true
+	}
+	return false
+}
+
+// checkCargoCapacity flags fleets holding more cargo than their ships can.
+func checkCargoCapacity(gs *store.GameStore, result *Result) {
+	for _, f := range gs.AllFleets() {
+		if f.Meta().Quality < store.QualityFull {
+			continue
+		}
+		capacity, ok := fleetCargoCapacity(gs, f)
+		if !ok {
+			continue
+		}
+		cargo := f.GetCargo()
+		load := cargo.Ironium + cargo.Boranium + cargo.Germanium + cargo.Population/100
+		if load <= capacity {
+			continue
+		}
+		result.Add(&Detection{
+			Type:        ExploitImpossibleCargo,
+			Severity:    SeverityCritical,
+			Player:      f.Owner,
+			Description: fmt.Sprintf("%s carries %d kT of cargo but holds only %d kT", f.Name(), load, capacity),
+			Details:     fmt.Sprintf("fleet #%d at (%d, %d)", f.FleetNumber+1, f.X, f.Y),
+			Year:        storeYear(gs),
+		})
+	}
+}
+
+// fleetCargoCapacity sums the cargo capacity of a fleet's ships, reporting
+// false when a design is unknown.
+func fleetCargoCapacity(gs *store.GameStore, f *store.FleetEntity) (int64, bool) {
+	var capacity int64
+	for slot := range 16 {
+		if f.ShipTypes&(1<<slot) == 0 || f.ShipCounts[slot] == 0 {
+			continue
+		}
+		design, ok := gs.Design(f.Owner, slot)
+		if !ok || design.Hull() == nil {
+			return 0, false
+		}
+		capacity += int64(design.GetCargoCapacity()) * int64(f.ShipCounts[slot])
+	}
+	return capacity, true
+}
+
+// checkDeepSpaceCargo flags fleets whose cargo grew while they stayed alone
+// in deep space, with no planet, fleet or salvage to load from.
+func checkDeepSpaceCargo(prev, next *store.GameStore, result *Result) {
+	fleetsAt := make(map[[2]int]int)
+	occupied := make(map[[2]int]bool)
+	for _, gs := range []*store.GameStore{prev, next} {
+		for _, f := range gs.AllFleets() {
+			fleetsAt[[2]int{f.X, f.Y}]++
+		}
+		for _, p := range gs.AllPlanets() {
+			occupied[[2]int{p.X, p.Y}] = true
+		}
+		for _, s := range gs.Salvage() {
+			occupied[[2]int{s.X, s.Y}] = true
+		}
+	}
+
+	for _, f := range next.AllFleets() {
+		if f.Meta().Quality < store.QualityFull {
+			continue
+		}
+		before, ok := prev.Fleet(f.Owner, f.FleetNumber)
+		if !ok || before.Meta().Quality < store.QualityFull || before.X != f.X || before.Y != f.Y {
+			continue
+		}
+		// The fleet itself is counted once in each turn
+		pos := [2]int{f.X, f.Y}
+		if occupied[pos] || fleetsAt[pos] > 2 {
+			continue
+		}
+
+		was, is := before.GetCargo(), f.GetCargo()
+		minerals := (is.Ironium + is.Boranium + is.Germanium) - (was.Ironium + was.Boranium + was.Germanium)
+		colonists := is.Population - was.Population
+		if player, ok := next.Player(f.Owner); ok && player.PRT == blocks.PRTInnerStrength {
+			// Inner Strength colonists grow in cargo holds
+			colonists = 0
+		}
+		if minerals <= 0 && colonists <= 0 {
+			continue
+		}
+		result.Add(&Detection{
+			Type:        ExploitImpossibleCargo,
+			Severity:    SeverityCritical,
+			Player:      f.Owner,
+			Description: fmt.Sprintf("%s gained cargo in deep space with nothing to load from", f.Name()),
+			Details: fmt.Sprintf("at (%d, %d): minerals %+d kT, colonists %+d",
+				f.X, f.Y, max(minerals, 0), max(colonists, 0)),
+			Year: storeYear(next),
+		})
+	}
+}
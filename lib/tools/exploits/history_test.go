@@ -0,0 +1,251 @@
+package exploits
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+// loadTurns loads two consecutive turns of player 1 from testdata, so that
+// tests can tamper with them.
+func loadTurns(t *testing.T) (prev, next *store.GameStore) {
+	t.Helper()
+	prev, next = store.New(), store.New()
+	for gs, year := range map[*store.GameStore]int{prev: 2470, next: 2471} {
+		testFile := fmt.Sprintf("../../../testdata/scenario-map/history/game-%d.m1", year)
+		if err := gs.AddFileWithXY(testFile); err != nil {
+			t.Skipf("Test file not found: %s", testFile)
+		}
+	}
+	if next.Turn != prev.Turn+1 {
+		t.Fatalf("Turns %d and %d do not follow each other", prev.Turn, next.Turn)
+	}
+	return prev, next
+}
+
+// ownedPlanet returns a planet of player 1 known in both turns, with or
+// without a fleet of the player at it on either turn.
+func ownedPlanet(t *testing.T, prev, next *store.GameStore, withFleet bool) (before, after *store.PlanetEntity) {
+	t.Helper()
+	for _, p := range next.AllPlanets() {
+		b, ok := prev.Planet(p.PlanetNumber)
+		if p.Owner != 0 || !ok || b.Owner != 0 || b.Meta().Quality < store.QualityFull {
+			continue
+		}
+		if hasFleet := fleetAt(prev, b) != nil || fleetAt(next, p) != nil; hasFleet == withFleet {
+			return b, p
+		}
+	}
+	t.Fatalf("No owned planet found (with fleet: %v)", withFleet)
+	return nil, nil
+}
+
+func fleetAt(gs *store.GameStore, p *store.PlanetEntity) *store.FleetEntity {
+	for _, f := range gs.AllFleets() {
+		if f.Owner == p.Owner && f.X == p.X && f.Y == p.Y {
+			return f
+		}
+	}
+	return nil
+}
+
+func TestCheckHistory_Clean(t *testing.T) {
+	prev, next := loadTurns(t)
+	result := CheckHistory([]*store.GameStore{next, prev})
+	for _, d := range result.Detections {
+		t.Errorf("Unexpected detection: %s (%s)", d, d.Details)
+	}
+}
+
+func TestCheckHistory_PopDrop(t *testing.T) {
+	prev, next := loadTurns(t)
+
+	_, target := ownedPlanet(t, prev, next, false)
+	target.SetPopulation(target.Population + 10_000_000)
+
+	result := CheckHistory([]*store.GameStore{prev, next})
+	drops := result.ByType(ExploitPopDrop)
+	if len(drops) != 1 {
+		t.Fatalf("Expected 1 pop drop, got %d", len(drops))
+	}
+	if drops[0].Player != 0 || drops[0].Year != storeYear(next) {
+		t.Errorf("Unexpected detection: %+v", drops[0])
+	}
+
+	// Turns that do not follow each other are not compared
+	next.Turn = prev.Turn + 2
+	if got := CheckHistory([]*store.GameStore{prev, next}).CountByType(ExploitPopDrop); got != 0 {
+		t.Errorf("Expected no pop drop across a gap, got %d", got)
+	}
+}
+
+func TestCheckHistory_PopDropFleetsElsewhere(t *testing.T) {
+	prev, next := loadTurns(t)
+
+	// Colonists carried across the galaxy cannot land on the planet
+	_, target := ownedPlanet(t, prev, next, false)
+	for _, f := range prev.AllFleets() {
+		if f.Owner == 0 {
+			f.SetCargo(store.Cargo{Population: 100_000_000})
+			break
+		}
+	}
+	target.SetPopulation(target.Population + 10_000_000)
+
+	if got := CheckHistory([]*store.GameStore{prev, next}).CountByType(ExploitPopDrop); got != 1 {
+		t.Errorf("Expected 1 pop drop, got %d", got)
+	}
+}
+
+func TestCheckHistory_PopDelivered(t *testing.T) {
+	prev, next := loadTurns(t)
+
+	before, target := ownedPlanet(t, prev, next, true)
+	fleet := fleetAt(prev, before)
+	if fleet == nil {
+		fleet = fleetAt(prev, target)
+	}
+	if fleet == nil {
+		// The fleet arrived this turn: give it the cargo it came with
+		now := fleetAt(next, target)
+		var ok bool
+		if fleet, ok = prev.Fleet(now.Owner, now.FleetNumber); !ok {
+			t.Skip("Arriving fleet not known on the previous turn")
+		}
+	}
+	fleet.SetCargo(store.Cargo{Population: 10_000_000})
+	target.SetPopulation(target.Population + 10_000_000)
+
+	if got := CheckHistory([]*store.GameStore{prev, next}).CountByType(ExploitPopDrop); got != 0 {
+		t.Errorf("Expected colonists dropped by a fleet at the planet to be accepted, got %d", got)
+	}
+
+	target.SetPopulation(target.Population + 10_000_000)
+	if got := CheckHistory([]*store.GameStore{prev, next}).CountByType(ExploitPopDrop); got != 1 {
+		t.Errorf("Expected more than the fleet carried to be flagged, got %d", got)
+	}
+}
+
+// rubberPump returns the colonist freighter of player 1 on the previous
+// turn and the planet it is bound for, Rubber, where Long Range Scout #21
+// is in orbit.
+func rubberPump(t *testing.T, prev, next *store.GameStore) (*store.FleetEntity, *store.PlanetEntity) {
+	t.Helper()
+	pump, ok := prev.Fleet(0, 11)
+	if !ok || pump.Name() != "Rubber Pump" || len(pump.Waypoints) < 2 {
+		t.Fatal("Rubber Pump not found")
+	}
+	rubber, ok := next.PlanetByName("Rubber")
+	if !ok || pump.Waypoints[1].X != rubber.X || pump.Waypoints[1].Y != rubber.Y {
+		t.Fatal("Rubber Pump is not bound for Rubber")
+	}
+	return pump, rubber
+}
+
+func TestCheckHistory_PopMerged(t *testing.T) {
+	prev, next := loadTurns(t)
+
+	// The pump arrives with its colonists, drops them and merges into the
+	// scout orbiting Rubber
+	pump, rubber := rubberPump(t, prev, next)
+	pump.SetCargo(store.Cargo{Population: 10_000_000})
+	rubber.SetPopulation(rubber.Population + 10_000_000)
+	if !next.Fleets.Remove(pump.Meta().Key) {
+		t.Fatal("Rubber Pump not found on the next turn")
+	}
+	scout, ok := next.Fleet(0, 20)
+	if !ok || scout.X != rubber.X || scout.Y != rubber.Y {
+		t.Fatal("Long Range Scout #21 not at Rubber")
+	}
+	for slot := range scout.ShipCounts {
+		if scout.ShipTypes&(1<<slot) != 0 {
+			scout.ShipCounts[slot]++
+			break
+		}
+	}
+
+	if got := CheckHistory([]*store.GameStore{prev, next}).CountByType(ExploitPopDrop); got != 0 {
+		t.Errorf("Expected colonists dropped before a merge to be accepted, got %d", got)
+	}
+}
+
+func TestCheckHistory_PopLoaded(t *testing.T) {
+	prev, next := loadTurns(t)
+
+	// The pump loads colonists at the homeworld, flies to Rubber and drops
+	// them on arrival: it carried none on the previous turn
+	pump, rubber := rubberPump(t, prev, next)
+	home, ok := prev.PlanetByName("Hurl")
+	if !ok {
+		t.Fatal("Homeworld not found")
+	}
+	pump.X, pump.Y, pump.PositionObjectId = home.X, home.Y, home.PlanetNumber
+	load := pump.Waypoints[0]
+	load.X, load.Y, load.Task = home.X, home.Y, blocks.WaypointTaskTransport
+	load.TransportOrders[blocks.CargoColonists] = blocks.TransportOrder{Action: blocks.TransportTaskLoadAll}
+	now, _ := next.Fleet(0, 11)
+	now.X, now.Y = rubber.X, rubber.Y
+	rubber.SetPopulation(rubber.Population + 10_000_000)
+
+	if got := CheckHistory([]*store.GameStore{prev, next}).CountByType(ExploitPopDrop); got != 0 {
+		t.Errorf("Expected colonists loaded on the way to be accepted, got %d", got)
+	}
+
+	// Without the load order, nothing explains them
+	load.Task = blocks.WaypointTaskNone
+	if got := CheckHistory([]*store.GameStore{prev, next}).CountByType(ExploitPopDrop); got != 1 {
+		t.Errorf("Expected 1 pop drop, got %d", got)
+	}
+}
+
+func TestCheckHistory_PopBeyondCapacity(t *testing.T) {
+	prev, next := loadTurns(t)
+
+	before, target := ownedPlanet(t, prev, next, false)
+	player, ok := next.Player(0)
+	if !ok {
+		t.Fatal("Player 1 not found")
+	}
+	capacity := int64(next.MaxPopulation(target, player))
+	if capacity <= 0 {
+		t.Skip("Planet has no capacity")
+	}
+
+	// A full planet does not grow: 10% more is within growth only below
+	// capacity
+	before.SetPopulation(capacity)
+	target.SetPopulation(capacity + capacity/10)
+	if got := CheckHistory([]*store.GameStore{prev, next}).CountByType(ExploitPopDrop); got != 1 {
+		t.Errorf("Expected growth beyond capacity to be flagged, got %d", got)
+	}
+
+	before.SetPopulation(capacity / 2)
+	target.SetPopulation(capacity/2 + capacity/20)
+	if got := CheckHistory([]*store.GameStore{prev, next}).CountByType(ExploitPopDrop); got != 0 {
+		t.Errorf("Expected growth below capacity to be accepted, got %d", got)
+	}
+}
+
+func TestCheckHistory_CargoOverCapacity(t *testing.T) {
+	prev, next := loadTurns(t)
+
+	var target *store.FleetEntity
+	for _, f := range next.AllFleets() {
+		if capacity, ok := fleetCargoCapacity(next, f); ok && f.Owner == 0 && capacity > 0 {
+			target = f
+			break
+		}
+	}
+	if target == nil {
+		t.Fatal("No freighter found")
+	}
+	capacity, _ := fleetCargoCapacity(next, target)
+	target.SetCargo(store.Cargo{Ironium: capacity + 1})
+
+	result := CheckHistory([]*store.GameStore{prev, next})
+	if got := result.CountByType(ExploitImpossibleCargo); got != 1 {
+		t.Errorf("Expected 1 impossible cargo detection, got %d", got)
+	}
+}
@@ -0,0 +1,142 @@
+package exploits
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/reproducible"
+)
+
+// Report is a shareable record of a scan, listing the scanned files and the
+// findings per player and turn. A signed report lets a host prove which files
+// were scanned and that the findings were not edited afterwards.
+type Report struct {
+	Generated time.Time    `json:"generated"`
+	Files     []ReportFile `json:"files"`
+	Findings  []Finding    `json:"findings"`
+	Signature string       `json:"signature,omitempty"` // Hex HMAC-SHA256 of the unsigned report
+}
+
+// ReportFile identifies a scanned file.
+type ReportFile struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	GameID uint32 `json:"game_id"`
+	Year   int    `json:"year"`
+	Player int    `json:"player,omitempty"` // Player number (1-16), 0 for the host file
+}
+
+// Finding is a detection in a report.
+type Finding struct {
+	File        string `json:"file,omitempty"` // Empty for checks spanning several turns
+	Player      int    `json:"player"`         // Player number (1-16)
+	Year        int    `json:"year"`
+	Exploit     string `json:"exploit"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+	Details     string `json:"details,omitempty"`
+	Fixable     bool   `json:"fixable"`
+	Fixed       bool   `json:"fixed"`
+}
+
+// ErrBadSignature is returned when a report does not match its signature.
+var ErrBadSignature = errors.New("report signature does not match")
+
+// NewReport creates an empty report.
+func NewReport() *Report {
+	return &Report{
+		Generated: reproducible.Now().Truncate(time.Second),
+		Files:     []ReportFile{},
+		Findings:  []Finding{},
+	}
+}
+
+// AddFile records a scanned file and the detections found in it.
+func (r *Report) AddFile(name string, data []byte, detections []*Detection) error {
+	header, err := parser.FileData(data).FileHeader()
+	if err != nil {
+		return fmt.Errorf("failed to parse header of %s: %w", name, err)
+	}
+	sum := sha256.Sum256(data)
+	file := ReportFile{
+		Name:   name,
+		SHA256: hex.EncodeToString(sum[:]),
+		GameID: header.GameID,
+		Year:   header.Year(),
+	}
+	if player := header.PlayerIndex(); player < 16 {
+		file.Player = player + 1
+	}
+	r.Files = append(r.Files, file)
+	for _, d := range detections {
+		r.addFinding(name, header.Year(), d)
+	}
+	return nil
+}
+
+// AddDetections records detections not tied to a file, such as those of
+// CheckHistory, under the year they carry.
+func (r *Report) AddDetections(detections []*Detection) {
+	for _, d := range detections {
+		r.addFinding("", d.Year, d)
+	}
+}
+
+func (r *Report) addFinding(file string, year int, d *Detection) {
+	r.Findings = append(r.Findings, Finding{
+		File:        file,
+		Player:      d.Player + 1,
+		Year:        year,
+		Exploit:     d.Type.String(),
+		Severity:    d.Severity.String(),
+		Description: d.Description,
+		Details:     d.Details,
+		Fixable:     d.CanFix,
+		Fixed:       d.FixApplied,
+	})
+}
+
+// Sign sets the report signature for a shared key.
+func (r *Report) Sign(key []byte) error {
+	mac, err := r.mac(key)
+	if err != nil {
+		return err
+	}
+	r.Signature = hex.EncodeToString(mac)
+	return nil
+}
+
+// Verify checks the report signature against a shared key.
+func (r *Report) Verify(key []byte) error {
+	want, err := hex.DecodeString(r.Signature)
+	if err != nil || len(want) == 0 {
+		return ErrBadSignature
+	}
+	mac, err := r.mac(key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(mac, want) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// mac computes the HMAC of the report encoded without its signature.
+func (r *Report) mac(key []byte) ([]byte, error) {
+	unsigned := *r
+	unsigned.Signature = ""
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil), nil
+}
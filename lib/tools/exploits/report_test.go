@@ -0,0 +1,83 @@
+package exploits
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestReport_AddFile(t *testing.T) {
+	testFile := "../../../testdata/scenario-basic/game.m1"
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Skipf("Test file not found: %s", testFile)
+	}
+
+	r := NewReport()
+	detection := &Detection{
+		Type:        ExploitFriendlyFire,
+		Severity:    SeverityWarning,
+		Player:      0,
+		Description: "Default battle plan attacks allies",
+		CanFix:      true,
+	}
+	if err := r.AddFile("game.m1", data, []*Detection{detection}); err != nil {
+		t.Fatalf("AddFile failed: %v", err)
+	}
+
+	if len(r.Files) != 1 || len(r.Findings) != 1 {
+		t.Fatalf("Expected 1 file and 1 finding, got %d and %d", len(r.Files), len(r.Findings))
+	}
+	file := r.Files[0]
+	if len(file.SHA256) != 64 || file.Player != 1 || file.Year < 2400 {
+		t.Errorf("Unexpected file entry: %+v", file)
+	}
+	finding := r.Findings[0]
+	if finding.Player != 1 || finding.Year != file.Year || finding.Exploit != "Friendly Fire" || !finding.Fixable {
+		t.Errorf("Unexpected finding: %+v", finding)
+	}
+
+	r.AddDetections([]*Detection{{Type: ExploitPopDrop, Player: 1, Year: 2420}})
+	if got := r.Findings[1]; got.File != "" || got.Player != 2 || got.Year != 2420 || got.Exploit != "Pop Drop" {
+		t.Errorf("Unexpected history finding: %+v", got)
+	}
+
+	if err := r.AddFile("bad.m1", []byte{1, 2, 3}, nil); err == nil {
+		t.Error("Expected an error for a file without a header")
+	}
+}
+
+func TestReport_Sign(t *testing.T) {
+	r := NewReport()
+	r.Findings = append(r.Findings, Finding{File: "game.x2", Player: 2, Year: 2410, Exploit: "32k Merge"})
+
+	key := []byte("forum secret")
+	if err := r.Sign(key); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if err := r.Verify(key); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+
+	// The signature survives a JSON round trip
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := decoded.Verify(key); err != nil {
+		t.Errorf("Verify after round trip failed: %v", err)
+	}
+
+	if err := decoded.Verify([]byte("other key")); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Expected ErrBadSignature for a wrong key, got %v", err)
+	}
+	decoded.Findings[0].Player = 3
+	if err := decoded.Verify(key); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Expected ErrBadSignature for an edited report, got %v", err)
+	}
+}
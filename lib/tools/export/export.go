@@ -0,0 +1,178 @@
+// Package export turns the game state of a GameStore into flat tables, one
+// per kind of entity, for spreadsheets and scripts that do not read JSON.
+//
+// Columns are the JSON field names of store.GameState. Nested structs are
+// flattened with an underscore (tech_energy, hab_gravity_center); lists
+// such as ship counts, waypoints or design slots are written as JSON in a
+// single cell.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/neper-stars/houston/store"
+)
+
+var (
+	// ErrUnknownEntity is returned for an entity name not in Entities.
+	ErrUnknownEntity = errors.New("unknown entity")
+	// ErrNotTable is returned by TableOf for rows that are not a slice of
+	// structs.
+	ErrNotTable = errors.New("rows are not a table")
+)
+
+// Entities are the kinds of entities that can be exported, in output order.
+var Entities = []string{"players", "planets", "fleets", "designs", "minefields", "wormholes", "messages", "scores"}
+
+// Score is a row of the scores table: the score of a player as computed by
+// Stars!, when the files hold it.
+type Score struct {
+	Player int `json:"player"`
+	store.ScoreState
+}
+
+// Table is one kind of entity of a game state as rows of text.
+type Table struct {
+	Name   string
+	Header []string
+	Rows   [][]string
+}
+
+// Select returns the entities of a kind as a slice of their state structs,
+// e.g. []store.PlanetState for "planets".
+func Select(state *store.GameState, entity string) (any, error) {
+	if entity == "scores" {
+		scores := make([]Score, 0, len(state.Players))
+		for _, p := range state.Players {
+			if p.Score != nil {
+				scores = append(scores, Score{Player: p.Number, ScoreState: *p.Score})
+			}
+		}
+		return scores, nil
+	}
+	v := reflect.ValueOf(state).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if jsonName(v.Type().Field(i)) == entity && v.Field(i).Kind() == reflect.Slice {
+			return v.Field(i).Interface(), nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q (want one of %s)", ErrUnknownEntity, entity, strings.Join(Entities, ", "))
+}
+
+// NewTable returns the entities of a kind as a table.
+func NewTable(state *store.GameState, entity string) (*Table, error) {
+	rows, err := Select(state, entity)
+	if err != nil {
+		return nil, err
+	}
+	return TableOf(entity, rows)
+}
+
+// TableOf returns a slice of structs as a table, with the columns named
+// and flattened like those of the game state.
+func TableOf(name string, rows any) (*Table, error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T is not a slice of structs", ErrNotTable, rows)
+	}
+	cols := columns(v.Type().Elem(), "", nil)
+
+	t := &Table{Name: name, Header: make([]string, len(cols)), Rows: make([][]string, 0, v.Len())}
+	for i, c := range cols {
+		t.Header[i] = c.name
+	}
+	for i := 0; i < v.Len(); i++ {
+		row := make([]string, len(cols))
+		for j, c := range cols {
+			row[j] = cell(v.Index(i), c.index)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
+
+// WriteCSV writes the table as CSV with a header line.
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(t.Rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// column is a flattened field of a row struct.
+type column struct {
+	name  string
+	index []int
+}
+
+// columns lists the flattened fields of a struct type.
+func columns(t reflect.Type, prefix string, index []int) []column {
+	var cols []column
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := jsonName(f)
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		idx := append(append([]int{}, index...), i)
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		switch {
+		case f.Anonymous && ft.Kind() == reflect.Struct:
+			cols = append(cols, columns(ft, prefix, idx)...)
+		case ft.Kind() == reflect.Struct:
+			cols = append(cols, columns(ft, prefix+name+"_", idx)...)
+		default:
+			cols = append(cols, column{name: prefix + name, index: idx})
+		}
+	}
+	return cols
+}
+
+// cell formats a field of a row, empty when it sits behind a nil pointer.
+func cell(row reflect.Value, index []int) string {
+	v, err := row.FieldByIndexErr(index)
+	if err != nil {
+		return ""
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.String:
+		return v.String()
+	case reflect.Slice, reflect.Map, reflect.Pointer:
+		if v.IsNil() {
+			return ""
+		}
+	}
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
@@ -0,0 +1,141 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func loadState(t *testing.T, dir string, files ...string) *store.GameState {
+	t.Helper()
+	gs := store.New()
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join("../../../testdata", dir, name))
+		require.NoError(t, err)
+		require.NoError(t, gs.AddFile(name, data))
+	}
+	return gs.ExportState()
+}
+
+func TestNewTablePlanets(t *testing.T) {
+	state := loadState(t, "scenario-minefield", "game.xy", "game.m1")
+
+	table, err := NewTable(state, "planets")
+	require.NoError(t, err)
+	require.Len(t, table.Rows, len(state.Planets))
+
+	name := slices.Index(table.Header, "name")
+	owner := slices.Index(table.Header, "owner")
+	pop := slices.Index(table.Header, "population")
+	require.True(t, name >= 0 && owner >= 0 && pop >= 0)
+	for i, p := range state.Planets {
+		assert.Equal(t, p.Name, table.Rows[i][name])
+		assert.Equal(t, strconv.Itoa(p.Owner), table.Rows[i][owner])
+		assert.Equal(t, strconv.FormatInt(p.Population, 10), table.Rows[i][pop])
+	}
+}
+
+func TestNewTableFlattens(t *testing.T) {
+	state := loadState(t, "scenario-minefield", "game.xy", "game.m1")
+
+	players, err := NewTable(state, "players")
+	require.NoError(t, err)
+	assert.Contains(t, players.Header, "tech_energy")
+	assert.Contains(t, players.Header, "hab_gravity_center")
+	assert.Contains(t, players.Header, "score_rank")
+
+	fleets, err := NewTable(state, "fleets")
+	require.NoError(t, err)
+	counts := slices.Index(fleets.Header, "ship_counts")
+	require.GreaterOrEqual(t, counts, 0)
+	assert.Regexp(t, `^\[\d+(,\d+){15}\]$`, fleets.Rows[0][counts])
+
+	scores, err := NewTable(state, "scores")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"player", "score", "resources"}, scores.Header[:3])
+}
+
+func TestWriteCSV(t *testing.T) {
+	state := loadState(t, "scenario-wormhole", "game.xy", "game.m1")
+
+	for _, entity := range Entities {
+		table, err := NewTable(state, entity)
+		require.NoError(t, err, entity)
+
+		var buf bytes.Buffer
+		require.NoError(t, table.WriteCSV(&buf))
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err, entity)
+		require.Len(t, records, len(table.Rows)+1, entity)
+		assert.Equal(t, table.Header, records[0])
+	}
+}
+
+func TestSelectUnknown(t *testing.T) {
+	_, err := Select(&store.GameState{}, "nebulae")
+	assert.ErrorIs(t, err, ErrUnknownEntity)
+}
+
+func TestTableOf(t *testing.T) {
+	type row struct {
+		Year int `json:"year"`
+		Tech struct {
+			Energy int `json:"energy"`
+		} `json:"tech"`
+	}
+	rows := []row{{Year: 2400}, {Year: 2401}}
+	rows[1].Tech.Energy = 3
+
+	table, err := TableOf("stats", rows)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"year", "tech_energy"}, table.Header)
+	assert.Equal(t, [][]string{{"2400", "0"}, {"2401", "3"}}, table.Rows)
+
+	_, err = TableOf("stats", 42)
+	assert.ErrorIs(t, err, ErrNotTable)
+}
+
+func TestWriteXLSX(t *testing.T) {
+	planets := &Table{Name: "planets", Header: []string{"name", "population"}, Rows: [][]string{{"Sol & Co", "12500"}, {"Rigel", ""}}}
+	fleets := &Table{Name: "fleets/ships", Header: []string{"name"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteXLSX(&buf, planets, fleets))
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		parts[f.Name] = string(data)
+	}
+
+	require.Contains(t, parts, "[Content_Types].xml")
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="planets" sheetId="1" r:id="rId1"/>`)
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="fleets_ships"`, "sheet names drop the characters spreadsheets reject")
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="B1" t="inlineStr"><is><t xml:space="preserve">population</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">Sol &amp; Co</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="B2"><v>12500</v></c>`, "numbers are numeric cells")
+	assert.NotContains(t, sheet, `r="B3"`, "empty cells are left out")
+	assert.Contains(t, parts, "xl/worksheets/sheet2.xml")
+}
+
+func TestColumnName(t *testing.T) {
+	for index, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, name, columnName(index), strconv.Itoa(index))
+	}
+}
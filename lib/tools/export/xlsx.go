@@ -0,0 +1,158 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxSheetName is the longest sheet name spreadsheets accept.
+const maxSheetName = 31
+
+const (
+	contentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`
+	sheetContentTypeXML = `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`
+	rootRelsXML         = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	workbookXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`
+	workbookRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`
+	sheetRelXML  = `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`
+	worksheetXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+)
+
+// WriteXLSX writes tables as an Excel workbook, one sheet per table named
+// after it, with the header as the first row. Cells holding a number are
+// written as numbers so that spreadsheets can chart and sum them.
+func WriteXLSX(w io.Writer, tables ...*Table) error {
+	parts := []part{
+		{"[Content_Types].xml", contentTypes(len(tables))},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", workbook(tables)},
+		{"xl/_rels/workbook.xml.rels", workbookRels(len(tables))},
+	}
+	for i, t := range tables {
+		parts = append(parts, part{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheet(t)})
+	}
+
+	zw := zip.NewWriter(w)
+	for _, p := range parts {
+		fw, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, p.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// part is a file of a workbook.
+type part struct {
+	name    string
+	content string
+}
+
+func contentTypes(sheets int) string {
+	var b strings.Builder
+	b.WriteString(contentTypesXML)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, sheetContentTypeXML, i)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+func workbook(tables []*Table) string {
+	var b strings.Builder
+	b.WriteString(workbookXML)
+	used := make(map[string]bool)
+	for i, t := range tables {
+		name := sheetName(t.Name, i+1, used)
+		b.WriteString(`<sheet name="`)
+		writeEscaped(&b, name)
+		fmt.Fprintf(&b, `" sheetId="%d" r:id="rId%d"/>`, i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+func workbookRels(sheets int) string {
+	var b strings.Builder
+	b.WriteString(workbookRelsXML)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, sheetRelXML, i, i)
+	}
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+func worksheet(t *Table) string {
+	var b strings.Builder
+	b.WriteString(worksheetXML)
+	rows := append([][]string{t.Header}, t.Rows...)
+	for r, row := range rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, value := range row {
+			if value == "" {
+				continue
+			}
+			ref := columnName(c) + strconv.Itoa(r+1)
+			if n, err := strconv.ParseFloat(value, 64); err == nil && r > 0 && !math.IsInf(n, 0) && !math.IsNaN(n) {
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(n, 'g', -1, 64))
+				continue
+			}
+			fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			writeEscaped(&b, value)
+			b.WriteString(`</t></is></c>`)
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// sheetName returns a unique sheet name for a table, without the characters
+// spreadsheets reject.
+func sheetName(name string, index int, used map[string]bool) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > maxSheetName {
+		name = string(runes[:maxSheetName])
+	}
+	if name == "" || used[strings.ToLower(name)] {
+		name = fmt.Sprintf("Sheet%d", index)
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+// columnName returns the letters of a column: A to Z, then AA, AB...
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+func writeEscaped(b *strings.Builder, s string) {
+	_ = xml.EscapeText(b, []byte(s))
+}
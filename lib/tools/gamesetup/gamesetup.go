@@ -0,0 +1,133 @@
+// Package gamesetup decodes the options a game was created with.
+//
+// The universe settings, game options and victory conditions chosen in the
+// Stars! "New Game" dialog are stored in the PlanetsBlock of every XY and
+// host file as raw bitmasks and threshold indices. This package turns them
+// into named settings ready to print.
+//
+// Example usage:
+//
+//	setup := gamesetup.Describe(gs)
+//	fmt.Printf("%s universe, %s density\n", setup.UniverseSize, setup.Density)
+//	for _, vc := range setup.VictoryConditions {
+//	    if vc.Enabled {
+//	        fmt.Println(vc.Description)
+//	    }
+//	}
+package gamesetup
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+// optionFlags lists the game option bits in the order Stars! shows them.
+var optionFlags = []int{
+	data.GameSettingMaxMinerals,
+	data.GameSettingSlowTech,
+	data.GameSettingSinglePlayer,
+	data.GameSettingComputerAlliances,
+	data.GameSettingAcceleratedBBS,
+	data.GameSettingPublicScores,
+	data.GameSettingNoRandomEvents,
+	data.GameSettingGalaxyClumping,
+}
+
+// Option is one on/off game option.
+type Option struct {
+	Flag    int // data.GameSetting*
+	Name    string
+	Enabled bool
+}
+
+// VictoryCondition is one victory criterion and its threshold.
+type VictoryCondition struct {
+	Condition   data.VictoryCondition
+	Enabled     bool
+	Description string
+}
+
+// Setup is the decoded configuration of a game.
+type Setup struct {
+	GameID   uint32
+	GameName string
+	Year     int
+
+	UniverseSize     string
+	Density          string
+	StartingDistance string // Player positions
+	Players          int
+	Planets          int
+
+	Options []Option
+
+	VictoryConditions []VictoryCondition
+	CriteriaToWin     int // Number of enabled criteria a winner must meet
+	MinYearsToWin     int // Years that must pass before a winner is declared
+}
+
+// Enabled reports whether a game option is on.
+func (s *Setup) Enabled(flag int) bool {
+	for _, o := range s.Options {
+		if o.Flag == flag {
+			return o.Enabled
+		}
+	}
+	return false
+}
+
+// Describe decodes the game setup from a store holding an XY or host file.
+func Describe(gs *store.GameStore) *Setup {
+	s := &Setup{
+		GameID:           gs.GameID,
+		GameName:         strings.TrimRight(gs.GameName, "\x00 "),
+		Year:             int(gs.Turn) + blocks.StarsBaseYear,
+		UniverseSize:     gs.UniverseSizeName(),
+		Density:          gs.DensityName(),
+		StartingDistance: gs.StartingDistanceName(),
+		Players:          int(gs.PlayerCount),
+		Planets:          int(gs.PlanetCount),
+	}
+
+	for _, flag := range optionFlags {
+		s.Options = append(s.Options, Option{
+			Flag:    flag,
+			Name:    data.GameSettingNames[flag],
+			Enabled: gs.HasGameSetting(flag),
+		})
+	}
+	// Undocumented bit: only worth showing when a file actually sets it
+	if gs.HasGameSetting(data.GameSettingBit3) {
+		s.Options = append(s.Options, Option{
+			Flag:    data.GameSettingBit3,
+			Name:    data.GameSettingNames[data.GameSettingBit3],
+			Enabled: true,
+		})
+	}
+
+	vc := gs.VictoryConditions
+	s.VictoryConditions = []VictoryCondition{
+		{data.VictoryOwnsPercentPlanets, vc.OwnsPercentPlanetsEnabled,
+			fmt.Sprintf("Owns %d%% of all planets", vc.OwnsPercentPlanetsValue)},
+		{data.VictoryAttainsPercentTechLevels, vc.AttainTechLevelEnabled,
+			fmt.Sprintf("Attains tech level %d in %d fields", vc.AttainTechLevelValue, vc.AttainTechInYFields)},
+		{data.VictoryExceedScoreCondition, vc.ExceedScoreEnabled,
+			fmt.Sprintf("Exceeds a score of %d", vc.ExceedScoreValue)},
+		{data.VictoryExceedSecondPlace, vc.ExceedSecondPlaceEnabled,
+			fmt.Sprintf("Exceeds second place score by %d%%", vc.ExceedSecondPlaceValue)},
+		{data.VictoryProductionCapacity, vc.ProductionCapacityEnabled,
+			fmt.Sprintf("Has a production capacity of %d thousand", vc.ProductionCapacityValue)},
+		{data.VictoryOwnCapitalShips, vc.OwnCapitalShipsEnabled,
+			fmt.Sprintf("Owns %d capital ships", vc.OwnCapitalShipsValue)},
+		{data.VictoryHighestScoreYears, vc.HighestScoreYearsEnabled,
+			fmt.Sprintf("Has the highest score after %d years", vc.HighestScoreYearsValue)},
+	}
+	s.CriteriaToWin = vc.NumCriteriaMetValue
+	s.MinYearsToWin = vc.MinYearsBeforeWinValue
+
+	return s
+}
@@ -0,0 +1,64 @@
+package gamesetup
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+func loadXY(t *testing.T, path string) *store.GameStore {
+	t.Helper()
+	fileData, err := os.ReadFile(path)
+	require.NoError(t, err)
+	gs := store.New()
+	require.NoError(t, gs.AddFile(path, fileData))
+	return gs
+}
+
+func TestDescribe(t *testing.T) {
+	setup := Describe(loadXY(t, "../../../testdata/scenario-map/game.xy"))
+
+	assert.Equal(t, uint32(1161673766), setup.GameID)
+	assert.Equal(t, "Minefield01", setup.GameName)
+	assert.Equal(t, 2400, setup.Year)
+	assert.Equal(t, "Tiny", setup.UniverseSize)
+	assert.Equal(t, "Normal", setup.Density)
+	assert.Equal(t, "Moderate", setup.StartingDistance)
+	assert.Equal(t, 2, setup.Players)
+	assert.Equal(t, 32, setup.Planets)
+
+	assert.True(t, setup.Enabled(data.GameSettingMaxMinerals))
+	assert.False(t, setup.Enabled(data.GameSettingNoRandomEvents))
+	assert.False(t, setup.Enabled(data.GameSettingPublicScores))
+	assert.Len(t, setup.Options, 8, "unset unknown bit is not listed")
+
+	var enabled []string
+	for _, vc := range setup.VictoryConditions {
+		if vc.Enabled {
+			enabled = append(enabled, vc.Description)
+		}
+	}
+	assert.Equal(t, []string{
+		"Owns 60% of all planets",
+		"Attains tech level 22 in 4 fields",
+		"Exceeds second place score by 100%",
+	}, enabled)
+	assert.Equal(t, 2, setup.CriteriaToWin)
+	assert.Equal(t, 100, setup.MinYearsToWin)
+}
+
+func TestDescribe_DefaultOptions(t *testing.T) {
+	setup := Describe(loadXY(t, "../../../testdata/scenario-basic/game.xy"))
+
+	assert.Equal(t, "TestBed01", setup.GameName)
+	for _, o := range setup.Options {
+		assert.False(t, o.Enabled, o.Name)
+	}
+	assert.Equal(t, 1, setup.CriteriaToWin)
+	assert.Equal(t, 50, setup.MinYearsToWin)
+}
@@ -0,0 +1,231 @@
+// Package handicap applies per-player handicaps to the host file of a new
+// game, so that players of different skill can share a league.
+//
+// The handicaps are listed in a JSON manifest kept with the game, which
+// records what each player was given:
+//
+//	{
+//	  "game_id": 1234567,
+//	  "players": [
+//	    {"player": 1, "minerals": 200, "tech": 1, "note": "newcomer"},
+//	    {"player": 4, "population_percent": 20, "note": "last season's winner"}
+//	  ]
+//	}
+//
+// Houston does not create games: the handicaps are applied to the host file
+// Stars! writes for the first year, before any turn is generated.
+//
+// Example usage:
+//
+//	m, _ := handicap.LoadManifest("handicaps.json")
+//	data, _ := os.ReadFile("game.hst")
+//	modified, changes, err := handicap.ApplyBytes(data, m)
+package handicap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+// MaxPopulationPercent is the largest share of the starting population a
+// handicap can remove.
+const MaxPopulationPercent = 90
+
+// Handicap is the bonus or penalty given to one player.
+type Handicap struct {
+	Player int `json:"player"` // Player number (1-16)
+
+	// Minerals is added to each of ironium, boranium and germanium on the
+	// player's homeworld, in kT.
+	Minerals int64 `json:"minerals,omitempty"`
+
+	// Tech is added to every technology field, capped at the player's
+	// TechCap (9 for shareware players).
+	Tech int `json:"tech,omitempty"`
+
+	// PopulationPercent is the share of the homeworld population removed.
+	PopulationPercent int `json:"population_percent,omitempty"`
+
+	Note string `json:"note,omitempty"`
+}
+
+// Manifest lists the handicaps of a game.
+type Manifest struct {
+	GameID  uint32     `json:"game_id,omitempty"` // When set, the host file must belong to this game
+	Players []Handicap `json:"players"`
+}
+
+// Change records a handicap applied to a player.
+type Change struct {
+	Player    int // Player index (0-15)
+	Name      string
+	Planet    int    // Homeworld planet number
+	Homeworld string // Homeworld name, empty without the XY file
+
+	Minerals         int64 // kT added to each mineral
+	TechBefore       store.TechLevels
+	TechAfter        store.TechLevels
+	PopulationBefore int64
+	PopulationAfter  int64
+	Note             string
+}
+
+// ParseManifest decodes and validates a JSON manifest.
+func ParseManifest(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// LoadManifest reads and validates a JSON manifest file.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return ParseManifest(data)
+}
+
+// Validate checks the handicap values. Each player may appear only once.
+func (m *Manifest) Validate() error {
+	seen := make(map[int]bool)
+	for _, h := range m.Players {
+		if h.Player < 1 || h.Player > 16 {
+			return fmt.Errorf("invalid player %d (must be 1-16)", h.Player)
+		}
+		if seen[h.Player] {
+			return fmt.Errorf("player %d is listed more than once", h.Player)
+		}
+		seen[h.Player] = true
+		if h.Minerals < 0 {
+			return fmt.Errorf("player %d: minerals must not be negative", h.Player)
+		}
+		if h.Tech < 0 || h.Tech > store.MaxTechLevel {
+			return fmt.Errorf("player %d: tech must be 0-%d", h.Player, store.MaxTechLevel)
+		}
+		if h.PopulationPercent < 0 || h.PopulationPercent > MaxPopulationPercent {
+			return fmt.Errorf("player %d: population_percent must be 0-%d", h.Player, MaxPopulationPercent)
+		}
+	}
+	return nil
+}
+
+// Apply applies the manifest to a store holding the host file of a new game.
+// Nothing is changed when an error is returned.
+func Apply(gs *store.GameStore, m *Manifest) ([]Change, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	if gs.Turn != 0 {
+		return nil, fmt.Errorf("handicaps apply to a new game, but the file is from year %d", blocks.StarsBaseYear+int(gs.Turn))
+	}
+	if m.GameID != 0 && gs.GameID != m.GameID {
+		return nil, fmt.Errorf("manifest is for game %d, file is game %d: %w", m.GameID, gs.GameID, store.ErrGameIDMismatch)
+	}
+
+	type target struct {
+		player *store.PlayerEntity
+		home   *store.PlanetEntity
+	}
+	targets := make([]target, len(m.Players))
+	for i, h := range m.Players {
+		player, ok := gs.Player(h.Player - 1)
+		if !ok || !player.HasFullData {
+			return nil, fmt.Errorf("player %d not found", h.Player)
+		}
+		home, ok := homeworld(gs, player)
+		if !ok {
+			return nil, fmt.Errorf("player %d has no homeworld", h.Player)
+		}
+		targets[i] = target{player, home}
+	}
+
+	changes := make([]Change, 0, len(m.Players))
+	for i, h := range m.Players {
+		player, home := targets[i].player, targets[i].home
+		change := Change{
+			Player:           player.PlayerNumber,
+			Name:             player.NamePlural,
+			Planet:           home.PlanetNumber,
+			Homeworld:        home.Name,
+			Minerals:         h.Minerals,
+			TechBefore:       player.Tech,
+			TechAfter:        player.Tech,
+			PopulationBefore: home.Population,
+			PopulationAfter:  home.Population,
+			Note:             h.Note,
+		}
+
+		if h.Minerals > 0 {
+			minerals := home.GetMinerals()
+			minerals.Ironium += h.Minerals
+			minerals.Boranium += h.Minerals
+			minerals.Germanium += h.Minerals
+			home.SetMinerals(minerals)
+		}
+
+		if h.Tech > 0 {
+			tech := player.Tech
+			for _, level := range []*int{&tech.Energy, &tech.Weapons, &tech.Propulsion, &tech.Construction, &tech.Electronics, &tech.Biotech} {
+				*level = min(*level+h.Tech, player.TechCap())
+			}
+			if err := player.SetTechLevels(tech); err != nil {
+				return nil, fmt.Errorf("player %d: %w", h.Player, err)
+			}
+			change.TechAfter = tech
+		}
+
+		if h.PopulationPercent > 0 {
+			// Population is stored in 100s of colonists
+			pop := home.Population * int64(100-h.PopulationPercent) / 100
+			pop -= pop % 100
+			home.SetPopulation(pop)
+			change.PopulationAfter = pop
+		}
+
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// ApplyBytes applies the manifest to the bytes of a host file and returns
+// the modified file.
+func ApplyBytes(data []byte, m *Manifest) ([]byte, []Change, error) {
+	gs := store.New()
+	if err := gs.AddFile("game.hst", data); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	changes, err := Apply(gs, m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	modified, err := gs.RegenerateHSTFile()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to regenerate file: %w", err)
+	}
+	return modified, changes, nil
+}
+
+// homeworld returns the homeworld of a player.
+func homeworld(gs *store.GameStore, player *store.PlayerEntity) (*store.PlanetEntity, bool) {
+	if p, ok := gs.Planet(player.HomePlanetID); ok && p.IsHomeworld && p.Owner == player.PlayerNumber {
+		return p, true
+	}
+	for _, p := range gs.PlanetsByOwner(player.PlayerNumber) {
+		if p.IsHomeworld {
+			return p, true
+		}
+	}
+	return nil, false
+}
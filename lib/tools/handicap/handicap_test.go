@@ -0,0 +1,91 @@
+package handicap
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+const newGame = "../../../testdata/scenario-map/history/game-2400.hst"
+
+func TestParseManifest(t *testing.T) {
+	m, err := ParseManifest([]byte(`{"game_id": 42, "players": [{"player": 2, "minerals": 100, "tech": 1, "note": "new"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(42), m.GameID)
+	assert.Equal(t, []Handicap{{Player: 2, Minerals: 100, Tech: 1, Note: "new"}}, m.Players)
+
+	for _, bad := range []string{
+		`{"players": [{"player": 0}]}`,
+		`{"players": [{"player": 1}, {"player": 1}]}`,
+		`{"players": [{"player": 1, "minerals": -5}]}`,
+		`{"players": [{"player": 1, "tech": 27}]}`,
+		`{"players": [{"player": 1, "population_percent": 95}]}`,
+		`{"players": `,
+	} {
+		_, err := ParseManifest([]byte(bad))
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestApplyBytes(t *testing.T) {
+	data, err := os.ReadFile(newGame)
+	require.NoError(t, err)
+
+	before := store.New()
+	require.NoError(t, before.AddFile("game.hst", data))
+	player, ok := before.Player(0)
+	require.True(t, ok)
+	home, ok := homeworld(before, player)
+	require.True(t, ok)
+
+	m := &Manifest{GameID: before.GameID, Players: []Handicap{
+		{Player: 1, Minerals: 150, Tech: 2, PopulationPercent: 25},
+	}}
+	modified, changes, err := ApplyBytes(data, m)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, 0, changes[0].Player)
+	assert.Equal(t, home.Name, changes[0].Homeworld)
+
+	after := store.New()
+	require.NoError(t, after.AddFile("game.hst", modified))
+	edited, ok := after.Planet(home.PlanetNumber)
+	require.True(t, ok)
+	assert.Equal(t, home.Ironium+150, edited.Ironium)
+	assert.Equal(t, home.Boranium+150, edited.Boranium)
+	assert.Equal(t, home.Germanium+150, edited.Germanium)
+	assert.Equal(t, changes[0].PopulationAfter, edited.Population)
+	assert.Less(t, edited.Population, home.Population)
+	assert.Zero(t, edited.Population%100)
+
+	p, ok := after.Player(0)
+	require.True(t, ok)
+	assert.Equal(t, min(player.Tech.Energy+2, store.MaxTechLevel), p.Tech.Energy)
+	assert.Equal(t, min(player.Tech.Biotech+2, store.MaxTechLevel), p.Tech.Biotech)
+	assert.Equal(t, changes[0].TechAfter, p.Tech)
+
+	// Other players are untouched
+	other, ok := after.Player(1)
+	require.True(t, ok)
+	original, _ := before.Player(1)
+	assert.Equal(t, original.Tech, other.Tech)
+}
+
+func TestApply_Rejects(t *testing.T) {
+	gs := store.New()
+	require.NoError(t, gs.AddFileWithXY(newGame))
+
+	_, err := Apply(gs, &Manifest{GameID: gs.GameID + 1, Players: []Handicap{{Player: 1, Tech: 1}}})
+	assert.ErrorIs(t, err, store.ErrGameIDMismatch)
+
+	_, err = Apply(gs, &Manifest{Players: []Handicap{{Player: 16, Tech: 1}}})
+	assert.Error(t, err)
+
+	gs.Turn = 1
+	_, err = Apply(gs, &Manifest{Players: []Handicap{{Player: 1, Tech: 1}}})
+	assert.Error(t, err)
+}
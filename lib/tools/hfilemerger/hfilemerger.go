@@ -29,6 +29,7 @@ import (
 
 	"github.com/neper-stars/houston/blocks"
 	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/store"
 )
 
 // FileEntry represents a single file's data.
@@ -234,6 +235,7 @@ func (m *Merger) Merge() (*MergeResult, error) {
 		HEntriesProcessed: len(m.hNames),
 		MEntriesProcessed: len(m.mNames),
 		PlanetsMerged:     len(m.planets),
+		Warnings:          m.storeWarnings(),
 	}
 
 	// Count designs
@@ -253,6 +255,23 @@ func (m *Merger) Merge() (*MergeResult, error) {
 	return result, nil
 }
 
+// storeWarnings reports what the M files disagree on, such as a design
+// slot holding different designs. H files hold planets as last seen, so
+// they are not compared.
+func (m *Merger) storeWarnings() []string {
+	gs := store.New()
+	var warnings []string
+	for _, name := range m.mNames {
+		if err := gs.AddFile(name, m.entries[name].OriginalData); err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	for _, w := range gs.Warnings() {
+		warnings = append(warnings, w.String())
+	}
+	return warnings
+}
+
 // GetMergedData returns the merged data for a specific H entry.
 // Note: Currently returns original data as block encoding is not yet implemented.
 func (m *Merger) GetMergedData(name string) ([]byte, error) {
@@ -0,0 +1,160 @@
+// Package hfilepruner shrinks H (history) files.
+//
+// An H file keeps the score of every player for every year and the last
+// known state of every planet ever seen. In long games the score records
+// make most of the file, and the Stars! client reads them all each time it
+// loads the game. Prune drops score records older than a retention period,
+// optionally keeping a sample of them so the score graphs keep their shape,
+// drops planet records not refreshed for a long time, and removes duplicated
+// records. The result is re-encrypted as a valid H file.
+//
+// Like the other tools of the library, it works in memory:
+//
+//	pruned, stats, err := hfilepruner.Prune(data, hfilepruner.Policy{
+//		ScoreYears:    50,
+//		ScoreInterval: 10,
+//	})
+package hfilepruner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/encoding"
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/store"
+)
+
+// ErrNotHFile is returned when the data is not an H file.
+var ErrNotHFile = errors.New("not an H file")
+
+// Policy sets what to keep in the file. The zero Policy only removes
+// duplicated records.
+type Policy struct {
+	// ScoreYears keeps the score records of the last ScoreYears years;
+	// 0 keeps them all.
+	ScoreYears int
+
+	// ScoreInterval keeps, among the older score records, those of one year
+	// out of ScoreInterval (2410, 2420, ... for 10); 0 drops them all.
+	ScoreInterval int
+
+	// PlanetYears drops the planet records last seen more than PlanetYears
+	// years ago, which the client then shows as unexplored; 0 keeps them
+	// all. Records without a turn number are always kept.
+	PlanetYears int
+}
+
+// Stats sums up what Prune removed.
+type Stats struct {
+	SizeBefore, SizeAfter int // Bytes
+	ScoresDropped         int
+	PlanetsDropped        int
+	DuplicatesDropped     int
+}
+
+// Prune returns the H file data pruned according to the policy.
+func Prune(data []byte, policy Policy) ([]byte, Stats, error) {
+	stats := Stats{SizeBefore: len(data)}
+
+	fd := parser.FileData(data)
+	header, err := fd.FileHeader()
+	if err != nil {
+		return nil, stats, err
+	}
+	if header.FileType != blocks.FileTypeH {
+		return nil, stats, fmt.Errorf("%w: file type %d", ErrNotHFile, header.FileType)
+	}
+	blockList, err := fd.BlockList()
+	if err != nil {
+		return nil, stats, fmt.Errorf("failed to parse blocks: %w", err)
+	}
+
+	// Duplicated records keep the last score of a player for a turn and the
+	// latest record of a planet
+	keep := make([]bool, len(blockList))
+	lastScore := make(map[[2]int]int) // player, turn -> block index
+	lastPlanet := make(map[int]int)   // planet number -> block index
+	for i, block := range blockList {
+		keep[i] = true
+		switch b := block.(type) {
+		case blocks.PlayerScoresBlock:
+			key := [2]int{b.PlayerID, b.Turn}
+			if j, ok := lastScore[key]; ok {
+				keep[j] = false
+				stats.DuplicatesDropped++
+			}
+			lastScore[key] = i
+		case blocks.PartialPlanetBlock:
+			j, ok := lastPlanet[b.PlanetNumber]
+			if !ok {
+				lastPlanet[b.PlanetNumber] = i
+				continue
+			}
+			stats.DuplicatesDropped++
+			if blockList[j].(blocks.PartialPlanetBlock).Turn > b.Turn {
+				keep[i] = false
+				continue
+			}
+			keep[j] = false
+			lastPlanet[b.PlanetNumber] = i
+		}
+	}
+
+	fileTurn := int(header.Turn)
+	for i, block := range blockList {
+		if !keep[i] {
+			continue
+		}
+		switch b := block.(type) {
+		case blocks.PlayerScoresBlock:
+			if !keepScore(b.Turn, fileTurn, policy) {
+				keep[i] = false
+				stats.ScoresDropped++
+			}
+		case blocks.PartialPlanetBlock:
+			if policy.PlanetYears > 0 && b.HasTurn && fileTurn-b.Turn > policy.PlanetYears {
+				keep[i] = false
+				stats.PlanetsDropped++
+			}
+		}
+	}
+
+	planets := 0
+	for i, block := range blockList {
+		if _, ok := block.(blocks.PartialPlanetBlock); ok && keep[i] {
+			planets++
+		}
+	}
+
+	writer := store.NewFileWriter()
+	out := writer.WriteHeader(header)
+	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), header.SharewareFlag())
+	for i, block := range blockList {
+		typeID := block.BlockTypeID()
+		if !keep[i] || typeID == blocks.FileHeaderBlockType || typeID == blocks.FileFooterBlockType {
+			continue
+		}
+		decrypted := block.DecryptedData()
+		if typeID == blocks.CountersBlockType && len(decrypted) >= 2 {
+			// The counters give the number of planet records to read
+			decrypted = append([]byte(nil), decrypted...)
+			encoding.Write16(decrypted, 0, uint16(planets))
+		}
+		out = append(out, writer.WriteEncryptedBlock(typeID, decrypted)...)
+	}
+	out = append(out, writer.WriteFooter(false, 0)...)
+
+	stats.SizeAfter = len(out)
+	return out, stats, nil
+}
+
+// keepScore returns true if the policy keeps the score record of a turn, in
+// a file of the given turn.
+func keepScore(turn, fileTurn int, policy Policy) bool {
+	if policy.ScoreYears <= 0 || fileTurn-turn < policy.ScoreYears {
+		return true
+	}
+	return policy.ScoreInterval > 0 && (turn+2400)%policy.ScoreInterval == 0
+}
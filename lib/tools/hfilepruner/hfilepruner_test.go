@@ -0,0 +1,74 @@
+package hfilepruner
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/parser"
+)
+
+// The H file of year 2481 holds the scores of years 2401-2481 and 180
+// planets, some last seen in the 2410s.
+const hFile = "../../../testdata/scenario-orders/waypoint-load/game.h1"
+
+func records(t *testing.T, data []byte) (scores []blocks.PlayerScoresBlock, planets []blocks.PartialPlanetBlock, counters blocks.CountersBlock) {
+	t.Helper()
+	blockList, err := parser.FileData(data).BlockList()
+	require.NoError(t, err)
+	for _, block := range blockList {
+		switch b := block.(type) {
+		case blocks.PlayerScoresBlock:
+			scores = append(scores, b)
+		case blocks.PartialPlanetBlock:
+			planets = append(planets, b)
+		case blocks.CountersBlock:
+			counters = b
+		}
+	}
+	return scores, planets, counters
+}
+
+func TestPrune_ZeroPolicy(t *testing.T) {
+	data, err := os.ReadFile(hFile)
+	require.NoError(t, err)
+
+	pruned, stats, err := Prune(data, Policy{})
+	require.NoError(t, err)
+	assert.Equal(t, data, pruned, "nothing to drop: the file is re-encrypted as is")
+	assert.Equal(t, Stats{SizeBefore: len(data), SizeAfter: len(data)}, stats)
+}
+
+func TestPrune(t *testing.T) {
+	data, err := os.ReadFile(hFile)
+	require.NoError(t, err)
+	scores, planets, _ := records(t, data)
+
+	pruned, stats, err := Prune(data, Policy{ScoreYears: 20, ScoreInterval: 10, PlanetYears: 60})
+	require.NoError(t, err)
+	assert.Less(t, stats.SizeAfter, stats.SizeBefore)
+	assert.Equal(t, len(pruned), stats.SizeAfter)
+
+	kept, keptPlanets, counters := records(t, pruned)
+	assert.Equal(t, len(scores)-stats.ScoresDropped, len(kept))
+	for _, s := range kept {
+		year := 2400 + s.Turn
+		assert.True(t, year > 2461 || year%10 == 0, "score of %d should be dropped", year)
+	}
+	assert.Positive(t, stats.PlanetsDropped)
+	assert.Len(t, keptPlanets, len(planets)-stats.PlanetsDropped)
+	assert.Equal(t, len(keptPlanets), counters.PlanetCount)
+	for _, p := range keptPlanets {
+		assert.LessOrEqual(t, 81-p.Turn, 60)
+	}
+}
+
+func TestPrune_NotHFile(t *testing.T) {
+	data, err := os.ReadFile("../../../testdata/scenario-orders/waypoint-load/game.m1")
+	require.NoError(t, err)
+	_, _, err = Prune(data, Policy{})
+	assert.ErrorIs(t, err, ErrNotHFile)
+}
@@ -0,0 +1,145 @@
+// Package hooks runs site-specific logic on houston events, such as posting
+// to a chat channel when an exploit is detected, without patching houston.
+//
+// In Go, implement Hook and register it on a Dispatcher. From the command
+// line, a Command hook runs an external program once per event, writing the
+// event as a JSON object on its standard input:
+//
+//	{
+//	  "type": "exploit.detected",
+//	  "time": "2026-10-17T15:30:00Z",
+//	  "player": 2,
+//	  "data": {"exploit": "32k Merge", "severity": "Critical", ...}
+//	}
+//
+// The program succeeds by exiting with status 0; its standard output is
+// ignored and its standard error is reported on failure.
+//
+// Example usage:
+//
+//	d := hooks.NewDispatcher()
+//	d.Register(&hooks.Command{Path: "./notify.sh"})
+//	err := d.Fire(ctx, hooks.Event{Type: hooks.EventExploitDetected, Player: 2})
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/neper-stars/houston/reproducible"
+)
+
+// EventType names an event.
+type EventType string
+
+const (
+	// EventTurnGenerated is fired when the host generates a new turn.
+	EventTurnGenerated EventType = "turn.generated"
+	// EventPlayerSubmitted is fired when a player's orders are received.
+	EventPlayerSubmitted EventType = "player.submitted"
+	// EventExploitDetected is fired for each exploit found in a file.
+	EventExploitDetected EventType = "exploit.detected"
+)
+
+// Event is something that happened to a game.
+type Event struct {
+	Type   EventType `json:"type"`
+	Time   time.Time `json:"time"`
+	GameID uint32    `json:"game_id,omitempty"`
+	Year   int       `json:"year,omitempty"`
+	Player int       `json:"player,omitempty"` // Player number (1-16), 0 when not about a player
+	File   string    `json:"file,omitempty"`
+	Data   any       `json:"data,omitempty"` // Event specific details
+}
+
+// Hook handles events.
+type Hook interface {
+	Handle(ctx context.Context, e Event) error
+}
+
+// DefaultTimeout bounds how long a Command may run.
+const DefaultTimeout = 30 * time.Second
+
+// Command is a hook running an external program with the event as JSON on
+// its standard input.
+type Command struct {
+	Path    string
+	Args    []string
+	Events  []EventType   // Events to handle, all when empty
+	Timeout time.Duration // DefaultTimeout when zero
+}
+
+// Handle runs the program for an event it subscribed to.
+func (c *Command) Handle(ctx context.Context, e Event) error {
+	if len(c.Events) > 0 && !slices.Contains(c.Events, e.Type) {
+		return nil
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", e.Type, err)
+	}
+
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.Path, c.Args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("hook %s failed on %s: %w: %s", c.Path, e.Type, err, msg)
+		}
+		return fmt.Errorf("hook %s failed on %s: %w", c.Path, e.Type, err)
+	}
+	return nil
+}
+
+// Dispatcher sends events to registered hooks.
+type Dispatcher struct {
+	hooks []Hook
+}
+
+// NewDispatcher returns a dispatcher without hooks.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{}
+}
+
+// Register adds a hook.
+func (d *Dispatcher) Register(h Hook) {
+	d.hooks = append(d.hooks, h)
+}
+
+// Len returns the number of registered hooks.
+func (d *Dispatcher) Len() int {
+	return len(d.hooks)
+}
+
+// Fire sends an event to every hook in registration order, stamping its
+// time if unset. A failing hook does not stop the others; their errors are
+// joined.
+func (d *Dispatcher) Fire(ctx context.Context, e Event) error {
+	if e.Time.IsZero() {
+		e.Time = reproducible.Now()
+	}
+	var errs []error
+	for _, h := range d.hooks {
+		if err := h.Handle(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
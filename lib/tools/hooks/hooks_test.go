@@ -0,0 +1,96 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	events []Event
+	err    error
+}
+
+func (r *recorder) Handle(_ context.Context, e Event) error {
+	r.events = append(r.events, e)
+	return r.err
+}
+
+func TestDispatcherFire(t *testing.T) {
+	first := &recorder{err: errors.New("boom")}
+	second := &recorder{}
+
+	d := NewDispatcher()
+	d.Register(first)
+	d.Register(second)
+	assert.Equal(t, 2, d.Len())
+
+	err := d.Fire(context.Background(), Event{Type: EventExploitDetected, Player: 2})
+	assert.ErrorContains(t, err, "boom")
+
+	// The failing hook does not stop the next one
+	require.Len(t, second.events, 1)
+	assert.Equal(t, 2, second.events[0].Player)
+	assert.False(t, second.events[0].Time.IsZero())
+}
+
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not available on windows")
+	}
+	path := filepath.Join(t.TempDir(), "hook.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755))
+	return path
+}
+
+func TestCommandReceivesEvent(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "event.json")
+	c := &Command{Path: writeScript(t, `cat > "$1"`), Args: []string{out}}
+
+	e := Event{
+		Type:   EventExploitDetected,
+		Time:   time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC),
+		Player: 3,
+		Data:   map[string]string{"exploit": "32k Merge"},
+	}
+	require.NoError(t, c.Handle(context.Background(), e))
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, "exploit.detected", got["type"])
+	assert.Equal(t, "2026-10-17T15:30:00Z", got["time"])
+	assert.Equal(t, 3.0, got["player"])
+	assert.Equal(t, map[string]any{"exploit": "32k Merge"}, got["data"])
+	assert.NotContains(t, got, "game_id")
+}
+
+func TestCommandFailure(t *testing.T) {
+	c := &Command{Path: writeScript(t, "echo 'no webhook configured' >&2\nexit 3\n")}
+	err := c.Handle(context.Background(), Event{Type: EventTurnGenerated})
+	assert.ErrorContains(t, err, "turn.generated")
+	assert.ErrorContains(t, err, "no webhook configured")
+}
+
+func TestCommandEventFilter(t *testing.T) {
+	c := &Command{Path: writeScript(t, "exit 1\n"), Events: []EventType{EventTurnGenerated}}
+	assert.NoError(t, c.Handle(context.Background(), Event{Type: EventExploitDetected}))
+	assert.Error(t, c.Handle(context.Background(), Event{Type: EventTurnGenerated}))
+}
+
+func TestCommandTimeout(t *testing.T) {
+	c := &Command{Path: writeScript(t, "exec sleep 5\n"), Timeout: 50 * time.Millisecond}
+	start := time.Now()
+	assert.Error(t, c.Handle(context.Background(), Event{Type: EventTurnGenerated}))
+	assert.Less(t, time.Since(start), 4*time.Second)
+}
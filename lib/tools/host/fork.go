@@ -0,0 +1,101 @@
+package host
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/encoding"
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/store"
+)
+
+var ErrNotGameFile = errors.New("not a game file")
+
+// Player statuses of byte 7 of a player block.
+const (
+	playerAIBit           = 0x02
+	playerHumanInactive   = 227        // Also sets the AI bit
+	inactiveBlankPassword = 0xFFFFFFFF // Inactive players keep their password hash inverted
+)
+
+// NewGameID returns a random game ID for a forked game.
+func NewGameID() uint32 {
+	for {
+		if id := rand.Uint32(); id != 0 {
+			return id
+		}
+	}
+}
+
+// ForkFile rewrites a file of a game (HST, XY, M, X or H) for a sandboxed
+// copy of the game: the file header and the universe definition are given
+// gameID, and the passwords of the human players are reset so whoever
+// tests the copy can open every turn. AI players keep theirs.
+//
+// Blocks are encrypted with a key derived from the game ID, so all of them
+// are decrypted and encrypted again. Files forked with the same ID form a
+// game Stars! opens without ever mixing it up with the original.
+func ForkFile(data []byte, gameID uint32) ([]byte, error) {
+	decrypted, err := parser.FileData(data).DecryptedBlocks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+
+	writer := store.NewFileWriter()
+	encoder := store.NewBlockEncoder()
+	var result []byte
+	for i, block := range decrypted {
+		switch block.Type {
+		case blocks.FileHeaderBlockType:
+			if i != 0 {
+				return nil, fmt.Errorf("unexpected file header at block %d", i)
+			}
+			header, err := blocks.NewFileHeader(block.GenericBlock)
+			if err != nil {
+				return nil, err
+			}
+			if header.FileType == blocks.FileTypeRace {
+				return nil, fmt.Errorf("%w: file type %s", ErrNotGameFile, header.FileTypeName())
+			}
+			header.GameID = gameID
+			result = append(result, writer.WriteHeader(header)...)
+
+			writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), header.SharewareFlag())
+		case blocks.FileFooterBlockType:
+			// The footer is not encrypted
+			result = append(result, encoder.EncodeBlock(block.Type, block.Data)...)
+		default:
+			if i == 0 {
+				return nil, errors.New("missing file header")
+			}
+			plain := append([]byte(nil), block.Decrypted...)
+			switch block.Type {
+			case blocks.PlanetsBlockType:
+				if len(plain) >= 4 {
+					encoding.Write32(plain, 0, gameID)
+				}
+			case blocks.PlayerBlockType:
+				resetPassword(plain)
+			}
+			result = append(result, writer.WriteEncryptedBlock(block.Type, plain)...)
+			result = append(result, block.Trailer...)
+		}
+	}
+	return result, nil
+}
+
+// resetPassword clears the password hash of a decrypted player block
+// holding the player's full data, the only one to carry it.
+func resetPassword(player []byte) {
+	if len(player) < 16 || player[6]&0x04 == 0 {
+		return
+	}
+	switch {
+	case player[7] == playerHumanInactive:
+		encoding.Write32(player, 12, inactiveBlankPassword)
+	case player[7]&playerAIBit == 0:
+		encoding.Write32(player, 12, 0)
+	}
+}
@@ -0,0 +1,361 @@
+// Package host generates the next turn of a game from its host (HST) file
+// and the turn orders (X files) of the players, without the original
+// Stars! executable, so that games can be hosted headlessly.
+//
+// The engine is partial: it applies the common orders and runs movement,
+// mining, production, research and population growth, and skips the other
+// phases of a turn. Phases lists what is run and what is not; a game hosted
+// with it drifts from what Stars! would have generated as soon as a skipped
+// phase would have done something. Verify measures the drift against a
+// turn generated by Stars!. ForkFile copies the files of a game into a
+// sandboxed game of their own, for trying out what-if scenarios.
+//
+//	result, err := host.Generate(host.Game{
+//		HST:    hst,
+//		XY:     xy,
+//		Orders: [][]byte{x1, x2},
+//		MFiles: map[int][]byte{0: m1, 1: m2},
+//	})
+//	...
+//	os.WriteFile("game.hst", result.HST, 0o644)
+//	for player, m := range result.MFiles { ... }
+package host
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/store"
+)
+
+var (
+	ErrNotHostFile = errors.New("not a host (HST) file")
+	ErrWrongGame   = errors.New("file belongs to another game")
+	ErrWrongTurn   = errors.New("file is for another turn")
+)
+
+// Phase is a phase of a turn generation.
+type Phase struct {
+	Name      string
+	Supported bool
+	Notes     string // What the engine leaves out of a supported phase, or why a phase is skipped
+}
+
+// Phases lists the phases of a turn in the order Stars! runs them.
+var Phases = []Phase{
+	{"orders", true, "research, production queues and waypoints; design changes, fleet merges, splits and renames, cargo transfers, battle plans, relations and planet settings are ignored"},
+	{"waypoint 0 tasks", false, "no loading, unloading, colonizing or scrapping"},
+	{"mystery trader", false, ""},
+	{"movement", true, "straight to the next waypoint at warp squared light-years a year; no fuel use, stargates, wormholes, minefields or repeated orders"},
+	{"inner strength growth", false, ""},
+	{"mineral packets", false, ""},
+	{"battles", false, ""},
+	{"bombing", false, ""},
+	{"waypoint 1 tasks", false, "no colonizing, invading, remote mining or mine laying"},
+	{"mining", true, "mineral concentrations do not decline"},
+	{"production", true, "factories, mines and defenses; the queue stops at anything else, ships and starbases included"},
+	{"research", true, "production leftovers go to research"},
+	{"population growth", true, "growth rate and habitability with crowding; approximate to a few percent"},
+	{"random events", false, ""},
+	{"scanning", false, "M files keep last year's view of the other players"},
+	{"scores", false, ""},
+}
+
+// Game holds the files of a game for a turn generation.
+type Game struct {
+	HST    []byte
+	XY     []byte         // Universe file, for planet positions
+	Orders [][]byte       // Submitted X files; players without orders keep last year's
+	MFiles map[int][]byte // Last year's M files by player (0-15), rewritten for the new year
+}
+
+// Event is something that happened during a turn generation.
+type Event struct {
+	Phase  string
+	Player int // Player index, -1 for the whole game
+	Text   string
+}
+
+func (e Event) String() string {
+	if e.Player < 0 {
+		return fmt.Sprintf("%s: %s", e.Phase, e.Text)
+	}
+	return fmt.Sprintf("%s: player %d: %s", e.Phase, e.Player+1, e.Text)
+}
+
+// Result is a generated turn.
+type Result struct {
+	Year   int
+	HST    []byte
+	MFiles map[int][]byte // New M files by player, for the players whose M file was given
+	Events []Event
+}
+
+// turn is a turn being generated.
+type turn struct {
+	gs     *store.GameStore
+	events []Event
+}
+
+func (t *turn) logf(phase string, player int, format string, args ...any) {
+	t.events = append(t.events, Event{Phase: phase, Player: player, Text: fmt.Sprintf(format, args...)})
+}
+
+// Generate runs a turn generation.
+func Generate(game Game) (*Result, error) {
+	gs := store.New()
+	if len(game.XY) > 0 {
+		if err := gs.AddFile("game.xy", game.XY); err != nil {
+			return nil, fmt.Errorf("failed to load the universe file: %w", err)
+		}
+	}
+	if err := gs.AddFile("game.hst", game.HST); err != nil {
+		return nil, fmt.Errorf("failed to load the host file: %w", err)
+	}
+	if hostSource(gs) == nil {
+		return nil, ErrNotHostFile
+	}
+
+	t := &turn{gs: gs}
+	for i, x := range game.Orders {
+		if err := t.applyOrders(x); err != nil {
+			return nil, fmt.Errorf("orders %d: %w", i+1, err)
+		}
+	}
+	t.move()
+	t.mine()
+	research := t.produce()
+	t.research(research)
+	t.grow()
+
+	for _, source := range gs.Sources() {
+		if source.Header != nil {
+			source.Header.Turn++
+		}
+	}
+	gs.Turn++
+
+	hst, err := gs.RegenerateHSTFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write the host file: %w", err)
+	}
+	result := &Result{
+		Year:   blocks.StarsBaseYear + int(gs.Turn),
+		HST:    hst,
+		MFiles: make(map[int][]byte),
+		Events: t.events,
+	}
+	for _, player := range slices.Sorted(maps.Keys(game.MFiles)) {
+		m, err := t.turnFile(player, game.MFiles[player])
+		if err != nil {
+			return nil, fmt.Errorf("M file of player %d: %w", player+1, err)
+		}
+		result.MFiles[player] = m
+	}
+	return result, nil
+}
+
+// hostSource returns the HST file of a store.
+func hostSource(gs *store.GameStore) *store.FileSource {
+	for _, source := range gs.Sources() {
+		// Host files are encrypted for the same player index as race files
+		if source.Type == store.SourceTypeHSTFile && source.Header != nil &&
+			source.Header.PlayerIndex() == blocks.RaceFilePlayerIndex {
+			return source
+		}
+	}
+	return nil
+}
+
+// applyOrders applies the orders of an X file.
+func (t *turn) applyOrders(data []byte) error {
+	fd := parser.FileData(data)
+	header, err := fd.FileHeader()
+	if err != nil {
+		return err
+	}
+	if header.GameID != t.gs.GameID {
+		return fmt.Errorf("%w: game %d, not %d", ErrWrongGame, header.GameID, t.gs.GameID)
+	}
+	if header.Turn != t.gs.Turn {
+		return fmt.Errorf("%w: year %d, not %d", ErrWrongTurn, header.Year(), blocks.StarsBaseYear+int(t.gs.Turn))
+	}
+	orders, err := fd.BlockList()
+	if err != nil {
+		return err
+	}
+	player := header.PlayerIndex()
+
+	ignored := make(map[string]int)
+	for _, order := range orders {
+		switch o := order.(type) {
+		case blocks.ResearchChangeBlock:
+			t.changeResearch(player, &o)
+		case blocks.ProductionQueueChangeBlock:
+			t.changeQueue(player, &o)
+		case blocks.WaypointAddBlock:
+			t.changeWaypoint(player, &o.WaypointChangeTaskBlock, true)
+		case blocks.WaypointChangeTaskBlock:
+			t.changeWaypoint(player, &o, false)
+		case blocks.WaypointDeleteBlock:
+			t.deleteWaypoint(player, o.FleetNumber, o.WaypointNumber)
+		case blocks.FileHeader, blocks.FileFooterBlock, blocks.FileHashBlock, blocks.SaveAndSubmitBlock:
+		default:
+			ignored[blocks.BlockTypeName(order.BlockTypeID())]++
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(ignored)) {
+		t.logf("orders", player, "%d %s orders not applied", ignored[name], name)
+	}
+	return nil
+}
+
+func (t *turn) changeResearch(player int, o *blocks.ResearchChangeBlock) {
+	p, ok := t.gs.Player(player)
+	if !ok {
+		return
+	}
+	if err := p.SetResearch(o.BudgetPercent, o.CurrentField, o.NextField); err != nil {
+		t.logf("orders", player, "research not changed: %v", err)
+	}
+}
+
+func (t *turn) changeQueue(player int, o *blocks.ProductionQueueChangeBlock) {
+	planet, ok := t.ownedPlanet(player, o.PlanetId)
+	if !ok {
+		t.logf("orders", player, "production queue of planet #%d not changed: not the player's", o.PlanetId+1)
+		return
+	}
+	items := make([]store.ProductionItem, len(o.Items))
+	for i, item := range o.Items {
+		items[i] = store.ProductionItem{
+			ItemId:          item.ItemId,
+			Count:           item.Count,
+			CompletePercent: item.CompletePercent,
+			ItemType:        item.ItemType,
+		}
+	}
+	t.gs.SetProductionQueue(planet.PlanetNumber, items)
+}
+
+// changeWaypoint adds or replaces a waypoint of a fleet.
+func (t *turn) changeWaypoint(player int, o *blocks.WaypointChangeTaskBlock, add bool) {
+	fleet, ok := t.gs.Fleet(player, o.FleetNumber)
+	if !ok {
+		t.logf("orders", player, "no fleet #%d to give waypoints to", o.FleetNumber+1)
+		return
+	}
+	wp := &store.WaypointEntity{
+		X:                  o.X,
+		Y:                  o.Y,
+		PositionObject:     o.Target,
+		PositionObjectType: waypointTargetType(o),
+		Warp:               o.Warp,
+		Task:               o.WaypointTask,
+		TransportOrders:    o.TransportOrders,
+	}
+	if o.WaypointTask == store.WaypointTaskPatrol {
+		wp.AdditionalBytes = []byte{byte(o.PatrolRange)}
+	}
+
+	waypoints := slices.Clone(fleet.Waypoints)
+	index := min(o.WaypointIndex, len(waypoints))
+	switch {
+	case add:
+		waypoints = slices.Insert(waypoints, index, wp)
+	case index == len(waypoints):
+		waypoints = append(waypoints, wp)
+	default:
+		waypoints[index] = wp
+	}
+	fleet.SetWaypoints(waypoints)
+}
+
+func (t *turn) deleteWaypoint(player, fleetNumber, index int) {
+	fleet, ok := t.gs.Fleet(player, fleetNumber)
+	if !ok || index <= 0 || index >= len(fleet.Waypoints) {
+		return
+	}
+	fleet.SetWaypoints(slices.Delete(slices.Clone(fleet.Waypoints), index, index+1))
+}
+
+// waypointTargetType returns the position type of a waypoint given by an
+// order, as written in waypoint blocks.
+func waypointTargetType(o *blocks.WaypointChangeTaskBlock) int {
+	kind := o.TargetType
+	if o.ValidTask {
+		kind |= 0x10
+	}
+	if o.NoAutoTrack {
+		kind |= 0x20
+	}
+	return kind
+}
+
+// ownedPlanet returns a planet when it belongs to a player.
+func (t *turn) ownedPlanet(player, number int) (*store.PlanetEntity, bool) {
+	planet, ok := t.gs.PlanetForSave(number)
+	if !ok || planet.Owner != player {
+		return nil, false
+	}
+	return planet, true
+}
+
+// turnFile rewrites last year's M file of a player for the new year, with
+// the player's own planets, fleets and research from the new turn.
+func (t *turn) turnFile(player int, data []byte) ([]byte, error) {
+	ms := store.New()
+	if err := ms.AddFile(fmt.Sprintf("game.m%d", player+1), data); err != nil {
+		return nil, err
+	}
+	if ms.GameID != t.gs.GameID {
+		return nil, fmt.Errorf("%w: game %d, not %d", ErrWrongGame, ms.GameID, t.gs.GameID)
+	}
+	if ms.Turn+1 != t.gs.Turn {
+		return nil, fmt.Errorf("%w: year %d, not %d", ErrWrongTurn, blocks.StarsBaseYear+int(ms.Turn), blocks.StarsBaseYear+int(t.gs.Turn)-1)
+	}
+
+	if p, ok := t.gs.Player(player); ok {
+		if mp, ok := ms.Player(player); ok && mp.HasFullData {
+			if err := mp.SetTechLevels(p.Tech); err != nil {
+				return nil, err
+			}
+			if err := mp.SetTechProgress(p.TechProgress, p.ResearchLastYear); err != nil {
+				return nil, err
+			}
+			if err := mp.SetResearch(p.ResearchPercentage, p.CurrentResearchField, p.NextResearchField); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, planet := range t.gs.PlanetsByOwner(player) {
+		mp, ok := ms.PlanetForSave(planet.PlanetNumber)
+		if !ok || mp.Owner != player {
+			continue
+		}
+		mp.SetPopulation(planet.Population)
+		mp.SetInstallations(planet.Mines, planet.Factories, planet.Defenses)
+		mp.SetMinerals(planet.GetMinerals())
+		if pq, ok := t.gs.ProductionQueue(planet.PlanetNumber); ok && pq.Meta().Dirty {
+			ms.SetProductionQueue(planet.PlanetNumber, slices.Clone(pq.Items))
+		}
+	}
+	for _, fleet := range t.gs.FleetsByOwner(player) {
+		mf, ok := ms.Fleet(player, fleet.FleetNumber)
+		if !ok || !fleet.Meta().Dirty {
+			continue
+		}
+		mf.X, mf.Y, mf.PositionObjectId = fleet.X, fleet.Y, fleet.PositionObjectId
+		mf.SetWaypoints(slices.Clone(fleet.Waypoints))
+	}
+
+	for _, source := range ms.Sources() {
+		source.Header.Turn = t.gs.Turn
+	}
+	ms.Turn = t.gs.Turn
+	return ms.RegenerateMFile(player)
+}
@@ -0,0 +1,197 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/store"
+)
+
+const scenario = "../../../testdata/scenario-singleplayer"
+
+func readGame(t *testing.T, dir string, withOrders bool) Game {
+	t.Helper()
+	read := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join(scenario, dir, name))
+		require.NoError(t, err)
+		return data
+	}
+	game := Game{
+		HST:    read("Game.hst"),
+		XY:     read("Game.xy"),
+		MFiles: map[int][]byte{0: read("Game.m1")},
+	}
+	if withOrders {
+		game.Orders = [][]byte{read("Game.x1")}
+	}
+	return game
+}
+
+func loadHST(t *testing.T, game Game, hst []byte) *store.GameStore {
+	t.Helper()
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game.xy", game.XY))
+	require.NoError(t, gs.AddFile("game.hst", hst))
+	return gs
+}
+
+func TestGenerateMovesFleets(t *testing.T) {
+	game := readGame(t, "2484", true)
+	result, err := Generate(game)
+	require.NoError(t, err)
+	assert.Equal(t, 2485, result.Year)
+
+	gs := loadHST(t, game, result.HST)
+	fleet, ok := gs.Fleet(0, 9)
+	require.True(t, ok)
+	assert.Equal(t, 1423, fleet.X)
+	assert.Equal(t, 1335, fleet.Y)
+	require.Len(t, fleet.Waypoints, 2)
+	assert.Equal(t, 1423, fleet.Waypoints[0].X)
+	assert.Equal(t, 183, fleet.Waypoints[1].PositionObject)
+	assert.Equal(t, 2, fleet.Waypoints[1].Warp)
+
+	require.Contains(t, result.MFiles, 0)
+	ms := store.New()
+	require.NoError(t, ms.AddFile("game.m1", result.MFiles[0]))
+	assert.Equal(t, 2485, blocks.StarsBaseYear+int(ms.Turn))
+	fleet, ok = ms.Fleet(0, 9)
+	require.True(t, ok)
+	assert.Equal(t, 1423, fleet.X)
+}
+
+func techSum(tech store.TechLevels) int {
+	return tech.Energy + tech.Weapons + tech.Propulsion + tech.Construction + tech.Electronics + tech.Biotech
+}
+
+func TestGenerateEconomy(t *testing.T) {
+	game := readGame(t, "2483-orders-given", true)
+	before := loadHST(t, game, game.HST)
+	result, err := Generate(game)
+	require.NoError(t, err)
+	after := loadHST(t, game, result.HST)
+
+	playerBefore, ok := before.Player(0)
+	require.True(t, ok)
+	player, ok := after.Player(0)
+	require.True(t, ok)
+	assert.Positive(t, player.ResearchLastYear)
+	assert.Equal(t, 0, player.CurrentResearchField, "research order applied")
+	assert.GreaterOrEqual(t, techSum(player.Tech), techSum(playerBefore.Tech))
+
+	for _, planet := range after.PlanetsByOwner(0) {
+		old, ok := before.Planet(planet.PlanetNumber)
+		require.True(t, ok)
+		assert.GreaterOrEqual(t, planet.Factories, old.Factories, planet.Name)
+		assert.GreaterOrEqual(t, planet.Mines, old.Mines, planet.Name)
+		assert.GreaterOrEqual(t, planet.Population, old.Population, planet.Name)
+	}
+	homeBefore, _ := before.Planet(121)
+	home, _ := after.Planet(121)
+	assert.Greater(t, home.Factories+home.Mines, homeBefore.Factories+homeBefore.Mines)
+}
+
+func TestGenerateRejectsForeignFiles(t *testing.T) {
+	game := readGame(t, "2484", false)
+
+	_, err := Generate(Game{HST: game.MFiles[0]})
+	assert.ErrorIs(t, err, ErrNotHostFile)
+
+	old := readGame(t, "2483-orders-given", true)
+	game.Orders = old.Orders
+	_, err = Generate(game)
+	assert.ErrorIs(t, err, ErrWrongTurn)
+}
+
+func TestVerify(t *testing.T) {
+	game := readGame(t, "2484", true)
+	expected := readGame(t, "2485", false).HST
+
+	report, err := Verify(game, expected)
+	require.NoError(t, err)
+	assert.Equal(t, 2485, report.Year)
+	fields := make(map[string]Field)
+	for _, f := range report.Fields {
+		fields[f.Name] = f
+	}
+	require.Contains(t, fields, "fleet.position")
+	assert.Equal(t, 1.0, fields["fleet.position"].Accuracy(), "movement follows Stars!")
+	assert.Equal(t, 11, fields["planet.owner"].Compared)
+	assert.Less(t, report.Accuracy(), 1.0, "the engine is partial")
+	assert.NotEmpty(t, report.Mismatches)
+
+	same, err := Compare(expected, expected, game.XY)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, same.Accuracy())
+	assert.Empty(t, same.Mismatches)
+
+	_, err = Compare(game.HST, expected, game.XY)
+	assert.ErrorIs(t, err, ErrWrongTurn)
+}
+
+func TestForkFile(t *testing.T) {
+	game := readGame(t, "2484", true)
+	const gameID = 12345
+	fork := func(data []byte) []byte {
+		out, err := ForkFile(data, gameID)
+		require.NoError(t, err)
+		return out
+	}
+	forked := Game{
+		HST:    fork(game.HST),
+		XY:     fork(game.XY),
+		Orders: [][]byte{fork(game.Orders[0])},
+		MFiles: map[int][]byte{0: fork(game.MFiles[0])},
+	}
+
+	gs := loadHST(t, forked, forked.HST)
+	assert.Equal(t, uint32(gameID), gs.GameID)
+	for _, data := range [][]byte{forked.HST, forked.XY, forked.Orders[0], forked.MFiles[0]} {
+		blockList, err := parser.FileData(data).BlockList()
+		require.NoError(t, err)
+		for _, block := range blockList {
+			switch b := block.(type) {
+			case blocks.FileHeader:
+				assert.Equal(t, uint32(gameID), b.GameID)
+			case blocks.PlayerBlock:
+				if b.FullDataFlag {
+					assert.False(t, b.HasPassword(), "player %d", b.PlayerNumber+1)
+				}
+			}
+		}
+	}
+
+	// The forked files form a game of their own
+	result, err := Generate(forked)
+	require.NoError(t, err)
+	assert.Equal(t, 2485, result.Year)
+	_, err = Generate(Game{HST: forked.HST, XY: forked.XY, Orders: game.Orders})
+	assert.ErrorIs(t, err, ErrWrongGame)
+
+	// Human players lose their password, AI players keep theirs
+	for file, want := range map[string]bool{
+		"../../../testdata/scenario-orders/change-password/02-order-generated/game.m2": false,
+		"../../../testdata/scenario-map/joat-start/Game.m2":                            true,
+	} {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		blockList, err := parser.FileData(fork(data)).BlockList()
+		require.NoError(t, err)
+		for _, block := range blockList {
+			if b, ok := block.(blocks.PlayerBlock); ok && b.FullDataFlag {
+				assert.Equal(t, want, b.HasPassword(), file)
+			}
+		}
+	}
+
+	race, err := os.ReadFile(filepath.Join(scenario, "2484", "stars.r1"))
+	require.NoError(t, err)
+	_, err = ForkFile(race, gameID)
+	assert.ErrorIs(t, err, ErrNotGameFile)
+}
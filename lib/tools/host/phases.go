@@ -0,0 +1,332 @@
+package host
+
+import (
+	"math"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+const (
+	// noPositionObject is the position object of fleets in deep space.
+	noPositionObject = 0xFFFF
+	// deepSpaceWaypoint is the position type of a waypoint left where a
+	// fleet stopped on its way (deep space, valid task).
+	deepSpaceWaypoint = 0x14
+	// planetTarget is the target type of waypoints at planets.
+	planetTarget = 1
+)
+
+// move moves the fleets toward their next waypoint.
+func (t *turn) move() {
+	for _, fleet := range t.gs.AllFleets() {
+		if len(fleet.Waypoints) < 2 {
+			continue
+		}
+		next := fleet.Waypoints[1]
+		if next.Warp <= 0 || next.Warp == blocks.WarpStargate {
+			continue
+		}
+		dx, dy := float64(next.X-fleet.X), float64(next.Y-fleet.Y)
+		distance := math.Hypot(dx, dy)
+		speed := float64(next.Warp * next.Warp)
+
+		if distance <= speed {
+			fleet.X, fleet.Y = next.X, next.Y
+			fleet.PositionObjectId = noPositionObject
+			if next.PositionObjectType&0x0F == planetTarget {
+				fleet.PositionObjectId = next.PositionObject
+			}
+			fleet.SetWaypoints(slices.Clone(fleet.Waypoints[1:]))
+			continue
+		}
+		fleet.X += int(math.Round(dx * speed / distance))
+		fleet.Y += int(math.Round(dy * speed / distance))
+		fleet.PositionObjectId = noPositionObject
+		here := &store.WaypointEntity{X: fleet.X, Y: fleet.Y, PositionObjectType: deepSpaceWaypoint}
+		fleet.SetWaypoints(append([]*store.WaypointEntity{here}, fleet.Waypoints[1:]...))
+	}
+}
+
+// economy returns the owned planets of the players with full data, by
+// player.
+func (t *turn) economy() map[*store.PlayerEntity][]*store.PlanetEntity {
+	planets := make(map[*store.PlayerEntity][]*store.PlanetEntity)
+	for _, player := range t.gs.AllPlayers() {
+		if !player.HasFullData {
+			continue
+		}
+		for _, planet := range t.gs.PlanetsByOwner(player.PlayerNumber) {
+			if planet.Meta().Quality >= store.QualityFull {
+				planets[player] = append(planets[player], planet)
+			}
+		}
+	}
+	return planets
+}
+
+// mine adds the output of the mines to the surface minerals of planets.
+func (t *turn) mine() {
+	for player, planets := range t.economy() {
+		if !player.BuildsInstallations() {
+			continue
+		}
+		for _, planet := range planets {
+			operable := int(planet.Population) * player.Production.MinesOperate / 10000
+			mines := min(planet.Mines, operable)
+			if mines <= 0 {
+				continue
+			}
+			output := func(concentration int) int64 {
+				return int64(mines * player.Production.MineProduction * concentration / 1000)
+			}
+			minerals := planet.GetMinerals()
+			minerals.Ironium += output(planet.IroniumConc)
+			minerals.Boranium += output(planet.BoraniumConc)
+			minerals.Germanium += output(planet.GermaniumConc)
+			planet.SetMinerals(minerals)
+		}
+	}
+}
+
+// installation is a planetary installation the engine builds.
+type installation struct {
+	cost  data.Cost
+	built func(p *store.PlanetEntity) *int
+	limit func(p *store.PlanetEntity) int
+}
+
+// produce builds the production queues of the planets and returns the
+// resources each player puts into research: the research share of the
+// planets and what production leaves.
+func (t *turn) produce() map[*store.PlayerEntity]int {
+	research := make(map[*store.PlayerEntity]int)
+	stopped := make(map[*store.PlayerEntity]int)
+	for player, planets := range t.economy() {
+		installations := t.installations(player)
+		for _, planet := range planets {
+			resources := t.gs.CResourcesAtPlanet(planet, player)
+			if !planet.NoResearch {
+				share := resources * player.ResearchPercentage / 100
+				research[player] += share
+				resources -= share
+			}
+			pq, ok := t.gs.ProductionQueue(planet.PlanetNumber)
+			if ok && len(pq.Items) > 0 {
+				var done bool
+				resources, done = t.build(planet, pq, installations, resources)
+				if !done {
+					stopped[player]++
+				}
+			}
+			research[player] += resources
+		}
+	}
+	for player, count := range stopped {
+		t.logf("production", player.PlayerNumber, "%d planets stopped at items the engine cannot build", count)
+	}
+	return research
+}
+
+// installations returns the installations a player can build by item ID,
+// automatic items included.
+func (t *turn) installations(player *store.PlayerEntity) map[int]installation {
+	if !player.BuildsInstallations() {
+		return nil
+	}
+	factoryGerm := 4
+	if player.FactoriesCost1LessGerm {
+		factoryGerm = 3
+	}
+	defense := data.Cost{Resources: 15, Ironium: 5, Boranium: 5, Germanium: 5}
+	if prt := data.GetPRT(player.PRT); prt != nil && prt.DefensesCostModifier > 0 {
+		defense.Resources = int(math.Round(float64(defense.Resources) * prt.DefensesCostModifier))
+	}
+
+	factories := installation{
+		cost:  data.Cost{Resources: player.Production.FactoryCost, Germanium: factoryGerm},
+		built: func(p *store.PlanetEntity) *int { return &p.Factories },
+		limit: func(p *store.PlanetEntity) int { return t.gs.MaxFactories(p, player) },
+	}
+	mines := installation{
+		cost:  data.Cost{Resources: player.Production.MineCost},
+		built: func(p *store.PlanetEntity) *int { return &p.Mines },
+		limit: func(p *store.PlanetEntity) int { return p.MaxMines(t.gs, player) },
+	}
+	defenses := installation{
+		cost:  defense,
+		built: func(p *store.PlanetEntity) *int { return &p.Defenses },
+		limit: func(p *store.PlanetEntity) int { return t.gs.MaxDefenses(p, player) },
+	}
+	return map[int]installation{
+		blocks.ProductionItemFactory:       factories,
+		blocks.ProductionItemAutoFactories: factories,
+		blocks.ProductionItemMine:          mines,
+		blocks.ProductionItemAutoMines:     mines,
+		blocks.ProductionItemDefense:       defenses,
+		blocks.ProductionItemAutoDefenses:  defenses,
+	}
+}
+
+// build builds the queue of a planet with its resources and returns what
+// is left, and whether the queue ran out of resources or items rather than
+// stopping at an item the engine cannot build. Automatic items stay in the
+// queue; the others leave it once built.
+func (t *turn) build(planet *store.PlanetEntity, pq *store.ProductionQueueEntity, installations map[int]installation, resources int) (int, bool) {
+	minerals := planet.GetMinerals()
+	items := slices.Clone(pq.Items)
+	done := true
+	for i := 0; i < len(items) && resources > 0; i++ {
+		item := &items[i]
+		inst, ok := installations[item.ItemId]
+		if item.ItemType != blocks.ProductionItemTypeStandard || !ok {
+			if item.IsAutoItem() {
+				continue
+			}
+			done = false
+			break
+		}
+
+		built := inst.built(planet)
+		count := min(item.Count, inst.limit(planet)-*built)
+		for ; count > 0; count-- {
+			c := inst.cost
+			if resources < c.Resources || minerals.Ironium < int64(c.Ironium) ||
+				minerals.Boranium < int64(c.Boranium) || minerals.Germanium < int64(c.Germanium) {
+				break
+			}
+			resources -= c.Resources
+			minerals.Ironium -= int64(c.Ironium)
+			minerals.Boranium -= int64(c.Boranium)
+			minerals.Germanium -= int64(c.Germanium)
+			*built++
+			if !item.IsAutoItem() {
+				item.Count--
+			}
+		}
+		if count > 0 && !item.IsAutoItem() {
+			// Out of resources or minerals for this item
+			break
+		}
+	}
+
+	items = slices.DeleteFunc(items, func(item store.ProductionItem) bool {
+		return item.Count == 0 && !item.IsAutoItem()
+	})
+	planet.SetInstallations(planet.Mines, planet.Factories, planet.Defenses)
+	planet.SetMinerals(minerals)
+	if len(items) != len(pq.Items) || !slices.Equal(items, pq.Items) {
+		pq.SetItems(items)
+	}
+	return resources, done
+}
+
+// research spends the research resources of the players. Generalized
+// Research puts half of them into the current field and 15% into each of the
+// others.
+func (t *turn) research(budgets map[*store.PlayerEntity]int) {
+	for player, budget := range budgets {
+		spent := [6]int{}
+		if player.HasLRT(blocks.LRTGeneralizedResearch) {
+			for field := range spent {
+				spent[field] = budget * 15 / 100
+			}
+			spent[player.CurrentResearchField] = budget / 2
+		} else {
+			spent[player.CurrentResearchField] = budget
+		}
+
+		tech := [6]int{player.Tech.Energy, player.Tech.Weapons, player.Tech.Propulsion,
+			player.Tech.Construction, player.Tech.Electronics, player.Tech.Biotech}
+		progress := [6]uint32{player.TechProgress.Energy, player.TechProgress.Weapons, player.TechProgress.Propulsion,
+			player.TechProgress.Construction, player.TechProgress.Electronics, player.TechProgress.Biotech}
+		factors := [6]int{player.ResearchCost.Energy, player.ResearchCost.Weapons, player.ResearchCost.Propulsion,
+			player.ResearchCost.Construction, player.ResearchCost.Electronics, player.ResearchCost.Biotech}
+		slowTech := t.gs.HasGameSetting(data.GameSettingSlowTech)
+		current, next := player.CurrentResearchField, player.NextResearchField
+
+		for field := range spent {
+			progress[field] += uint32(spent[field])
+			for tech[field] < player.TechCap() {
+				total := 0
+				for _, level := range tech {
+					total += level
+				}
+				cost := data.TechCost(tech[field]+1, total, costFactor(factors[field]), slowTech)
+				if cost <= 0 || int(progress[field]) < cost {
+					break
+				}
+				progress[field] -= uint32(cost)
+				tech[field]++
+				t.logf("research", player.PlayerNumber, "reached %s %d", fieldNames[field], tech[field])
+				if field == current && next != blocks.ResearchFieldSameField {
+					current = next
+				}
+			}
+		}
+
+		levels := store.TechLevels{Energy: tech[0], Weapons: tech[1], Propulsion: tech[2],
+			Construction: tech[3], Electronics: tech[4], Biotech: tech[5]}
+		points := blocks.TechPoints{Energy: progress[0], Weapons: progress[1], Propulsion: progress[2],
+			Construction: progress[3], Electronics: progress[4], Biotech: progress[5]}
+		if err := player.SetTechLevels(levels); err != nil {
+			t.logf("research", player.PlayerNumber, "tech levels not set: %v", err)
+		}
+		if err := player.SetTechProgress(points, budget); err != nil {
+			t.logf("research", player.PlayerNumber, "research not recorded: %v", err)
+		}
+		if current != player.CurrentResearchField {
+			if err := player.SetResearch(player.ResearchPercentage, current, next); err != nil {
+				t.logf("research", player.PlayerNumber, "research field not changed: %v", err)
+			}
+		}
+	}
+}
+
+var fieldNames = [6]string{"Energy", "Weapons", "Propulsion", "Construction", "Electronics", "Biotechnology"}
+
+// costFactor returns the cost factor of a research cost setting.
+func costFactor(setting int) float64 {
+	switch setting {
+	case blocks.ResearchCostExpensive:
+		return data.ResearchFactorExpensive
+	case blocks.ResearchCostCheap:
+		return data.ResearchFactorCheap
+	}
+	return data.ResearchFactorNormal
+}
+
+// grow grows or shrinks the population of the planets.
+func (t *turn) grow() {
+	for player, planets := range t.economy() {
+		for _, planet := range planets {
+			pop := float64(planet.Population)
+			capacity := float64(t.gs.MaxPopulation(planet, player))
+			hab := float64(t.gs.PctPlanetDesirability(planet, player))
+
+			var growth float64
+			switch {
+			case hab < 0:
+				// Hostile planets lose a tenth of their negative habitability
+				growth = pop * hab / 1000
+			case capacity > 0 && pop > capacity:
+				// Overcrowded planets lose 4% of their population per 100% over capacity, at most 12%
+				growth = -pop * min((pop/capacity-1)*0.04, 0.12)
+			default:
+				growth = pop * float64(player.GrowthRate) / 100 * hab / 100
+				if capacity > 0 && pop > capacity/4 {
+					crowding := 1 - pop/capacity
+					growth *= 16.0 / 9.0 * crowding * crowding
+				}
+			}
+			// Populations are counted in hundreds
+			change := int64(growth) / 100 * 100
+			if change == 0 {
+				continue
+			}
+			planet.SetPopulation(max(planet.Population+change, 0))
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package host
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+// Field is how often the engine generated one field of the host file as
+// Stars! did.
+type Field struct {
+	Name     string `json:"name"`
+	Compared int    `json:"compared"`
+	Matched  int    `json:"matched"`
+}
+
+// Accuracy returns the share of the values compared that matched, 1 when
+// nothing was compared.
+func (f Field) Accuracy() float64 {
+	if f.Compared == 0 {
+		return 1
+	}
+	return float64(f.Matched) / float64(f.Compared)
+}
+
+// Mismatch is a value the engine generated differently from Stars!.
+type Mismatch struct {
+	Field  string `json:"field"`
+	Object string `json:"object"`
+	Got    string `json:"got"`
+	Want   string `json:"want"`
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s %s: got %s, want %s", m.Object, m.Field, m.Got, m.Want)
+}
+
+// Report compares a host file generated by the engine with the one
+// Stars! generated from the same files.
+type Report struct {
+	Year       int        `json:"year"`
+	Fields     []Field    `json:"fields"`
+	Mismatches []Mismatch `json:"mismatches"`
+}
+
+// Accuracy returns the share of all the values compared that matched.
+func (r *Report) Accuracy() float64 {
+	total := Field{}
+	for _, f := range r.Fields {
+		total.Compared += f.Compared
+		total.Matched += f.Matched
+	}
+	return total.Accuracy()
+}
+
+// Verify generates the turn of a game and compares the host file with the
+// one Stars! generated, to measure how close the engine comes.
+func Verify(game Game, expected []byte) (*Report, error) {
+	result, err := Generate(game)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(result.HST, expected, game.XY)
+}
+
+// Compare compares a generated host file with the expected one, field by
+// field: the tech of the players, the planets owned in either file, and
+// the fleets. The universe file, when given, names the planets.
+func Compare(got, expected, xy []byte) (*Report, error) {
+	load := func(hst []byte) (*store.GameStore, error) {
+		gs := store.New()
+		if len(xy) > 0 {
+			if err := gs.AddFile("game.xy", xy); err != nil {
+				return nil, fmt.Errorf("failed to load the universe file: %w", err)
+			}
+		}
+		if err := gs.AddFile("game.hst", hst); err != nil {
+			return nil, fmt.Errorf("failed to load the host file: %w", err)
+		}
+		if hostSource(gs) == nil {
+			return nil, ErrNotHostFile
+		}
+		return gs, nil
+	}
+	g, err := load(got)
+	if err != nil {
+		return nil, err
+	}
+	want, err := load(expected)
+	if err != nil {
+		return nil, fmt.Errorf("expected file: %w", err)
+	}
+	if g.GameID != want.GameID {
+		return nil, fmt.Errorf("%w: game %d, not %d", ErrWrongGame, want.GameID, g.GameID)
+	}
+	if g.Turn != want.Turn {
+		return nil, fmt.Errorf("%w: year %d, not %d", ErrWrongTurn,
+			blocks.StarsBaseYear+int(want.Turn), blocks.StarsBaseYear+int(g.Turn))
+	}
+
+	c := &comparison{report: &Report{Year: blocks.StarsBaseYear + int(g.Turn)}, fields: make(map[string]*Field)}
+	c.players(g, want)
+	c.planets(g, want)
+	c.fleets(g, want)
+	for _, name := range c.order {
+		c.report.Fields = append(c.report.Fields, *c.fields[name])
+	}
+	return c.report, nil
+}
+
+// comparison gathers the fields of a report as they are compared.
+type comparison struct {
+	report *Report
+	fields map[string]*Field
+	order  []string
+}
+
+// check compares one value of an object.
+func (c *comparison) check(field, object string, got, want any) {
+	f, ok := c.fields[field]
+	if !ok {
+		f = &Field{Name: field}
+		c.fields[field] = f
+		c.order = append(c.order, field)
+	}
+	f.Compared++
+	if got == want {
+		f.Matched++
+		return
+	}
+	c.report.Mismatches = append(c.report.Mismatches, Mismatch{
+		Field: field, Object: object, Got: fmt.Sprint(got), Want: fmt.Sprint(want),
+	})
+}
+
+func presence(ok bool) string {
+	if ok {
+		return "present"
+	}
+	return "missing"
+}
+
+func (c *comparison) players(g, want *store.GameStore) {
+	for _, w := range want.AllPlayers() {
+		p, ok := g.Player(w.PlayerNumber)
+		object := fmt.Sprintf("player %d", w.PlayerNumber+1)
+		if !ok {
+			c.check("player", object, presence(false), presence(true))
+			continue
+		}
+		c.check("player.tech", object, p.Tech, w.Tech)
+		c.check("player.tech_progress", object, p.TechProgress, w.TechProgress)
+		c.check("player.research_field", object, p.CurrentResearchField, w.CurrentResearchField)
+	}
+}
+
+func (c *comparison) planets(g, want *store.GameStore) {
+	numbers := make(map[int]bool)
+	for _, gs := range []*store.GameStore{g, want} {
+		for _, planet := range gs.Planets.All() {
+			if planet.Owner >= 0 {
+				numbers[planet.PlanetNumber] = true
+			}
+		}
+	}
+	for _, number := range slices.Sorted(maps.Keys(numbers)) {
+		p, ok := g.Planet(number)
+		w, wok := want.Planet(number)
+		object := fmt.Sprintf("planet #%d", number+1)
+		if wok && w.Name != "" {
+			object = fmt.Sprintf("planet %s (#%d)", w.Name, number+1)
+		}
+		if !ok || !wok {
+			c.check("planet", object, presence(ok), presence(wok))
+			continue
+		}
+		c.check("planet.owner", object, p.Owner, w.Owner)
+		c.check("planet.population", object, p.Population, w.Population)
+		c.check("planet.ironium", object, p.Ironium, w.Ironium)
+		c.check("planet.boranium", object, p.Boranium, w.Boranium)
+		c.check("planet.germanium", object, p.Germanium, w.Germanium)
+		c.check("planet.mines", object, p.Mines, w.Mines)
+		c.check("planet.factories", object, p.Factories, w.Factories)
+		c.check("planet.defenses", object, p.Defenses, w.Defenses)
+	}
+}
+
+func (c *comparison) fleets(g, want *store.GameStore) {
+	keys := make(map[store.EntityKey]bool)
+	for _, gs := range []*store.GameStore{g, want} {
+		for _, fleet := range gs.Fleets.All() {
+			keys[fleet.Meta().Key] = true
+		}
+	}
+	sorted := slices.SortedFunc(maps.Keys(keys), func(a, b store.EntityKey) int {
+		if a.Owner != b.Owner {
+			return a.Owner - b.Owner
+		}
+		return a.Number - b.Number
+	})
+	for _, key := range sorted {
+		p, ok := g.Fleets.Get(key)
+		w, wok := want.Fleets.Get(key)
+		object := fmt.Sprintf("fleet #%d of player %d", key.Number+1, key.Owner+1)
+		if wok {
+			object = fmt.Sprintf("%s (#%d) of player %d", w.Name(), key.Number+1, key.Owner+1)
+		}
+		if !ok || !wok {
+			c.check("fleet", object, presence(ok), presence(wok))
+			continue
+		}
+		c.check("fleet.position", object, fmt.Sprintf("(%d,%d)", p.X, p.Y), fmt.Sprintf("(%d,%d)", w.X, w.Y))
+		c.check("fleet.ships", object, p.ShipCounts, w.ShipCounts)
+		gotCargo, wantCargo := p.GetCargo(), w.GetCargo()
+		c.check("fleet.fuel", object, gotCargo.Fuel, wantCargo.Fuel)
+		gotCargo.Fuel, wantCargo.Fuel = 0, 0
+		c.check("fleet.cargo", object, gotCargo, wantCargo)
+		c.check("fleet.waypoints", object, len(p.Waypoints), len(w.Waypoints))
+	}
+}
@@ -0,0 +1,214 @@
+// Package houserules checks submitted orders against the house rules of a
+// game, so that a host can bounce offending X files with an explanation
+// instead of reviewing every turn by hand.
+//
+// Rules are declared in YAML; a rule left out (or zero) is not enforced:
+//
+//	# No more than 20 chaff fleets per player
+//	max_chaff: 20
+//	# Colonists may not be dropped onto planets beyond what fleets carried
+//	no_pop_drop: true
+//	# A player declared a friend stays one for at least 5 years
+//	min_nap_years: 5
+//
+// Example usage:
+//
+//	rules, _ := houserules.Load("rules.yaml")
+//	for _, v := range rules.Check(submission) {
+//	    fmt.Println(v)
+//	}
+package houserules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/exploits"
+	"github.com/neper-stars/houston/lib/tools/xfilereader"
+	"github.com/neper-stars/houston/store"
+)
+
+// Rules are the house rules of a game.
+type Rules struct {
+	// MaxChaff is the most chaff fleets a player may own: fleets of a single
+	// ship whose design carries nothing but engines, armor and shields.
+	MaxChaff int `yaml:"max_chaff"`
+
+	// NoPopDrop forbids planets gaining more colonists than growth and the
+	// owner's fleets can explain (see exploits.ExploitPopDrop).
+	NoPopDrop bool `yaml:"no_pop_drop"`
+
+	// MinNAPYears is how long a player must keep another player as a friend
+	// before changing the relation.
+	MinNAPYears int `yaml:"min_nap_years"`
+}
+
+// Rule names, as used in the YAML file.
+const (
+	RuleMaxChaff    = "max_chaff"
+	RuleNoPopDrop   = "no_pop_drop"
+	RuleMinNAPYears = "min_nap_years"
+)
+
+// Violation is a house rule broken by a submission.
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+// String returns the violation as shown to the player.
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+}
+
+// Submission is a player's orders with the turns they are checked against.
+type Submission struct {
+	Player int // Player index (0-15)
+	Orders *xfilereader.FileInfo
+
+	// Turns holds the player's M files, one store per turn in any order.
+	// The latest turn is the one the orders are for; earlier turns are
+	// needed by the rules spanning several years.
+	Turns []*store.GameStore
+}
+
+// Parse decodes rules from YAML. Unknown keys are rejected so that a typo
+// does not silently disable a rule.
+func Parse(data []byte) (*Rules, error) {
+	var r Rules
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&r); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse house rules: %w", err)
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Load reads rules from a YAML file.
+func Load(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read house rules: %w", err)
+	}
+	return Parse(data)
+}
+
+// Validate checks the rule values.
+func (r *Rules) Validate() error {
+	if r.MaxChaff < 0 {
+		return fmt.Errorf("%s must not be negative", RuleMaxChaff)
+	}
+	if r.MinNAPYears < 0 {
+		return fmt.Errorf("%s must not be negative", RuleMinNAPYears)
+	}
+	return nil
+}
+
+// Check returns the house rules broken by a submission.
+func (r *Rules) Check(s *Submission) []Violation {
+	turns := slices.Clone(s.Turns)
+	slices.SortFunc(turns, func(a, b *store.GameStore) int {
+		return int(a.Turn) - int(b.Turn)
+	})
+
+	var violations []Violation
+	if r.MaxChaff > 0 && len(turns) > 0 {
+		if n := countChaff(turns[len(turns)-1], s.Player); n > r.MaxChaff {
+			violations = append(violations, Violation{
+				Rule:    RuleMaxChaff,
+				Message: fmt.Sprintf("%d chaff fleets owned, the limit is %d", n, r.MaxChaff),
+			})
+		}
+	}
+	if r.NoPopDrop && len(turns) > 1 {
+		latest := blocks.StarsBaseYear + int(turns[len(turns)-1].Turn)
+		for _, d := range exploits.CheckHistory(turns).Detections {
+			if d.Type == exploits.ExploitPopDrop && d.Player == s.Player && d.Year == latest {
+				violations = append(violations, Violation{Rule: RuleNoPopDrop, Message: d.Description})
+			}
+		}
+	}
+	if r.MinNAPYears > 0 && s.Orders != nil && len(turns) > 0 {
+		violations = append(violations, r.checkPacts(s, turns)...)
+	}
+	return violations
+}
+
+// IsChaff returns true if a design carries nothing but engines, armor and
+// shields, on a hull without built-in cargo, mining or minelaying. Designs
+// seen without their components are never chaff.
+func IsChaff(d *store.DesignEntity) bool {
+	if d.IsStarbase || d.Meta().Quality < store.QualityFull {
+		return false
+	}
+	if d.GetCargoCapacity() > 0 || d.HasMining() || d.HasMinelaying() {
+		return false
+	}
+	for _, item := range d.EquippedItems() {
+		switch item.Category {
+		case blocks.ItemCategoryEngine, blocks.ItemCategoryArmor, blocks.ItemCategoryShield:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// countChaff counts the single-ship chaff fleets of a player.
+func countChaff(gs *store.GameStore, player int) int {
+	count := 0
+	for _, f := range gs.FleetsByOwner(player) {
+		if f.TotalShips() != 1 {
+			continue
+		}
+		for _, entry := range f.GetDesigns(gs) {
+			if IsChaff(entry.Design) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// checkPacts flags relation changes ending a friendship younger than the
+// minimum. A friendship already held in the earliest turn given is dated
+// to that turn, so the player's older M files should be included.
+func (r *Rules) checkPacts(s *Submission, turns []*store.GameStore) []Violation {
+	latest := turns[len(turns)-1]
+	year := blocks.StarsBaseYear + int(latest.Turn)
+
+	var violations []Violation
+	for _, order := range s.Orders.Orders {
+		change, ok := order.Block.(blocks.PlayersRelationChangeBlock)
+		if !ok || change.Relation == blocks.RelationFriend {
+			continue
+		}
+		since := -1
+		for i := len(turns) - 1; i >= 0; i-- {
+			p, ok := turns[i].Player(s.Player)
+			if !ok || !p.HasFullData || p.GetRelationTo(change.TargetPlayer) != blocks.StoredRelationFriend {
+				break
+			}
+			since = blocks.StarsBaseYear + int(turns[i].Turn)
+		}
+		if since < 0 || year-since >= r.MinNAPYears {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule: RuleMinNAPYears,
+			Message: fmt.Sprintf("player %d has been a friend since %d, the pact must last %d years (until %d)",
+				change.TargetPlayer+1, since, r.MinNAPYears, since+r.MinNAPYears),
+		})
+	}
+	return violations
+}
@@ -0,0 +1,78 @@
+package houserules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/xfilereader"
+	"github.com/neper-stars/houston/store"
+)
+
+func loadStore(t *testing.T, path string) *store.GameStore {
+	t.Helper()
+	gs := store.New()
+	require.NoError(t, gs.AddFileWithXY("../../../testdata/"+path))
+	return gs
+}
+
+func relationOrders(relation, target int) *xfilereader.FileInfo {
+	return &xfilereader.FileInfo{Orders: []xfilereader.Order{{
+		Type:  "PlayersRelationChange",
+		Block: blocks.PlayersRelationChangeBlock{Relation: relation, TargetPlayer: target},
+	}}}
+}
+
+func TestParse(t *testing.T) {
+	r, err := Parse([]byte("# house rules\nmax_chaff: 20\nno_pop_drop: true\nmin_nap_years: 5\n"))
+	require.NoError(t, err)
+	assert.Equal(t, Rules{MaxChaff: 20, NoPopDrop: true, MinNAPYears: 5}, *r)
+
+	r, err = Parse(nil)
+	require.NoError(t, err)
+	assert.Equal(t, Rules{}, *r)
+
+	_, err = Parse([]byte("max_chaf: 20\n"))
+	assert.Error(t, err, "unknown keys are rejected")
+	_, err = Parse([]byte("min_nap_years: -1\n"))
+	assert.Error(t, err)
+}
+
+func TestCheck_MinNAPYears(t *testing.T) {
+	// In 2482 the MineMongers have declared the Halflings (player 2) friends
+	before := loadStore(t, "scenario-diplomacy/1/side1/game.m1")
+	friends := loadStore(t, "scenario-diplomacy/2/side1/game.m1")
+	rules := &Rules{MinNAPYears: 5}
+
+	broken := rules.Check(&Submission{
+		Player: 0,
+		Orders: relationOrders(blocks.RelationEnemy, 1),
+		Turns:  []*store.GameStore{friends, before},
+	})
+	require.Len(t, broken, 1)
+	assert.Equal(t, RuleMinNAPYears, broken[0].Rule)
+	assert.Contains(t, broken[0].Message, "player 2 has been a friend since 2482")
+
+	// Confirming the friendship or changing the relation to a non-friend is allowed
+	assert.Empty(t, rules.Check(&Submission{Player: 0, Orders: relationOrders(blocks.RelationFriend, 1), Turns: []*store.GameStore{friends}}))
+	assert.Empty(t, rules.Check(&Submission{Player: 0, Orders: relationOrders(blocks.RelationEnemy, 1), Turns: []*store.GameStore{before}}))
+
+	// Once the pact has lasted long enough
+	later := loadStore(t, "scenario-diplomacy/2/side1/game.m1")
+	later.Turn += 5
+	assert.Empty(t, rules.Check(&Submission{Player: 0, Orders: relationOrders(blocks.RelationEnemy, 1), Turns: []*store.GameStore{friends, later}}))
+}
+
+func TestIsChaff(t *testing.T) {
+	gs := loadStore(t, "scenario-map/game.m1")
+	designs := gs.AllDesigns()
+	require.NotEmpty(t, designs)
+	// Scouts carry a scanner, freighters have cargo holds and enemy designs
+	// are only partly known: none of them is chaff
+	for _, d := range designs {
+		assert.False(t, IsChaff(d), d.Name)
+	}
+	assert.Empty(t, (&Rules{MaxChaff: 1}).Check(&Submission{Player: 0, Turns: []*store.GameStore{gs}}))
+}
@@ -0,0 +1,124 @@
+package maprenderer
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// AnnotationKind selects how an annotation is drawn.
+type AnnotationKind int
+
+const (
+	// AnnotationCircle rings a location.
+	AnnotationCircle AnnotationKind = iota
+	// AnnotationArrow points from (FromX, FromY) to (X, Y).
+	AnnotationArrow
+	// AnnotationCallout draws a boxed text label with a leader line to (X, Y).
+	AnnotationCallout
+)
+
+// Annotation is a mark drawn over a rendered map. Coordinates are game
+// coordinates (light years), so callers never deal with pixels.
+type Annotation struct {
+	Kind         AnnotationKind
+	X, Y         int        // Circle center, arrow tip or callout anchor
+	FromX, FromY int        // Arrow tail
+	Radius       float64    // Circle radius in light years (minimum 6 pixels on screen)
+	Text         string     // Callout text, or label next to a circle or arrow
+	Color        color.RGBA // Zero value means white
+}
+
+// Circle returns an annotation ringing (x, y) with an optional label.
+func Circle(x, y int, radius float64, label string) Annotation {
+	return Annotation{Kind: AnnotationCircle, X: x, Y: y, Radius: radius, Text: label}
+}
+
+// Arrow returns an annotation pointing from (fromX, fromY) to (toX, toY).
+func Arrow(fromX, fromY, toX, toY int, label string) Annotation {
+	return Annotation{Kind: AnnotationArrow, X: toX, Y: toY, FromX: fromX, FromY: fromY, Text: label}
+}
+
+// Callout returns an annotation labelling (x, y) with a boxed text.
+func Callout(x, y int, text string) Annotation {
+	return Annotation{Kind: AnnotationCallout, X: x, Y: y, Text: text}
+}
+
+// Annotate draws annotations over an image rendered by this renderer with
+// the same options (Render or RenderSVGToImage), e.g. to answer "where is
+// planet X?" with a marked-up map.
+func (r *Renderer) Annotate(img *image.RGBA, opts *RenderOptions, annotations []Annotation) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	p := r.Projection(opts)
+
+	for _, a := range annotations {
+		col := a.Color
+		if col == (color.RGBA{}) {
+			col = color.RGBA{255, 255, 255, 255}
+		}
+		px, py := p.ToScreenPixel(a.X, a.Y)
+
+		switch a.Kind {
+		case AnnotationCircle:
+			radius := max(6, int(a.Radius*p.Scale))
+			drawCircleOutline(img, px, py, radius, col)
+			drawCircleOutline(img, px, py, radius+1, col)
+			if a.Text != "" {
+				drawText(img, px+radius+4, py-5, a.Text, col)
+			}
+
+		case AnnotationArrow:
+			fx, fy := p.ToScreenPixel(a.FromX, a.FromY)
+			drawLine(img, fx, fy, px, py, col)
+			drawArrowHead(img, fx, fy, px, py, col)
+			if a.Text != "" {
+				drawText(img, fx+4, fy-12, a.Text, col)
+			}
+
+		case AnnotationCallout:
+			drawCallout(img, px, py, a.Text, col)
+		}
+	}
+}
+
+// drawArrowHead draws the two barbs of an arrow pointing at (x1, y1).
+func drawArrowHead(img *image.RGBA, x0, y0, x1, y1 int, col color.RGBA) {
+	angle := math.Atan2(float64(y1-y0), float64(x1-x0))
+	const length, spread = 10.0, math.Pi / 7
+	for _, a := range []float64{angle + math.Pi - spread, angle + math.Pi + spread} {
+		bx := x1 + int(math.Round(length*math.Cos(a)))
+		by := y1 + int(math.Round(length*math.Sin(a)))
+		drawLine(img, x1, y1, bx, by, col)
+	}
+}
+
+// drawCallout draws text in a dark box offset up and right of the anchor,
+// with a leader line to it. The box is moved inside the image when needed.
+func drawCallout(img *image.RGBA, x, y int, text string, col color.RGBA) {
+	const charWidth, textHeight, pad = 8, 10, 4
+	width := len([]rune(text))*charWidth + 2*pad
+	height := textHeight + 2*pad
+
+	bounds := img.Bounds()
+	bx := min(x+12, bounds.Max.X-width-1)
+	by := max(y-12-height, bounds.Min.Y+1)
+	bx = max(bx, bounds.Min.X+1)
+
+	drawLine(img, x, y, bx, by+height, col)
+	drawFilledCircle(img, x, y, 2, col)
+
+	box := image.Rect(bx, by, bx+width, by+height).Intersect(bounds)
+	bg := color.RGBA{0, 0, 0, 255}
+	for py := box.Min.Y; py < box.Max.Y; py++ {
+		for px := box.Min.X; px < box.Max.X; px++ {
+			img.SetRGBA(px, py, bg)
+		}
+	}
+	drawLine(img, bx, by, bx+width, by, col)
+	drawLine(img, bx, by+height, bx+width, by+height, col)
+	drawLine(img, bx, by, bx, by+height, col)
+	drawLine(img, bx+width, by, bx+width, by+height, col)
+	drawText(img, bx+pad, by+pad, text, col)
+}
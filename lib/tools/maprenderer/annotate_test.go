@@ -0,0 +1,100 @@
+package maprenderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// annotated renders the map scenario and marks it up.
+func annotated(t *testing.T, annotations ...Annotation) (*Renderer, *image.RGBA) {
+	t.Helper()
+	r := New()
+	require.NoError(t, r.LoadFileWithXY("../../../testdata/scenario-map/game.m1"))
+	img := r.Render(DefaultOptions())
+	r.Annotate(img, DefaultOptions(), annotations)
+	return r, img
+}
+
+// planetPixel returns where the renderer projects a planet of the map
+// scenario.
+func planetPixel(t *testing.T, r *Renderer, name string) (int, int) {
+	t.Helper()
+	planet, ok := r.store.PlanetByName(name)
+	require.True(t, ok, name)
+	return r.Projection(nil).ToScreenPixel(planet.X, planet.Y)
+}
+
+func TestAnnotateCircle(t *testing.T) {
+	// Where is Woody, the enemy homeworld? 20 ly around it are 31 pixels
+	red := color.RGBA{255, 0, 0, 255}
+	r, img := annotated(t, Annotation{Kind: AnnotationCircle, X: 1150, Y: 1176, Radius: 20, Color: red})
+	x, y := planetPixel(t, r, "Woody")
+	for _, p := range []image.Point{{x + 31, y}, {x + 32, y}, {x - 31, y}, {x, y + 31}, {x, y - 32}} {
+		assert.Equal(t, red, img.RGBAAt(p.X, p.Y), "ring at %v", p)
+	}
+	assert.NotEqual(t, red, img.RGBAAt(x+20, y))
+
+	// Small rings keep a 6 pixel radius, white by default
+	_, img = annotated(t, Circle(1150, 1176, 1, ""))
+	assert.Equal(t, color.RGBA{255, 255, 255, 255}, img.RGBAAt(x+6, y))
+	assert.Equal(t, color.RGBA{255, 255, 255, 255}, img.RGBAAt(x+7, y))
+}
+
+func TestAnnotateArrow(t *testing.T) {
+	// From the Gates starbase to Woody, heading left and up on screen
+	green := color.RGBA{0, 255, 0, 255}
+	a := Arrow(1289, 1239, 1150, 1176, "ATTACK")
+	a.Color = green
+	r, img := annotated(t, a)
+	fx, fy := planetPixel(t, r, "Gates")
+	tx, ty := planetPixel(t, r, "Woody")
+	assert.Equal(t, green, img.RGBAAt(fx, fy))
+	assert.Equal(t, green, img.RGBAAt(tx, ty))
+	assert.Equal(t, green, img.RGBAAt((fx+tx)/2, (fy+ty)/2))
+
+	// The barbs trail behind the tip, to its right
+	var barbs int
+	for y := ty - 10; y <= ty+10; y++ {
+		for x := tx - 10; x <= tx+10; x++ {
+			if img.RGBAAt(x, y) == green {
+				assert.GreaterOrEqual(t, x, tx)
+				barbs++
+			}
+		}
+	}
+	assert.Greater(t, barbs, 20)
+
+	// The label starts above the tail
+	var label int
+	for y := fy - 12; y < fy-7; y++ {
+		for x := fx + 4; x < fx+4+6*8; x++ {
+			if img.RGBAAt(x, y) == green {
+				label++
+			}
+		}
+	}
+	assert.Positive(t, label)
+}
+
+func TestAnnotateCallout(t *testing.T) {
+	// Oasis, low on the map: the box goes up and right with a leader line
+	r, img := annotated(t, Callout(1240, 1025, "OASIS"))
+	white, black := color.RGBA{255, 255, 255, 255}, color.RGBA{0, 0, 0, 255}
+	x, y := planetPixel(t, r, "Oasis")
+	assert.Equal(t, white, img.RGBAAt(x, y), "anchor dot")
+	width, height := 5*8+2*4, 10+2*4
+	bx, by := x+12, y-12-height
+	assert.Equal(t, white, img.RGBAAt(bx, by))
+	assert.Equal(t, white, img.RGBAAt(bx+width, by+height))
+	assert.Equal(t, black, img.RGBAAt(bx+1, by+1))
+
+	// Elder is on the top edge of the galaxy: the box is kept inside
+	r, img = annotated(t, Callout(1267, 1381, "ELDER"))
+	x, _ = planetPixel(t, r, "Elder")
+	assert.Equal(t, white, img.RGBAAt(x+12, 1))
+	assert.Equal(t, white, img.RGBAAt(x+12+width, 1+height))
+}
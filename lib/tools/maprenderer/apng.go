@@ -0,0 +1,161 @@
+package maprenderer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"os"
+)
+
+// pngSignature starts every PNG file.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// SaveAPNG saves all frames as an animated PNG.
+func (a *Animator) SaveAPNG(filename string, delayMs int) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return a.WriteAPNG(f, delayMs)
+}
+
+// WriteAPNG writes all frames as an animated PNG to an io.Writer. Unlike a
+// GIF, frames keep their full colors; viewers without APNG support show the
+// first frame.
+func (a *Animator) WriteAPNG(w io.Writer, delayMs int) error {
+	frames, delays, err := a.encodeFrames(delayMs, encodePNGFrame)
+	if err != nil {
+		return err
+	}
+	if err := writeAPNG(w, frames, delays); err != nil {
+		return fmt.Errorf("failed to encode APNG: %w", err)
+	}
+	return nil
+}
+
+// pngFrame is a frame encoded by image/png, split into its header and
+// image data.
+type pngFrame struct {
+	ihdr []byte
+	idat []byte // Concatenated IDAT chunks
+}
+
+// translucentImage reports an image as translucent, so that image/png
+// writes it with an alpha channel even when all its pixels are opaque.
+type translucentImage struct {
+	image.Image
+}
+
+func (translucentImage) Opaque() bool { return false }
+
+// encodePNGFrame encodes a frame as an RGBA PNG: all frames of an APNG share
+// the pixel format of its IHDR, whether or not they are opaque.
+func encodePNGFrame(img *image.RGBA) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := png.Encoder{CompressionLevel: png.BestSpeed}
+	if err := enc.Encode(&buf, translucentImage{img}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// splitPNG reads the IHDR and IDAT chunks of a PNG file.
+func splitPNG(data []byte) (pngFrame, error) {
+	var f pngFrame
+	if !bytes.HasPrefix(data, pngSignature) {
+		return f, fmt.Errorf("not a PNG")
+	}
+	data = data[len(pngSignature):]
+	for len(data) >= 12 {
+		n := int(binary.BigEndian.Uint32(data))
+		if len(data) < 12+n {
+			break
+		}
+		typ, body := string(data[4:8]), data[8:8+n]
+		switch typ {
+		case "IHDR":
+			f.ihdr = body
+		case "IDAT":
+			f.idat = append(f.idat, body...)
+		}
+		data = data[12+n:]
+	}
+	if f.ihdr == nil || f.idat == nil {
+		return f, fmt.Errorf("PNG without image data")
+	}
+	return f, nil
+}
+
+// writeAPNG assembles PNG files into an animation looping forever.
+func writeAPNG(w io.Writer, frames [][]byte, delaysMs []int) error {
+	parts := make([]pngFrame, len(frames))
+	for i, data := range frames {
+		f, err := splitPNG(data)
+		if err != nil {
+			return fmt.Errorf("frame %d: %w", i, err)
+		}
+		// Frames cover the whole canvas: same size and pixel format
+		if i > 0 && !bytes.Equal(f.ihdr, parts[0].ihdr) {
+			return fmt.Errorf("frame %d: size or format differs from the first frame", i)
+		}
+		parts[i] = f
+	}
+
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+	writePNGChunk(&buf, "IHDR", parts[0].ihdr)
+
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl[0:], uint32(len(parts)))
+	binary.BigEndian.PutUint32(actl[4:], 0) // Loop forever
+	writePNGChunk(&buf, "acTL", actl)
+
+	width := binary.BigEndian.Uint32(parts[0].ihdr[0:])
+	height := binary.BigEndian.Uint32(parts[0].ihdr[4:])
+	seq := uint32(0) // Shared by fcTL and fdAT chunks
+	for i, f := range parts {
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], width)
+		binary.BigEndian.PutUint32(fctl[8:], height)
+		// X and Y offsets stay 0
+		binary.BigEndian.PutUint16(fctl[20:], uint16(min(delaysMs[i], 0xFFFF)))
+		binary.BigEndian.PutUint16(fctl[22:], 1000)
+		// Dispose and blend ops stay 0: frames are opaque and replace the canvas
+		writePNGChunk(&buf, "fcTL", fctl)
+		seq++
+
+		if i == 0 {
+			writePNGChunk(&buf, "IDAT", f.idat)
+			continue
+		}
+		fdat := make([]byte, 4+len(f.idat))
+		binary.BigEndian.PutUint32(fdat, seq)
+		copy(fdat[4:], f.idat)
+		writePNGChunk(&buf, "fdAT", fdat)
+		seq++
+	}
+	writePNGChunk(&buf, "IEND", nil)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writePNGChunk(buf *bytes.Buffer, typ string, data []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(data)))
+	buf.Write(n[:])
+	crc := crc32.NewIEEE()
+	_, _ = crc.Write([]byte(typ))
+	_, _ = crc.Write(data)
+	buf.WriteString(typ)
+	buf.Write(data)
+	binary.BigEndian.PutUint32(n[:], crc.Sum32())
+	buf.Write(n[:])
+}
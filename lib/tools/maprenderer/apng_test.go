@@ -0,0 +1,150 @@
+package maprenderer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pngChunk is a chunk of a PNG file.
+type pngChunk struct {
+	typ  string
+	data []byte
+}
+
+// readPNGChunks walks the chunks of a PNG file, checking their CRCs.
+func readPNGChunks(t *testing.T, data []byte) []pngChunk {
+	t.Helper()
+	require.True(t, bytes.HasPrefix(data, pngSignature), "PNG signature")
+	data = data[len(pngSignature):]
+	var chunks []pngChunk
+	for len(data) > 0 {
+		require.GreaterOrEqual(t, len(data), 12, "truncated chunk")
+		n := int(binary.BigEndian.Uint32(data))
+		require.GreaterOrEqual(t, len(data), 12+n, "truncated %s chunk", data[4:8])
+		typ, body := string(data[4:8]), data[8:8+n]
+		assert.Equal(t, crc32.ChecksumIEEE(data[4:8+n]), binary.BigEndian.Uint32(data[8+n:]), "%s CRC", typ)
+		chunks = append(chunks, pngChunk{typ, body})
+		data = data[12+n:]
+	}
+	return chunks
+}
+
+// apngFrame is a frame read back from an APNG file.
+type apngFrame struct {
+	delayMs int
+	img     image.Image
+}
+
+// readAPNG reads the frames of an APNG file, checking the sequence numbers
+// of the fcTL and fdAT chunks, and decodes each as a standalone PNG.
+func readAPNG(t *testing.T, data []byte) []apngFrame {
+	t.Helper()
+	chunks := readPNGChunks(t, data)
+	require.Equal(t, "IHDR", chunks[0].typ)
+	require.Equal(t, "acTL", chunks[1].typ)
+	assert.Equal(t, "IEND", chunks[len(chunks)-1].typ)
+	ihdr := chunks[0].data
+	numFrames := int(binary.BigEndian.Uint32(chunks[1].data))
+	assert.Zero(t, binary.BigEndian.Uint32(chunks[1].data[4:]), "loop forever")
+
+	standalone := func(idat []byte) image.Image {
+		var buf bytes.Buffer
+		buf.Write(pngSignature)
+		writePNGChunk(&buf, "IHDR", ihdr)
+		writePNGChunk(&buf, "IDAT", idat)
+		writePNGChunk(&buf, "IEND", nil)
+		img, err := png.Decode(&buf)
+		require.NoError(t, err)
+		return img
+	}
+
+	var frames []apngFrame
+	seq := uint32(0)
+	for _, c := range chunks[2 : len(chunks)-1] {
+		switch c.typ {
+		case "fcTL":
+			require.Len(t, c.data, 26)
+			assert.Equal(t, seq, binary.BigEndian.Uint32(c.data), "fcTL sequence number")
+			assert.Equal(t, ihdr[:8], c.data[4:12], "frame size")
+			num := int(binary.BigEndian.Uint16(c.data[20:]))
+			den := int(binary.BigEndian.Uint16(c.data[22:]))
+			frames = append(frames, apngFrame{delayMs: num * 1000 / den})
+			seq++
+		case "IDAT":
+			require.Len(t, frames, 1, "IDAT belongs to the first frame")
+			frames[0].img = standalone(c.data)
+		case "fdAT":
+			assert.Equal(t, seq, binary.BigEndian.Uint32(c.data), "fdAT sequence number")
+			require.NotEmpty(t, frames)
+			frames[len(frames)-1].img = standalone(c.data[4:])
+			seq++
+		}
+	}
+	assert.Len(t, frames, numFrames)
+	return frames
+}
+
+func TestWriteAPNGRoundTrip(t *testing.T) {
+	frames := testFrames(t)
+	delays := []int{500, 125, 500}
+
+	encoded := make([][]byte, len(frames))
+	for i, img := range frames {
+		var err error
+		encoded[i], err = encodePNGFrame(img)
+		require.NoError(t, err)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, writeAPNG(&buf, encoded, delays))
+
+	got := readAPNG(t, buf.Bytes())
+	require.Len(t, got, len(frames))
+	for i, f := range got {
+		assert.Equal(t, delays[i], f.delayMs, "frame %d", i)
+		assertSamePixels(t, frames[i], f.img)
+	}
+
+	// Viewers without APNG support show the first frame
+	first, err := png.Decode(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assertSamePixels(t, frames[0], first)
+}
+
+func TestWriteAPNGSizeMismatch(t *testing.T) {
+	small, err := encodePNGFrame(image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	require.NoError(t, err)
+	large, err := encodePNGFrame(image.NewRGBA(image.Rect(0, 0, 8, 4)))
+	require.NoError(t, err)
+
+	err = writeAPNG(&bytes.Buffer{}, [][]byte{small, large}, []int{100, 100})
+	assert.ErrorContains(t, err, "frame 1")
+}
+
+func TestAnimatorWriteAPNG(t *testing.T) {
+	a := NewAnimator()
+	a.SetOptions(NewOptions(WithSize(120, 90)))
+	a.SetInBetweenFrames(2)
+	for _, year := range []string{"2470", "2471"} {
+		require.NoError(t, a.AddFile(historyDir+"game-"+year+".m1"))
+	}
+	a.SortByYear()
+
+	var buf bytes.Buffer
+	require.NoError(t, a.WriteAPNG(&buf, 400))
+
+	got := readAPNG(t, buf.Bytes())
+	var delays []int
+	for _, f := range got {
+		delays = append(delays, f.delayMs)
+		assert.Equal(t, image.Pt(120, 90), f.img.Bounds().Size())
+	}
+	// Two turns and two frames between them
+	assert.Equal(t, []int{400, 200, 200, 400}, delays)
+}
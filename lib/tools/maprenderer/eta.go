@@ -0,0 +1,42 @@
+package maprenderer
+
+import (
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/geom"
+	"github.com/neper-stars/houston/store"
+)
+
+// waypointETA is a waypoint of a fleet and the years the fleet needs to
+// reach it.
+type waypointETA struct {
+	X, Y  int
+	Years int
+}
+
+// fleetETAs returns the years a fleet needs to reach each of its waypoints,
+// in order. Each leg is flown at the warp of the waypoint it ends at, and a
+// fleet stops at every waypoint, so the years of the legs add up; a
+// stargate jump takes a year. Waypoints at the fleet's position are left
+// out, and the list stops at the first leg the fleet will not fly (warp 0).
+func fleetETAs(fleet *store.FleetEntity) []waypointETA {
+	var etas []waypointETA
+	x, y, years := fleet.X, fleet.Y, 0
+	for _, wp := range fleet.Waypoints {
+		distance := geom.Distance(x, y, wp.X, wp.Y)
+		if distance == 0 {
+			continue
+		}
+		if wp.Warp == blocks.WarpStargate {
+			years++
+		} else {
+			leg := geom.YearsAtWarp(distance, wp.Warp)
+			if leg < 0 {
+				break
+			}
+			years += leg
+		}
+		etas = append(etas, waypointETA{wp.X, wp.Y, years})
+		x, y = wp.X, wp.Y
+	}
+	return etas
+}
@@ -0,0 +1,85 @@
+package maprenderer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+func TestFleetETAs(t *testing.T) {
+	tests := []struct {
+		name   string
+		year   int
+		number int
+		want   []waypointETA
+	}{
+		{
+			name:   "one leg",
+			year:   2470,
+			number: 2, // Armed Probe #3: 361 ly at warp 7
+			want:   []waypointETA{{1943, 2087, 8}},
+		},
+		{
+			name:   "legs add up",
+			year:   2470,
+			number: 3, // Purgatory Pump: 110 then 165 ly at warp 3
+			want:   []waypointETA{{1273, 1341, 13}, {1436, 1364, 32}},
+		},
+		{
+			name:   "arriving next year",
+			year:   2472,
+			number: 12, // Stealth Scout #13: 19 ly at warp 6
+			want:   []waypointETA{{1991, 2054, 1}},
+		},
+		{
+			name:   "going back",
+			year:   2474,
+			number: 11, // Rubber Pump, on its first waypoint
+			want:   []waypointETA{{1273, 1341, 6}, {1249, 1149, 12}},
+		},
+		{
+			name:   "not moving",
+			year:   2470,
+			number: 0, // Fleet 1
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := loadHistory(t, tt.year)
+			assert.Equal(t, tt.want, fleetETAs(fleetByNumber(t, r, 0, tt.number)))
+		})
+	}
+}
+
+func TestFleetETAsWarp(t *testing.T) {
+	fleet := &store.FleetEntity{X: 100, Y: 100, Waypoints: []*store.WaypointEntity{
+		{X: 100, Y: 100, Warp: 5},
+		{X: 400, Y: 100, Warp: blocks.WarpStargate}, // A jump takes a year
+		{X: 400, Y: 181, Warp: 9},                   // 81 ly in one year
+		{X: 400, Y: 182, Warp: 0},                   // Never reached
+		{X: 400, Y: 300, Warp: 9},
+	}}
+	assert.Equal(t, []waypointETA{{400, 100, 1}, {400, 181, 2}}, fleetETAs(fleet))
+}
+
+func TestRenderSVGFleetETAs(t *testing.T) {
+	r := loadHistory(t, 2470)
+	opts := NewOptions(WithSize(400, 300), WithFleetPaths(5), WithFleetETAs(true))
+	svg := r.RenderSVG(opts)
+
+	// Labels sit next to the waypoints, in the owner's color
+	p := r.Projection(opts)
+	col := opts.PlayerColor(0)
+	for _, eta := range []waypointETA{{1273, 1341, 13}, {1436, 1364, 32}, {1943, 2087, 8}} {
+		x, y := p.ToScreen(eta.X, eta.Y)
+		assert.Contains(t, svg, fmt.Sprintf(`<text x="%.1f" y="%.1f" fill="rgb(%d,%d,%d)" font-size="8" font-family="monospace">%dy</text>`,
+			x+4, y+9, col.R, col.G, col.B, eta.Years))
+	}
+
+	svg = r.RenderSVG(NewOptions(WithSize(400, 300), WithFleetPaths(5)))
+	assert.NotContains(t, svg, `font-size="8"`)
+}
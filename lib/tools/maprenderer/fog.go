@@ -0,0 +1,135 @@
+package maprenderer
+
+import (
+	"github.com/neper-stars/houston/geom"
+	"github.com/neper-stars/houston/store"
+	"github.com/neper-stars/houston/visibility"
+)
+
+// scanner is a planet or fleet of the viewing player and its scanner
+// ranges in light years.
+type scanner struct {
+	x, y         int
+	normal, pen  int
+	isFleet      bool
+	detectsFleet func(target *store.FleetEntity) bool
+}
+
+// fog is what a player could see of the map, from the scanners of their
+// planets and fleets (see RenderOptions.ViewAsPlayer). A nil fog sees
+// everything.
+//
+// The player sees their own objects; the fleets their scanners detect,
+// cloaking and tachyon detectors included; minefields and wormholes in
+// range of a scanner; and the owner and starbase of planets in range of a
+// penetrating scanner or orbited by one of their fleets. Other planets are
+// drawn as unowned.
+type fog struct {
+	player   int
+	scanners []scanner
+}
+
+// fog returns the view of the player of the options, or nil when the map
+// is drawn whole.
+func (r *Renderer) fog(opts *RenderOptions) *fog {
+	if opts.ViewAsPlayer <= 0 {
+		return nil
+	}
+	f := &fog{player: opts.ViewAsPlayer - 1}
+	gs := r.store
+	for _, planet := range gs.PlanetsByOwner(f.player) {
+		s := scanner{x: planet.X, y: planet.Y}
+		s.normal, s.pen = planet.GetScannerRanges(gs)
+		s.detectsFleet = func(target *store.FleetEntity) bool {
+			return visibility.CanPlanetDetectFleet(planet, target, gs)
+		}
+		f.scanners = append(f.scanners, s)
+	}
+	for _, fleet := range gs.FleetsByOwner(f.player) {
+		s := scanner{x: fleet.X, y: fleet.Y, isFleet: true}
+		s.normal, s.pen = fleet.GetScannerRanges(gs)
+		s.detectsFleet = func(target *store.FleetEntity) bool {
+			return visibility.CanDetect(fleet, target, gs)
+		}
+		f.scanners = append(f.scanners, s)
+	}
+	return f
+}
+
+// seesFleet reports whether the player sees a fleet.
+func (f *fog) seesFleet(fleet *store.FleetEntity) bool {
+	if f == nil || fleet.Owner == f.player {
+		return true
+	}
+	for _, s := range f.scanners {
+		// Cloaking only shortens the ranges
+		if geom.Distance(s.x, s.y, fleet.X, fleet.Y) <= float64(max(s.normal, s.pen)) && s.detectsFleet(fleet) {
+			return true
+		}
+	}
+	return false
+}
+
+// seesPlanet reports whether the player knows the owner and starbase of a
+// planet.
+func (f *fog) seesPlanet(planet *store.PlanetEntity) bool {
+	if f == nil || planet.Owner == f.player {
+		return true
+	}
+	for _, s := range f.scanners {
+		if s.isFleet && s.x == planet.X && s.y == planet.Y {
+			return true
+		}
+		if geom.Distance(s.x, s.y, planet.X, planet.Y) <= float64(s.pen) {
+			return true
+		}
+	}
+	return false
+}
+
+// seesArea reports whether a scanner of the player reaches a circle of the
+// map.
+func (f *fog) seesArea(x, y int, radius float64) bool {
+	if f == nil {
+		return true
+	}
+	for _, s := range f.scanners {
+		if geom.Distance(s.x, s.y, x, y) <= float64(max(s.normal, s.pen))+radius {
+			return true
+		}
+	}
+	return false
+}
+
+// seesMinefield reports whether the player sees a minefield.
+func (f *fog) seesMinefield(mf *store.ObjectEntity) bool {
+	return f == nil || mf.Owner == f.player || f.seesArea(mf.X, mf.Y, mf.Radius())
+}
+
+// planet returns the owner and starbase of a planet as the player knows
+// them: unowned without a starbase when they could not scan it.
+func (f *fog) planet(planet *store.PlanetEntity) (owner int, hasStarbase bool) {
+	if !f.seesPlanet(planet) {
+		return -1, false
+	}
+	return planet.Owner, planet.HasStarbase
+}
+
+// wormholes returns the wormholes the player sees.
+func (f *fog) wormholes(wormholes []*store.ObjectEntity) []*store.ObjectEntity {
+	if f == nil {
+		return wormholes
+	}
+	var seen []*store.ObjectEntity
+	for _, wh := range wormholes {
+		if f.seesArea(wh.X, wh.Y, 0) {
+			seen = append(seen, wh)
+		}
+	}
+	return seen
+}
+
+// owns reports whether an object of a player is the viewing player's.
+func (f *fog) owns(owner int) bool {
+	return f == nil || owner == f.player
+}
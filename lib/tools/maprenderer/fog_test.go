@@ -0,0 +1,117 @@
+package maprenderer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+// bothPlayers returns a renderer of both players' turns of 2470 of the
+// history scenario.
+func bothPlayers(t *testing.T) *Renderer {
+	t.Helper()
+	r := New()
+	for _, name := range []string{"game-2470.m1", "game-2470.m2"} {
+		require.NoError(t, r.LoadFileWithXY(historyDir+name))
+	}
+	return r
+}
+
+func TestFogNil(t *testing.T) {
+	r := bothPlayers(t)
+	assert.Nil(t, r.fog(DefaultOptions()))
+
+	var f *fog
+	assert.True(t, f.seesFleet(&store.FleetEntity{Owner: 1}))
+	assert.True(t, f.seesPlanet(&store.PlanetEntity{Owner: 1}))
+	assert.True(t, f.seesArea(0, 0, 0))
+	assert.True(t, f.owns(3))
+	wormholes := r.wormholes()
+	assert.Equal(t, wormholes, f.wormholes(wormholes))
+}
+
+func TestFogScanners(t *testing.T) {
+	detects := true
+	f := &fog{player: 0, scanners: []scanner{
+		{x: 100, y: 100, normal: 50, pen: 20},
+		{x: 500, y: 500, isFleet: true},
+	}}
+	for i := range f.scanners {
+		f.scanners[i].detectsFleet = func(*store.FleetEntity) bool { return detects }
+	}
+
+	// Fleets in normal range, unless the scanner fails to detect them
+	assert.True(t, f.seesFleet(&store.FleetEntity{Owner: 1, X: 150, Y: 100}))
+	assert.False(t, f.seesFleet(&store.FleetEntity{Owner: 1, X: 151, Y: 100}))
+	detects = false
+	assert.False(t, f.seesFleet(&store.FleetEntity{Owner: 1, X: 120, Y: 100}), "cloaked")
+	assert.True(t, f.seesFleet(&store.FleetEntity{Owner: 0, X: 900, Y: 900}), "own fleet")
+
+	// Planet owners in penetrating range or orbited by a fleet
+	in := &store.PlanetEntity{Owner: 1, HasStarbase: true, X: 100, Y: 120}
+	out := &store.PlanetEntity{Owner: 1, HasStarbase: true, X: 100, Y: 121}
+	orbited := &store.PlanetEntity{Owner: 1, X: 500, Y: 500}
+	assert.True(t, f.seesPlanet(in))
+	assert.False(t, f.seesPlanet(out))
+	assert.True(t, f.seesPlanet(orbited))
+	owner, starbase := f.planet(in)
+	assert.Equal(t, 1, owner)
+	assert.True(t, starbase)
+	owner, starbase = f.planet(out)
+	assert.Equal(t, -1, owner)
+	assert.False(t, starbase)
+
+	// Areas reached by a scanner, their radius included
+	assert.True(t, f.seesArea(100, 160, 10))
+	assert.False(t, f.seesArea(100, 160, 9))
+	assert.True(t, f.seesMinefield(&store.ObjectEntity{Owner: 0, X: 900, Y: 900}), "own minefield")
+	assert.True(t, f.owns(0))
+	assert.False(t, f.owns(1))
+}
+
+func TestFogViewAsPlayer(t *testing.T) {
+	r := bothPlayers(t)
+	fleets := r.store.AllFleets()
+	require.Len(t, fleets, 20)
+
+	seen := func(f *fog) int {
+		n := 0
+		for _, fleet := range fleets {
+			if f.seesFleet(fleet) {
+				n++
+			}
+		}
+		return n
+	}
+
+	one := r.fog(NewOptions(WithViewAsPlayer(1)))
+	require.NotNil(t, one)
+	assert.Equal(t, 0, one.player)
+	assert.Equal(t, 19, seen(one))
+	assert.False(t, one.seesFleet(fleetByNumber(t, r, 1, 1)))
+	assert.Len(t, one.wormholes(r.wormholes()), 2)
+
+	two := r.fog(NewOptions(WithViewAsPlayer(2)))
+	assert.Equal(t, 7, seen(two))
+	assert.True(t, two.seesFleet(fleetByNumber(t, r, 0, 12)), "Stealth Scout #13 scouting player 2")
+	assert.False(t, two.seesFleet(fleetByNumber(t, r, 0, 0)))
+	assert.Empty(t, two.wormholes(r.wormholes()))
+	for _, planet := range r.store.PlanetsByOwner(0) {
+		owner, _ := two.planet(planet)
+		assert.Equal(t, -1, owner, "player 2 does not reach player 1's planets")
+	}
+}
+
+func TestRenderSVGViewAsPlayer(t *testing.T) {
+	r := bothPlayers(t)
+	whole := r.RenderSVG(NewOptions(WithSize(400, 300)))
+	assert.Contains(t, whole, `id="`+FleetElementID(0, 0)+`"`)
+
+	view := r.RenderSVG(NewOptions(WithSize(400, 300), WithViewAsPlayer(2)))
+	assert.NotContains(t, view, `id="`+FleetElementID(0, 0)+`"`)
+	assert.Contains(t, view, `id="`+FleetElementID(0, 12)+`"`)
+	assert.Contains(t, view, `id="`+FleetElementID(1, 1)+`"`)
+}
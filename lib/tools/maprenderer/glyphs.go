@@ -0,0 +1,134 @@
+package maprenderer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/neper-stars/houston/lib/tools/summary"
+	"github.com/neper-stars/houston/store"
+)
+
+// glyphShapes are the outlines of the fleet glyphs of each role, in units of
+// the glyph size, heading along +X. Fleets whose designs are unknown use the
+// RoleOther diamond.
+var glyphShapes = map[summary.Role][][2]float64{
+	// Triangle
+	summary.RoleWarship: {{1, 0}, {-0.5, 0.87}, {-0.5, -0.87}},
+	// Chevron
+	summary.RoleBomber: {{1, 0}, {-0.8, 0.9}, {-0.3, 0}, {-0.8, -0.9}},
+	// Pentagon
+	summary.RoleColonizer: regularPolygon(5, 1),
+	// Four-pointed star
+	summary.RoleMinelayer: {{1.1, 0}, {0.3, 0.3}, {0, 1.1}, {-0.3, 0.3}, {-1.1, 0}, {-0.3, -0.3}, {0, -1.1}, {0.3, -0.3}},
+	// Hexagon
+	summary.RoleMiner: regularPolygon(6, 1),
+	// Square
+	summary.RoleFreighter: {{0.8, 0.8}, {-0.8, 0.8}, {-0.8, -0.8}, {0.8, -0.8}},
+	// Dart
+	summary.RoleScout: {{1.2, 0}, {-0.6, 0.5}, {-0.2, 0}, {-0.6, -0.5}},
+	// Diamond
+	summary.RoleOther: {{1, 0}, {0, 1}, {-1, 0}, {0, -1}},
+}
+
+// regularPolygon returns the corners of a regular polygon of the given
+// radius with a corner on +X.
+func regularPolygon(sides int, radius float64) [][2]float64 {
+	points := make([][2]float64, sides)
+	for i := range points {
+		a := 2 * math.Pi * float64(i) / float64(sides)
+		points[i] = [2]float64{math.Cos(a) * radius, math.Sin(a) * radius}
+	}
+	return points
+}
+
+// glyphPoints returns the outline of the glyph of a role centered on
+// (cx, cy) and turned toward angle (radians, screen coordinates).
+func glyphPoints(role summary.Role, cx, cy, size, angle float64) [][2]float64 {
+	shape, ok := glyphShapes[role]
+	if !ok {
+		shape = glyphShapes[summary.RoleOther]
+	}
+	sin, cos := math.Sincos(angle)
+	points := make([][2]float64, len(shape))
+	for i, p := range shape {
+		x, y := p[0]*size, p[1]*size
+		points[i] = [2]float64{cx + x*cos - y*sin, cy + x*sin + y*cos}
+	}
+	return points
+}
+
+// glyphUp is the angle of stationary fleet glyphs: pointing up the screen.
+const glyphUp = -math.Pi / 2
+
+// roleClass returns the SVG class of a fleet role (e.g. "role-minelayer").
+func roleClass(role summary.Role) string {
+	return "role-" + strings.ToLower(role.String())
+}
+
+// fleetRoles returns the role of each fleet in view, inferred from its
+// designs.
+func (r *Renderer) fleetRoles(view *fog) map[*store.FleetEntity]summary.Role {
+	roles := make(map[*store.FleetEntity]summary.Role)
+	for _, fleet := range r.store.AllFleets() {
+		if !view.seesFleet(fleet) {
+			continue
+		}
+		roles[fleet] = summary.FleetRole(r.store, fleet)
+	}
+	return roles
+}
+
+// legendRoles returns the roles of the fleets on the map, in priority order.
+func legendRoles(roles map[*store.FleetEntity]summary.Role) []summary.Role {
+	present := make(map[summary.Role]bool)
+	for _, role := range roles {
+		present[role] = true
+	}
+	var result []summary.Role
+	for _, role := range summary.Roles {
+		if present[role] {
+			result = append(result, role)
+		}
+	}
+	return result
+}
+
+// legendGlyphColor is the color of the glyph legend entries.
+var legendGlyphColor = color.RGBA{200, 200, 200, 255}
+
+// FleetGlyph adds the glyph of a fleet role: turned toward its heading and
+// lightly filled when moving, upright and hollow when stationary.
+func (b *SVGBuilder) FleetGlyph(cx, cy, size float64, role summary.Role, moving bool, angle float64, col color.RGBA) *SVGBuilder {
+	fill := "none"
+	if moving {
+		fill = fmt.Sprintf("rgba(%d,%d,%d,0.4)", col.R, col.G, col.B)
+	} else {
+		angle = glyphUp
+	}
+	stroke := fmt.Sprintf("rgba(%d,%d,%d,0.8)", col.R, col.G, col.B)
+	return b.Polygon(glyphPoints(role, cx, cy, size, angle), fill, stroke, 1)
+}
+
+// GlyphLegendItem adds a fleet glyph legend entry.
+func (b *SVGBuilder) GlyphLegendItem(x, y float64, role summary.Role, col color.RGBA) *SVGBuilder {
+	b.FleetGlyph(x+5, y+5, 4, role, false, 0, col)
+	b.Text(x+15, y+9, role.String(), col, 10)
+	return b
+}
+
+// drawFleetGlyph draws the outline of the glyph of a fleet role on a raster
+// image, turned toward the fleet heading (dx, dy) or upright when stationary.
+func drawFleetGlyph(img *image.RGBA, cx, cy int, role summary.Role, dx, dy float64, col color.RGBA) {
+	angle := glyphUp
+	if math.Abs(dx) >= 0.5 || math.Abs(dy) >= 0.5 {
+		angle = math.Atan2(dy, dx)
+	}
+	points := glyphPoints(role, float64(cx), float64(cy), 4, angle)
+	for i, p := range points {
+		q := points[(i+1)%len(points)]
+		drawLine(img, int(math.Round(p[0])), int(math.Round(p[1])), int(math.Round(q[0])), int(math.Round(q[1])), col)
+	}
+}
@@ -0,0 +1,95 @@
+package maprenderer
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/lib/tools/summary"
+	"github.com/neper-stars/houston/store"
+)
+
+// fleetByNumber returns a fleet of the renderer's store.
+func fleetByNumber(t *testing.T, r *Renderer, owner, number int) *store.FleetEntity {
+	t.Helper()
+	fleet, ok := r.store.Fleet(owner, number)
+	require.True(t, ok, "no fleet %d of player %d", number, owner)
+	return fleet
+}
+
+func TestFleetRoles(t *testing.T) {
+	// A minelaying player: two minelayers sit in their fields
+	r := New()
+	require.NoError(t, r.LoadFileWithXY("../../../testdata/scenario-map/minefields/game.m1"))
+	roles := r.fleetRoles(nil)
+	require.Len(t, roles, len(r.store.AllFleets()))
+
+	tests := []struct {
+		owner, number int
+		want          summary.Role
+	}{
+		{0, 0, summary.RoleScout},
+		{0, 1, summary.RoleFreighter},
+		{0, 2, summary.RoleMinelayer},
+		{0, 3, summary.RoleMinelayer},
+		{0, 11, summary.RoleColonizer},
+		{1, 3, summary.RoleFreighter}, // Known from the other player's brief design
+		{1, 0, summary.RoleOther},
+	}
+	for _, tt := range tests {
+		fleet := fleetByNumber(t, r, tt.owner, tt.number)
+		assert.Equal(t, tt.want, roles[fleet], "fleet %d of player %d", tt.number, tt.owner)
+	}
+
+	assert.Equal(t, []summary.Role{
+		summary.RoleColonizer, summary.RoleMinelayer, summary.RoleFreighter,
+		summary.RoleScout, summary.RoleOther,
+	}, legendRoles(roles))
+	assert.Empty(t, legendRoles(nil))
+}
+
+func TestGlyphPoints(t *testing.T) {
+	for _, role := range summary.Roles {
+		assert.Contains(t, glyphShapes, role, role.String())
+	}
+
+	// A warship heading right points its nose along +X, upright when still
+	points := glyphPoints(summary.RoleWarship, 100, 50, 4, 0)
+	require.Len(t, points, 3)
+	assert.InDelta(t, 104, points[0][0], 1e-9)
+	assert.InDelta(t, 50, points[0][1], 1e-9)
+	points = glyphPoints(summary.RoleWarship, 100, 50, 4, glyphUp)
+	assert.InDelta(t, 100, points[0][0], 1e-9)
+	assert.InDelta(t, 46, points[0][1], 1e-9)
+
+	// Unknown roles fall back to the diamond
+	assert.Equal(t, glyphPoints(summary.RoleOther, 0, 0, 2, 1), glyphPoints(summary.Role(99), 0, 0, 2, 1))
+	for _, p := range glyphPoints(summary.RoleMiner, 10, 10, 3, math.Pi/3) {
+		assert.InDelta(t, 3, math.Hypot(p[0]-10, p[1]-10), 1e-9)
+	}
+}
+
+func TestRenderSVGFleetGlyphs(t *testing.T) {
+	r := New()
+	require.NoError(t, r.LoadFileWithXY("../../../testdata/scenario-map/minefields/game.m1"))
+	svg := r.RenderSVG(DefaultOptions())
+
+	// Minelayers are hollow four-pointed stars: stationary in their fields
+	assert.Contains(t, svg, `<g id="fleet-0-2" class="fleet player-0 role-minelayer stationary">`+"\n"+`<polygon points="493.6,213.8 `)
+	assert.Contains(t, svg, `<g id="fleet-0-1" class="fleet player-0 role-freighter moving">`+"\n"+`<polygon points="144.8,107.5 138.7,105.3 140.9,99.3 146.9,101.4" fill="rgba(255,3,3,0.4)"`)
+
+	// The legend lists the roles on the map after the players
+	var legend []string
+	for _, line := range strings.Split(svg, "\n") {
+		if role, ok := strings.CutPrefix(line, `<g class="legend-item role-`); ok {
+			legend = append(legend, strings.TrimSuffix(role, `">`))
+		}
+	}
+	assert.Equal(t, []string{"colonizer", "minelayer", "freighter", "scout", "other"}, legend)
+
+	opts := NewOptions(WithPlainFleets(true))
+	assert.NotContains(t, r.RenderSVG(opts), "role-")
+}
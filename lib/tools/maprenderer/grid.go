@@ -0,0 +1,108 @@
+package maprenderer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"slices"
+)
+
+// GridOptions lays out the turns of an Animator as a single image of small
+// maps, one per year, for comparing turns side by side where an animation
+// cannot be posted. Each map has the size of the animator's RenderOptions.
+type GridOptions struct {
+	// Columns is the number of maps per row. Zero picks a near-square grid.
+	Columns int
+	// Years are the years to show, in order. Empty shows every frame.
+	Years []int
+	// Gap is the space between maps, in pixels.
+	Gap int
+}
+
+// gridGapColor shows between the maps of a grid, so black maps stay apart.
+var gridGapColor = color.RGBA{64, 64, 64, 255}
+
+// RenderGrid renders the selected years side by side, left to right and top
+// to bottom, on the same scale. Each map shows its year, as animation
+// frames do.
+func (a *Animator) RenderGrid(grid GridOptions) (*image.RGBA, error) {
+	if len(a.renderers) == 0 {
+		a.SortByYear()
+	}
+	frames, err := a.gridFrames(grid.Years)
+	if err != nil {
+		return nil, err
+	}
+	a.NormalizeBounds()
+
+	columns := grid.Columns
+	if columns <= 0 {
+		columns = int(math.Ceil(math.Sqrt(float64(len(frames)))))
+	}
+	columns = min(columns, len(frames))
+	rows := (len(frames) + columns - 1) / columns
+
+	opts := a.opts
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	gap := max(grid.Gap, 0)
+	img := image.NewRGBA(image.Rect(0, 0,
+		columns*opts.Width+(columns-1)*gap,
+		rows*opts.Height+(rows-1)*gap))
+	draw.Draw(img, img.Bounds(), &image.Uniform{gridGapColor}, image.Point{}, draw.Src)
+
+	a.renderFrames(frames, func(idx int, frame *image.RGBA) {
+		x := (idx % columns) * (opts.Width + gap)
+		y := (idx / columns) * (opts.Height + gap)
+		draw.Draw(img, frame.Bounds().Add(image.Pt(x, y)), frame, image.Point{}, draw.Src)
+	})
+	return img, nil
+}
+
+// SaveGridPNG saves the grid of the selected years as a PNG image.
+func (a *Animator) SaveGridPNG(filename string, grid GridOptions) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return a.WriteGridPNG(f, grid)
+}
+
+// WriteGridPNG writes the grid of the selected years as PNG to an io.Writer.
+func (a *Animator) WriteGridPNG(w io.Writer, grid GridOptions) error {
+	img, err := a.RenderGrid(grid)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return nil
+}
+
+// gridFrames returns the frames of the years, or all frames.
+func (a *Animator) gridFrames(years []int) ([]*Renderer, error) {
+	if len(a.renderers) == 0 {
+		return nil, fmt.Errorf("no frames to render")
+	}
+	if len(years) == 0 {
+		return a.renderers, nil
+	}
+	frames := make([]*Renderer, 0, len(years))
+	for _, year := range years {
+		i := slices.IndexFunc(a.renderers, func(r *Renderer) bool { return r.Year() == year })
+		if i < 0 {
+			return nil, fmt.Errorf("no turn loaded for year %d", year)
+		}
+		frames = append(frames, a.renderers[i])
+	}
+	return frames, nil
+}
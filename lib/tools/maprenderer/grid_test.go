@@ -0,0 +1,105 @@
+package maprenderer
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertSamePixels compares an image with the image it is expected to
+// reproduce, such as a decoded frame or a cell of a grid.
+func assertSamePixels(t *testing.T, want *image.RGBA, got image.Image) {
+	t.Helper()
+	require.Equal(t, want.Bounds().Size(), got.Bounds().Size())
+	for y := range want.Bounds().Dy() {
+		for x := range want.Bounds().Dx() {
+			w := color.NRGBAModel.Convert(want.At(x, y))
+			g := color.NRGBAModel.Convert(got.At(got.Bounds().Min.X+x, got.Bounds().Min.Y+y))
+			if w != g {
+				t.Fatalf("pixel (%d,%d): got %v, want %v", x, y, g, w)
+			}
+		}
+	}
+}
+
+// historyAnimator returns an animator of 120x90 frames over the given
+// years of the history scenario.
+func historyAnimator(t *testing.T, years ...string) *Animator {
+	t.Helper()
+	a := NewAnimator()
+	a.SetOptions(NewOptions(WithSize(120, 90)))
+	for _, year := range years {
+		require.NoError(t, a.AddFile(historyDir+"game-"+year+".m1"))
+	}
+	a.SortByYear()
+	return a
+}
+
+func TestRenderGrid(t *testing.T) {
+	a := historyAnimator(t, "2470", "2471", "2472", "2473")
+	img, err := a.RenderGrid(GridOptions{Columns: 2, Years: []int{2472, 2470, 2473}, Gap: 3})
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(243, 183), img.Bounds().Size())
+
+	// Maps go left to right then top to bottom, each as its frame
+	cells := map[int]image.Rectangle{
+		2472: image.Rect(0, 0, 120, 90),
+		2470: image.Rect(123, 0, 243, 90),
+		2473: image.Rect(0, 93, 120, 183),
+	}
+	for year, cell := range cells {
+		var frame *Renderer
+		for _, r := range a.renderers {
+			if r.Year() == year {
+				frame = r
+			}
+		}
+		require.NotNil(t, frame, year)
+		want, err := frame.RenderSVGToImage(a.opts)
+		require.NoError(t, err)
+		assertSamePixels(t, want, img.SubImage(cell))
+	}
+
+	// The gaps and the empty cell are filled in
+	for _, pt := range []image.Point{{121, 10}, {10, 91}, {200, 150}} {
+		assert.Equal(t, gridGapColor, img.RGBAAt(pt.X, pt.Y), "at %v", pt)
+	}
+}
+
+func TestRenderGridDefaults(t *testing.T) {
+	// Every frame, in a near-square grid without gaps
+	img, err := historyAnimator(t, "2470", "2471", "2472").RenderGrid(GridOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(240, 180), img.Bounds().Size())
+
+	// More columns than maps make a single row
+	img, err = historyAnimator(t, "2470", "2471").RenderGrid(GridOptions{Columns: 5, Gap: -2})
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(240, 90), img.Bounds().Size())
+}
+
+func TestRenderGridErrors(t *testing.T) {
+	_, err := NewAnimator().RenderGrid(GridOptions{})
+	assert.EqualError(t, err, "no frames to render")
+
+	_, err = historyAnimator(t, "2470", "2471").RenderGrid(GridOptions{Years: []int{2470, 2475}})
+	assert.EqualError(t, err, "no turn loaded for year 2475")
+}
+
+func TestWriteGridPNG(t *testing.T) {
+	a := historyAnimator(t, "2470", "2471")
+	grid := GridOptions{Gap: 4}
+	want, err := a.RenderGrid(grid)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, a.WriteGridPNG(&buf, grid))
+	got, err := png.Decode(&buf)
+	require.NoError(t, err)
+	assertSamePixels(t, want, got)
+}
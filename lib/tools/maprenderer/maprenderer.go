@@ -26,6 +26,7 @@ import (
 	"io"
 	"math"
 	"os"
+	"path"
 	"runtime"
 	"sort"
 	"strings"
@@ -35,6 +36,10 @@ import (
 	"github.com/tdewolff/canvas/renderers/rasterizer"
 
 	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/filenames"
+	"github.com/neper-stars/houston/geom"
+	"github.com/neper-stars/houston/lib/tools/summary"
+	"github.com/neper-stars/houston/parser"
 	"github.com/neper-stars/houston/store"
 )
 
@@ -42,14 +47,19 @@ import (
 type Renderer struct {
 	store *store.GameStore
 
+	// Earlier turns, latest first (see SetHistory)
+	history []*store.GameStore
+
 	// Map bounds (computed from entities)
-	minX, maxX int
-	minY, maxY int
+	bounds geom.Bounds
 
 	// Cached filtered collections (lazily populated)
 	cachedMinefields []*store.ObjectEntity
 	cachedWormholes  []*store.ObjectEntity
 	cacheValid       bool
+
+	// Fleets moved toward the next turn, for frames between turns
+	tween *tween
 }
 
 // RenderOptions controls how the map is rendered.
@@ -59,11 +69,50 @@ type RenderOptions struct {
 	ShowNames           bool // Show planet names
 	ShowFleets          bool // Show fleet indicators
 	ShowFleetPaths      int  // Show fleet projected paths (0=off, N=years to project)
+	ShowFleetTrails     int  // Show fleet trails (0=off, N=past turns, needs Renderer.SetHistory)
+	ShowFleetETAs       bool // Label waypoint paths with the years to each waypoint (needs ShowFleetPaths)
 	ShowMines           bool // Show minefields
 	ShowWormholes       bool // Show wormholes
 	ShowLegend          bool // Show player legend
 	ShowScannerCoverage bool // Show scanner coverage circles
 	Padding             int  // Padding around the galaxy (default: 20)
+
+	// PlainFleets draws every fleet as a triangle (a diamond when
+	// stationary) instead of a glyph for its role: warship, bomber,
+	// colonizer, minelayer, miner, freighter or scout, inferred from its
+	// designs.
+	PlainFleets bool
+
+	// NamedPlanets lists planets (case-insensitive names) whose name is
+	// drawn even when ShowNames is off.
+	NamedPlanets []string
+
+	// PlayerColors overrides the player colors, indexed by player number
+	// (see PlayerPalette and ParsePlayerColors). Players beyond the end of
+	// the list use the default colors.
+	PlayerColors []color.RGBA
+
+	// Hotspots are highlighted locations drawn above planets
+	// (e.g. contested systems from a front line analysis).
+	Hotspots []Hotspot
+
+	// ScannerPlayers limits the scanner coverage (see ShowScannerCoverage)
+	// to these players (1-16); empty draws the coverage of every player.
+	ScannerPlayers []int
+
+	// ViewAsPlayer draws the map as a player (1-16) could see it from the
+	// scanners of their planets and fleets, rather than everything the
+	// files hold; 0 draws everything. Planets the player could not scan
+	// are drawn as unowned, and only their scanners are drawn.
+	ViewAsPlayer int
+}
+
+// Hotspot is a highlighted location on the map.
+type Hotspot struct {
+	X, Y   int        // Game coordinates
+	Radius float64    // Radius in light years (minimum 4 pixels on screen)
+	Color  color.RGBA // Ring color
+	Label  string     // Optional label drawn next to the ring
 }
 
 // DefaultOptions returns default rendering options.
@@ -84,10 +133,6 @@ func DefaultOptions() *RenderOptions {
 func New() *Renderer {
 	return &Renderer{
 		store: store.New(),
-		minX:  math.MaxInt32,
-		maxX:  math.MinInt32,
-		minY:  math.MaxInt32,
-		maxY:  math.MinInt32,
 	}
 }
 
@@ -95,10 +140,6 @@ func New() *Renderer {
 func NewFromStore(gs *store.GameStore) *Renderer {
 	r := &Renderer{
 		store: gs,
-		minX:  math.MaxInt32,
-		maxX:  math.MinInt32,
-		minY:  math.MaxInt32,
-		maxY:  math.MinInt32,
 	}
 	r.computeBounds()
 	return r
@@ -149,44 +190,41 @@ func (r *Renderer) LoadFileWithXY(filename string) error {
 
 // computeBounds calculates the map bounds from all entities.
 func (r *Renderer) computeBounds() {
-	r.minX = math.MaxInt32
-	r.maxX = math.MinInt32
-	r.minY = math.MaxInt32
-	r.maxY = math.MinInt32
+	r.bounds = geom.Bounds{}
 
 	// Invalidate cache when bounds are recomputed (data changed)
 	r.cacheValid = false
 	r.cachedMinefields = nil
 	r.cachedWormholes = nil
 
+	// Place the planets left without coordinates by a missing XY file
+	r.store.LocatePlanets()
+
 	// Bounds from planets
 	for _, planet := range r.store.AllPlanets() {
-		r.updateBounds(planet.X, planet.Y)
+		r.bounds.Add(planet.X, planet.Y)
 	}
 
 	// Bounds from fleets
 	for _, fleet := range r.store.AllFleets() {
-		r.updateBounds(fleet.X, fleet.Y)
+		r.bounds.Add(fleet.X, fleet.Y)
 	}
 
 	// Bounds from minefields
 	for _, mf := range r.minefields() {
-		r.updateBounds(mf.X, mf.Y)
+		r.bounds.Add(mf.X, mf.Y)
 	}
 
 	// Bounds from wormholes
 	for _, wh := range r.wormholes() {
-		r.updateBounds(wh.X, wh.Y)
+		r.bounds.Add(wh.X, wh.Y)
 	}
 }
 
 // setBounds sets the map bounds externally. Used by Animator to ensure
 // consistent scaling across all animation frames.
-func (r *Renderer) setBounds(minX, maxX, minY, maxY int) {
-	r.minX = minX
-	r.maxX = maxX
-	r.minY = minY
-	r.maxY = maxY
+func (r *Renderer) setBounds(b geom.Bounds) {
+	r.bounds = b
 }
 
 // minefields returns cached minefields or fetches them from store.
@@ -205,21 +243,6 @@ func (r *Renderer) wormholes() []*store.ObjectEntity {
 	return r.cachedWormholes
 }
 
-func (r *Renderer) updateBounds(x, y int) {
-	if x < r.minX {
-		r.minX = x
-	}
-	if x > r.maxX {
-		r.maxX = x
-	}
-	if y < r.minY {
-		r.minY = y
-	}
-	if y > r.maxY {
-		r.maxY = y
-	}
-}
-
 // Player colors - same as Java version
 var playerColors = []color.RGBA{
 	{255, 3, 3, 255},     // Red
@@ -240,12 +263,20 @@ var playerColors = []color.RGBA{
 	{100, 100, 200, 255}, // Light purple
 }
 
-// GetPlayerColor returns the color for a player.
+// GetPlayerColor returns the default color for a player. Rendering uses
+// RenderOptions.PlayerColor, which honors custom palettes.
 func (r *Renderer) GetPlayerColor(playerNum int) color.RGBA {
-	if playerNum >= 0 && playerNum < len(playerColors) {
-		return playerColors[playerNum]
+	return (*RenderOptions)(nil).PlayerColor(playerNum)
+}
+
+// RampColor maps a value in [0, 1] onto a green-yellow-red color ramp,
+// suitable for heat map overlays. Values outside the range are clamped.
+func RampColor(t float64) color.RGBA {
+	t = math.Max(0, math.Min(1, t))
+	if t < 0.5 {
+		return color.RGBA{uint8(510 * t), 200, 0, 255}
 	}
-	return color.RGBA{128, 128, 128, 255}
+	return color.RGBA{255, uint8(200 * (2 - 2*t)), 0, 255}
 }
 
 // Render creates an image of the galaxy map.
@@ -259,52 +290,49 @@ func (r *Renderer) Render(opts *RenderOptions) *image.RGBA {
 	// Fill background with black
 	draw.Draw(img, img.Bounds(), &image.Uniform{color.Black}, image.Point{}, draw.Src)
 
-	// Calculate scaling
-	galaxyWidth := float64(r.maxX - r.minX)
-	galaxyHeight := float64(r.maxY - r.minY)
-	if galaxyWidth == 0 {
-		galaxyWidth = 1
-	}
-	if galaxyHeight == 0 {
-		galaxyHeight = 1
-	}
-
-	availWidth := float64(opts.Width - 2*opts.Padding)
-	availHeight := float64(opts.Height - 2*opts.Padding)
-
-	scaleX := availWidth / galaxyWidth
-	scaleY := availHeight / galaxyHeight
-	scale := math.Min(scaleX, scaleY)
-
-	// Calculate offsets to center the galaxy
-	offsetX := float64(opts.Padding) + (availWidth-galaxyWidth*scale)/2
-	offsetY := float64(opts.Padding) + (availHeight-galaxyHeight*scale)/2
-
-	// Transform function
+	proj := r.Projection(opts)
+	scale := proj.Scale
 	transform := func(x, y int) (int, int) {
-		px := int(offsetX + float64(x-r.minX)*scale)
-		py := int(offsetY + float64(r.maxY-y)*scale) // Flip Y axis
-		return px, py
+		px, py := proj.ToScreen(x, y)
+		return int(px), int(py)
 	}
+	view := r.fog(opts)
 
 	// Draw minefields first (background) as cloud of dots
 	if opts.ShowMines {
 		for _, mf := range r.minefields() {
+			if !view.seesMinefield(mf) {
+				continue
+			}
 			px, py := transform(mf.X, mf.Y)
 			radius := int(mf.Radius() * scale)
 			if radius < 2 {
 				radius = 2
 			}
-			col := r.GetPlayerColor(mf.Owner)
+			col := opts.PlayerColor(mf.Owner)
 			col.A = 180 // Semi-transparent
 			drawMinefieldCloud(img, px, py, radius, col, mf.Number)
 		}
 	}
 
+	// Draw scanner coverage
+	if opts.ShowScannerCoverage {
+		normalScanners, penScanners := r.scannerCircles(opts, view)
+		for _, s := range normalScanners {
+			px, py := transform(s.x, s.y)
+			col := opts.PlayerColor(s.owner)
+			drawCoverage(img, px, py, int(float64(s.radius)*scale), col, 20, color.RGBA{col.R / 2, col.G / 2, col.B / 2, 255})
+		}
+		for _, s := range penScanners {
+			px, py := transform(s.x, s.y)
+			drawCoverage(img, px, py, int(float64(s.radius)*scale), opts.PlayerColor(s.owner), 40, opts.PlayerColor(s.owner))
+		}
+	}
+
 	// Draw wormholes
 	if opts.ShowWormholes {
 		purple := color.RGBA{128, 0, 128, 255}
-		wormholes := r.wormholes()
+		wormholes := view.wormholes(r.wormholes())
 		// Build lookup map for wormhole connections
 		whByID := make(map[int]*store.ObjectEntity)
 		for _, wh := range wormholes {
@@ -324,41 +352,68 @@ func (r *Renderer) Render(opts *RenderOptions) *image.RGBA {
 	// Draw planets
 	for _, planet := range r.store.AllPlanets() {
 		px, py := transform(planet.X, planet.Y)
+		owner, hasStarbase := view.planet(planet)
 
 		var col color.RGBA
 		radius := 2
 
-		if planet.Owner >= 0 {
-			col = r.GetPlayerColor(planet.Owner)
+		if owner >= 0 {
+			col = opts.PlayerColor(owner)
 			radius = 3
 		} else {
 			col = color.RGBA{128, 128, 128, 255}
 		}
 
 		// Draw starbase if present (white circle + yellow satellite)
-		if planet.HasStarbase {
+		if hasStarbase {
 			// White circle around planet
 			drawCircleOutline(img, px, py, 6, color.RGBA{255, 255, 255, 255})
 			// Yellow satellite dot offset to upper-right
 			drawFilledCircle(img, px+5, py-5, 1, color.RGBA{255, 255, 0, 255})
 		}
 
-		drawFilledCircle(img, px, py, radius, col)
+		if planet.PositionEstimated {
+			drawCircleOutline(img, px, py, radius, col)
+		} else {
+			drawFilledCircle(img, px, py, radius, col)
+		}
+	}
+
+	// Draw hotspots
+	for _, hs := range opts.Hotspots {
+		px, py := transform(hs.X, hs.Y)
+		radius := int(hs.Radius * scale)
+		if radius < 4 {
+			radius = 4
+		}
+		drawCircleOutline(img, px, py, radius, hs.Color)
 	}
 
 	// Draw fleets
 	if opts.ShowFleets {
-		for _, fleet := range r.store.AllFleets() {
-			px, py := transform(fleet.X, fleet.Y)
-			col := r.GetPlayerColor(fleet.Owner)
-			col.A = 200
-
-			// Draw direction triangle
-			// DeltaX/DeltaY are game-space deltas (already centered around 0)
-			// Negate Y for screen coordinates (game Y increases up, screen Y increases down)
-			dx := float64(fleet.DeltaX)
-			dy := -float64(fleet.DeltaY)
-			drawFleetTriangle(img, px, py, dx, dy, col)
+		roles := r.fleetRoles(view)
+		for _, stack := range r.fleetStacks(view) {
+			sx, sy := transform(stack.X, stack.Y)
+			for i, fleet := range stack.Fleets {
+				ox, oy := fanOffset(i, len(stack.Fleets))
+				px, py := sx+int(math.Round(ox)), sy+int(math.Round(oy))
+				col := opts.PlayerColor(fleet.Owner)
+				col.A = 200
+
+				// Draw direction triangle or role glyph
+				// DeltaX/DeltaY are game-space deltas (already centered around 0)
+				// Negate Y for screen coordinates (game Y increases up, screen Y increases down)
+				dx := float64(fleet.DeltaX)
+				dy := -float64(fleet.DeltaY)
+				if opts.PlainFleets {
+					drawFleetTriangle(img, px, py, dx, dy, col)
+				} else {
+					drawFleetGlyph(img, px, py, roles[fleet], dx, dy, col)
+				}
+			}
+			if len(stack.Fleets) > 1 {
+				drawText(img, sx+stackRadius, sy+stackRadius, fmt.Sprintf("%d", len(stack.Fleets)), opts.stackColor(stack))
+			}
 		}
 	}
 
@@ -382,7 +437,7 @@ func (r *Renderer) drawLegend(img *image.RGBA, opts *RenderOptions) {
 
 	y := 10
 	for _, player := range players {
-		col := r.GetPlayerColor(player.PlayerNumber)
+		col := opts.PlayerColor(player.PlayerNumber)
 		// Draw color box
 		for dy := 0; dy < 10; dy++ {
 			for dx := 0; dx < 10; dx++ {
@@ -397,6 +452,17 @@ func (r *Renderer) drawLegend(img *image.RGBA, opts *RenderOptions) {
 		drawText(img, 20, y+2, name, col)
 		y += 14
 	}
+
+	// Fleet glyphs of the roles on the map
+	if !opts.ShowFleets || opts.PlainFleets {
+		return
+	}
+	y += 6
+	for _, role := range legendRoles(r.fleetRoles(r.fog(opts))) {
+		drawFleetGlyph(img, 10, y+5, role, 0, 0, legendGlyphColor)
+		drawText(img, 20, y+2, role.String(), legendGlyphColor)
+		y += 14
+	}
 }
 
 func (r *Renderer) drawYear(img *image.RGBA, opts *RenderOptions) {
@@ -412,6 +478,23 @@ func (r *Renderer) drawYear(img *image.RGBA, opts *RenderOptions) {
 		drawDigit(img, x, y, digit, color.RGBA{0, 128, 255, 255})
 		x += 8
 	}
+	if r.positionsEstimated() {
+		drawText(img, x+8, y+2, estimatedNote, color.RGBA{0, 128, 255, 255})
+	}
+}
+
+// estimatedNote is shown by the year when planet positions are estimated.
+const estimatedNote = "positions estimated (no XY file)"
+
+// positionsEstimated reports whether some planets are drawn at estimated
+// positions (see store.GameStore.LocatePlanets).
+func (r *Renderer) positionsEstimated() bool {
+	for _, planet := range r.store.AllPlanets() {
+		if planet.PositionEstimated {
+			return true
+		}
+	}
+	return false
 }
 
 // SavePNG saves the rendered map as a PNG file.
@@ -499,164 +582,69 @@ func (r *Renderer) buildSVGInternal(opts *RenderOptions, forRasterization bool)
 	svg.AddMinefieldHatchPattern()
 
 	// Calculate scale and transform
-	if r.minX == math.MaxInt32 || r.maxX == math.MinInt32 {
+	if r.bounds.Empty() {
 		return svg
 	}
 
-	rangeX := float64(r.maxX - r.minX)
-	rangeY := float64(r.maxY - r.minY)
-	if rangeX == 0 {
-		rangeX = 1
-	}
-	if rangeY == 0 {
-		rangeY = 1
-	}
-
-	padding := float64(opts.Padding)
-	availWidth := float64(opts.Width) - 2*padding
-	availHeight := float64(opts.Height) - 2*padding
-
-	scaleX := availWidth / rangeX
-	scaleY := availHeight / rangeY
-	scale := scaleX
-	if scaleY < scaleX {
-		scale = scaleY
-	}
-
-	offsetX := padding + (availWidth-rangeX*scale)/2
-	offsetY := padding + (availHeight-rangeY*scale)/2
-
-	transform := func(x, y int) (float64, float64) {
-		px := offsetX + float64(x-r.minX)*scale
-		py := offsetY + float64(r.maxY-y)*scale // Flip Y axis
-		return px, py
-	}
+	proj := r.Projection(opts)
+	scale := proj.Scale
+	transform := proj.ToScreen
+	view := r.fog(opts)
 
 	// Add arrow markers for fleet paths (one per player color)
 	if opts.ShowFleetPaths > 0 {
 		for _, player := range r.store.AllPlayers() {
 			markerID := fmt.Sprintf("arrow-%d", player.PlayerNumber)
-			col := r.GetPlayerColor(player.PlayerNumber)
+			col := opts.PlayerColor(player.PlayerNumber)
 			svg.AddArrowMarker(markerID, col)
 		}
 	}
 
 	// Draw minefields
 	if opts.ShowMines {
+		svg.BeginLayer(LayerMinefields)
 		for _, mf := range r.minefields() {
+			if !view.seesMinefield(mf) {
+				continue
+			}
 			px, py := transform(mf.X, mf.Y)
 			radius := mf.Radius() * scale
 			if radius < 2 {
 				radius = 2
 			}
-			col := r.GetPlayerColor(mf.Owner)
+			col := opts.PlayerColor(mf.Owner)
+			svg.BeginGroup(MinefieldElementID(mf.Owner, mf.Number), "minefield "+playerClass(mf.Owner))
 			svg.Minefield(px, py, radius, col)
+			svg.EndGroup()
 		}
+		svg.EndGroup()
 	}
 
 	// Draw scanner coverage (very early so it's behind everything else)
-	// Normal scanner range shown in player color, penetrating range shown in yellow
+	// Normal ranges are faint discs in the player color, penetrating ranges
+	// stronger dashed ones
 	if opts.ShowScannerCoverage {
-		yellowPen := color.RGBA{255, 255, 0, 255} // Yellow for penetrating scanners
-
-		// Collect all scanner circles (we'll filter out contained ones)
-		type scannerCircle struct {
-			x, y   int // Game coordinates (not transformed)
-			radius int // Range in ly
-			owner  int
-		}
-		var normalScanners, penScanners []scannerCircle
-
-		// Collect planet scanners (planetary scanners, starbase scanners, and PRT intrinsic scanners)
-		for _, planet := range r.store.AllPlanets() {
-			if planet.Owner < 0 {
-				continue
-			}
-			normalRange, penRange := planet.GetScannerRanges(r.store)
-			if normalRange > 0 {
-				normalScanners = append(normalScanners, scannerCircle{planet.X, planet.Y, normalRange, planet.Owner})
-			}
-			if penRange > 0 {
-				penScanners = append(penScanners, scannerCircle{planet.X, planet.Y, penRange, planet.Owner})
-			}
-		}
-
-		// Collect fleet scanners
-		for _, fleet := range r.store.AllFleets() {
-			normalRange, penRange := fleet.GetScannerRanges(r.store)
-			if normalRange > 0 {
-				normalScanners = append(normalScanners, scannerCircle{fleet.X, fleet.Y, normalRange, fleet.Owner})
-			}
-			if penRange > 0 {
-				penScanners = append(penScanners, scannerCircle{fleet.X, fleet.Y, penRange, fleet.Owner})
-			}
-		}
-
-		// Optimized filter: sort by radius descending, then check only against
-		// larger circles. Uses squared distances to avoid sqrt in hot path.
-		filterContained := func(scanners []scannerCircle) []scannerCircle {
-			if len(scanners) <= 1 {
-				return scanners
-			}
-
-			// Sort by radius descending - larger circles checked first
-			sort.Slice(scanners, func(i, j int) bool {
-				return scanners[i].radius > scanners[j].radius
-			})
-
-			result := make([]scannerCircle, 0, len(scanners))
-			for _, s := range scanners {
-				contained := false
-				// Only check against larger (or equal) circles already in result
-				for _, other := range result {
-					if s.owner != other.owner {
-						continue
-					}
-					// Check if s is contained in other using squared distance
-					// A is contained in B if: dist(A,B) + radiusA <= radiusB
-					// Rearranged: dist(A,B) <= radiusB - radiusA
-					// If radiusB < radiusA, can't be contained (and other is smaller anyway)
-					radiusDiff := other.radius - s.radius
-					if radiusDiff < 0 {
-						continue
-					}
-					dx := s.x - other.x
-					dy := s.y - other.y
-					distSq := dx*dx + dy*dy
-					// Compare squared: distSq <= radiusDiff^2
-					if distSq <= radiusDiff*radiusDiff {
-						contained = true
-						break
-					}
-				}
-				if !contained {
-					result = append(result, s)
-				}
-			}
-			return result
-		}
-
-		// Filter out contained scanners
-		normalScanners = filterContained(normalScanners)
-		penScanners = filterContained(penScanners)
-
-		// Draw normal scanners in player color
+		svg.BeginLayer(LayerScanners)
+		normalScanners, penScanners := r.scannerCircles(opts, view)
 		for _, s := range normalScanners {
 			px, py := transform(s.x, s.y)
-			col := r.GetPlayerColor(s.owner)
-			svg.ScannerCoverage(px, py, float64(s.radius)*scale, col)
+			svg.BeginGroup("", "scanner "+playerClass(s.owner))
+			svg.ScannerCoverage(px, py, float64(s.radius)*scale, opts.PlayerColor(s.owner))
+			svg.EndGroup()
 		}
-
-		// Draw penetrating scanners in yellow
 		for _, s := range penScanners {
 			px, py := transform(s.x, s.y)
-			svg.ScannerCoverage(px, py, float64(s.radius)*scale, yellowPen)
+			svg.BeginGroup("", "scanner penetrating "+playerClass(s.owner))
+			svg.PenetratingCoverage(px, py, float64(s.radius)*scale, opts.PlayerColor(s.owner))
+			svg.EndGroup()
 		}
+		svg.EndGroup()
 	}
 
 	// Draw wormholes
 	if opts.ShowWormholes {
-		wormholes := r.wormholes()
+		svg.BeginLayer(LayerWormholes)
+		wormholes := view.wormholes(r.wormholes())
 		// Build lookup map for wormhole connections
 		whByID := make(map[int]*store.ObjectEntity)
 		for _, wh := range wormholes {
@@ -673,21 +661,50 @@ func (r *Renderer) buildSVGInternal(opts *RenderOptions, forRasterization bool)
 		// Draw wormhole circles
 		for _, wh := range wormholes {
 			px, py := transform(wh.X, wh.Y)
+			svg.BeginGroup(WormholeElementID(wh.WormholeId), "wormhole")
 			svg.Wormhole(px, py)
+			svg.EndGroup()
+		}
+		svg.EndGroup()
+	}
+
+	// Draw fleet trails (oldest drawn faintest)
+	if opts.ShowFleetTrails > 0 && len(r.history) > 0 {
+		svg.BeginLayer(LayerFleetTrails)
+		for _, fleet := range r.store.AllFleets() {
+			if !view.seesFleet(fleet) {
+				continue
+			}
+			trail := r.fleetTrail(fleet, opts.ShowFleetTrails)
+			if len(trail) < 2 {
+				continue
+			}
+			points := make([][2]float64, len(trail))
+			for i, p := range trail {
+				points[i][0], points[i][1] = transform(p[0], p[1])
+			}
+			svg.BeginGroup("", fleetTrailClass(fleet.Owner, fleet.FleetNumber))
+			svg.FleetTrail(points, opts.PlayerColor(fleet.Owner))
+			svg.EndGroup()
 		}
+		svg.EndGroup()
 	}
 
 	// Draw fleet projected paths (before fleets so paths are behind)
 	if opts.ShowFleetPaths > 0 {
+		svg.BeginLayer(LayerFleetPaths)
 		for _, fleet := range r.store.AllFleets() {
-			col := r.GetPlayerColor(fleet.Owner)
+			if !view.seesFleet(fleet) {
+				continue
+			}
+			col := opts.PlayerColor(fleet.Owner)
 			markerID := fmt.Sprintf("arrow-%d", fleet.Owner)
 
 			// Check if fleet has waypoints (owned fleets)
 			if len(fleet.Waypoints) > 0 {
 				// Build path from current position through all waypoints
 				var points [][2]float64
-				px, py := transform(fleet.X, fleet.Y)
+				px, py := transform(r.fleetPosition(fleet))
 				points = append(points, [2]float64{px, py})
 
 				for _, wp := range fleet.Waypoints {
@@ -706,7 +723,15 @@ func (r *Renderer) buildSVGInternal(opts *RenderOptions, forRasterization bool)
 					points = append(points, [2]float64{wpx, wpy})
 				}
 
+				svg.BeginGroup("", fleetPathClass(fleet.Owner, fleet.FleetNumber))
 				svg.WaypointPath(points, col, markerID)
+				if opts.ShowFleetETAs {
+					for _, eta := range fleetETAs(fleet) {
+						ex, ey := transform(eta.X, eta.Y)
+						svg.ETALabel(ex, ey, eta.Years, col)
+					}
+				}
+				svg.EndGroup()
 			} else {
 				// Use DeltaX/DeltaY for enemy fleets
 				// Skip if fleet is not moving (Warp=0 or no delta)
@@ -721,78 +746,148 @@ func (r *Renderer) buildSVGInternal(opts *RenderOptions, forRasterization bool)
 					continue // Stationary (no heading data)
 				}
 
-				px, py := transform(fleet.X, fleet.Y)
+				px, py := transform(r.fleetPosition(fleet))
 
 				// Scale the delta to screen coordinates
 				// (Y already flipped above when computing dy)
 				screenDx := dx * scale
 				screenDy := dy * scale
 
+				svg.BeginGroup("", fleetPathClass(fleet.Owner, fleet.FleetNumber))
 				svg.FleetSpeedLine(px, py, screenDx, screenDy, opts.ShowFleetPaths, col, markerID)
+				svg.EndGroup()
 			}
 		}
+		svg.EndGroup()
 	}
 
 	// Draw planets
+	named := make(map[string]bool, len(opts.NamedPlanets))
+	for _, name := range opts.NamedPlanets {
+		named[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	svg.BeginLayer(LayerPlanets)
 	for _, planet := range r.store.AllPlanets() {
 		px, py := transform(planet.X, planet.Y)
+		owner, hasStarbase := view.planet(planet)
 
 		var col color.RGBA
 		radius := 2.0
+		class := "planet unowned"
 
-		if planet.Owner >= 0 {
-			col = r.GetPlayerColor(planet.Owner)
+		if owner >= 0 {
+			col = opts.PlayerColor(owner)
 			radius = 3.0
+			class = "planet " + playerClass(owner)
 		} else {
 			col = color.RGBA{128, 128, 128, 255}
 		}
+		if hasStarbase {
+			class += " starbase"
+		}
+		if planet.PositionEstimated {
+			class += " estimated"
+		}
 
-		svg.Planet(px, py, radius, col, planet.HasStarbase, planet.Name, opts.ShowNames)
+		showName := opts.ShowNames || named[strings.ToLower(planet.Name)]
+		svg.BeginGroup(PlanetElementID(planet.PlanetNumber), class)
+		if planet.PositionEstimated {
+			svg.EstimatedPlanet(px, py, radius, col, hasStarbase, planet.Name, showName)
+		} else {
+			svg.Planet(px, py, radius, col, hasStarbase, planet.Name, showName)
+		}
+		svg.EndGroup()
+	}
+	svg.EndGroup()
+
+	// Draw hotspots
+	svg.BeginLayer(LayerHotspots)
+	for _, hs := range opts.Hotspots {
+		px, py := transform(hs.X, hs.Y)
+		radius := hs.Radius * scale
+		if radius < 4 {
+			radius = 4
+		}
+		svg.Hotspot(px, py, radius, hs.Color, hs.Label)
 	}
+	svg.EndGroup()
 
 	// Draw fleets
+	var roles map[*store.FleetEntity]summary.Role
 	if opts.ShowFleets {
-		for _, fleet := range r.store.AllFleets() {
-			px, py := transform(fleet.X, fleet.Y)
-			col := r.GetPlayerColor(fleet.Owner)
+		roles = r.fleetRoles(view)
+		svg.BeginLayer(LayerFleets)
+		for _, stack := range r.fleetStacks(view) {
+			px, py := transform(stack.X, stack.Y)
+			stacked := len(stack.Fleets) > 1
+			if stacked {
+				svg.BeginGroup(FleetStackElementID(stack.X, stack.Y), "fleet-stack")
+				svg.Title(r.stackTitle(stack))
+			}
+			for i, fleet := range stack.Fleets {
+				col := opts.PlayerColor(fleet.Owner)
+
+				var dx, dy float64
+				isMoving := false
+
+				// Check for waypoints first (owned fleets moving to waypoint)
+				if len(fleet.Waypoints) > 0 {
+					// Find first waypoint that's not at current position
+					for _, wp := range fleet.Waypoints {
+						if wp.X != fleet.X || wp.Y != fleet.Y {
+							wpx, wpy := transform(wp.X, wp.Y)
+							dx = wpx - px
+							dy = wpy - py
+							isMoving = true
+							break
+						}
+					}
+				}
 
-			var dx, dy float64
-			isMoving := false
+				// If no waypoint movement, check DeltaX/DeltaY (enemy fleets)
+				// DeltaX/DeltaY are game-space deltas (already centered around 0)
+				// Negate Y for screen coordinates (game Y increases up, screen Y increases down)
+				if !isMoving {
+					dx = float64(fleet.DeltaX)
+					dy = -float64(fleet.DeltaY)
+					isMoving = math.Abs(dx) >= 0.5 || math.Abs(dy) >= 0.5
+				}
 
-			// Check for waypoints first (owned fleets moving to waypoint)
-			if len(fleet.Waypoints) > 0 {
-				// Find first waypoint that's not at current position
-				for _, wp := range fleet.Waypoints {
-					if wp.X != fleet.X || wp.Y != fleet.Y {
-						wpx, wpy := transform(wp.X, wp.Y)
-						dx = wpx - px
-						dy = wpy - py
-						isMoving = true
-						break
-					}
+				class := "fleet " + playerClass(fleet.Owner)
+				if !opts.PlainFleets {
+					class += " " + roleClass(roles[fleet])
+				}
+				if isMoving {
+					class += " moving"
+				} else {
+					class += " stationary"
 				}
-			}
 
-			// If no waypoint movement, check DeltaX/DeltaY (enemy fleets)
-			// DeltaX/DeltaY are game-space deltas (already centered around 0)
-			// Negate Y for screen coordinates (game Y increases up, screen Y increases down)
-			if !isMoving {
-				dx = float64(fleet.DeltaX)
-				dy = -float64(fleet.DeltaY)
-				isMoving = math.Abs(dx) >= 0.5 || math.Abs(dy) >= 0.5
+				// Fan stacked fleets out around their point
+				ox, oy := fanOffset(i, len(stack.Fleets))
+				fx, fy := px+ox, py+oy
+				svg.BeginGroup(FleetElementID(fleet.Owner, fleet.FleetNumber), class)
+				if !opts.PlainFleets {
+					svg.FleetGlyph(fx, fy, 4, roles[fleet], isMoving, math.Atan2(dy, dx), col)
+				} else if !isMoving {
+					svg.Diamond(fx, fy, 3, col)
+				} else {
+					angle := math.Atan2(dy, dx)
+					svg.Triangle(fx, fy, 4, angle, col)
+				}
+				svg.EndGroup()
 			}
-
-			if !isMoving {
-				svg.Diamond(px, py, 3, col)
-			} else {
-				angle := math.Atan2(dy, dx)
-				svg.Triangle(px, py, 4, angle, col)
+			if stacked {
+				svg.CountBadge(px+stackRadius+2, py+stackRadius+2, len(stack.Fleets), opts.stackColor(stack))
+				svg.EndGroup()
 			}
 		}
+		svg.EndGroup()
 	}
 
 	// Draw legend
 	if opts.ShowLegend {
+		svg.BeginLayer(LayerLegend)
 		players := r.store.AllPlayers()
 		sort.Slice(players, func(i, j int) bool {
 			return players[i].PlayerNumber < players[j].PlayerNumber
@@ -800,18 +895,35 @@ func (r *Renderer) buildSVGInternal(opts *RenderOptions, forRasterization bool)
 
 		y := 10.0
 		for _, player := range players {
-			col := r.GetPlayerColor(player.PlayerNumber)
+			col := opts.PlayerColor(player.PlayerNumber)
 			name := player.NameSingular
 			if name == "" {
 				name = fmt.Sprintf("Player %d", player.PlayerNumber+1)
 			}
+			svg.BeginGroup("", "legend-item "+playerClass(player.PlayerNumber))
 			svg.LegendItem(5, y, name, col)
+			svg.EndGroup()
 			y += 14
 		}
+		if opts.ShowFleets && !opts.PlainFleets {
+			y += 6
+			for _, role := range legendRoles(roles) {
+				svg.BeginGroup("", "legend-item "+roleClass(role))
+				svg.GlyphLegendItem(5, y, role, legendGlyphColor)
+				svg.EndGroup()
+				y += 14
+			}
+		}
+		svg.EndGroup()
 	}
 
 	// Draw year
+	svg.BeginLayer(LayerYear)
 	svg.Text(10, float64(opts.Height-10), fmt.Sprintf("%d", r.Year()), color.RGBA{0, 128, 255, 255}, 12)
+	if r.positionsEstimated() {
+		svg.Text(50, float64(opts.Height-10), estimatedNote, color.RGBA{0, 128, 255, 255}, 9)
+	}
+	svg.EndGroup()
 
 	return svg
 }
@@ -921,8 +1033,18 @@ type Animator struct {
 	// universe structure shared across all turns.
 	baseFileName string
 	baseFileData []byte
+	// progress is an optional callback reporting rendered frames.
+	progress ProgressFunc
+	// cache is an optional cache of parsed files.
+	cache *parser.Cache
+	// inBetween is the number of frames drawn between two turns.
+	inBetween int
 }
 
+// ProgressFunc is called as work completes with the number of items done
+// out of the total.
+type ProgressFunc func(done, total int)
+
 // NewAnimator creates a new Animator.
 func NewAnimator() *Animator {
 	return &Animator{
@@ -943,6 +1065,34 @@ func (a *Animator) SetPalette(p color.Palette) {
 	a.palette = p
 }
 
+// SetProgress sets a callback called after each frame is rendered by
+// WriteGIF. Calls are serialized, with done increasing from 1 to the frame
+// count. Pass nil to disable.
+func (a *Animator) SetProgress(progress ProgressFunc) {
+	a.progress = progress
+}
+
+// SetCache makes the animator reuse the blocks cached for files already
+// parsed (see parser.Cache). A nil cache disables caching.
+func (a *Animator) SetCache(cache *parser.Cache) {
+	a.cache = cache
+}
+
+// SetInBetweenFrames makes WriteGIF draw n frames between each two turns,
+// with the fleets moved part of the way from one turn's position to the
+// next, for smooth movement. Each turn is still shown for the full delay,
+// and the frames between two turns share another delay. 0 disables them.
+func (a *Animator) SetInBetweenFrames(n int) {
+	a.inBetween = max(n, 0)
+}
+
+// newRenderer returns an empty frame renderer using the animator's cache.
+func (a *Animator) newRenderer() *Renderer {
+	r := New()
+	r.store.SetCache(a.cache)
+	return r
+}
+
 // SetBaseData sets data that should be loaded into every frame.
 // This is typically the .xy universe file that provides planet names
 // and universe structure shared across all turns.
@@ -1012,15 +1162,19 @@ func DefaultGIFPalette() color.Palette {
 }
 
 // AddFile adds a game file. Files from the same year are merged into a single frame.
+// A ZIP archive adds all the turns it holds, its XY file becoming the base data.
 func (a *Animator) AddFile(filename string) error {
 	// First load the file to get its year
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
+	if filenames.IsZip(filename) {
+		return a.addZip(filename, data)
+	}
 
 	// Create a temporary renderer to get the year
-	tempR := New()
+	tempR := a.newRenderer()
 	if err := tempR.LoadBytes(filename, data); err != nil {
 		return err
 	}
@@ -1035,7 +1189,7 @@ func (a *Animator) AddFile(filename string) error {
 		existingR.computeBounds()
 	} else {
 		// Use LoadFileWithXY to also load companion XY file
-		r := New()
+		r := a.newRenderer()
 		if err := r.LoadFileWithXY(filename); err != nil {
 			return err
 		}
@@ -1050,7 +1204,7 @@ func (a *Animator) AddFile(filename string) error {
 // new frame before the turn-specific data.
 func (a *Animator) AddBytes(name string, data []byte) error {
 	// Create a temporary renderer to get the year
-	tempR := New()
+	tempR := a.newRenderer()
 	if err := tempR.LoadBytes(name, data); err != nil {
 		return err
 	}
@@ -1066,7 +1220,7 @@ func (a *Animator) AddBytes(name string, data []byte) error {
 	} else {
 		// Creating new frame - load base data first if set
 		if a.baseFileData != nil {
-			r := New()
+			r := a.newRenderer()
 			if err := r.LoadBytes(a.baseFileName, a.baseFileData); err != nil {
 				return err
 			}
@@ -1084,6 +1238,25 @@ func (a *Animator) AddBytes(name string, data []byte) error {
 	return nil
 }
 
+// addZip adds the files of a ZIP archive, the XY file first.
+func (a *Animator) addZip(filename string, data []byte) error {
+	files, err := store.ReadZip(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	for _, f := range files {
+		name := path.Join(filename, f.Name)
+		if filenames.KindOf(f.Name) == filenames.XY {
+			a.SetBaseData(name, f.Data)
+			continue
+		}
+		if err := a.AddBytes(name, f.Data); err != nil {
+			return fmt.Errorf("failed to load %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 // AddReader adds game data from an io.Reader. Files from the same year are merged into a single frame.
 func (a *Animator) AddReader(name string, reader io.Reader) error {
 	data, err := io.ReadAll(reader)
@@ -1115,29 +1288,14 @@ func (a *Animator) NormalizeBounds() {
 	}
 
 	// Calculate global bounds (union of all frames)
-	minX := math.MaxInt32
-	maxX := math.MinInt32
-	minY := math.MaxInt32
-	maxY := math.MinInt32
-
+	var bounds geom.Bounds
 	for _, r := range a.renderers {
-		if r.minX < minX {
-			minX = r.minX
-		}
-		if r.maxX > maxX {
-			maxX = r.maxX
-		}
-		if r.minY < minY {
-			minY = r.minY
-		}
-		if r.maxY > maxY {
-			maxY = r.maxY
-		}
+		bounds = bounds.Union(r.bounds)
 	}
 
 	// Apply global bounds to all frames
 	for _, r := range a.renderers {
-		r.setBounds(minX, maxX, minY, maxY)
+		r.setBounds(bounds)
 	}
 }
 
@@ -1171,12 +1329,79 @@ func (a *Animator) WriteGIF(w io.Writer, delayMs int) error {
 	// Normalize bounds across all frames to ensure consistent scaling
 	a.NormalizeBounds()
 
-	n := len(a.renderers)
+	frames, isTurn := tweenFrames(a.renderers, a.inBetween)
+	n := len(frames)
 	delay := delayMs / 10
 
-	// Pre-allocate result slice for parallel rendering
 	results := make([]*image.Paletted, n)
-	errors := make([]error, n)
+	a.renderFrames(frames, func(idx int, img *image.RGBA) {
+		// Convert to paletted image
+		if a.palette != nil {
+			// Use shared palette (faster, more consistent)
+			results[idx] = imageToPalettedWithPalette(img, a.palette)
+		} else {
+			// Compute per-frame palette
+			results[idx] = imageToPaletted(img)
+		}
+	})
+
+	// Assemble GIF in order
+	anim := gif.GIF{
+		LoopCount: 0,
+		Image:     results,
+		Delay:     make([]int, n),
+	}
+	for i := range anim.Delay {
+		anim.Delay[i] = delay
+		if !isTurn[i] {
+			anim.Delay[i] = max(delay/a.inBetween, 2)
+		}
+	}
+
+	if err := gif.EncodeAll(w, &anim); err != nil {
+		return fmt.Errorf("failed to encode GIF: %w", err)
+	}
+
+	return nil
+}
+
+// encodeFrames renders the frames of a full-color animation, turns and the
+// frames between them, and encodes each with encode. Delays are in
+// milliseconds.
+func (a *Animator) encodeFrames(delayMs int, encode func(img *image.RGBA) ([]byte, error)) ([][]byte, []int, error) {
+	if len(a.renderers) == 0 {
+		return nil, nil, fmt.Errorf("no frames to save")
+	}
+
+	// Normalize bounds across all frames to ensure consistent scaling
+	a.NormalizeBounds()
+
+	frames, isTurn := tweenFrames(a.renderers, a.inBetween)
+	encoded := make([][]byte, len(frames))
+	errs := make([]error, len(frames))
+	a.renderFrames(frames, func(idx int, img *image.RGBA) {
+		encoded[idx], errs[idx] = encode(img)
+	})
+	for i, err := range errs {
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode frame %d: %w", i, err)
+		}
+	}
+
+	delays := make([]int, len(frames))
+	for i := range delays {
+		delays[i] = delayMs
+		if !isTurn[i] {
+			delays[i] = max(delayMs/a.inBetween, 20)
+		}
+	}
+	return encoded, delays, nil
+}
+
+// renderFrames renders frames in parallel, passing each image to done, and
+// reports progress. done is called concurrently for different frames.
+func (a *Animator) renderFrames(frames []*Renderer, done func(idx int, img *image.RGBA)) {
+	n := len(frames)
 
 	// Use worker pool to limit concurrency (rendering is memory-bound)
 	workers := runtime.GOMAXPROCS(0)
@@ -1185,8 +1410,12 @@ func (a *Animator) WriteGIF(w io.Writer, delayMs int) error {
 	}
 	sem := make(chan struct{}, workers)
 
+	// Frames finish out of order: count them under a lock
+	var progressMu sync.Mutex
+	rendered := 0
+
 	var wg sync.WaitGroup
-	for i, r := range a.renderers {
+	for i, r := range frames {
 		wg.Add(1)
 		sem <- struct{}{} // Acquire semaphore
 
@@ -1202,41 +1431,17 @@ func (a *Animator) WriteGIF(w io.Writer, delayMs int) error {
 					idx, renderer.Year(), err)
 				img = renderer.Render(a.opts)
 			}
+			done(idx, img)
 
-			// Convert to paletted image
-			if a.palette != nil {
-				// Use shared palette (faster, more consistent)
-				results[idx] = imageToPalettedWithPalette(img, a.palette)
-			} else {
-				// Compute per-frame palette
-				results[idx] = imageToPaletted(img)
+			if a.progress != nil {
+				progressMu.Lock()
+				rendered++
+				a.progress(rendered, n)
+				progressMu.Unlock()
 			}
 		}(i, r)
 	}
 	wg.Wait()
-
-	// Check for any errors
-	for i, err := range errors {
-		if err != nil {
-			return fmt.Errorf("frame %d render failed: %w", i, err)
-		}
-	}
-
-	// Assemble GIF in order
-	anim := gif.GIF{
-		LoopCount: 0,
-		Image:     results,
-		Delay:     make([]int, n),
-	}
-	for i := range anim.Delay {
-		anim.Delay[i] = delay
-	}
-
-	if err := gif.EncodeAll(w, &anim); err != nil {
-		return fmt.Errorf("failed to encode GIF: %w", err)
-	}
-
-	return nil
 }
 
 // RenderGIFBytes returns all frames as an animated GIF in bytes.
@@ -1629,6 +1834,23 @@ var letterPatterns = map[rune][5][3]bool{
 	' ': {{false, false, false}, {false, false, false}, {false, false, false}, {false, false, false}, {false, false, false}},
 }
 
+// Accented letters of the Windows-1252 character set Stars! names are
+// written in, and the letters the bitmap font draws in their place
+var (
+	accentedLetters = []rune("ÀÁÂÃÄÅÇÈÉÊËÌÍÎÏÑÒÓÔÕÖØÙÚÛÜÝàáâãäåçèéêëìíîïñòóôõöøùúûüýÿŠšŽžŸ")
+	unaccented      = []rune("AAAAAACEEEEIIIINOOOOOOUUUUYaaaaaaceeeeiiiinoooooouuuuyySsZzY")
+)
+
+// foldAccent returns the unaccented letter of an accented one.
+func foldAccent(ch rune) rune {
+	for i, a := range accentedLetters {
+		if a == ch {
+			return unaccented[i]
+		}
+	}
+	return ch
+}
+
 // drawText draws a string using the bitmap font
 func drawText(img *image.RGBA, x, y int, text string, col color.RGBA) {
 	startX := x
@@ -1638,6 +1860,7 @@ func drawText(img *image.RGBA, x, y int, text string, col color.RGBA) {
 			y += 12
 			continue
 		}
+		ch = foldAccent(ch)
 		// Try letter patterns first
 		if pattern, ok := letterPatterns[ch]; ok {
 			drawPattern(img, x, y, pattern, col)
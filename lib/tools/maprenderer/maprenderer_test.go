@@ -0,0 +1,38 @@
+package maprenderer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderSVGNamedPlanets(t *testing.T) {
+	r := New()
+	require.NoError(t, r.LoadFileWithXY("../../../testdata/scenario-map/game.m1"))
+
+	// As given to 'houston map --name': any case, spaces after the commas
+	opts := DefaultOptions()
+	plain := r.RenderSVG(opts)
+	opts.NamedPlanets = strings.Split("woody, LGM 4,Atlantis", ",")
+	svg := r.RenderSVG(opts)
+	assert.Equal(t, strings.Count(plain, "<text")+2, strings.Count(svg, "<text"))
+
+	// Woody is the enemy homeworld, named in its owner's color next to it
+	woody, ok := r.store.PlanetByName("Woody")
+	require.True(t, ok)
+	x, y := r.Projection(opts).ToScreen(woody.X, woody.Y)
+	col := r.GetPlayerColor(1)
+	assert.Contains(t, svg, fmt.Sprintf(`<text x="%.1f" y="%.1f" fill="rgb(%d,%d,%d)" font-size="10" font-family="monospace">Woody</text>`,
+		x+5, y-5, col.R, col.G, col.B))
+	assert.Contains(t, svg, ">LGM 4</text>")
+	assert.NotContains(t, svg, ">Gates</text>")
+
+	// ShowNames names every planet, the selected ones once
+	opts.ShowNames = true
+	svg = r.RenderSVG(opts)
+	assert.Equal(t, 1, strings.Count(svg, ">Woody</text>"))
+	assert.Contains(t, svg, ">Gates</text>")
+}
@@ -0,0 +1,175 @@
+package maprenderer
+
+import "image/color"
+
+// Option changes the rendering options; see NewOptions.
+type Option func(*RenderOptions)
+
+// NewOptions returns the default options (see DefaultOptions) changed by
+// opts, applied in order:
+//
+//	opts := maprenderer.NewOptions(
+//		maprenderer.WithSize(1024, 768),
+//		maprenderer.WithLayer(maprenderer.LayerMinefields, maprenderer.LayerScanners),
+//		maprenderer.WithoutLayer(maprenderer.LayerLegend),
+//	)
+func NewOptions(opts ...Option) *RenderOptions {
+	return DefaultOptions().Apply(opts...)
+}
+
+// Apply changes the options with opts, in order, and returns them.
+func (o *RenderOptions) Apply(opts ...Option) *RenderOptions {
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// optionalLayers are the layers the options can hide. Fleet paths and
+// trails are shown with WithFleetPaths and WithFleetTrails; planets,
+// hotspots and the year are always drawn.
+var optionalLayers = []string{LayerMinefields, LayerScanners, LayerWormholes, LayerFleets, LayerLegend}
+
+// layer returns the option field showing a layer, or nil if it cannot be
+// hidden.
+func (o *RenderOptions) layer(name string) *bool {
+	switch name {
+	case LayerMinefields:
+		return &o.ShowMines
+	case LayerScanners:
+		return &o.ShowScannerCoverage
+	case LayerWormholes:
+		return &o.ShowWormholes
+	case LayerFleets:
+		return &o.ShowFleets
+	case LayerLegend:
+		return &o.ShowLegend
+	}
+	return nil
+}
+
+// WithLayer shows layers of the map, named by the Layer constants.
+func WithLayer(layers ...string) Option {
+	return func(o *RenderOptions) {
+		for _, l := range layers {
+			if show := o.layer(l); show != nil {
+				*show = true
+			}
+		}
+	}
+}
+
+// WithoutLayer hides layers of the map.
+func WithoutLayer(layers ...string) Option {
+	return func(o *RenderOptions) {
+		for _, l := range layers {
+			if show := o.layer(l); show != nil {
+				*show = false
+			}
+		}
+	}
+}
+
+// WithOnlyLayers shows the given layers and hides the other optional ones.
+func WithOnlyLayers(layers ...string) Option {
+	return func(o *RenderOptions) {
+		WithoutLayer(optionalLayers...)(o)
+		WithLayer(layers...)(o)
+	}
+}
+
+// WithPlanetNames draws the names of all planets (or none, see
+// WithNamedPlanets).
+func WithPlanetNames(show bool) Option {
+	return func(o *RenderOptions) {
+		o.ShowNames = show
+	}
+}
+
+// WithSize sets the image size in pixels.
+func WithSize(width, height int) Option {
+	return func(o *RenderOptions) {
+		o.Width = width
+		o.Height = height
+	}
+}
+
+// WithPadding sets the padding around the galaxy in pixels.
+func WithPadding(padding int) Option {
+	return func(o *RenderOptions) {
+		o.Padding = padding
+	}
+}
+
+// WithFleetPaths projects fleet paths for the given number of years
+// (0 disables them).
+func WithFleetPaths(years int) Option {
+	return func(o *RenderOptions) {
+		o.ShowFleetPaths = years
+	}
+}
+
+// WithFleetETAs labels the waypoints of fleet paths with the years the
+// fleet needs to reach them, from the warp of each leg. The labels are
+// drawn on the paths shown with WithFleetPaths.
+func WithFleetETAs(show bool) Option {
+	return func(o *RenderOptions) {
+		o.ShowFleetETAs = show
+	}
+}
+
+// WithPlainFleets draws fleets as plain triangles instead of role glyphs
+// (see RenderOptions.PlainFleets).
+func WithPlainFleets(plain bool) Option {
+	return func(o *RenderOptions) {
+		o.PlainFleets = plain
+	}
+}
+
+// WithFleetTrails draws the trails of fleets over the given number of past
+// turns (0 disables them). Trails need earlier turns, see
+// Renderer.SetHistory.
+func WithFleetTrails(turns int) Option {
+	return func(o *RenderOptions) {
+		o.ShowFleetTrails = turns
+	}
+}
+
+// WithNamedPlanets adds planets whose name is drawn even without the names
+// layer.
+func WithNamedPlanets(names ...string) Option {
+	return func(o *RenderOptions) {
+		o.NamedPlanets = append(o.NamedPlanets, names...)
+	}
+}
+
+// WithPlayerColors overrides the player colors (see RenderOptions.PlayerColors).
+func WithPlayerColors(colors []color.RGBA) Option {
+	return func(o *RenderOptions) {
+		o.PlayerColors = colors
+	}
+}
+
+// WithViewAsPlayer draws the map as a player (1-16) could see it, 0 for
+// everything (see RenderOptions.ViewAsPlayer).
+func WithViewAsPlayer(player int) Option {
+	return func(o *RenderOptions) {
+		o.ViewAsPlayer = player
+	}
+}
+
+// WithScannerPlayers draws the scanner coverage of the given players
+// (1-16) only (see RenderOptions.ScannerPlayers).
+func WithScannerPlayers(players ...int) Option {
+	return func(o *RenderOptions) {
+		o.ShowScannerCoverage = true
+		o.ScannerPlayers = players
+	}
+}
+
+// WithHotspots adds highlighted locations to the map.
+func WithHotspots(hotspots ...Hotspot) Option {
+	return func(o *RenderOptions) {
+		o.Hotspots = append(o.Hotspots, hotspots...)
+	}
+}
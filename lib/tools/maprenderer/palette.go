@@ -0,0 +1,118 @@
+package maprenderer
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownPalette is returned by PlayerPalette for an unknown name.
+var ErrUnknownPalette = errors.New("unknown palette")
+
+// colorBlindColors is safe for the common forms of color blindness: the
+// Okabe-Ito palette (with white instead of black, the map background) for
+// the first eight players, then Paul Tol's "light" scheme. Grays are
+// avoided as they are used for unowned planets.
+var colorBlindColors = []color.RGBA{
+	{230, 159, 0, 255},   // Orange
+	{86, 180, 233, 255},  // Sky blue
+	{0, 158, 115, 255},   // Bluish green
+	{240, 228, 66, 255},  // Yellow
+	{0, 114, 178, 255},   // Blue
+	{213, 94, 0, 255},    // Vermillion
+	{204, 121, 167, 255}, // Reddish purple
+	{255, 255, 255, 255}, // White
+	{119, 170, 221, 255}, // Light blue
+	{238, 136, 102, 255}, // Orange
+	{238, 221, 136, 255}, // Light yellow
+	{255, 170, 187, 255}, // Pink
+	{153, 221, 255, 255}, // Light cyan
+	{68, 187, 153, 255},  // Mint
+	{187, 204, 51, 255},  // Pear
+	{170, 170, 0, 255},   // Olive
+}
+
+var playerPalettes = map[string][]color.RGBA{
+	"default":    playerColors,
+	"colorblind": colorBlindColors,
+}
+
+// PlayerPaletteNames returns the names of the built-in player palettes.
+func PlayerPaletteNames() []string {
+	names := make([]string, 0, len(playerPalettes))
+	for name := range playerPalettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PlayerPalette returns a copy of a built-in player palette: "default"
+// (the classic colors) or "colorblind" (distinguishable with the common
+// forms of color blindness).
+func PlayerPalette(name string) ([]color.RGBA, error) {
+	p, ok := playerPalettes[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w %q (available: %s)", ErrUnknownPalette, name, strings.Join(PlayerPaletteNames(), ", "))
+	}
+	return append([]color.RGBA(nil), p...), nil
+}
+
+// ParsePlayerColors parses player colors from a built-in palette name or a
+// comma or space separated list of hex colors ("#rrggbb", "rrggbb" or
+// "#rgb"), one per player starting with player 1.
+func ParsePlayerColors(s string) ([]color.RGBA, error) {
+	if !strings.ContainsAny(s, ",# ") {
+		p, err := PlayerPalette(s)
+		if err == nil {
+			return p, nil
+		}
+		if _, hexErr := parseHexColor(s); hexErr != nil {
+			return nil, err
+		}
+	}
+
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) == 0 {
+		return nil, errors.New("no colors given")
+	}
+	colors := make([]color.RGBA, 0, len(fields))
+	for _, f := range fields {
+		c, err := parseHexColor(f)
+		if err != nil {
+			return nil, err
+		}
+		colors = append(colors, c)
+	}
+	return colors, nil
+}
+
+func parseHexColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid color %q: expected #rrggbb", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q: expected #rrggbb", s)
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, nil
+}
+
+// PlayerColor returns the color of a player (0-15): from PlayerColors when
+// set and long enough, otherwise from the default palette.
+func (o *RenderOptions) PlayerColor(playerNum int) color.RGBA {
+	if o != nil && playerNum >= 0 && playerNum < len(o.PlayerColors) {
+		return o.PlayerColors[playerNum]
+	}
+	if playerNum >= 0 && playerNum < len(playerColors) {
+		return playerColors[playerNum]
+	}
+	return color.RGBA{128, 128, 128, 255}
+}
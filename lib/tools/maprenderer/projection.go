@@ -0,0 +1,61 @@
+package maprenderer
+
+import "math"
+
+// Projection maps game coordinates (light years, Y pointing up) to image
+// pixels (Y pointing down) and back. It is the transform the renderer draws
+// with, so front-ends and overlays using it hit the same pixels.
+type Projection struct {
+	Scale            float64 // Pixels per light year
+	OffsetX, OffsetY float64 // Pixel position of the galaxy's top-left corner
+	MinX, MaxY       int     // Game coordinates of the galaxy's left and top edges
+}
+
+// Projection returns the projection used to render the loaded galaxy with
+// the given options: the galaxy bounds are scaled uniformly to fit inside
+// the padded image and centered.
+func (r *Renderer) Projection(opts *RenderOptions) Projection {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	rangeX := math.Max(1, float64(r.bounds.Width()))
+	rangeY := math.Max(1, float64(r.bounds.Height()))
+
+	padding := float64(opts.Padding)
+	availWidth := float64(opts.Width) - 2*padding
+	availHeight := float64(opts.Height) - 2*padding
+	scale := math.Min(availWidth/rangeX, availHeight/rangeY)
+
+	return Projection{
+		Scale:   scale,
+		OffsetX: padding + (availWidth-rangeX*scale)/2,
+		OffsetY: padding + (availHeight-rangeY*scale)/2,
+		MinX:    r.bounds.MinX,
+		MaxY:    r.bounds.MaxY,
+	}
+}
+
+// ToScreen converts game coordinates to pixel coordinates.
+func (p Projection) ToScreen(x, y int) (float64, float64) {
+	px := p.OffsetX + float64(x-p.MinX)*p.Scale
+	py := p.OffsetY + float64(p.MaxY-y)*p.Scale // Flip Y axis
+	return px, py
+}
+
+// ToScreenPixel converts game coordinates to the nearest pixel.
+func (p Projection) ToScreenPixel(x, y int) (int, int) {
+	px, py := p.ToScreen(x, y)
+	return int(math.Round(px)), int(math.Round(py))
+}
+
+// ToGame converts pixel coordinates to the nearest game coordinates, e.g.
+// to find what the user clicked on.
+func (p Projection) ToGame(px, py float64) (int, int) {
+	if p.Scale == 0 {
+		return p.MinX, p.MaxY
+	}
+	x := p.MinX + int(math.Round((px-p.OffsetX)/p.Scale))
+	y := p.MaxY - int(math.Round((py-p.OffsetY)/p.Scale))
+	return x, y
+}
@@ -0,0 +1,54 @@
+package maprenderer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjection(t *testing.T) {
+	// The first turn of a small galaxy, 345 ly wide and 367 ly high
+	r := New()
+	require.NoError(t, r.LoadFileWithXY("../../../testdata/scenario-basic/game.m1"))
+	p := r.Projection(&RenderOptions{Width: 800, Height: 600, Padding: 20})
+
+	// Height is what limits the scale; the galaxy is centered across
+	assert.InDelta(t, 560.0/367, p.Scale, 1e-9)
+	assert.Equal(t, 1039, p.MinX)
+	assert.Equal(t, 1381, p.MaxY)
+	left, top := p.ToScreen(1039, 1381)
+	right, bottom := p.ToScreen(1384, 1014)
+	assert.InDelta(t, 20, top, 1e-9)
+	assert.InDelta(t, 580, bottom, 1e-9)
+	assert.InDelta(t, 800, left+right, 1e-9)
+
+	// Clicking a planet's pixel finds the planet's coordinates
+	for _, planet := range r.store.AllPlanets() {
+		x, y := p.ToGame(p.ToScreen(planet.X, planet.Y))
+		assert.Equal(t, [2]int{planet.X, planet.Y}, [2]int{x, y}, planet.Name)
+	}
+	px, py := p.ToScreenPixel(1102, 1103) // Abacus, the homeworld
+	x, y := p.ToGame(float64(px), float64(py))
+	assert.InDelta(t, 1102, x, 1)
+	assert.InDelta(t, 1103, y, 1)
+
+	// Without a scale there is nothing to click on but the corner
+	x, y = Projection{MinX: 1039, MaxY: 1381}.ToGame(400, 300)
+	assert.Equal(t, [2]int{1039, 1381}, [2]int{x, y})
+}
+
+func TestProjectionMatchesSVG(t *testing.T) {
+	r := New()
+	require.NoError(t, r.LoadFileWithXY("../../../testdata/scenario-basic/game.m1"))
+	opts := &RenderOptions{Width: 500, Height: 400, Padding: 35}
+	p := r.Projection(opts)
+	svg := r.RenderSVG(opts)
+
+	for _, planet := range r.store.AllPlanets() {
+		x, y := p.ToScreen(planet.X, planet.Y)
+		assert.Contains(t, svg, fmt.Sprintf(`<circle cx="%.1f" cy="%.1f"`, x, y), planet.Name)
+	}
+	assert.Equal(t, r.Projection(DefaultOptions()), r.Projection(nil))
+}
@@ -0,0 +1,122 @@
+package maprenderer
+
+import (
+	"image"
+	"image/color"
+	"slices"
+	"sort"
+)
+
+// scannerCircle is the range of a planet or fleet scanner.
+type scannerCircle struct {
+	x, y   int // Game coordinates (not transformed)
+	radius int // Range in ly
+	owner  int
+}
+
+// showsScanners reports whether the scanner coverage of a player (0-15) is
+// drawn (see RenderOptions.ScannerPlayers).
+func (o *RenderOptions) showsScanners(owner int) bool {
+	return len(o.ScannerPlayers) == 0 || slices.Contains(o.ScannerPlayers, owner+1)
+}
+
+// scannerCircles returns the normal and penetrating scanner ranges of the
+// planets and fleets drawn, without the circles lying within a larger one
+// of the same player.
+func (r *Renderer) scannerCircles(opts *RenderOptions, view *fog) (normal, pen []scannerCircle) {
+	// Planetary scanners, starbase scanners, and PRT intrinsic scanners
+	for _, planet := range r.store.AllPlanets() {
+		if planet.Owner < 0 || !view.owns(planet.Owner) || !opts.showsScanners(planet.Owner) {
+			continue
+		}
+		normalRange, penRange := planet.GetScannerRanges(r.store)
+		if normalRange > 0 {
+			normal = append(normal, scannerCircle{planet.X, planet.Y, normalRange, planet.Owner})
+		}
+		if penRange > 0 {
+			pen = append(pen, scannerCircle{planet.X, planet.Y, penRange, planet.Owner})
+		}
+	}
+
+	for _, fleet := range r.store.AllFleets() {
+		if !view.owns(fleet.Owner) || !opts.showsScanners(fleet.Owner) {
+			continue
+		}
+		normalRange, penRange := fleet.GetScannerRanges(r.store)
+		x, y := r.fleetPosition(fleet)
+		if normalRange > 0 {
+			normal = append(normal, scannerCircle{x, y, normalRange, fleet.Owner})
+		}
+		if penRange > 0 {
+			pen = append(pen, scannerCircle{x, y, penRange, fleet.Owner})
+		}
+	}
+	return filterContained(normal), filterContained(pen)
+}
+
+// filterContained drops the circles lying within a larger circle of the
+// same owner. Sorting by radius descending means a circle is only checked
+// against larger ones, with squared distances to avoid sqrt in the hot path.
+func filterContained(scanners []scannerCircle) []scannerCircle {
+	if len(scanners) <= 1 {
+		return scanners
+	}
+
+	// Sort by radius descending - larger circles checked first
+	sort.Slice(scanners, func(i, j int) bool {
+		return scanners[i].radius > scanners[j].radius
+	})
+
+	result := make([]scannerCircle, 0, len(scanners))
+	for _, s := range scanners {
+		contained := false
+		// Only check against larger (or equal) circles already in result
+		for _, other := range result {
+			if s.owner != other.owner {
+				continue
+			}
+			// A is contained in B if: dist(A,B) + radiusA <= radiusB
+			// Rearranged: dist(A,B) <= radiusB - radiusA
+			radiusDiff := other.radius - s.radius
+			if radiusDiff < 0 {
+				continue
+			}
+			dx := s.x - other.x
+			dy := s.y - other.y
+			if dx*dx+dy*dy <= radiusDiff*radiusDiff {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// drawCoverage blends a translucent disc into the image, alpha being the
+// opacity of the fill (0-255), and draws its outline.
+func drawCoverage(img *image.RGBA, cx, cy, radius int, col color.RGBA, alpha uint8, outline color.RGBA) {
+	bounds := img.Bounds()
+	radiusSq := radius * radius
+	a := uint32(alpha)
+	for dy := -radius; dy <= radius; dy++ {
+		py := cy + dy
+		if py < bounds.Min.Y || py >= bounds.Max.Y {
+			continue
+		}
+		for dx := -radius; dx <= radius; dx++ {
+			px := cx + dx
+			if px < bounds.Min.X || px >= bounds.Max.X || dx*dx+dy*dy > radiusSq {
+				continue
+			}
+			i := img.PixOffset(px, py)
+			pix := img.Pix[i : i+3 : i+3]
+			pix[0] = uint8((uint32(pix[0])*(255-a) + uint32(col.R)*a) / 255)
+			pix[1] = uint8((uint32(pix[1])*(255-a) + uint32(col.G)*a) / 255)
+			pix[2] = uint8((uint32(pix[2])*(255-a) + uint32(col.B)*a) / 255)
+		}
+	}
+	drawCircleOutline(img, cx, cy, radius, outline)
+}
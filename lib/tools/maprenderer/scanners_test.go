@@ -0,0 +1,116 @@
+package maprenderer
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShowsScanners(t *testing.T) {
+	opts := DefaultOptions()
+	assert.True(t, opts.showsScanners(0))
+	assert.True(t, opts.showsScanners(15))
+
+	opts = NewOptions(WithScannerPlayers(2, 4))
+	assert.False(t, opts.showsScanners(0))
+	assert.True(t, opts.showsScanners(1))
+	assert.True(t, opts.showsScanners(3))
+}
+
+func TestFilterContained(t *testing.T) {
+	circles := []scannerCircle{
+		{x: 0, y: 0, radius: 50, owner: 0},
+		{x: 10, y: 0, radius: 100, owner: 0},
+		{x: 110, y: 0, radius: 10, owner: 0}, // Touches the edge from outside
+		{x: 100, y: 0, radius: 10, owner: 0}, // Touches the edge from inside
+		{x: 10, y: 0, radius: 30, owner: 1},  // Another player's
+		{x: 10, y: 0, radius: 100, owner: 0}, // Same circle twice
+	}
+	assert.ElementsMatch(t, []scannerCircle{
+		{x: 10, y: 0, radius: 100, owner: 0},
+		{x: 110, y: 0, radius: 10, owner: 0},
+		{x: 10, y: 0, radius: 30, owner: 1},
+	}, filterContained(circles))
+
+	assert.Empty(t, filterContained(nil))
+	one := []scannerCircle{{x: 1, y: 2, radius: 3}}
+	assert.Equal(t, one, filterContained(one))
+}
+
+func TestScannerCircles(t *testing.T) {
+	r := bothPlayers(t)
+
+	// Player 1's scouts and homeworld, its fleets there adding nothing
+	player1 := []scannerCircle{
+		{1273, 1341, 200, 0}, {1184, 1657, 200, 0}, {1702, 1818, 200, 0},
+		{1662, 1413, 200, 0}, {1115, 1041, 200, 0}, {1897, 1171, 200, 0},
+		{1965, 1968, 200, 0}, {1058, 1272, 200, 0}, {1249, 1149, 200, 0},
+	}
+	player2 := []scannerCircle{{1992, 2075, 200, 1}, {2186, 2096, 200, 1}}
+	penOf := func(circles []scannerCircle) []scannerCircle {
+		var pen []scannerCircle
+		for _, c := range circles {
+			c.radius = 100
+			pen = append(pen, c)
+		}
+		return pen
+	}
+
+	opts := DefaultOptions()
+	normal, pen := r.scannerCircles(opts, r.fog(opts))
+	assert.ElementsMatch(t, append(append([]scannerCircle{}, player1...), player2...), normal)
+	assert.ElementsMatch(t, append(penOf(player1), penOf(player2)...), pen)
+
+	opts = NewOptions(WithScannerPlayers(1))
+	normal, pen = r.scannerCircles(opts, r.fog(opts))
+	assert.ElementsMatch(t, player1, normal)
+	assert.ElementsMatch(t, penOf(player1), pen)
+
+	// A player's view only holds their own scanners
+	opts = NewOptions(WithViewAsPlayer(2))
+	normal, pen = r.scannerCircles(opts, r.fog(opts))
+	assert.ElementsMatch(t, player2, normal)
+	assert.ElementsMatch(t, penOf(player2), pen)
+
+	opts = NewOptions(WithViewAsPlayer(2), WithScannerPlayers(1))
+	normal, pen = r.scannerCircles(opts, r.fog(opts))
+	assert.Empty(t, normal)
+	assert.Empty(t, pen)
+}
+
+func TestDrawCoverage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:], []byte{0, 0, 100, 255})
+	}
+	outline := color.RGBA{1, 2, 3, 255}
+	drawCoverage(img, 10, 10, 5, color.RGBA{255, 0, 0, 255}, 51, outline)
+
+	// Blended inside, a fifth of the way to red
+	assert.Equal(t, color.RGBA{51, 0, 80, 255}, img.RGBAAt(10, 10))
+	assert.Equal(t, color.RGBA{51, 0, 80, 255}, img.RGBAAt(12, 12))
+	// Outlined on the edge, untouched outside
+	assert.Equal(t, outline, img.RGBAAt(15, 10))
+	assert.Equal(t, color.RGBA{0, 0, 100, 255}, img.RGBAAt(0, 0))
+
+	// Discs crossing the edges of the image are clipped
+	drawCoverage(img, 0, 0, 30, color.RGBA{255, 0, 0, 255}, 255, outline)
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, img.RGBAAt(19, 19))
+}
+
+func TestRenderSVGScannerCoverage(t *testing.T) {
+	r := bothPlayers(t)
+	svg := r.RenderSVG(NewOptions(WithSize(400, 300), WithScannerPlayers(2)))
+
+	// Each player's circles are grouped under their player class
+	assert.Equal(t, 2, strings.Count(svg, `class="scanner player-1"`))
+	assert.Equal(t, 2, strings.Count(svg, `class="scanner penetrating player-1"`))
+	assert.NotContains(t, svg, `class="scanner player-0"`)
+	assert.NotContains(t, svg, `class="scanner penetrating player-0"`)
+
+	svg = r.RenderSVG(NewOptions(WithSize(400, 300), WithoutLayer(LayerScanners)))
+	assert.NotContains(t, svg, `class="scanner`)
+}
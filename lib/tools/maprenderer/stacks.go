@@ -0,0 +1,89 @@
+package maprenderer
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/neper-stars/houston/store"
+)
+
+// Fleets sharing a point are fanned out on a ring around it so none hides
+// another. Past maxFannedFleets the remaining fleets are drawn on the point
+// itself; the count badge still gives the total.
+const (
+	stackRadius     = 7 // Ring radius in pixels
+	maxFannedFleets = 8
+)
+
+// fleetStack is the fleets at one point of the map.
+type fleetStack struct {
+	X, Y   int
+	Fleets []*store.FleetEntity
+}
+
+// fleetStacks groups the fleets in view by position, in store order.
+func (r *Renderer) fleetStacks(view *fog) []fleetStack {
+	var stacks []fleetStack
+	index := make(map[[2]int]int)
+	for _, fleet := range r.store.AllFleets() {
+		if !view.seesFleet(fleet) {
+			continue
+		}
+		x, y := r.fleetPosition(fleet)
+		key := [2]int{x, y}
+		i, ok := index[key]
+		if !ok {
+			i = len(stacks)
+			index[key] = i
+			stacks = append(stacks, fleetStack{X: x, Y: y})
+		}
+		stacks[i].Fleets = append(stacks[i].Fleets, fleet)
+	}
+	return stacks
+}
+
+// fanOffset returns the screen offset of the i-th of n stacked fleets,
+// starting at the top of the ring and going clockwise.
+func fanOffset(i, n int) (float64, float64) {
+	if n < 2 || i >= maxFannedFleets {
+		return 0, 0
+	}
+	angle := glyphUp + 2*math.Pi*float64(i)/float64(min(n, maxFannedFleets))
+	return math.Cos(angle) * stackRadius, math.Sin(angle) * stackRadius
+}
+
+// stackColor returns the color of the count badge of a stack: the owner's
+// color, or white when several players have fleets there.
+func (o *RenderOptions) stackColor(stack fleetStack) color.RGBA {
+	owner := stack.Fleets[0].Owner
+	for _, fleet := range stack.Fleets[1:] {
+		if fleet.Owner != owner {
+			return color.RGBA{255, 255, 255, 255}
+		}
+	}
+	return o.PlayerColor(owner)
+}
+
+// stackTitle returns the tooltip of a stack, listing its fleets.
+func (r *Renderer) stackTitle(stack fleetStack) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d fleets at (%d, %d)", len(stack.Fleets), stack.X, stack.Y)
+	for _, fleet := range stack.Fleets {
+		ships := "ships"
+		if fleet.TotalShips() == 1 {
+			ships = "ship"
+		}
+		fmt.Fprintf(&b, "\n%s (%s, %d %s)", fleet.Name(), r.playerName(fleet.Owner), fleet.TotalShips(), ships)
+	}
+	return b.String()
+}
+
+// playerName returns the singular race name of a player, or "Player N".
+func (r *Renderer) playerName(playerNumber int) string {
+	if player, ok := r.store.Player(playerNumber); ok && player.NameSingular != "" {
+		return player.NameSingular
+	}
+	return fmt.Sprintf("Player %d", playerNumber+1)
+}
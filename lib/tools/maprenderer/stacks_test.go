@@ -0,0 +1,90 @@
+package maprenderer
+
+import (
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFleetStacks(t *testing.T) {
+	// Late game: Hobbit fleets gather at their homeworld, both players
+	// meet in the enemy's home system
+	r := New()
+	require.NoError(t, r.LoadFileWithXY("../../../testdata/scenario-map/history/game-2482.m1"))
+	stacks := r.fleetStacks(nil)
+	var fleets int
+	stacked := make(map[[2]int]fleetStack)
+	for _, stack := range stacks {
+		fleets += len(stack.Fleets)
+		if len(stack.Fleets) > 1 {
+			stacked[[2]int{stack.X, stack.Y}] = stack
+		}
+	}
+	assert.Len(t, stacks, 11)
+	assert.Equal(t, len(r.store.AllFleets()), fleets)
+	require.Len(t, stacked, 3)
+
+	home := stacked[[2]int{1273, 1341}]
+	assert.Equal(t, "3 fleets at (1273, 1341)\n"+
+		"Fleet 1 (Hobbit, 22 ships)\n"+
+		"Maxi-Miner #5 (Hobbit, 1 ship)\n"+
+		"Teamster #6 (Hobbit, 2 ships)", r.stackTitle(home))
+
+	// One owner gives its color to the badge, a mixed stack is white
+	opts := DefaultOptions()
+	assert.Equal(t, r.GetPlayerColor(0), opts.stackColor(home))
+	assert.Equal(t, color.RGBA{255, 255, 255, 255}, opts.stackColor(stacked[[2]int{1991, 2054}]))
+	assert.Equal(t, r.GetPlayerColor(1), opts.stackColor(stacked[[2]int{1992, 2075}]))
+}
+
+func TestFanOffset(t *testing.T) {
+	// A lone fleet and the fleets past the ring stay on the point
+	for _, in := range [][2]int{{0, 1}, {maxFannedFleets, 10}, {11, 12}} {
+		x, y := fanOffset(in[0], in[1])
+		assert.Zero(t, x)
+		assert.Zero(t, y)
+	}
+
+	// The first fleet is on top, the others clockwise on screen
+	x, y := fanOffset(0, 3)
+	assert.InDelta(t, 0, x, 1e-9)
+	assert.InDelta(t, -stackRadius, y, 1e-9)
+	x, _ = fanOffset(1, 3)
+	assert.Positive(t, x)
+
+	// A crowded point spreads its first fleets evenly on the ring
+	seen := make(map[[2]float64]bool)
+	for i := range maxFannedFleets {
+		x, y := fanOffset(i, 20)
+		assert.InDelta(t, stackRadius, math.Hypot(x, y), 1e-9)
+		seen[[2]float64{math.Round(x * 100), math.Round(y * 100)}] = true
+	}
+	assert.Len(t, seen, maxFannedFleets)
+}
+
+func TestRenderSVGFleetStacks(t *testing.T) {
+	r := New()
+	require.NoError(t, r.LoadFileWithXY("../../../testdata/scenario-map/history/game-2482.m1"))
+	svg := r.RenderSVG(DefaultOptions())
+
+	// Each stack groups its fleets under a tooltip and a count badge
+	assert.Equal(t, 3, strings.Count(svg, `class="fleet-stack"`))
+	assert.Contains(t, svg, `<g id="fleet-stack-1991-2054" class="fleet-stack">`+"\n"+
+		"<title>2 fleets at (1991, 2054)\nStealth Scout #13 (Hobbit, 1 ship)\nFleet #6 (Halfling, 1 ship)</title>")
+	assert.Contains(t, svg, `fill="rgb(255,255,255)" font-size="7" font-family="monospace" text-anchor="middle">2</text>`)
+	assert.Contains(t, svg, `fill="rgb(255,3,3)" font-size="7" font-family="monospace" text-anchor="middle">3</text>`)
+	for _, fleet := range r.store.AllFleets() {
+		assert.Equal(t, 1, strings.Count(svg, `<g id="`+FleetElementID(fleet.Owner, fleet.FleetNumber)+`"`))
+	}
+
+	// Tooltips are not drawn when rasterizing
+	_, err := r.RenderSVGToImage(DefaultOptions())
+	require.NoError(t, err)
+	b := NewSVGBuilder(10, 10)
+	b.forRasterization = true
+	assert.NotContains(t, b.Title("hidden").String(), "<title>")
+}
@@ -2,19 +2,98 @@ package maprenderer
 
 import (
 	"fmt"
+	"html"
 	"image/color"
 	"math"
 	"strings"
 )
 
 // SVGBuilder provides a fluent interface for building SVG documents.
+//
+// Maps rendered by Renderer.RenderSVG are structured for styling and
+// scripting by downstream tools:
+//
+//   - Each kind of object is drawn in its own layer, a group with id
+//     "layer-NAME" and class "layer NAME" (see the Layer* constants),
+//     in drawing order.
+//   - Each planet, fleet, minefield and wormhole is a group whose id is
+//     given by PlanetElementID, FleetElementID, MinefieldElementID and
+//     WormholeElementID, with classes such as "planet player-2 starbase"
+//     or "fleet player-0 role-scout moving". Player classes use the
+//     0-based player index; unowned planets have the "unowned" class.
+//     Fleet role classes are left out with RenderOptions.PlainFleets.
+//   - Fleets sharing a point are fanned out around it inside a
+//     "fleet-stack" group (see FleetStackElementID) with a count badge
+//     and a <title> tooltip listing them.
+//
+// AddStyle embeds a stylesheet targeting these ids and classes.
 type SVGBuilder struct {
 	width, height    int
 	elements         []string
 	defs             []string
+	styles           []string
+	openGroups       int
 	forRasterization bool // If true, skip markers and patterns during element creation
 }
 
+// Layers of a rendered map, in drawing order.
+const (
+	LayerMinefields  = "minefields"
+	LayerScanners    = "scanners"
+	LayerWormholes   = "wormholes"
+	LayerFleetTrails = "fleet-trails"
+	LayerFleetPaths  = "fleet-paths"
+	LayerPlanets     = "planets"
+	LayerHotspots    = "hotspots"
+	LayerFleets      = "fleets"
+	LayerLegend      = "legend"
+	LayerYear        = "year"
+)
+
+// PlanetElementID returns the SVG id of a planet's group.
+func PlanetElementID(planetNumber int) string {
+	return fmt.Sprintf("planet-%d", planetNumber)
+}
+
+// FleetElementID returns the SVG id of a fleet's group, from its owner's
+// player index and its fleet number (as in store.GameStore.Fleet).
+func FleetElementID(owner, fleetNumber int) string {
+	return fmt.Sprintf("fleet-%d-%d", owner, fleetNumber)
+}
+
+// FleetStackElementID returns the SVG id of the group holding the fleets
+// stacked at a point, from its game coordinates.
+func FleetStackElementID(x, y int) string {
+	return fmt.Sprintf("fleet-stack-%d-%d", x, y)
+}
+
+// MinefieldElementID returns the SVG id of a minefield's group.
+func MinefieldElementID(owner, number int) string {
+	return fmt.Sprintf("minefield-%d-%d", owner, number)
+}
+
+// WormholeElementID returns the SVG id of a wormhole's group.
+func WormholeElementID(wormholeID int) string {
+	return fmt.Sprintf("wormhole-%d", wormholeID)
+}
+
+// playerClass returns the CSS class of objects owned by a player.
+func playerClass(owner int) string {
+	return fmt.Sprintf("player-%d", owner)
+}
+
+// fleetTrailClass returns the classes of a fleet's trail group, which like
+// its path carries the fleet element id as a class.
+func fleetTrailClass(owner, number int) string {
+	return "fleet-trail " + playerClass(owner) + " " + FleetElementID(owner, number)
+}
+
+// fleetPathClass returns the classes of a fleet's path group. Paths carry
+// the fleet element id as a class so both can be selected together.
+func fleetPathClass(owner, number int) string {
+	return "fleet-path " + playerClass(owner) + " " + FleetElementID(owner, number)
+}
+
 // NewSVGBuilder creates a new SVG builder with the given dimensions.
 // Pre-allocates slices for typical map rendering (500+ elements).
 func NewSVGBuilder(width, height int) *SVGBuilder {
@@ -48,6 +127,46 @@ func (b *SVGBuilder) AddDef(def string) *SVGBuilder {
 	return b
 }
 
+// AddStyle adds a CSS stylesheet to the document, e.g.
+// ".fleet.player-1 { display: none }".
+func (b *SVGBuilder) AddStyle(css string) *SVGBuilder {
+	b.styles = append(b.styles, css)
+	return b
+}
+
+// BeginGroup opens a <g> element with the given id and class (either may
+// be empty). Elements added until the matching EndGroup belong to it.
+func (b *SVGBuilder) BeginGroup(id, class string) *SVGBuilder {
+	var g strings.Builder
+	g.WriteString("<g")
+	if id != "" {
+		fmt.Fprintf(&g, ` id="%s"`, html.EscapeString(id))
+	}
+	if class != "" {
+		fmt.Fprintf(&g, ` class="%s"`, html.EscapeString(class))
+	}
+	g.WriteString(">")
+	b.elements = append(b.elements, g.String())
+	b.openGroups++
+	return b
+}
+
+// EndGroup closes the innermost group opened by BeginGroup or BeginLayer.
+func (b *SVGBuilder) EndGroup() *SVGBuilder {
+	if b.openGroups == 0 {
+		return b
+	}
+	b.elements = append(b.elements, "</g>")
+	b.openGroups--
+	return b
+}
+
+// BeginLayer opens a layer: a group with id "layer-NAME" and class
+// "layer NAME". Close it with EndGroup.
+func (b *SVGBuilder) BeginLayer(name string) *SVGBuilder {
+	return b.BeginGroup("layer-"+name, "layer "+name)
+}
+
 // AddMinefieldHatchPattern adds the standard minefield hatching pattern.
 func (b *SVGBuilder) AddMinefieldHatchPattern() *SVGBuilder {
 	b.defs = append(b.defs, `<pattern id="minefield-hatch" patternUnits="userSpaceOnUse" width="6" height="6" patternTransform="rotate(45)">
@@ -112,6 +231,16 @@ func (b *SVGBuilder) Minefield(cx, cy, r float64, col color.RGBA) *SVGBuilder {
 	return b
 }
 
+// Title adds a <title> element, shown by browsers as the tooltip of the
+// enclosing group. Skipped when forRasterization is true.
+func (b *SVGBuilder) Title(text string) *SVGBuilder {
+	if b.forRasterization {
+		return b
+	}
+	b.elements = append(b.elements, "<title>"+html.EscapeString(text)+"</title>")
+	return b
+}
+
 // Rect adds a rectangle element.
 func (b *SVGBuilder) Rect(x, y, width, height float64, fill string) *SVGBuilder {
 	b.elements = append(b.elements, fmt.Sprintf(
@@ -124,7 +253,7 @@ func (b *SVGBuilder) Rect(x, y, width, height float64, fill string) *SVGBuilder
 func (b *SVGBuilder) Text(x, y float64, text string, col color.RGBA, fontSize int) *SVGBuilder {
 	b.elements = append(b.elements, fmt.Sprintf(
 		`<text x="%.1f" y="%.1f" fill="rgb(%d,%d,%d)" font-size="%d" font-family="monospace">%s</text>`,
-		x, y, col.R, col.G, col.B, fontSize, text))
+		x, y, col.R, col.G, col.B, fontSize, html.EscapeString(text)))
 	return b
 }
 
@@ -254,6 +383,21 @@ func (b *SVGBuilder) FleetSpeedLine(startX, startY, dx, dy float64, years int, c
 	return b.Path(pathD.String(), stroke, 1, "", markerID, markerID)
 }
 
+// FleetTrail draws the past positions of a fleet, oldest first, as a line
+// fading out toward the oldest position with a dot at each turn.
+func (b *SVGBuilder) FleetTrail(points [][2]float64, col color.RGBA) *SVGBuilder {
+	for i := 1; i < len(points); i++ {
+		alpha := 0.1 + 0.5*float64(i)/float64(len(points)-1)
+		stroke := fmt.Sprintf("rgba(%d,%d,%d,%.2f)", col.R, col.G, col.B, alpha)
+		b.Line(points[i-1][0], points[i-1][1], points[i][0], points[i][1], stroke, 1)
+		if i < len(points)-1 {
+			b.Circle(points[i][0], points[i][1], 1.5, stroke, "", 0)
+		}
+	}
+	b.Circle(points[0][0], points[0][1], 1.5, fmt.Sprintf("rgba(%d,%d,%d,0.10)", col.R, col.G, col.B), "", 0)
+	return b
+}
+
 // WaypointPath draws a fleet's waypoint path with lines connecting each waypoint.
 func (b *SVGBuilder) WaypointPath(points [][2]float64, col color.RGBA, markerID string) *SVGBuilder {
 	if len(points) < 2 {
@@ -272,6 +416,12 @@ func (b *SVGBuilder) WaypointPath(points [][2]float64, col color.RGBA, markerID
 	return b.Path(pathD.String(), stroke, 1.5, "", markerID, markerID)
 }
 
+// ETALabel adds a small label next to a waypoint with the years a fleet
+// needs to reach it.
+func (b *SVGBuilder) ETALabel(x, y float64, years int, col color.RGBA) *SVGBuilder {
+	return b.Text(x+4, y+9, fmt.Sprintf("%dy", years), col, 8)
+}
+
 // Starbase adds a starbase indicator (white circle + yellow satellite).
 func (b *SVGBuilder) Starbase(cx, cy float64) *SVGBuilder {
 	b.CircleOutline(cx, cy, 6, "white", 1)
@@ -291,6 +441,21 @@ func (b *SVGBuilder) Planet(cx, cy, radius float64, col color.RGBA, hasStarbase
 	return b
 }
 
+// EstimatedPlanet adds a planet whose position is only estimated, as a
+// dashed ring instead of a disc.
+func (b *SVGBuilder) EstimatedPlanet(cx, cy, radius float64, col color.RGBA, hasStarbase bool, name string, showName bool) *SVGBuilder {
+	if hasStarbase {
+		b.Starbase(cx, cy)
+	}
+	b.elements = append(b.elements, fmt.Sprintf(
+		`<circle cx="%.1f" cy="%.1f" r="%.1f" fill="none" stroke="rgb(%d,%d,%d)" stroke-width="1" stroke-dasharray="1.5,1"/>`,
+		cx, cy, radius+0.5, col.R, col.G, col.B))
+	if showName && name != "" {
+		b.Text(cx+5, cy-5, name+"?", col, 10)
+	}
+	return b
+}
+
 // Wormhole adds a wormhole indicator.
 func (b *SVGBuilder) Wormhole(cx, cy float64) *SVGBuilder {
 	return b.CircleOutline(cx, cy, 5, "purple", 1.5)
@@ -305,6 +470,35 @@ func (b *SVGBuilder) ScannerCoverage(cx, cy, radius float64, col color.RGBA) *SV
 	return b
 }
 
+// PenetratingCoverage adds a penetrating scanner coverage circle, stronger
+// than ScannerCoverage and dashed.
+func (b *SVGBuilder) PenetratingCoverage(cx, cy, radius float64, col color.RGBA) *SVGBuilder {
+	b.elements = append(b.elements, fmt.Sprintf(
+		`<circle cx="%.1f" cy="%.1f" r="%.1f" fill="rgba(%d,%d,%d,0.15)" stroke="rgba(%d,%d,%d,0.6)" stroke-width="1" stroke-dasharray="4,3"/>`,
+		cx, cy, radius, col.R, col.G, col.B, col.R, col.G, col.B))
+	return b
+}
+
+// Hotspot adds a highlighted ring with an optional label.
+func (b *SVGBuilder) Hotspot(cx, cy, radius float64, col color.RGBA, label string) *SVGBuilder {
+	b.elements = append(b.elements, fmt.Sprintf(
+		`<circle cx="%.1f" cy="%.1f" r="%.1f" fill="rgba(%d,%d,%d,0.15)" stroke="rgb(%d,%d,%d)" stroke-width="1.5"/>`,
+		cx, cy, radius, col.R, col.G, col.B, col.R, col.G, col.B))
+	if label != "" {
+		b.Text(cx+radius+2, cy+3, label, col, 9)
+	}
+	return b
+}
+
+// CountBadge adds a small circle holding a count (for stacked fleets).
+func (b *SVGBuilder) CountBadge(cx, cy float64, count int, col color.RGBA) *SVGBuilder {
+	b.Circle(cx, cy, 5, "black", fmt.Sprintf("rgb(%d,%d,%d)", col.R, col.G, col.B), 1)
+	b.elements = append(b.elements, fmt.Sprintf(
+		`<text x="%.1f" y="%.1f" fill="rgb(%d,%d,%d)" font-size="7" font-family="monospace" text-anchor="middle">%d</text>`,
+		cx, cy+2.5, col.R, col.G, col.B, count))
+	return b
+}
+
 // LegendItem adds a legend entry.
 func (b *SVGBuilder) LegendItem(x, y float64, name string, col color.RGBA) *SVGBuilder {
 	b.Rect(x, y, 10, 10, fmt.Sprintf("rgb(%d,%d,%d)", col.R, col.G, col.B))
@@ -349,11 +543,21 @@ func (b *SVGBuilder) buildSVG() string {
 		svg.WriteString("</defs>\n")
 	}
 
+	// Stylesheets
+	for _, css := range b.styles {
+		svg.WriteString("<style>\n")
+		svg.WriteString(css)
+		svg.WriteString("\n</style>\n")
+	}
+
 	// Elements
 	for _, elem := range b.elements {
 		svg.WriteString(elem)
 		svg.WriteString("\n")
 	}
+	for range b.openGroups {
+		svg.WriteString("</g>\n")
+	}
 
 	svg.WriteString("</svg>")
 	return svg.String()
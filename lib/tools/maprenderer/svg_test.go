@@ -0,0 +1,108 @@
+package maprenderer
+
+import (
+	"encoding/xml"
+	"errors"
+	"image/color"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// svgIDs parses an SVG document and returns the ids of its elements in
+// document order.
+func svgIDs(t *testing.T, svg string) []string {
+	t.Helper()
+	var ids []string
+	d := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return ids
+		}
+		require.NoError(t, err)
+		if start, ok := tok.(xml.StartElement); ok {
+			for _, attr := range start.Attr {
+				if attr.Name.Local == "id" {
+					ids = append(ids, attr.Value)
+				}
+			}
+		}
+	}
+}
+
+func TestSVGBuilderGroups(t *testing.T) {
+	b := NewSVGBuilder(200, 100)
+	b.AddStyle(".fleet.player-1 { display: none }")
+	b.BeginLayer(LayerFleets).
+		BeginGroup(FleetElementID(1, 4), `fleet "R&D" <1>`).
+		Text(10, 20, "Scout & <Co>", color.RGBA{255, 255, 255, 255}, 10).
+		EndGroup().
+		EndGroup().
+		EndGroup() // Nothing left to close
+	b.BeginLayer(LayerLegend) // Left open
+
+	svg := b.String()
+	assert.Equal(t, []string{"layer-fleets", "fleet-1-4", "layer-legend"}, svgIDs(t, svg))
+	assert.Contains(t, svg, "<style>\n.fleet.player-1 { display: none }\n</style>")
+	assert.Contains(t, svg, `<g id="fleet-1-4" class="fleet &#34;R&amp;D&#34; &lt;1&gt;">`)
+	assert.Contains(t, svg, ">Scout &amp; &lt;Co&gt;</text>")
+	assert.Equal(t, strings.Count(svg, "<g "), strings.Count(svg, "</g>"))
+}
+
+func TestRenderSVGElementIDs(t *testing.T) {
+	r := New()
+	require.NoError(t, r.LoadFileWithXY("../../../testdata/scenario-map/minefields/game.m1"))
+	opts := DefaultOptions()
+	opts.ShowMines = true
+	opts.ShowFleetPaths = 2
+	svg := r.RenderSVG(opts)
+
+	// Ids are unique, layers shown come in drawing order
+	ids := svgIDs(t, svg)
+	seen := make(map[string]bool)
+	var layers []string
+	for _, id := range ids {
+		assert.False(t, seen[id], "duplicate id %s", id)
+		seen[id] = true
+		if name, ok := strings.CutPrefix(id, "layer-"); ok {
+			layers = append(layers, name)
+		}
+	}
+	assert.Equal(t, []string{
+		LayerMinefields, LayerWormholes, LayerFleetPaths,
+		LayerPlanets, LayerHotspots, LayerFleets, LayerLegend, LayerYear,
+	}, layers)
+
+	// Every planet, fleet and minefield can be looked up
+	for _, planet := range r.store.AllPlanets() {
+		assert.True(t, seen[PlanetElementID(planet.PlanetNumber)], planet.Name)
+	}
+	for _, fleet := range r.store.AllFleets() {
+		assert.True(t, seen[FleetElementID(fleet.Owner, fleet.FleetNumber)])
+	}
+	assert.True(t, seen[MinefieldElementID(0, 0)])
+	assert.True(t, seen[MinefieldElementID(0, 2)])
+
+	// Classes tell owners, starbases and movement
+	for _, g := range []string{
+		`<g id="planet-11" class="planet player-1 starbase">`, // Woody
+		`<g id="planet-21" class="planet player-0 starbase">`, // Gates
+		`<g id="minefield-0-2" class="minefield player-0">`,
+		`<g id="fleet-0-1" class="fleet player-0 role-freighter moving">`,
+		`<g id="fleet-0-0" class="fleet player-0 role-scout stationary">`,
+		`<g class="fleet-path player-0 fleet-0-1">`,
+	} {
+		assert.Contains(t, svg, g)
+	}
+	assert.Contains(t, svg, `class="planet unowned"`)
+
+	// Rasterizing still draws the grouped elements
+	img, err := r.RenderSVGToImage(opts)
+	require.NoError(t, err)
+	x, y := r.Projection(opts).ToScreenPixel(1150, 1176) // Woody
+	assert.NotEqual(t, color.RGBA{0, 0, 0, 255}, img.RGBAAt(x, y))
+}
@@ -0,0 +1,59 @@
+package maprenderer
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/neper-stars/houston/geom"
+	"github.com/neper-stars/houston/store"
+)
+
+// maxTrailStep is the longest step of a fleet trail per year, in light
+// years: a fleet at warp 10 moves 100 ly a year. A longer step is a
+// wormhole jump or a fleet number reused by another fleet, and ends the
+// trail.
+const maxTrailStep = 100
+
+// SetHistory sets earlier turns of the game, as loaded from older turn
+// files, from which fleet trails are drawn (see RenderOptions.ShowFleetTrails).
+// Turns from the rendered turn on are ignored.
+func (r *Renderer) SetHistory(turns []*store.GameStore) {
+	r.history = nil
+	for _, gs := range turns {
+		if gs.Turn < r.store.Turn {
+			r.history = append(r.history, gs)
+		}
+	}
+	// Latest first, the order trails are walked in
+	slices.SortStableFunc(r.history, func(a, b *store.GameStore) int {
+		return cmp.Compare(b.Turn, a.Turn)
+	})
+}
+
+// fleetTrail returns the positions of a fleet over at most the last turns
+// turns of the history, oldest first and ending at its current position.
+// Turns the fleet did not move are left out, and the trail stops at the
+// first turn the fleet is not seen in.
+func (r *Renderer) fleetTrail(fleet *store.FleetEntity, turns int) [][2]int {
+	trail := [][2]int{{fleet.X, fleet.Y}}
+	x, y, turn := fleet.X, fleet.Y, r.store.Turn
+	for _, gs := range r.history {
+		if int(r.store.Turn-gs.Turn) > turns {
+			break
+		}
+		past, ok := gs.Fleet(fleet.Owner, fleet.FleetNumber)
+		if !ok {
+			break
+		}
+		step := geom.Distance(x, y, past.X, past.Y)
+		if step > maxTrailStep*float64(turn-gs.Turn) {
+			break
+		}
+		if past.X != x || past.Y != y {
+			trail = append(trail, [2]int{past.X, past.Y})
+		}
+		x, y, turn = past.X, past.Y, gs.Turn
+	}
+	slices.Reverse(trail)
+	return trail
+}
@@ -0,0 +1,127 @@
+package maprenderer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+// historyDir holds the turns of a two player game, 2470 to 2482.
+const historyDir = "../../../testdata/scenario-map/history/"
+
+// loadHistory returns a renderer of a year of the history scenario.
+func loadHistory(t *testing.T, year int) *Renderer {
+	t.Helper()
+	r := New()
+	require.NoError(t, r.LoadFileWithXY(fmt.Sprintf("%sgame-%d.m1", historyDir, year)))
+	return r
+}
+
+// historyRenderer returns a renderer of a year of the history scenario
+// with the turns of the given years as history.
+func historyRenderer(t *testing.T, year int, history ...int) *Renderer {
+	t.Helper()
+	r := loadHistory(t, year)
+	var turns []*store.GameStore
+	for _, y := range history {
+		turns = append(turns, loadHistory(t, y).store)
+	}
+	r.SetHistory(turns)
+	return r
+}
+
+func TestSetHistory(t *testing.T) {
+	r := historyRenderer(t, 2475, 2472, 2470, 2476, 2474, 2475, 2471, 2473)
+
+	// Latest first, without the rendered turn and later ones
+	var years []int
+	for _, gs := range r.history {
+		years = append(years, int(gs.Turn)+2400)
+	}
+	assert.Equal(t, []int{2474, 2473, 2472, 2471, 2470}, years)
+
+	r.SetHistory(nil)
+	assert.Empty(t, r.history)
+}
+
+func TestFleetTrail(t *testing.T) {
+	r := historyRenderer(t, 2475, 2470, 2471, 2472, 2473, 2474)
+
+	tests := []struct {
+		name   string
+		number int
+		turns  int
+		want   [][2]int
+	}{
+		{
+			name:   "moving every turn",
+			number: 2, // Armed Probe #3
+			turns:  5,
+			want:   [][2]int{{1702, 1818}, {1735, 1854}, {1768, 1891}, {1801, 1928}, {1834, 1965}, {1867, 2002}},
+		},
+		{
+			name:   "limited to the last turns",
+			number: 2,
+			turns:  2,
+			want:   [][2]int{{1801, 1928}, {1834, 1965}, {1867, 2002}},
+		},
+		{
+			name:   "turning back",
+			number: 11, // Rubber Pump
+			turns:  5,
+			want:   [][2]int{{1264, 1269}, {1260, 1233}, {1255, 1197}, {1251, 1161}, {1249, 1149}, {1253, 1185}},
+		},
+		{
+			name:   "stationary turns left out",
+			number: 12, // Stealth Scout #13, still since 2473
+			turns:  5,
+			want:   [][2]int{{1965, 1968}, {1975, 2002}, {1986, 2036}, {1991, 2054}},
+		},
+		{
+			name:   "stationary",
+			number: 0, // Fleet 1
+			turns:  5,
+			want:   [][2]int{{1273, 1341}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trail := r.fleetTrail(fleetByNumber(t, r, 0, tt.number), tt.turns)
+			assert.Equal(t, tt.want, trail)
+		})
+	}
+}
+
+func TestFleetTrailStops(t *testing.T) {
+	r := historyRenderer(t, 2475, 2470, 2471, 2472, 2473, 2474)
+	fleet := fleetByNumber(t, r, 0, 2)
+
+	// A jump longer than warp 10 ends the trail: a wormhole or a reused
+	// fleet number
+	past, ok := r.history[2].Fleet(0, 2) // 2472
+	require.True(t, ok)
+	past.X += 2 * maxTrailStep
+	assert.Equal(t, [][2]int{{1801, 1928}, {1834, 1965}, {1867, 2002}}, r.fleetTrail(fleet, 5))
+
+	// So does a turn the fleet is not seen in
+	r = historyRenderer(t, 2475, 2474)
+	unseen := store.New()
+	unseen.Turn = 73 // 2473
+	r.history = append(r.history, unseen, loadHistory(t, 2472).store)
+	fleet = fleetByNumber(t, r, 0, 2)
+	assert.Equal(t, [][2]int{{1834, 1965}, {1867, 2002}}, r.fleetTrail(fleet, 5))
+}
+
+func TestRenderSVGFleetTrails(t *testing.T) {
+	r := historyRenderer(t, 2475, 2473, 2474)
+	svg := r.RenderSVG(NewOptions(WithSize(400, 300), WithFleetTrails(2)))
+	assert.Contains(t, svg, `class="`+fleetTrailClass(0, 2)+`"`)
+	assert.NotContains(t, svg, `class="`+fleetTrailClass(0, 0)+`"`, "stationary fleets leave no trail")
+
+	svg = r.RenderSVG(NewOptions(WithSize(400, 300)))
+	assert.NotContains(t, svg, "fleet-trail")
+}
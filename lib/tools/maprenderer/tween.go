@@ -0,0 +1,56 @@
+package maprenderer
+
+import (
+	"math"
+
+	"github.com/neper-stars/houston/store"
+)
+
+// tween places the fleets of a frame part of the way to their positions at
+// the next turn, for the frames the Animator draws between two turns.
+type tween struct {
+	next *store.GameStore
+	t    float64 // 0 at the frame's turn, 1 at the next
+}
+
+// inBetween returns a frame drawing the turn of r with its fleets moved a
+// fraction t of the way to their positions in the next frame.
+func (r *Renderer) inBetween(next *Renderer, t float64) *Renderer {
+	frame := *r
+	frame.tween = &tween{next: next.store, t: t}
+	return &frame
+}
+
+// fleetPosition returns where a fleet is drawn: its position, or between
+// its positions at this turn and the next one in a frame between two turns.
+// Fleets missing from the next turn, destroyed or merged, stay in place.
+func (r *Renderer) fleetPosition(fleet *store.FleetEntity) (x, y int) {
+	if r.tween == nil {
+		return fleet.X, fleet.Y
+	}
+	next, ok := r.tween.next.Fleet(fleet.Owner, fleet.FleetNumber)
+	if !ok {
+		return fleet.X, fleet.Y
+	}
+	lerp := func(a, b int) int {
+		return a + int(math.Round(float64(b-a)*r.tween.t))
+	}
+	return lerp(fleet.X, next.X), lerp(fleet.Y, next.Y)
+}
+
+// tweenFrames returns the frames of an animation with n frames between
+// each two turns, and whether each frame is a turn.
+func tweenFrames(turns []*Renderer, n int) (frames []*Renderer, isTurn []bool) {
+	for i, r := range turns {
+		frames = append(frames, r)
+		isTurn = append(isTurn, true)
+		if i == len(turns)-1 {
+			break
+		}
+		for k := 1; k <= n; k++ {
+			frames = append(frames, r.inBetween(turns[i+1], float64(k)/float64(n+1)))
+			isTurn = append(isTurn, false)
+		}
+	}
+	return frames, isTurn
+}
@@ -0,0 +1,77 @@
+package maprenderer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFleetPositionTween(t *testing.T) {
+	// In 2480, Fleet #5 of player 2 moved, Purgatory Pump is gone and
+	// Stalwart Defender #6 is new
+	r := loadHistory(t, 2479)
+	next := loadHistory(t, 2480)
+
+	moving := fleetByNumber(t, r, 1, 4)
+	gone := fleetByNumber(t, r, 0, 3)
+	tests := []struct {
+		t          float64
+		moving     [2]int
+		stationary [2]int
+	}{
+		{0, [2]int{1968, 2081}, [2]int{1301, 1346}},
+		{0.5, [2]int{1955, 2084}, [2]int{1301, 1346}},
+		{1, [2]int{1943, 2087}, [2]int{1301, 1346}},
+	}
+	for _, tt := range tests {
+		frame := r.inBetween(next, tt.t)
+		x, y := frame.fleetPosition(moving)
+		assert.Equal(t, tt.moving, [2]int{x, y}, "moving fleet at t=%v", tt.t)
+		x, y = frame.fleetPosition(gone)
+		assert.Equal(t, tt.stationary, [2]int{x, y}, "fleet gone at the next turn at t=%v", tt.t)
+	}
+
+	// The turn itself is untouched
+	x, y := r.fleetPosition(moving)
+	assert.Equal(t, [2]int{1968, 2081}, [2]int{x, y})
+	assert.Nil(t, r.tween)
+}
+
+func TestInBetweenFleetsAppearing(t *testing.T) {
+	r := loadHistory(t, 2479)
+	next := loadHistory(t, 2480)
+	opts := NewOptions(WithSize(400, 300))
+
+	// A fleet new at the next turn shows up with it, not in the frames before
+	frame := r.inBetween(next, 0.5)
+	assert.NotContains(t, frame.RenderSVG(opts), `id="`+FleetElementID(0, 5)+`"`)
+	assert.Contains(t, next.RenderSVG(opts), `id="`+FleetElementID(0, 5)+`"`)
+
+	// A fleet gone at the next turn is drawn until then
+	assert.Contains(t, frame.RenderSVG(opts), `id="`+FleetElementID(0, 3)+`"`)
+	assert.NotContains(t, next.RenderSVG(opts), `id="`+FleetElementID(0, 3)+`"`)
+}
+
+func TestTweenFrames(t *testing.T) {
+	turns := []*Renderer{loadHistory(t, 2479), loadHistory(t, 2480), loadHistory(t, 2481)}
+
+	frames, isTurn := tweenFrames(turns, 0)
+	assert.Equal(t, turns, frames)
+	assert.Equal(t, []bool{true, true, true}, isTurn)
+
+	frames, isTurn = tweenFrames(turns, 3)
+	require.Len(t, frames, 9)
+	assert.Equal(t, []bool{true, false, false, false, true, false, false, false, true}, isTurn)
+	assert.Same(t, turns[0], frames[0])
+	assert.Same(t, turns[1], frames[4])
+	assert.Same(t, turns[2], frames[8])
+	for i, want := range []float64{0.25, 0.5, 0.75} {
+		frame := frames[1+i]
+		require.NotNil(t, frame.tween)
+		assert.Equal(t, want, frame.tween.t)
+		assert.Same(t, turns[1].store, frame.tween.next)
+		assert.Same(t, turns[0].store, frame.store)
+	}
+	assert.Same(t, turns[2].store, frames[5].tween.next)
+}
@@ -0,0 +1,455 @@
+package maprenderer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"slices"
+)
+
+// VP8L (lossless WebP) alphabet sizes: green with the LZ77 length codes,
+// red, blue, alpha and LZ77 distance codes.
+const (
+	vp8lLiterals     = 256
+	vp8lLengthCodes  = 24
+	vp8lDistCodes    = 40
+	vp8lMaxCodeLen   = 15
+	vp8lMaxLenCodeLn = 7    // Longest code of the code length code
+	vp8lMinMatch     = 3    // Shorter runs are cheaper as literals
+	vp8lMaxMatch     = 4096 // Longest LZ77 length
+)
+
+// vp8lCodeLengthOrder is the order the code length code lengths are written in.
+var vp8lCodeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// SaveWebP saves all frames as an animated WebP.
+func (a *Animator) SaveWebP(filename string, delayMs int) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return a.WriteWebP(f, delayMs)
+}
+
+// WriteWebP writes all frames as an animated lossless WebP to an io.Writer.
+// Unlike a GIF, frames keep their full colors.
+func (a *Animator) WriteWebP(w io.Writer, delayMs int) error {
+	var width, height int
+	frames, delays, err := a.encodeFrames(delayMs, func(img *image.RGBA) ([]byte, error) {
+		width, height = img.Bounds().Dx(), img.Bounds().Dy()
+		return encodeVP8L(img)
+	})
+	if err != nil {
+		return err
+	}
+	if err := writeAnimatedWebP(w, width, height, frames, delays); err != nil {
+		return fmt.Errorf("failed to encode WebP: %w", err)
+	}
+	return nil
+}
+
+// writeAnimatedWebP assembles VP8L frames covering the whole canvas into
+// an animation looping forever.
+func writeAnimatedWebP(w io.Writer, width, height int, frames [][]byte, delaysMs []int) error {
+	if width > 1<<14 || height > 1<<14 {
+		return fmt.Errorf("%dx%d is larger than WebP allows", width, height)
+	}
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+
+	vp8x := make([]byte, 10)
+	vp8x[0] = 0x02 // Animation
+	putUint24(vp8x[4:], width-1)
+	putUint24(vp8x[7:], height-1)
+	writeRIFFChunk(&body, "VP8X", vp8x)
+
+	anim := make([]byte, 6) // Black background, loop forever
+	writeRIFFChunk(&body, "ANIM", anim)
+
+	for i, frame := range frames {
+		var anmf bytes.Buffer
+		header := make([]byte, 16)
+		// X and Y offsets stay 0
+		putUint24(header[6:], width-1)
+		putUint24(header[9:], height-1)
+		putUint24(header[12:], min(delaysMs[i], 1<<24-1))
+		header[15] = 0x02 // Do not blend: frames are opaque and replace the canvas
+		anmf.Write(header)
+		writeRIFFChunk(&anmf, "VP8L", frame)
+		writeRIFFChunk(&body, "ANMF", anmf.Bytes())
+	}
+
+	var riff bytes.Buffer
+	writeRIFFChunk(&riff, "RIFF", body.Bytes())
+	_, err := w.Write(riff.Bytes())
+	return err
+}
+
+func writeRIFFChunk(buf *bytes.Buffer, fourCC string, data []byte) {
+	buf.WriteString(fourCC)
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+	if len(data)%2 == 1 {
+		buf.WriteByte(0) // Chunks are padded to an even size
+	}
+}
+
+func putUint24(b []byte, v int) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
+
+// vp8lBits writes the least significant bit first stream of VP8L.
+type vp8lBits struct {
+	buf  []byte
+	acc  uint64
+	nacc uint
+}
+
+func (b *vp8lBits) write(v uint32, n uint) {
+	b.acc |= uint64(v) << b.nacc
+	b.nacc += n
+	for b.nacc >= 8 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc >>= 8
+		b.nacc -= 8
+	}
+}
+
+func (b *vp8lBits) bytes() []byte {
+	if b.nacc > 0 {
+		return append(b.buf, byte(b.acc))
+	}
+	return b.buf
+}
+
+// vp8lSymbol is a literal pixel or an LZ77 back-reference.
+type vp8lSymbol struct {
+	argb   [4]uint8 // Green, red, blue, alpha
+	length int      // Back-reference length, 0 for a literal
+	dist   int      // Back-reference distance code
+}
+
+// encodeVP8L encodes an image as a lossless VP8L bit stream, without
+// transforms or color cache: literals and back-references to the pixel on
+// the left or above, which is most of a map.
+func encodeVP8L(img *image.RGBA) ([]byte, error) {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width < 1 || height < 1 || width > 1<<14 || height > 1<<14 {
+		return nil, fmt.Errorf("invalid size %dx%d", width, height)
+	}
+
+	// Pixels as RGBA words in scan order. VP8L colors are not premultiplied
+	// by alpha, unlike those of image.RGBA.
+	pix := make([]uint32, 0, width*height)
+	opaque := true
+	for y := range height {
+		row := img.Pix[y*img.Stride : y*img.Stride+4*width]
+		for x := 0; x < len(row); x += 4 {
+			c := color.NRGBAModel.Convert(color.RGBA{row[x], row[x+1], row[x+2], row[x+3]}).(color.NRGBA)
+			pix = append(pix, uint32(c.R)|uint32(c.G)<<8|uint32(c.B)<<16|uint32(c.A)<<24)
+			opaque = opaque && c.A == 0xFF
+		}
+	}
+
+	symbols := vp8lSymbols(pix, width)
+
+	// Symbol frequencies per prefix code
+	freqs := [5][]int{
+		make([]int, vp8lLiterals+vp8lLengthCodes),
+		make([]int, vp8lLiterals),
+		make([]int, vp8lLiterals),
+		make([]int, vp8lLiterals),
+		make([]int, vp8lDistCodes),
+	}
+	for _, s := range symbols {
+		if s.length == 0 {
+			freqs[0][s.argb[0]]++
+			freqs[1][s.argb[1]]++
+			freqs[2][s.argb[2]]++
+			freqs[3][s.argb[3]]++
+			continue
+		}
+		code, _, _ := vp8lPrefix(s.length)
+		freqs[0][vp8lLiterals+code]++
+		code, _, _ = vp8lPrefix(s.dist)
+		freqs[4][code]++
+	}
+
+	var b vp8lBits
+	b.write(0x2F, 8) // Signature
+	b.write(uint32(width-1), 14)
+	b.write(uint32(height-1), 14)
+	if opaque {
+		b.write(0, 1)
+	} else {
+		b.write(1, 1)
+	}
+	b.write(0, 3) // Version
+	b.write(0, 1) // No transform
+	b.write(0, 1) // No color cache
+	b.write(0, 1) // No meta prefix codes
+
+	var codes [5]prefixCode
+	for i, f := range freqs {
+		codes[i] = newPrefixCode(f, vp8lMaxCodeLen)
+		codes[i].writeHeader(&b)
+	}
+
+	for _, s := range symbols {
+		if s.length == 0 {
+			for i, c := range s.argb {
+				codes[i].write(&b, int(c))
+			}
+			continue
+		}
+		code, n, extra := vp8lPrefix(s.length)
+		codes[0].write(&b, vp8lLiterals+code)
+		b.write(extra, n)
+		code, n, extra = vp8lPrefix(s.dist)
+		codes[4].write(&b, code)
+		b.write(extra, n)
+	}
+	return b.bytes(), nil
+}
+
+// vp8lSymbols turns pixels into literals and back-references copying runs
+// from the pixel on the left (distance code 2) or above (distance code 1).
+func vp8lSymbols(pix []uint32, width int) []vp8lSymbol {
+	var symbols []vp8lSymbol
+	matchLen := func(i, dist int) int {
+		n := 0
+		for i+n < len(pix) && n < vp8lMaxMatch && pix[i+n] == pix[i+n-dist] {
+			n++
+		}
+		return n
+	}
+	for i := 0; i < len(pix); {
+		length, dist := 0, 0
+		if i >= 1 {
+			length, dist = matchLen(i, 1), 2
+		}
+		if i >= width {
+			if n := matchLen(i, width); n > length {
+				length, dist = n, 1
+			}
+		}
+		if length >= vp8lMinMatch {
+			symbols = append(symbols, vp8lSymbol{length: length, dist: dist})
+			i += length
+			continue
+		}
+		p := pix[i]
+		symbols = append(symbols, vp8lSymbol{argb: [4]uint8{uint8(p >> 8), uint8(p), uint8(p >> 16), uint8(p >> 24)}})
+		i++
+	}
+	return symbols
+}
+
+// vp8lPrefix returns the prefix code and extra bits of an LZ77 length or
+// distance code.
+func vp8lPrefix(v int) (code int, nbits uint, extra uint32) {
+	d := v - 1
+	if d < 4 {
+		return d, 0, 0
+	}
+	h := 0
+	for d>>(h+1) != 0 {
+		h++
+	}
+	second := (d >> (h - 1)) & 1
+	nbits = uint(h - 1)
+	return 2*h + second, nbits, uint32(d & (1<<nbits - 1))
+}
+
+// prefixCode is a canonical Huffman code.
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32 // Bit-reversed, as written
+	single  int      // The only symbol, coded with no bit; -1 otherwise
+}
+
+// newPrefixCode builds a prefix code for symbol frequencies, with codes no
+// longer than maxLen bits.
+func newPrefixCode(freq []int, maxLen int) prefixCode {
+	c := prefixCode{lengths: make([]uint8, len(freq)), codes: make([]uint32, len(freq)), single: -1}
+	var used []int
+	for s, f := range freq {
+		if f > 0 {
+			used = append(used, s)
+		}
+	}
+	switch len(used) {
+	case 0:
+		c.single = 0
+		return c
+	case 1:
+		c.single = used[0]
+		return c
+	}
+
+	// Raising the rare frequencies flattens the tree until it fits
+	for floor := 1; huffmanLengths(freq, used, floor, c.lengths) > maxLen; floor *= 2 {
+	}
+
+	var count [vp8lMaxCodeLen + 1]uint32
+	for _, n := range c.lengths {
+		count[n]++
+	}
+	count[0] = 0
+	var next [vp8lMaxCodeLen + 1]uint32
+	code := uint32(0)
+	for n := 1; n <= vp8lMaxCodeLen; n++ {
+		code = (code + count[n-1]) << 1
+		next[n] = code
+	}
+	for s, n := range c.lengths {
+		if n == 0 {
+			continue
+		}
+		v := next[n]
+		next[n]++
+		var rev uint32
+		for range n {
+			rev = rev<<1 | v&1
+			v >>= 1
+		}
+		c.codes[s] = rev
+	}
+	return c
+}
+
+// huffmanLengths sets the code lengths of the used symbols, with
+// frequencies raised to at least floor, and returns the longest.
+func huffmanLengths(freq []int, used []int, floor int, lengths []uint8) int {
+	type node struct {
+		weight      int
+		left, right int // Children, -1 for a leaf
+		symbol      int
+	}
+	nodes := make([]node, 0, 2*len(used)-1)
+	for _, s := range used {
+		nodes = append(nodes, node{weight: max(freq[s], floor), left: -1, right: -1, symbol: s})
+	}
+	slices.SortStableFunc(nodes, func(a, b node) int { return a.weight - b.weight })
+
+	// Two queues: the sorted leaves, and the merged nodes in the order they
+	// are made, which is by weight
+	leaf, merged := 0, len(nodes)
+	pop := func() int {
+		if leaf < len(used) && (merged >= len(nodes) || nodes[leaf].weight <= nodes[merged].weight) {
+			leaf++
+			return leaf - 1
+		}
+		merged++
+		return merged - 1
+	}
+	for range len(used) - 1 {
+		a, b := pop(), pop()
+		nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, left: a, right: b})
+	}
+
+	longest := 0
+	var walk func(i, depth int)
+	walk = func(i, depth int) {
+		if n := nodes[i]; n.left >= 0 {
+			walk(n.left, depth+1)
+			walk(n.right, depth+1)
+			return
+		}
+		lengths[nodes[i].symbol] = uint8(depth)
+		longest = max(longest, depth)
+	}
+	walk(len(nodes)-1, 0)
+	return longest
+}
+
+func (c *prefixCode) write(b *vp8lBits, symbol int) {
+	if c.single < 0 {
+		b.write(c.codes[symbol], uint(c.lengths[symbol]))
+	}
+}
+
+// writeHeader writes the code lengths of the code.
+func (c *prefixCode) writeHeader(b *vp8lBits) {
+	if c.single >= 0 && c.single < vp8lLiterals {
+		b.write(1, 1) // Simple code
+		b.write(0, 1) // One symbol
+		if c.single < 2 {
+			b.write(0, 1)
+			b.write(uint32(c.single), 1)
+		} else {
+			b.write(1, 1)
+			b.write(uint32(c.single), 8)
+		}
+		return
+	}
+	lengths := c.lengths
+	if c.single >= 0 {
+		// A lone symbol beyond the simple code range has one length
+		lengths = make([]uint8, len(c.lengths))
+		lengths[c.single] = 1
+	}
+
+	// Code lengths, with runs of zeros as codes 17 and 18
+	type token struct {
+		code  int
+		extra uint32
+		nbits uint
+	}
+	var tokens []token
+	for i := 0; i < len(lengths); {
+		if lengths[i] != 0 {
+			tokens = append(tokens, token{code: int(lengths[i])})
+			i++
+			continue
+		}
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == 0 && run < 138 {
+			run++
+		}
+		switch {
+		case run >= 11:
+			tokens = append(tokens, token{code: 18, extra: uint32(run - 11), nbits: 7})
+		case run >= 3:
+			tokens = append(tokens, token{code: 17, extra: uint32(run - 3), nbits: 3})
+		default:
+			for range run {
+				tokens = append(tokens, token{code: 0})
+			}
+		}
+		i += run
+	}
+
+	freq := make([]int, len(vp8lCodeLengthOrder))
+	for _, t := range tokens {
+		freq[t.code]++
+	}
+	lenCode := newPrefixCode(freq, vp8lMaxLenCodeLn)
+	lenLengths := lenCode.lengths
+	if lenCode.single >= 0 {
+		lenLengths = make([]uint8, len(freq))
+		lenLengths[lenCode.single] = 1
+	}
+	n := len(vp8lCodeLengthOrder)
+	for n > 4 && lenLengths[vp8lCodeLengthOrder[n-1]] == 0 {
+		n--
+	}
+
+	b.write(0, 1) // Normal code
+	b.write(uint32(n-4), 4)
+	for _, s := range vp8lCodeLengthOrder[:n] {
+		b.write(uint32(lenLengths[s]), 3)
+	}
+	b.write(0, 1) // Lengths for the whole alphabet
+	for _, t := range tokens {
+		lenCode.write(b, t.code)
+		b.write(t.extra, t.nbits)
+	}
+}
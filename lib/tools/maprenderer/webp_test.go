@@ -0,0 +1,153 @@
+package maprenderer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+// testFrames returns frames of the same size: a rendered map, noise with
+// runs for back-references, and a flat frame.
+func testFrames(t *testing.T) []*image.RGBA {
+	t.Helper()
+	r := New()
+	require.NoError(t, r.LoadFileWithXY(historyDir+"game-2470.m1"))
+	rendered := r.Render(NewOptions(WithSize(160, 120)))
+	bounds := rendered.Bounds()
+
+	noise := image.NewRGBA(bounds)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < len(noise.Pix); i += 4 {
+		if i%64 < 32 {
+			rng.Read(noise.Pix[i : i+3])
+		}
+		noise.Pix[i+3] = 0xFF
+	}
+
+	flat := image.NewRGBA(bounds)
+	for i := 0; i < len(flat.Pix); i += 4 {
+		copy(flat.Pix[i:], []byte{10, 20, 30, 0xFF})
+	}
+	return []*image.RGBA{rendered, noise, flat}
+}
+
+// riffChunk is a chunk of a RIFF file.
+type riffChunk struct {
+	fourCC string
+	data   []byte
+}
+
+func readRIFFChunks(t *testing.T, data []byte) []riffChunk {
+	t.Helper()
+	var chunks []riffChunk
+	for len(data) > 0 {
+		require.GreaterOrEqual(t, len(data), 8, "truncated chunk header")
+		n := int(binary.LittleEndian.Uint32(data[4:]))
+		require.GreaterOrEqual(t, len(data), 8+n, "truncated %s chunk", data[:4])
+		chunks = append(chunks, riffChunk{string(data[:4]), data[8 : 8+n]})
+		data = data[8+n+n%2:]
+	}
+	return chunks
+}
+
+// decodeVP8L decodes a VP8L bit stream wrapped as a still WebP file, as
+// x/image/webp does not decode animations.
+func decodeVP8L(t *testing.T, frame []byte) image.Image {
+	t.Helper()
+	var still bytes.Buffer
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	writeRIFFChunk(&body, "VP8L", frame)
+	writeRIFFChunk(&still, "RIFF", body.Bytes())
+	img, err := webp.Decode(&still)
+	require.NoError(t, err)
+	return img
+}
+
+func TestWriteAnimatedWebPRoundTrip(t *testing.T) {
+	frames := testFrames(t)
+	size := frames[0].Bounds().Size()
+	delays := []int{500, 125, 500}
+
+	encoded := make([][]byte, len(frames))
+	for i, img := range frames {
+		var err error
+		encoded[i], err = encodeVP8L(img)
+		require.NoError(t, err)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, writeAnimatedWebP(&buf, size.X, size.Y, encoded, delays))
+
+	riff := readRIFFChunks(t, buf.Bytes())
+	require.Len(t, riff, 1)
+	assert.Equal(t, "RIFF", riff[0].fourCC)
+	require.Equal(t, "WEBP", string(riff[0].data[:4]))
+
+	chunks := readRIFFChunks(t, riff[0].data[4:])
+	require.Len(t, chunks, 2+len(frames))
+	assert.Equal(t, "VP8X", chunks[0].fourCC)
+	assert.Equal(t, byte(0x02), chunks[0].data[0]&0x02, "animation flag")
+	assert.Equal(t, "ANIM", chunks[1].fourCC)
+
+	for i, anmf := range chunks[2:] {
+		require.Equal(t, "ANMF", anmf.fourCC)
+		header := anmf.data[:16]
+		width := int(header[6]) | int(header[7])<<8 | int(header[8])<<16
+		height := int(header[9]) | int(header[10])<<8 | int(header[11])<<16
+		duration := int(header[12]) | int(header[13])<<8 | int(header[14])<<16
+		assert.Equal(t, size, image.Pt(width+1, height+1), "frame %d", i)
+		assert.Equal(t, delays[i], duration, "frame %d", i)
+
+		inner := readRIFFChunks(t, anmf.data[16:])
+		require.Len(t, inner, 1)
+		require.Equal(t, "VP8L", inner[0].fourCC)
+		assertSamePixels(t, frames[i], decodeVP8L(t, inner[0].data))
+	}
+}
+
+func TestEncodeVP8LTranslucent(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 5, 3))
+	img.Set(0, 0, color.NRGBA{200, 100, 50, 128})
+	img.Set(1, 0, color.NRGBA{255, 255, 255, 1})
+	img.Set(2, 1, color.NRGBA{0, 255, 0, 255})
+	// The rest stays fully transparent
+
+	data, err := encodeVP8L(img)
+	require.NoError(t, err)
+	assertSamePixels(t, img, decodeVP8L(t, data))
+}
+
+func TestAnimatorWriteWebP(t *testing.T) {
+	a := NewAnimator()
+	a.SetOptions(NewOptions(WithSize(120, 90)))
+	a.SetInBetweenFrames(1)
+	for _, year := range []string{"2470", "2471"} {
+		require.NoError(t, a.AddFile(historyDir+"game-"+year+".m1"))
+	}
+	a.SortByYear()
+
+	var buf bytes.Buffer
+	require.NoError(t, a.WriteWebP(&buf, 400))
+
+	riff := readRIFFChunks(t, buf.Bytes())
+	require.Len(t, riff, 1)
+	chunks := readRIFFChunks(t, riff[0].data[4:])
+	var durations []int
+	for _, c := range chunks {
+		if c.fourCC == "ANMF" {
+			durations = append(durations, int(c.data[12])|int(c.data[13])<<8|int(c.data[14])<<16)
+			inner := readRIFFChunks(t, c.data[16:])
+			img := decodeVP8L(t, inner[0].data)
+			assert.Equal(t, image.Pt(120, 90), img.Bounds().Size())
+		}
+	}
+	// Two turns and a frame between them
+	assert.Equal(t, []int{400, 400, 400}, durations)
+}
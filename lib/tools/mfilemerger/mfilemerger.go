@@ -30,6 +30,7 @@ import (
 
 	"github.com/neper-stars/houston/blocks"
 	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/store"
 )
 
 // FileEntry represents a single M file's data.
@@ -204,6 +205,7 @@ func (m *Merger) Merge() (*MergeResult, error) {
 		EntriesProcessed: len(m.entries),
 		PlanetsMerged:    len(m.planets),
 		ObjectsMerged:    len(m.objects),
+		Warnings:         m.storeWarnings(),
 	}
 
 	// Count fleets and designs
@@ -224,6 +226,22 @@ func (m *Merger) Merge() (*MergeResult, error) {
 	return result, nil
 }
 
+// storeWarnings reports what the files disagree on, such as a planet
+// owned by different players or a design slot holding different designs.
+func (m *Merger) storeWarnings() []string {
+	gs := store.New()
+	var warnings []string
+	for _, name := range m.names {
+		if err := gs.AddFile(name, m.entries[name].OriginalData); err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	for _, w := range gs.Warnings() {
+		warnings = append(warnings, w.String())
+	}
+	return warnings
+}
+
 // GetMergedData returns the merged data for a specific entry.
 // Note: Currently returns original data as block encoding is not yet implemented.
 // Full implementation would rebuild the file with merged blocks.
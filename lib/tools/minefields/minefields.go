@@ -0,0 +1,175 @@
+// Package minefields estimates what remote detonation of minefields would do.
+//
+// Space Demolition races can order their standard minefields to detonate:
+// every fleet inside the field, whoever owns it, takes damage as if it had
+// hit a mine. This package lists the fleets caught in each of a player's
+// standard minefields with the damage they would take, and builds the
+// detonation orders. PlanRoutes suggests where minelayers should lay new
+// fields: across the approach lanes of enemy fleets, out of sight of enemy
+// scanners.
+//
+// Example usage:
+//
+//	for _, d := range minefields.Detonations(gs, playerNumber) {
+//	    fmt.Printf("minefield #%d: %d damage to enemies, %d to own fleets\n",
+//	        d.Minefield.Number, d.EnemyDamage, d.OwnDamage)
+//	    if d.EnemyDamage > d.OwnDamage {
+//	        orders = append(orders, d.Minefield.DetonateOrder(true))
+//	    }
+//	}
+package minefields
+
+import (
+	"sort"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/geom"
+	"github.com/neper-stars/houston/store"
+)
+
+// MineDamage is the damage a minefield type deals to a fleet striking it,
+// as listed in the Stars! help. Ram scoop engines take more damage.
+type MineDamage struct {
+	PerEngine        int // Damage per engine
+	PerRamScoop      int // Damage per ram scoop engine
+	Minimum          int // Minimum damage per fleet
+	MinimumRamScoops int // Minimum damage per fleet with ram scoop engines
+}
+
+// MineDamages lists the damage of each minefield type (blocks.MinefieldType*).
+// Speed bump fields deal no damage.
+var MineDamages = map[int]MineDamage{
+	blocks.MinefieldTypeStandard:  {PerEngine: 100, PerRamScoop: 125, Minimum: 500, MinimumRamScoops: 600},
+	blocks.MinefieldTypeHeavy:     {PerEngine: 500, PerRamScoop: 600, Minimum: 2000, MinimumRamScoops: 2500},
+	blocks.MinefieldTypeSpeedBump: {},
+}
+
+// Exposure is a fleet caught in a minefield.
+type Exposure struct {
+	Fleet    *store.FleetEntity
+	Distance float64 // Distance from the field center (ly)
+	Damage   int     // Expected damage if the field detonates
+	Armor    int     // Total armor of the fleet (0 if its designs are unknown)
+	Own      bool    // True if the fleet belongs to the minefield owner
+}
+
+// Destroyed reports whether the expected damage exceeds the fleet armor.
+// Shields and the spread of damage across ships are not taken into account,
+// and fleets of unknown designs are never reported destroyed.
+func (e Exposure) Destroyed() bool {
+	return e.Armor > 0 && e.Damage >= e.Armor
+}
+
+// Detonation is the expected outcome of detonating one minefield.
+type Detonation struct {
+	Minefield   *store.ObjectEntity
+	Fleets      []Exposure // Closest to the center first
+	OwnDamage   int        // Damage to the minefield owner's fleets
+	EnemyDamage int        // Damage to all other fleets
+}
+
+// Detonations lists the standard minefields of a player with the fleets
+// currently inside them, most enemy damage first. Minefields with no fleet
+// inside are included so that a report shows every detonable field.
+func Detonations(gs *store.GameStore, playerNumber int) []*Detonation {
+	var result []*Detonation
+	for _, mf := range gs.ObjectsByOwner(playerNumber) {
+		if !mf.IsMinefield() || mf.MinefieldType != blocks.MinefieldTypeStandard {
+			continue
+		}
+		result = append(result, detonate(gs, mf))
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].EnemyDamage != result[j].EnemyDamage {
+			return result[i].EnemyDamage > result[j].EnemyDamage
+		}
+		return result[i].Minefield.Number < result[j].Minefield.Number
+	})
+	return result
+}
+
+// CanDetonate reports whether a player's race can remotely detonate mines.
+func CanDetonate(gs *store.GameStore, playerNumber int) bool {
+	player, ok := gs.Player(playerNumber)
+	if !ok {
+		return false
+	}
+	prt := data.GetPRT(player.PRT)
+	return prt != nil && prt.CanRemoteDetonateMines
+}
+
+func detonate(gs *store.GameStore, mf *store.ObjectEntity) *Detonation {
+	d := &Detonation{Minefield: mf}
+	radius := mf.Radius()
+
+	for _, fleet := range gs.AllFleets() {
+		distance := geom.Distance(fleet.X, fleet.Y, mf.X, mf.Y)
+		if distance > radius {
+			continue
+		}
+		e := Exposure{
+			Fleet:    fleet,
+			Distance: distance,
+			Damage:   FleetDamage(gs, fleet, mf.MinefieldType),
+			Armor:    fleetArmor(gs, fleet),
+			Own:      fleet.Owner == mf.Owner,
+		}
+		d.Fleets = append(d.Fleets, e)
+		if e.Own {
+			d.OwnDamage += e.Damage
+		} else {
+			d.EnemyDamage += e.Damage
+		}
+	}
+
+	sort.SliceStable(d.Fleets, func(i, j int) bool {
+		return d.Fleets[i].Distance < d.Fleets[j].Distance
+	})
+	return d
+}
+
+// FleetDamage returns the damage a fleet takes from one mine hit of the
+// given minefield type: per-engine damage for every engine in the fleet,
+// raised to the fleet minimum. The ram scoop minimum applies as soon as one
+// ship of the fleet uses ram scoops.
+func FleetDamage(gs *store.GameStore, fleet *store.FleetEntity, minefieldType int) int {
+	dmg := MineDamages[minefieldType]
+	if dmg.Minimum == 0 {
+		return 0
+	}
+
+	damage, ramScoops := 0, false
+	for _, info := range fleet.GetDesigns(gs) {
+		engine := info.Design.GetEngine()
+		if engine == nil || info.Design.IsStarbase {
+			continue
+		}
+		engines := 0
+		for _, item := range info.Design.ItemsByCategory(blocks.ItemCategoryEngine) {
+			engines += item.Count
+		}
+		perEngine := dmg.PerEngine
+		// Ram scoops are the engines burning no fuel at low warp
+		if engine.FreeSpeed > 0 {
+			perEngine = dmg.PerRamScoop
+			ramScoops = true
+		}
+		damage += perEngine * engines * info.Count
+	}
+
+	minimum := dmg.Minimum
+	if ramScoops {
+		minimum = dmg.MinimumRamScoops
+	}
+	return max(damage, minimum)
+}
+
+func fleetArmor(gs *store.GameStore, fleet *store.FleetEntity) int {
+	armor := 0
+	for _, info := range fleet.GetDesigns(gs) {
+		armor += info.Design.GetTotalArmorValue() * info.Count
+	}
+	return armor
+}
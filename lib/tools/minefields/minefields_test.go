@@ -0,0 +1,77 @@
+package minefields
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+func loadStore(t *testing.T, path string) *store.GameStore {
+	t.Helper()
+	gs := store.New()
+	require.NoError(t, gs.AddFileWithXY(path))
+	return gs
+}
+
+func TestDetonations(t *testing.T) {
+	gs := loadStore(t, "../../../testdata/scenario-map/minefields/game.m1")
+
+	detonations := Detonations(gs, 0)
+	require.NotEmpty(t, detonations)
+
+	for _, d := range detonations {
+		assert.Equal(t, blocks.MinefieldTypeStandard, d.Minefield.MinefieldType, "only standard fields detonate")
+		own, enemy := 0, 0
+		for _, e := range d.Fleets {
+			assert.LessOrEqual(t, e.Distance, d.Minefield.Radius())
+			assert.GreaterOrEqual(t, e.Damage, MineDamages[blocks.MinefieldTypeStandard].Minimum)
+			if e.Own {
+				own += e.Damage
+			} else {
+				enemy += e.Damage
+			}
+		}
+		assert.Equal(t, own, d.OwnDamage)
+		assert.Equal(t, enemy, d.EnemyDamage)
+	}
+
+	// Field #0 holds three own fleets and one enemy fleet
+	first := detonations[0]
+	assert.Equal(t, 0, first.Minefield.Number)
+	assert.Len(t, first.Fleets, 4)
+	assert.Equal(t, 1500, first.OwnDamage)
+	assert.Equal(t, 500, first.EnemyDamage)
+
+	// No field is owned by a player without minefields
+	assert.Empty(t, Detonations(gs, 1))
+}
+
+func TestCanDetonate(t *testing.T) {
+	gs := loadStore(t, "../../../testdata/scenario-minefield/game.m1")
+	assert.True(t, CanDetonate(gs, 0), "MineMongers are SD")
+	assert.False(t, CanDetonate(gs, 15), "unknown player")
+}
+
+func TestDetonateOrder(t *testing.T) {
+	gs := loadStore(t, "../../../testdata/scenario-minefield/game.m1")
+
+	mf, ok := gs.Object(0, 0)
+	require.True(t, ok)
+	order := mf.DetonateOrder(true)
+	require.NotNil(t, order)
+
+	decoded := blocks.NewObjectBlock(order.GenericBlock)
+	assert.True(t, decoded.Detonating)
+	assert.Equal(t, mf.Number, decoded.Number)
+	assert.Equal(t, mf.MineCount, decoded.MineCount)
+}
+
+func TestExposureDestroyed(t *testing.T) {
+	assert.True(t, Exposure{Damage: 500, Armor: 60}.Destroyed())
+	assert.False(t, Exposure{Damage: 500, Armor: 2000}.Destroyed())
+	assert.False(t, Exposure{Damage: 500}.Destroyed(), "unknown designs")
+}
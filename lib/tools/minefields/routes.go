@@ -0,0 +1,370 @@
+package minefields
+
+import (
+	"math"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/geom"
+	"github.com/neper-stars/houston/lib/tools/threat"
+	"github.com/neper-stars/houston/store"
+	"github.com/neper-stars/houston/visibility"
+)
+
+// RouteOptions controls minelaying route planning.
+type RouteOptions struct {
+	Stops   int     // Maximum stops per route (default: 3)
+	Spacing float64 // Distance in ly between candidate stops along a lane (default: 10)
+	Margin  float64 // Distance in ly beyond an enemy scanner range still deemed risky (default: 50)
+	Warp    int     // Warp the minelayers travel at (default: 6)
+
+	// Predict controls how enemy approach lanes are projected
+	// (default: threat.DefaultPredictOptions).
+	Predict *threat.PredictOptions
+}
+
+// DefaultRouteOptions returns default route planning options.
+func DefaultRouteOptions() *RouteOptions {
+	return &RouteOptions{
+		Stops:   3,
+		Spacing: 10,
+		Margin:  50,
+		Warp:    6,
+		Predict: threat.DefaultPredictOptions(),
+	}
+}
+
+// Lane is the projected approach of an enemy fleet toward a planet of the
+// player.
+type Lane struct {
+	Fleet  *store.FleetEntity
+	Planet *store.PlanetEntity
+	Speed  float64 // Observed speed of the fleet in ly per year
+}
+
+// Length returns the length of the lane in ly.
+func (l Lane) Length() float64 {
+	return geom.Distance(l.Fleet.X, l.Fleet.Y, l.Planet.X, l.Planet.Y)
+}
+
+// Stop is a point where a minelayer stays for a year laying its field.
+type Stop struct {
+	X, Y      int
+	Arrival   int     // Years until the minelayer reaches the stop
+	Coverage  float64 // Length in ly of approach lanes newly covered
+	Lanes     int     // Number of lanes the field covers
+	InTime    bool    // The field is laid before an enemy fleet passes
+	Detection float64 // Risk that enemy scanners see the minelayer (0-1)
+}
+
+// Route is a minelaying patrol suggested for a fleet.
+type Route struct {
+	Fleet  *store.FleetEntity
+	Type   int     // Type of the minefields laid (blocks.MinefieldType*)
+	Rate   int     // Mines laid per year
+	Radius float64 // Radius of a field laid for one year (ly)
+	Cloak  float64 // Cloaking of the fleet (0-1)
+	Stops  []Stop
+}
+
+// Coverage returns the length in ly of approach lanes covered by the route.
+func (r *Route) Coverage() float64 {
+	total := 0.0
+	for _, s := range r.Stops {
+		total += s.Coverage
+	}
+	return total
+}
+
+// Detection returns the risk that the minelayer is seen at any stop.
+func (r *Route) Detection() float64 {
+	unseen := 1.0
+	for _, s := range r.Stops {
+		unseen *= 1 - s.Detection
+	}
+	return 1 - unseen
+}
+
+// ApproachLanes returns the lanes of enemy fleets heading for planets of a
+// player: one per threatened destination, from the fleet to the planet.
+// Turns must be ordered oldest first, as for threat.PredictArrivals.
+func ApproachLanes(turns []*store.GameStore, playerNumber int, opts *threat.PredictOptions) []Lane {
+	var lanes []Lane
+	for _, p := range threat.PredictArrivals(turns, playerNumber, opts) {
+		for _, d := range p.Destinations {
+			if d.Threatened {
+				lanes = append(lanes, Lane{Fleet: p.Fleet, Planet: d.Planet, Speed: p.Speed})
+			}
+		}
+	}
+	return lanes
+}
+
+// PlanRoutes suggests a minelaying route for each minelayer fleet of a
+// player in the latest turn, so that its fields cover the approach lanes of
+// enemy fleets while the fleet keeps out of sight.
+//
+// Each route is built greedily from the fleet position: the next stop is
+// the point along a lane where a field laid for a year covers the most lane
+// length not already inside a field of the player for each year spent
+// getting there and laying it, scaled by the chance of staying unseen. Lanes are the tracks of the enemy fleets seen, which later
+// fleets are likely to follow, so a field laid after a fleet has passed
+// still counts; Stop.InTime tells whether it catches the fleet seen. A stop is deemed seen inside the range of an enemy scanner known
+// to the player, shortened by the cloaking of the minelayer, and the risk
+// fades out over Margin ly beyond it. Routes are planned one after another,
+// each avoiding the lanes covered by the previous ones; fleets with nothing
+// worth covering get no route.
+//
+// Space Demolition races benefit most, as they can detonate the fields when
+// enemy fleets are inside (see Detonations).
+func PlanRoutes(turns []*store.GameStore, playerNumber int, opts *RouteOptions) []*Route {
+	if len(turns) == 0 {
+		return nil
+	}
+	if opts == nil {
+		opts = DefaultRouteOptions()
+	}
+	gs := turns[len(turns)-1]
+
+	p := &planner{
+		opts:     opts,
+		lanes:    lanePoints(ApproachLanes(turns, playerNumber, opts.Predict)),
+		scanners: enemyScanners(gs, playerNumber),
+	}
+	for _, mf := range gs.ObjectsByOwner(playerNumber) {
+		if mf.IsMinefield() {
+			p.cover(float64(mf.X), float64(mf.Y), mf.Radius())
+		}
+	}
+
+	var routes []*Route
+	for _, fleet := range gs.FleetsByOwner(playerNumber) {
+		route := minelayer(gs, fleet)
+		if route == nil {
+			continue
+		}
+		if p.plan(route); len(route.Stops) > 0 {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// minelayer returns an empty route for a fleet able to lay mines, laying
+// standard mines if it can, heavy mines otherwise, then speed bumps.
+func minelayer(gs *store.GameStore, fleet *store.FleetEntity) *Route {
+	var normal, heavy, speed int
+	for _, info := range fleet.GetDesigns(gs) {
+		n, h, s := info.Design.GetMinelayingRate()
+		normal += n * info.Count
+		heavy += h * info.Count
+		speed += s * info.Count
+	}
+	r := &Route{Fleet: fleet, Cloak: visibility.FleetCloaking(fleet, gs)}
+	switch {
+	case normal > 0:
+		r.Type, r.Rate = blocks.MinefieldTypeStandard, normal
+	case heavy > 0:
+		r.Type, r.Rate = blocks.MinefieldTypeHeavy, heavy
+	case speed > 0:
+		r.Type, r.Rate = blocks.MinefieldTypeSpeedBump, speed
+	default:
+		return nil
+	}
+	r.Radius = math.Sqrt(float64(r.Rate))
+	return r
+}
+
+// lanePoint is a point of an approach lane, up to one ly from the next.
+type lanePoint struct {
+	x, y    float64
+	step    float64 // Length of lane the point stands for (ly)
+	years   float64 // Years until the enemy fleet passes
+	covered bool
+}
+
+func lanePoints(lanes []Lane) [][]*lanePoint {
+	points := make([][]*lanePoint, len(lanes))
+	for i, l := range lanes {
+		length := l.Length()
+		n := int(math.Ceil(length))
+		for j := 0; j <= n; j++ {
+			t := 1.0
+			if n > 0 {
+				t = float64(j) / float64(n)
+			}
+			pt := &lanePoint{
+				x:     float64(l.Fleet.X) + t*float64(l.Planet.X-l.Fleet.X),
+				y:     float64(l.Fleet.Y) + t*float64(l.Planet.Y-l.Fleet.Y),
+				years: math.Inf(1),
+			}
+			if n > 0 {
+				pt.step = length / float64(n)
+			}
+			if l.Speed > 0 {
+				pt.years = t * length / l.Speed
+			}
+			points[i] = append(points[i], pt)
+		}
+	}
+	return points
+}
+
+func (pt *lanePoint) point() point {
+	return point{int(math.Round(pt.x)), int(math.Round(pt.y))}
+}
+
+// scanner is an enemy scanner known to the player.
+type scanner struct {
+	x, y                int
+	normal, penetrating int
+	tachyons            int
+}
+
+// enemyScanners returns the scanners of the planets and fleets of the
+// players that are not friends of a player.
+func enemyScanners(gs *store.GameStore, playerNumber int) []scanner {
+	player, hasPlayer := gs.Player(playerNumber)
+	enemy := func(owner int) bool {
+		if owner < 0 || owner == playerNumber {
+			return false
+		}
+		return !hasPlayer || data.PlayerRelation(player.GetRelationTo(owner)) != data.RelationFriend
+	}
+
+	var result []scanner
+	for _, planet := range gs.AllPlanets() {
+		if !planet.IsOwned() || !enemy(planet.Owner) {
+			continue
+		}
+		s := scanner{x: planet.X, y: planet.Y}
+		s.normal, s.penetrating = planet.GetScannerRanges(gs)
+		if planet.HasStarbase {
+			if starbase, ok := gs.StarbaseDesign(planet.Owner, planet.StarbaseDesign); ok {
+				s.tachyons = starbase.GetTachyonCount()
+			}
+		}
+		result = append(result, s)
+	}
+	for _, fleet := range gs.AllFleets() {
+		if !enemy(fleet.Owner) {
+			continue
+		}
+		s := scanner{x: fleet.X, y: fleet.Y, tachyons: fleet.GetTachyonCount(gs)}
+		s.normal, s.penetrating = fleet.GetScannerRanges(gs)
+		result = append(result, s)
+	}
+	return result
+}
+
+// detectionRisk returns the risk that a fleet with the given cloaking is
+// seen at a point: 1 within the range of a scanner, falling to 0 at margin
+// ly beyond it.
+func detectionRisk(scanners []scanner, x, y int, cloak, margin float64) float64 {
+	risk := 0.0
+	for _, s := range scanners {
+		c := visibility.EffectiveCloaking(cloak, s.tachyons)
+		reach := max(visibility.EffectiveScannerRange(s.normal, c), visibility.EffectiveScannerRange(s.penetrating, c))
+		if reach <= 0 {
+			continue
+		}
+		d := geom.Distance(s.x, s.y, x, y)
+		switch {
+		case d <= reach:
+			return 1
+		case margin > 0 && d < reach+margin:
+			risk = max(risk, 1-(d-reach)/margin)
+		}
+	}
+	return risk
+}
+
+// planner builds routes over the points of the approach lanes.
+type planner struct {
+	opts     *RouteOptions
+	lanes    [][]*lanePoint
+	scanners []scanner
+}
+
+// cover marks the lane points inside a field as covered.
+func (p *planner) cover(x, y, radius float64) {
+	for _, lane := range p.lanes {
+		for _, pt := range lane {
+			if math.Hypot(pt.x-x, pt.y-y) <= radius {
+				pt.covered = true
+			}
+		}
+	}
+}
+
+// plan adds stops to a route until no candidate is worth it.
+func (p *planner) plan(r *Route) {
+	x, y := r.Fleet.X, r.Fleet.Y
+	elapsed := 0
+	for len(r.Stops) < p.opts.Stops {
+		best, bestScore := Stop{}, 0.0
+		for _, c := range p.candidates() {
+			travel := geom.YearsAtWarp(geom.Distance(x, y, c.x, c.y), p.opts.Warp)
+			if travel < 0 {
+				continue // Not moving
+			}
+			s := p.evaluate(r, c.x, c.y, elapsed+travel)
+			// Lane covered per year spent travelling and laying
+			if score := s.Coverage * (1 - s.Detection) / float64(travel+1); score > bestScore {
+				best, bestScore = s, score
+			}
+		}
+		if bestScore == 0 {
+			return
+		}
+		r.Stops = append(r.Stops, best)
+		p.cover(float64(best.X), float64(best.Y), r.Radius)
+		x, y = best.X, best.Y
+		elapsed = best.Arrival + 1
+	}
+}
+
+type point struct{ x, y int }
+
+// candidates returns the points every Spacing ly along the lanes, and their
+// ends.
+func (p *planner) candidates() []point {
+	spacing := max(1, int(math.Round(p.opts.Spacing)))
+	var result []point
+	for _, lane := range p.lanes {
+		for j := 0; j < len(lane); j += spacing {
+			result = append(result, lane[j].point())
+		}
+		if n := len(lane); n > 0 && (n-1)%spacing != 0 {
+			result = append(result, lane[n-1].point())
+		}
+	}
+	return result
+}
+
+// evaluate returns the stop of a route at a point reached after arrival
+// years.
+func (p *planner) evaluate(r *Route, x, y, arrival int) Stop {
+	s := Stop{X: x, Y: y, Arrival: arrival}
+	for _, lane := range p.lanes {
+		crossed := false
+		for _, pt := range lane {
+			if math.Hypot(pt.x-float64(x), pt.y-float64(y)) > r.Radius {
+				continue
+			}
+			crossed = true
+			if !pt.covered {
+				s.Coverage += pt.step
+			}
+			// Mines are laid at the end of the year spent at the stop
+			if pt.years >= float64(arrival+1) {
+				s.InTime = true
+			}
+		}
+		if crossed {
+			s.Lanes++
+		}
+	}
+	s.Detection = detectionRisk(p.scanners, x, y, r.Cloak, p.opts.Margin)
+	return s
+}
@@ -0,0 +1,80 @@
+package minefields
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+func TestPlanRoutes(t *testing.T) {
+	gs := loadStore(t, "../../../testdata/scenario-minefield/game.m1")
+	turns := []*store.GameStore{gs}
+
+	// Fleet #2 of player 2 is heading for Applegate
+	lanes := ApproachLanes(turns, 0, nil)
+	require.Len(t, lanes, 1)
+	assert.Equal(t, "Applegate", lanes[0].Planet.Name)
+	assert.InDelta(t, 67.0, lanes[0].Length(), 0.1)
+
+	routes := PlanRoutes(turns, 0, nil)
+	require.Len(t, routes, 2)
+
+	// Little Hen lays 160 standard mines a year, the Speed Turtle 80 speed bumps
+	hen := routes[0]
+	assert.Equal(t, blocks.MinefieldTypeStandard, hen.Type)
+	assert.Equal(t, 160, hen.Rate)
+	assert.InDelta(t, 12.65, hen.Radius, 0.01)
+	require.Len(t, hen.Stops, 3)
+	assert.Equal(t, blocks.MinefieldTypeSpeedBump, routes[1].Type)
+
+	for _, r := range routes {
+		arrival := 0
+		for _, s := range r.Stops {
+			assert.Greater(t, s.Coverage, 0.0)
+			assert.Equal(t, 1, s.Lanes)
+			assert.GreaterOrEqual(t, s.Arrival, arrival, "stops are in travel order")
+			arrival = s.Arrival + 1
+		}
+		// No enemy scanner is known
+		assert.Zero(t, r.Detection())
+	}
+
+	// Together the routes cover at most the lane, less what is mined already
+	covered := 0.0
+	for _, r := range routes {
+		covered += r.Coverage()
+	}
+	assert.LessOrEqual(t, covered, lanes[0].Length()+2)
+
+	// Without enemy fleets on the move there is nothing to mine
+	assert.Empty(t, PlanRoutes(turns, 1, nil))
+}
+
+func TestDetectionRisk(t *testing.T) {
+	scanners := []scanner{{x: 0, y: 0, normal: 100}}
+
+	assert.Equal(t, 1.0, detectionRisk(scanners, 90, 0, 0, 50))
+	assert.InDelta(t, 0.5, detectionRisk(scanners, 125, 0, 0, 50), 1e-9)
+	assert.Zero(t, detectionRisk(scanners, 150, 0, 0, 50))
+
+	// Cloaked by 50%, the fleet is seen within 50 ly only
+	assert.Zero(t, detectionRisk(scanners, 110, 0, 0.5, 50))
+	assert.Equal(t, 1.0, detectionRisk(scanners, 50, 0, 0.5, 50))
+
+	// Tachyon detectors weaken the cloak
+	scanners[0].tachyons = 4
+	assert.Equal(t, 1.0, detectionRisk(scanners, 52, 0, 0.5, 50))
+
+	// Penetrating scanners see fleets in deep space too
+	assert.Equal(t, 1.0, detectionRisk([]scanner{{penetrating: 100}}, 90, 0, 0, 0))
+}
+
+func TestRouteDetection(t *testing.T) {
+	r := &Route{Stops: []Stop{{Coverage: 10, Detection: 0.5}, {Coverage: 5, Detection: 0.5}}}
+	assert.Equal(t, 15.0, r.Coverage())
+	assert.InDelta(t, 0.75, r.Detection(), 1e-9)
+}
@@ -0,0 +1,251 @@
+// Package montecarlo runs a simulation many times with different random
+// seeds and sums up the outcomes as distributions.
+//
+// A simulation is any function drawing its randomness from the Trial it is
+// given and returning named measurements: the winner of a battle (1 or 0),
+// the year a planet is colonized, the resources of an economy after 50
+// years. Trials run in parallel, each with its own generator derived from the
+// configured seed and the trial number, so a run gives the same results
+// whatever the number of workers.
+//
+//	report, err := montecarlo.Run(ctx, func(t *montecarlo.Trial) (montecarlo.Sample, error) {
+//		won := 0.0
+//		if t.Rand.IntN(100) < 60 {
+//			won = 1
+//		}
+//		return montecarlo.Sample{"won": won}, nil
+//	}, montecarlo.Config{Runs: 10000})
+//
+//	d := report.Metric("won")
+//	fmt.Printf("%.1f%% (%.1f-%.1f)\n", 100*d.Mean, 100*d.Low, 100*d.High)
+//
+// RunSweep runs the same simulation over a list of parameters, such as
+// battle plans or production strategies to compare.
+package montecarlo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/neper-stars/houston/rng"
+)
+
+// Sample holds the measurements of a trial by name. Yes/no outcomes are
+// measured as 1 or 0, so that their mean is their probability.
+type Sample map[string]float64
+
+// Trial is a run of a simulation.
+type Trial struct {
+	Run  int        // Trial number, from 0
+	Rand *rand.Rand // Random source of the trial
+}
+
+// StarsRNG returns a Stars! random number generator seeded from the trial's
+// source, for simulations replaying the game's own rolls.
+func (t *Trial) StarsRNG() *rng.Source {
+	return rng.New(1+t.Rand.IntN(rng.ModulusA-1), 1+t.Rand.IntN(rng.ModulusB-1))
+}
+
+// Simulation runs one trial.
+type Simulation func(t *Trial) (Sample, error)
+
+// Config sets how simulations are run.
+type Config struct {
+	Runs       int     // Number of trials (default 1000)
+	Seed       uint64  // Seed of the trials; the same seed gives the same results
+	Workers    int     // Trials run at once (default: number of CPUs)
+	Confidence float64 // Level of the confidence intervals of the means (default 0.95)
+}
+
+func (c Config) withDefaults() Config {
+	if c.Runs <= 0 {
+		c.Runs = 1000
+	}
+	if c.Workers <= 0 {
+		c.Workers = runtime.GOMAXPROCS(0)
+	}
+	if c.Confidence <= 0 || c.Confidence >= 1 {
+		c.Confidence = 0.95
+	}
+	return c
+}
+
+// Distribution sums up the values of a measurement over the trials.
+type Distribution struct {
+	Name      string
+	N         int // Trials reporting the measurement
+	Mean      float64
+	StdDev    float64
+	Min, Max  float64
+	Low, High float64   // Confidence interval of the mean
+	Values    []float64 // Sorted
+}
+
+// Percentile returns the value below which p percent (0-100) of the values
+// fall, interpolating between values.
+func (d *Distribution) Percentile(p float64) float64 {
+	if len(d.Values) == 0 {
+		return math.NaN()
+	}
+	pos := math.Max(0, math.Min(100, p)) / 100 * float64(len(d.Values)-1)
+	i := int(pos)
+	if i+1 >= len(d.Values) {
+		return d.Values[len(d.Values)-1]
+	}
+	frac := pos - float64(i)
+	return d.Values[i]*(1-frac) + d.Values[i+1]*frac
+}
+
+// Median returns the 50th percentile.
+func (d *Distribution) Median() float64 {
+	return d.Percentile(50)
+}
+
+// Report holds the distributions of the measurements of a run.
+type Report struct {
+	Runs       int
+	Confidence float64
+	Metrics    map[string]*Distribution
+}
+
+// Metric returns the distribution of a measurement, or nil if no trial
+// reported it.
+func (r *Report) Metric(name string) *Distribution {
+	return r.Metrics[name]
+}
+
+// Names returns the names of the measurements, sorted.
+func (r *Report) Names() []string {
+	names := make([]string, 0, len(r.Metrics))
+	for name := range r.Metrics {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Run runs the simulation config.Runs times. It stops at the first failing
+// trial, or when the context is done.
+func Run(ctx context.Context, sim Simulation, config Config) (*Report, error) {
+	config = config.withDefaults()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	samples := make([]Sample, config.Runs)
+	runs := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+	for range min(config.Workers, config.Runs) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for run := range runs {
+				t := &Trial{Run: run, Rand: rand.New(rand.NewPCG(config.Seed, uint64(run)))}
+				sample, err := sim(t)
+				if err != nil {
+					fail(fmt.Errorf("trial %d: %w", run, err))
+					return
+				}
+				samples[run] = sample
+			}
+		}()
+	}
+
+feed:
+	for run := range config.Runs {
+		select {
+		case runs <- run:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(runs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return summarize(samples, config), nil
+}
+
+// RunSweep runs the simulation for each parameter, each with the same seeds
+// so that the parameters are compared on the same luck.
+func RunSweep[P any](ctx context.Context, params []P, sim func(P, *Trial) (Sample, error), config Config) ([]*Report, error) {
+	reports := make([]*Report, len(params))
+	for i, p := range params {
+		report, err := Run(ctx, func(t *Trial) (Sample, error) { return sim(p, t) }, config)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %d: %w", i, err)
+		}
+		reports[i] = report
+	}
+	return reports, nil
+}
+
+// summarize builds the distributions of the samples, in trial order.
+func summarize(samples []Sample, config Config) *Report {
+	values := make(map[string][]float64)
+	for _, sample := range samples {
+		for name, v := range sample {
+			values[name] = append(values[name], v)
+		}
+	}
+
+	z := normalQuantile(1 - (1-config.Confidence)/2)
+	report := &Report{Runs: len(samples), Confidence: config.Confidence, Metrics: make(map[string]*Distribution)}
+	for name, vs := range values {
+		d := &Distribution{Name: name, N: len(vs)}
+		var sum float64
+		for _, v := range vs {
+			sum += v
+		}
+		d.Mean = sum / float64(d.N)
+		if d.N > 1 {
+			var squares float64
+			for _, v := range vs {
+				squares += (v - d.Mean) * (v - d.Mean)
+			}
+			d.StdDev = math.Sqrt(squares / float64(d.N-1))
+		}
+		margin := z * d.StdDev / math.Sqrt(float64(d.N))
+		d.Low, d.High = d.Mean-margin, d.Mean+margin
+
+		d.Values = slices.Clone(vs)
+		slices.Sort(d.Values)
+		d.Min, d.Max = d.Values[0], d.Values[d.N-1]
+		report.Metrics[name] = d
+	}
+	return report
+}
+
+// normalQuantile returns the quantile of the standard normal distribution,
+// by bisection of its cumulative distribution function.
+func normalQuantile(p float64) float64 {
+	lo, hi := -10.0, 10.0
+	for range 100 {
+		mid := (lo + hi) / 2
+		if 0.5*math.Erfc(-mid/math.Sqrt2) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
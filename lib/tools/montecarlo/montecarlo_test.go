@@ -0,0 +1,92 @@
+package montecarlo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// coin wins with the given probability (percent).
+func coin(percent int) Simulation {
+	return func(t *Trial) (Sample, error) {
+		won := 0.0
+		if t.Rand.IntN(100) < percent {
+			won = 1
+		}
+		return Sample{"won": won, "roll": float64(t.StarsRNG().Intn(6) + 1)}, nil
+	}
+}
+
+func TestRun(t *testing.T) {
+	report, err := Run(context.Background(), coin(60), Config{Runs: 20000, Seed: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 20000, report.Runs)
+	assert.Equal(t, []string{"roll", "won"}, report.Names())
+
+	won := report.Metric("won")
+	assert.Equal(t, 20000, won.N)
+	assert.InDelta(t, 0.6, won.Mean, 0.02)
+	assert.Less(t, won.Low, won.Mean)
+	assert.Greater(t, won.High, won.Mean)
+	assert.InDelta(t, 0.0068, won.High-won.Mean, 0.0005, "95%% interval of a proportion")
+
+	roll := report.Metric("roll")
+	assert.Equal(t, 1.0, roll.Min)
+	assert.Equal(t, 6.0, roll.Max)
+	assert.InDelta(t, 3.5, roll.Median(), 0.5)
+	assert.Nil(t, report.Metric("lost"))
+}
+
+func TestRun_Deterministic(t *testing.T) {
+	one, err := Run(context.Background(), coin(50), Config{Runs: 500, Seed: 7, Workers: 1})
+	require.NoError(t, err)
+	many, err := Run(context.Background(), coin(50), Config{Runs: 500, Seed: 7, Workers: 8})
+	require.NoError(t, err)
+	assert.Equal(t, one, many, "results do not depend on the workers")
+
+	other, err := Run(context.Background(), coin(50), Config{Runs: 500, Seed: 8})
+	require.NoError(t, err)
+	assert.NotEqual(t, one.Metric("won").Mean, other.Metric("won").Mean)
+}
+
+func TestRun_Error(t *testing.T) {
+	boom := errors.New("boom")
+	_, err := Run(context.Background(), func(t *Trial) (Sample, error) {
+		if t.Run == 42 {
+			return nil, boom
+		}
+		return Sample{}, nil
+	}, Config{Runs: 100})
+	assert.ErrorIs(t, err, boom)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Run(ctx, coin(50), Config{Runs: 100})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRunSweep(t *testing.T) {
+	reports, err := RunSweep(context.Background(), []int{20, 80}, func(percent int, t *Trial) (Sample, error) {
+		return coin(percent)(t)
+	}, Config{Runs: 2000})
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	assert.InDelta(t, 0.2, reports[0].Metric("won").Mean, 0.03)
+	assert.InDelta(t, 0.8, reports[1].Metric("won").Mean, 0.03)
+}
+
+func TestDistribution_Percentile(t *testing.T) {
+	d := &Distribution{Values: []float64{1, 2, 3, 4, 5}}
+	assert.Equal(t, 1.0, d.Percentile(0))
+	assert.Equal(t, 3.0, d.Median())
+	assert.Equal(t, 4.5, d.Percentile(87.5))
+	assert.Equal(t, 5.0, d.Percentile(100))
+}
+
+func TestNormalQuantile(t *testing.T) {
+	assert.InDelta(t, 1.96, normalQuantile(0.975), 0.001)
+	assert.InDelta(t, 0, normalQuantile(0.5), 1e-9)
+}
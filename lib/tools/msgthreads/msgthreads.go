@@ -0,0 +1,250 @@
+// Package msgthreads rebuilds the conversations between players from the
+// messages of several turns.
+//
+// Stars! shows the messages of one year at a time, and the files hold them
+// the same way: a player's M file has the messages received that year and
+// the X file those written in reply. A reply only records, in InReplyTo, the
+// position of the message it answers in the replier's message list of the
+// year it was written. That list may also number host messages ahead of the
+// player messages, and the files do not tell how many. When the positions a
+// player replied to in a year go past the player messages they received,
+// the highest one is taken to be the last player message and the others
+// are counted from it. A reply whose position still falls outside is
+// attached to the latest message from its recipient.
+//
+// Threads are seen from one player: the messages they received (M files),
+// wrote (X files, or other players' M files) and the messages to everyone.
+//
+// Example usage:
+//
+//	threads := msgthreads.FromStores(stores, 1)
+//	err := msgthreads.WriteMarkdown(os.Stdout, threads, 1, names)
+package msgthreads
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/report"
+	"github.com/neper-stars/houston/store"
+)
+
+// Everyone is the correspondent of the messages sent to all players.
+const Everyone = 0
+
+// Message is a player message and the replies to it.
+type Message struct {
+	Year      int    // Year the message was read in
+	From      int    // Player number (1-16) of the sender
+	To        int    // Player number (1-16) of the recipient, Everyone for all
+	InReplyTo int    // Position of the message replied to (see the package documentation), 0 if none
+	Text      string // Message text
+
+	Parent  *Message   // Message replied to, nil if not found or not a reply
+	Replies []*Message // Replies, oldest first
+}
+
+// Thread is the conversation with one correspondent: a player number, or
+// Everyone for the messages sent to all players.
+type Thread struct {
+	Correspondent int
+	Messages      []*Message // Messages starting a conversation, oldest first
+}
+
+// Count returns the number of messages of the thread, replies included.
+func (t *Thread) Count() int {
+	n := 0
+	var count func([]*Message)
+	count = func(messages []*Message) {
+		for _, m := range messages {
+			n++
+			count(m.Replies)
+		}
+	}
+	count(t.Messages)
+	return n
+}
+
+// FromStores returns the conversations of a player (1-16) in the messages
+// of the stores, one per turn. Messages seen in several files, such as a
+// message to everyone, are kept once. Threads are sorted by correspondent,
+// Everyone first.
+func FromStores(stores []*store.GameStore, player int) []Thread {
+	messages := collect(stores, player)
+	for _, m := range messages {
+		if m.InReplyTo > 0 {
+			m.Parent = findParent(messages, m, hostMessages(messages, m))
+		}
+	}
+
+	byCorrespondent := make(map[int]*Thread)
+	var threads []*Thread
+	for _, m := range messages {
+		if m.Parent != nil {
+			m.Parent.Replies = append(m.Parent.Replies, m)
+			continue
+		}
+		c := correspondent(m, player)
+		t, ok := byCorrespondent[c]
+		if !ok {
+			t = &Thread{Correspondent: c}
+			byCorrespondent[c] = t
+			threads = append(threads, t)
+		}
+		t.Messages = append(t.Messages, m)
+	}
+
+	result := make([]Thread, 0, len(threads))
+	for _, t := range threads {
+		result = append(result, *t)
+	}
+	slices.SortFunc(result, func(a, b Thread) int {
+		return cmp.Compare(a.Correspondent, b.Correspondent)
+	})
+	return result
+}
+
+// collect returns the messages a player sent, received or could read, by
+// year then file order.
+func collect(stores []*store.GameStore, player int) []*Message {
+	type key struct {
+		year, from, to int
+		text           string
+	}
+	seen := make(map[key]bool)
+	var messages []*Message
+	for _, gs := range stores {
+		for _, entity := range gs.AllMessages() {
+			m := &Message{
+				Year:      readYear(entity),
+				From:      entity.SenderId + 1,
+				To:        entity.ReceiverId,
+				InReplyTo: entity.InReplyTo,
+				Text:      entity.Message,
+			}
+			if m.From != player && m.To != player && m.To != Everyone {
+				continue
+			}
+			k := key{m.Year, m.From, m.To, m.Text}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			messages = append(messages, m)
+		}
+	}
+	slices.SortStableFunc(messages, func(a, b *Message) int {
+		return cmp.Compare(a.Year, b.Year)
+	})
+	return messages
+}
+
+// readYear returns the year a message is read in: the year of the M file
+// holding it, or the year after the X file it was written in.
+func readYear(m *store.MessageEntity) int {
+	year := int(m.Meta().Turn) + blocks.StarsBaseYear
+	if source := m.Meta().BestSource; source != nil && source.Type == store.SourceTypeXFile {
+		year++
+	}
+	return year
+}
+
+// readBy returns the player messages a player read in a year.
+func readBy(messages []*Message, player, year int) []*Message {
+	var read []*Message
+	for _, m := range messages {
+		if m.Year == year && m.From != player && (m.To == player || m.To == Everyone) {
+			read = append(read, m)
+		}
+	}
+	return read
+}
+
+// hostMessages estimates the number of host messages listed before the
+// player messages the sender of a reply read, from the highest position
+// they replied to that year.
+func hostMessages(messages []*Message, reply *Message) int {
+	highest := 0
+	for _, m := range messages {
+		if m.Year == reply.Year && m.From == reply.From {
+			highest = max(highest, m.InReplyTo)
+		}
+	}
+	return max(0, highest-len(readBy(messages, reply.From, reply.Year-1)))
+}
+
+// findParent returns the message a reply answers among those its sender
+// read the year before, or nil if none fits.
+func findParent(messages []*Message, reply *Message, hostMessages int) *Message {
+	read := readBy(messages, reply.From, reply.Year-1)
+	var fromRecipient []*Message
+	for _, m := range read {
+		if reply.To == Everyone || m.From == reply.To {
+			fromRecipient = append(fromRecipient, m)
+		}
+	}
+	if i := reply.InReplyTo - 1 - hostMessages; i >= 0 && i < len(read) && slices.Contains(fromRecipient, read[i]) {
+		return read[i]
+	}
+	if len(fromRecipient) == 0 {
+		return nil
+	}
+	return fromRecipient[len(fromRecipient)-1]
+}
+
+// correspondent returns who a player talks with in a message.
+func correspondent(m *Message, player int) int {
+	switch {
+	case m.To == Everyone:
+		return Everyone
+	case m.From == player:
+		return m.To
+	default:
+		return m.From
+	}
+}
+
+// WriteMarkdown writes the threads of a player as Markdown, one section per
+// correspondent with replies nested under the message they answer. names
+// returns the name of a player number, "" if unknown.
+func WriteMarkdown(w io.Writer, threads []Thread, player int, names func(int) string) error {
+	label := func(p int) string {
+		if p == Everyone {
+			return "everyone"
+		}
+		if name := names(p); name != "" {
+			return fmt.Sprintf("%s (player %d)", name, p)
+		}
+		return fmt.Sprintf("player %d", p)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Messages of %s\n", report.EscapeMarkdown(label(player)))
+	if len(threads) == 0 {
+		b.WriteString("\nNo messages.\n")
+	}
+	for _, t := range threads {
+		if t.Correspondent == Everyone {
+			b.WriteString("\n## To everyone\n\n")
+		} else {
+			fmt.Fprintf(&b, "\n## With %s\n\n", report.EscapeMarkdown(label(t.Correspondent)))
+		}
+		var write func(messages []*Message, depth int)
+		write = func(messages []*Message, depth int) {
+			for _, m := range messages {
+				text := strings.Join(strings.Fields(m.Text), " ")
+				fmt.Fprintf(&b, "%s- **%d, %s to %s:** %s\n", strings.Repeat("  ", depth),
+					m.Year, report.EscapeMarkdown(label(m.From)), report.EscapeMarkdown(label(m.To)),
+					report.EscapeMarkdown(text))
+				write(m.Replies, depth+1)
+			}
+		}
+		write(t.Messages, 0)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,81 @@
+package msgthreads
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+// Player 1 writes to player 2 and to everyone in 2402, player 2 answers
+// with three messages in 2403 and player 1 replies to each in 2404.
+func loadConversation(t *testing.T) []*store.GameStore {
+	t.Helper()
+	dir := "../../../testdata/scenario-message/player-messages/"
+	var stores []*store.GameStore
+	for _, turn := range [][]string{
+		{"2403-p2-just-received-and-reply-on-the-way/game.m2", "2403-p2-just-received-and-reply-on-the-way/game.x2"},
+		{"2404-p1/game.m1", "2404-p1/game.x1"},
+	} {
+		gs := store.New()
+		for _, name := range turn {
+			data, err := os.ReadFile(dir + name)
+			require.NoError(t, err)
+			require.NoError(t, gs.AddFile(name, data))
+		}
+		stores = append(stores, gs)
+	}
+	return stores
+}
+
+func TestFromStores(t *testing.T) {
+	threads := FromStores(loadConversation(t), 1)
+	require.Len(t, threads, 2)
+
+	assert.Equal(t, Everyone, threads[0].Correspondent)
+	require.Len(t, threads[0].Messages, 1)
+	assert.Equal(t, "Hi everybody :p", threads[0].Messages[0].Text)
+
+	with2 := threads[1]
+	assert.Equal(t, 2, with2.Correspondent)
+	assert.Equal(t, 7, with2.Count())
+	require.Len(t, with2.Messages, 1)
+	first := with2.Messages[0]
+	assert.Equal(t, 2403, first.Year)
+	assert.Equal(t, 1, first.From)
+	assert.Equal(t, 2, first.To)
+
+	// Each of player 2's messages gets its own reply, though player 1's
+	// replies point past the host messages of their list
+	require.Len(t, first.Replies, 3)
+	for i, answer := range first.Replies {
+		assert.Equal(t, 2404, answer.Year)
+		assert.Same(t, first, answer.Parent)
+		require.Len(t, answer.Replies, 1, "message %d", i+1)
+		assert.Equal(t, 2405, answer.Replies[0].Year)
+		assert.Contains(t, strings.ToLower(answer.Replies[0].Text), "reply to "+strconv.Itoa(i+1))
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	names := map[int]string{1: "Hobbits"}
+	var b strings.Builder
+	err := WriteMarkdown(&b, FromStores(loadConversation(t), 2), 2, func(p int) string { return names[p] })
+	require.NoError(t, err)
+
+	out := b.String()
+	assert.True(t, strings.HasPrefix(out, "# Messages of player 2\n"))
+	assert.Contains(t, out, "\n## To everyone\n\n- **2403, Hobbits (player 1) to everyone:** Hi everybody :p\n")
+	assert.Contains(t, out, "\n## With Hobbits (player 1)\n\n")
+	assert.Contains(t, out, "\n    - **2405, Hobbits (player 1) to player 2:** reply to 1\n")
+	assert.Contains(t, out, "I'll find you first man ... be sure of it !")
+
+	b.Reset()
+	require.NoError(t, WriteMarkdown(&b, nil, 3, func(int) string { return "" }))
+	assert.Equal(t, "# Messages of player 3\n\nNo messages.\n", b.String())
+}
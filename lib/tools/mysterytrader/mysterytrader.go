@@ -0,0 +1,196 @@
+// Package mysterytrader tracks which players met the Mystery Trader and
+// which items they received over a game, and summarizes how evenly the
+// trader's luck was spread.
+//
+// Items come from each player's owned item bits, known for the players whose
+// M files (or the host file) are loaded; a gift is dated to the first turn
+// its bit appears. Encounters come from the traders' met bits, which every
+// player seeing the trader can read.
+//
+// Example usage:
+//
+//	report := mysterytrader.Analyze(turns)
+//	for _, g := range report.Gifts {
+//	    fmt.Printf("%d: player %d received %s\n", g.Year, g.Player+1, g.Item)
+//	}
+//	fmt.Printf("Gini: %.2f\n", report.Fairness.Gini)
+package mysterytrader
+
+import (
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+// Gift is a Mystery Trader item received by a player.
+type Gift struct {
+	Year   int
+	Player int // Player index (0-15)
+	Bit    uint16
+	Item   string
+}
+
+// Encounter is the first meeting of a player with a trader.
+type Encounter struct {
+	Year   int
+	Player int // Player index (0-15)
+	Trader int // Trader object number
+	X, Y   int
+}
+
+// PlayerSummary totals the trader luck of one player.
+type PlayerSummary struct {
+	Player     int // Player index (0-15)
+	Name       string
+	ItemsKnown bool     // False when no file revealed the player's items
+	Items      []string // Items owned in the latest turn the player was known
+	Encounters int
+}
+
+// Fairness summarizes how items were spread among the players whose items
+// are known.
+type Fairness struct {
+	Players   int
+	MeanItems float64
+	MinItems  int
+	MaxItems  int
+	Gini      float64 // 0 when every player has as many items, towards 1 when one has them all
+	Empty     []int   // Players without any item
+}
+
+// Report is the Mystery Trader history of a game.
+type Report struct {
+	FirstYear  int
+	LastYear   int
+	Traders    int // Distinct traders sighted
+	Gifts      []Gift
+	Encounters []Encounter
+	Players    []PlayerSummary
+	Fairness   Fairness
+}
+
+// Analyze builds the report from one store per turn, in any order.
+func Analyze(turns []*store.GameStore) *Report {
+	sorted := slices.Clone(turns)
+	slices.SortFunc(sorted, func(a, b *store.GameStore) int {
+		return int(a.Turn) - int(b.Turn)
+	})
+
+	report := &Report{}
+	items := make(map[int]uint16)
+	names := make(map[int]string)
+	met := make(map[[2]int]bool)
+	traders := make(map[int]bool)
+	encounters := make(map[int]int)
+
+	for _, gs := range sorted {
+		year := blocks.StarsBaseYear + int(gs.Turn)
+		if report.FirstYear == 0 {
+			report.FirstYear = year
+		}
+		report.LastYear = year
+
+		for _, p := range gs.AllPlayers() {
+			if p.NamePlural != "" {
+				names[p.PlayerNumber] = p.NamePlural
+			}
+			if !p.HasFullData {
+				continue
+			}
+			for bit := uint16(1); bit <= blocks.TraderItemShip; bit <<= 1 {
+				if p.MTItems&bit != 0 && items[p.PlayerNumber]&bit == 0 {
+					report.Gifts = append(report.Gifts, Gift{
+						Year:   year,
+						Player: p.PlayerNumber,
+						Bit:    bit,
+						Item:   blocks.TraderItemName(bit),
+					})
+				}
+			}
+			items[p.PlayerNumber] = p.MTItems
+		}
+
+		for _, t := range gs.MysteryTraders() {
+			traders[t.Number] = true
+			for player := range 16 {
+				key := [2]int{t.Number, player}
+				if t.MetBits&(1<<player) == 0 || met[key] {
+					continue
+				}
+				met[key] = true
+				encounters[player]++
+				report.Encounters = append(report.Encounters, Encounter{
+					Year:   year,
+					Player: player,
+					Trader: t.Number,
+					X:      t.X,
+					Y:      t.Y,
+				})
+			}
+		}
+	}
+	report.Traders = len(traders)
+
+	for player := range 16 {
+		owned, known := items[player]
+		if _, named := names[player]; !named && !known && encounters[player] == 0 {
+			continue
+		}
+		summary := PlayerSummary{
+			Player:     player,
+			Name:       names[player],
+			ItemsKnown: known,
+			Encounters: encounters[player],
+		}
+		for bit := uint16(1); bit <= blocks.TraderItemShip; bit <<= 1 {
+			if owned&bit != 0 {
+				summary.Items = append(summary.Items, blocks.TraderItemName(bit))
+			}
+		}
+		report.Players = append(report.Players, summary)
+	}
+	report.Fairness = fairness(report.Players)
+	return report
+}
+
+// fairness computes the item spread over the players whose items are known.
+func fairness(players []PlayerSummary) Fairness {
+	var f Fairness
+	var counts []int
+	for _, p := range players {
+		if !p.ItemsKnown {
+			continue
+		}
+		counts = append(counts, len(p.Items))
+		if len(p.Items) == 0 {
+			f.Empty = append(f.Empty, p.Player)
+		}
+	}
+	f.Players = len(counts)
+	if f.Players == 0 {
+		return f
+	}
+
+	total := 0
+	f.MinItems, f.MaxItems = counts[0], counts[0]
+	for _, c := range counts {
+		total += c
+		f.MinItems = min(f.MinItems, c)
+		f.MaxItems = max(f.MaxItems, c)
+	}
+	f.MeanItems = float64(total) / float64(f.Players)
+
+	// Gini coefficient: mean absolute difference over twice the mean
+	if total > 0 {
+		var diff int
+		for _, a := range counts {
+			for _, b := range counts {
+				diff += max(a-b, b-a)
+			}
+		}
+		n := float64(f.Players)
+		f.Gini = float64(diff) / (2 * n * n * f.MeanItems)
+	}
+	return f
+}
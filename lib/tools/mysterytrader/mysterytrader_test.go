@@ -0,0 +1,80 @@
+package mysterytrader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+func loadStore(t *testing.T, turn uint16) *store.GameStore {
+	t.Helper()
+	gs := store.New()
+	require.NoError(t, gs.AddFileWithXY("../../../testdata/scenario-mysterytrader/game.m1"))
+	gs.Turn = turn
+	return gs
+}
+
+func TestAnalyze(t *testing.T) {
+	first, second := loadStore(t, 47), loadStore(t, 48)
+
+	p0, ok := first.Player(0)
+	require.True(t, ok)
+	p0.MTItems = blocks.TraderItemLangstonShield
+	p0, _ = second.Player(0)
+	p0.MTItems = blocks.TraderItemLangstonShield | blocks.TraderItemGenesisDevice
+
+	trader := second.MysteryTraders()[0]
+	trader.MetBits = 1 << 1
+
+	report := Analyze([]*store.GameStore{second, first})
+	assert.Equal(t, 2447, report.FirstYear)
+	assert.Equal(t, 2448, report.LastYear)
+	assert.Equal(t, 1, report.Traders)
+
+	assert.Equal(t, []Gift{
+		{Year: 2447, Player: 0, Bit: blocks.TraderItemLangstonShield, Item: "Langston Shield"},
+		{Year: 2448, Player: 0, Bit: blocks.TraderItemGenesisDevice, Item: "Genesis Device"},
+	}, report.Gifts)
+
+	require.Len(t, report.Encounters, 1)
+	assert.Equal(t, Encounter{Year: 2448, Player: 1, Trader: trader.Number, X: 1182, Y: 1127}, report.Encounters[0])
+
+	require.Len(t, report.Players, 2)
+	assert.Equal(t, "MineMongers", report.Players[0].Name)
+	assert.Equal(t, []string{"Langston Shield", "Genesis Device"}, report.Players[0].Items)
+	// The Halflings' items are not in the MineMongers' M file
+	assert.False(t, report.Players[1].ItemsKnown)
+	assert.Equal(t, 1, report.Players[1].Encounters)
+
+	assert.Equal(t, 1, report.Fairness.Players)
+	assert.Equal(t, 2.0, report.Fairness.MeanItems)
+	assert.Empty(t, report.Fairness.Empty)
+}
+
+func TestFairness(t *testing.T) {
+	players := []PlayerSummary{
+		{Player: 0, ItemsKnown: true, Items: []string{"a", "b", "c"}},
+		{Player: 1, ItemsKnown: true},
+		{Player: 2, ItemsKnown: true, Items: []string{"a"}},
+		{Player: 3},
+	}
+	f := fairness(players)
+	assert.Equal(t, 3, f.Players)
+	assert.InDelta(t, 4.0/3, f.MeanItems, 1e-9)
+	assert.Equal(t, 0, f.MinItems)
+	assert.Equal(t, 3, f.MaxItems)
+	assert.Equal(t, []int{1}, f.Empty)
+	// Pairwise differences 2*(3+1+2) = 12 over 2*9*(4/3) = 24
+	assert.InDelta(t, 0.5, f.Gini, 1e-9)
+
+	even := fairness([]PlayerSummary{
+		{ItemsKnown: true, Items: []string{"a"}},
+		{ItemsKnown: true, Items: []string{"b"}},
+	})
+	assert.Zero(t, even.Gini)
+	assert.Zero(t, fairness(nil).Players)
+}
@@ -0,0 +1,130 @@
+// Package namefixer detects and repairs Stars! files named after another
+// player than the one in their header.
+//
+// Stars! reads the player of a turn (.mN), orders (.xN) or history (.hN)
+// file from its header, not from its name. A game.m2 copied over game.m1 is
+// still player 2's turn: Stars! opens it as such, and the host rejects the
+// orders saved from it. The mistake is easy to make when copying files by
+// hand, and hard to see. A misnamed file is fixed either by renaming it
+// after the player of its header, or by rewriting its header for the player
+// of its name, which re-encrypts the whole file.
+//
+// The library operates entirely in memory - callers are responsible for reading files
+// from and writing files to their storage (disk, database, etc.).
+//
+// Example usage:
+//
+//	data, _ := os.ReadFile("game.m1")
+//	m, err := namefixer.CheckBytes("game.m1", data)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if m != nil {
+//	    os.Rename(m.Filename, m.RenamedPath()) // game.m2
+//	}
+package namefixer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/filenames"
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/store"
+)
+
+// ErrNotPlayerFile is returned for files that do not belong to a single
+// player: universe, host and race files.
+var ErrNotPlayerFile = errors.New("not a turn, orders or history file")
+
+// Mismatch describes a file named after another player than its header's.
+type Mismatch struct {
+	Filename     string
+	NamePlayer   int // Player number (1-16) of the file name
+	HeaderPlayer int // Player number (1-16) in the file header
+}
+
+// String describes the mismatch.
+func (m *Mismatch) String() string {
+	return fmt.Sprintf("%s is named for player %d but holds player %d's data",
+		m.Filename, m.NamePlayer, m.HeaderPlayer)
+}
+
+// RenamedPath returns the path the file should have: game.m1 holding player
+// 2's turn is game.m2.
+func (m *Mismatch) RenamedPath() string {
+	return filenames.Companion(m.Filename, filenames.KindOf(m.Filename), m.HeaderPlayer)
+}
+
+// CheckBytes compares the player number of a file name with the player
+// index in the header of its data. It returns nil when they agree.
+func CheckBytes(filename string, data []byte) (*Mismatch, error) {
+	name := filenames.Parse(filename)
+	if !name.Kind.PerPlayer() || name.Kind == filenames.R {
+		return nil, fmt.Errorf("%w: %s", ErrNotPlayerFile, filename)
+	}
+	header, err := parser.FileData(data).FileHeader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if name.MatchesPlayer(header.PlayerIndex()) {
+		return nil, nil
+	}
+	return &Mismatch{
+		Filename:     filename,
+		NamePlayer:   name.Player,
+		HeaderPlayer: header.PlayerIndex() + 1,
+	}, nil
+}
+
+// RewriteBytes returns the file with the player index of its header set to
+// player (1-16). Blocks are encrypted with a key derived from the header, so
+// all of them are decrypted and encrypted again; their content is left as
+// is. The result is the file as player would have it, which Stars! accepts
+// only if player's data is what it holds: renaming is the safer fix when
+// the header is right.
+func RewriteBytes(data []byte, player int) ([]byte, error) {
+	if player < 1 || player > 16 {
+		return nil, fmt.Errorf("invalid player number %d", player)
+	}
+	decrypted, err := parser.FileData(data).DecryptedBlocks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+
+	writer := store.NewFileWriter()
+	encoder := store.NewBlockEncoder()
+	var result []byte
+	for i, block := range decrypted {
+		switch block.Type {
+		case blocks.FileHeaderBlockType:
+			if i != 0 {
+				return nil, fmt.Errorf("unexpected file header at block %d", i)
+			}
+			header, err := blocks.NewFileHeader(block.GenericBlock)
+			if err != nil {
+				return nil, err
+			}
+			switch header.FileType {
+			case blocks.FileTypeM, blocks.FileTypeX, blocks.FileTypeH:
+			default:
+				return nil, fmt.Errorf("%w: file type %s", ErrNotPlayerFile, header.FileTypeName())
+			}
+			header.SetPlayerIndex(player - 1)
+			result = append(result, writer.WriteHeader(header)...)
+
+			writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), header.SharewareFlag())
+		case blocks.FileFooterBlockType:
+			// The footer is not encrypted
+			result = append(result, encoder.EncodeBlock(block.Type, block.Data)...)
+		default:
+			if i == 0 {
+				return nil, errors.New("missing file header")
+			}
+			result = append(result, writer.WriteEncryptedBlock(block.Type, block.Decrypted)...)
+			result = append(result, block.Trailer...)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package namefixer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/store"
+)
+
+const turnFile = "../../../testdata/scenario-map/history/game-2440.m1"
+
+func TestCheckBytes(t *testing.T) {
+	data, err := os.ReadFile(turnFile)
+	require.NoError(t, err)
+
+	m, err := CheckBytes("game.m1", data)
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	m, err = CheckBytes("turns/GAME.M3", data)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	assert.Equal(t, 3, m.NamePlayer)
+	assert.Equal(t, 1, m.HeaderPlayer)
+	assert.Equal(t, "turns/GAME.M1", m.RenamedPath())
+	assert.Equal(t, "turns/GAME.M3 is named for player 3 but holds player 1's data", m.String())
+
+	_, err = CheckBytes("game.hst", data)
+	assert.ErrorIs(t, err, ErrNotPlayerFile)
+}
+
+func TestRewriteBytes(t *testing.T) {
+	data, err := os.ReadFile(turnFile)
+	require.NoError(t, err)
+
+	rewritten, err := RewriteBytes(data, 3)
+	require.NoError(t, err)
+	header, err := parser.FileData(rewritten).FileHeader()
+	require.NoError(t, err)
+	assert.Equal(t, 2, header.PlayerIndex())
+
+	// The blocks decrypt with the new key to the same content
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game.m3", rewritten))
+	assert.Empty(t, gs.Warnings())
+	orig := store.New()
+	require.NoError(t, orig.AddFile("game.m1", data))
+	assert.Equal(t, len(orig.AllPlanets()), len(gs.AllPlanets()))
+
+	// Rewriting back gives the original file
+	back, err := RewriteBytes(rewritten, 1)
+	require.NoError(t, err)
+	assert.Equal(t, data, back)
+
+	_, err = RewriteBytes(data, 17)
+	assert.Error(t, err)
+}
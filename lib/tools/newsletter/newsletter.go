@@ -0,0 +1,289 @@
+// Package newsletter writes the public news of a game year, for hosts to
+// post after each turn generation.
+//
+// A newsletter only tells what the players could all know, so that it can
+// be posted where everyone reads it:
+//
+//   - the score table and its changes since the year before, when the game
+//     has public scores
+//   - who fought whom, without where or with what losses
+//   - the messages sent to everyone
+//
+// Planets, fleets, designs, research and the messages between two players
+// are left out, whatever the host files reveal.
+//
+// Example usage:
+//
+//	news, err := newsletter.Build(stores, 2450) // one GameStore per turn
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, line := range news.Headlines {
+//	    fmt.Println(line)
+//	}
+package newsletter
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/lib/tools/eventlog"
+	"github.com/neper-stars/houston/store"
+)
+
+// ErrYearNotFound is returned when no turn of the requested year is given.
+var ErrYearNotFound = errors.New("no turn found for the year")
+
+// Standing is a player's line of the score table. Changes are since the
+// year before, 0 when it is not known.
+type Standing struct {
+	Player int // Player number (1-16)
+	Name   string
+	Rank   int // 1 for the leader
+
+	Score, ScoreChange         int
+	Planets, PlanetsChange     int
+	Starbases                  int
+	Ships, ShipsChange         int // Unarmed, escort and capital ships
+	TechLevels, TechChange     int
+	Resources, ResourcesChange int64
+}
+
+// Clash is two players who fought during the year.
+type Clash struct {
+	Players [2]int // Player numbers (1-16), lowest first
+	Battles int
+}
+
+// Announcement is a message sent to everyone.
+type Announcement struct {
+	From int // Player number (1-16)
+	Text string
+}
+
+// Newsletter is the public news of a year.
+type Newsletter struct {
+	GameID   uint32
+	GameName string
+	Year     int
+	Players  map[int]string // Plural race names by player number
+
+	// PublicScores tells whether the game shows scores to everyone.
+	// Standings are empty otherwise.
+	PublicScores bool
+	HasPrevious  bool // The turn of the year before was given
+	Standings    []Standing
+
+	Headlines     []string
+	Clashes       []Clash
+	Announcements []Announcement
+}
+
+// PlayerName returns the display name of a player number (1-16).
+func (n *Newsletter) PlayerName(player int) string {
+	if name := n.Players[player]; name != "" {
+		return "the " + name
+	}
+	return fmt.Sprintf("player %d", player)
+}
+
+// Build writes the newsletter of a year from one GameStore per turn. The
+// store of the year before, if given, provides the score changes. Stores
+// should hold the M files of every player: each only has its own score.
+func Build(stores []*store.GameStore, year int) (*Newsletter, error) {
+	var current, previous *store.GameStore
+	for _, gs := range stores {
+		switch int(gs.Turn) + blocks.StarsBaseYear {
+		case year:
+			current = gs
+		case year - 1:
+			previous = gs
+		}
+	}
+	if current == nil {
+		return nil, fmt.Errorf("%w: %d", ErrYearNotFound, year)
+	}
+
+	n := &Newsletter{
+		GameID:       current.GameID,
+		GameName:     strings.TrimRight(current.GameName, "\x00 "),
+		Year:         year,
+		Players:      make(map[int]string),
+		PublicScores: current.HasGameSetting(data.GameSettingPublicScores),
+		HasPrevious:  previous != nil,
+	}
+	for _, p := range current.AllPlayers() {
+		if p.NamePlural != "" {
+			n.Players[p.PlayerNumber+1] = p.NamePlural
+		}
+	}
+
+	if n.PublicScores {
+		n.Standings = standings(current)
+		if previous != nil {
+			applyChanges(n.Standings, standings(previous))
+		}
+	}
+	n.Clashes = clashes(current, year)
+	n.Announcements = announcements(current)
+	n.Headlines = n.headlines(previous)
+	return n, nil
+}
+
+// standings returns the score table of a turn, best score first.
+func standings(gs *store.GameStore) []Standing {
+	var table []Standing
+	for _, p := range gs.AllPlayers() {
+		s := p.StoredScore
+		if s == nil {
+			continue
+		}
+		table = append(table, Standing{
+			Player:     p.PlayerNumber + 1,
+			Name:       p.NamePlural,
+			Score:      s.Score,
+			Planets:    s.Planets,
+			Starbases:  s.Starbases,
+			Ships:      s.UnarmedShips + s.EscortShips + s.CapitalShips,
+			TechLevels: s.TechLevels,
+			Resources:  s.Resources,
+		})
+	}
+	slices.SortStableFunc(table, func(a, b Standing) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Player, b.Player))
+	})
+	for i := range table {
+		table[i].Rank = i + 1
+	}
+	return table
+}
+
+// applyChanges fills the changes of a score table from the year before.
+func applyChanges(table, before []Standing) {
+	for i := range table {
+		s := &table[i]
+		j := slices.IndexFunc(before, func(b Standing) bool { return b.Player == s.Player })
+		if j < 0 {
+			continue
+		}
+		b := before[j]
+		s.ScoreChange = s.Score - b.Score
+		s.PlanetsChange = s.Planets - b.Planets
+		s.ShipsChange = s.Ships - b.Ships
+		s.TechChange = s.TechLevels - b.TechLevels
+		s.ResourcesChange = s.Resources - b.Resources
+	}
+}
+
+// clashes returns the pairs of players who fought during the year, from the
+// battles reported in their M files. A battle reported by both sides is
+// counted once.
+func clashes(gs *store.GameStore, year int) []Clash {
+	type battle struct {
+		pair   [2]int
+		planet int
+	}
+	seen := make(map[battle]bool)
+	counts := make(map[[2]int]int)
+	for _, e := range eventlog.FromStore(gs) {
+		if e.Type != eventlog.TypeBattle || e.Year != year || e.Enemy == 0 {
+			continue
+		}
+		b := battle{pair: [2]int{min(e.Player, e.Enemy), max(e.Player, e.Enemy)}, planet: e.Planet}
+		if seen[b] {
+			continue
+		}
+		seen[b] = true
+		counts[b.pair]++
+	}
+
+	result := make([]Clash, 0, len(counts))
+	for pair, battles := range counts {
+		result = append(result, Clash{Players: pair, Battles: battles})
+	}
+	slices.SortFunc(result, func(a, b Clash) int {
+		return cmp.Or(cmp.Compare(b.Battles, a.Battles),
+			cmp.Compare(a.Players[0], b.Players[0]), cmp.Compare(a.Players[1], b.Players[1]))
+	})
+	return result
+}
+
+// announcements returns the messages to everyone delivered with the turn.
+// Messages in orders files are left out: they are only read the year after.
+func announcements(gs *store.GameStore) []Announcement {
+	var result []Announcement
+	for _, m := range gs.AllMessages() {
+		source := m.Meta().BestSource
+		if !m.IsBroadcast() || source == nil || source.Type != store.SourceTypeMFile {
+			continue
+		}
+		a := Announcement{From: m.SenderId + 1, Text: strings.Join(strings.Fields(m.Message), " ")}
+		if a.Text != "" && !slices.Contains(result, a) {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// headlines sums up the year in a few sentences.
+func (n *Newsletter) headlines(previous *store.GameStore) []string {
+	var lines []string
+	if len(n.Standings) > 0 {
+		top := n.Standings[0]
+		before := 0
+		if previous != nil {
+			if prev := standings(previous); len(prev) > 0 {
+				before = prev[0].Player
+			}
+		}
+		switch {
+		case before != 0 && before != top.Player:
+			lines = append(lines, capitalize(fmt.Sprintf("%s take the lead from %s with %d points.",
+				n.PlayerName(top.Player), n.PlayerName(before), top.Score)))
+		default:
+			lines = append(lines, capitalize(fmt.Sprintf("%s lead with %d points.", n.PlayerName(top.Player), top.Score)))
+		}
+
+		if n.HasPrevious {
+			gainer := slices.MaxFunc(n.Standings, func(a, b Standing) int {
+				return cmp.Or(cmp.Compare(a.ScoreChange, b.ScoreChange), cmp.Compare(b.Player, a.Player))
+			})
+			if gainer.ScoreChange > 0 {
+				lines = append(lines, capitalize(fmt.Sprintf("%s gained the most ground, %+d points.",
+					n.PlayerName(gainer.Player), gainer.ScoreChange)))
+			}
+			grower := slices.MaxFunc(n.Standings, func(a, b Standing) int {
+				return cmp.Or(cmp.Compare(a.PlanetsChange, b.PlanetsChange), cmp.Compare(b.Player, a.Player))
+			})
+			if grower.PlanetsChange > 0 {
+				lines = append(lines, capitalize(fmt.Sprintf("%s expanded the most, now on %d planets (%+d).",
+					n.PlayerName(grower.Player), grower.Planets, grower.PlanetsChange)))
+			}
+		}
+	}
+
+	battles := 0
+	for _, c := range n.Clashes {
+		battles += c.Battles
+	}
+	switch battles {
+	case 0:
+	case 1:
+		lines = append(lines, "One battle was fought.")
+	default:
+		lines = append(lines, fmt.Sprintf("%d battles were fought.", battles))
+	}
+	return lines
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
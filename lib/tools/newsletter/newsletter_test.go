@@ -0,0 +1,95 @@
+package newsletter
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+func loadYear(t *testing.T, dir string, names ...string) *store.GameStore {
+	t.Helper()
+	gs := store.New()
+	for _, name := range names {
+		raw, err := os.ReadFile(dir + name)
+		require.NoError(t, err)
+		require.NoError(t, gs.AddFile(name, raw))
+	}
+	return gs
+}
+
+func historyYear(t *testing.T, year int) *store.GameStore {
+	t.Helper()
+	var names []string
+	for _, ext := range []string{"xy", "m1", "m2", "hst"} {
+		names = append(names, fmt.Sprintf("game-%d.%s", year, ext))
+	}
+	return loadYear(t, "../../../testdata/scenario-map/history/", names...)
+}
+
+func TestBuild(t *testing.T) {
+	stores := []*store.GameStore{historyYear(t, 2479), historyYear(t, 2480)}
+
+	news, err := Build(stores, 2480)
+	require.NoError(t, err)
+	assert.Equal(t, 2480, news.Year)
+	assert.True(t, news.HasPrevious)
+	assert.Equal(t, "Hobbits", news.Players[1])
+
+	// Scores are private in this game
+	assert.False(t, news.PublicScores)
+	assert.Empty(t, news.Standings)
+
+	// Both sides report the battle at Redmond; it is told once, without
+	// its location
+	assert.Equal(t, []Clash{{Players: [2]int{1, 2}, Battles: 1}}, news.Clashes)
+	assert.Equal(t, []string{"One battle was fought."}, news.Headlines)
+
+	_, err = Build(stores, 2481)
+	assert.ErrorIs(t, err, ErrYearNotFound)
+}
+
+func TestBuild_PublicScores(t *testing.T) {
+	stores := []*store.GameStore{historyYear(t, 2479), historyYear(t, 2480)}
+	for _, gs := range stores {
+		gs.GameSettings |= data.GameSettingPublicScores
+	}
+
+	news, err := Build(stores, 2480)
+	require.NoError(t, err)
+	require.Len(t, news.Standings, 2)
+
+	top := news.Standings[0]
+	assert.Equal(t, 1, top.Rank)
+	assert.Equal(t, 2, news.Standings[1].Rank)
+	assert.GreaterOrEqual(t, top.Score, news.Standings[1].Score)
+
+	before, err := Build(stores, 2479)
+	require.NoError(t, err)
+	assert.False(t, before.HasPrevious)
+	for _, s := range news.Standings {
+		for _, b := range before.Standings {
+			if b.Player == s.Player {
+				assert.Equal(t, s.Score-b.Score, s.ScoreChange)
+				assert.Equal(t, s.Planets-b.Planets, s.PlanetsChange)
+			}
+		}
+	}
+	assert.Contains(t, news.Headlines[0], fmt.Sprintf("lead with %d points.", top.Score))
+}
+
+func TestBuild_Announcements(t *testing.T) {
+	// Player 2's turn holds player 1's messages, one of them to everyone,
+	// and their orders the replies, not delivered yet
+	dir := "../../../testdata/scenario-message/player-messages/2403-p2-just-received-and-reply-on-the-way/"
+	gs := loadYear(t, dir, "game.m2", "game.x2")
+
+	news, err := Build([]*store.GameStore{gs}, 2403)
+	require.NoError(t, err)
+	assert.Equal(t, []Announcement{{From: 1, Text: "Hi everybody :p"}}, news.Announcements)
+}
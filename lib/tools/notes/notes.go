@@ -0,0 +1,185 @@
+// Package notes keeps player notes about planets and fleets in a JSON file
+// next to the game files, so they follow the game from turn to turn:
+//
+//	book, err := notes.Load(notes.Path("game.m1"))
+//	book.Add(notes.Note{Planet: "Rigel", Text: "staging area"})
+//	err = book.Save()
+//
+// A notes file belongs to one game: it records the game ID and refuses to
+// be used with the files of another game.
+package notes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/neper-stars/houston/store"
+)
+
+var (
+	// ErrNoteNotFound is returned when removing a note that does not exist.
+	ErrNoteNotFound = errors.New("note not found")
+	// ErrGameMismatch is returned when a notes file is used with another game.
+	ErrGameMismatch = errors.New("notes belong to another game")
+	// ErrNoTarget is returned when adding a note about nothing.
+	ErrNoTarget = errors.New("a note needs a planet or a fleet")
+)
+
+// Note is a note about a planet or a fleet.
+type Note struct {
+	ID int `json:"id"`
+	// Planet is the planet name; PlanetNumber is set when the planet was
+	// found in the game files.
+	Planet       string `json:"planet,omitempty"`
+	PlanetNumber *int   `json:"planet_number,omitempty"`
+	// FleetOwner (1-16) and Fleet (1-based, as in fleet names) identify a
+	// fleet.
+	FleetOwner int       `json:"fleet_owner,omitempty"`
+	Fleet      int       `json:"fleet,omitempty"`
+	Text       string    `json:"text"`
+	Year       int       `json:"year,omitempty"` // Game year the note was taken in
+	Added      time.Time `json:"added"`
+}
+
+// Subject describes what the note is about, e.g. "planet Rigel" or
+// "fleet 3 of player 2".
+func (n Note) Subject() string {
+	if n.Planet != "" {
+		return "planet " + n.Planet
+	}
+	return fmt.Sprintf("fleet %d of player %d", n.Fleet, n.FleetOwner)
+}
+
+// Book is the notes of a game.
+type Book struct {
+	GameID uint32 `json:"game_id,omitempty"`
+	Notes  []Note `json:"notes"`
+
+	path string
+}
+
+// Path returns the notes file of the game a file belongs to: game.notes.json
+// for game.m1, game.xy or game.hst.
+func Path(gameFile string) string {
+	dir, base := filepath.Split(gameFile)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	return filepath.Join(dir, base+".notes.json")
+}
+
+// Load reads a notes file. A missing file is an empty book, created by Save.
+func Load(path string) (*Book, error) {
+	book := &Book{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return book, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, book); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return book, nil
+}
+
+// Save writes the book to the file it was loaded from.
+func (b *Book) Save() error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(b.path, append(data, '\n'), 0o644)
+}
+
+// CheckGame ties the book to a game, or fails with ErrGameMismatch when it
+// belongs to another one.
+func (b *Book) CheckGame(gameID uint32) error {
+	if b.GameID == 0 {
+		b.GameID = gameID
+	}
+	if b.GameID != gameID {
+		return fmt.Errorf("%w: %s is for game %d, not %d", ErrGameMismatch, b.path, b.GameID, gameID)
+	}
+	return nil
+}
+
+// Add adds a note and returns it with its ID. Added is set when zero.
+func (b *Book) Add(n Note) (Note, error) {
+	if n.Planet == "" && n.Fleet == 0 {
+		return Note{}, ErrNoTarget
+	}
+	n.ID = 1
+	for _, existing := range b.Notes {
+		n.ID = max(n.ID, existing.ID+1)
+	}
+	if n.Added.IsZero() {
+		n.Added = time.Now().UTC().Truncate(time.Second)
+	}
+	b.Notes = append(b.Notes, n)
+	return n, nil
+}
+
+// Remove deletes the note with an ID.
+func (b *Book) Remove(id int) error {
+	i := slices.IndexFunc(b.Notes, func(n Note) bool { return n.ID == id })
+	if i < 0 {
+		return fmt.Errorf("%w: #%d", ErrNoteNotFound, id)
+	}
+	b.Notes = slices.Delete(b.Notes, i, i+1)
+	return nil
+}
+
+// ForPlanet returns the notes about a planet, by number when the note
+// recorded it, else by case-insensitive name.
+func (b *Book) ForPlanet(number int, name string) []Note {
+	var found []Note
+	for _, n := range b.Notes {
+		if n.PlanetNumber != nil && *n.PlanetNumber == number ||
+			n.PlanetNumber == nil && n.Planet != "" && strings.EqualFold(n.Planet, name) {
+			found = append(found, n)
+		}
+	}
+	return found
+}
+
+// ForFleet returns the notes about a fleet (owner 1-16, fleet 1-based).
+func (b *Book) ForFleet(owner, fleet int) []Note {
+	var found []Note
+	for _, n := range b.Notes {
+		if n.Fleet == fleet && n.FleetOwner == owner {
+			found = append(found, n)
+		}
+	}
+	return found
+}
+
+// Locate returns where the subject of a note is in a game, when it is
+// known there.
+func Locate(gs *store.GameStore, n Note) (x, y int, ok bool) {
+	if n.Planet != "" {
+		// A planet may be known from several files; the best view wins
+		var best *store.PlanetEntity
+		for _, p := range gs.AllPlanets() {
+			if n.PlanetNumber != nil && p.PlanetNumber == *n.PlanetNumber ||
+				n.PlanetNumber == nil && strings.EqualFold(p.Name, n.Planet) {
+				if best == nil || p.Meta().Quality > best.Meta().Quality {
+					best = p
+				}
+			}
+		}
+		if best == nil {
+			return 0, 0, false
+		}
+		return best.X, best.Y, true
+	}
+	if f, found := gs.Fleet(n.FleetOwner-1, n.Fleet-1); found {
+		return f.X, f.Y, true
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,78 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func TestPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("games", "rigel.notes.json"), Path(filepath.Join("games", "rigel.m3")))
+	assert.Equal(t, "rigel.notes.json", Path("rigel.xy"))
+}
+
+func TestBookRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.notes.json")
+
+	book, err := Load(path)
+	require.NoError(t, err)
+	require.NoError(t, book.CheckGame(42))
+
+	planet := 7
+	first, err := book.Add(Note{Planet: "Rigel", PlanetNumber: &planet, Text: "staging area", Year: 2410})
+	require.NoError(t, err)
+	second, err := book.Add(Note{FleetOwner: 2, Fleet: 3, Text: "scout, watch it"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.ID)
+	assert.Equal(t, 2, second.ID)
+	assert.False(t, second.Added.IsZero())
+	require.NoError(t, book.Save())
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(42), loaded.GameID)
+	assert.Equal(t, book.Notes, loaded.Notes)
+
+	assert.Len(t, loaded.ForPlanet(7, "renamed"), 1)
+	assert.Empty(t, loaded.ForPlanet(8, "Rigel"))
+	assert.Len(t, loaded.ForFleet(2, 3), 1)
+	assert.Empty(t, loaded.ForFleet(1, 3))
+
+	require.NoError(t, loaded.Remove(1))
+	assert.ErrorIs(t, loaded.Remove(1), ErrNoteNotFound)
+	third, err := loaded.Add(Note{Planet: "Sol", Text: "home"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, third.ID)
+	assert.Len(t, loaded.ForPlanet(0, "SOL"), 1)
+
+	assert.ErrorIs(t, loaded.CheckGame(43), ErrGameMismatch)
+	_, err = loaded.Add(Note{Text: "about nothing"})
+	assert.ErrorIs(t, err, ErrNoTarget)
+}
+
+func TestLocate(t *testing.T) {
+	gs := store.New()
+	for _, name := range []string{"game.xy", "game.m1"} {
+		data, err := os.ReadFile(filepath.Join("../../../testdata/scenario-minefield", name))
+		require.NoError(t, err)
+		require.NoError(t, gs.AddFile(name, data))
+	}
+	planet := gs.AllPlanets()[3]
+	fleet := gs.FleetsByOwner(0)[0]
+
+	x, y, ok := Locate(gs, Note{Planet: planet.Name})
+	assert.True(t, ok)
+	assert.Equal(t, [2]int{planet.X, planet.Y}, [2]int{x, y})
+
+	x, y, ok = Locate(gs, Note{FleetOwner: 1, Fleet: fleet.FleetNumber + 1})
+	assert.True(t, ok)
+	assert.Equal(t, [2]int{fleet.X, fleet.Y}, [2]int{x, y})
+
+	_, _, ok = Locate(gs, Note{Planet: "Nowhere"})
+	assert.False(t, ok)
+}
@@ -0,0 +1,262 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/neper-stars/houston/blocks"
+)
+
+// ErrInvalidOrder is returned when an order has values Stars! would reject.
+var ErrInvalidOrder = errors.New("invalid order")
+
+// Limits of the order fields.
+const (
+	maxFleetNumber = 511 // 9 bits
+	maxWaypoint    = 255
+	maxBattlePlan  = 4 // Default plan and four custom plans
+	maxAttackWho   = 19
+)
+
+// Waypoint is a fleet destination and the task carried out there.
+type Waypoint struct {
+	X, Y       int
+	Target     int // Planet or fleet number, for planet and fleet targets
+	TargetType int // blocks.WaypointTarget*
+	Warp       int // 0-10, or blocks.WarpStargate
+	Task       int // blocks.WaypointTask*
+
+	// Transport orders of each cargo type (ironium, boranium, germanium,
+	// colonists, fuel), for blocks.WaypointTaskTransport
+	Transport [blocks.TransportCargoTypeCount]blocks.TransportOrder
+
+	// PatrolRange is the patrol range index, for blocks.WaypointTaskPatrol
+	// (see blocks.WaypointChangeTaskBlock)
+	PatrolRange int
+}
+
+// BattlePlan is a battle plan of the player. Plan 0 is the default plan.
+type BattlePlan struct {
+	ID              int
+	Name            string
+	Tactic          int // blocks.Tactic*
+	PrimaryTarget   int // blocks.Target*
+	SecondaryTarget int // blocks.Target*
+	AttackWho       int // 0 nobody, 1 enemies, 2 neutrals and enemies, 3 everyone, 4+ a player
+	DumpCargo       bool
+}
+
+// AddWaypoint inserts a waypoint in the orders of a fleet, at index (1 for
+// the first waypoint after the fleet's position).
+func (b *Builder) AddWaypoint(fleet, index int, wp Waypoint) error {
+	order, err := waypointOrder(fleet, index, wp)
+	if err != nil {
+		return err
+	}
+	add := &blocks.WaypointAddBlock{WaypointChangeTaskBlock: *order}
+	add.Refresh()
+	b.orders = append(b.orders, add)
+	return nil
+}
+
+// ChangeWaypoint replaces a waypoint of a fleet. Index 0 is the fleet's
+// position, where only the task can change.
+func (b *Builder) ChangeWaypoint(fleet, index int, wp Waypoint) error {
+	order, err := waypointOrder(fleet, index, wp)
+	if err != nil {
+		return err
+	}
+	order.Refresh()
+	b.orders = append(b.orders, order)
+	return nil
+}
+
+// DeleteWaypoint removes a waypoint of a fleet.
+func (b *Builder) DeleteWaypoint(fleet, index int) error {
+	if err := checkFleet(fleet); err != nil {
+		return err
+	}
+	if index < 1 || index > maxWaypoint {
+		return fmt.Errorf("%w: waypoint %d", ErrInvalidOrder, index)
+	}
+	b.orders = append(b.orders, blocks.NewWaypointDeleteOrder(fleet, index))
+	return nil
+}
+
+// SetProductionQueue replaces the production queue of a planet, and any
+// earlier order of the builder for it.
+func (b *Builder) SetProductionQueue(planet int, items []blocks.QueueItem) error {
+	if planet < 0 {
+		return fmt.Errorf("%w: planet %d", ErrInvalidOrder, planet)
+	}
+	for _, item := range items {
+		if item.Count < 1 || item.Count > maxQueueCount {
+			return fmt.Errorf("%w: count %d of a queue item (1-%d)", ErrInvalidOrder, item.Count, maxQueueCount)
+		}
+	}
+	order := blocks.NewProductionQueueOrder(planet, items)
+	for i, o := range b.orders {
+		if q, ok := queueOrder(o); ok && q.PlanetId == planet {
+			b.orders[i] = order
+			return nil
+		}
+	}
+	b.orders = append(b.orders, order)
+	return nil
+}
+
+// SetResearch sets the share of resources spent on research and the fields
+// researched now and next (blocks.ResearchField*; the next field may be
+// blocks.ResearchFieldSameField). It replaces any earlier research order of
+// the builder.
+func (b *Builder) SetResearch(budgetPercent, currentField, nextField int) error {
+	if budgetPercent < 0 || budgetPercent > 100 {
+		return fmt.Errorf("%w: research budget %d%%", ErrInvalidOrder, budgetPercent)
+	}
+	if currentField < blocks.ResearchFieldEnergy || currentField > blocks.ResearchFieldBiotechnology {
+		return fmt.Errorf("%w: research field %d", ErrInvalidOrder, currentField)
+	}
+	if nextField < blocks.ResearchFieldEnergy || nextField > blocks.ResearchFieldSameField {
+		return fmt.Errorf("%w: next research field %d", ErrInvalidOrder, nextField)
+	}
+	order := blocks.NewResearchChangeOrder(budgetPercent, currentField, nextField)
+	for i, o := range b.orders {
+		if o.BlockTypeID() == blocks.ResearchChangeBlockType {
+			b.orders[i] = order
+			return nil
+		}
+	}
+	b.orders = append(b.orders, order)
+	return nil
+}
+
+// SetBattlePlan creates or replaces a battle plan of the player.
+func (b *Builder) SetBattlePlan(plan BattlePlan) error {
+	switch {
+	case plan.ID < 0 || plan.ID > maxBattlePlan:
+		return fmt.Errorf("%w: battle plan %d", ErrInvalidOrder, plan.ID)
+	case plan.Name == "":
+		return fmt.Errorf("%w: battle plan %d has no name", ErrInvalidOrder, plan.ID)
+	case plan.Tactic < blocks.TacticDisengage || plan.Tactic > blocks.TacticMaximizeDamage:
+		return fmt.Errorf("%w: battle tactic %d", ErrInvalidOrder, plan.Tactic)
+	case plan.PrimaryTarget < blocks.TargetNone || plan.PrimaryTarget > blocks.TargetFreighters,
+		plan.SecondaryTarget < blocks.TargetNone || plan.SecondaryTarget > blocks.TargetFreighters:
+		return fmt.Errorf("%w: battle targets %d and %d", ErrInvalidOrder, plan.PrimaryTarget, plan.SecondaryTarget)
+	case plan.AttackWho < 0 || plan.AttackWho > maxAttackWho:
+		return fmt.Errorf("%w: attack policy %d", ErrInvalidOrder, plan.AttackWho)
+	}
+	order := &blocks.BattlePlanBlock{
+		OwnerPlayerId:   b.PlayerIndex(),
+		PlanId:          plan.ID,
+		Tactic:          plan.Tactic,
+		DumpCargo:       plan.DumpCargo,
+		PrimaryTarget:   plan.PrimaryTarget,
+		SecondaryTarget: plan.SecondaryTarget,
+		AttackWho:       plan.AttackWho,
+		Name:            plan.Name,
+	}
+	order.Refresh()
+	b.orders = append(b.orders, order)
+	return nil
+}
+
+// SetFleetBattlePlan gives a fleet one of the player's battle plans.
+func (b *Builder) SetFleetBattlePlan(fleet, plan int) error {
+	if err := checkFleet(fleet); err != nil {
+		return err
+	}
+	if plan < 0 || plan > maxBattlePlan {
+		return fmt.Errorf("%w: battle plan %d", ErrInvalidOrder, plan)
+	}
+	b.orders = append(b.orders, blocks.NewSetFleetBattlePlanOrder(fleet, plan))
+	return nil
+}
+
+// MergeFleets merges fleets into a fleet, which keeps its orders.
+func (b *Builder) MergeFleets(fleet int, others ...int) error {
+	if len(others) == 0 {
+		return fmt.Errorf("%w: no fleet to merge into fleet %d", ErrInvalidOrder, fleet+1)
+	}
+	for _, f := range append([]int{fleet}, others...) {
+		if err := checkFleet(f); err != nil {
+			return err
+		}
+	}
+	b.orders = append(b.orders, blocks.NewFleetsMergeOrder(fleet, others))
+	return nil
+}
+
+// SplitFleet splits ships off a fleet into a new fleet, numbered newFleet.
+// Stars! numbers new fleets with the lowest number the player does not use.
+// Counts are the ships of each design slot moving to the new fleet.
+func (b *Builder) SplitFleet(fleet, newFleet int, ships []blocks.ShipTransfer) error {
+	if err := checkFleet(fleet); err != nil {
+		return err
+	}
+	if err := checkFleet(newFleet); err != nil {
+		return err
+	}
+	if fleet == newFleet {
+		return fmt.Errorf("%w: fleet %d split into itself", ErrInvalidOrder, fleet+1)
+	}
+	leaving := make([]blocks.ShipTransfer, 0, len(ships))
+	for _, s := range ships {
+		if s.Count < 1 || s.DesignSlot < 0 || s.DesignSlot > 15 {
+			return fmt.Errorf("%w: %d ships of design slot %d", ErrInvalidOrder, s.Count, s.DesignSlot)
+		}
+		leaving = append(leaving, blocks.ShipTransfer{DesignSlot: s.DesignSlot, Count: -s.Count})
+	}
+	if len(leaving) == 0 {
+		return fmt.Errorf("%w: no ships split off fleet %d", ErrInvalidOrder, fleet+1)
+	}
+
+	// Counts are from the split fleet's side: the ships leave it
+	b.orders = append(b.orders,
+		blocks.NewFleetSplitOrder(fleet),
+		blocks.NewMoveShipsOrder(fleet, newFleet, leaving))
+	return nil
+}
+
+// waypointOrder returns the waypoint change of a waypoint, checked.
+func waypointOrder(fleet, index int, wp Waypoint) (*blocks.WaypointChangeTaskBlock, error) {
+	if err := checkFleet(fleet); err != nil {
+		return nil, err
+	}
+	switch {
+	case index < 0 || index > maxWaypoint:
+		return nil, fmt.Errorf("%w: waypoint %d", ErrInvalidOrder, index)
+	case wp.Warp < 0 || wp.Warp > blocks.WarpStargate:
+		return nil, fmt.Errorf("%w: warp %d", ErrInvalidOrder, wp.Warp)
+	case wp.Task < blocks.WaypointTaskNone || wp.Task > blocks.WaypointTaskTransfer:
+		return nil, fmt.Errorf("%w: waypoint task %d", ErrInvalidOrder, wp.Task)
+	case wp.X < 0 || wp.Y < 0:
+		return nil, fmt.Errorf("%w: position %d,%d", ErrInvalidOrder, wp.X, wp.Y)
+	}
+	switch wp.TargetType {
+	case blocks.WaypointTargetPlanet, blocks.WaypointTargetFleet,
+		blocks.WaypointTargetDeepSpace, blocks.WaypointTargetWormhole:
+	default:
+		return nil, fmt.Errorf("%w: waypoint target type %d", ErrInvalidOrder, wp.TargetType)
+	}
+	return &blocks.WaypointChangeTaskBlock{
+		FleetNumber:     fleet,
+		WaypointIndex:   index,
+		X:               wp.X,
+		Y:               wp.Y,
+		Target:          wp.Target,
+		Warp:            wp.Warp,
+		WaypointTask:    wp.Task,
+		ValidTask:       true,
+		TargetType:      wp.TargetType,
+		TransportOrders: wp.Transport,
+		PatrolRange:     wp.PatrolRange,
+	}, nil
+}
+
+// checkFleet checks a fleet number.
+func checkFleet(fleet int) error {
+	if fleet < 0 || fleet > maxFleetNumber {
+		return fmt.Errorf("%w: fleet %d", ErrInvalidOrder, fleet+1)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package orders
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/xfilereader"
+	"github.com/neper-stars/houston/parser"
+)
+
+func TestTypedOrders(t *testing.T) {
+	b, err := New(readTestFile(t, "game.m2"))
+	require.NoError(t, err)
+
+	wp := Waypoint{
+		X: 1249, Y: 1149, Target: 99,
+		TargetType: blocks.WaypointTargetPlanet,
+		Warp:       6,
+		Task:       blocks.WaypointTaskColonize,
+	}
+	require.NoError(t, b.AddWaypoint(0, 1, wp))
+	wp.Task = blocks.WaypointTaskNone
+	require.NoError(t, b.ChangeWaypoint(0, 1, wp))
+	require.NoError(t, b.DeleteWaypoint(2, 1))
+	require.NoError(t, b.SetProductionQueue(5, []blocks.QueueItem{{ItemId: 2, Count: 10, ItemType: blocks.ProductionItemTypeStandard}}))
+	require.NoError(t, b.SetResearch(15, blocks.ResearchFieldWeapons, blocks.ResearchFieldSameField))
+	require.NoError(t, b.SetResearch(20, blocks.ResearchFieldWeapons, blocks.ResearchFieldPropulsion))
+	require.NoError(t, b.SetBattlePlan(BattlePlan{
+		ID: 1, Name: "Snipe",
+		Tactic:          blocks.TacticMaximizeDamage,
+		PrimaryTarget:   blocks.TargetArmedShips,
+		SecondaryTarget: blocks.TargetAny,
+		AttackWho:       1,
+	}))
+	require.NoError(t, b.SetFleetBattlePlan(0, 1))
+	require.NoError(t, b.MergeFleets(0, 3, 4))
+	require.NoError(t, b.SplitFleet(1, 5, []blocks.ShipTransfer{{DesignSlot: 0, Count: 2}}))
+
+	// The later research order replaced the first
+	require.Len(t, b.Orders(), 10)
+
+	data, err := b.Commit()
+	require.NoError(t, err)
+	info, err := xfilereader.ReadBytes("game.x2", data)
+	require.NoError(t, err)
+	require.NoError(t, info.Validate())
+	require.Len(t, info.Orders, 11) // And SaveAndSubmit
+
+	add, ok := info.Orders[0].Block.(blocks.WaypointAddBlock)
+	require.True(t, ok, "got %T", info.Orders[0].Block)
+	assert.Equal(t, 1249, add.X)
+	assert.Equal(t, 99, add.Target)
+	assert.Equal(t, 6, add.Warp)
+	assert.Equal(t, blocks.WaypointTaskColonize, add.WaypointTask)
+
+	change, ok := info.Orders[1].Block.(blocks.WaypointChangeTaskBlock)
+	require.True(t, ok, "got %T", info.Orders[1].Block)
+	assert.Equal(t, blocks.WaypointTaskNone, change.WaypointTask)
+
+	del, ok := info.Orders[2].Block.(blocks.WaypointDeleteBlock)
+	require.True(t, ok, "got %T", info.Orders[2].Block)
+	assert.Equal(t, 2, del.FleetNumber)
+	assert.Equal(t, 1, del.WaypointNumber)
+
+	research, ok := info.Orders[4].Block.(blocks.ResearchChangeBlock)
+	require.True(t, ok, "got %T", info.Orders[4].Block)
+	assert.Equal(t, 20, research.BudgetPercent)
+	assert.Equal(t, blocks.ResearchFieldWeapons, research.CurrentField)
+	assert.Equal(t, blocks.ResearchFieldPropulsion, research.NextField)
+
+	plan, ok := info.Orders[5].Block.(blocks.BattlePlanBlock)
+	require.True(t, ok, "got %T", info.Orders[5].Block)
+	assert.Equal(t, 1, plan.OwnerPlayerId)
+	assert.Equal(t, "Snipe", plan.Name)
+	assert.Equal(t, blocks.TacticMaximizeDamage, plan.Tactic)
+
+	fleetPlan, ok := info.Orders[6].Block.(blocks.SetFleetBattlePlanBlock)
+	require.True(t, ok, "got %T", info.Orders[6].Block)
+	assert.Equal(t, 1, fleetPlan.BattlePlanIndex)
+
+	merge, ok := info.Orders[7].Block.(blocks.FleetsMergeBlock)
+	require.True(t, ok, "got %T", info.Orders[7].Block)
+	assert.Equal(t, []int{3, 4}, merge.FleetsToMerge)
+
+	move, ok := info.Orders[9].Block.(blocks.MoveShipsBlock)
+	require.True(t, ok, "got %T", info.Orders[9].Block)
+	assert.Equal(t, 1, move.DestFleetNumber)
+	assert.Equal(t, 5, move.SourceFleetNumber)
+	assert.Equal(t, []blocks.ShipTransfer{{DesignSlot: 0, Count: -2}}, move.ShipTransfers)
+}
+
+// A split written by the builder matches the one Stars! wrote.
+func TestSplitFleetMatchesStars(t *testing.T) {
+	data, err := os.ReadFile("../../../testdata/scenario-fleetsplit/game.x1")
+	require.NoError(t, err)
+	list, err := parser.FileData(data).BlockList()
+	require.NoError(t, err)
+
+	var split blocks.FleetSplitBlock
+	var move blocks.MoveShipsBlock
+	for _, block := range list {
+		switch bl := block.(type) {
+		case blocks.FleetSplitBlock:
+			split = bl
+		case blocks.MoveShipsBlock:
+			move = bl
+		}
+	}
+	require.NotEmpty(t, move.ShipTransfers)
+
+	leaving := make([]blocks.ShipTransfer, 0, len(move.ShipTransfers))
+	for _, s := range move.ShipTransfers {
+		leaving = append(leaving, blocks.ShipTransfer{DesignSlot: s.DesignSlot, Count: -s.Count})
+	}
+	b, err := New(readTestFile(t, "game.m2"))
+	require.NoError(t, err)
+	require.NoError(t, b.SplitFleet(split.FleetNumber, move.SourceFleetNumber, leaving))
+	require.Len(t, b.Orders(), 2)
+	assert.Equal(t, split.DecryptedData(), b.Orders()[0].DecryptedData())
+	assert.Equal(t, move.DecryptedData(), b.Orders()[1].DecryptedData())
+}
+
+func TestTypedOrdersRejectInvalid(t *testing.T) {
+	b, err := New(readTestFile(t, "game.m2"))
+	require.NoError(t, err)
+
+	wp := Waypoint{TargetType: blocks.WaypointTargetDeepSpace, Warp: 12}
+	assert.ErrorIs(t, b.AddWaypoint(0, 1, wp), ErrInvalidOrder)
+	assert.ErrorIs(t, b.AddWaypoint(512, 1, Waypoint{TargetType: blocks.WaypointTargetDeepSpace}), ErrInvalidOrder)
+	assert.ErrorIs(t, b.DeleteWaypoint(0, 0), ErrInvalidOrder)
+	assert.ErrorIs(t, b.SetProductionQueue(5, []blocks.QueueItem{{ItemId: 2}}), ErrInvalidOrder)
+	assert.ErrorIs(t, b.SetResearch(101, blocks.ResearchFieldEnergy, blocks.ResearchFieldEnergy), ErrInvalidOrder)
+	assert.ErrorIs(t, b.SetResearch(10, blocks.ResearchFieldSameField, blocks.ResearchFieldEnergy), ErrInvalidOrder)
+	assert.ErrorIs(t, b.SetBattlePlan(BattlePlan{ID: 5, Name: "Late"}), ErrInvalidOrder)
+	assert.ErrorIs(t, b.SetBattlePlan(BattlePlan{ID: 1}), ErrInvalidOrder)
+	assert.ErrorIs(t, b.SetFleetBattlePlan(0, 5), ErrInvalidOrder)
+	assert.ErrorIs(t, b.MergeFleets(0), ErrInvalidOrder)
+	assert.ErrorIs(t, b.SplitFleet(1, 1, []blocks.ShipTransfer{{Count: 1}}), ErrInvalidOrder)
+	assert.ErrorIs(t, b.SplitFleet(1, 2, nil), ErrInvalidOrder)
+	assert.Empty(t, b.Orders())
+}
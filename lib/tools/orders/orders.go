@@ -0,0 +1,291 @@
+// Package orders builds turn order (X) files.
+//
+// A Builder starts from the M file of a turn, or from an X file already
+// written for it, and collects orders as blocks. The orders stay a draft
+// until Commit encodes them into an X file; meanwhile the draft can be saved
+// to a JSON working file and resumed later, by another session of the same
+// program or after a bot restarts. Typed methods check and add the common
+// orders (waypoints, production queues, research, battle plans, fleet merges
+// and splits), and BuildTemplate queues named build programs at planets (see
+// Template).
+//
+// An X file written by Stars! also holds the registration of the player's
+// copy (FileHashBlock), which the builder keeps; M files do not hold it, so
+// orders built from an M file are written without it, as the store does.
+// Submitting the turn also sends the player's default production template
+// (ZipProdQueue): that of the X file the builder starts from, or else the one
+// the host last received.
+//
+//	b, err := orders.New(mFile)
+//	...
+//	err = b.AddWaypoint(fleet, 1, orders.Waypoint{X: x, Y: y, ...})
+//	err = b.SetResearch(15, blocks.ResearchFieldWeapons, blocks.ResearchFieldSameField)
+//	b.Add(plan.Order())
+//	err = b.SaveDraft(w) // resumed with orders.LoadDraft(r)
+//	...
+//	xFile, err := b.Commit()
+package orders
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/reproducible"
+	"github.com/neper-stars/houston/store"
+)
+
+var (
+	ErrNotTurnFile   = errors.New("orders are built from an M or X file")
+	ErrNotOrderBlock = errors.New("block is not a turn order")
+	ErrDraftVersion  = errors.New("unsupported draft version")
+)
+
+// DraftVersion is the version of the JSON working file written by SaveDraft.
+const DraftVersion = 1
+
+// Builder collects the orders of a player for a turn.
+type Builder struct {
+	header *blocks.FileHeader // Header of the X file
+	hash   blocks.Block       // Registration of the player's copy, if known
+	submit []byte             // Data of the save and submit block
+	orders []blocks.Block
+}
+
+// New returns a builder for the turn of an M file, without orders, or for
+// the turn of an X file, keeping its orders so that they can be amended.
+func New(turnFile []byte) (*Builder, error) {
+	blockList, err := parser.FileData(turnFile).BlockList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse blocks: %w", err)
+	}
+	if len(blockList) == 0 {
+		return nil, ErrNotTurnFile
+	}
+	header, ok := blockList[0].(blocks.FileHeader)
+	if !ok {
+		return nil, ErrNotTurnFile
+	}
+
+	switch header.FileType {
+	case blocks.FileTypeX:
+		b := &Builder{header: &header}
+		for _, block := range blockList[1:] {
+			switch {
+			case block.BlockTypeID() == blocks.FileHashBlockType:
+				b.hash = block
+			case block.BlockTypeID() == blocks.SaveAndSubmitBlockType:
+				b.submit = block.DecryptedData()
+			case isOrder(block.BlockTypeID()):
+				b.orders = append(b.orders, block)
+			}
+		}
+		return b, nil
+	case blocks.FileTypeM:
+		// The X file of a turn has the header of the M file, with its own
+		// salt, and no flag but the generation
+		data := header.Encode()
+		data[14] = blocks.FileTypeX
+		data[15] &= blocks.FlagGenMask
+		x, err := newHeader(data)
+		if err != nil {
+			return nil, err
+		}
+		x.SetSalt(reproducible.Salt(blocks.MaxSaltValue))
+		b := &Builder{header: x}
+		for _, block := range blockList {
+			if p, ok := block.(blocks.PlayerBlock); ok && p.PlayerNumber == x.PlayerIndex() {
+				b.submit = submitData(p.ZipProdDefault)
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("%w, not a %s file", ErrNotTurnFile, header.FileTypeName())
+}
+
+// GameID returns the ID of the game of the orders.
+func (b *Builder) GameID() uint32 {
+	return b.header.GameID
+}
+
+// Year returns the year of the orders.
+func (b *Builder) Year() int {
+	return b.header.Year()
+}
+
+// PlayerIndex returns the player giving the orders (0-15).
+func (b *Builder) PlayerIndex() int {
+	return b.header.PlayerIndex()
+}
+
+// Add appends orders, which the game carries out in order.
+func (b *Builder) Add(orders ...blocks.Block) error {
+	for _, order := range orders {
+		if !isOrder(order.BlockTypeID()) {
+			return fmt.Errorf("%w: %s", ErrNotOrderBlock, blocks.BlockTypeName(order.BlockTypeID()))
+		}
+	}
+	b.orders = append(b.orders, orders...)
+	return nil
+}
+
+// Orders returns the orders given so far.
+func (b *Builder) Orders() []blocks.Block {
+	return slices.Clone(b.orders)
+}
+
+// Remove removes the i-th order.
+func (b *Builder) Remove(i int) {
+	b.orders = slices.Delete(b.orders, i, i+1)
+}
+
+// Clear removes every order.
+func (b *Builder) Clear() {
+	b.orders = nil
+}
+
+// Commit returns the X file of the orders, submitting the turn.
+func (b *Builder) Commit() ([]byte, error) {
+	header := b.header
+	writer := store.NewFileWriter()
+	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), header.SharewareFlag())
+
+	result := writer.WriteHeader(header)
+	if b.hash != nil {
+		result = append(result, writer.WriteEncryptedBlock(blocks.FileHashBlockType, b.hash.DecryptedData())...)
+	}
+	for _, order := range b.orders {
+		result = append(result, writer.WriteEncryptedBlock(order.BlockTypeID(), order.DecryptedData())...)
+	}
+	result = append(result, writer.WriteEncryptedBlock(blocks.SaveAndSubmitBlockType, b.submit)...)
+	result = append(result, writer.WriteFooter(false, 0)...)
+	return result, nil
+}
+
+// draft is the JSON working file of a builder. The header and the orders are
+// kept as decrypted block data, so that the draft is encrypted only once
+// committed; the other fields are for people reading the file.
+type draft struct {
+	Version int          `json:"version"`
+	GameID  uint32       `json:"game_id"`
+	Year    int          `json:"year"`
+	Player  int          `json:"player"` // Player number (1-16)
+	Header  []byte       `json:"header"`
+	Hash    []byte       `json:"file_hash,omitempty"`
+	Submit  []byte       `json:"submit,omitempty"`
+	Orders  []draftOrder `json:"orders"`
+}
+
+type draftOrder struct {
+	Type   string             `json:"type"`
+	TypeID blocks.BlockTypeID `json:"type_id"`
+	Data   []byte             `json:"data"`
+}
+
+// SaveDraft writes the orders given so far as JSON.
+func (b *Builder) SaveDraft(w io.Writer) error {
+	d := draft{
+		Version: DraftVersion,
+		GameID:  b.GameID(),
+		Year:    b.Year(),
+		Player:  b.PlayerIndex() + 1,
+		Header:  b.header.Encode(),
+		Submit:  b.submit,
+		Orders:  make([]draftOrder, len(b.orders)),
+	}
+	if b.hash != nil {
+		d.Hash = b.hash.DecryptedData()
+	}
+	for i, order := range b.orders {
+		d.Orders[i] = draftOrder{
+			Type:   blocks.BlockTypeName(order.BlockTypeID()),
+			TypeID: order.BlockTypeID(),
+			Data:   order.DecryptedData(),
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// LoadDraft reads orders saved with SaveDraft.
+func LoadDraft(r io.Reader) (*Builder, error) {
+	var d draft
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return nil, fmt.Errorf("failed to decode draft: %w", err)
+	}
+	if d.Version != DraftVersion {
+		return nil, fmt.Errorf("%w: %d", ErrDraftVersion, d.Version)
+	}
+	header, err := newHeader(d.Header)
+	if err != nil {
+		return nil, err
+	}
+	if header.FileType != blocks.FileTypeX {
+		return nil, fmt.Errorf("%w, not a %s file", ErrNotTurnFile, header.FileTypeName())
+	}
+
+	var decrypted []parser.DecryptedBlock
+	if d.Hash != nil {
+		decrypted = append(decrypted, decryptedBlock(blocks.FileHashBlockType, d.Hash))
+	}
+	for i, order := range d.Orders {
+		if !isOrder(order.TypeID) {
+			return nil, fmt.Errorf("order %d: %w: %s", i+1, ErrNotOrderBlock, blocks.BlockTypeName(order.TypeID))
+		}
+		decrypted = append(decrypted, decryptedBlock(order.TypeID, order.Data))
+	}
+	blockList, err := parser.BuildBlockList(decrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode orders: %w", err)
+	}
+
+	b := &Builder{header: header, submit: d.Submit}
+	if d.Hash != nil {
+		b.hash, blockList = blockList[0], blockList[1:]
+	}
+	b.orders = blockList
+	return b, nil
+}
+
+func decryptedBlock(typeID blocks.BlockTypeID, data []byte) parser.DecryptedBlock {
+	return parser.DecryptedBlock{GenericBlock: blocks.GenericBlock{
+		Type:      typeID,
+		Size:      blocks.BlockSize(len(data)),
+		Decrypted: blocks.DecryptedData(data),
+	}}
+}
+
+// submitData returns the save and submit block data sending a production
+// template: the research flag, the item count and the items.
+func submitData(zipProd blocks.ZipProdQueue) []byte {
+	raw := zipProd.RawBytes
+	if len(raw) < 2 {
+		return nil
+	}
+	return slices.Clone(raw[:min(2+2*int(raw[1]), len(raw))])
+}
+
+// newHeader decodes a 16-byte file header.
+func newHeader(data []byte) (*blocks.FileHeader, error) {
+	return blocks.NewFileHeader(blocks.GenericBlock{
+		Type: blocks.FileHeaderBlockType,
+		Size: blocks.BlockSize(len(data)),
+		Data: blocks.BlockData(data),
+	})
+}
+
+// isOrder returns true for the blocks of an X file that carry orders, as
+// opposed to the blocks Commit writes itself.
+func isOrder(typeID blocks.BlockTypeID) bool {
+	switch typeID {
+	case blocks.FileHeaderBlockType, blocks.FileFooterBlockType,
+		blocks.FileHashBlockType, blocks.SaveAndSubmitBlockType:
+		return false
+	}
+	return true
+}
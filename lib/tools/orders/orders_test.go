@@ -0,0 +1,117 @@
+package orders
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/xfilereader"
+)
+
+const turnDir = "../../../testdata/scenario-orders/change-password/01-order-given/"
+
+func readTestFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(turnDir + name)
+	require.NoError(t, err)
+	return data
+}
+
+func TestNewFromXFileRoundTrip(t *testing.T) {
+	original := readTestFile(t, "game.x2")
+
+	b, err := New(original)
+	require.NoError(t, err)
+	assert.Equal(t, 1, b.PlayerIndex())
+	assert.Equal(t, 2408, b.Year())
+	require.Len(t, b.Orders(), 1)
+	assert.Equal(t, blocks.ChangePasswordBlockType, b.Orders()[0].BlockTypeID())
+
+	committed, err := b.Commit()
+	require.NoError(t, err)
+	assert.Equal(t, original, committed)
+}
+
+func TestDraftRoundTrip(t *testing.T) {
+	original := readTestFile(t, "game.x2")
+	b, err := New(original)
+	require.NoError(t, err)
+
+	var draft bytes.Buffer
+	require.NoError(t, b.SaveDraft(&draft))
+	assert.Contains(t, draft.String(), `"type": "ChangePassword"`)
+
+	resumed, err := LoadDraft(&draft)
+	require.NoError(t, err)
+	committed, err := resumed.Commit()
+	require.NoError(t, err)
+	assert.Equal(t, original, committed)
+}
+
+func TestNewFromMFile(t *testing.T) {
+	b, err := New(readTestFile(t, "game.m2"))
+	require.NoError(t, err)
+	assert.Empty(t, b.Orders())
+
+	// The player has no production template yet
+	assert.Equal(t, []byte{0, 0}, b.submit)
+
+	require.NoError(t, b.Add(blocks.NewMassDriverTargetOrder(3, 7, 9)))
+
+	// Resume from a draft before committing
+	var draft bytes.Buffer
+	require.NoError(t, b.SaveDraft(&draft))
+	b, err = LoadDraft(&draft)
+	require.NoError(t, err)
+
+	data, err := b.Commit()
+	require.NoError(t, err)
+	info, err := xfilereader.ReadBytes("game.x2", data)
+	require.NoError(t, err)
+	require.NoError(t, info.Validate())
+	assert.Equal(t, uint32(1098388264), info.GameID)
+	assert.Equal(t, 1, info.PlayerIndex)
+	assert.Equal(t, 2408, info.Year)
+	assert.True(t, info.IsSubmitted)
+
+	require.Len(t, info.Orders, 2)
+	order, ok := info.Orders[0].Block.(blocks.PlanetChangeBlock)
+	require.True(t, ok, "got %T", info.Orders[0].Block)
+	assert.Equal(t, 3, order.PlanetId)
+}
+
+func TestEditOrders(t *testing.T) {
+	b, err := New(readTestFile(t, "game.x2"))
+	require.NoError(t, err)
+
+	require.NoError(t, b.Add(blocks.NewMassDriverTargetOrder(3, 7, 9)))
+	b.Remove(0)
+	require.Len(t, b.Orders(), 1)
+	assert.Equal(t, blocks.PlanetChangeBlockType, b.Orders()[0].BlockTypeID())
+
+	b.Clear()
+	assert.Empty(t, b.Orders())
+}
+
+func TestAddRejectsFileBlocks(t *testing.T) {
+	b, err := New(readTestFile(t, "game.x2"))
+	require.NoError(t, err)
+
+	err = b.Add(blocks.GenericBlock{Type: blocks.SaveAndSubmitBlockType})
+	assert.ErrorIs(t, err, ErrNotOrderBlock)
+	assert.Len(t, b.Orders(), 1)
+}
+
+func TestNewRejectsOtherFiles(t *testing.T) {
+	_, err := New(readTestFile(t, "game.xy"))
+	assert.ErrorIs(t, err, ErrNotTurnFile)
+}
+
+func TestLoadDraftVersion(t *testing.T) {
+	_, err := LoadDraft(bytes.NewReader([]byte(`{"version": 99}`)))
+	assert.ErrorIs(t, err, ErrDraftVersion)
+}
@@ -0,0 +1,190 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+var (
+	ErrEmptyTemplate  = errors.New("template builds no ships")
+	ErrUnknownDesign  = errors.New("no ship design of that name")
+	ErrCannotBuild    = errors.New("design needs more tech")
+	ErrNotPlanetOwner = errors.New("planet is not owned by the player")
+)
+
+// maxQueueCount is the largest count of a production queue item (10 bits).
+const maxQueueCount = 1023
+
+// Template is a named build program, such as "3x Beamers + 1 Jammer escort":
+// ship designs and how many of each make up a fleet.
+type Template struct {
+	Name  string
+	Ships []TemplateShips
+}
+
+// TemplateShips is a part of a template.
+type TemplateShips struct {
+	Design string // Design name, or its first words followed by a note
+	Count  int
+}
+
+var templatePart = regexp.MustCompile(`^(\d+)\s*(?:[xX]\s)?\s*(.+)$`)
+
+// ParseTemplate parses a build program: parts joined by "+", each a count,
+// optionally followed by "x", and a design name ("3x Beamers", "1 Jammer").
+// A part without a count builds one ship.
+func ParseTemplate(name, program string) (*Template, error) {
+	t := &Template{Name: name}
+	for part := range strings.SplitSeq(program, "+") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		ships := TemplateShips{Design: part, Count: 1}
+		if m := templatePart.FindStringSubmatch(part); m != nil {
+			count, err := strconv.Atoi(m[1])
+			if err != nil || count < 1 || count > maxQueueCount {
+				return nil, fmt.Errorf("template %s: invalid count in %q", name, part)
+			}
+			ships = TemplateShips{Design: strings.TrimSpace(m[2]), Count: count}
+		}
+		t.Ships = append(t.Ships, ships)
+	}
+	if len(t.Ships) == 0 {
+		return nil, fmt.Errorf("template %s: %w", name, ErrEmptyTemplate)
+	}
+	return t, nil
+}
+
+// String returns the program of the template.
+func (t *Template) String() string {
+	parts := make([]string, len(t.Ships))
+	for i, s := range t.Ships {
+		parts[i] = fmt.Sprintf("%dx %s", s.Count, s.Design)
+	}
+	return strings.Join(parts, " + ")
+}
+
+// Expansion is a template expanded for a planet.
+type Expansion struct {
+	Items  []blocks.QueueItem // One per design
+	Fleets int                // Copies of the template queued
+	Cost   data.Cost          // Cost of one copy, as estimated by DesignEntity.GetCost
+}
+
+// Expand returns the production queue items building the template at a
+// planet, from the ship designs of the planet's owner that it can build.
+//
+// Design names are matched regardless of case; when no design has the whole
+// name of a part, its first words are tried, so that the rest can describe
+// the role of the ships ("1 Jammer escort"). With years above zero, the
+// template is queued as many times as the planet's resources pay for in
+// that many years, at least once.
+func (t *Template) Expand(gs *store.GameStore, planet *store.PlanetEntity, years int) (*Expansion, error) {
+	player, ok := gs.Player(planet.Owner)
+	if !ok || !planet.IsOwned() {
+		return nil, fmt.Errorf("%w: %s", ErrNotPlanetOwner, planet.Name)
+	}
+	designs := gs.ShipDesignsByOwner(planet.Owner)
+
+	e := &Expansion{Fleets: 1}
+	counts := make(map[int]int)
+	var order []int
+	for _, ships := range t.Ships {
+		d := findDesign(designs, ships.Design)
+		if d == nil {
+			return nil, fmt.Errorf("template %s: %w: %s", t.Name, ErrUnknownDesign, ships.Design)
+		}
+		if !d.CanBuildWith(player.Tech) {
+			return nil, fmt.Errorf("template %s: %w: %s", t.Name, ErrCannotBuild, d.Name)
+		}
+		if _, seen := counts[d.DesignNumber]; !seen {
+			order = append(order, d.DesignNumber)
+		}
+		counts[d.DesignNumber] += ships.Count
+
+		cost := d.GetCost()
+		e.Cost.Resources += cost.Resources * ships.Count
+		e.Cost.Ironium += cost.Ironium * ships.Count
+		e.Cost.Boranium += cost.Boranium * ships.Count
+		e.Cost.Germanium += cost.Germanium * ships.Count
+	}
+
+	if years > 0 && e.Cost.Resources > 0 {
+		e.Fleets = max(1, years*gs.CResourcesAtPlanet(planet, player)/e.Cost.Resources)
+	}
+	for _, number := range order {
+		e.Fleets = min(e.Fleets, maxQueueCount/counts[number])
+	}
+	if e.Fleets < 1 {
+		return nil, fmt.Errorf("template %s: more than %d ships of a design", t.Name, maxQueueCount)
+	}
+	for _, number := range order {
+		e.Items = append(e.Items, blocks.QueueItem{
+			ItemId:   number,
+			Count:    counts[number] * e.Fleets,
+			ItemType: blocks.ProductionItemTypeCustom,
+		})
+	}
+	return e, nil
+}
+
+// findDesign returns the design named name, or by its longest first words.
+func findDesign(designs []*store.DesignEntity, name string) *store.DesignEntity {
+	words := strings.Fields(name)
+	for n := len(words); n > 0; n-- {
+		candidate := strings.Join(words[:n], " ")
+		for _, d := range designs {
+			if strings.EqualFold(strings.TrimSpace(d.Name), candidate) {
+				return d
+			}
+		}
+	}
+	return nil
+}
+
+// BuildTemplate queues a template at a planet, after the items already in its
+// production queue or in an earlier order of the builder for the planet.
+func (b *Builder) BuildTemplate(gs *store.GameStore, planet *store.PlanetEntity, t *Template, years int) (*Expansion, error) {
+	if planet.Owner != b.PlayerIndex() {
+		return nil, fmt.Errorf("%w: %s", ErrNotPlanetOwner, planet.Name)
+	}
+	e, err := t.Expand(gs, planet, years)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, order := range b.orders {
+		if q, ok := queueOrder(order); ok && q.PlanetId == planet.PlanetNumber {
+			b.orders[i] = blocks.NewProductionQueueOrder(planet.PlanetNumber, append(q.Items, e.Items...))
+			return e, nil
+		}
+	}
+	var items []blocks.QueueItem
+	if queue, ok := gs.ProductionQueue(planet.PlanetNumber); ok {
+		for _, item := range queue.Items {
+			items = append(items, blocks.QueueItem(item))
+		}
+	}
+	b.orders = append(b.orders, blocks.NewProductionQueueOrder(planet.PlanetNumber, append(items, e.Items...)))
+	return e, nil
+}
+
+// queueOrder returns the production queue change of an order, whether it was
+// read from a file or built in memory.
+func queueOrder(order blocks.Block) (*blocks.ProductionQueueChangeBlock, bool) {
+	switch q := order.(type) {
+	case blocks.ProductionQueueChangeBlock:
+		return &q, true
+	case *blocks.ProductionQueueChangeBlock:
+		return q, true
+	}
+	return nil, false
+}
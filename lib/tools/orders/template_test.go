@@ -0,0 +1,120 @@
+package orders
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+// Player 1's M file, with the home world Hurl (planet 105)
+const queueTurn = "../../../testdata/scenario-production-queue-change/game.m1"
+
+func loadQueueTurn(t *testing.T) (*store.GameStore, *store.PlanetEntity) {
+	t.Helper()
+	gs := store.New()
+	require.NoError(t, gs.AddFileWithXY(queueTurn))
+	planet, ok := gs.Planet(105)
+	require.True(t, ok)
+	return gs, planet
+}
+
+func TestParseTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("escort", "3x Beamers + 1 Jammer escort + 2 x Armed Probe + Scout")
+	require.NoError(t, err)
+	assert.Equal(t, []TemplateShips{
+		{Design: "Beamers", Count: 3},
+		{Design: "Jammer escort", Count: 1},
+		{Design: "Armed Probe", Count: 2},
+		{Design: "Scout", Count: 1},
+	}, tmpl.Ships)
+	assert.Equal(t, "3x Beamers + 1x Jammer escort + 2x Armed Probe + 1x Scout", tmpl.String())
+
+	_, err = ParseTemplate("empty", " + ")
+	assert.ErrorIs(t, err, ErrEmptyTemplate)
+	_, err = ParseTemplate("none", "0x Beamers")
+	assert.Error(t, err)
+}
+
+func TestExpand(t *testing.T) {
+	gs, planet := loadQueueTurn(t)
+	tmpl, err := ParseTemplate("convoy", "3x armed probe + 1 Teamster escort")
+	require.NoError(t, err)
+
+	e, err := tmpl.Expand(gs, planet, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, e.Fleets)
+	assert.Equal(t, []blocks.QueueItem{
+		{ItemId: 0, Count: 3, ItemType: blocks.ProductionItemTypeCustom},
+		{ItemId: 3, Count: 1, ItemType: blocks.ProductionItemTypeCustom},
+	}, e.Items)
+	assert.Equal(t, 3*15+22, e.Cost.Resources)
+
+	// Hurl makes 2599 resources a year
+	e, err = tmpl.Expand(gs, planet, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2599/67, e.Fleets)
+	assert.Equal(t, 3*e.Fleets, e.Items[0].Count)
+	assert.Equal(t, e.Fleets, e.Items[1].Count)
+}
+
+func TestExpandMergesDesigns(t *testing.T) {
+	gs, planet := loadQueueTurn(t)
+	tmpl, err := ParseTemplate("probes", "2 Armed Probe + 1 Teamster + 1 Armed Probe")
+	require.NoError(t, err)
+
+	e, err := tmpl.Expand(gs, planet, 0)
+	require.NoError(t, err)
+	require.Len(t, e.Items, 2)
+	assert.Equal(t, 3, e.Items[0].Count)
+}
+
+func TestExpandErrors(t *testing.T) {
+	gs, planet := loadQueueTurn(t)
+
+	tmpl, err := ParseTemplate("unknown", "2 Beamers")
+	require.NoError(t, err)
+	_, err = tmpl.Expand(gs, planet, 0)
+	assert.ErrorIs(t, err, ErrUnknownDesign)
+
+	tmpl, err = ParseTemplate("cruisers", "2 Cruiser")
+	require.NoError(t, err)
+	_, err = tmpl.Expand(gs, planet, 0)
+	assert.ErrorIs(t, err, ErrCannotBuild)
+}
+
+func TestBuildTemplate(t *testing.T) {
+	gs, planet := loadQueueTurn(t)
+	data, err := os.ReadFile(queueTurn)
+	require.NoError(t, err)
+	b, err := New(data)
+	require.NoError(t, err)
+
+	tmpl, err := ParseTemplate("convoy", "3x Armed Probe + 1 Teamster")
+	require.NoError(t, err)
+	_, err = b.BuildTemplate(gs, planet, tmpl, 0)
+	require.NoError(t, err)
+	_, err = b.BuildTemplate(gs, planet, tmpl, 0)
+	require.NoError(t, err)
+
+	// One order for the planet: its queue, then the template twice
+	require.Len(t, b.Orders(), 1)
+	order, ok := queueOrder(b.Orders()[0])
+	require.True(t, ok)
+	assert.Equal(t, 105, order.PlanetId)
+	require.Len(t, order.Items, 12+4)
+	assert.Equal(t, blocks.QueueItem{ItemId: 3, Count: 1, ItemType: blocks.ProductionItemTypeCustom}, order.Items[15])
+
+	// The order is read back from the X file
+	xFile, err := b.Commit()
+	require.NoError(t, err)
+	resumed, err := New(xFile)
+	require.NoError(t, err)
+	order, ok = queueOrder(resumed.Orders()[0])
+	require.True(t, ok)
+	assert.Len(t, order.Items, 16)
+}
@@ -0,0 +1,121 @@
+// Package packets helps plan mineral packet launches between mass drivers.
+//
+// A packet arriving at or below the warp rating of the receiving planet's
+// mass driver is caught safely. A faster packet is only partially caught and
+// the rest strikes the planet. This matters most to Packet Physics players,
+// whose drivers outrun most receivers: the helpers here compute the fastest
+// warp a receiving planet catches safely and build the matching orders.
+//
+// Example usage:
+//
+//	plan := packets.PlanLaunch(gs, source, destination)
+//	if !plan.Safe {
+//	    fmt.Printf("%s only catches %.0f%% at warp %d\n",
+//	        plan.Destination.Name, plan.CaughtFraction*100, plan.Warp)
+//	}
+//	order := plan.Order() // PlanetChangeBlock for the X file
+package packets
+
+import (
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+// DriverWarp returns the warp rating of the mass driver on a planet's
+// starbase, or 0 if the planet has no starbase or its design has no driver.
+func DriverWarp(gs *store.GameStore, planet *store.PlanetEntity) int {
+	if !planet.HasStarbase || planet.Owner < 0 {
+		return 0
+	}
+	design, ok := gs.StarbaseDesign(planet.Owner, planet.StarbaseDesign)
+	if !ok {
+		return 0
+	}
+	return design.GetMassDriverWarp()
+}
+
+// SafeCatchWarp returns the fastest packet warp the receiving planet catches
+// without damage, or 0 if it cannot catch packets at all.
+func SafeCatchWarp(gs *store.GameStore, receiver *store.PlanetEntity) int {
+	return DriverWarp(gs, receiver)
+}
+
+// CatchFraction returns the fraction of a packet caught by a receiver whose
+// safe catch warp is catchWarp. Packets at or below that warp are caught
+// whole; faster packets are caught in proportion to the square of the speeds.
+func CatchFraction(packetWarp, catchWarp int) float64 {
+	if catchWarp <= 0 {
+		return 0
+	}
+	if packetWarp <= catchWarp {
+		return 1
+	}
+	return float64(catchWarp*catchWarp) / float64(packetWarp*packetWarp)
+}
+
+// Plan is a suggested packet launch between two planets.
+type Plan struct {
+	Source      *store.PlanetEntity
+	Destination *store.PlanetEntity
+
+	DriverWarp int // Rating of the source mass driver (0 = cannot fling)
+	CatchWarp  int // Safe catch warp of the destination (0 = cannot catch)
+
+	// Warp is the suggested fling warp: the fastest warp the destination
+	// catches safely, or the source driver rating if it catches nothing.
+	Warp int
+
+	Safe           bool    // True if the packet is caught whole at Warp
+	CaughtFraction float64 // Fraction of the packet caught at Warp
+}
+
+// PlanLaunch suggests the fling warp for packets from source to destination.
+func PlanLaunch(gs *store.GameStore, source, destination *store.PlanetEntity) Plan {
+	p := Plan{
+		Source:      source,
+		Destination: destination,
+		DriverWarp:  DriverWarp(gs, source),
+		CatchWarp:   SafeCatchWarp(gs, destination),
+	}
+	if p.DriverWarp == 0 {
+		return p
+	}
+
+	p.Warp = p.DriverWarp
+	if p.CatchWarp > 0 {
+		p.Warp = min(p.DriverWarp, p.CatchWarp)
+	}
+	p.CaughtFraction = CatchFraction(p.Warp, p.CatchWarp)
+	p.Safe = p.CaughtFraction == 1
+	return p
+}
+
+// Order returns the planet change order setting the source mass driver to
+// the destination at the planned warp. The order keeps the source planet's
+// research contribution setting but not its fleet route; set
+// RouteDestinationId and call Refresh if the planet has one.
+func (p Plan) Order() *blocks.PlanetChangeBlock {
+	order := blocks.NewMassDriverTargetOrder(p.Source.PlanetNumber, p.Destination.PlanetNumber, p.Warp)
+	order.ContributeLeftover = p.Source.NoResearch
+	order.Refresh()
+	return order
+}
+
+// TerraformChances returns how many chances an arriving packet gives a
+// Packet Physics owner of terraforming the receiving planet: one for every
+// data.PacketTerraformMass kT the receiver does not catch. Other races get
+// none.
+func TerraformChances(gs *store.GameStore, packet *store.ObjectEntity, receiver *store.PlanetEntity) int {
+	player, ok := gs.Player(packet.Owner)
+	if !ok {
+		return 0
+	}
+	prt := data.GetPRT(player.PRT)
+	if prt == nil {
+		return 0
+	}
+	mass := packet.Ironium + packet.Boranium + packet.Germanium
+	caught := CatchFraction(packet.PacketWarp(), SafeCatchWarp(gs, receiver))
+	return prt.PacketTerraformChances(int(float64(mass) * (1 - caught)))
+}
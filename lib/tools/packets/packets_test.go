@@ -0,0 +1,96 @@
+package packets
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+func TestCatchFraction(t *testing.T) {
+	assert.Equal(t, 1.0, CatchFraction(7, 7))
+	assert.Equal(t, 1.0, CatchFraction(5, 9))
+	assert.InDelta(t, 49.0/100.0, CatchFraction(10, 7), 0.0001)
+	assert.Zero(t, CatchFraction(7, 0), "no mass driver catches nothing")
+}
+
+func loadScenario(t *testing.T) *store.GameStore {
+	t.Helper()
+	fileData, err := os.ReadFile("../../../testdata/scenario-packet-driver-destination/game.m1")
+	require.NoError(t, err)
+
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game.m1", fileData))
+	return gs
+}
+
+func TestPlanLaunch(t *testing.T) {
+	gs := loadScenario(t)
+
+	// Hurl (105) and Purgatory (109) both have a "Starbase MD" with a Mass Driver 7
+	hurl, ok := gs.Planet(105)
+	require.True(t, ok)
+	purgatory, ok := gs.Planet(109)
+	require.True(t, ok)
+
+	plan := PlanLaunch(gs, hurl, purgatory)
+	assert.Equal(t, 7, plan.DriverWarp)
+	assert.Equal(t, 7, plan.CatchWarp)
+	assert.Equal(t, 7, plan.Warp)
+	assert.True(t, plan.Safe)
+
+	order := plan.Order()
+	target, ok := order.MassDriverTarget()
+	require.True(t, ok)
+	assert.Equal(t, 109, target)
+	assert.Equal(t, 7, order.PacketWarp())
+	assert.Equal(t, 105, order.PlanetId)
+}
+
+func TestPlanLaunch_NoCatcher(t *testing.T) {
+	gs := loadScenario(t)
+
+	hurl, ok := gs.Planet(105)
+	require.True(t, ok)
+	var target *store.PlanetEntity
+	for _, p := range gs.AllPlanets() {
+		if !p.HasStarbase {
+			target = p
+			break
+		}
+	}
+	require.NotNil(t, target)
+
+	plan := PlanLaunch(gs, hurl, target)
+	assert.Equal(t, 7, plan.Warp, "falls back to the source driver rating")
+	assert.Zero(t, plan.CatchWarp)
+	assert.False(t, plan.Safe)
+	assert.Zero(t, plan.CaughtFraction)
+}
+
+func TestTerraformChances(t *testing.T) {
+	fileData, err := os.ReadFile("../../../testdata/scenario-mineral-packet/game.m1")
+	require.NoError(t, err)
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game.m1", fileData))
+
+	var packet *store.ObjectEntity
+	for _, o := range gs.Objects.All() {
+		if o.IsPacket() {
+			packet = o
+		}
+	}
+	require.NotNil(t, packet)
+	target := &store.PlanetEntity{PlanetNumber: packet.DestinationPlanetID, Owner: -1}
+	assert.Zero(t, TerraformChances(gs, packet, target), "the owner is not PP")
+
+	player, ok := gs.Player(packet.Owner)
+	require.True(t, ok)
+	player.PRT = blocks.PRTPacketPhysics
+	mass := packet.Ironium + packet.Boranium + packet.Germanium
+	assert.Equal(t, mass/100, TerraformChances(gs, packet, target), "nothing is caught without a mass driver")
+}
@@ -0,0 +1,134 @@
+// Package planetnames renames the planets of a new universe from a name
+// dictionary: the classic Stars! list, or a themed list supplied by the host.
+//
+// Stars! files do not store planet names, only their index in the name
+// table built into the game, so every client can display them. A dictionary
+// is therefore a selection of that table: a themed list keeps the names
+// fitting its theme, and names outside the table are rejected.
+//
+// Dictionaries are plain text, one name per line; blank lines and lines
+// starting with '#' are ignored:
+//
+//	# Constellations
+//	Andromeda
+//	Aquarius
+//	Cassiopeia
+//
+// Example usage:
+//
+//	dict, _ := planetnames.Load("constellations.txt")
+//	renames, err := planetnames.Apply(gs, dict, 42)
+//	xy, err := gs.GenerateXYFile()
+package planetnames
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+// ErrTooFewNames is returned when a dictionary has fewer names than the
+// universe has planets.
+var ErrTooFewNames = errors.New("not enough names in dictionary")
+
+// Dictionary is a list of planet names.
+type Dictionary struct {
+	Name  string
+	Names []string // Spelled as in the Stars! name table
+}
+
+// Rename is a planet given a new name.
+type Rename struct {
+	Planet  int // Planet number (0-based)
+	OldName string
+	NewName string
+}
+
+// Classic returns the complete Stars! planet name list.
+func Classic() *Dictionary {
+	d := &Dictionary{Name: "classic", Names: make([]string, 0, len(data.PlanetNames))}
+	for id := range uint32(len(data.PlanetNames)) {
+		d.Names = append(d.Names, data.PlanetNames[id])
+	}
+	return d
+}
+
+// Parse reads a dictionary, one name per line. Names are matched against the
+// Stars! name table ignoring case; unknown and repeated names are errors.
+func Parse(name string, r io.Reader) (*Dictionary, error) {
+	d := &Dictionary{Name: name}
+	seen := make(map[string]bool)
+	var unknown []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, ok := data.PlanetNameID(line)
+		if !ok {
+			unknown = append(unknown, line)
+			continue
+		}
+		canonical := data.PlanetNames[id]
+		if seen[canonical] {
+			return nil, fmt.Errorf("%s: %s listed twice", name, canonical)
+		}
+		seen[canonical] = true
+		d.Names = append(d.Names, canonical)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dictionary %s: %w", name, err)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%s: %w: %s", name, store.ErrUnknownPlanetName, strings.Join(unknown, ", "))
+	}
+	return d, nil
+}
+
+// Load reads a dictionary file, named after the file.
+func Load(path string) (*Dictionary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dictionary: %w", err)
+	}
+	defer f.Close()
+	return Parse(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), f)
+}
+
+// Apply renames every planet of the store with names drawn from the
+// dictionary. The draw is shuffled by the seed, so the same seed gives the
+// same names. The XY file must be regenerated afterwards.
+func Apply(gs *store.GameStore, d *Dictionary, seed uint64) ([]Rename, error) {
+	count := int(gs.PlanetCount)
+	if count == 0 {
+		return nil, fmt.Errorf("no universe loaded, an XY file is needed")
+	}
+	if len(d.Names) < count {
+		return nil, fmt.Errorf("%w: %s has %d names for %d planets", ErrTooFewNames, d.Name, len(d.Names), count)
+	}
+
+	names := slices.Clone(d.Names)
+	rng := rand.New(rand.NewPCG(seed, uint64(gs.GameID)))
+	rng.Shuffle(len(names), func(i, j int) { names[i], names[j] = names[j], names[i] })
+
+	renames := make([]Rename, 0, count)
+	byPlanet := make(map[int]string, count)
+	for planet := range count {
+		renames = append(renames, Rename{Planet: planet, OldName: gs.PlanetName(planet), NewName: names[planet]})
+		byPlanet[planet] = names[planet]
+	}
+	if err := gs.RenamePlanets(byPlanet); err != nil {
+		return nil, err
+	}
+	return renames, nil
+}
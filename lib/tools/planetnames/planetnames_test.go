@@ -0,0 +1,76 @@
+package planetnames
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+func loadUniverse(t *testing.T) *store.GameStore {
+	t.Helper()
+	raw, err := os.ReadFile("../../../testdata/scenario-map/history/game-2400.xy")
+	require.NoError(t, err)
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game-2400.xy", raw))
+	return gs
+}
+
+func TestClassic(t *testing.T) {
+	d := Classic()
+	assert.Len(t, d.Names, len(data.PlanetNames))
+	assert.Equal(t, data.PlanetNames[0], d.Names[0])
+}
+
+func TestParse(t *testing.T) {
+	d, err := Parse("myth", strings.NewReader("# Mythology\n\nabel\n  Accord \n"))
+	require.NoError(t, err)
+	assert.Equal(t, "myth", d.Name)
+	assert.Equal(t, []string{"Abel", "Accord"}, d.Names, "spelled as in the name table")
+
+	_, err = Parse("bad", strings.NewReader("Abel\nNot A Planet\n"))
+	assert.ErrorIs(t, err, store.ErrUnknownPlanetName)
+	assert.ErrorContains(t, err, "Not A Planet")
+
+	_, err = Parse("twice", strings.NewReader("Abel\nABEL\n"))
+	assert.ErrorContains(t, err, "listed twice")
+}
+
+func TestApply(t *testing.T) {
+	gs := loadUniverse(t)
+	count := int(gs.PlanetCount)
+
+	_, err := Apply(gs, &Dictionary{Name: "short", Names: Classic().Names[:count-1]}, 1)
+	assert.ErrorIs(t, err, ErrTooFewNames)
+
+	renames, err := Apply(gs, Classic(), 7)
+	require.NoError(t, err)
+	require.Len(t, renames, count)
+
+	used := make(map[string]bool)
+	for _, r := range renames {
+		assert.Equal(t, r.NewName, gs.PlanetName(r.Planet))
+		assert.False(t, used[r.NewName], "names are unique")
+		used[r.NewName] = true
+	}
+
+	// The same seed draws the same names
+	again, err := Apply(loadUniverse(t), Classic(), 7)
+	require.NoError(t, err)
+	for i := range renames {
+		assert.Equal(t, renames[i].NewName, again[i].NewName)
+	}
+
+	xy, err := gs.GenerateXYFile()
+	require.NoError(t, err)
+	gs2 := store.New()
+	require.NoError(t, gs2.AddFile("game-2400.xy", xy))
+	for _, r := range renames {
+		assert.Equal(t, r.NewName, gs2.PlanetName(r.Planet))
+	}
+}
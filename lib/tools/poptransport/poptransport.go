@@ -0,0 +1,278 @@
+// Package poptransport plans colonist shipments between a player's planets.
+//
+// Population grows fastest on planets filled to a quarter of their
+// capacity: above that crowding slows growth down, and planets over
+// capacity lose colonists every year. Moving the colonists of overcrowded
+// worlds to green planets below a quarter of their capacity turns those
+// losses into growth. Plan matches the surplus of the crowded planets with
+// the room of the greens, best growth per year of travel first, and gives
+// each shipment to an idle freighter of the player, within its cargo
+// capacity.
+//
+// The shipments become two transport waypoints of the freighter: loading
+// the colonists at the crowded planet, unloading them at the green one.
+//
+//	plan, err := poptransport.Plan(gs, player, poptransport.Options{})
+//	...
+//	for _, s := range plan.Shipments {
+//	    fmt.Println(s)
+//	}
+//	err = plan.Apply(builder) // an orders.Builder for the player's turn
+package poptransport
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/orders"
+	"github.com/neper-stars/houston/store"
+)
+
+var (
+	ErrNoPlayer   = errors.New("player not found")
+	ErrNoRaceData = errors.New("the player's race is not known from this file")
+)
+
+// Options tunes a plan. The zero value gives the defaults.
+type Options struct {
+	// Warp of the freighters, 6 when zero
+	Warp int
+	// MaxYears is the longest trip planned, from the freighter to the
+	// destination, in years; 0 for no limit
+	MaxYears int
+	// SourceFill is the share of capacity kept on the crowded planets,
+	// 1 when zero: only colonists over capacity are moved
+	SourceFill float64
+	// DestinationFill is the share of capacity the green planets are filled
+	// to, 0.25 when zero: the most that grows at the full rate
+	DestinationFill float64
+}
+
+func (o Options) withDefaults() Options {
+	if o.Warp == 0 {
+		o.Warp = 6
+	}
+	if o.SourceFill == 0 {
+		o.SourceFill = 1
+	}
+	if o.DestinationFill == 0 {
+		o.DestinationFill = 0.25
+	}
+	return o
+}
+
+// Shipment is a load of colonists carried from a planet to another.
+type Shipment struct {
+	Fleet     *store.FleetEntity
+	From, To  *store.PlanetEntity
+	Colonists int64   // Colonists carried, a multiple of 100 (1 kT)
+	Years     int     // Years of travel, from the freighter's position to the destination
+	Growth    float64 // Colonists gained in the first year after delivery
+}
+
+func (s Shipment) String() string {
+	return fmt.Sprintf("%s: %d colonists from %s to %s (%d years, %+.0f colonists a year)",
+		s.Fleet.Name(), s.Colonists, s.From.Name, s.To.Name, s.Years, s.Growth)
+}
+
+// Result is a plan of shipments.
+type Result struct {
+	Shipments []Shipment
+
+	// Colonists left over capacity, and room left on the greens, when
+	// freighters run out
+	Surplus, Room int64
+	warp          int
+}
+
+// Plan plans the colonist shipments of a player (0-15).
+func Plan(gs *store.GameStore, player int, opts Options) (*Result, error) {
+	opts = opts.withDefaults()
+	p, ok := gs.Player(player)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrNoPlayer, player+1)
+	}
+	if !p.HasFullData {
+		return nil, ErrNoRaceData
+	}
+
+	var sources, destinations []*planetState
+	for _, planet := range gs.PlanetsByOwner(player) {
+		capacity := float64(gs.MaxPopulation(planet, p))
+		hab := gs.PctPlanetDesirability(planet, p)
+		if capacity <= 0 || hab <= 0 {
+			continue
+		}
+		s := &planetState{planet: planet, pop: planet.Population, capacity: capacity, hab: hab}
+		if surplus := float64(planet.Population) - opts.SourceFill*capacity; surplus >= 100 {
+			s.surplus = int64(surplus) / 100 * 100
+			sources = append(sources, s)
+		} else if room := opts.DestinationFill*capacity - float64(planet.Population); room >= 100 {
+			s.room = int64(room) / 100 * 100
+			destinations = append(destinations, s)
+		}
+	}
+
+	freighters := idleFreighters(gs, player)
+	result := &Result{warp: opts.Warp}
+	speed := float64(opts.Warp * opts.Warp)
+	for {
+		best, bestScore := Shipment{}, 0.0
+		for _, f := range freighters {
+			if f.used {
+				continue
+			}
+			for _, src := range sources {
+				for _, dst := range destinations {
+					if src.surplus < 100 || dst.room < 100 {
+						continue
+					}
+					years := int(math.Ceil((distance(f.fleet.X, f.fleet.Y, src.planet.X, src.planet.Y) +
+						distance(src.planet.X, src.planet.Y, dst.planet.X, dst.planet.Y)) / speed))
+					if opts.MaxYears > 0 && years > opts.MaxYears {
+						continue
+					}
+					colonists := min(src.surplus, dst.room, f.capacity*100)
+					gain := growth(src, p.GrowthRate, -colonists) + growth(dst, p.GrowthRate, colonists)
+					score := gain / float64(max(years, 1))
+					if gain > 0 && score > bestScore {
+						bestScore = score
+						best = Shipment{Fleet: f.fleet, From: src.planet, To: dst.planet,
+							Colonists: colonists, Years: years, Growth: gain}
+					}
+				}
+			}
+		}
+		if best.Fleet == nil {
+			break
+		}
+		result.Shipments = append(result.Shipments, best)
+		for _, f := range freighters {
+			f.used = f.used || f.fleet == best.Fleet
+		}
+		for _, src := range sources {
+			if src.planet == best.From {
+				src.surplus -= best.Colonists
+				src.pop -= best.Colonists
+			}
+		}
+		for _, dst := range destinations {
+			if dst.planet == best.To {
+				dst.room -= best.Colonists
+				dst.pop += best.Colonists
+			}
+		}
+	}
+
+	for _, src := range sources {
+		result.Surplus += max(src.surplus, 0)
+	}
+	for _, dst := range destinations {
+		result.Room += max(dst.room, 0)
+	}
+	return result, nil
+}
+
+// Apply adds the waypoints of the shipments to the orders of the player:
+// loading at the crowded planet, then unloading at the green one. The
+// load is the task of waypoint 0 when the freighter is already there.
+func (r *Result) Apply(b *orders.Builder) error {
+	for _, s := range r.Shipments {
+		load := transportWaypoint(s.From, r.warp, blocks.TransportTaskLoadExactly, int(s.Colonists/100))
+		unload := transportWaypoint(s.To, r.warp, blocks.TransportTaskUnloadAll, 0)
+		next := 1
+		if s.Fleet.X == s.From.X && s.Fleet.Y == s.From.Y {
+			if err := b.ChangeWaypoint(s.Fleet.FleetNumber, 0, load); err != nil {
+				return err
+			}
+		} else {
+			if err := b.AddWaypoint(s.Fleet.FleetNumber, next, load); err != nil {
+				return err
+			}
+			next++
+		}
+		if err := b.AddWaypoint(s.Fleet.FleetNumber, next, unload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func transportWaypoint(planet *store.PlanetEntity, warp, action, value int) orders.Waypoint {
+	wp := orders.Waypoint{
+		X: planet.X, Y: planet.Y,
+		Target: planet.PlanetNumber, TargetType: blocks.WaypointTargetPlanet,
+		Warp: warp, Task: blocks.WaypointTaskTransport,
+	}
+	wp.Transport[blocks.CargoColonists] = blocks.TransportOrder{Action: action, Value: value}
+	return wp
+}
+
+// planetState is a planet of the player as shipments are planned.
+type planetState struct {
+	planet        *store.PlanetEntity
+	pop           int64
+	capacity      float64
+	hab           int
+	surplus, room int64
+}
+
+// growth returns the change of the yearly growth of a planet when its
+// population changes by delta colonists, following the growth rules of
+// Stars!: full rate up to a quarter of capacity, slowed by crowding above,
+// and losses of 4% per 100% over capacity.
+func growth(s *planetState, rate int, delta int64) float64 {
+	return yearlyGrowth(float64(s.pop+delta), s.capacity, s.hab, rate) -
+		yearlyGrowth(float64(s.pop), s.capacity, s.hab, rate)
+}
+
+func yearlyGrowth(pop, capacity float64, hab, rate int) float64 {
+	if pop > capacity {
+		return -pop * min((pop/capacity-1)*0.04, 0.12)
+	}
+	g := pop * float64(rate) / 100 * float64(hab) / 100
+	if pop > capacity/4 {
+		crowding := 1 - pop/capacity
+		g *= 16.0 / 9.0 * crowding * crowding
+	}
+	return g
+}
+
+// freighter is a fleet of the player free to carry colonists.
+type freighter struct {
+	fleet    *store.FleetEntity
+	capacity int64 // kT
+	used     bool
+}
+
+// idleFreighters returns the fleets of a player with cargo holds, no
+// orders beyond their position and no colonists aboard, largest first.
+func idleFreighters(gs *store.GameStore, player int) []*freighter {
+	var result []*freighter
+	for _, fleet := range gs.FleetsByOwner(player) {
+		if len(fleet.Waypoints) > 1 || fleet.GetCargo().Population > 0 {
+			continue
+		}
+		var capacity int64
+		for _, ships := range fleet.GetDesigns(gs) {
+			capacity += int64(ships.Design.GetCargoCapacity()) * int64(ships.Count)
+		}
+		if capacity > 0 {
+			result = append(result, &freighter{fleet: fleet, capacity: capacity})
+		}
+	}
+	slices.SortStableFunc(result, func(a, b *freighter) int {
+		if a.capacity != b.capacity {
+			return int(b.capacity - a.capacity)
+		}
+		return a.fleet.FleetNumber - b.fleet.FleetNumber
+	})
+	return result
+}
+
+func distance(x1, y1, x2, y2 int) float64 {
+	return math.Hypot(float64(x2-x1), float64(y2-y1))
+}
@@ -0,0 +1,98 @@
+package poptransport
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/orders"
+	"github.com/neper-stars/houston/store"
+)
+
+const mFile = "../../../testdata/scenario-singleplayer/2499/Game.m1"
+
+// crowdedGame loads a game where Basket Case is over capacity and
+// Gladiolus nearly empty.
+func crowdedGame(t *testing.T) *store.GameStore {
+	t.Helper()
+	gs := store.New()
+	require.NoError(t, gs.AddFileWithXY(mFile))
+	for _, planet := range gs.PlanetsByOwner(0) {
+		switch planet.Name {
+		case "Basket Case":
+			planet.SetPopulation(1600000)
+		case "Gladiolus":
+			planet.SetPopulation(100000)
+		}
+	}
+	return gs
+}
+
+func TestPlan(t *testing.T) {
+	gs := crowdedGame(t)
+	result, err := Plan(gs, 0, Options{})
+	require.NoError(t, err)
+
+	require.Len(t, result.Shipments, 2)
+	first := result.Shipments[0]
+	assert.Equal(t, "Large Freighter #11", first.Fleet.Name(), "the largest freighter goes first")
+	assert.Equal(t, "Basket Case", first.From.Name)
+	assert.Equal(t, "Gladiolus", first.To.Name)
+	assert.Equal(t, int64(120000), first.Colonists, "a full hold of 1200 kT")
+	assert.Positive(t, first.Growth)
+
+	// Gladiolus is filled to a quarter of its capacity
+	var carried int64
+	for _, s := range result.Shipments {
+		carried += s.Colonists
+	}
+	player, _ := gs.Player(0)
+	assert.Equal(t, int64(gs.MaxPopulation(first.To, player)/4-100000)/100*100, carried)
+	assert.Zero(t, result.Room)
+	assert.Positive(t, result.Surplus, "colonists are left over capacity when freighters run out")
+}
+
+func TestPlan_Options(t *testing.T) {
+	gs := crowdedGame(t)
+	result, err := Plan(gs, 0, Options{MaxYears: 2})
+	require.NoError(t, err)
+	assert.Empty(t, result.Shipments, "Gladiolus is too far at warp 6")
+
+	result, err = Plan(gs, 0, Options{Warp: 9, MaxYears: 4})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Shipments)
+
+	_, err = Plan(gs, 5, Options{})
+	assert.ErrorIs(t, err, ErrNoPlayer)
+}
+
+func TestApply(t *testing.T) {
+	gs := crowdedGame(t)
+	result, err := Plan(gs, 0, Options{})
+	require.NoError(t, err)
+
+	m, err := os.ReadFile(mFile)
+	require.NoError(t, err)
+	b, err := orders.New(m)
+	require.NoError(t, err)
+	require.NoError(t, result.Apply(b))
+
+	list := b.Orders()
+	require.Len(t, list, 4, "a load and an unload waypoint per shipment")
+	load, ok := list[0].(*blocks.WaypointAddBlock)
+	require.True(t, ok)
+	assert.Equal(t, result.Shipments[0].Fleet.FleetNumber, load.FleetNumber)
+	assert.Equal(t, result.Shipments[0].From.PlanetNumber, load.Target)
+	assert.Equal(t, blocks.TransportOrder{Action: blocks.TransportTaskLoadExactly, Value: 1200},
+		load.TransportOrders[blocks.CargoColonists])
+	unload, ok := list[1].(*blocks.WaypointAddBlock)
+	require.True(t, ok)
+	assert.Equal(t, 2, unload.WaypointIndex)
+	assert.Equal(t, blocks.TransportTaskUnloadAll, unload.TransportOrders[blocks.CargoColonists].Action)
+
+	_, err = b.Commit()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,152 @@
+// Package publish generates a browsable static website from a game archive.
+//
+// The site has an index page with a score chart, the final standings and a
+// list of turns, plus one page per turn holding the galaxy map, the scores
+// and the battles reported that year. Pages are plain HTML with inline
+// styles and SVG maps, so the output directory can be hosted as is for
+// post-game retrospectives.
+//
+// Example usage:
+//
+//	archive := publish.Build(stores) // one GameStore per turn
+//	if err := archive.Write("site", nil); err != nil {
+//	    log.Fatal(err)
+//	}
+package publish
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+// Archive is a whole game, turn by turn.
+type Archive struct {
+	GameID   uint32
+	GameName string
+	Turns    []*Turn   // Sorted by year
+	Players  []*Player // Final standings, best score first
+}
+
+// Turn holds what the site shows for one year.
+type Turn struct {
+	Year    int
+	Scores  map[int]int // Player index (0-15) -> score, for players whose score is known
+	Battles []Battle
+
+	store *store.GameStore
+}
+
+// Player is a player's final standing.
+type Player struct {
+	Index     int // Player index (0-15)
+	Name      string
+	Score     int
+	Rank      int
+	Planets   int
+	Starbases int
+	Ships     int
+	HasScore  bool // Whether a score was ever reported for the player
+}
+
+// Battle is a battle as reported to one of the participants.
+type Battle struct {
+	Location    string
+	Reporter    int // Player index of the file owner that reported the battle
+	Enemy       int // Player index of the opponent
+	Forces      int // Reporter's stacks
+	EnemyForces int
+	Losses      int // Ships lost by the reporter
+	EnemyLosses int
+}
+
+// Build assembles an archive from one GameStore per turn (see
+// store.GameStore.Turn). Stores are sorted by year.
+func Build(stores []*store.GameStore) *Archive {
+	a := &Archive{}
+	final := make(map[int]*Player)
+
+	// Process turns in order so that final standings come from the last one
+	stores = slices.Clone(stores)
+	slices.SortStableFunc(stores, func(x, y *store.GameStore) int { return cmp.Compare(x.Turn, y.Turn) })
+
+	for _, gs := range stores {
+		if a.GameID == 0 {
+			a.GameID = gs.GameID
+		}
+		if gs.GameName != "" {
+			a.GameName = gs.GameName
+		}
+
+		t := &Turn{
+			Year:   int(gs.Turn) + blocks.StarsBaseYear,
+			Scores: make(map[int]int),
+			store:  gs,
+		}
+
+		for _, p := range gs.AllPlayers() {
+			fp, ok := final[p.PlayerNumber]
+			if !ok {
+				fp = &Player{Index: p.PlayerNumber}
+				final[p.PlayerNumber] = fp
+			}
+			if p.NamePlural != "" {
+				fp.Name = p.NamePlural
+			}
+			if p.StoredScore == nil {
+				continue
+			}
+			t.Scores[p.PlayerNumber] = p.StoredScore.Score
+			fp.HasScore = true
+			fp.Score = p.StoredScore.Score
+			fp.Rank = p.StoredScore.Rank
+			fp.Planets = p.StoredScore.Planets
+			fp.Starbases = p.StoredScore.Starbases
+			fp.Ships = p.StoredScore.UnarmedShips + p.StoredScore.EscortShips + p.StoredScore.CapitalShips
+		}
+
+		for _, evt := range gs.EventsForTurn(gs.Turn) {
+			for _, b := range evt.Battles {
+				t.Battles = append(t.Battles, Battle{
+					Location:    gs.PlanetName(b.PlanetID),
+					Reporter:    evt.Source.PlayerIndex,
+					Enemy:       b.EnemyPlayer,
+					Forces:      b.YourForces,
+					EnemyForces: b.EnemyForces,
+					Losses:      b.YourLosses,
+					EnemyLosses: b.EnemyLosses,
+				})
+			}
+		}
+
+		a.Turns = append(a.Turns, t)
+	}
+
+	for _, p := range final {
+		if p.Name == "" {
+			p.Name = fmt.Sprintf("Player %d", p.Index+1)
+		}
+		a.Players = append(a.Players, p)
+	}
+	slices.SortFunc(a.Players, func(x, y *Player) int {
+		if c := cmp.Compare(y.Score, x.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.Index, y.Index)
+	})
+
+	return a
+}
+
+// PlayerName returns the display name of a player index.
+func (a *Archive) PlayerName(index int) string {
+	for _, p := range a.Players {
+		if p.Index == index {
+			return p.Name
+		}
+	}
+	return fmt.Sprintf("Player %d", index+1)
+}
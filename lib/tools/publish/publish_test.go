@@ -0,0 +1,72 @@
+package publish
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func loadTurns(t *testing.T, years ...int) []*store.GameStore {
+	t.Helper()
+	var stores []*store.GameStore
+	for _, year := range years {
+		gs := store.New()
+		for _, ext := range []string{"m1", "m2"} {
+			filename := filepath.Join("../../../testdata/scenario-map/history", fmt.Sprintf("game-%d.%s", year, ext))
+			require.NoError(t, gs.AddFileWithXY(filename))
+		}
+		stores = append(stores, gs)
+	}
+	return stores
+}
+
+func TestBuild(t *testing.T) {
+	// Out of order on purpose
+	a := Build(loadTurns(t, 2471, 2440, 2460))
+
+	require.Len(t, a.Turns, 3)
+	assert.Equal(t, 2440, a.Turns[0].Year)
+	assert.Equal(t, 2471, a.Turns[2].Year)
+	assert.NotZero(t, a.GameID)
+
+	require.Len(t, a.Players, 2)
+	assert.GreaterOrEqual(t, a.Players[0].Score, a.Players[1].Score, "standings sorted by score")
+	for _, p := range a.Players {
+		assert.True(t, p.HasScore)
+		assert.NotEmpty(t, p.Name)
+		assert.Equal(t, p.Score, a.Turns[2].Scores[p.Index], "final standings use the last turn")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	a := Build(loadTurns(t, 2440, 2441))
+	dir := t.TempDir()
+
+	calls := 0
+	require.NoError(t, a.Write(dir, &Options{Progress: func(done, total int) {
+		calls++
+		assert.Equal(t, 2, total)
+	}}))
+	assert.Equal(t, 2, calls)
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(index), `<a href="turn-2440.html">2440</a>`)
+	assert.Contains(t, string(index), "<polyline", "score chart")
+	assert.Contains(t, string(index), a.Players[0].Name)
+
+	page, err := os.ReadFile(filepath.Join(dir, "turn-2441.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `<img src="maps/2441.svg"`)
+	assert.Contains(t, string(page), `<a href="turn-2440.html">`)
+
+	svg, err := os.ReadFile(filepath.Join(dir, "maps", "2440.svg"))
+	require.NoError(t, err)
+	assert.Contains(t, string(svg), "<svg")
+}
@@ -0,0 +1,251 @@
+package publish
+
+import (
+	"fmt"
+	"html/template"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/neper-stars/houston/lib/tools/maprenderer"
+)
+
+// Options controls site generation.
+type Options struct {
+	// Map holds the galaxy map rendering options (maprenderer.DefaultOptions
+	// if nil).
+	Map *maprenderer.RenderOptions
+
+	// Progress, if set, is called after each turn page is written.
+	Progress maprenderer.ProgressFunc
+}
+
+const pageStyle = `body{font-family:sans-serif;max-width:60em;margin:1em auto;color:#222}` +
+	`h1{border-bottom:2px solid #444}` +
+	`table{border-collapse:collapse;margin:.5em 0}` +
+	`th,td{border:1px solid #ccc;padding:.2em .6em}th{background:#eee}` +
+	`.num{text-align:right}.swatch{display:inline-block;width:.8em;height:.8em;margin-right:.4em}` +
+	`nav a{margin-right:1em}img{max-width:100%;border:1px solid #ccc}`
+
+// pages holds the page templates. The "color" function is bound to the
+// map options by Write.
+var pages = template.Must(template.New("").Funcs(template.FuncMap{
+	"turnPage": turnPage,
+	"color":    func(int) template.CSS { return "" },
+}).Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html><head><meta charset="utf-8">
+<title>{{.}}</title>
+<style>` + pageStyle + `</style>
+</head><body>
+{{end}}
+
+{{define "index"}}{{template "head" .Title}}<h1>{{.Title}}</h1>
+<p>Game {{.Archive.GameID}}, {{len .Archive.Turns}} turns{{if .Archive.Turns}} ({{.FirstYear}}-{{.LastYear}}){{end}}.</p>
+{{if .Chart}}<h2>Scores</h2>
+{{.Chart}}{{end}}
+<h2>Final standings</h2>
+<table>
+<tr><th>Player</th><th class="num">Score</th><th class="num">Rank</th><th class="num">Planets</th><th class="num">Starbases</th><th class="num">Ships</th></tr>
+{{range .Archive.Players}}<tr><td><span class="swatch" style="background:{{color .Index}}"></span>{{.Name}}</td>{{if .HasScore}}<td class="num">{{.Score}}</td><td class="num">{{.Rank}}</td><td class="num">{{.Planets}}</td><td class="num">{{.Starbases}}</td><td class="num">{{.Ships}}</td>{{else}}<td colspan="5">no score reported</td>{{end}}</tr>
+{{end}}</table>
+<h2>Turns</h2>
+<table>
+<tr><th>Year</th><th class="num">Battles</th></tr>
+{{range .Archive.Turns}}<tr><td><a href="{{turnPage .Year}}">{{.Year}}</a></td><td class="num">{{len .Battles}}</td></tr>
+{{end}}</table>
+</body></html>
+{{end}}
+
+{{define "turn"}}{{template "head" .Title}}<h1>{{.Title}}</h1>
+<nav><a href="index.html">Index</a>{{with .Prev}}<a href="{{turnPage .Year}}">&larr; {{.Year}}</a>{{end}}{{with .Next}}<a href="{{turnPage .Year}}">{{.Year}} &rarr;</a>{{end}}</nav>
+<h2>Galaxy</h2>
+<p><img src="{{.Map}}" alt="Galaxy map, {{.Turn.Year}}"></p>
+{{if .Scores}}<h2>Scores</h2>
+<table>
+<tr><th>Player</th><th class="num">Score</th></tr>
+{{range .Scores}}<tr><td><span class="swatch" style="background:{{color .Index}}"></span>{{.Name}}</td><td class="num">{{.Score}}</td></tr>
+{{end}}</table>{{end}}
+<h2>Battles</h2>
+{{if .Battles}}<table>
+<tr><th>Location</th><th>Reported by</th><th>Against</th><th class="num">Forces</th><th class="num">Losses</th><th class="num">Enemy forces</th><th class="num">Enemy losses</th></tr>
+{{range .Battles}}<tr><td>{{.Location}}</td><td>{{.Reporter}}</td><td>{{.Enemy}}</td><td class="num">{{.Forces}}</td><td class="num">{{.Losses}}</td><td class="num">{{.EnemyForces}}</td><td class="num">{{.EnemyLosses}}</td></tr>
+{{end}}</table>{{else}}<p>No battles reported.</p>{{end}}
+</body></html>
+{{end}}
+`))
+
+type indexPage struct {
+	Title               string
+	Archive             *Archive
+	Chart               template.HTML
+	FirstYear, LastYear int
+}
+
+type scoreRow struct {
+	Index int
+	Name  string
+	Score int
+}
+
+type battleRow struct {
+	Battle
+	Reporter string
+	Enemy    string
+}
+
+type turnPageData struct {
+	Title      string
+	Turn       *Turn
+	Prev, Next *Turn
+	Map        string
+	Scores     []scoreRow
+	Battles    []battleRow
+}
+
+// Write generates the site in dir, creating it if needed: index.html, one
+// turn-YEAR.html page per turn and the maps/YEAR.svg galaxy maps.
+func (a *Archive) Write(dir string, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	mapOpts := opts.Map
+	if mapOpts == nil {
+		mapOpts = maprenderer.DefaultOptions()
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "maps"), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	title := a.GameName
+	if title == "" {
+		title = fmt.Sprintf("Game %d", a.GameID)
+	}
+
+	colors := func(index int) template.CSS { return cssColor(mapOpts.PlayerColor(index)) }
+	tmpl := template.Must(pages.Clone()).Funcs(template.FuncMap{"color": colors})
+
+	index := indexPage{Title: title, Archive: a, Chart: a.scoreChart(colors)}
+	if len(a.Turns) > 0 {
+		index.FirstYear = a.Turns[0].Year
+		index.LastYear = a.Turns[len(a.Turns)-1].Year
+	}
+	if err := writePage(tmpl, filepath.Join(dir, "index.html"), "index", index); err != nil {
+		return err
+	}
+
+	for i, t := range a.Turns {
+		mapFile := fmt.Sprintf("maps/%d.svg", t.Year)
+		if err := maprenderer.NewFromStore(t.store).SaveSVG(filepath.Join(dir, mapFile), mapOpts); err != nil {
+			return fmt.Errorf("failed to render map for %d: %w", t.Year, err)
+		}
+
+		page := turnPageData{
+			Title: fmt.Sprintf("%s, %d", title, t.Year),
+			Turn:  t,
+			Map:   mapFile,
+		}
+		if i > 0 {
+			page.Prev = a.Turns[i-1]
+		}
+		if i+1 < len(a.Turns) {
+			page.Next = a.Turns[i+1]
+		}
+		for _, p := range a.Players {
+			if score, ok := t.Scores[p.Index]; ok {
+				page.Scores = append(page.Scores, scoreRow{Index: p.Index, Name: p.Name, Score: score})
+			}
+		}
+		for _, b := range t.Battles {
+			page.Battles = append(page.Battles, battleRow{
+				Battle:   b,
+				Reporter: a.PlayerName(b.Reporter),
+				Enemy:    a.PlayerName(b.Enemy),
+			})
+		}
+
+		if err := writePage(tmpl, filepath.Join(dir, turnPage(t.Year)), "turn", page); err != nil {
+			return err
+		}
+		if opts.Progress != nil {
+			opts.Progress(i+1, len(a.Turns))
+		}
+	}
+
+	return nil
+}
+
+// scoreChart draws the score history of every player as an inline SVG line
+// chart, or returns "" when fewer than two turns have scores.
+func (a *Archive) scoreChart(colors func(int) template.CSS) template.HTML {
+	const width, height, margin = 720, 300, 40
+
+	var years []int
+	maxScore := 0
+	for _, t := range a.Turns {
+		if len(t.Scores) == 0 {
+			continue
+		}
+		years = append(years, t.Year)
+		for _, s := range t.Scores {
+			maxScore = max(maxScore, s)
+		}
+	}
+	if len(years) < 2 || maxScore == 0 {
+		return ""
+	}
+
+	x := func(year int) float64 {
+		return margin + float64(year-years[0])*(width-2*margin)/float64(years[len(years)-1]-years[0])
+	}
+	y := func(score int) float64 {
+		return height - margin - float64(score)*(height-2*margin)/float64(maxScore)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-size="12">`, width, height)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#888"/>`, margin, height-margin, width-margin, height-margin)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#888"/>`, margin, margin, margin, height-margin)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%d</text>`, margin-4, margin+4, maxScore)
+	fmt.Fprintf(&b, `<text x="%d" y="%d">%d</text>`, margin, height-margin+16, years[0])
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%d</text>`, width-margin, height-margin+16, years[len(years)-1])
+
+	for _, p := range a.Players {
+		var points []string
+		for _, t := range a.Turns {
+			if s, ok := t.Scores[p.Index]; ok {
+				points = append(points, fmt.Sprintf("%.1f,%.1f", x(t.Year), y(s)))
+			}
+		}
+		if len(points) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke-width="2" stroke="%s" points="%s"><title>%s</title></polyline>`,
+			colors(p.Index), strings.Join(points, " "), template.HTMLEscapeString(p.Name))
+	}
+	b.WriteString(`</svg>`)
+
+	return template.HTML(b.String())
+}
+
+func writePage(tmpl *template.Template, path, name string, data any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := tmpl.ExecuteTemplate(f, name, data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+func turnPage(year int) string {
+	return fmt.Sprintf("turn-%d.html", year)
+}
+
+func cssColor(c color.RGBA) template.CSS {
+	return template.CSS(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+}
@@ -0,0 +1,246 @@
+package query
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+// Field describes a field usable in queries.
+type Field struct {
+	Name string
+	Doc  string
+}
+
+// field reads a field of an entity as an int, int64, float64, string or bool.
+type field[T any] struct {
+	Field
+	get func(T) any
+}
+
+// owner converts a store owner (-1 for none) to a player number (1-16, 0
+// for none), as shown to users.
+func owner(o int) int {
+	if o < 0 {
+		return 0
+	}
+	return o + 1
+}
+
+var planetFields = []field[*store.PlanetEntity]{
+	{Field{"number", "Planet number"}, func(p *store.PlanetEntity) any { return p.PlanetNumber }},
+	{Field{"name", "Planet name"}, func(p *store.PlanetEntity) any { return p.Name }},
+	{Field{"owner", "Owner player number (1-16, 0 if unowned)"}, func(p *store.PlanetEntity) any { return owner(p.Owner) }},
+	{Field{"x", "X coordinate"}, func(p *store.PlanetEntity) any { return p.X }},
+	{Field{"y", "Y coordinate"}, func(p *store.PlanetEntity) any { return p.Y }},
+	{Field{"homeworld", "Whether the planet is a homeworld"}, func(p *store.PlanetEntity) any { return p.IsHomeworld }},
+	{Field{"starbase", "Whether the planet has a starbase"}, func(p *store.PlanetEntity) any { return p.HasStarbase }},
+	{Field{"population", "Colonists"}, func(p *store.PlanetEntity) any { return p.Population }},
+	{Field{"mines", "Mines"}, func(p *store.PlanetEntity) any { return p.Mines }},
+	{Field{"factories", "Factories"}, func(p *store.PlanetEntity) any { return p.Factories }},
+	{Field{"defenses", "Defenses"}, func(p *store.PlanetEntity) any { return p.Defenses }},
+	{Field{"scanner", "Whether the planet has a planetary scanner"}, func(p *store.PlanetEntity) any { return p.HasScanner() }},
+	{Field{"ironium", "Surface ironium (kT)"}, func(p *store.PlanetEntity) any { return p.Ironium }},
+	{Field{"boranium", "Surface boranium (kT)"}, func(p *store.PlanetEntity) any { return p.Boranium }},
+	{Field{"germanium", "Surface germanium (kT)"}, func(p *store.PlanetEntity) any { return p.Germanium }},
+	{Field{"ironium_conc", "Ironium concentration"}, func(p *store.PlanetEntity) any { return p.IroniumConc }},
+	{Field{"boranium_conc", "Boranium concentration"}, func(p *store.PlanetEntity) any { return p.BoraniumConc }},
+	{Field{"germanium_conc", "Germanium concentration"}, func(p *store.PlanetEntity) any { return p.GermaniumConc }},
+	{Field{"environment", "Whether gravity, temperature, radiation and concentrations are known"}, func(p *store.PlanetEntity) any { return p.CanSeeEnvironment() }},
+	{Field{"gravity", "Gravity (g)"}, func(p *store.PlanetEntity) any { return blocks.GravityToDisplay(p.Gravity) }},
+	{Field{"temperature", "Temperature (°C)"}, func(p *store.PlanetEntity) any { return blocks.TemperatureToDisplay(p.Temperature) }},
+	{Field{"radiation", "Radiation (mR)"}, func(p *store.PlanetEntity) any { return blocks.RadiationToDisplay(p.Radiation) }},
+	{Field{"artifact", "Whether the planet has an ancient artifact"}, func(p *store.PlanetEntity) any { return p.HasArtifact }},
+}
+
+var fleetFields = []field[*store.FleetEntity]{
+	{Field{"number", "Fleet number, as shown in its name"}, func(f *store.FleetEntity) any { return f.FleetNumber + 1 }},
+	{Field{"name", "Fleet name"}, func(f *store.FleetEntity) any { return f.Name() }},
+	{Field{"owner", "Owner player number (1-16)"}, func(f *store.FleetEntity) any { return owner(f.Owner) }},
+	{Field{"design", "Name of the design with the most ships"}, func(f *store.FleetEntity) any {
+		if f.PrimaryDesign == nil {
+			return ""
+		}
+		return f.PrimaryDesign.Name
+	}},
+	{Field{"x", "X coordinate"}, func(f *store.FleetEntity) any { return f.X }},
+	{Field{"y", "Y coordinate"}, func(f *store.FleetEntity) any { return f.Y }},
+	{Field{"ships", "Number of ships"}, func(f *store.FleetEntity) any { return f.TotalShips() }},
+	{Field{"warp", "Warp speed"}, func(f *store.FleetEntity) any { return f.Warp }},
+	{Field{"moving", "Whether the fleet is heading somewhere"}, func(f *store.FleetEntity) any { return fleetMoving(f) }},
+	{Field{"waypoints", "Number of waypoints (own fleets only)"}, func(f *store.FleetEntity) any { return len(f.Waypoints) }},
+	{Field{"ironium", "Ironium in cargo (kT)"}, func(f *store.FleetEntity) any { return f.GetCargo().Ironium }},
+	{Field{"boranium", "Boranium in cargo (kT)"}, func(f *store.FleetEntity) any { return f.GetCargo().Boranium }},
+	{Field{"germanium", "Germanium in cargo (kT)"}, func(f *store.FleetEntity) any { return f.GetCargo().Germanium }},
+	{Field{"colonists", "Colonists in cargo"}, func(f *store.FleetEntity) any { return f.GetCargo().Population }},
+	{Field{"fuel", "Fuel (mg)"}, func(f *store.FleetEntity) any { return f.GetCargo().Fuel }},
+}
+
+// fleetMoving reports whether a fleet has a waypoint away from its position
+// or, for enemy fleets, a heading.
+func fleetMoving(f *store.FleetEntity) bool {
+	for _, wp := range f.Waypoints {
+		if wp.X != f.X || wp.Y != f.Y {
+			return true
+		}
+	}
+	return f.DeltaX != 0 || f.DeltaY != 0
+}
+
+// Fields returns the fields available for a kind of query.
+func Fields(kind Kind) []Field {
+	if kind == KindFleet {
+		return fieldList(fleetFields)
+	}
+	return fieldList(planetFields)
+}
+
+// FieldNames returns the names of the fields available for a kind of query.
+func FieldNames(kind Kind) []string {
+	var names []string
+	for _, f := range Fields(kind) {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func fieldList[T any](fields []field[T]) []Field {
+	list := make([]Field, len(fields))
+	for i, f := range fields {
+		list[i] = f.Field
+	}
+	return list
+}
+
+// fieldType returns the static type of a field from its value on a zero
+// entity.
+func fieldType(kind Kind, name string) (valueType, bool) {
+	var v any
+	var ok bool
+	if kind == KindFleet {
+		v, ok = value(fleetFields, &store.FleetEntity{}, name)
+	} else {
+		v, ok = value(planetFields, &store.PlanetEntity{}, name)
+	}
+	return typeOf(v), ok
+}
+
+func value[T any](fields []field[T], entity T, name string) (any, bool) {
+	for _, f := range fields {
+		if f.Name == name {
+			return f.get(entity), true
+		}
+	}
+	return nil, false
+}
+
+// normalize converts numbers to float64 for evaluation.
+func normalize(v any) any {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	}
+	return v
+}
+
+// Record holds the values of all fields of an entity, in field order.
+type Record struct {
+	Names  []string
+	Values []any
+}
+
+// MarshalJSON encodes the record as an object, keeping the field order.
+func (r Record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range r.Names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func record[T any](fields []field[T], entity T) Record {
+	r := Record{Names: make([]string, len(fields)), Values: make([]any, len(fields))}
+	for i, f := range fields {
+		r.Names[i] = f.Name
+		r.Values[i] = f.get(entity)
+	}
+	return r
+}
+
+func matches[T any](q *Query, fields []field[T], entity T) bool {
+	return q.match(func(name string) any {
+		v, _ := value(fields, entity, name)
+		return normalize(v)
+	})
+}
+
+// Planets returns the planets matching a planet query, by planet number.
+func (q *Query) Planets(gs *store.GameStore) []*store.PlanetEntity {
+	if q.Kind != KindPlanet {
+		return nil
+	}
+	var result []*store.PlanetEntity
+	for _, p := range gs.AllPlanets() {
+		if matches(q, planetFields, p) {
+			result = append(result, p)
+		}
+	}
+	slices.SortFunc(result, func(a, b *store.PlanetEntity) int { return cmp.Compare(a.PlanetNumber, b.PlanetNumber) })
+	return result
+}
+
+// Fleets returns the fleets matching a fleet query, by owner and number.
+func (q *Query) Fleets(gs *store.GameStore) []*store.FleetEntity {
+	if q.Kind != KindFleet {
+		return nil
+	}
+	var result []*store.FleetEntity
+	for _, f := range gs.AllFleets() {
+		if matches(q, fleetFields, f) {
+			result = append(result, f)
+		}
+	}
+	slices.SortFunc(result, func(a, b *store.FleetEntity) int {
+		if c := cmp.Compare(a.Owner, b.Owner); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.FleetNumber, b.FleetNumber)
+	})
+	return result
+}
+
+// Find returns the records of the planets or fleets matching the query.
+func (q *Query) Find(gs *store.GameStore) []Record {
+	var records []Record
+	if q.Kind == KindFleet {
+		for _, f := range q.Fleets(gs) {
+			records = append(records, record(fleetFields, f))
+		}
+		return records
+	}
+	for _, p := range q.Planets(gs) {
+		records = append(records, record(planetFields, p))
+	}
+	return records
+}
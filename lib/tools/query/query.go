@@ -0,0 +1,517 @@
+// Package query evaluates small boolean expressions over the planets or the
+// fleets of a GameStore, for ad-hoc searches such as:
+//
+//	planet.owner == 2 && planet.population > 200000
+//	fleet.ships >= 10 && !fleet.moving
+//	planet.name ~ "sol" || planet.starbase
+//
+// Expressions combine fields (planet.* or fleet.*, see Fields), numbers,
+// double-quoted strings and true/false with:
+//
+//	|| && !                  logic
+//	== != < <= > >=          comparison (numbers, strings; == and != on booleans)
+//	~                        case-insensitive substring match on strings
+//	+ - * / %                arithmetic on numbers (division by zero gives 0)
+//
+// Expressions are type checked when parsed. A query is about planets or
+// fleets, never both.
+//
+// Example usage:
+//
+//	q, err := query.Parse(`planet.owner == 2 && planet.population > 200000`)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	planets := q.Planets(gs)
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// ErrSyntax is returned for malformed expressions.
+	ErrSyntax = errors.New("syntax error")
+	// ErrType is returned for expressions mixing incompatible types.
+	ErrType = errors.New("type error")
+)
+
+// Kind is what a query searches.
+type Kind int
+
+const (
+	KindPlanet Kind = iota
+	KindFleet
+)
+
+// String returns the field prefix of the kind ("planet" or "fleet").
+func (k Kind) String() string {
+	if k == KindFleet {
+		return "fleet"
+	}
+	return "planet"
+}
+
+// valueType is the static type of an expression.
+type valueType int
+
+const (
+	typeNumber valueType = iota
+	typeString
+	typeBool
+)
+
+func (t valueType) String() string {
+	switch t {
+	case typeString:
+		return "string"
+	case typeBool:
+		return "boolean"
+	default:
+		return "number"
+	}
+}
+
+// Query is a parsed expression.
+type Query struct {
+	Kind Kind
+	src  string
+	root node
+}
+
+// String returns the source of the query.
+func (q *Query) String() string {
+	return q.src
+}
+
+// Parse parses and type checks an expression. Its kind comes from the
+// fields it uses.
+func Parse(src string) (*Query, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, kind: -1}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("%w: unexpected %q at offset %d", ErrSyntax, t.text, t.pos)
+	}
+	if p.kind < 0 {
+		return nil, fmt.Errorf("%w: the query uses no planet.* or fleet.* field", ErrSyntax)
+	}
+	if root.typ() != typeBool {
+		return nil, fmt.Errorf("%w: the query is a %s, not a condition", ErrType, root.typ())
+	}
+	return &Query{Kind: Kind(p.kind), src: src, root: root}, nil
+}
+
+// match evaluates the query against a record of field values.
+func (q *Query) match(record func(name string) any) bool {
+	return q.root.eval(record).(bool)
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+	num  float64
+}
+
+// operators lists the operators, longest first so that "<=" wins over "<".
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "~", "+", "-", "*", "/", "%"}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+
+		case c == '(' || c == ')':
+			kind := tokLParen
+			if c == ')' {
+				kind = tokRParen
+			}
+			tokens = append(tokens, token{kind: kind, text: string(c), pos: i})
+			i++
+
+		case c == '"':
+			end := strings.IndexByte(src[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated string at offset %d", ErrSyntax, i)
+			}
+			tokens = append(tokens, token{kind: tokString, text: src[i+1 : i+1+end], pos: i})
+			i += end + 2
+
+		case unicode.IsDigit(c) || c == '.' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1])):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.' || src[i] == '_') {
+				i++
+			}
+			text := src[start:i]
+			num, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: bad number %q at offset %d", ErrSyntax, text, start)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, pos: start, num: num})
+
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(src) && (unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i])) || src[i] == '_' || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:i], pos: start})
+
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("%w: unexpected %q at offset %d", ErrSyntax, c, i)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, text: "end of query", pos: len(src)}), nil
+}
+
+// Parser: precedence climbing from || (lowest) to unary operators.
+
+type parser struct {
+	tokens []token
+	pos    int
+	kind   Kind // -1 until a field is seen
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// acceptOp consumes the next token if it is one of the operators.
+func (p *parser) acceptOp(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseBinary(p.parseNot, "&&")
+}
+
+func (p *parser) parseNot() (node, error) {
+	if _, ok := p.acceptOp("!"); ok {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if x.typ() != typeBool {
+			return nil, fmt.Errorf("%w: ! needs a boolean, got a %s", ErrType, x.typ())
+		}
+		return notNode{x}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.acceptOp("==", "!=", "<=", ">=", "<", ">", "~")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	return newBinary(op, left, right)
+}
+
+func (p *parser) parseSum() (node, error) {
+	return p.parseBinary(p.parseProduct, "+", "-")
+}
+
+func (p *parser) parseProduct() (node, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+// parseBinary parses a left-associative chain of operators.
+func (p *parser) parseBinary(operand func() (node, error), ops ...string) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newBinary(op, left, right); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if _, ok := p.acceptOp("-"); ok {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if x.typ() != typeNumber {
+			return nil, fmt.Errorf("%w: - needs a number, got a %s", ErrType, x.typ())
+		}
+		return newBinary("-", literal{0.0}, x)
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return literal{t.num}, nil
+	case tokString:
+		return literal{t.text}, nil
+	case tokLParen:
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokRParen {
+			return nil, fmt.Errorf("%w: expected ) at offset %d, got %q", ErrSyntax, t.pos, t.text)
+		}
+		return x, nil
+	case tokIdent:
+		switch strings.ToLower(t.text) {
+		case "true":
+			return literal{true}, nil
+		case "false":
+			return literal{false}, nil
+		}
+		return p.field(t)
+	}
+	return nil, fmt.Errorf("%w: unexpected %q at offset %d", ErrSyntax, t.text, t.pos)
+}
+
+// field resolves a planet.* or fleet.* identifier.
+func (p *parser) field(t token) (node, error) {
+	prefix, name, ok := strings.Cut(strings.ToLower(t.text), ".")
+	var kind Kind
+	switch {
+	case ok && prefix == "planet":
+		kind = KindPlanet
+	case ok && prefix == "fleet":
+		kind = KindFleet
+	default:
+		return nil, fmt.Errorf("%w: unknown name %q at offset %d (fields start with planet. or fleet.)", ErrSyntax, t.text, t.pos)
+	}
+	if p.kind >= 0 && p.kind != kind {
+		return nil, fmt.Errorf("%w: %q: a query is about planets or fleets, not both", ErrSyntax, t.text)
+	}
+	p.kind = kind
+
+	typ, known := fieldType(kind, name)
+	if !known {
+		return nil, fmt.Errorf("%w: unknown field %q (available: %s)", ErrSyntax, t.text, strings.Join(FieldNames(kind), ", "))
+	}
+	return fieldNode{name: name, t: typ}, nil
+}
+
+// Expression tree
+
+type node interface {
+	typ() valueType
+	eval(record func(name string) any) any
+}
+
+type literal struct{ v any }
+
+func (l literal) typ() valueType { return typeOf(l.v) }
+
+func (l literal) eval(func(string) any) any { return l.v }
+
+type fieldNode struct {
+	name string
+	t    valueType
+}
+
+func (f fieldNode) typ() valueType { return f.t }
+
+func (f fieldNode) eval(record func(string) any) any { return record(f.name) }
+
+type notNode struct{ x node }
+
+func (n notNode) typ() valueType { return typeBool }
+
+func (n notNode) eval(record func(string) any) any { return !n.x.eval(record).(bool) }
+
+type binaryNode struct {
+	op          string
+	left, right node
+	t           valueType
+}
+
+// newBinary type checks and builds a binary operation.
+func newBinary(op string, left, right node) (node, error) {
+	lt, rt := left.typ(), right.typ()
+	mismatch := fmt.Errorf("%w: cannot apply %s to a %s and a %s", ErrType, op, lt, rt)
+
+	switch op {
+	case "&&", "||":
+		if lt != typeBool || rt != typeBool {
+			return nil, mismatch
+		}
+		return binaryNode{op, left, right, typeBool}, nil
+	case "==", "!=":
+		if lt != rt {
+			return nil, mismatch
+		}
+		return binaryNode{op, left, right, typeBool}, nil
+	case "<", "<=", ">", ">=":
+		if lt != rt || lt == typeBool {
+			return nil, mismatch
+		}
+		return binaryNode{op, left, right, typeBool}, nil
+	case "~":
+		if lt != typeString || rt != typeString {
+			return nil, mismatch
+		}
+		return binaryNode{op, left, right, typeBool}, nil
+	default: // Arithmetic
+		if lt != typeNumber || rt != typeNumber {
+			return nil, mismatch
+		}
+		return binaryNode{op, left, right, typeNumber}, nil
+	}
+}
+
+func (b binaryNode) typ() valueType { return b.t }
+
+func (b binaryNode) eval(record func(string) any) any {
+	// Short-circuit logic
+	switch b.op {
+	case "&&":
+		return b.left.eval(record).(bool) && b.right.eval(record).(bool)
+	case "||":
+		return b.left.eval(record).(bool) || b.right.eval(record).(bool)
+	}
+
+	l, r := b.left.eval(record), b.right.eval(record)
+	switch b.op {
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	case "~":
+		return strings.Contains(strings.ToLower(l.(string)), strings.ToLower(r.(string)))
+	}
+
+	if ls, ok := l.(string); ok {
+		return compare(b.op, strings.Compare(ls, r.(string)))
+	}
+	x, y := l.(float64), r.(float64)
+	switch b.op {
+	case "+":
+		return x + y
+	case "-":
+		return x - y
+	case "*":
+		return x * y
+	case "/":
+		if y == 0 {
+			return 0.0
+		}
+		return x / y
+	case "%":
+		if y == 0 {
+			return 0.0
+		}
+		return float64(int64(x) % int64(y))
+	}
+	switch {
+	case x < y:
+		return compare(b.op, -1)
+	case x > y:
+		return compare(b.op, 1)
+	}
+	return compare(b.op, 0)
+}
+
+// compare turns a three-way comparison result into the result of op.
+func compare(op string, c int) bool {
+	switch op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default: // ">="
+		return c >= 0
+	}
+}
+
+func typeOf(v any) valueType {
+	switch v.(type) {
+	case string:
+		return typeString
+	case bool:
+		return typeBool
+	default:
+		return typeNumber
+	}
+}
@@ -0,0 +1,117 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func planetRecord(values map[string]any) func(string) any {
+	return func(name string) any {
+		if v, ok := values[name]; ok {
+			return normalize(v)
+		}
+		v, _ := value(planetFields, &store.PlanetEntity{}, name)
+		return normalize(v)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	record := planetRecord(map[string]any{
+		"owner":      2,
+		"population": int64(250000),
+		"name":       "Sol Prime",
+		"starbase":   true,
+		"mines":      100,
+		"factories":  40,
+	})
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"planet.owner==2 && planet.population>200000", true},
+		{"planet.owner == 2 && planet.population > 300000", false},
+		{"planet.owner == 1 || planet.starbase", true},
+		{"!planet.starbase", false},
+		{"!(planet.owner == 1)", true},
+		{`planet.name ~ "sol"`, true},
+		{`planet.name == "Sol Prime"`, true},
+		{`planet.name != "sol prime"`, true},
+		{`planet.name < "Z"`, true},
+		{"planet.mines - planet.factories * 2 == 20", true},
+		{"(planet.mines + planet.factories) / 2 >= 70", true},
+		{"planet.mines % 30 == 10", true},
+		{"planet.mines / 0 == 0", true},
+		{"-planet.mines < 0", true},
+		{"planet.population > 200_000", true},
+		{"planet.starbase == true", true},
+		{"planet.homeworld == false && planet.gravity > 0.1", true},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, KindPlanet, q.Kind)
+		assert.Equal(t, tt.want, q.match(record), tt.expr)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  error
+	}{
+		{"planet.owner = 2", ErrSyntax},
+		{"planet.owner == ", ErrSyntax},
+		{"(planet.owner == 2", ErrSyntax},
+		{"planet.owner == 2)", ErrSyntax},
+		{`planet.name == "Sol`, ErrSyntax},
+		{"planet.nope == 2", ErrSyntax},
+		{"owner == 2", ErrSyntax},
+		{"1 == 1", ErrSyntax},
+		{"planet.owner == 2 && fleet.ships > 1", ErrSyntax},
+		{"planet.owner", ErrType},
+		{`planet.name > 3`, ErrType},
+		{"planet.starbase < true", ErrType},
+		{"!planet.owner", ErrType},
+		{`-planet.name == 1`, ErrType},
+		{"planet.owner && planet.starbase", ErrType},
+		{`planet.owner ~ "2"`, ErrType},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		assert.ErrorIs(t, err, tt.err, tt.expr)
+	}
+}
+
+func TestFind(t *testing.T) {
+	gs := store.New()
+	require.NoError(t, gs.AddFileWithXY("../../../testdata/scenario-map/history/game-2471.m1"))
+
+	q, err := Parse("planet.owner == 1")
+	require.NoError(t, err)
+	planets := q.Planets(gs)
+	assert.Len(t, planets, 15)
+	for i := 1; i < len(planets); i++ {
+		assert.Less(t, planets[i-1].PlanetNumber, planets[i].PlanetNumber)
+	}
+	assert.Nil(t, q.Fleets(gs))
+
+	q, err = Parse("planet.owner == 1 && planet.starbase")
+	require.NoError(t, err)
+	records := q.Find(gs)
+	assert.Len(t, records, 3)
+
+	data, err := json.Marshal(records[0])
+	require.NoError(t, err)
+	assert.Regexp(t, `^\{"number":\d+,"name":"[^"]+","owner":1,`, string(data))
+
+	q, err = Parse("fleet.owner == 1")
+	require.NoError(t, err)
+	assert.Len(t, q.Fleets(gs), 14)
+	assert.Len(t, q.Find(gs), 14)
+}
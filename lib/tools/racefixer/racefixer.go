@@ -284,10 +284,10 @@ func RemovePasswordBytes(data []byte) ([]byte, *RepairResult, error) {
 	encryptor := crypto.NewEncryptor()
 	encryptor.InitEncryption(
 		headerBlock.Salt(),
-		0, // shareware flag
-		headerBlock.PlayerIndex(),
-		int(headerBlock.Turn),
 		int(headerBlock.GameID),
+		int(headerBlock.Turn),
+		headerBlock.PlayerIndex(),
+		headerBlock.SharewareFlag(),
 	)
 
 	// Find PlayerBlock offset in raw data and re-encrypt
@@ -4,6 +4,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
 )
 
 func TestRemovePasswordBytes(t *testing.T) {
@@ -79,6 +82,46 @@ func TestRemovePasswordBytes_NoPassword(t *testing.T) {
 	}
 }
 
+func TestRemovePasswordBytes_Shareware(t *testing.T) {
+	// A race file written by a shareware copy of Stars! is encrypted with
+	// the shareware flag, and must be encrypted back the same way
+	passData, err := os.ReadFile("../../../testdata/scenario-racefiles/race1-password.r2")
+	if err != nil {
+		t.Fatalf("Failed to read password-protected file: %v", err)
+	}
+	gs := store.New()
+	if err := gs.AddFile("race1.r2", passData); err != nil {
+		t.Fatalf("Failed to load race file: %v", err)
+	}
+	gs.Sources()[0].Header.SetCrippled(true)
+	sharewareData, err := gs.GenerateRFile(blocks.RaceFilePlayerIndex)
+	if err != nil {
+		t.Fatalf("Failed to write shareware race file: %v", err)
+	}
+
+	repaired, result, err := RemovePasswordBytes(sharewareData)
+	if err != nil {
+		t.Fatalf("RemovePasswordBytes failed: %v", err)
+	}
+	if !result.PasswordRemoved {
+		t.Fatal("Expected PasswordRemoved to be true")
+	}
+
+	info, err := AnalyzeBytes("repaired", repaired)
+	if err != nil {
+		t.Fatalf("Failed to analyze repaired file: %v", err)
+	}
+	if info.HasPassword {
+		t.Error("Expected repaired file to have no password")
+	}
+	if info.NeedsRepair {
+		t.Error("Expected repaired file to have correct checksum")
+	}
+	if info.PluralName != "race1s" {
+		t.Errorf("Expected plural name 'race1s', got %q", info.PluralName)
+	}
+}
+
 func TestAnalyzeBytes_ScenarioRacefixer(t *testing.T) {
 	// Test game.r1 which needs repair - verify we can detect and fix it
 	t.Run("game.r1_needs_repair", func(t *testing.T) {
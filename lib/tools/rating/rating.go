@@ -0,0 +1,275 @@
+// Package rating maintains Elo and Glicko ratings of league players from the
+// final standings of completed games.
+//
+// A game of n players counts as the n(n-1)/2 duels between them: each player
+// beats those ranked below and loses to those ranked above, players with the
+// same score drawing. Elo changes are scaled down by n-1 so that a game
+// weighs the same whatever its size; Glicko (Glickman's Glicko-1) treats each
+// game as a rating period, its rating deviation (RD) telling how reliable a
+// rating is.
+//
+//	league := rating.New(rating.DefaultConfig())
+//	game := rating.GameFromStore(gs, nil) // final turn of a game
+//	if err := league.Rate(game); err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, r := range league.Ratings() {
+//	    fmt.Printf("%s %.0f\n", r.Player, r.Elo)
+//	}
+package rating
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+var (
+	ErrAlreadyRated    = errors.New("game already rated")
+	ErrTooFewPlayers   = errors.New("a game needs at least two ranked players")
+	ErrDuplicatePlayer = errors.New("player ranked twice in a game")
+)
+
+// Config sets the rating systems.
+type Config struct {
+	InitialRating float64 `json:"initial_rating"` // Elo and Glicko rating of new players
+	K             float64 `json:"k"`              // Elo K-factor of a whole game
+	InitialRD     float64 `json:"initial_rd"`     // Glicko RD of new players, also the largest RD
+	RDGrowth      float64 `json:"rd_growth"`      // Glicko RD gained by a player before each game
+}
+
+// DefaultConfig returns the usual settings: ratings start at 1500, with a
+// K-factor of 32 and an RD of 350.
+func DefaultConfig() Config {
+	return Config{InitialRating: 1500, K: 32, InitialRD: 350, RDGrowth: 30}
+}
+
+// Standing is the final result of a player in a game.
+type Standing struct {
+	Player string // League name of the player
+	Score  int
+}
+
+// Game is a completed game to rate.
+type Game struct {
+	ID        string // Identifies the game, so that it is rated once
+	Year      int    // Final year
+	Standings []Standing
+}
+
+// GameFromStore returns the standings of the final turn of a game, from the
+// scores of its players. Players are named by names, indexed by player
+// number (0-15), or by their race name otherwise. Players without a score
+// are left out.
+func GameFromStore(gs *store.GameStore, names map[int]string) Game {
+	g := Game{
+		ID:   strconv.FormatUint(uint64(gs.GameID), 10),
+		Year: blocks.StarsBaseYear + int(gs.Turn),
+	}
+	for _, p := range gs.AllPlayers() {
+		if p.StoredScore == nil {
+			continue
+		}
+		name := names[p.PlayerNumber]
+		if name == "" {
+			name = p.NamePlural
+		}
+		if name == "" {
+			name = fmt.Sprintf("Player %d", p.PlayerNumber+1)
+		}
+		g.Standings = append(g.Standings, Standing{Player: name, Score: p.StoredScore.Score})
+	}
+	return g
+}
+
+// Rating is the rating of a league player.
+type Rating struct {
+	Player string  `json:"player"`
+	Elo    float64 `json:"elo"`
+	Glicko float64 `json:"glicko"`
+	RD     float64 `json:"rd"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"` // Games won outright
+}
+
+// League holds the ratings of the players and the games rated so far.
+type League struct {
+	Config  Config             `json:"config"`
+	Players map[string]*Rating `json:"players"`
+	Games   []string           `json:"games"` // IDs of the rated games, in order
+}
+
+// New returns a league without players.
+func New(config Config) *League {
+	return &League{Config: config, Players: make(map[string]*Rating)}
+}
+
+// Load reads a league saved with Save.
+func Load(r io.Reader) (*League, error) {
+	l := New(DefaultConfig())
+	if err := json.NewDecoder(r).Decode(l); err != nil {
+		return nil, fmt.Errorf("failed to decode ratings: %w", err)
+	}
+	if l.Players == nil {
+		l.Players = make(map[string]*Rating)
+	}
+	return l, nil
+}
+
+// Save writes the league as JSON.
+func (l *League) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l)
+}
+
+// Rated returns true if the game was rated.
+func (l *League) Rated(id string) bool {
+	return slices.Contains(l.Games, id)
+}
+
+// Rate updates the ratings of the players of a game.
+func (l *League) Rate(g Game) error {
+	if l.Rated(g.ID) {
+		return fmt.Errorf("%w: %s", ErrAlreadyRated, g.ID)
+	}
+	if len(g.Standings) < 2 {
+		return ErrTooFewPlayers
+	}
+
+	for i, s := range g.Standings {
+		for _, other := range g.Standings[:i] {
+			if other.Player == s.Player {
+				return fmt.Errorf("%w: %s", ErrDuplicatePlayer, s.Player)
+			}
+		}
+	}
+	players := make([]*Rating, len(g.Standings))
+	for i, s := range g.Standings {
+		players[i] = l.player(s.Player)
+	}
+
+	// Results of each player against each other player
+	n := len(players)
+	results := make([][]float64, n)
+	for i, a := range g.Standings {
+		results[i] = make([]float64, n)
+		for j, b := range g.Standings {
+			switch {
+			case a.Score > b.Score:
+				results[i][j] = 1
+			case a.Score == b.Score:
+				results[i][j] = 0.5
+			}
+		}
+	}
+
+	elo := make([]float64, n)
+	glicko := make([]float64, n)
+	rd := make([]float64, n)
+	for i, p := range players {
+		var change float64
+		var opponents []opponent
+		for j, o := range players {
+			if i == j {
+				continue
+			}
+			change += results[i][j] - eloExpected(p.Elo, o.Elo)
+			opponents = append(opponents, opponent{rating: o.Glicko, rd: l.preGameRD(o), score: results[i][j]})
+		}
+		elo[i] = p.Elo + l.Config.K/float64(n-1)*change
+		glicko[i], rd[i] = glickoUpdate(p.Glicko, l.preGameRD(p), opponents)
+	}
+
+	best := slices.MaxFunc(g.Standings, func(a, b Standing) int { return cmp.Compare(a.Score, b.Score) }).Score
+	winners := 0
+	for _, s := range g.Standings {
+		if s.Score == best {
+			winners++
+		}
+	}
+	for i, p := range players {
+		p.Elo, p.Glicko, p.RD = elo[i], glicko[i], rd[i]
+		p.Games++
+		if winners == 1 && g.Standings[i].Score == best {
+			p.Wins++
+		}
+	}
+	l.Games = append(l.Games, g.ID)
+	return nil
+}
+
+// Ratings returns the players, best Elo rating first.
+func (l *League) Ratings() []*Rating {
+	ratings := make([]*Rating, 0, len(l.Players))
+	for _, r := range l.Players {
+		ratings = append(ratings, r)
+	}
+	slices.SortFunc(ratings, func(a, b *Rating) int {
+		if c := cmp.Compare(b.Elo, a.Elo); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Player, b.Player)
+	})
+	return ratings
+}
+
+// player returns the rating of a player, adding new players.
+func (l *League) player(name string) *Rating {
+	r, ok := l.Players[name]
+	if !ok {
+		r = &Rating{
+			Player: name,
+			Elo:    l.Config.InitialRating,
+			Glicko: l.Config.InitialRating,
+			RD:     l.Config.InitialRD,
+		}
+		l.Players[name] = r
+	}
+	return r
+}
+
+// preGameRD returns the RD of a player at the start of a game: ratings grow
+// less reliable between games.
+func (l *League) preGameRD(r *Rating) float64 {
+	return math.Min(math.Hypot(r.RD, l.Config.RDGrowth), l.Config.InitialRD)
+}
+
+// eloExpected returns the expected result of a player against another.
+func eloExpected(rating, opponent float64) float64 {
+	return 1 / (1 + math.Pow(10, (opponent-rating)/400))
+}
+
+// opponent is a duel of a Glicko rating period.
+type opponent struct {
+	rating, rd float64
+	score      float64 // 1 for a win, 0.5 for a draw, 0 for a loss
+}
+
+// glickoQ is ln(10)/400, the scale of Glicko ratings.
+var glickoQ = math.Ln10 / 400
+
+func glickoG(rd float64) float64 {
+	return 1 / math.Sqrt(1+3*glickoQ*glickoQ*rd*rd/(math.Pi*math.Pi))
+}
+
+// glickoUpdate returns the rating and RD of a player after a rating period.
+func glickoUpdate(rating, rd float64, opponents []opponent) (float64, float64) {
+	var invD2, sum float64
+	for _, o := range opponents {
+		g := glickoG(o.rd)
+		e := 1 / (1 + math.Pow(10, -g*(rating-o.rating)/400))
+		invD2 += glickoQ * glickoQ * g * g * e * (1 - e)
+		sum += g * (o.score - e)
+	}
+	denominator := 1/(rd*rd) + invD2
+	return rating + glickoQ/denominator*sum, math.Sqrt(1 / denominator)
+}
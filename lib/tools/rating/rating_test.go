@@ -0,0 +1,99 @@
+package rating
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func TestGlickoUpdate(t *testing.T) {
+	// Example of Glickman's paper on the Glicko system
+	rating, rd := glickoUpdate(1500, 200, []opponent{
+		{rating: 1400, rd: 30, score: 1},
+		{rating: 1550, rd: 100, score: 0},
+		{rating: 1700, rd: 300, score: 0},
+	})
+	assert.InDelta(t, 1464, rating, 0.5)
+	assert.InDelta(t, 151.4, rd, 0.5)
+}
+
+func TestRate_Duel(t *testing.T) {
+	l := New(DefaultConfig())
+	require.NoError(t, l.Rate(Game{ID: "1", Standings: []Standing{
+		{Player: "alice", Score: 900},
+		{Player: "bob", Score: 700},
+	}}))
+
+	alice, bob := l.Players["alice"], l.Players["bob"]
+	assert.InDelta(t, 1516, alice.Elo, 1e-9)
+	assert.InDelta(t, 1484, bob.Elo, 1e-9)
+	assert.Greater(t, alice.Glicko, 1500.0)
+	assert.Less(t, alice.RD, 350.0)
+	assert.Equal(t, 1, alice.Wins)
+	assert.Equal(t, 0, bob.Wins)
+	assert.Equal(t, 1, bob.Games)
+
+	assert.ErrorIs(t, l.Rate(Game{ID: "1"}), ErrAlreadyRated)
+}
+
+func TestRate_Multiplayer(t *testing.T) {
+	l := New(DefaultConfig())
+	require.NoError(t, l.Rate(Game{ID: "1", Standings: []Standing{
+		{Player: "carol", Score: 500},
+		{Player: "alice", Score: 900},
+		{Player: "bob", Score: 900},
+		{Player: "dave", Score: 100},
+	}}))
+
+	ratings := l.Ratings()
+	require.Len(t, ratings, 4)
+	assert.Equal(t, []string{"alice", "bob", "carol", "dave"},
+		[]string{ratings[0].Player, ratings[1].Player, ratings[2].Player, ratings[3].Player})
+	assert.Equal(t, ratings[0].Elo, ratings[1].Elo, "a tie shares the rating")
+	assert.Zero(t, ratings[0].Wins, "a tie is not a win")
+
+	var total float64
+	for _, r := range ratings {
+		total += r.Elo
+	}
+	assert.InDelta(t, 4*1500, total, 1e-9, "Elo points are exchanged, not created")
+}
+
+func TestRate_Errors(t *testing.T) {
+	l := New(DefaultConfig())
+	assert.ErrorIs(t, l.Rate(Game{ID: "1", Standings: []Standing{{Player: "alice"}}}), ErrTooFewPlayers)
+	assert.ErrorIs(t, l.Rate(Game{ID: "2", Standings: []Standing{{Player: "alice"}, {Player: "alice"}}}), ErrDuplicatePlayer)
+	assert.Empty(t, l.Players)
+}
+
+func TestSaveLoad(t *testing.T) {
+	l := New(DefaultConfig())
+	require.NoError(t, l.Rate(Game{ID: "1", Standings: []Standing{{Player: "alice", Score: 2}, {Player: "bob", Score: 1}}}))
+
+	var buf bytes.Buffer
+	require.NoError(t, l.Save(&buf))
+	loaded, err := Load(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, l, loaded)
+	assert.True(t, loaded.Rated("1"))
+}
+
+func TestGameFromStore(t *testing.T) {
+	// Each M file holds the score of its player
+	gs := store.New()
+	for _, side := range []string{"side1/game.m1", "side2/game.m2", "side3/game.m3"} {
+		require.NoError(t, gs.AddFileWithXY("../../../testdata/scenario-diplomacy-3way/1/"+side))
+	}
+
+	g := GameFromStore(gs, map[int]string{0: "alice"})
+	assert.Equal(t, 2401, g.Year)
+	assert.Equal(t, []Standing{
+		{Player: "alice", Score: 25},
+		{Player: "Halflings", Score: 25},
+		{Player: "Orcs", Score: 26},
+	}, g.Standings)
+}
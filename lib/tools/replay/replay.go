@@ -0,0 +1,207 @@
+// Package replay reconstructs the story of a game from its archive: the
+// files of every turn and the event logs written after each generation.
+//
+// The story is told one chapter per year. Each chapter narrates the notable
+// events of the year (battles, colonies, random events, bombardments,
+// research breakthroughs) and the changes of score leader. Events reported to
+// several players, such as both sides of a battle, are told once.
+//
+// Example usage:
+//
+//	story := replay.Build(stores, events) // one GameStore per turn, event logs
+//	for _, ch := range story.Chapters {
+//	    fmt.Println(ch.Year)
+//	    for _, line := range ch.Narration {
+//	        fmt.Println("  " + line)
+//	    }
+//	}
+package replay
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/eventlog"
+	"github.com/neper-stars/houston/store"
+)
+
+// Standing is a player's score in a year.
+type Standing struct {
+	Player int // Player number (1-16)
+	Name   string
+	Score  int
+	Rank   int
+}
+
+// Chapter is one year of the story.
+type Chapter struct {
+	Year      int
+	Narration []string
+	Events    []eventlog.Event // Deduplicated events of the year
+	Standings []Standing       // Best score first, for the players whose score is known
+}
+
+// Story is the whole game, year by year.
+type Story struct {
+	GameID   uint32
+	GameName string
+	Chapters []*Chapter // Sorted by year
+	Players  map[int]string
+}
+
+// PlayerName returns the display name of a player number (1-16).
+func (s *Story) PlayerName(player int) string {
+	if name := s.Players[player]; name != "" {
+		return "the " + name
+	}
+	return fmt.Sprintf("player %d", player)
+}
+
+// Build assembles the story from one GameStore per turn and the events of
+// the archived event logs. The events of each turn's M files are added to
+// the logged ones, so either source alone is enough.
+func Build(stores []*store.GameStore, events []eventlog.Event) *Story {
+	story := &Story{Players: make(map[int]string)}
+
+	stores = slices.Clone(stores)
+	slices.SortStableFunc(stores, func(x, y *store.GameStore) int { return cmp.Compare(x.Turn, y.Turn) })
+
+	byYear := make(map[int]*Chapter)
+	chapter := func(year int) *Chapter {
+		ch, ok := byYear[year]
+		if !ok {
+			ch = &Chapter{Year: year}
+			byYear[year] = ch
+			story.Chapters = append(story.Chapters, ch)
+		}
+		return ch
+	}
+
+	all := slices.Clone(events)
+	for _, gs := range stores {
+		if story.GameID == 0 {
+			story.GameID = gs.GameID
+		}
+		if name := strings.TrimRight(gs.GameName, "\x00 "); name != "" {
+			story.GameName = name
+		}
+
+		ch := chapter(blocks.StarsBaseYear + int(gs.Turn))
+		for _, p := range gs.AllPlayers() {
+			if p.NamePlural != "" {
+				story.Players[p.PlayerNumber+1] = p.NamePlural
+			}
+			if p.StoredScore == nil || slices.ContainsFunc(ch.Standings, func(s Standing) bool { return s.Player == p.PlayerNumber+1 }) {
+				continue
+			}
+			ch.Standings = append(ch.Standings, Standing{
+				Player: p.PlayerNumber + 1,
+				Name:   p.NamePlural,
+				Score:  p.StoredScore.Score,
+				Rank:   p.StoredScore.Rank,
+			})
+		}
+		all = append(all, eventlog.FromStore(gs)...)
+	}
+	if story.GameID == 0 && len(events) > 0 {
+		story.GameID = events[0].GameID
+	}
+
+	seen := make(map[string]bool)
+	for _, e := range all {
+		key := eventKey(e)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		ch := chapter(e.Year)
+		ch.Events = append(ch.Events, e)
+	}
+
+	slices.SortFunc(story.Chapters, func(a, b *Chapter) int { return cmp.Compare(a.Year, b.Year) })
+	leader := 0
+	for _, ch := range story.Chapters {
+		slices.SortStableFunc(ch.Standings, func(a, b Standing) int {
+			return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Player, b.Player))
+		})
+		if len(ch.Standings) > 0 && ch.Standings[0].Player != leader {
+			top := ch.Standings[0]
+			verb := "take"
+			if leader == 0 {
+				verb = "hold"
+			}
+			ch.Narration = append(ch.Narration, capitalize(fmt.Sprintf("%s %s the lead with %d points.",
+				story.PlayerName(top.Player), verb, top.Score)))
+			leader = top.Player
+		}
+		for _, e := range ch.Events {
+			if line := story.narrate(e); line != "" {
+				ch.Narration = append(ch.Narration, line)
+			}
+		}
+	}
+	return story
+}
+
+// eventKey identifies an event regardless of the player it was reported to:
+// a battle is the same from both sides, and everyone sees the same comet.
+func eventKey(e eventlog.Event) string {
+	switch e.Type {
+	case eventlog.TypeBattle:
+		a, b := min(e.Player, e.Enemy), max(e.Player, e.Enemy)
+		return fmt.Sprintf("%d/%s/%d/%d-%d", e.Year, e.Type, e.Planet, a, b)
+	case eventlog.TypeCometStrike:
+		return fmt.Sprintf("%d/%s/%d", e.Year, e.Type, e.Planet)
+	}
+	return fmt.Sprintf("%d/%s/%d/%d/%s", e.Year, e.Type, e.Player, e.Planet, e.Text)
+}
+
+// narrate tells a notable event in a sentence, or returns an empty string
+// for routine events such as scrapped fleets and launched packets.
+func (s *Story) narrate(e eventlog.Event) string {
+	where := e.Location
+	switch {
+	case e.Planet == 0:
+		where = "an unknown location"
+	case where == "":
+		where = fmt.Sprintf("planet #%d", e.Planet)
+	}
+	who := s.PlayerName(e.Player)
+
+	switch e.Type {
+	case eventlog.TypeBattle:
+		if e.Battle == nil {
+			return capitalize(fmt.Sprintf("%s and %s fought at %s.", who, s.PlayerName(e.Enemy), where))
+		}
+		b := e.Battle
+		return capitalize(fmt.Sprintf("%s and %s fought at %s: %s lost %d ship(s), %s lost %d.",
+			who, s.PlayerName(e.Enemy), where, who, b.Losses, s.PlayerName(e.Enemy), b.EnemyLosses))
+	case eventlog.TypeColony:
+		return capitalize(fmt.Sprintf("%s settled %s.", who, where))
+	case eventlog.TypeCometStrike:
+		return capitalize(e.Text + ".")
+	case eventlog.TypeStrangeArtifact:
+		return capitalize(fmt.Sprintf("%s unearthed a strange artifact on %s.", who, where))
+	case eventlog.TypePacketBombardment:
+		return capitalize(fmt.Sprintf("A mineral packet struck %s of %s, killing %d colonists.", where, who, e.Amount))
+	case eventlog.TypeResearch:
+		// Only milestones, every level would drown the story
+		if e.Amount%5 != 0 {
+			return ""
+		}
+		return capitalize(fmt.Sprintf("%s reached %s.", who, strings.TrimSuffix(e.Text, " reached")))
+	case eventlog.TypeStarbaseBuilt:
+		return capitalize(fmt.Sprintf("%s built a starbase at %s.", who, where))
+	}
+	return ""
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
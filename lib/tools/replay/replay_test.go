@@ -0,0 +1,72 @@
+package replay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/lib/tools/eventlog"
+	"github.com/neper-stars/houston/store"
+)
+
+func loadTurn(t *testing.T, names ...string) *store.GameStore {
+	t.Helper()
+	gs := store.New()
+	for _, name := range names {
+		require.NoError(t, gs.AddFileWithXY("../../../testdata/scenario-map/history/"+name))
+	}
+	return gs
+}
+
+func TestBuild(t *testing.T) {
+	stores := []*store.GameStore{
+		loadTurn(t, "game-2418.m1", "game-2418.m2"),
+		loadTurn(t, "game-2411.m1", "game-2411.m2"),
+	}
+	// Both sides of a battle, as logged by each player
+	battle := eventlog.Event{Year: 2418, Type: eventlog.TypeBattle, Planet: 314, Location: "Applegate"}
+	hobbits, halflings := battle, battle
+	hobbits.Player, hobbits.Enemy = 1, 2
+	hobbits.Battle = &eventlog.Battle{Losses: 3, EnemyLosses: 1}
+	halflings.Player, halflings.Enemy = 2, 1
+	halflings.Battle = &eventlog.Battle{Losses: 1, EnemyLosses: 3}
+
+	story := Build(stores, []eventlog.Event{hobbits, halflings})
+	assert.Equal(t, stores[0].GameID, story.GameID)
+	assert.Equal(t, "WormHole01", story.GameName)
+	assert.Equal(t, "the Hobbits", story.PlayerName(1))
+	assert.Equal(t, "player 5", story.PlayerName(5))
+
+	require.Len(t, story.Chapters, 2)
+	first, second := story.Chapters[0], story.Chapters[1]
+	assert.Equal(t, 2411, first.Year)
+	assert.Equal(t, 2418, second.Year)
+
+	assert.Equal(t, []string{
+		"The Hobbits hold the lead with 37 points.",
+		"The Halflings unearthed a strange artifact on Ball Bearing.",
+	}, first.Narration)
+	require.Len(t, first.Standings, 2)
+	assert.Equal(t, Standing{Player: 1, Name: "Hobbits", Score: 37}, first.Standings[0])
+
+	// The battle and the comet seen by both players are told once
+	assert.Equal(t, []string{
+		"The Hobbits and the Halflings fought at Applegate: the Hobbits lost 3 ship(s), the Halflings lost 1.",
+		"Huge comet struck Applegate.",
+	}, second.Narration)
+	assert.Len(t, second.Events, 2)
+}
+
+func TestNarrate(t *testing.T) {
+	story := Build(nil, nil)
+	assert.Empty(t, story.Chapters)
+
+	story = &Story{Players: map[int]string{}}
+	assert.Equal(t, "", story.narrate(eventlog.Event{Type: eventlog.TypeFleetScrapped, Player: 1}))
+	assert.Equal(t, "", story.narrate(eventlog.Event{Type: eventlog.TypeResearch, Player: 1, Amount: 7}))
+	assert.Equal(t, "Player 1 reached Weapons level 10.",
+		story.narrate(eventlog.Event{Type: eventlog.TypeResearch, Player: 1, Amount: 10, Text: "Weapons level 10 reached"}))
+	assert.Equal(t, "Player 2 built a starbase at an unknown location.",
+		story.narrate(eventlog.Event{Type: eventlog.TypeStarbaseBuilt, Player: 2}))
+}
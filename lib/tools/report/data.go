@@ -0,0 +1,171 @@
+package report
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/notes"
+	"github.com/neper-stars/houston/lib/tools/summary"
+	"github.com/neper-stars/houston/store"
+)
+
+// Data is the model exposed to custom report templates (see Execute).
+// Templates access it as the dot, e.g. {{.Game.Year}} or
+// {{range .Planets}}{{.Name}}{{end}}.
+//
+// Player numbers are 1-based, as displayed by Stars!. The Summary and Store
+// fields give access to the full analysis and game data for templates that
+// need more than the flattened lists.
+type Data struct {
+	Game    GameData         // Game identification and turn
+	Player  PlayerData       // The player the report is written for
+	Players []PlayerData     // All known players, by player number
+	Planets []PlanetData     // The player's planets, by name
+	Fleets  []FleetData      // The player's fleets, by fleet number
+	Summary *summary.Summary // Empire overview of the player
+	Store   *store.GameStore // Underlying game store
+	Notes   []notes.Note     // The player's notes, see AddNotes
+}
+
+// GameData identifies the game and turn.
+type GameData struct {
+	ID      uint32
+	Name    string
+	Turn    int // Turn number, starting at 1 on the first year
+	Year    int // Stars! year (2400 on the first turn)
+	Players int // Number of players in the game (0 if unknown)
+}
+
+// PlayerData describes a player.
+type PlayerData struct {
+	Number       int    // Player number (1-16)
+	Name         string // Plural race name, e.g. "Humanoids"
+	NameSingular string
+	Planets      int  // Planets owned, from the score screen when available
+	Score        int  // Score from the file, 0 if not reported
+	Rank         int  // Rank from the file, 0 if not reported
+	Self         bool // Whether this is the player the report is written for
+}
+
+// PlanetData describes one of the player's planets.
+type PlanetData struct {
+	Number      int
+	Name        string
+	X, Y        int
+	Population  int64
+	Resources   int // Resources produced per year
+	Ironium     int64
+	Boranium    int64
+	Germanium   int64
+	Mines       int
+	Factories   int
+	Defenses    int
+	HasStarbase bool
+	IsHomeworld bool
+	Notes       []string // Texts of the notes about the planet
+}
+
+// FleetData describes one of the player's fleets.
+type FleetData struct {
+	Number int // Fleet number (1-based, as in fleet names)
+	Name   string
+	X, Y   int
+	Ships  int
+	Role   string // Dominant role, see summary.Role
+	Warp   int
+	Notes  []string // Texts of the notes about the fleet
+}
+
+// NewData builds the template data model for a player (0-indexed).
+func NewData(gs *store.GameStore, playerNumber int) (*Data, error) {
+	s, err := summary.Summarize(gs, playerNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Data{
+		Game: GameData{
+			ID:      gs.GameID,
+			Name:    gs.GameName,
+			Turn:    int(gs.Turn) + 1,
+			Year:    int(gs.Turn) + blocks.StarsBaseYear,
+			Players: int(gs.PlayerCount),
+		},
+		Summary: s,
+		Store:   gs,
+	}
+
+	for _, p := range gs.AllPlayers() {
+		pd := PlayerData{
+			Number:       p.PlayerNumber + 1,
+			Name:         p.NamePlural,
+			NameSingular: p.NameSingular,
+			Planets:      p.PlanetCount,
+			Rank:         p.Rank,
+			Self:         p.PlayerNumber == playerNumber,
+		}
+		if p.StoredScore != nil {
+			pd.Score = p.StoredScore.Score
+			pd.Planets = p.StoredScore.Planets
+		}
+		if pd.Self {
+			pd.Score = s.Score
+			pd.Planets = s.Planets
+			d.Player = pd
+		}
+		d.Players = append(d.Players, pd)
+	}
+	slices.SortFunc(d.Players, func(a, b PlayerData) int { return cmp.Compare(a.Number, b.Number) })
+
+	for _, p := range gs.PlanetsByOwner(playerNumber) {
+		d.Planets = append(d.Planets, PlanetData{
+			Number:      p.PlanetNumber,
+			Name:        p.Name,
+			X:           p.X,
+			Y:           p.Y,
+			Population:  p.Population,
+			Resources:   gs.CResourcesAtPlanet(p, s.Player),
+			Ironium:     p.Ironium,
+			Boranium:    p.Boranium,
+			Germanium:   p.Germanium,
+			Mines:       p.Mines,
+			Factories:   p.Factories,
+			Defenses:    p.Defenses,
+			HasStarbase: p.HasStarbase,
+			IsHomeworld: p.IsHomeworld,
+		})
+	}
+	slices.SortFunc(d.Planets, func(a, b PlanetData) int { return cmp.Compare(a.Name, b.Name) })
+
+	for _, f := range gs.FleetsByOwner(playerNumber) {
+		d.Fleets = append(d.Fleets, FleetData{
+			Number: f.FleetNumber + 1,
+			Name:   f.Name(),
+			X:      f.X,
+			Y:      f.Y,
+			Ships:  f.TotalShips(),
+			Role:   summary.FleetRole(gs, f).String(),
+			Warp:   f.Warp,
+		})
+	}
+	slices.SortFunc(d.Fleets, func(a, b FleetData) int { return cmp.Compare(a.Number, b.Number) })
+
+	return d, nil
+}
+
+// AddNotes attaches the notes of a book to the report: all of them to
+// Notes, and each one to the planet or fleet of the player it is about.
+func (d *Data) AddNotes(book *notes.Book) {
+	d.Notes = book.Notes
+	for i, p := range d.Planets {
+		for _, n := range book.ForPlanet(p.Number, p.Name) {
+			d.Planets[i].Notes = append(d.Planets[i].Notes, n.Text)
+		}
+	}
+	for i, f := range d.Fleets {
+		for _, n := range book.ForFleet(d.Player.Number, f.Number) {
+			d.Fleets[i].Notes = append(d.Fleets[i].Notes, n.Text)
+		}
+	}
+}
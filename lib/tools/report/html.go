@@ -0,0 +1,92 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+)
+
+// HTML renders documents as a standalone HTML page with inline styles, so
+// that the page displays the same when mailed.
+type HTML struct{}
+
+const htmlStyle = `body{font-family:sans-serif;max-width:50em;margin:1em auto;color:#222}` +
+	`h1{border-bottom:2px solid #444}` +
+	`table{border-collapse:collapse;margin:.5em 0}` +
+	`th,td{border:1px solid #ccc;padding:.2em .6em}th{background:#eee}` +
+	`.num{text-align:right}dt{font-weight:bold;float:left;clear:left;width:12em}dd{margin-left:12em}`
+
+// Render writes the document as an HTML page.
+func (HTML) Render(w io.Writer, doc *Document) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "<!DOCTYPE html>")
+	fmt.Fprintln(bw, `<html><head><meta charset="utf-8">`)
+	fmt.Fprintf(bw, "<title>%s</title>\n", html.EscapeString(doc.Title))
+	fmt.Fprintf(bw, "<style>%s</style>\n", htmlStyle)
+	fmt.Fprintln(bw, "</head><body>")
+
+	if doc.Title != "" {
+		fmt.Fprintf(bw, "<h1>%s</h1>\n", html.EscapeString(doc.Title))
+	}
+	if doc.Subtitle != "" {
+		fmt.Fprintf(bw, "<p><em>%s</em></p>\n", html.EscapeString(doc.Subtitle))
+	}
+
+	for _, s := range doc.Sections {
+		if s.Heading != "" {
+			fmt.Fprintf(bw, "<h2>%s</h2>\n", html.EscapeString(s.Heading))
+		}
+		for _, b := range s.Blocks {
+			writeHTMLBlock(bw, b)
+		}
+	}
+
+	fmt.Fprintln(bw, "</body></html>")
+	return bw.Flush()
+}
+
+func writeHTMLBlock(w io.Writer, b Block) {
+	switch b := b.(type) {
+	case *Paragraph:
+		fmt.Fprintf(w, "<p>%s</p>\n", html.EscapeString(b.Text))
+
+	case *Fields:
+		fmt.Fprintln(w, "<dl>")
+		for _, f := range b.Fields {
+			fmt.Fprintf(w, "<dt>%s</dt><dd>%s</dd>\n", html.EscapeString(f.Name), html.EscapeString(f.Value))
+		}
+		fmt.Fprintln(w, "</dl>")
+
+	case *Table:
+		fmt.Fprintln(w, "<table>")
+		fmt.Fprint(w, "<tr>")
+		for _, c := range b.Columns {
+			fmt.Fprintf(w, "<th%s>%s</th>", numClass(c), html.EscapeString(c.Header))
+		}
+		fmt.Fprintln(w, "</tr>")
+		for _, row := range b.Rows {
+			fmt.Fprint(w, "<tr>")
+			for i, cell := range row {
+				fmt.Fprintf(w, "<td%s>%s</td>", numClass(b.Columns[i]), html.EscapeString(cell))
+			}
+			fmt.Fprintln(w, "</tr>")
+		}
+		fmt.Fprintln(w, "</table>")
+
+	case *List:
+		fmt.Fprintln(w, "<ul>")
+		for _, item := range b.Items {
+			fmt.Fprintf(w, "<li>%s</li>\n", html.EscapeString(item))
+		}
+		fmt.Fprintln(w, "</ul>")
+	}
+}
+
+func numClass(c Column) string {
+	if c.Numeric {
+		return ` class="num"`
+	}
+	return ""
+}
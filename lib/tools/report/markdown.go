@@ -0,0 +1,87 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Markdown renders documents as GitHub-flavored Markdown, suitable for
+// forum posts and wikis.
+type Markdown struct{}
+
+// Render writes the document as Markdown.
+func (Markdown) Render(w io.Writer, doc *Document) error {
+	bw := bufio.NewWriter(w)
+
+	if doc.Title != "" {
+		fmt.Fprintf(bw, "# %s\n\n", EscapeMarkdown(doc.Title))
+	}
+	if doc.Subtitle != "" {
+		fmt.Fprintf(bw, "_%s_\n\n", EscapeMarkdown(doc.Subtitle))
+	}
+
+	for _, s := range doc.Sections {
+		if s.Heading != "" {
+			fmt.Fprintf(bw, "## %s\n\n", EscapeMarkdown(s.Heading))
+		}
+		for _, b := range s.Blocks {
+			writeMarkdownBlock(bw, b)
+			fmt.Fprintln(bw)
+		}
+	}
+
+	return bw.Flush()
+}
+
+func writeMarkdownBlock(w io.Writer, b Block) {
+	switch b := b.(type) {
+	case *Paragraph:
+		fmt.Fprintln(w, EscapeMarkdown(b.Text))
+
+	case *Fields:
+		for _, f := range b.Fields {
+			fmt.Fprintf(w, "- **%s:** %s\n", EscapeMarkdown(f.Name), EscapeMarkdown(f.Value))
+		}
+
+	case *Table:
+		headers := make([]string, len(b.Columns))
+		rules := make([]string, len(b.Columns))
+		for i, c := range b.Columns {
+			headers[i] = escapeTableCell(c.Header)
+			rules[i] = "---"
+			if c.Numeric {
+				rules[i] = "---:"
+			}
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(headers, " | "))
+		fmt.Fprintf(w, "| %s |\n", strings.Join(rules, " | "))
+		for _, row := range b.Rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = escapeTableCell(cell)
+			}
+			fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+		}
+
+	case *List:
+		for _, item := range b.Items {
+			fmt.Fprintf(w, "- %s\n", EscapeMarkdown(item))
+		}
+	}
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", "&lt;",
+)
+
+// EscapeMarkdown escapes the characters Markdown would read as formatting
+// or inline HTML.
+func EscapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func escapeTableCell(s string) string {
+	return strings.ReplaceAll(EscapeMarkdown(s), "|", `\|`)
+}
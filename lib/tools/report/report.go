@@ -0,0 +1,157 @@
+// Package report renders analysis results as plain text, Markdown or HTML.
+//
+// Commands build a Document made of sections holding paragraphs, name/value
+// fields, tables and lists, then hand it to a Renderer. The same analysis
+// can thus be printed on a terminal, pasted in a forum post or mailed as an
+// HTML digest.
+//
+// Example usage:
+//
+//	doc := &report.Document{Title: "Empire summary", Subtitle: "Year 2450"}
+//	s := doc.AddSection("Economy")
+//	s.AddFields(report.Field{Name: "Planets", Value: "12"})
+//	t := s.AddTable(report.Column{Header: "Mineral"}, report.Column{Header: "kT", Numeric: true})
+//	t.AddRow("Ironium", "1200")
+//
+//	r, err := report.NewRenderer("markdown")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	err = r.Render(os.Stdout, doc)
+package report
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrUnknownFormat is returned by NewRenderer for an unsupported format.
+var ErrUnknownFormat = errors.New("unknown report format")
+
+// Formats lists the format names accepted by NewRenderer.
+var Formats = []string{"text", "markdown", "html"}
+
+// Renderer writes a document in one output format.
+type Renderer interface {
+	Render(w io.Writer, doc *Document) error
+}
+
+// NewRenderer returns the renderer for a format name (see Formats).
+// "md" is accepted for Markdown.
+func NewRenderer(format string) (Renderer, error) {
+	switch strings.ToLower(format) {
+	case "", "text", "txt":
+		return Text{}, nil
+	case "markdown", "md":
+		return Markdown{}, nil
+	case "html":
+		return HTML{}, nil
+	}
+	return nil, fmt.Errorf("%w: %q (valid: %s)", ErrUnknownFormat, format, strings.Join(Formats, ", "))
+}
+
+// Document is a titled report made of sections.
+type Document struct {
+	Title    string
+	Subtitle string // Optional line under the title, e.g. player and year
+	Sections []*Section
+}
+
+// AddSection appends a section and returns it.
+func (d *Document) AddSection(heading string) *Section {
+	s := &Section{Heading: heading}
+	d.Sections = append(d.Sections, s)
+	return s
+}
+
+// Section is a headed part of a document.
+// A section without heading continues the previous one.
+type Section struct {
+	Heading string
+	Blocks  []Block
+}
+
+// Block is a piece of section content: *Paragraph, *Fields, *Table or *List.
+type Block interface {
+	block()
+}
+
+// Paragraph is a line of free text.
+type Paragraph struct {
+	Text string
+}
+
+// Field is a name/value pair.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Fields is a list of name/value pairs, aligned in text output.
+type Fields struct {
+	Fields []Field
+}
+
+// Column is a table column. Numeric columns are right-aligned.
+type Column struct {
+	Header  string
+	Numeric bool
+}
+
+// Table is a grid of cells under column headers.
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+}
+
+// List is a bulleted list.
+type List struct {
+	Items []string
+}
+
+func (*Paragraph) block() {}
+func (*Fields) block()    {}
+func (*Table) block()     {}
+func (*List) block()      {}
+
+// AddParagraph appends a paragraph built like fmt.Sprintf.
+func (s *Section) AddParagraph(format string, args ...any) {
+	s.Blocks = append(s.Blocks, &Paragraph{Text: fmt.Sprintf(format, args...)})
+}
+
+// AddFields appends name/value pairs. Consecutive calls extend the same list.
+func (s *Section) AddFields(fields ...Field) {
+	if n := len(s.Blocks); n > 0 {
+		if last, ok := s.Blocks[n-1].(*Fields); ok {
+			last.Fields = append(last.Fields, fields...)
+			return
+		}
+	}
+	s.Blocks = append(s.Blocks, &Fields{Fields: fields})
+}
+
+// AddTable appends an empty table with the given columns and returns it.
+func (s *Section) AddTable(columns ...Column) *Table {
+	t := &Table{Columns: columns}
+	s.Blocks = append(s.Blocks, t)
+	return t
+}
+
+// AddList appends a bulleted list.
+func (s *Section) AddList(items ...string) {
+	s.Blocks = append(s.Blocks, &List{Items: items})
+}
+
+// AddRow appends a row. Missing cells are left empty; extra cells are dropped.
+func (t *Table) AddRow(cells ...string) {
+	row := make([]string, len(t.Columns))
+	copy(row, cells)
+	t.Rows = append(t.Rows, row)
+}
+
+// F formats a field value like fmt.Sprintf, for building fields inline.
+func F(name, format string, args ...any) Field {
+	return Field{Name: name, Value: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,100 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleDocument() *Document {
+	doc := &Document{Title: "Empire summary", Subtitle: "Player 1, year 2450"}
+
+	s := doc.AddSection("Economy")
+	s.AddFields(F("Planets", "%d", 12))
+	s.AddFields(Field{Name: "Population", Value: "1200000"})
+	t := s.AddTable(Column{Header: "Mineral"}, Column{Header: "kT", Numeric: true})
+	t.AddRow("Ironium", "1200")
+	t.AddRow("Boranium", "85")
+
+	notes := doc.AddSection("Notes")
+	notes.AddParagraph("Research %d%% <energy>", 15)
+	notes.AddList("Build *scouts*", "a|b")
+	return doc
+}
+
+func render(t *testing.T, format string) string {
+	t.Helper()
+	r, err := NewRenderer(format)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, r.Render(&buf, sampleDocument()))
+	return buf.String()
+}
+
+func TestNewRenderer(t *testing.T) {
+	for _, format := range append(Formats, "md", "", "HTML") {
+		_, err := NewRenderer(format)
+		assert.NoError(t, err, format)
+	}
+	_, err := NewRenderer("pdf")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestAddFieldsExtendsList(t *testing.T) {
+	s := &Section{}
+	s.AddFields(Field{Name: "A"})
+	s.AddFields(Field{Name: "B"})
+	require.Len(t, s.Blocks, 1)
+	assert.Len(t, s.Blocks[0].(*Fields).Fields, 2)
+}
+
+func TestAddRowPadsCells(t *testing.T) {
+	tbl := &Table{Columns: []Column{{Header: "A"}, {Header: "B"}}}
+	tbl.AddRow("x")
+	tbl.AddRow("1", "2", "3")
+	assert.Equal(t, [][]string{{"x", ""}, {"1", "2"}}, tbl.Rows)
+}
+
+func TestText(t *testing.T) {
+	out := render(t, "text")
+
+	assert.True(t, strings.HasPrefix(out, "Empire summary\n"+strings.Repeat("=", 60)+"\nPlayer 1, year 2450\n"))
+	assert.Contains(t, out, "\nEconomy:\n")
+	// Field names are aligned
+	assert.Contains(t, out, "  Planets:    12\n")
+	assert.Contains(t, out, "  Population: 1200000\n")
+	// Numeric columns are right-aligned
+	assert.Contains(t, out, "  Mineral     kT\n")
+	assert.Contains(t, out, "  Ironium   1200\n")
+	assert.Contains(t, out, "  Boranium    85\n")
+	assert.Contains(t, out, "  Research 15% <energy>\n")
+	assert.Contains(t, out, "  - Build *scouts*\n")
+}
+
+func TestMarkdown(t *testing.T) {
+	out := render(t, "markdown")
+
+	assert.True(t, strings.HasPrefix(out, "# Empire summary\n\n_Player 1, year 2450_\n\n"))
+	assert.Contains(t, out, "## Economy\n")
+	assert.Contains(t, out, "- **Planets:** 12\n")
+	assert.Contains(t, out, "| Mineral | kT |\n| --- | ---: |\n| Ironium | 1200 |\n")
+	assert.Contains(t, out, "Research 15% &lt;energy>\n")
+	assert.Contains(t, out, `- Build \*scouts\*`)
+	assert.Contains(t, out, "- a|b\n", "pipes only need escaping in tables")
+}
+
+func TestHTML(t *testing.T) {
+	out := render(t, "html")
+
+	assert.True(t, strings.HasPrefix(out, "<!DOCTYPE html>\n"))
+	assert.Contains(t, out, "<title>Empire summary</title>")
+	assert.Contains(t, out, "<h2>Economy</h2>")
+	assert.Contains(t, out, "<dt>Planets</dt><dd>12</dd>")
+	assert.Contains(t, out, `<th class="num">kT</th>`)
+	assert.Contains(t, out, `<td>Ironium</td><td class="num">1200</td>`)
+	assert.Contains(t, out, "<p>Research 15% &lt;energy&gt;</p>")
+	assert.True(t, strings.HasSuffix(out, "</body></html>\n"))
+}
@@ -0,0 +1,111 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// TemplateFuncs are the functions available to custom report templates in
+// addition to the text/template builtins:
+//
+//	add a b        a + b
+//	sub a b        a - b
+//	mul a b        a * b
+//	div a b        a / b (0 when b is 0)
+//	pct a b        a as a percentage of b, rounded down (0 when b is 0)
+//	upper s        s in upper case
+//	lower s        s in lower case
+//	pad n s        s left-aligned in a field of n characters
+//	rpad n s       s right-aligned in a field of n characters
+//	thousands n    n with comma thousands separators, e.g. 1,200,000
+//
+// Arithmetic functions accept any integer type and return int64.
+var TemplateFuncs = template.FuncMap{
+	"add": func(a, b any) int64 { return toInt64(a) + toInt64(b) },
+	"sub": func(a, b any) int64 { return toInt64(a) - toInt64(b) },
+	"mul": func(a, b any) int64 { return toInt64(a) * toInt64(b) },
+	"div": func(a, b any) int64 {
+		if toInt64(b) == 0 {
+			return 0
+		}
+		return toInt64(a) / toInt64(b)
+	},
+	"pct": func(a, b any) int64 {
+		if toInt64(b) == 0 {
+			return 0
+		}
+		return toInt64(a) * 100 / toInt64(b)
+	},
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"pad":       func(n int, v any) string { return fmt.Sprintf("%-*v", n, v) },
+	"rpad":      func(n int, v any) string { return fmt.Sprintf("%*v", n, v) },
+	"thousands": func(v any) string { return thousands(toInt64(v)) },
+}
+
+// ParseTemplate parses a custom report template with TemplateFuncs
+// available.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(TemplateFuncs).Option("missingkey=error").Parse(text)
+}
+
+// ParseTemplateFile reads and parses a custom report template file.
+func ParseTemplateFile(path string) (*template.Template, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTemplate(filepath.Base(path), string(text))
+}
+
+// Execute renders a parsed template over the report data.
+func Execute(w io.Writer, tmpl *template.Template, data *Data) error {
+	return tmpl.Execute(w, data)
+}
+
+func toInt64(v any) int64 {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int8:
+		return int64(n)
+	case int16:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case uint:
+		return int64(n)
+	case uint8:
+		return int64(n)
+	case uint16:
+		return int64(n)
+	case uint32:
+		return int64(n)
+	case uint64:
+		return int64(n)
+	}
+	panic(fmt.Sprintf("not an integer: %v (%T)", v, v))
+}
+
+func thousands(n int64) string {
+	s := fmt.Sprint(n)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	var b strings.Builder
+	if neg {
+		b.WriteByte('-')
+	}
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
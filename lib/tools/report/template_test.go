@@ -0,0 +1,98 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/lib/tools/notes"
+	"github.com/neper-stars/houston/store"
+)
+
+func loadData(t *testing.T) *Data {
+	t.Helper()
+	fileData, err := os.ReadFile("../../../testdata/scenario-map/history/game-2471.m1")
+	require.NoError(t, err)
+
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game-2471.m1", fileData))
+
+	d, err := NewData(gs, 0)
+	require.NoError(t, err)
+	return d
+}
+
+func TestNewData(t *testing.T) {
+	d := loadData(t)
+
+	assert.Equal(t, 2471, d.Game.Year)
+	assert.Equal(t, 72, d.Game.Turn)
+	assert.Equal(t, 1, d.Player.Number)
+	assert.True(t, d.Player.Self)
+	assert.Equal(t, 475, d.Player.Score)
+	assert.Len(t, d.Planets, 15)
+	assert.Len(t, d.Fleets, 14)
+	assert.NotEmpty(t, d.Players)
+	for i := 1; i < len(d.Planets); i++ {
+		assert.LessOrEqual(t, d.Planets[i-1].Name, d.Planets[i].Name)
+	}
+
+	_, err := NewData(d.Store, 15)
+	assert.Error(t, err)
+}
+
+func TestExecuteTemplate(t *testing.T) {
+	d := loadData(t)
+
+	tmpl, err := ParseTemplate("bulletin", `{{.Game.Year}} {{.Player.Name | upper}}
+{{range .Planets}}{{if .IsHomeworld}}HW {{.Name}}{{end}}{{end}}
+{{len .Fleets}} fleets, {{pct .Summary.Starbases .Summary.Planets}}% fortified`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Execute(&buf, tmpl, d))
+	assert.Contains(t, buf.String(), "2471 ")
+	assert.Contains(t, buf.String(), "HW ")
+	assert.Contains(t, buf.String(), "14 fleets, 20% fortified")
+
+	tmpl, err = ParseTemplate("bad", "{{.Game.Nope}}")
+	require.NoError(t, err)
+	assert.Error(t, Execute(&buf, tmpl, d))
+}
+
+func TestAddNotes(t *testing.T) {
+	d := loadData(t)
+	book, err := notes.Load(t.TempDir() + "/game.notes.json")
+	require.NoError(t, err)
+	planet := d.Planets[0].Number
+	_, err = book.Add(notes.Note{Planet: "Staging", PlanetNumber: &planet, Text: "staging area"})
+	require.NoError(t, err)
+	_, err = book.Add(notes.Note{FleetOwner: 1, Fleet: d.Fleets[1].Number, Text: "bomber group"})
+	require.NoError(t, err)
+	_, err = book.Add(notes.Note{FleetOwner: 2, Fleet: d.Fleets[2].Number, Text: "not ours"})
+	require.NoError(t, err)
+
+	d.AddNotes(book)
+	assert.Len(t, d.Notes, 3)
+	assert.Equal(t, []string{"staging area"}, d.Planets[0].Notes)
+	assert.Empty(t, d.Planets[1].Notes)
+	assert.Equal(t, []string{"bomber group"}, d.Fleets[1].Notes)
+	assert.Empty(t, d.Fleets[2].Notes)
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	tmpl, err := ParseTemplate("funcs",
+		`{{add 2 3}} {{sub 2 3}} {{mul 4 5}} {{div 7 2}} {{div 1 0}} {{pct 1 3}} {{thousands 1234567}} {{thousands -1000}} [{{pad 4 "ab"}}] [{{rpad 4 7}}]`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, tmpl.Execute(&buf, nil))
+	assert.Equal(t, "5 -1 20 3 0 33 1,234,567 -1,000 [ab  ] [   7]", buf.String())
+
+	tmpl, err = ParseTemplate("bad", `{{add "a" 1}}`)
+	require.NoError(t, err)
+	assert.Error(t, tmpl.Execute(&buf, nil), "non-integer arguments fail the template")
+}
@@ -0,0 +1,105 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Text renders documents as plain text for terminals.
+type Text struct{}
+
+// Render writes the document as plain text.
+func (Text) Render(w io.Writer, doc *Document) error {
+	bw := bufio.NewWriter(w)
+
+	if doc.Title != "" {
+		fmt.Fprintln(bw, doc.Title)
+		fmt.Fprintln(bw, strings.Repeat("=", 60))
+	}
+	if doc.Subtitle != "" {
+		fmt.Fprintln(bw, doc.Subtitle)
+	}
+
+	for _, s := range doc.Sections {
+		fmt.Fprintln(bw)
+		indent := ""
+		if s.Heading != "" {
+			fmt.Fprintf(bw, "%s:\n", s.Heading)
+			indent = "  "
+		}
+		for i, b := range s.Blocks {
+			if i > 0 {
+				fmt.Fprintln(bw)
+			}
+			writeTextBlock(bw, b, indent)
+		}
+	}
+
+	return bw.Flush()
+}
+
+func writeTextBlock(w io.Writer, b Block, indent string) {
+	switch b := b.(type) {
+	case *Paragraph:
+		fmt.Fprintf(w, "%s%s\n", indent, b.Text)
+
+	case *Fields:
+		width := 0
+		for _, f := range b.Fields {
+			width = max(width, textWidth(f.Name)+1)
+		}
+		for _, f := range b.Fields {
+			fmt.Fprintf(w, "%s%s %s\n", indent, pad(f.Name+":", width, false), f.Value)
+		}
+
+	case *Table:
+		widths := make([]int, len(b.Columns))
+		for i, c := range b.Columns {
+			widths[i] = textWidth(c.Header)
+		}
+		for _, row := range b.Rows {
+			for i, cell := range row {
+				widths[i] = max(widths[i], textWidth(cell))
+			}
+		}
+		line := func(cells []string) {
+			parts := make([]string, len(b.Columns))
+			for i, c := range b.Columns {
+				parts[i] = pad(cells[i], widths[i], c.Numeric)
+			}
+			fmt.Fprintf(w, "%s%s\n", indent, strings.TrimRight(strings.Join(parts, "  "), " "))
+		}
+		headers := make([]string, len(b.Columns))
+		for i, c := range b.Columns {
+			headers[i] = c.Header
+		}
+		line(headers)
+		for _, row := range b.Rows {
+			line(row)
+		}
+
+	case *List:
+		for _, item := range b.Items {
+			fmt.Fprintf(w, "%s- %s\n", indent, item)
+		}
+	}
+}
+
+// pad pads s with spaces to width runes, on the left when right-aligned.
+func pad(s string, width int, right bool) string {
+	n := width - textWidth(s)
+	if n <= 0 {
+		return s
+	}
+	if right {
+		return strings.Repeat(" ", n) + s
+	}
+	return s + strings.Repeat(" ", n)
+}
+
+func textWidth(s string) int {
+	return utf8.RuneCountInString(s)
+}
@@ -85,6 +85,18 @@ func (d *ODSDocument) SheetByName(name string) *ods.Table {
 	return nil
 }
 
+// SheetByNameOrAdd finds a sheet by name, appending an empty one if the
+// document has none (e.g. a template or report predating the sheet).
+// Adding a sheet may move the others: look them up again afterwards.
+func (d *ODSDocument) SheetByNameOrAdd(name string) *ods.Table {
+	if sheet := d.SheetByName(name); sheet != nil {
+		return sheet
+	}
+	tables := &d.data.Content.Body.Spreadsheet.Table
+	*tables = append(*tables, ods.Table{Name: name})
+	return &(*tables)[len(*tables)-1]
+}
+
 // SheetNames returns all sheet names.
 func (d *ODSDocument) SheetNames() []string {
 	var names []string
@@ -7,6 +7,7 @@ import (
 	"os"
 	"sort"
 
+	"github.com/neper-stars/houston/geom"
 	"github.com/neper-stars/houston/store"
 )
 
@@ -202,6 +203,10 @@ func (r *Reporter) GenerateReport(opts *ReportOptions) ([]byte, error) {
 		return nil, fmt.Errorf("failed to generate score estimates: %w", err)
 	}
 
+	if err := r.generateSalvageSheet(doc, opts); err != nil {
+		return nil, fmt.Errorf("failed to generate salvage: %w", err)
+	}
+
 	return doc.WriteBytes()
 }
 
@@ -511,9 +516,7 @@ func (r *Reporter) AnalyzeMineralShuffling(playerNumber int, threshold int64) []
 				continue
 			}
 
-			dx := float64(source.Planet.X - need.Planet.X)
-			dy := float64(source.Planet.Y - need.Planet.Y)
-			dist := math.Sqrt(dx*dx + dy*dy)
+			dist := geom.Distance(source.Planet.X, source.Planet.Y, need.Planet.X, need.Planet.Y)
 
 			if dist < bestDistance {
 				bestDistance = dist
@@ -2,9 +2,12 @@ package reporter
 
 import (
 	"fmt"
+	"math"
 	"sort"
 
 	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/lib/tools/salvage"
+	"github.com/neper-stars/houston/store"
 )
 
 // generateSummarySheet creates the Summary sheet.
@@ -646,3 +649,51 @@ func (r *Reporter) generateScoreEstimatesSheet(doc *ODSDocument, opts *ReportOpt
 
 	return nil
 }
+
+// generateSalvageSheet creates the Salvage sheet.
+// Lists visible salvage with the closest of the player's fleets able to carry it.
+func (r *Reporter) generateSalvageSheet(doc *ODSDocument, opts *ReportOptions) error {
+	sheet := doc.SheetByNameOrAdd(SheetSalvage)
+
+	// Clear and set headers
+	doc.ClearSheet(sheet, 0)
+	doc.SetHeaderRow(sheet, "Owner", "X", "Y", "Ironium", "Boranium", "Germanium", "Total", "Nearest Fleet", "Distance (ly)", "Free Cargo")
+
+	// Closest fleet with free cargo space for each salvage
+	nearest := make(map[*store.ObjectEntity]salvage.Advice)
+	for _, advice := range salvage.Nearby(r.store, opts.PlayerNumber, math.Inf(1)) {
+		if _, ok := nearest[advice.Salvage]; !ok {
+			nearest[advice.Salvage] = advice
+		}
+	}
+
+	for _, obj := range r.store.Salvage() {
+		owner := "Unknown"
+		if player, ok := r.store.Player(obj.Owner); ok {
+			owner = player.NamePlural
+		}
+		cargo := obj.GetCargo()
+
+		fleetName, distance, freeCargo := "", "", ""
+		if advice, ok := nearest[obj]; ok {
+			fleetName = advice.Fleet.Name()
+			distance = fmt.Sprintf("%.1f", advice.Distance)
+			freeCargo = fmt.Sprintf("%d", advice.FreeCargo)
+		}
+
+		doc.AppendRow(sheet,
+			owner,
+			int64(obj.X),
+			int64(obj.Y),
+			cargo.Ironium,
+			cargo.Boranium,
+			cargo.Germanium,
+			salvage.Minerals(cargo),
+			fleetName,
+			distance,
+			freeCargo,
+		)
+	}
+
+	return nil
+}
@@ -137,4 +137,5 @@ const (
 	SheetOpponentFleets  = "Opponent Fleets"
 	SheetNewDesigns      = "New Designs"
 	SheetScoreEstimates  = "Score Estimates"
+	SheetSalvage         = "Salvage" // Added to reports whose template lacks it
 )
@@ -0,0 +1,231 @@
+package research
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+)
+
+// Breakpoint kinds.
+const (
+	KindHull             = "hull"
+	KindStarbase         = "starbase"
+	KindBeam             = "beam"
+	KindTorpedo          = "torpedo"
+	KindScanner          = "scanner"
+	KindPlanetaryScanner = "planetary scanner"
+	KindTerraform        = "terraform"
+)
+
+// Kinds lists the breakpoint kinds in report order.
+var Kinds = []string{KindHull, KindStarbase, KindBeam, KindTorpedo, KindScanner, KindPlanetaryScanner, KindTerraform}
+
+// Breakpoint is a tech the player cannot use yet and that would improve on
+// what it has.
+type Breakpoint struct {
+	Kind   string
+	Name   string
+	Tech   data.TechRequirements // Levels needed
+	Levels int                   // Levels to gain, all fields together
+	Cost   int                   // Research resources left to reach the levels
+	Years  int                   // Years on the whole budget (-1 without budget)
+}
+
+// BreakpointOptions controls which breakpoints are listed.
+type BreakpointOptions struct {
+	PerKind int // Breakpoints listed per kind, cheapest first (default: 2, 0 for all)
+}
+
+// DefaultBreakpointOptions returns default breakpoint options.
+func DefaultBreakpointOptions() *BreakpointOptions {
+	return &BreakpointOptions{PerKind: 2}
+}
+
+// Breakpoints returns the next tech breakpoints of the player, cheapest
+// first: hulls it cannot build yet, beam weapons, torpedoes and scanners
+// better than its best, and faster terraforming. An item is better unless
+// one the player has matches it in every respect (a beam weapon of the
+// same kind with as much power and range, for instance). Hulls and items
+// that the race can never use are left out.
+//
+// The cost and years assume the whole research budget goes to the fields
+// needed; the budget spread by Generalized Research is not modelled.
+func (p *Plan) Breakpoints(opts *BreakpointOptions) []Breakpoint {
+	if opts == nil {
+		opts = DefaultBreakpointOptions()
+	}
+	var result []Breakpoint
+	for _, kind := range Kinds {
+		var list []Breakpoint
+		for _, c := range p.candidates(kind) {
+			list = append(list, p.breakpoint(kind, c.name, c.tech))
+		}
+		slices.SortStableFunc(list, compareBreakpoints)
+		if opts.PerKind > 0 && len(list) > opts.PerKind {
+			list = list[:opts.PerKind]
+		}
+		result = append(result, list...)
+	}
+	slices.SortStableFunc(result, compareBreakpoints)
+	return result
+}
+
+func compareBreakpoints(a, b Breakpoint) int {
+	if c := cmp.Compare(a.Cost, b.Cost); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Name, b.Name)
+}
+
+func (p *Plan) breakpoint(kind, name string, tech data.TechRequirements) Breakpoint {
+	b := Breakpoint{Kind: kind, Name: name, Tech: tech, Cost: p.Cost(tech)}
+	have, want := techLevels(p.Tech), techLevels(tech)
+	for i := range have {
+		b.Levels += max(0, want[i]-have[i])
+	}
+	b.Years = p.years(b.Cost)
+	return b
+}
+
+type candidate struct {
+	name string
+	tech data.TechRequirements
+}
+
+// candidates returns the items of a kind the player cannot use yet and
+// that improve on the ones it can.
+func (p *Plan) candidates(kind string) []candidate {
+	var result []candidate
+	has := func(tech data.TechRequirements) bool { return tech.CanBuildWith(p.Tech) }
+
+	switch kind {
+	case KindHull, KindStarbase:
+		for id, h := range data.Hulls {
+			if h.IsStarbase == (kind == KindStarbase) && !has(h.Tech) && p.canUseHull(id) {
+				result = append(result, candidate{h.Name, h.Tech})
+			}
+		}
+	case KindBeam:
+		for _, w := range data.BeamWeapons {
+			if has(w.Tech) {
+				continue
+			}
+			better := true
+			for _, o := range data.BeamWeapons {
+				if has(o.Tech) && o.IsSapper == w.IsSapper && o.IsGatling == w.IsGatling && o.Power >= w.Power && o.Range >= w.Range {
+					better = false
+				}
+			}
+			if better {
+				result = append(result, candidate{w.Name, w.Tech})
+			}
+		}
+	case KindTorpedo:
+		for _, w := range data.Torpedoes {
+			if has(w.Tech) {
+				continue
+			}
+			better := true
+			for _, o := range data.Torpedoes {
+				if has(o.Tech) && o.IsCapital == w.IsCapital && o.Power >= w.Power {
+					better = false
+				}
+			}
+			if better {
+				result = append(result, candidate{w.Name, w.Tech})
+			}
+		}
+	case KindScanner:
+		for _, s := range data.Scanners {
+			if has(s.Tech) || !p.canUseScanner(s.PenetratingRange) {
+				continue
+			}
+			// Thieving and cloaking scanners are for Super Stealth races
+			if (s.StealsCargo || s.CloakPercent > 0) && p.Player.PRT != blocks.PRTSuperStealth {
+				continue
+			}
+			better := true
+			for _, o := range data.Scanners {
+				if has(o.Tech) && o.NormalRange >= s.NormalRange && o.PenetratingRange >= s.PenetratingRange {
+					better = false
+				}
+			}
+			if better {
+				result = append(result, candidate{s.Name, s.Tech})
+			}
+		}
+	case KindPlanetaryScanner:
+		for _, s := range data.PlanetaryScanners {
+			if has(s.Tech) || !p.canUseScanner(s.PenetratingRange) {
+				continue
+			}
+			better := true
+			for _, o := range data.PlanetaryScanners {
+				if has(o.Tech) && o.NormalRange >= s.NormalRange && o.PenetratingRange >= s.PenetratingRange {
+					better = false
+				}
+			}
+			if better {
+				result = append(result, candidate{s.Name, s.Tech})
+			}
+		}
+	case KindTerraform:
+		for _, t := range data.Terraformers {
+			if has(t.Tech) {
+				continue
+			}
+			// Total terraforming is a Total Terraforming trait
+			if t.TerraformType == "Total" && !p.Player.HasLRT(blocks.LRTTotalTerraforming) {
+				continue
+			}
+			better := true
+			for _, o := range data.Terraformers {
+				if has(o.Tech) && o.TerraformType == t.TerraformType && o.TerraformRate >= t.TerraformRate {
+					better = false
+				}
+			}
+			if better {
+				result = append(result, candidate{t.Name, t.Tech})
+			}
+		}
+	}
+	return result
+}
+
+// canUseScanner reports whether the race can use a scanner: races with No
+// Advanced Scanners have no penetrating scanners.
+func (p *Plan) canUseScanner(penetratingRange int) bool {
+	return penetratingRange == 0 || !p.Player.HasLRT(blocks.LRTNoAdvancedScanners)
+}
+
+// hullPRTs lists the hulls only a primary racial trait can build.
+var hullPRTs = map[int]int{
+	data.HullRogue:          blocks.PRTSuperStealth,
+	data.HullStealthBomber:  blocks.PRTSuperStealth,
+	data.HullMiniColonyShip: blocks.PRTHyperExpansion,
+	data.HullMiniMorph:      blocks.PRTHyperExpansion,
+	data.HullMetaMorph:      blocks.PRTHyperExpansion,
+	data.HullFuelTransport:  blocks.PRTInnerStrength,
+	data.HullSuperFuelXport: blocks.PRTInnerStrength,
+	data.HullMiniMineLayer:  blocks.PRTSpaceDemolition,
+	data.HullSuperMineLayer: blocks.PRTSpaceDemolition,
+}
+
+// hullLRTs lists the hulls only a lesser racial trait can build.
+var hullLRTs = map[int]uint16{
+	data.HullMidgetMiner: blocks.LRTAdvancedRemoteMining,
+	data.HullMiner:       blocks.LRTAdvancedRemoteMining,
+	data.HullUltraMiner:  blocks.LRTAdvancedRemoteMining,
+}
+
+func (p *Plan) canUseHull(id int) bool {
+	if prt, ok := hullPRTs[id]; ok && p.Player.PRT != prt {
+		return false
+	}
+	if lrt, ok := hullLRTs[id]; ok && !p.Player.HasLRT(lrt) {
+		return false
+	}
+	return true
+}
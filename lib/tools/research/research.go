@@ -0,0 +1,187 @@
+// Package research plans the research of a player: what the next level of
+// each field costs, how many years it takes at the current research
+// budget, and which tech breakpoints (new hulls, weapon tiers, scanners and
+// terraforming) are closest.
+//
+// Example usage:
+//
+//	plan, err := research.NewPlan(gs, playerNumber)
+//	...
+//	for _, b := range plan.Breakpoints(nil) {
+//	    fmt.Printf("%s %s: %d resources, %d years\n", b.Kind, b.Name, b.Cost, b.Years)
+//	}
+package research
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+var (
+	ErrPlayerNotFound = errors.New("player not found")
+	ErrNoResearchData = errors.New("research settings are only known for the player of the file")
+)
+
+// Fields lists the research fields (blocks.ResearchField*) in game order.
+var Fields = []int{
+	blocks.ResearchFieldEnergy,
+	blocks.ResearchFieldWeapons,
+	blocks.ResearchFieldPropulsion,
+	blocks.ResearchFieldConstruction,
+	blocks.ResearchFieldElectronics,
+	blocks.ResearchFieldBiotechnology,
+}
+
+// FieldPlan is the state of one research field.
+type FieldPlan struct {
+	Field    int // blocks.ResearchField*
+	Level    int
+	Factor   float64 // Cost factor (data.ResearchFactor*)
+	Progress int     // Resources spent toward the next level
+	NextCost int     // Resources left to reach the next level (0 at the last level)
+	Years    int     // Years to reach the next level on the whole budget (-1 without budget)
+}
+
+// Plan is the research of a player.
+type Plan struct {
+	Player    *store.PlayerEntity
+	Tech      data.TechRequirements
+	Budget    int // Research resources per year
+	Current   int // Field being researched (blocks.ResearchField*)
+	Next      int // Field researched next (blocks.ResearchField*)
+	Fields    []FieldPlan
+	slowTech  bool
+	techCap   int
+	progress  [6]int
+	factors   [6]float64
+	totalTech int
+}
+
+// NewPlan returns the research plan of a player. The budget is what the
+// player spent on research last year, or else the research share of the
+// resources of its planets.
+func NewPlan(gs *store.GameStore, playerNumber int) (*Plan, error) {
+	player, ok := gs.Player(playerNumber)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrPlayerNotFound, playerNumber+1)
+	}
+	if !player.HasFullData {
+		return nil, fmt.Errorf("%w: player %d", ErrNoResearchData, playerNumber+1)
+	}
+
+	p := &Plan{
+		Player:   player,
+		Tech:     player.Tech,
+		Budget:   player.ResearchLastYear,
+		Current:  player.CurrentResearchField,
+		Next:     player.NextResearchField,
+		slowTech: gs.HasGameSetting(data.GameSettingSlowTech),
+		techCap:  player.TechCap(),
+		progress: [6]int{
+			int(player.TechProgress.Energy), int(player.TechProgress.Weapons), int(player.TechProgress.Propulsion),
+			int(player.TechProgress.Construction), int(player.TechProgress.Electronics), int(player.TechProgress.Biotech),
+		},
+	}
+	costs := []int{
+		player.ResearchCost.Energy, player.ResearchCost.Weapons, player.ResearchCost.Propulsion,
+		player.ResearchCost.Construction, player.ResearchCost.Electronics, player.ResearchCost.Biotech,
+	}
+	for i, c := range costs {
+		p.factors[i] = costFactor(c)
+	}
+	levels := techLevels(p.Tech)
+	for _, l := range levels {
+		p.totalTech += l
+	}
+	if p.Budget <= 0 {
+		resources := 0
+		for _, planet := range gs.PlanetsByOwner(playerNumber) {
+			resources += gs.CResourcesAtPlanet(planet, player)
+		}
+		p.Budget = resources * player.ResearchPercentage / 100
+	}
+
+	for _, field := range Fields {
+		fp := FieldPlan{
+			Field:    field,
+			Level:    levels[field],
+			Factor:   p.factors[field],
+			Progress: p.progress[field],
+		}
+		var target data.TechRequirements
+		setLevel(&target, field, fp.Level+1)
+		fp.NextCost = p.Cost(target)
+		fp.Years = p.years(fp.NextCost)
+		p.Fields = append(p.Fields, fp)
+	}
+	return p, nil
+}
+
+// Cost returns the research resources left to reach tech levels, counting
+// the resources already spent toward the next level of each field. Each
+// level gained makes later levels 10 resources dearer.
+func (p *Plan) Cost(target data.TechRequirements) int {
+	levels := techLevels(p.Tech)
+	wanted := techLevels(target)
+	total, cost := p.totalTech, 0
+	for _, field := range Fields {
+		for level := levels[field] + 1; level <= min(wanted[field], p.techCap); level++ {
+			c := data.TechCost(level, total, p.factors[field], p.slowTech)
+			if level == levels[field]+1 {
+				c = max(0, c-p.progress[field])
+			}
+			cost += c
+			total++
+		}
+	}
+	return cost
+}
+
+// years returns the years of research budget a cost takes.
+func (p *Plan) years(cost int) int {
+	if cost == 0 {
+		return 0
+	}
+	if p.Budget <= 0 {
+		return -1
+	}
+	return int(math.Ceil(float64(cost) / float64(p.Budget)))
+}
+
+// costFactor returns the cost factor of a research cost setting.
+func costFactor(setting int) float64 {
+	switch setting {
+	case blocks.ResearchCostExpensive:
+		return data.ResearchFactorExpensive
+	case blocks.ResearchCostCheap:
+		return data.ResearchFactorCheap
+	}
+	return data.ResearchFactorNormal
+}
+
+// techLevels returns tech levels indexed by field.
+func techLevels(t data.TechRequirements) [6]int {
+	return [6]int{t.Energy, t.Weapons, t.Propulsion, t.Construction, t.Electronics, t.Biotech}
+}
+
+func setLevel(t *data.TechRequirements, field, level int) {
+	switch field {
+	case blocks.ResearchFieldEnergy:
+		t.Energy = level
+	case blocks.ResearchFieldWeapons:
+		t.Weapons = level
+	case blocks.ResearchFieldPropulsion:
+		t.Propulsion = level
+	case blocks.ResearchFieldConstruction:
+		t.Construction = level
+	case blocks.ResearchFieldElectronics:
+		t.Electronics = level
+	case blocks.ResearchFieldBiotechnology:
+		t.Biotech = level
+	}
+}
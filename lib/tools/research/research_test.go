@@ -0,0 +1,117 @@
+package research
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+func loadStore(t *testing.T, path string) *store.GameStore {
+	t.Helper()
+	gs := store.New()
+	require.NoError(t, gs.AddFileWithXY(path))
+	return gs
+}
+
+// A Jack of all Trades at tech 12/12/9/12/10/7, with expensive Biotech
+func loadPlan(t *testing.T) *Plan {
+	t.Helper()
+	gs := loadStore(t, "../../../testdata/scenario-production-queue-change/game.m1")
+	p, err := NewPlan(gs, 0)
+	require.NoError(t, err)
+	return p
+}
+
+func TestNewPlan(t *testing.T) {
+	p := loadPlan(t)
+	assert.Equal(t, 5441, p.Budget)
+	assert.Equal(t, blocks.ResearchFieldBiotechnology, p.Current)
+	require.Len(t, p.Fields, 6)
+
+	// Construction 13 costs 13850 plus 10 per level held (62), 12486 spent
+	con := p.Fields[blocks.ResearchFieldConstruction]
+	assert.Equal(t, 12, con.Level)
+	assert.Equal(t, 13850+620-12486, con.NextCost)
+	assert.Equal(t, 1, con.Years)
+
+	bio := p.Fields[blocks.ResearchFieldBiotechnology]
+	assert.Equal(t, data.ResearchFactorExpensive, bio.Factor)
+	assert.Equal(t, (1440+620)*7/4-3451, bio.NextCost)
+}
+
+func TestCost(t *testing.T) {
+	p := loadPlan(t)
+	assert.Zero(t, p.Cost(p.Tech))
+
+	// Two Construction levels: the second costs 10 more for the first
+	target := p.Tech
+	target.Construction = 14
+	assert.Equal(t, (13850+620-12486)+(18040+630), p.Cost(target))
+}
+
+func TestBreakpoints(t *testing.T) {
+	p := loadPlan(t)
+
+	list := p.Breakpoints(nil)
+	require.NotEmpty(t, list)
+	for i, b := range list {
+		assert.Positive(t, b.Levels, b.Name)
+		assert.False(t, b.Tech.CanBuildWith(p.Tech), b.Name)
+		if i > 0 {
+			assert.LessOrEqual(t, list[i-1].Cost, b.Cost, "cheapest first")
+		}
+	}
+	assert.Equal(t, "Battleship", list[0].Name)
+	assert.Equal(t, 1984, list[0].Cost)
+
+	// Two per kind by default
+	perKind := make(map[string]int)
+	for _, b := range list {
+		perKind[b.Kind]++
+	}
+	for kind, n := range perKind {
+		assert.LessOrEqual(t, n, 2, kind)
+	}
+
+	// The Heavy Blaster does more than the Colloidal Phaser at the same range
+	all := p.Breakpoints(&BreakpointOptions{})
+	assert.Greater(t, len(all), len(list))
+	assert.True(t, hasBreakpoint(all, "Heavy Blaster"))
+	assert.False(t, hasBreakpoint(all, "Colloidal Phaser"), "already available")
+	assert.False(t, hasBreakpoint(all, "Rogue"), "Super Stealth only")
+	assert.False(t, hasBreakpoint(all, "Total Terraform +15"), "Total Terraforming only")
+}
+
+func TestBreakpointsRacialHulls(t *testing.T) {
+	// Space Demolition at tech 3
+	gs := loadStore(t, "../../../testdata/scenario-minefield/game.m1")
+	p, err := NewPlan(gs, 0)
+	require.NoError(t, err)
+
+	all := p.Breakpoints(&BreakpointOptions{})
+	assert.True(t, hasBreakpoint(all, "Super Mine Layer"))
+	assert.False(t, hasBreakpoint(all, "Meta Morph"))
+}
+
+func TestNewPlanErrors(t *testing.T) {
+	gs := loadStore(t, "../../../testdata/scenario-minefield/game.m1")
+
+	_, err := NewPlan(gs, 1)
+	assert.ErrorIs(t, err, ErrNoResearchData)
+	_, err = NewPlan(gs, 15)
+	assert.ErrorIs(t, err, ErrPlayerNotFound)
+}
+
+func hasBreakpoint(list []Breakpoint, name string) bool {
+	for _, b := range list {
+		if b.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,268 @@
+// Package review stages the orders players submit so that a host can check
+// them before generating a turn.
+//
+// Submitted X files are collected in a pending directory, <hostdir>/pending
+// by default. Each is checked against the host file (game, year, player,
+// submit flag) and scanned for exploits using the player's M file. The host
+// then approves orders, moving them next to the .hst file where turn
+// generation picks them up, or bounces them to <hostdir>/bounced with an
+// optional reason for the player.
+//
+// Example usage:
+//
+//	q := review.New("game", "")
+//	subs, _, _ := q.Review()
+//	for _, s := range subs {
+//	    if s.Flagged() {
+//	        q.Bounce(s.Player, "see the host")
+//	    } else {
+//	        q.Approve(s.Player)
+//	    }
+//	}
+package review
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/filenames"
+	"github.com/neper-stars/houston/lib/tools/exploits"
+	"github.com/neper-stars/houston/lib/tools/houserules"
+	"github.com/neper-stars/houston/lib/tools/xfilereader"
+	"github.com/neper-stars/houston/parser"
+)
+
+// ErrNotPending is returned when a player has no orders awaiting review.
+var ErrNotPending = errors.New("no pending orders")
+
+// Queue is the pending directory of a host directory.
+type Queue struct {
+	HostDir string
+	Pending string
+}
+
+// New returns the queue of a host directory. An empty pending directory
+// defaults to <hostDir>/pending.
+func New(hostDir, pending string) *Queue {
+	if pending == "" {
+		pending = filepath.Join(hostDir, "pending")
+	}
+	return &Queue{HostDir: hostDir, Pending: pending}
+}
+
+// BouncedDir returns the directory bounced orders are moved to.
+func (q *Queue) BouncedDir() string {
+	return filepath.Join(q.HostDir, "bounced")
+}
+
+// Submission is a pending X file with the problems found in it.
+type Submission struct {
+	Path       string
+	Player     int                   // Player number (1-16) from the file extension
+	Info       *xfilereader.FileInfo // nil when the file could not be read
+	Violations []string              // Mismatches with the host file and read errors
+	Detections []*exploits.Detection
+	Broken     []houserules.Violation // Set by the caller, see houserules.Rules.Check
+	Warnings   []string               // Problems with the context of the checks
+}
+
+// Flagged reports whether anything was found in the submission.
+func (s *Submission) Flagged() bool {
+	return len(s.Violations) > 0 || len(s.Detections) > 0 || len(s.Broken) > 0
+}
+
+// HostFile returns the single .hst file of the host directory.
+func (q *Queue) HostFile() (string, error) {
+	matches, err := filepath.Glob(filepath.Join(q.HostDir, "*.[hH][sS][tT]"))
+	if err != nil {
+		return "", err
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no .hst file found in %s", q.HostDir)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("several .hst files found in %s", q.HostDir)
+	}
+}
+
+// Files lists the X files of the pending directory in player order.
+func (q *Queue) Files() ([]string, error) {
+	entries, err := os.ReadDir(q.Pending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending directory: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && xFilePlayer(entry.Name()) > 0 {
+			files = append(files, filepath.Join(q.Pending, entry.Name()))
+		}
+	}
+	slices.SortFunc(files, func(a, b string) int {
+		return xFilePlayer(a) - xFilePlayer(b)
+	})
+	return files, nil
+}
+
+// Review checks every pending submission against the host file and
+// returns them in player order, with the header of the host file.
+func (q *Queue) Review() ([]*Submission, *blocks.FileHeader, error) {
+	host, err := q.HostFile()
+	if err != nil {
+		return nil, nil, err
+	}
+	hostData, err := os.ReadFile(host)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", host, err)
+	}
+	header, err := parser.FileData(hostData).FileHeader()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", host, err)
+	}
+
+	files, err := q.Files()
+	if err != nil {
+		return nil, nil, err
+	}
+	subs := make([]*Submission, 0, len(files))
+	for _, path := range files {
+		subs = append(subs, check(path, host, header))
+	}
+	return subs, header, nil
+}
+
+// check decodes a pending X file and checks it against the host file and
+// the player's M file.
+func check(path, host string, header *blocks.FileHeader) *Submission {
+	s := &Submission{Path: path, Player: xFilePlayer(path)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		s.Violations = append(s.Violations, err.Error())
+		return s
+	}
+	s.Info, err = xfilereader.ReadBytes(path, data)
+	if err != nil {
+		s.Violations = append(s.Violations, err.Error())
+		return s
+	}
+
+	info := s.Info
+	if info.GameID != header.GameID {
+		s.Violations = append(s.Violations,
+			fmt.Sprintf("game ID %d does not match host game %d", info.GameID, header.GameID))
+	}
+	if info.Year != header.Year() {
+		s.Violations = append(s.Violations,
+			fmt.Sprintf("orders are for year %d, host is at year %d", info.Year, header.Year()))
+	}
+	if info.PlayerIndex+1 != s.Player {
+		s.Violations = append(s.Violations,
+			fmt.Sprintf("file is for player %d, not player %d", info.PlayerIndex+1, s.Player))
+	}
+	if !info.IsSubmitted {
+		s.Violations = append(s.Violations, "turn was saved but not submitted")
+	}
+
+	// The player's M file gives the fleet and design context needed to
+	// detect order-based exploits
+	scanner := exploits.NewScanner()
+	mFile, _ := filenames.Find(filenames.Companion(host, filenames.M, s.Player))
+	if mData, err := os.ReadFile(mFile); err == nil {
+		if err := scanner.ScanFile(mFile, mData); err != nil {
+			s.Warnings = append(s.Warnings, fmt.Sprintf("failed to scan %s: %v", mFile, err))
+		}
+	}
+	known := scanner.Result().Count()
+	if err := scanner.ScanFile(path, data); err != nil {
+		s.Violations = append(s.Violations, fmt.Sprintf("exploit scan failed: %v", err))
+	}
+	s.Detections = scanner.Result().Detections[known:]
+	return s
+}
+
+// MFiles returns the M files of a player the house rules are checked
+// with: the one next to the host file, and those of earlier turns found in
+// the archive directory when not empty.
+func (q *Queue) MFiles(player int, archive string) ([]string, error) {
+	var files []string
+	if host, err := q.HostFile(); err == nil {
+		if mFile, ok := filenames.Find(filenames.Companion(host, filenames.M, player)); ok {
+			files = append(files, mFile)
+		}
+	}
+	if archive != "" {
+		archived, err := filepath.Glob(filepath.Join(archive, "*.[mM]"+strconv.Itoa(player)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the archive: %w", err)
+		}
+		files = append(files, archived...)
+	}
+	return files, nil
+}
+
+// find returns the pending X file of a player.
+func (q *Queue) find(player int) (string, error) {
+	files, err := q.Files()
+	if err != nil {
+		return "", err
+	}
+	for _, path := range files {
+		if xFilePlayer(path) == player {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%w for player %d in %s", ErrNotPending, player, q.Pending)
+}
+
+// Approve moves a player's pending orders into the host directory and
+// returns their new path.
+func (q *Queue) Approve(player int) (string, error) {
+	path, err := q.find(player)
+	if err != nil {
+		return "", err
+	}
+	dest := filepath.Join(q.HostDir, filepath.Base(path))
+	if err := os.Rename(path, dest); err != nil {
+		return "", fmt.Errorf("failed to approve %s: %w", path, err)
+	}
+	return dest, nil
+}
+
+// Bounce moves a player's pending orders to the bounced directory, with
+// the reason in a text file next to them, and returns their new path.
+func (q *Queue) Bounce(player int, reason string) (string, error) {
+	path, err := q.find(player)
+	if err != nil {
+		return "", err
+	}
+	bounced := q.BouncedDir()
+	if err := os.MkdirAll(bounced, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", bounced, err)
+	}
+	dest := filepath.Join(bounced, filepath.Base(path))
+	if err := os.Rename(path, dest); err != nil {
+		return "", fmt.Errorf("failed to bounce %s: %w", path, err)
+	}
+	if reason != "" {
+		if err := os.WriteFile(dest+".txt", []byte(reason+"\n"), 0644); err != nil {
+			return "", fmt.Errorf("failed to write bounce reason: %w", err)
+		}
+	}
+	return dest, nil
+}
+
+// xFilePlayer returns the player number (1-16) of an X file name, or 0 if
+// the name is not an X file.
+func xFilePlayer(name string) int {
+	if n := filenames.Parse(name); n.Kind == filenames.X {
+		return n.Player
+	}
+	return 0
+}
@@ -0,0 +1,151 @@
+package review
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testdata = "../../../testdata/"
+
+func copyFile(t *testing.T, src, dest string) {
+	t.Helper()
+	data, err := os.ReadFile(testdata + src)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(dest), 0755))
+	require.NoError(t, os.WriteFile(dest, data, 0644))
+}
+
+// hostDir sets up a host directory with the host file and player 1's M
+// file of a game, player 1's orders pending review, and the orders of
+// another game as player 2's.
+func hostDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	copyFile(t, "scenario-map/joat-spread-fleets/backup/Game.hst", filepath.Join(dir, "Game.hst"))
+	copyFile(t, "scenario-map/joat-spread-fleets/backup/Game.m1", filepath.Join(dir, "Game.m1"))
+	copyFile(t, "scenario-map/joat-spread-fleets/backup/Game.x1", filepath.Join(dir, "pending", "Game.x1"))
+	copyFile(t, "scenario-fleetsplit/game.x1", filepath.Join(dir, "pending", "Game.x2"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pending", "notes.txt"), []byte("not orders"), 0644))
+	return dir
+}
+
+func TestNew(t *testing.T) {
+	assert.Equal(t, filepath.Join("game", "pending"), New("game", "").Pending)
+	assert.Equal(t, "staging", New("game", "staging").Pending)
+	assert.Equal(t, filepath.Join("game", "bounced"), New("game", "").BouncedDir())
+}
+
+func TestReview(t *testing.T) {
+	dir := hostDir(t)
+	q := New(dir, "")
+
+	files, err := q.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "pending", "Game.x1"),
+		filepath.Join(dir, "pending", "Game.x2"),
+	}, files)
+
+	subs, header, err := q.Review()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1902316071), header.GameID)
+	require.Len(t, subs, 2)
+
+	s := subs[0]
+	assert.Equal(t, 1, s.Player)
+	require.NotNil(t, s.Info)
+	assert.Equal(t, header.Year(), s.Info.Year)
+	assert.Equal(t, []string{"turn was saved but not submitted"}, s.Violations)
+	assert.True(t, s.Flagged())
+
+	s = subs[1]
+	assert.Equal(t, 2, s.Player)
+	require.NotNil(t, s.Info)
+	assert.Contains(t, s.Violations, "file is for player 1, not player 2")
+	assert.Contains(t, s.Violations[0], "does not match host game 1902316071")
+}
+
+func TestReviewHostFile(t *testing.T) {
+	dir := t.TempDir()
+	_, _, err := New(dir, "").Review()
+	assert.ErrorContains(t, err, "no .hst file")
+
+	copyFile(t, "scenario-map/joat-spread-fleets/Game.hst", filepath.Join(dir, "a.hst"))
+	copyFile(t, "scenario-map/joat-spread-fleets/Game.hst", filepath.Join(dir, "b.HST"))
+	_, _, err = New(dir, "").Review()
+	assert.ErrorContains(t, err, "several .hst files")
+}
+
+func TestApprove(t *testing.T) {
+	dir := hostDir(t)
+	q := New(dir, "")
+
+	dest, err := q.Approve(1)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "Game.x1"), dest)
+	assert.FileExists(t, dest)
+	assert.NoFileExists(t, filepath.Join(dir, "pending", "Game.x1"))
+
+	files, err := q.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "pending", "Game.x2")}, files)
+
+	_, err = q.Approve(1)
+	assert.ErrorIs(t, err, ErrNotPending)
+	_, err = q.Approve(3)
+	assert.ErrorIs(t, err, ErrNotPending)
+}
+
+func TestBounce(t *testing.T) {
+	dir := hostDir(t)
+	q := New(dir, "")
+
+	dest, err := q.Bounce(2, "orders of another game")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "bounced", "Game.x2"), dest)
+	assert.FileExists(t, dest)
+	assert.NoFileExists(t, filepath.Join(dir, "pending", "Game.x2"))
+	reason, err := os.ReadFile(dest + ".txt")
+	require.NoError(t, err)
+	assert.Equal(t, "orders of another game\n", string(reason))
+
+	// Without a reason, no reason file
+	dest, err = q.Bounce(1, "")
+	require.NoError(t, err)
+	assert.FileExists(t, dest)
+	assert.NoFileExists(t, dest+".txt")
+
+	files, err := q.Files()
+	require.NoError(t, err)
+	assert.Empty(t, files)
+	_, err = q.Bounce(1, "")
+	assert.ErrorIs(t, err, ErrNotPending)
+
+	// Bounced orders are not pending, nor approvable
+	_, err = q.Approve(2)
+	assert.ErrorIs(t, err, ErrNotPending)
+}
+
+func TestMFiles(t *testing.T) {
+	dir := hostDir(t)
+	archive := t.TempDir()
+	copyFile(t, "scenario-map/history/game-2470.m1", filepath.Join(archive, "game-2470.m1"))
+	copyFile(t, "scenario-map/history/game-2470.m2", filepath.Join(archive, "game-2470.m2"))
+	q := New(dir, "")
+
+	files, err := q.MFiles(1, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "Game.m1")}, files)
+
+	files, err = q.MFiles(1, archive)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "Game.m1"), filepath.Join(archive, "game-2470.m1")}, files)
+
+	files, err = q.MFiles(2, archive)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(archive, "game-2470.m2")}, files)
+}
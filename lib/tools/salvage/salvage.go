@@ -0,0 +1,221 @@
+// Package salvage tracks the salvage left behind by scrapped and destroyed ships.
+//
+// Salvage is a map object holding minerals. It decays every year until it is
+// gone or a fleet picks it up. Track follows salvage across turns and records
+// how each wreck ended; Nearby suggests pickups for fleets with free cargo
+// space whose next leg passes close to salvage.
+//
+// Example usage:
+//
+//	for _, w := range salvage.Track(turnStores) {
+//	    fmt.Printf("salvage #%d: %s, %d kT lost to decay\n",
+//	        w.Number, w.Fate, salvage.Minerals(w.Decay()))
+//	}
+//	for _, a := range salvage.Nearby(gs, playerNumber, 20) {
+//	    fmt.Printf("%s passes %.0f ly from salvage\n", a.Fleet.Name(), a.Distance)
+//	}
+package salvage
+
+import (
+	"sort"
+
+	"github.com/neper-stars/houston/geom"
+	"github.com/neper-stars/houston/store"
+)
+
+// Fate is how a tracked wreck ended.
+type Fate int
+
+const (
+	FateActive   Fate = iota // Still present in the latest turn
+	FatePickedUp             // Vanished with a fleet sitting on its position
+	FateGone                 // Vanished: decayed away or out of scanner range
+)
+
+func (f Fate) String() string {
+	switch f {
+	case FateActive:
+		return "active"
+	case FatePickedUp:
+		return "picked up"
+	default:
+		return "gone"
+	}
+}
+
+// Sighting is a wreck as seen in one turn.
+type Sighting struct {
+	Turn  uint16
+	X, Y  int
+	Cargo store.Cargo
+}
+
+// Wreck is one piece of salvage followed across turns.
+type Wreck struct {
+	Owner         int
+	Number        int
+	SourceFleetID int
+	Sightings     []Sighting // In turn order
+	Fate          Fate
+
+	// PickedUpBy is the fleet found on the wreck position the turn it
+	// vanished (FatePickedUp only).
+	PickedUpBy *store.FleetEntity
+}
+
+// First returns the first sighting of the wreck.
+func (w *Wreck) First() Sighting {
+	return w.Sightings[0]
+}
+
+// Last returns the latest sighting of the wreck.
+func (w *Wreck) Last() Sighting {
+	return w.Sightings[len(w.Sightings)-1]
+}
+
+// Decay returns the minerals the wreck lost between its first and last sightings.
+func (w *Wreck) Decay() store.Cargo {
+	first, last := w.First().Cargo, w.Last().Cargo
+	return store.Cargo{
+		Ironium:   first.Ironium - last.Ironium,
+		Boranium:  first.Boranium - last.Boranium,
+		Germanium: first.Germanium - last.Germanium,
+	}
+}
+
+// Minerals returns the total minerals of a cargo in kT.
+func Minerals(c store.Cargo) int64 {
+	return c.Ironium + c.Boranium + c.Germanium
+}
+
+type wreckKey struct {
+	owner  int
+	number int
+}
+
+// Track follows salvage across turn stores, which must be sorted by turn.
+// Wrecks are returned in order of first sighting.
+func Track(turns []*store.GameStore) []*Wreck {
+	active := make(map[wreckKey]*Wreck)
+	var wrecks []*Wreck
+
+	for _, gs := range turns {
+		seen := make(map[wreckKey]bool)
+		for _, obj := range gs.Salvage() {
+			key := wreckKey{obj.Owner, obj.Number}
+			seen[key] = true
+			w, ok := active[key]
+			if ok && (w.Last().X != obj.X || w.Last().Y != obj.Y) {
+				// Salvage never moves: the number was reused by a new wreck
+				endWreck(gs, w)
+				ok = false
+			}
+			if !ok {
+				w = &Wreck{Owner: obj.Owner, Number: obj.Number, SourceFleetID: obj.SourceFleetID}
+				active[key] = w
+				wrecks = append(wrecks, w)
+			}
+			w.Sightings = append(w.Sightings, Sighting{Turn: gs.Turn, X: obj.X, Y: obj.Y, Cargo: obj.GetCargo()})
+		}
+
+		for key, w := range active {
+			if seen[key] {
+				continue
+			}
+			endWreck(gs, w)
+			// Object numbers are reused: a later wreck with this key is a new one
+			delete(active, key)
+		}
+	}
+
+	sort.SliceStable(wrecks, func(i, j int) bool {
+		return wrecks[i].First().Turn < wrecks[j].First().Turn
+	})
+	return wrecks
+}
+
+// endWreck sets the fate of a wreck missing from a turn.
+func endWreck(gs *store.GameStore, w *Wreck) {
+	last := w.Last()
+	if fleet := fleetAt(gs, last.X, last.Y); fleet != nil {
+		w.Fate = FatePickedUp
+		w.PickedUpBy = fleet
+	} else {
+		w.Fate = FateGone
+	}
+}
+
+// fleetAt returns a fleet at the given position, or nil.
+func fleetAt(gs *store.GameStore, x, y int) *store.FleetEntity {
+	for _, fleet := range gs.AllFleets() {
+		if fleet.X == x && fleet.Y == y {
+			return fleet
+		}
+	}
+	return nil
+}
+
+// FreeCargo returns the unused cargo space of a fleet in kT.
+func FreeCargo(gs *store.GameStore, fleet *store.FleetEntity) int64 {
+	capacity := int64(0)
+	for _, info := range fleet.GetDesigns(gs) {
+		capacity += int64(info.Design.GetCargoCapacity() * info.Count)
+	}
+	cargo := fleet.GetCargo()
+	// Colonists take 1 kT per 100
+	used := Minerals(cargo) + cargo.Population/100
+	return max(0, capacity-used)
+}
+
+// Advice is a suggested salvage pickup.
+type Advice struct {
+	Fleet     *store.FleetEntity
+	Salvage   *store.ObjectEntity
+	Distance  float64 // Closest approach of the fleet's next leg to the salvage (ly)
+	FreeCargo int64   // Free cargo space of the fleet (kT)
+}
+
+// Nearby lists salvage within maxDistance of the next leg of the player's
+// fleets with free cargo space, closest first. A fleet without a waypoint
+// elsewhere is measured from where it sits.
+func Nearby(gs *store.GameStore, playerNumber int, maxDistance float64) []Advice {
+	wrecks := gs.Salvage()
+	if len(wrecks) == 0 {
+		return nil
+	}
+
+	var advice []Advice
+	for _, fleet := range gs.FleetsByOwner(playerNumber) {
+		free := FreeCargo(gs, fleet)
+		if free <= 0 {
+			continue
+		}
+		toX, toY := nextLeg(fleet)
+		for _, obj := range wrecks {
+			if Minerals(obj.GetCargo()) == 0 {
+				continue
+			}
+			d := geom.SegmentDistance(float64(obj.X), float64(obj.Y),
+				float64(fleet.X), float64(fleet.Y), float64(toX), float64(toY))
+			if d <= maxDistance {
+				advice = append(advice, Advice{Fleet: fleet, Salvage: obj, Distance: d, FreeCargo: free})
+			}
+		}
+	}
+
+	sort.SliceStable(advice, func(i, j int) bool {
+		return advice[i].Distance < advice[j].Distance
+	})
+	return advice
+}
+
+// nextLeg returns the end of a fleet's next leg: its first waypoint away
+// from its current position, or the position itself.
+func nextLeg(fleet *store.FleetEntity) (int, int) {
+	for _, wp := range fleet.Waypoints {
+		if wp.X != fleet.X || wp.Y != fleet.Y {
+			return wp.X, wp.Y
+		}
+	}
+	return fleet.X, fleet.Y
+}
@@ -0,0 +1,93 @@
+package salvage
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func turnStore(turn uint16) *store.GameStore {
+	gs := store.New()
+	gs.Turn = turn
+	return gs
+}
+
+func addSalvage(gs *store.GameStore, number, x, y, ironium int) {
+	obj := &store.ObjectEntity{
+		Number:     number,
+		ObjectType: store.ObjectTypePacket,
+		IsSalvage:  true,
+		X:          x,
+		Y:          y,
+		Ironium:    ironium,
+	}
+	obj.Meta().Key = store.EntityKey{Type: store.EntityTypeObject, Number: number}
+	gs.Objects.Add(obj)
+}
+
+func addFleet(gs *store.GameStore, owner, number, x, y int) *store.FleetEntity {
+	fleet := &store.FleetEntity{Owner: owner, FleetNumber: number, X: x, Y: y}
+	fleet.Meta().Key = store.EntityKey{Type: store.EntityTypeFleet, Owner: owner, Number: number}
+	gs.Fleets.Add(fleet)
+	return fleet
+}
+
+func TestTrack(t *testing.T) {
+	t1, t2, t3 := turnStore(10), turnStore(11), turnStore(12)
+
+	addSalvage(t1, 1, 100, 100, 50) // Decays, then picked up
+	addSalvage(t2, 1, 100, 100, 45)
+	picker := addFleet(t3, 1, 4, 100, 100)
+
+	addSalvage(t1, 2, 300, 300, 20) // Decays away
+	addSalvage(t2, 2, 300, 300, 10)
+
+	addSalvage(t2, 3, 500, 500, 80) // Still there
+	addSalvage(t3, 3, 500, 500, 70)
+
+	addSalvage(t3, 1, 700, 700, 30) // Reused number: a new wreck
+
+	wrecks := Track([]*store.GameStore{t1, t2, t3})
+	require.Len(t, wrecks, 4)
+
+	assert.Equal(t, 1, wrecks[0].Number)
+	assert.Equal(t, FatePickedUp, wrecks[0].Fate)
+	assert.Same(t, picker, wrecks[0].PickedUpBy)
+	assert.Equal(t, int64(5), Minerals(wrecks[0].Decay()))
+	assert.Len(t, wrecks[0].Sightings, 2)
+
+	assert.Equal(t, 2, wrecks[1].Number)
+	assert.Equal(t, FateGone, wrecks[1].Fate)
+	assert.Nil(t, wrecks[1].PickedUpBy)
+
+	assert.Equal(t, 3, wrecks[2].Number)
+	assert.Equal(t, FateActive, wrecks[2].Fate)
+	assert.Equal(t, uint16(11), wrecks[2].First().Turn)
+	assert.Equal(t, int64(70), wrecks[2].Last().Cargo.Ironium)
+
+	assert.Equal(t, 1, wrecks[3].Number)
+	assert.Equal(t, FateActive, wrecks[3].Fate)
+	assert.Equal(t, 700, wrecks[3].First().X)
+}
+
+func TestNearby(t *testing.T) {
+	fileData, err := os.ReadFile("../../../testdata/scenario-message/event/fleet-scrapped/game.m1")
+	require.NoError(t, err)
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game.m1", fileData))
+	require.Len(t, gs.Salvage(), 1)
+
+	advice := Nearby(gs, 0, 30)
+	require.NotEmpty(t, advice)
+	for _, a := range advice {
+		assert.Positive(t, a.FreeCargo, a.Fleet.Name())
+		assert.Equal(t, FreeCargo(gs, a.Fleet), a.FreeCargo)
+		assert.InDelta(t, 28.44, a.Distance, 0.01)
+	}
+
+	assert.Empty(t, Nearby(gs, 0, 20), "salvage is 28 ly from the nearest freighter")
+}
@@ -0,0 +1,195 @@
+package scores
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+
+	"github.com/neper-stars/houston/lib/tools/maprenderer"
+)
+
+// Metric is a value of the points charted over the years.
+type Metric struct {
+	Name  string
+	Value func(Point) int
+}
+
+// Metrics are the values charted by default, one panel each.
+var Metrics = []Metric{
+	{Name: "Score", Value: func(p Point) int { return p.Score }},
+	{Name: "Resources", Value: func(p Point) int { return p.Resources }},
+	{Name: "Tech levels", Value: func(p Point) int { return p.TechLevels }},
+}
+
+// ChartOptions configures a chart.
+type ChartOptions struct {
+	Width, Height int
+	Metrics       []Metric     // Panels from top to bottom; Metrics when empty
+	Colors        []color.RGBA // Player colors from player 1; the map colors when empty
+}
+
+// Chart layout, in pixels.
+const (
+	chartMarginLeft   = 56
+	chartMarginRight  = 130 // Legend
+	chartMarginTop    = 10
+	chartMarginBottom = 24 // Year labels
+	chartPanelGap     = 22 // Panel title
+	chartTicks        = 4
+)
+
+var (
+	chartAxisColor  = color.RGBA{90, 90, 90, 255}
+	chartLabelColor = color.RGBA{200, 200, 200, 255}
+)
+
+// ChartSVG draws the points as line charts, one panel per metric sharing
+// the year axis, with a line per player in their map color.
+func ChartSVG(points []Point, opts ChartOptions) string {
+	if opts.Width <= 0 || opts.Height <= 0 {
+		opts.Width, opts.Height = 800, 600
+	}
+	metrics := opts.Metrics
+	if len(metrics) == 0 {
+		metrics = Metrics
+	}
+	colors := opts.Colors
+	if len(colors) == 0 {
+		colors, _ = maprenderer.PlayerPalette("default")
+	}
+	colorOf := func(player int) color.RGBA { return colors[(player-1)%len(colors)] }
+
+	b := maprenderer.NewSVGBuilderForRasterization(opts.Width, opts.Height)
+	if len(points) == 0 {
+		return b.String()
+	}
+
+	firstYear, lastYear := points[0].Year, points[0].Year
+	var players []int
+	races := make(map[int]string)
+	for _, p := range points {
+		firstYear, lastYear = min(firstYear, p.Year), max(lastYear, p.Year)
+		if !slices.Contains(players, p.Player) {
+			players = append(players, p.Player)
+		}
+		races[p.Player] = p.Race
+	}
+	slices.Sort(players)
+
+	left := float64(chartMarginLeft)
+	right := float64(opts.Width - chartMarginRight)
+	xOf := func(year int) float64 {
+		if lastYear == firstYear {
+			return (left + right) / 2
+		}
+		return left + (right-left)*float64(year-firstYear)/float64(lastYear-firstYear)
+	}
+
+	panelHeight := (float64(opts.Height-chartMarginTop-chartMarginBottom) - float64(len(metrics))*chartPanelGap) / float64(len(metrics))
+	for i, m := range metrics {
+		top := chartMarginTop + float64(i+1)*chartPanelGap + float64(i)*panelHeight
+		bottom := top + panelHeight
+
+		high := 0
+		for _, p := range points {
+			high = max(high, m.Value(p))
+		}
+		scale := niceCeil(high)
+		yOf := func(v int) float64 { return bottom - panelHeight*float64(v)/float64(scale) }
+
+		b.Text(left, top-6, m.Name, chartLabelColor, 11)
+		ticks := min(chartTicks, scale)
+		for t := 0; t <= ticks; t++ {
+			v := scale * t / ticks
+			y := yOf(v)
+			b.Line(left, y, right, y, rgb(chartAxisColor), 0.5)
+			b.Text(4, y+3, strconv.Itoa(v), chartLabelColor, 9)
+		}
+
+		for _, player := range players {
+			var line [][2]float64
+			for _, p := range points {
+				if p.Player == player {
+					line = append(line, [2]float64{xOf(p.Year), yOf(m.Value(p))})
+				}
+			}
+			col := colorOf(player)
+			if len(line) == 1 {
+				// A single year: a dot, as a path of one point draws nothing
+				b.Circle(line[0][0], line[0][1], 2, rgb(col), "", 0)
+				continue
+			}
+			var d strings.Builder
+			for j, pt := range line {
+				cmd := "L"
+				if j == 0 {
+					cmd = "M"
+				}
+				fmt.Fprintf(&d, "%s%.1f,%.1f ", cmd, pt[0], pt[1])
+			}
+			b.Path(strings.TrimSpace(d.String()), rgb(col), 1.5, "", "", "")
+		}
+	}
+
+	// Year axis under the last panel
+	axisY := float64(opts.Height - chartMarginBottom)
+	step := max(1, niceCeil((lastYear-firstYear)/8))
+	for year := firstYear; year <= lastYear; year += step {
+		x := xOf(year)
+		b.Line(x, axisY, x, axisY+4, rgb(chartAxisColor), 1)
+		b.Text(x-12, axisY+16, strconv.Itoa(year), chartLabelColor, 9)
+	}
+
+	for i, player := range players {
+		name := fmt.Sprintf("%d %s", player, races[player])
+		b.LegendItem(right+12, chartMarginTop+chartPanelGap+float64(i)*16, name, colorOf(player))
+	}
+	return b.String()
+}
+
+// WriteChartPNG draws the points as by ChartSVG and writes them as a PNG.
+func WriteChartPNG(w io.Writer, points []Point, opts ChartOptions) error {
+	if opts.Width <= 0 || opts.Height <= 0 {
+		opts.Width, opts.Height = 800, 600
+	}
+	c, err := canvas.ParseSVG(strings.NewReader(ChartSVG(points, opts)))
+	if err != nil {
+		return fmt.Errorf("failed to parse SVG: %w", err)
+	}
+	// The SVG is sized in pixels: rasterize at one dot per pixel
+	img := rasterizer.Draw(c, canvas.DPMM(float64(opts.Width)/c.W), canvas.DefaultColorSpace)
+	return png.Encode(w, cropTo(img, opts.Width, opts.Height))
+}
+
+// cropTo trims the rounding of the rasterized size.
+func cropTo(img *image.RGBA, width, height int) image.Image {
+	r := image.Rect(0, 0, width, height).Add(img.Bounds().Min).Intersect(img.Bounds())
+	return img.SubImage(r)
+}
+
+// niceCeil rounds a value up to 1, 2 or 5 times a power of ten, for axes.
+func niceCeil(v int) int {
+	if v <= 1 {
+		return 1
+	}
+	pow := int(math.Pow(10, math.Floor(math.Log10(float64(v)))))
+	for _, m := range []int{1, 2, 5, 10} {
+		if m*pow >= v {
+			return m * pow
+		}
+	}
+	return 10 * pow
+}
+
+func rgb(c color.RGBA) string {
+	return fmt.Sprintf("rgb(%d,%d,%d)", c.R, c.G, c.B)
+}
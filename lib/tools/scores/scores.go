@@ -0,0 +1,111 @@
+// Package scores extracts the score history of a game: the score of every
+// player year by year, broken down into the components of the Stars!
+// formula (see store.ScoreComponents), and charts it.
+//
+// Scores are computed from the data of each year, so a player is only
+// scored in the years holding their full data: their own M files, or the
+// host files.
+//
+// Example usage:
+//
+//	points, err := scores.Collect(turns)
+//	table, _ := export.TableOf("scores", points)
+//	table.WriteCSV(os.Stdout)
+package scores
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+// ErrNoData is returned when no turn holds the full data of a player.
+var ErrNoData = errors.New("no turn holds the full data of a player")
+
+// Point is the score of a player in a year.
+type Point struct {
+	Year   int    `json:"year"`
+	Player int    `json:"player"` // 1-16
+	Race   string `json:"race"`
+
+	Score          int `json:"score"` // Computed with the Stars! formula
+	PlanetPopScore int `json:"planet_pop_score"`
+	ResourceScore  int `json:"resource_score"`
+	StarbaseScore  int `json:"starbase_score"`
+	TechScore      int `json:"tech_score"`
+	ShipScore      int `json:"ship_score"`
+
+	Resources    int `json:"resources"`
+	Planets      int `json:"planets"`
+	Starbases    int `json:"starbases"`
+	TechLevels   int `json:"tech_levels"` // Sum of the tech levels
+	UnarmedShips int `json:"unarmed_ships"`
+	EscortShips  int `json:"escort_ships"`
+	CapitalShips int `json:"capital_ships"`
+
+	// ReportedScore is the score Stars! wrote in the file, nil when the
+	// file holds none.
+	ReportedScore *int `json:"reported_score"`
+}
+
+// Collect returns the scores of the players, by year then player. Players
+// lists the players to score, numbered from 0; all of them when empty.
+func Collect(turns []*store.GameStore, players ...int) ([]Point, error) {
+	var points []Point
+	for _, gs := range turns {
+		for _, player := range gs.AllPlayers() {
+			if !player.HasFullData {
+				continue
+			}
+			if len(players) > 0 && !slices.Contains(players, player.PlayerNumber) {
+				continue
+			}
+			points = append(points, score(gs, player))
+		}
+	}
+	if len(points) == 0 {
+		return nil, ErrNoData
+	}
+	slices.SortStableFunc(points, func(a, b Point) int {
+		if a.Year != b.Year {
+			return a.Year - b.Year
+		}
+		return a.Player - b.Player
+	})
+	// Several files of a year may hold the same player
+	points = slices.CompactFunc(points, func(a, b Point) bool {
+		return a.Year == b.Year && a.Player == b.Player
+	})
+	return points, nil
+}
+
+func score(gs *store.GameStore, player *store.PlayerEntity) Point {
+	sc := gs.ComputeScoreFromActualData(player.PlayerNumber)
+	tech := player.Tech
+	p := Point{
+		Year:           int(gs.Turn) + blocks.StarsBaseYear,
+		Player:         player.PlayerNumber + 1,
+		Race:           player.NamePlural,
+		Score:          sc.Score,
+		PlanetPopScore: sc.PlanetPopScore,
+		ResourceScore:  sc.ResourceScore,
+		StarbaseScore:  sc.StarbaseScore,
+		TechScore:      sc.TechScore,
+		ShipScore:      sc.ShipScore,
+		Resources:      sc.TotalResources,
+		Planets:        sc.PlanetCount,
+		Starbases:      sc.StarbaseCount,
+		TechLevels: tech.Energy + tech.Weapons + tech.Propulsion +
+			tech.Construction + tech.Electronics + tech.Biotech,
+		UnarmedShips: sc.UnarmedShips,
+		EscortShips:  sc.EscortShips,
+		CapitalShips: sc.CapitalShips,
+	}
+	if stored := player.StoredScore; stored != nil {
+		reported := stored.Score
+		p.ReportedScore = &reported
+	}
+	return p
+}
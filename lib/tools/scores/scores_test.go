@@ -0,0 +1,80 @@
+package scores
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func historyYear(t *testing.T, year int) *store.GameStore {
+	t.Helper()
+	gs := store.New()
+	for _, ext := range []string{"xy", "m1", "m2"} {
+		name := fmt.Sprintf("game-%d.%s", year, ext)
+		raw, err := os.ReadFile("../../../testdata/scenario-map/history/" + name)
+		require.NoError(t, err)
+		require.NoError(t, gs.AddFile(name, raw))
+	}
+	return gs
+}
+
+func TestCollect(t *testing.T) {
+	var turns []*store.GameStore
+	for year := 2478; year <= 2480; year++ {
+		turns = append(turns, historyYear(t, year))
+	}
+
+	points, err := Collect(turns)
+	require.NoError(t, err)
+	require.Len(t, points, 6, "both players of the M files, three years")
+	assert.Equal(t, []int{2478, 2478, 2479}, []int{points[0].Year, points[1].Year, points[2].Year})
+	assert.Equal(t, []int{1, 2}, []int{points[0].Player, points[1].Player})
+
+	last := points[4]
+	assert.Equal(t, 2480, last.Year)
+	assert.Equal(t, 1, last.Player)
+	assert.Equal(t, 16, last.Planets)
+	assert.Equal(t, 4, last.Starbases)
+	assert.Equal(t, 12, last.StarbaseScore)
+	assert.Equal(t, 69, last.TechLevels)
+	assert.Equal(t, last.PlanetPopScore+last.ResourceScore+last.StarbaseScore+last.TechScore+last.ShipScore, last.Score)
+	require.NotNil(t, last.ReportedScore)
+	assert.InDelta(t, *last.ReportedScore, last.Score, 15, "the formula is close to the Stars! score")
+
+	points, err = Collect(turns, 1)
+	require.NoError(t, err)
+	require.Len(t, points, 3)
+	assert.Equal(t, 2, points[0].Player)
+
+	_, err = Collect(turns, 5)
+	assert.ErrorIs(t, err, ErrNoData)
+}
+
+func TestWriteChartPNG(t *testing.T) {
+	points, err := Collect([]*store.GameStore{historyYear(t, 2479), historyYear(t, 2480)})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteChartPNG(&buf, points, ChartOptions{Width: 640, Height: 480}))
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 640, img.Bounds().Dx())
+	assert.Equal(t, 480, img.Bounds().Dy())
+
+	svg := ChartSVG(points, ChartOptions{})
+	assert.Contains(t, svg, "Hobbits")
+	assert.Contains(t, svg, "Tech levels")
+}
+
+func TestNiceCeil(t *testing.T) {
+	for v, want := range map[int]int{0: 1, 1: 1, 3: 5, 10: 10, 11: 20, 579: 1000, 9608: 10000, 150: 200} {
+		assert.Equal(t, want, niceCeil(v), "niceCeil(%d)", v)
+	}
+}
@@ -0,0 +1,213 @@
+// Package script runs Starlark scripts over the game state of a GameStore,
+// for custom per-turn analyses that need more logic than a report template.
+//
+// Starlark is a small dialect of Python (see
+// https://github.com/bazelbuild/starlark/blob/master/spec.md). Scripts run
+// top to bottom with these names predeclared:
+//
+//	game          the game state (see store.GameState), read-only
+//	previous      the game state of the previous turn given, or None
+//	player        number of the player the files belong to, as in game
+//	              owner fields (0-15), or -1 when unknown
+//	distance(a,b) distance between two values with x and y, e.g. planets
+//	json, math    the Starlark json and math modules
+//
+// The game state is exposed as structs with the field names of its JSON
+// form: game.turn, game.planets[0].name, game.fleets[0].waypoints, and so
+// on. Players are numbered from 0 and unowned planets have owner -1. The
+// output of print goes to the script's writer.
+//
+// Example, listing the enemy fleets within 100 light years of a planet of
+// the player:
+//
+//	mine = [p for p in game.planets if p.owner == player]
+//	for f in game.fleets:
+//	    if f.owner == player:
+//	        continue
+//	    for p in mine:
+//	        if distance(f, p) <= 100:
+//	            print("%s fleet %d near %s" % (game.players[f.owner].name_plural, f.number + 1, p.name))
+//	            break
+package script
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"go.starlark.net/lib/json"
+	starlarkmath "go.starlark.net/lib/math"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+
+	"github.com/neper-stars/houston/store"
+)
+
+// fileOptions enables the Starlark extensions that make scripts read like
+// Python: top-level loops, while, recursion and sets.
+var fileOptions = &syntax.FileOptions{
+	Set:             true,
+	While:           true,
+	TopLevelControl: true,
+	GlobalReassign:  true,
+	Recursion:       true,
+}
+
+// Env is what a script runs over.
+type Env struct {
+	Game     *store.GameStore
+	Previous *store.GameStore // Previous turn, may be nil
+	Player   int              // 0-15, or -1 when unknown
+}
+
+// Run executes a script over an environment. The name is used in error
+// messages. Errors raised by the script are *starlark.EvalError, whose
+// Backtrace shows where the script failed.
+func Run(w io.Writer, name string, src []byte, env Env) error {
+	thread := &starlark.Thread{
+		Name:  name,
+		Print: func(_ *starlark.Thread, msg string) { fmt.Fprintln(w, msg) },
+	}
+	_, err := starlark.ExecFileOptions(fileOptions, thread, name, src, Predeclared(env))
+	return err
+}
+
+// RunFile reads and executes a script file (see Run).
+func RunFile(w io.Writer, path string, env Env) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return Run(w, filepath.Base(path), src, env)
+}
+
+// Predeclared returns the names available to scripts over an environment.
+func Predeclared(env Env) starlark.StringDict {
+	game := ToValue(env.Game.ExportState())
+	game.Freeze()
+	previous := starlark.Value(starlark.None)
+	if env.Previous != nil {
+		previous = ToValue(env.Previous.ExportState())
+		previous.Freeze()
+	}
+	return starlark.StringDict{
+		"game":     game,
+		"previous": previous,
+		"player":   starlark.MakeInt(env.Player),
+		"distance": starlark.NewBuiltin("distance", distance),
+		"json":     json.Module,
+		"math":     starlarkmath.Module,
+	}
+}
+
+// ToValue converts a Go value to a Starlark value: structs become structs
+// with the field names of their JSON form, slices and arrays become lists
+// ([]byte becomes bytes), nil pointers become None.
+func ToValue(v any) starlark.Value {
+	return toValue(reflect.ValueOf(v))
+}
+
+func toValue(v reflect.Value) starlark.Value {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return starlark.None
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return starlark.None
+		}
+		return toValue(v.Elem())
+	case reflect.Bool:
+		return starlark.Bool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return starlark.MakeInt64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return starlark.MakeUint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return starlark.Float(v.Float())
+	case reflect.String:
+		return starlark.String(v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return starlark.Bytes(b)
+		}
+		elems := make([]starlark.Value, v.Len())
+		for i := range elems {
+			elems[i] = toValue(v.Index(i))
+		}
+		return starlark.NewList(elems)
+	case reflect.Map:
+		d := starlark.NewDict(v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			_ = d.SetKey(toValue(iter.Key()), toValue(iter.Value()))
+		}
+		return d
+	case reflect.Struct:
+		fields := make(starlark.StringDict, v.NumField())
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			fields[name] = toValue(v.Field(i))
+		}
+		return starlarkstruct.FromStringDict(starlarkstruct.Default, fields)
+	}
+	return starlark.String(fmt.Sprint(v.Interface()))
+}
+
+var errNoPosition = errors.New("value has no x and y")
+
+// distance implements distance(a, b).
+func distance(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var from, to starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &from, &to); err != nil {
+		return nil, err
+	}
+	x1, y1, err := position(from)
+	if err != nil {
+		return nil, fmt.Errorf("%s: first argument: %w", b.Name(), err)
+	}
+	x2, y2, err := position(to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: second argument: %w", b.Name(), err)
+	}
+	return starlark.Float(math.Hypot(x2-x1, y2-y1)), nil
+}
+
+// position reads the x and y attributes of a value.
+func position(v starlark.Value) (x, y float64, err error) {
+	attrs, ok := v.(starlark.HasAttrs)
+	if !ok {
+		return 0, 0, fmt.Errorf("%w: %s", errNoPosition, v.Type())
+	}
+	coords := [2]float64{}
+	for i, name := range []string{"x", "y"} {
+		attr, err := attrs.Attr(name)
+		if err != nil || attr == nil {
+			return 0, 0, fmt.Errorf("%w: %s", errNoPosition, v.Type())
+		}
+		f, ok := starlark.AsFloat(attr)
+		if !ok {
+			return 0, 0, fmt.Errorf("%w: %s.%s is %s", errNoPosition, v.Type(), name, attr.Type())
+		}
+		coords[i] = f
+	}
+	return coords[0], coords[1], nil
+}
@@ -0,0 +1,109 @@
+package script
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.starlark.net/starlark"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func loadGame(t *testing.T, dir string, files ...string) *store.GameStore {
+	t.Helper()
+	gs := store.New()
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join("../../../testdata", dir, name))
+		require.NoError(t, err)
+		require.NoError(t, gs.AddFile(name, data))
+	}
+	return gs
+}
+
+func TestRun(t *testing.T) {
+	gs := loadGame(t, "scenario-minefield", "game.xy", "game.m1")
+
+	src := `
+mine = [p for p in game.planets if p.owner == player]
+print("year", game.year, "planets", len(mine))
+home = [p for p in mine if p.homeworld][0]
+print("home", home.name, home.population)
+print("minefields", len(game.minefields), "previous", previous)
+print("self distance", distance(home, home))
+`
+	var out bytes.Buffer
+	require.NoError(t, Run(&out, "test.star", []byte(src), Env{Game: gs, Player: 0}))
+
+	var home *store.PlanetEntity
+	owned := 0
+	for _, p := range gs.AllPlanets() {
+		if p.Owner == 0 {
+			owned++
+			if p.IsHomeworld {
+				home = p
+			}
+		}
+	}
+	require.NotNil(t, home)
+	want := fmt.Sprintf("year %d planets %d\nhome %s %d\nminefields %d previous None\nself distance 0.0\n",
+		int(gs.Turn)+2400, owned, home.Name, home.Population, len(gs.Minefields()))
+	assert.Equal(t, want, out.String())
+}
+
+func TestRunPrevious(t *testing.T) {
+	gs := loadGame(t, "scenario-minefield", "game.xy", "game.m1")
+
+	var out bytes.Buffer
+	src := `print(game.turn - previous.turn, distance(game.planets[0], previous.planets[0]))`
+	require.NoError(t, Run(&out, "test.star", []byte(src), Env{Game: gs, Previous: gs, Player: -1}))
+	assert.Equal(t, "0 0.0\n", out.String())
+}
+
+func TestRunErrors(t *testing.T) {
+	gs := loadGame(t, "scenario-minefield", "game.xy", "game.m1")
+
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"syntax", "print(("},
+		{"read-only", "game.planets.append(1)"},
+		{"fail", `fail("rule broken")`},
+		{"distance", "distance(1, game.planets[0])"},
+		{"unknown field", "game.planets[0].nope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Run(&bytes.Buffer{}, "test.star", []byte(tt.src), Env{Game: gs, Player: 0})
+			require.Error(t, err)
+			if tt.name != "syntax" {
+				var evalErr *starlark.EvalError
+				assert.True(t, errors.As(err, &evalErr), "%T", err)
+			}
+		})
+	}
+}
+
+func TestToValue(t *testing.T) {
+	type inner struct {
+		Value int `json:"value,omitempty"`
+	}
+	v := ToValue(struct {
+		Name    string `json:"name"`
+		Skipped int    `json:"-"`
+		Plain   bool
+		Data    []byte `json:"data"`
+		Inner   *inner `json:"inner"`
+		Missing *inner `json:"missing"`
+		List    []inner
+	}{Name: "a", Plain: true, Data: []byte{1}, Inner: &inner{}, List: []inner{{1}, {2}}})
+
+	assert.Equal(t, `struct(List = [struct(value = 1), struct(value = 2)], Plain = True, data = b"\x01", inner = struct(value = 0), missing = None, name = "a")`,
+		v.String())
+}
@@ -0,0 +1,149 @@
+// Package starbaseadvisor suggests starbase upgrades for a player's planets.
+//
+// Each planet's current starbase design is compared against the player's
+// other starbase designs that can be built at current tech. Candidates that
+// add firepower or armor are ranked by cost-effectiveness: strength gained
+// per resource spent on the upgrade.
+//
+// Example usage:
+//
+//	for _, advice := range starbaseadvisor.Advise(gs, playerNumber) {
+//	    best := advice.Upgrades[0]
+//	    fmt.Printf("%s: upgrade to %s (+%d firepower, +%d armor)\n",
+//	        advice.Planet.Name, best.Design.Name, best.AddedFirepower, best.AddedArmor)
+//	}
+package starbaseadvisor
+
+import (
+	"sort"
+
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+// Stats holds the combat-relevant statistics of a starbase design.
+type Stats struct {
+	Firepower int       // Combat power from weapons (see DesignEntity.GetCombatPower)
+	Armor     int       // Total armor plus shields
+	Cost      data.Cost // Full build cost
+}
+
+// StatsFor computes the statistics of a starbase design.
+func StatsFor(d *store.DesignEntity) Stats {
+	return Stats{
+		Firepower: max(d.GetCombatPower(), 0),
+		Armor:     d.GetTotalArmorValue() + d.GetTotalShieldValue(),
+		Cost:      d.GetCost(),
+	}
+}
+
+// Upgrade is a candidate replacement for a planet's current starbase.
+type Upgrade struct {
+	Design         *store.DesignEntity
+	AddedFirepower int
+	AddedArmor     int
+
+	// Cost is the estimated upgrade cost: the difference between the new and
+	// current design costs, floored at zero for each resource and mineral.
+	Cost data.Cost
+
+	// Value is the strength gained (firepower plus armor) per resource.
+	Value float64
+}
+
+// Advice lists the upgrade options for one planet.
+type Advice struct {
+	Planet   *store.PlanetEntity
+	Current  *store.DesignEntity
+	Upgrades []Upgrade // Sorted by Value, best first
+}
+
+// Advise returns upgrade suggestions for every starbase owned by the player.
+// Planets with no worthwhile upgrade are omitted. Results are sorted by the
+// value of each planet's best upgrade.
+func Advise(gs *store.GameStore, playerNumber int) []*Advice {
+	var tech data.TechRequirements
+	if player, ok := gs.Player(playerNumber); ok {
+		tech = player.Tech
+	}
+
+	var candidates []*store.DesignEntity
+	for _, d := range gs.StarbaseDesignsByOwner(playerNumber) {
+		if d.Meta().Quality < store.QualityFull || !d.CanBuildWith(tech) {
+			continue
+		}
+		candidates = append(candidates, d)
+	}
+
+	var result []*Advice
+	for _, planet := range gs.PlanetsByOwner(playerNumber) {
+		if !planet.HasStarbase {
+			continue
+		}
+		current, ok := gs.StarbaseDesign(playerNumber, planet.StarbaseDesign)
+		if !ok {
+			continue
+		}
+		upgrades := rankUpgrades(StatsFor(current), current, candidates)
+		if len(upgrades) == 0 {
+			continue
+		}
+		result = append(result, &Advice{
+			Planet:   planet,
+			Current:  current,
+			Upgrades: upgrades,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		vi, vj := result[i].Upgrades[0].Value, result[j].Upgrades[0].Value
+		if vi != vj {
+			return vi > vj
+		}
+		return result[i].Planet.PlanetNumber < result[j].Planet.PlanetNumber
+	})
+	return result
+}
+
+// rankUpgrades compares candidates against the current design and returns
+// those adding strength, best value first.
+func rankUpgrades(current Stats, currentDesign *store.DesignEntity, candidates []*store.DesignEntity) []Upgrade {
+	var upgrades []Upgrade
+	for _, d := range candidates {
+		if d == currentDesign {
+			continue
+		}
+		stats := StatsFor(d)
+		if u, ok := compare(current, stats); ok {
+			u.Design = d
+			upgrades = append(upgrades, u)
+		}
+	}
+
+	sort.SliceStable(upgrades, func(i, j int) bool {
+		return upgrades[i].Value > upgrades[j].Value
+	})
+	return upgrades
+}
+
+// compare computes the gain of moving from current to candidate stats.
+// It returns false if the candidate adds no strength.
+func compare(current, candidate Stats) (Upgrade, bool) {
+	u := Upgrade{
+		AddedFirepower: candidate.Firepower - current.Firepower,
+		AddedArmor:     candidate.Armor - current.Armor,
+		Cost: data.Cost{
+			Resources: max(candidate.Cost.Resources-current.Cost.Resources, 0),
+			Ironium:   max(candidate.Cost.Ironium-current.Cost.Ironium, 0),
+			Boranium:  max(candidate.Cost.Boranium-current.Cost.Boranium, 0),
+			Germanium: max(candidate.Cost.Germanium-current.Cost.Germanium, 0),
+		},
+	}
+
+	gain := u.AddedFirepower + u.AddedArmor
+	if gain <= 0 {
+		return Upgrade{}, false
+	}
+	u.Value = float64(gain) / float64(max(u.Cost.Resources, 1))
+	return u, true
+}
@@ -0,0 +1,58 @@
+package starbaseadvisor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+func TestCompare(t *testing.T) {
+	current := Stats{Firepower: 100, Armor: 500, Cost: data.Cost{Resources: 600, Ironium: 120, Boranium: 80, Germanium: 250}}
+
+	t.Run("stronger design", func(t *testing.T) {
+		candidate := Stats{Firepower: 300, Armor: 700, Cost: data.Cost{Resources: 1000, Ironium: 100, Boranium: 180, Germanium: 250}}
+		u, ok := compare(current, candidate)
+		require.True(t, ok)
+		assert.Equal(t, 200, u.AddedFirepower)
+		assert.Equal(t, 200, u.AddedArmor)
+		assert.Equal(t, data.Cost{Resources: 400, Ironium: 0, Boranium: 100, Germanium: 0}, u.Cost)
+		assert.InDelta(t, 1.0, u.Value, 0.0001)
+	})
+
+	t.Run("trade armor for firepower", func(t *testing.T) {
+		candidate := Stats{Firepower: 400, Armor: 400, Cost: current.Cost}
+		u, ok := compare(current, candidate)
+		require.True(t, ok)
+		assert.Equal(t, -100, u.AddedArmor)
+		assert.InDelta(t, 200.0, u.Value, 0.0001, "free upgrade counts as one resource")
+	})
+
+	t.Run("weaker design", func(t *testing.T) {
+		candidate := Stats{Firepower: 50, Armor: 500}
+		_, ok := compare(current, candidate)
+		assert.False(t, ok)
+	})
+}
+
+func TestAdvise(t *testing.T) {
+	fileData, err := os.ReadFile("../../../testdata/scenario-map/history/game-2471.m1")
+	require.NoError(t, err)
+
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game-2471.m1", fileData))
+
+	// The player's alternative designs only add a mass driver and stargate,
+	// which add no combat strength, so no upgrade is suggested.
+	assert.Empty(t, Advise(gs, 0))
+
+	sb, ok := gs.StarbaseDesign(0, 0)
+	require.True(t, ok)
+	stats := StatsFor(sb)
+	assert.Greater(t, stats.Armor, 500, "hull armor plus shields")
+	assert.Equal(t, 888, stats.Cost.Resources)
+}
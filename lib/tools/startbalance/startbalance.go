@@ -0,0 +1,181 @@
+// Package startbalance scores how fair the starting positions of a new game
+// are, so that a host can reject a generated universe before players see it.
+//
+// Each homeworld is rated on its distance to the nearest other homeworld,
+// the planets habitable for its race within a radius, and the mineral
+// concentrations of the planets within that radius. The host file of the
+// first year holds all of this; M files only rate the positions they can see.
+//
+// Example usage:
+//
+//	report := startbalance.Analyze(gs, startbalance.DefaultRadius)
+//	if report.Fairness < 80 {
+//	    fmt.Println("regenerate the universe")
+//	}
+package startbalance
+
+import (
+	"math"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+// DefaultRadius is the distance in light years counted as a homeworld's
+// neighbourhood, about two years of travel at warp 6 to 7.
+const DefaultRadius = 150.0
+
+// Position rates a player's starting position.
+type Position struct {
+	Player    int // Player index (0-15)
+	Name      string
+	Homeworld string
+	X, Y      int
+
+	NearestNeighbor float64 // Distance to the closest other homeworld
+	Planets         int     // Planets within the radius, homeworld excluded
+	Green           int     // Planets habitable for the player's race within the radius
+	GreenValue      int     // Sum of the habitability percentages of those planets
+	Minerals        float64 // Mean mineral concentration (all three) within the radius
+
+	Score float64 // Relative to the average position, 1.0 is average
+}
+
+// Report rates all starting positions of a game.
+type Report struct {
+	Year      int
+	Radius    float64
+	Positions []Position
+
+	// Fairness is the lowest position score over the highest, in percent;
+	// 100 means every position rates the same.
+	Fairness float64
+}
+
+// Analyze rates the starting position of every player with a known
+// homeworld.
+func Analyze(gs *store.GameStore, radius float64) *Report {
+	report := &Report{Year: blocks.StarsBaseYear + int(gs.Turn), Radius: radius}
+
+	var homes []*store.PlanetEntity
+	var players []*store.PlayerEntity
+	for _, player := range gs.AllPlayers() {
+		home, ok := homeworld(gs, player)
+		if !ok {
+			continue
+		}
+		homes = append(homes, home)
+		players = append(players, player)
+	}
+
+	for i, home := range homes {
+		player := players[i]
+		pos := Position{
+			Player:          player.PlayerNumber,
+			Name:            player.NamePlural,
+			Homeworld:       home.Name,
+			X:               home.X,
+			Y:               home.Y,
+			NearestNeighbor: math.Inf(1),
+		}
+		for j, other := range homes {
+			if j != i {
+				pos.NearestNeighbor = min(pos.NearestNeighbor, distance(home, other))
+			}
+		}
+		if math.IsInf(pos.NearestNeighbor, 1) {
+			pos.NearestNeighbor = 0
+		}
+
+		var minerals, counted int
+		for _, p := range gs.AllPlanets() {
+			if distance(home, p) > radius {
+				continue
+			}
+			if p.CanSeeEnvironment() {
+				minerals += p.IroniumConc + p.BoraniumConc + p.GermaniumConc
+				counted++
+			}
+			if p.PlanetNumber == home.PlanetNumber {
+				continue
+			}
+			pos.Planets++
+			if !p.CanSeeEnvironment() || !player.HasFullData {
+				continue
+			}
+			if hab := gs.PctPlanetDesirability(p, player); hab > 0 {
+				pos.Green++
+				pos.GreenValue += hab
+			}
+		}
+		if counted > 0 {
+			pos.Minerals = float64(minerals) / float64(counted)
+		}
+		report.Positions = append(report.Positions, pos)
+	}
+
+	score(report)
+	return report
+}
+
+// homeworld returns the homeworld of a player.
+func homeworld(gs *store.GameStore, player *store.PlayerEntity) (*store.PlanetEntity, bool) {
+	if p, ok := gs.Planet(player.HomePlanetID); ok && p.IsHomeworld && p.Owner == player.PlayerNumber {
+		return p, true
+	}
+	for _, p := range gs.PlanetsByOwner(player.PlayerNumber) {
+		if p.IsHomeworld {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+func distance(a, b *store.PlanetEntity) float64 {
+	return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y))
+}
+
+// score rates each position as the mean of its metrics relative to the
+// average position, then derives the fairness. Metrics that are zero for
+// everyone (e.g. unknown habitability) are left out.
+func score(report *Report) {
+	metrics := []func(p *Position) float64{
+		func(p *Position) float64 { return p.NearestNeighbor },
+		func(p *Position) float64 { return float64(p.GreenValue) },
+		func(p *Position) float64 { return p.Minerals },
+	}
+
+	n := len(report.Positions)
+	if n == 0 {
+		return
+	}
+	used := 0
+	for _, metric := range metrics {
+		var total float64
+		for i := range report.Positions {
+			total += metric(&report.Positions[i])
+		}
+		if total == 0 {
+			continue
+		}
+		used++
+		mean := total / float64(n)
+		for i := range report.Positions {
+			report.Positions[i].Score += metric(&report.Positions[i]) / mean
+		}
+	}
+
+	scores := make([]float64, n)
+	for i := range report.Positions {
+		if used > 0 {
+			report.Positions[i].Score /= float64(used)
+		} else {
+			report.Positions[i].Score = 1
+		}
+		scores[i] = report.Positions[i].Score
+	}
+	if best := slices.Max(scores); best > 0 {
+		report.Fairness = 100 * slices.Min(scores) / best
+	}
+}
@@ -0,0 +1,53 @@
+package startbalance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func TestAnalyze(t *testing.T) {
+	gs := store.New()
+	require.NoError(t, gs.AddFileWithXY("../../../testdata/scenario-cloaking-visibility/game01/historic-backup/game-2401.hst"))
+
+	report := Analyze(gs, DefaultRadius)
+	assert.Equal(t, 2401, report.Year)
+	require.Len(t, report.Positions, 2)
+
+	a, b := report.Positions[0], report.Positions[1]
+	assert.Equal(t, "StealthBastards", a.Name)
+	assert.Equal(t, "Ars", b.Name)
+	// Homeworlds at (1535, 1910) and (2168, 2028)
+	assert.InDelta(t, 643.9, a.NearestNeighbor, 0.1)
+	assert.Equal(t, a.NearestNeighbor, b.NearestNeighbor)
+
+	for _, p := range report.Positions {
+		t.Logf("%s: %d planets, %d green, minerals %.1f, score %.2f", p.Name, p.Planets, p.Green, p.Minerals, p.Score)
+		assert.Positive(t, p.Planets)
+		assert.LessOrEqual(t, p.Green, p.Planets)
+		assert.Positive(t, p.Minerals)
+	}
+	assert.InDelta(t, 2.0, a.Score+b.Score, 1e-9)
+	assert.Greater(t, report.Fairness, 0.0)
+	assert.LessOrEqual(t, report.Fairness, 100.0)
+}
+
+func TestScore(t *testing.T) {
+	report := &Report{Positions: []Position{
+		{NearestNeighbor: 100, GreenValue: 300, Minerals: 50},
+		{NearestNeighbor: 100, GreenValue: 100, Minerals: 50},
+	}}
+	score(report)
+	// Green value is 1.5 and 0.5 of the mean, the other metrics are even
+	assert.InDelta(t, (1+1.5+1)/3, report.Positions[0].Score, 1e-9)
+	assert.InDelta(t, (1+0.5+1)/3, report.Positions[1].Score, 1e-9)
+	assert.InDelta(t, 100*2.5/3.5, report.Fairness, 1e-9)
+
+	// Unknown metrics are left out
+	even := &Report{Positions: []Position{{Minerals: 40}, {Minerals: 40}}}
+	score(even)
+	assert.InDelta(t, 100, even.Fairness, 1e-9)
+}
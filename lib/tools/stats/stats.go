@@ -0,0 +1,154 @@
+// Package stats collects the statistics of a player year by year, from the
+// turns of a game archive, for the spreadsheets players keep of their
+// empire.
+//
+// Example usage:
+//
+//	years, err := stats.Collect(stores, 2) // one GameStore per turn, player 3
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	table, err := export.TableOf("stats", years)
+package stats
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/eventlog"
+	"github.com/neper-stars/houston/lib/tools/summary"
+	"github.com/neper-stars/houston/store"
+)
+
+// ErrNoData is returned when no turn holds the full data of the player,
+// as found in their own M files or in the host file.
+var ErrNoData = errors.New("no turn holds the data of the player")
+
+// Minerals are amounts of minerals in kT.
+type Minerals struct {
+	Ironium   int64 `json:"ironium"`
+	Boranium  int64 `json:"boranium"`
+	Germanium int64 `json:"germanium"`
+}
+
+// Tech are the tech levels of a player.
+type Tech struct {
+	Energy       int `json:"energy"`
+	Weapons      int `json:"weapons"`
+	Propulsion   int `json:"propulsion"`
+	Construction int `json:"construction"`
+	Electronics  int `json:"electronics"`
+	Biotech      int `json:"biotech"`
+}
+
+// Year is the statistics of a player at a year. The JSON names are the
+// columns of the table export.TableOf makes of them.
+type Year struct {
+	Year       int   `json:"year"`
+	Planets    int   `json:"planets"`
+	Starbases  int   `json:"starbases"`
+	Population int64 `json:"population"`
+	Resources  int   `json:"resources"` // Produced per year
+
+	Mined    Minerals `json:"mined"`    // Mined per year, estimated from mines and concentrations
+	Minerals Minerals `json:"minerals"` // On the surface of owned planets
+
+	Fleets int `json:"fleets"`
+	Ships  int `json:"ships"`
+	// ShipsBuilt is the number of ships built during the year, from the
+	// build counts of the player's designs; nil when the turn before is
+	// not given, or when the counts went down as a design was deleted.
+	ShipsBuilt *int `json:"ships_built"`
+	ShipsLost  int  `json:"ships_lost"` // In the battles of the year
+
+	Tech       Tech `json:"tech"`
+	TechLevels int  `json:"tech_levels"` // Sum of the tech levels
+
+	Score int `json:"score"`
+	Rank  int `json:"rank"` // 0 when not known
+}
+
+// Collect returns the statistics of a player (0-15) for each turn holding
+// their full data, in the order of the turns. Turns are GameStores sorted
+// by turn, as loaded one per turn.
+func Collect(turns []*store.GameStore, playerNumber int) ([]Year, error) {
+	var years []Year
+	var lastTurn uint16
+	var lastBuilt int64
+	for _, gs := range turns {
+		player, ok := gs.Player(playerNumber)
+		if !ok || !player.HasFullData {
+			continue
+		}
+		s, err := summary.Summarize(gs, playerNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		y := Year{
+			Year:       int(gs.Turn) + blocks.StarsBaseYear,
+			Planets:    s.Planets,
+			Starbases:  s.Starbases,
+			Population: s.Population,
+			Resources:  s.Resources,
+			Mined:      minerals(s.MineralIncome),
+			Minerals:   minerals(s.Minerals),
+			Fleets:     s.Fleets,
+			Ships:      s.Ships,
+			ShipsLost:  battleLosses(gs, playerNumber),
+			Tech: Tech{
+				Energy:       s.Tech.Energy,
+				Weapons:      s.Tech.Weapons,
+				Propulsion:   s.Tech.Propulsion,
+				Construction: s.Tech.Construction,
+				Electronics:  s.Tech.Electronics,
+				Biotech:      s.Tech.Biotech,
+			},
+			Score: s.Score,
+			Rank:  s.Rank,
+		}
+		y.TechLevels = y.Tech.Energy + y.Tech.Weapons + y.Tech.Propulsion +
+			y.Tech.Construction + y.Tech.Electronics + y.Tech.Biotech
+
+		built := shipsBuilt(gs, playerNumber)
+		if len(years) > 0 && lastTurn+1 == gs.Turn && built >= lastBuilt {
+			n := int(built - lastBuilt)
+			y.ShipsBuilt = &n
+		}
+		lastTurn, lastBuilt = gs.Turn, built
+
+		years = append(years, y)
+	}
+	if len(years) == 0 {
+		return nil, fmt.Errorf("%w %d", ErrNoData, playerNumber+1)
+	}
+	return years, nil
+}
+
+func minerals(c store.Cargo) Minerals {
+	return Minerals{Ironium: c.Ironium, Boranium: c.Boranium, Germanium: c.Germanium}
+}
+
+// shipsBuilt returns the number of ships built of the current designs of
+// a player.
+func shipsBuilt(gs *store.GameStore, playerNumber int) int64 {
+	var total int64
+	for _, design := range gs.ShipDesignsByOwner(playerNumber) {
+		built, _ := design.ShipCounts()
+		total += built
+	}
+	return total
+}
+
+// battleLosses returns the ships a player lost in the battles of a turn, as
+// reported to them.
+func battleLosses(gs *store.GameStore, playerNumber int) int {
+	lost := 0
+	for _, e := range eventlog.FromStore(gs) {
+		if e.Type == eventlog.TypeBattle && e.Player == playerNumber+1 {
+			lost += e.Battle.Losses
+		}
+	}
+	return lost
+}
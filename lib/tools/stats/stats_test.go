@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func historyYear(t *testing.T, year int) *store.GameStore {
+	t.Helper()
+	gs := store.New()
+	for _, ext := range []string{"xy", "m1", "m2"} {
+		name := fmt.Sprintf("game-%d.%s", year, ext)
+		raw, err := os.ReadFile("../../../testdata/scenario-map/history/" + name)
+		require.NoError(t, err)
+		require.NoError(t, gs.AddFile(name, raw))
+	}
+	return gs
+}
+
+func TestCollect(t *testing.T) {
+	var turns []*store.GameStore
+	for year := 2478; year <= 2480; year++ {
+		turns = append(turns, historyYear(t, year))
+	}
+
+	years, err := Collect(turns, 0)
+	require.NoError(t, err)
+	require.Len(t, years, 3)
+	assert.Nil(t, years[0].ShipsBuilt, "nothing to compare the first year with")
+	require.NotNil(t, years[1].ShipsBuilt)
+
+	last := years[2]
+	assert.Equal(t, 2480, last.Year)
+	assert.Equal(t, 16, last.Planets)
+	assert.Equal(t, 4, last.Starbases)
+	assert.Equal(t, 43, last.Ships)
+	assert.Equal(t, 1, last.ShipsLost, "the ship lost in the battle at Redmond")
+	assert.Equal(t, Tech{Energy: 12, Weapons: 14, Propulsion: 14, Construction: 12, Electronics: 10, Biotech: 7}, last.Tech)
+	assert.Equal(t, 69, last.TechLevels)
+	assert.Positive(t, last.Resources)
+	assert.Positive(t, last.Mined.Ironium)
+
+	// Ships built are only known from the year just before
+	years, err = Collect([]*store.GameStore{turns[0], turns[2]}, 0)
+	require.NoError(t, err)
+	assert.Nil(t, years[1].ShipsBuilt)
+
+	_, err = Collect(turns, 5)
+	assert.ErrorIs(t, err, ErrNoData)
+}
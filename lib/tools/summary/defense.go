@@ -0,0 +1,103 @@
+package summary
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+// Ground combat modifiers: War Monger colonists attack better and
+// Inner-Strength colonists repel attacks better.
+const (
+	WarMongerAttackBonus      = 1.5
+	InnerStrengthDefenseBonus = 1.5
+)
+
+// PlanetDefense is the defense coverage and invasion resistance of a planet.
+type PlanetDefense struct {
+	Planet   *store.PlanetEntity
+	Defenses int
+	Defense  *data.PlanetaryDefense // Type installed at the owner's tech
+	Coverage float64                // Fraction of bombing blocked (0-1)
+
+	// ColonistsToCapture is how many colonists an attacker must land to take
+	// the planet, in whole loads of 100 colonists (1 kT); War Mongers need
+	// WarMongerAttackBonus times less.
+	ColonistsToCapture int64
+}
+
+// Defense sort keys for SortDefenses.
+const (
+	SortDefenseByCapture  = "capture"  // Easiest to invade first
+	SortDefenseByCoverage = "coverage" // Least covered first
+	SortDefenseByName     = "name"
+)
+
+// SortDefenseKeys lists the keys accepted by SortDefenses.
+var SortDefenseKeys = []string{SortDefenseByCapture, SortDefenseByCoverage, SortDefenseByName}
+
+// Defenses returns the defense coverage and invasion resistance of the
+// planets of a player, easiest to invade first.
+//
+// Defending colonists are strengthened by the planet's defense coverage, so
+// an attacker needs more than population × (1 + coverage) colonists, times
+// InnerStrengthDefenseBonus against Inner-Strength races.
+func Defenses(gs *store.GameStore, playerNumber int) ([]PlanetDefense, error) {
+	player, ok := gs.Player(playerNumber)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrPlayerNotFound, playerNumber+1)
+	}
+	advanced := true
+	if prt := data.GetPRT(player.PRT); prt != nil {
+		advanced = prt.CanBuildAdvancedDefenses
+	}
+	defense := data.BestPlanetaryDefense(player.Tech, advanced)
+
+	var result []PlanetDefense
+	for _, planet := range gs.PlanetsByOwner(playerNumber) {
+		pd := PlanetDefense{
+			Planet:   planet,
+			Defenses: planet.Defenses,
+			Defense:  defense,
+			Coverage: defense.Coverage(planet.Defenses),
+		}
+		strength := float64(planet.Population) * (1 + pd.Coverage)
+		if player.PRT == blocks.PRTInnerStrength {
+			strength *= InnerStrengthDefenseBonus
+		}
+		// Outnumber the defenders by at least one colonist
+		pd.ColonistsToCapture = (int64(math.Floor(strength))/100 + 1) * 100
+		result = append(result, pd)
+	}
+	if err := SortDefenses(result, SortDefenseByCapture); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SortDefenses sorts planet defenses by one of SortDefenseKeys.
+func SortDefenses(defenses []PlanetDefense, key string) error {
+	var compare func(a, b PlanetDefense) int
+	switch key {
+	case SortDefenseByCapture:
+		compare = func(a, b PlanetDefense) int { return cmp.Compare(a.ColonistsToCapture, b.ColonistsToCapture) }
+	case SortDefenseByCoverage:
+		compare = func(a, b PlanetDefense) int { return cmp.Compare(a.Coverage, b.Coverage) }
+	case SortDefenseByName:
+		compare = func(a, b PlanetDefense) int { return 0 }
+	default:
+		return fmt.Errorf("unknown sort key %q (expected one of %v)", key, SortDefenseKeys)
+	}
+	slices.SortStableFunc(defenses, func(a, b PlanetDefense) int {
+		if c := compare(a, b); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Planet.Name, b.Planet.Name)
+	})
+	return nil
+}
@@ -0,0 +1,265 @@
+package summary
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+// Mineral concentration decay. Every year a planet's operating mines add
+// to a counter per mineral, and the concentration drops by one point each
+// time the counter passes MineralDecayFactor / concentration² mine-years,
+// down to MinConcentration. The factor is fitted to recorded games, whose
+// files hold whole concentrations only.
+const (
+	MineralDecayFactor = 1250000
+	MinConcentration   = 1
+	DepletionHorizon   = 100 // Years forecast
+)
+
+// factoryGermanium is the germanium cost of a factory (kT).
+const factoryGermanium = 4
+
+// MineralForecast is the concentration outlook of one mineral of a planet.
+type MineralForecast struct {
+	Concentration int // Now
+	After10Years  int // Concentration in ten years at the current mining rate
+
+	// YearsToFloor is the number of years until the concentration falls to
+	// MinConcentration, or -1 if not within DepletionHorizon years.
+	YearsToFloor int
+}
+
+// PlanetDepletion is the mineral depletion forecast of a planet, and the
+// minerals its production queue needs against those it will have.
+type PlanetDepletion struct {
+	Planet   *store.PlanetEntity
+	Minerals [3]MineralForecast // Ironium, boranium, germanium
+
+	// QueueNeeds is what the non-auto items of the production queue still
+	// cost in minerals, and QueueYears the years the planet's resources take
+	// to build them (DepletionHorizon if it produces none).
+	QueueNeeds store.Cargo
+	QueueYears int
+
+	// Shortfall is the part of QueueNeeds that the surface minerals and the
+	// forecast mining over QueueYears do not cover. Minerals shipped in by
+	// freighters or packets are not counted.
+	Shortfall store.Cargo
+}
+
+// Short reports whether the planet's queue needs minerals it will not have.
+func (pd *PlanetDepletion) Short() bool {
+	return pd.Shortfall != store.Cargo{}
+}
+
+// Depleting reports whether any mineral falls to MinConcentration within
+// years.
+func (pd *PlanetDepletion) Depleting(years int) bool {
+	for _, m := range pd.Minerals {
+		if m.YearsToFloor >= 0 && m.YearsToFloor <= years {
+			return true
+		}
+	}
+	return false
+}
+
+// Depletion forecasts the mineral concentrations of the planets of a
+// player, assuming the mines operating today keep operating, and checks
+// their production queues against the minerals they will have. Planets
+// depleted soonest come first; planets that mine nothing and have no
+// shortfall are left out.
+func Depletion(gs *store.GameStore, playerNumber int) ([]PlanetDepletion, error) {
+	player, ok := gs.Player(playerNumber)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrPlayerNotFound, playerNumber+1)
+	}
+
+	var result []PlanetDepletion
+	for _, planet := range gs.PlanetsByOwner(playerNumber) {
+		pd := ForecastPlanet(planet, player)
+		if queue, ok := gs.ProductionQueue(planet.PlanetNumber); ok {
+			var resources int
+			pd.QueueNeeds, resources = queueCost(gs, planet.Owner, queue)
+			pd.QueueYears = DepletionHorizon
+			if perYear := gs.CResourcesAtPlanet(planet, player); perYear > 0 {
+				pd.QueueYears = min(DepletionHorizon, max(1, (resources+perYear-1)/perYear))
+			}
+			pd.Shortfall = shortfall(planet, player, pd.QueueNeeds, pd.QueueYears)
+		}
+		if operatingMines(planet, player) == 0 && !pd.Short() {
+			continue
+		}
+		result = append(result, pd)
+	}
+
+	slices.SortStableFunc(result, func(a, b PlanetDepletion) int {
+		return cmp.Or(
+			cmp.Compare(firstFloor(a), firstFloor(b)),
+			cmp.Compare(tenYearDrop(b), tenYearDrop(a)),
+			cmp.Compare(a.Planet.PlanetNumber, b.Planet.PlanetNumber),
+		)
+	})
+	return result, nil
+}
+
+// ForecastPlanet forecasts the mineral concentrations of a planet mined by
+// its current operating mines. Its production queue is not considered.
+func ForecastPlanet(planet *store.PlanetEntity, player *store.PlayerEntity) PlanetDepletion {
+	pd := PlanetDepletion{Planet: planet}
+	mines := operatingMines(planet, player)
+	for i, conc := range concentrations(planet) {
+		m := MineralForecast{Concentration: conc, YearsToFloor: -1}
+		if conc <= MinConcentration {
+			m.YearsToFloor = 0
+		}
+		d := &decay{conc: conc}
+		for year := 1; year <= DepletionHorizon; year++ {
+			d.mine(mines)
+			if year == 10 {
+				m.After10Years = d.conc
+			}
+			if d.conc <= MinConcentration && m.YearsToFloor < 0 {
+				m.YearsToFloor = year
+			}
+		}
+		pd.Minerals[i] = m
+	}
+	return pd
+}
+
+// decay tracks the concentration of one mineral year after year.
+type decay struct {
+	conc     int
+	progress int // Mine-years toward the next point lost
+}
+
+// mine runs mines for a year and returns the concentration they mined at.
+func (d *decay) mine(mines int) int {
+	mined := d.conc
+	if d.conc <= MinConcentration {
+		return mined
+	}
+	d.progress += mines
+	for d.conc > MinConcentration {
+		need := MineralDecayFactor / (d.conc * d.conc)
+		if d.progress < need {
+			break
+		}
+		d.progress -= need
+		d.conc--
+	}
+	return mined
+}
+
+// operatingMines returns the mines the population of a planet operates.
+func operatingMines(planet *store.PlanetEntity, player *store.PlayerEntity) int {
+	return max(0, min(planet.Mines, int(planet.Population)*player.Production.MinesOperate/10000))
+}
+
+func concentrations(planet *store.PlanetEntity) [3]int {
+	return [3]int{planet.IroniumConc, planet.BoraniumConc, planet.GermaniumConc}
+}
+
+// firstFloor returns the years until the first mineral of a planet falls
+// to MinConcentration, DepletionHorizon+1 if none does.
+func firstFloor(pd PlanetDepletion) int {
+	first := DepletionHorizon + 1
+	for _, m := range pd.Minerals {
+		if m.YearsToFloor >= 0 {
+			first = min(first, m.YearsToFloor)
+		}
+	}
+	return first
+}
+
+// tenYearDrop returns the largest concentration loss of a planet over ten
+// years.
+func tenYearDrop(pd PlanetDepletion) int {
+	drop := 0
+	for _, m := range pd.Minerals {
+		drop = max(drop, m.Concentration-m.After10Years)
+	}
+	return drop
+}
+
+// shortfall returns the part of needs that surface minerals and years of
+// decaying mining do not cover.
+func shortfall(planet *store.PlanetEntity, player *store.PlayerEntity, needs store.Cargo, years int) store.Cargo {
+	available := planet.GetMinerals()
+	mines := operatingMines(planet, player)
+	mined := [3]int64{}
+	for i, conc := range concentrations(planet) {
+		d := &decay{conc: conc}
+		for range years {
+			mined[i] += int64(mines * player.Production.MineProduction * d.mine(mines) / 1000)
+		}
+	}
+	available.Ironium += mined[0]
+	available.Boranium += mined[1]
+	available.Germanium += mined[2]
+
+	return store.Cargo{
+		Ironium:   max(0, needs.Ironium-available.Ironium),
+		Boranium:  max(0, needs.Boranium-available.Boranium),
+		Germanium: max(0, needs.Germanium-available.Germanium),
+	}
+}
+
+// queueCost returns the minerals and resources the non-auto items of a
+// production queue still cost. Auto items build only what the planet can
+// afford, and ship hulls are not in the data tables, so both are left out.
+func queueCost(gs *store.GameStore, owner int, queue *store.ProductionQueueEntity) (store.Cargo, int) {
+	var minerals store.Cargo
+	resources := 0
+	for _, item := range queue.Items {
+		if item.IsAutoItem() || item.Count <= 0 {
+			continue
+		}
+		cost, ok := itemCost(gs, owner, item)
+		if !ok {
+			continue
+		}
+		// One unit may be partly built (CompletePercent of 4095)
+		units := float64(item.Count) - float64(item.CompletePercent)/4095
+		minerals.Ironium += int64(units * float64(cost.Ironium))
+		minerals.Boranium += int64(units * float64(cost.Boranium))
+		minerals.Germanium += int64(units * float64(cost.Germanium))
+		resources += int(units * float64(cost.Resources))
+	}
+	return minerals, resources
+}
+
+// itemCost returns the cost of one unit of a production queue item.
+// Custom items 0-15 are ship designs and 16 and up starbase designs.
+func itemCost(gs *store.GameStore, owner int, item store.ProductionItem) (data.Cost, bool) {
+	if item.IsShipDesign() {
+		design, ok := gs.Design(owner, item.ItemId)
+		if item.ItemId >= 16 {
+			design, ok = gs.StarbaseDesign(owner, item.ItemId-16)
+		}
+		if !ok {
+			return data.Cost{}, false
+		}
+		return design.GetCost(), true
+	}
+
+	player, ok := gs.Player(owner)
+	if !ok {
+		return data.Cost{}, false
+	}
+	switch item.ItemId {
+	case blocks.ProductionItemFactory:
+		return data.Cost{Resources: player.Production.FactoryCost, Germanium: factoryGermanium}, true
+	case blocks.ProductionItemMine:
+		return data.Cost{Resources: player.Production.MineCost}, true
+	case blocks.ProductionItemDefense:
+		// Every defense type costs the same
+		return data.GetPlanetaryDefense(data.DefenseSDI).Cost, true
+	}
+	return data.Cost{}, false
+}
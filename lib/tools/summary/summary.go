@@ -0,0 +1,231 @@
+// Package summary computes a one-screen overview of a player's empire.
+//
+// It gathers the numbers players otherwise total by hand every turn:
+// planets, population, yearly resources, mineral stockpiles, mining income
+// and how long it lasts, fleets by role, planetary defenses, tech levels,
+// research settings and score.
+//
+// Example usage:
+//
+//	s, err := summary.Summarize(gs, playerNumber)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("%d planets, %d resources/year\n", s.Planets, s.Resources)
+package summary
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+var ErrPlayerNotFound = errors.New("player not found")
+
+// Role is the job of a ship design or fleet.
+type Role int
+
+// Roles, in priority order: a fleet takes the first role any of its designs has.
+const (
+	RoleWarship Role = iota
+	RoleBomber
+	RoleColonizer
+	RoleMinelayer
+	RoleMiner
+	RoleFreighter
+	RoleScout
+	RoleOther
+)
+
+// Roles lists every role in priority order.
+var Roles = []Role{RoleWarship, RoleBomber, RoleColonizer, RoleMinelayer, RoleMiner, RoleFreighter, RoleScout, RoleOther}
+
+func (r Role) String() string {
+	switch r {
+	case RoleWarship:
+		return "Warship"
+	case RoleBomber:
+		return "Bomber"
+	case RoleColonizer:
+		return "Colonizer"
+	case RoleMinelayer:
+		return "Minelayer"
+	case RoleMiner:
+		return "Miner"
+	case RoleFreighter:
+		return "Freighter"
+	case RoleScout:
+		return "Scout"
+	default:
+		return "Other"
+	}
+}
+
+// DesignRole classifies a ship design by its equipment.
+func DesignRole(d *store.DesignEntity) Role {
+	switch {
+	case d.HasBombs():
+		return RoleBomber
+	case d.GetCombatPower() > 0:
+		return RoleWarship
+	case d.CanColonize():
+		return RoleColonizer
+	case d.HasMinelaying():
+		return RoleMinelayer
+	case d.HasMining():
+		return RoleMiner
+	case d.GetCargoCapacity() > 0:
+		return RoleFreighter
+	case d.HasScanner():
+		return RoleScout
+	default:
+		return RoleOther
+	}
+}
+
+// FleetRole classifies a fleet by the first role, in priority order, any of
+// its known designs has.
+func FleetRole(gs *store.GameStore, fleet *store.FleetEntity) Role {
+	role := RoleOther
+	for _, info := range fleet.GetDesigns(gs) {
+		if info.Design != nil && info.Count > 0 {
+			role = min(role, DesignRole(info.Design))
+		}
+	}
+	return role
+}
+
+// RoleCount is the number of fleets and ships with a given role.
+type RoleCount struct {
+	Fleets int
+	Ships  int
+}
+
+// Summary is the empire overview of one player.
+type Summary struct {
+	Player *store.PlayerEntity
+	Year   int
+
+	Planets    int
+	Starbases  int
+	Population int64
+	Resources  int // Resources produced per year
+
+	Minerals      store.Cargo       // Surface minerals on owned planets (kT)
+	MineralIncome store.Cargo       // Minerals mined per year (kT)
+	Depletion     []PlanetDepletion // Mineral concentration forecast, soonest depleted first
+
+	Fleets int
+	Ships  int
+	Roles  map[Role]RoleCount
+
+	Defenses []PlanetDefense // Easiest planets to invade first
+
+	Tech              data.TechRequirements
+	ResearchPercent   int
+	ResearchField     int // blocks.ResearchField*
+	NextResearchField int // blocks.ResearchField*
+
+	Score         int
+	Rank          int
+	ScoreFromFile bool // True if Score was read from the file rather than computed
+}
+
+// Summarize builds the empire overview of a player.
+func Summarize(gs *store.GameStore, playerNumber int) (*Summary, error) {
+	player, ok := gs.Player(playerNumber)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrPlayerNotFound, playerNumber+1)
+	}
+
+	s := &Summary{
+		Player:            player,
+		Year:              int(gs.Turn) + blocks.StarsBaseYear,
+		Roles:             make(map[Role]RoleCount),
+		Tech:              player.Tech,
+		ResearchPercent:   player.ResearchPercentage,
+		ResearchField:     player.CurrentResearchField,
+		NextResearchField: player.NextResearchField,
+		Rank:              player.Rank,
+	}
+
+	for _, planet := range gs.PlanetsByOwner(playerNumber) {
+		s.Planets++
+		if planet.HasStarbase {
+			s.Starbases++
+		}
+		s.Population += planet.Population
+		s.Resources += gs.CResourcesAtPlanet(planet, player)
+
+		s.Minerals.Ironium += planet.Ironium
+		s.Minerals.Boranium += planet.Boranium
+		s.Minerals.Germanium += planet.Germanium
+
+		income := MiningIncome(planet, player)
+		s.MineralIncome.Ironium += income.Ironium
+		s.MineralIncome.Boranium += income.Boranium
+		s.MineralIncome.Germanium += income.Germanium
+	}
+
+	for _, fleet := range gs.FleetsByOwner(playerNumber) {
+		ships := 0
+		for _, info := range fleet.GetDesigns(gs) {
+			if info.Design != nil && info.Count > 0 {
+				ships += info.Count
+			}
+		}
+		if ships == 0 {
+			continue
+		}
+		s.Fleets++
+		s.Ships += ships
+		role := FleetRole(gs, fleet)
+		rc := s.Roles[role]
+		rc.Fleets++
+		rc.Ships += ships
+		s.Roles[role] = rc
+	}
+
+	defenses, err := Defenses(gs, playerNumber)
+	if err != nil {
+		return nil, err
+	}
+	s.Defenses = defenses
+
+	depletion, err := Depletion(gs, playerNumber)
+	if err != nil {
+		return nil, err
+	}
+	s.Depletion = depletion
+
+	if player.StoredScore != nil {
+		s.Score = player.StoredScore.Score
+		s.ScoreFromFile = true
+	} else {
+		s.Score = gs.ComputeScoreFromActualData(playerNumber).Score
+	}
+
+	return s, nil
+}
+
+// MiningIncome estimates the minerals mined at a planet per year.
+// Mines beyond what the population can operate produce nothing; each ten
+// operating mines extract MineProduction kT at 100% concentration.
+func MiningIncome(planet *store.PlanetEntity, player *store.PlayerEntity) store.Cargo {
+	// Mines operable per 10,000 colonists
+	operating := min(planet.Mines, int(planet.Population)*player.Production.MinesOperate/10000)
+	if operating <= 0 {
+		return store.Cargo{}
+	}
+	perMineral := func(conc int) int64 {
+		return int64(operating * player.Production.MineProduction * conc / 1000)
+	}
+	return store.Cargo{
+		Ironium:   perMineral(planet.IroniumConc),
+		Boranium:  perMineral(planet.BoraniumConc),
+		Germanium: perMineral(planet.GermaniumConc),
+	}
+}
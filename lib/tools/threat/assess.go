@@ -0,0 +1,168 @@
+package threat
+
+import (
+	"math"
+	"sort"
+
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/geom"
+	"github.com/neper-stars/houston/store"
+)
+
+// AssessOptions controls per-planet threat assessment.
+type AssessOptions struct {
+	Radius float64 // Enemy fleets beyond this distance in ly are ignored (default: 150)
+}
+
+// DefaultAssessOptions returns default assessment options.
+func DefaultAssessOptions() *AssessOptions {
+	return &AssessOptions{
+		Radius: 150,
+	}
+}
+
+// PlanetThreat is the threat assessment for a single owned planet.
+type PlanetThreat struct {
+	Planet *store.PlanetEntity
+
+	// Attack side
+	EnemyPower   float64 // Enemy firepower, weighted by proximity (full at 0 ly, none at Radius)
+	EnemyFleets  int     // Number of enemy fleets within Radius
+	NearestEnemy float64 // Distance to the nearest enemy fleet in ly (-1 if none)
+
+	// Defense side
+	StarbaseStrength int     // Firepower plus armor and shields of the starbase
+	GarrisonStrength int     // Firepower plus armor and shields of own fleets in orbit
+	DefenseCoverage  float64 // Fraction of bombing blocked by planetary defenses (0-1)
+
+	// Score is EnemyPower left after planetary defenses, minus starbase and
+	// garrison strength. Zero means the planet is adequately defended.
+	Score float64
+}
+
+// Assess scores every planet owned by viewer against nearby non-allied
+// fleets. The result is sorted with the most threatened planets first,
+// forming a "defend these first" list.
+func Assess(gs *store.GameStore, viewer int, opts *AssessOptions) []*PlanetThreat {
+	if opts == nil {
+		opts = DefaultAssessOptions()
+	}
+
+	viewerPlayer, hasViewer := gs.Player(viewer)
+
+	var enemies, garrison []fleetStrength
+	for _, fleet := range gs.AllFleets() {
+		if fleet.Owner == viewer {
+			garrison = append(garrison, fleetStrength{x: fleet.X, y: fleet.Y, power: FleetStrength(gs, fleet)})
+			continue
+		}
+		if hasViewer && data.PlayerRelation(viewerPlayer.GetRelationTo(fleet.Owner)) == data.RelationFriend {
+			continue
+		}
+		enemies = append(enemies, fleetStrength{x: fleet.X, y: fleet.Y, power: FleetFirepower(gs, fleet)})
+	}
+
+	var tech data.TechRequirements
+	if hasViewer {
+		tech = viewerPlayer.Tech
+	}
+
+	var result []*PlanetThreat
+	for _, planet := range gs.PlanetsByOwner(viewer) {
+		pt := &PlanetThreat{
+			Planet:          planet,
+			DefenseCoverage: DefenseCoverage(planet.Defenses, tech),
+		}
+		if planet.HasStarbase {
+			if sb, ok := gs.StarbaseDesign(viewer, planet.StarbaseDesign); ok {
+				pt.StarbaseStrength = designStrength(sb)
+			}
+		}
+		for _, g := range garrison {
+			if g.x == planet.X && g.y == planet.Y {
+				pt.GarrisonStrength += g.power
+			}
+		}
+		assessPlanet(pt, enemies, opts.Radius)
+		result = append(result, pt)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		if result[i].EnemyPower != result[j].EnemyPower {
+			return result[i].EnemyPower > result[j].EnemyPower
+		}
+		return result[i].Planet.PlanetNumber < result[j].Planet.PlanetNumber
+	})
+	return result
+}
+
+// fleetStrength is a fleet position with a precomputed strength value.
+type fleetStrength struct {
+	x, y  int
+	power int
+}
+
+// assessPlanet fills in the attack side and score of a planet threat.
+func assessPlanet(pt *PlanetThreat, enemies []fleetStrength, radius float64) {
+	pt.NearestEnemy = -1
+	for _, e := range enemies {
+		dist := geom.Distance(e.x, e.y, pt.Planet.X, pt.Planet.Y)
+		if dist > radius {
+			continue
+		}
+		pt.EnemyFleets++
+		if pt.NearestEnemy < 0 || dist < pt.NearestEnemy {
+			pt.NearestEnemy = dist
+		}
+		pt.EnemyPower += float64(e.power) * (1 - dist/radius)
+	}
+
+	score := pt.EnemyPower*(1-pt.DefenseCoverage) - float64(pt.StarbaseStrength+pt.GarrisonStrength)
+	pt.Score = math.Max(score, 0)
+}
+
+// FleetFirepower estimates the offensive power of a fleet from its designs.
+// Designs known only from a brief enemy scan carry no weapon data, so their
+// hull armor is used as a stand-in.
+func FleetFirepower(gs *store.GameStore, fleet *store.FleetEntity) int {
+	total := 0
+	for _, d := range fleet.GetDesigns(gs) {
+		power := designFirepower(d.Design)
+		if d.Design.Meta().Quality < store.QualityFull {
+			if hull := d.Design.Hull(); hull != nil {
+				power = hull.Armor
+			}
+		}
+		total += power * d.Count
+	}
+	return total
+}
+
+// FleetStrength estimates the combined firepower, armor and shields of a fleet.
+func FleetStrength(gs *store.GameStore, fleet *store.FleetEntity) int {
+	total := 0
+	for _, d := range fleet.GetDesigns(gs) {
+		total += designStrength(d.Design) * d.Count
+	}
+	return total
+}
+
+func designStrength(d *store.DesignEntity) int {
+	return designFirepower(d) + d.GetTotalArmorValue() + d.GetTotalShieldValue()
+}
+
+// designFirepower returns the combat power of a design, floored at zero.
+// Immobile designs (starbases) can otherwise go negative through the speed term.
+func designFirepower(d *store.DesignEntity) int {
+	return max(d.GetCombatPower(), 0)
+}
+
+// DefenseCoverage returns the fraction of bombing blocked by the given number
+// of planetary defenses, using the best defense available at the given tech.
+// Each defense blocks DefenseValue/1000 of the remaining damage.
+func DefenseCoverage(defenses int, tech data.TechRequirements) float64 {
+	return data.BestPlanetaryDefense(tech, true).Coverage(defenses)
+}
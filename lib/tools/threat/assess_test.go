@@ -0,0 +1,61 @@
+package threat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+func TestAssessPlanet(t *testing.T) {
+	enemies := []fleetStrength{
+		{x: 100, y: 100, power: 1000}, // In orbit: full weight
+		{x: 175, y: 100, power: 1000}, // Halfway to the radius: half weight
+		{x: 400, y: 100, power: 5000}, // Out of range
+	}
+
+	pt := &PlanetThreat{Planet: &store.PlanetEntity{X: 100, Y: 100}}
+	assessPlanet(pt, enemies, 150)
+
+	assert.Equal(t, 2, pt.EnemyFleets)
+	assert.InDelta(t, 0.0, pt.NearestEnemy, 0.001)
+	assert.InDelta(t, 1500.0, pt.EnemyPower, 0.001)
+	assert.InDelta(t, 1500.0, pt.Score, 0.001)
+}
+
+func TestAssessPlanet_Defended(t *testing.T) {
+	enemies := []fleetStrength{{x: 100, y: 100, power: 1000}}
+
+	pt := &PlanetThreat{
+		Planet:           &store.PlanetEntity{X: 100, Y: 100},
+		StarbaseStrength: 300,
+		DefenseCoverage:  0.5,
+	}
+	assessPlanet(pt, enemies, 150)
+	assert.InDelta(t, 200.0, pt.Score, 0.001)
+
+	pt.StarbaseStrength = 1000
+	assessPlanet(pt, enemies, 150)
+	assert.Zero(t, pt.Score, "score never goes negative")
+}
+
+func TestAssessPlanet_NoEnemies(t *testing.T) {
+	pt := &PlanetThreat{Planet: &store.PlanetEntity{X: 100, Y: 100}}
+	assessPlanet(pt, nil, 150)
+	assert.Equal(t, -1.0, pt.NearestEnemy)
+	assert.Zero(t, pt.Score)
+}
+
+func TestDefenseCoverage(t *testing.T) {
+	assert.Zero(t, DefenseCoverage(0, data.TechRequirements{}))
+
+	// 100 SDI at no tech: 1 - 0.99^100
+	assert.InDelta(t, 0.634, DefenseCoverage(100, data.TechRequirements{}), 0.001)
+
+	// Better tech unlocks better defenses
+	assert.Greater(t,
+		DefenseCoverage(100, data.TechRequirements{Energy: 10}),
+		DefenseCoverage(100, data.TechRequirements{}))
+}
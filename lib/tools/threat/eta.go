@@ -0,0 +1,217 @@
+// Package threat provides analyses of enemy activity from a player's
+// point of view, such as projecting where enemy fleets are heading and
+// when they may arrive.
+//
+// Example usage:
+//
+//	predictions := threat.PredictArrivals(storesByTurn, myPlayer, nil)
+//	for _, p := range predictions {
+//	    if p.Threatens() {
+//	        fmt.Printf("%s is heading for one of our planets\n", p.Fleet.Name())
+//	    }
+//	}
+package threat
+
+import (
+	"math"
+	"sort"
+
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/geom"
+	"github.com/neper-stars/houston/store"
+)
+
+// PredictOptions controls enemy fleet arrival prediction.
+type PredictOptions struct {
+	MaxYears     int     // Only report destinations reachable within this many years (default: 5)
+	MaxDeviation float64 // Maximum distance in ly a planet may lie off the projected track (default: 20)
+}
+
+// DefaultPredictOptions returns default prediction options.
+func DefaultPredictOptions() *PredictOptions {
+	return &PredictOptions{
+		MaxYears:     5,
+		MaxDeviation: 20,
+	}
+}
+
+// Destination is a planet a fleet may be heading for.
+type Destination struct {
+	Planet        *store.PlanetEntity
+	Distance      float64 // Distance from the fleet in ly
+	Deviation     float64 // Distance in ly between the planet and the projected track
+	EarliestYears int     // Arrival if the fleet speeds up by one warp step
+	LatestYears   int     // Arrival if the fleet slows down by one warp step
+	Threatened    bool    // Planet is owned by the viewing player
+}
+
+// Prediction is the projected course of a single enemy fleet.
+type Prediction struct {
+	Fleet        *store.FleetEntity
+	Speed        float64 // Observed speed in ly per year
+	Heading      float64 // Heading in radians (game coordinates, Y up)
+	FromHistory  bool    // Course derived from positions in earlier turns rather than DeltaX/DeltaY
+	Destinations []Destination
+}
+
+// Threatens returns true if any likely destination is owned by the viewing player.
+func (p *Prediction) Threatens() bool {
+	for _, d := range p.Destinations {
+		if d.Threatened {
+			return true
+		}
+	}
+	return false
+}
+
+// PredictArrivals projects the course of every moving non-allied fleet in
+// the latest turn and lists the planets along its track. Turns must be
+// ordered oldest first; when the same fleet is visible in the previous turn
+// its observed displacement is preferred over the DeltaX/DeltaY heading.
+//
+// Predictions are sorted so that fleets threatening the viewer come first,
+// then by earliest arrival, then by owner and fleet number.
+func PredictArrivals(turns []*store.GameStore, viewer int, opts *PredictOptions) []*Prediction {
+	if len(turns) == 0 {
+		return nil
+	}
+	if opts == nil {
+		opts = DefaultPredictOptions()
+	}
+
+	latest := turns[len(turns)-1]
+	var previous *store.GameStore
+	if len(turns) > 1 {
+		previous = turns[len(turns)-2]
+	}
+
+	viewerPlayer, hasViewer := latest.Player(viewer)
+	planets := latest.AllPlanets()
+
+	var result []*Prediction
+	for _, fleet := range latest.AllFleets() {
+		if fleet.Owner == viewer {
+			continue
+		}
+		if hasViewer && data.PlayerRelation(viewerPlayer.GetRelationTo(fleet.Owner)) == data.RelationFriend {
+			continue
+		}
+
+		var prev *store.FleetEntity
+		years := 0
+		if previous != nil {
+			if pf, ok := previous.Fleet(fleet.Owner, fleet.FleetNumber); ok {
+				prev = pf
+				years = int(latest.Turn) - int(previous.Turn)
+			}
+		}
+
+		if p := predictFleet(fleet, prev, years, planets, viewer, opts); p != nil {
+			result = append(result, p)
+		}
+	}
+
+	sortPredictions(result)
+	return result
+}
+
+// sortPredictions orders predictions by threat, then earliest arrival, then
+// owner and fleet number so that listings are the same from run to run.
+func sortPredictions(predictions []*Prediction) {
+	sort.SliceStable(predictions, func(i, j int) bool {
+		pi, pj := predictions[i], predictions[j]
+		ti, tj := pi.Threatens(), pj.Threatens()
+		if ti != tj {
+			return ti
+		}
+		if ei, ej := pi.Destinations[0].EarliestYears, pj.Destinations[0].EarliestYears; ei != ej {
+			return ei < ej
+		}
+		if pi.Fleet.Owner != pj.Fleet.Owner {
+			return pi.Fleet.Owner < pj.Fleet.Owner
+		}
+		return pi.Fleet.FleetNumber < pj.Fleet.FleetNumber
+	})
+}
+
+// predictFleet projects a single fleet. It returns nil if the fleet is not
+// moving or no planet lies along its track.
+func predictFleet(fleet, prev *store.FleetEntity, years int, planets []*store.PlanetEntity, viewer int, opts *PredictOptions) *Prediction {
+	var dirX, dirY, speed float64
+	fromHistory := false
+
+	if prev != nil && years > 0 && (prev.X != fleet.X || prev.Y != fleet.Y) {
+		dirX = float64(fleet.X - prev.X)
+		dirY = float64(fleet.Y - prev.Y)
+		speed = math.Hypot(dirX, dirY) / float64(years)
+		fromHistory = true
+	} else {
+		if fleet.Warp == 0 || (fleet.DeltaX == 0 && fleet.DeltaY == 0) {
+			return nil
+		}
+		dirX = float64(fleet.DeltaX)
+		dirY = float64(fleet.DeltaY)
+		speed = geom.WarpSpeed(fleet.Warp)
+	}
+
+	length := math.Hypot(dirX, dirY)
+	dirX /= length
+	dirY /= length
+
+	warp := fleet.Warp
+	if warp == 0 {
+		warp = int(math.Round(math.Sqrt(speed)))
+	}
+	fast := math.Max(speed, geom.WarpSpeed(min(warp+1, 10)))
+	slow := math.Min(speed, geom.WarpSpeed(max(warp-1, 1)))
+
+	pred := &Prediction{
+		Fleet:       fleet,
+		Speed:       speed,
+		Heading:     math.Atan2(dirY, dirX),
+		FromHistory: fromHistory,
+	}
+
+	for _, planet := range planets {
+		px := float64(planet.X - fleet.X)
+		py := float64(planet.Y - fleet.Y)
+		along := px*dirX + py*dirY
+		if along <= 0 {
+			continue // Behind the fleet
+		}
+		deviation := math.Abs(px*dirY - py*dirX)
+		if deviation > opts.MaxDeviation {
+			continue
+		}
+		dist := geom.Distance(fleet.X, fleet.Y, planet.X, planet.Y)
+		earliest := int(math.Ceil(dist / fast))
+		if earliest > opts.MaxYears {
+			continue
+		}
+		pred.Destinations = append(pred.Destinations, Destination{
+			Planet:        planet,
+			Distance:      dist,
+			Deviation:     deviation,
+			EarliestYears: earliest,
+			LatestYears:   int(math.Ceil(dist / slow)),
+			Threatened:    planet.Owner == viewer,
+		})
+	}
+
+	if len(pred.Destinations) == 0 {
+		return nil
+	}
+
+	// Planets closest to the track are the most likely destinations
+	sort.SliceStable(pred.Destinations, func(i, j int) bool {
+		di, dj := pred.Destinations[i], pred.Destinations[j]
+		if di.Deviation != dj.Deviation {
+			return di.Deviation < dj.Deviation
+		}
+		if di.Distance != dj.Distance {
+			return di.Distance < dj.Distance
+		}
+		return di.Planet.PlanetNumber < dj.Planet.PlanetNumber
+	})
+	return pred
+}
@@ -0,0 +1,99 @@
+package threat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func testPlanets() []*store.PlanetEntity {
+	return []*store.PlanetEntity{
+		{PlanetNumber: 1, Name: "Home", X: 200, Y: 100, Owner: 0},
+		{PlanetNumber: 2, Name: "Outpost", X: 150, Y: 105, Owner: -1},
+		{PlanetNumber: 3, Name: "Behind", X: 50, Y: 100, Owner: 0},
+		{PlanetNumber: 4, Name: "FarOff", X: 200, Y: 300, Owner: 0},
+	}
+}
+
+func TestPredictFleet_FromDelta(t *testing.T) {
+	fleet := &store.FleetEntity{FleetNumber: 1, Owner: 1, X: 100, Y: 100, DeltaX: 50, Warp: 5}
+
+	pred := predictFleet(fleet, nil, 0, testPlanets(), 0, DefaultPredictOptions())
+	require.NotNil(t, pred)
+	assert.False(t, pred.FromHistory)
+	assert.InDelta(t, 25.0, pred.Speed, 0.001)
+	assert.True(t, pred.Threatens())
+
+	require.Len(t, pred.Destinations, 2, "planets behind or far off track are excluded")
+	home := pred.Destinations[0]
+	assert.Equal(t, "Home", home.Planet.Name)
+	assert.True(t, home.Threatened)
+	// 100 ly: warp 6 (36 ly/yr) -> 3 years, warp 4 (16 ly/yr) -> 7 years
+	assert.Equal(t, 3, home.EarliestYears)
+	assert.Equal(t, 7, home.LatestYears)
+
+	outpost := pred.Destinations[1]
+	assert.Equal(t, "Outpost", outpost.Planet.Name)
+	assert.False(t, outpost.Threatened)
+	assert.InDelta(t, 5.0, outpost.Deviation, 0.001)
+}
+
+func TestPredictFleet_FromHistory(t *testing.T) {
+	prev := &store.FleetEntity{FleetNumber: 1, Owner: 1, X: 100, Y: 300}
+	fleet := &store.FleetEntity{FleetNumber: 1, Owner: 1, X: 100, Y: 100, DeltaX: 50, Warp: 7}
+
+	// Observed movement is straight down (negative Y), overriding DeltaX
+	planets := []*store.PlanetEntity{
+		{PlanetNumber: 1, Name: "South", X: 100, Y: 0, Owner: 0},
+		{PlanetNumber: 2, Name: "East", X: 200, Y: 100, Owner: 0},
+	}
+	pred := predictFleet(fleet, prev, 2, planets, 0, DefaultPredictOptions())
+	require.NotNil(t, pred)
+	assert.True(t, pred.FromHistory)
+	assert.InDelta(t, 100.0, pred.Speed, 0.001)
+	require.Len(t, pred.Destinations, 1)
+	assert.Equal(t, "South", pred.Destinations[0].Planet.Name)
+	assert.Equal(t, 1, pred.Destinations[0].EarliestYears)
+}
+
+func TestPredictFleet_Stationary(t *testing.T) {
+	fleet := &store.FleetEntity{FleetNumber: 1, Owner: 1, X: 100, Y: 100}
+	assert.Nil(t, predictFleet(fleet, nil, 0, testPlanets(), 0, DefaultPredictOptions()))
+}
+
+func TestPredictFleet_OutOfRange(t *testing.T) {
+	fleet := &store.FleetEntity{FleetNumber: 1, Owner: 1, X: 100, Y: 100, DeltaX: 50, Warp: 1}
+	opts := &PredictOptions{MaxYears: 2, MaxDeviation: 20}
+	assert.Nil(t, predictFleet(fleet, nil, 0, testPlanets(), 0, opts))
+}
+
+func TestPredictArrivals_Empty(t *testing.T) {
+	assert.Nil(t, PredictArrivals(nil, 0, nil))
+}
+
+func TestSortPredictions_Ties(t *testing.T) {
+	// Mirror images around the fleets' track: same deviation and distance
+	planets := []*store.PlanetEntity{
+		{PlanetNumber: 7, Name: "North", X: 200, Y: 110, Owner: 0},
+		{PlanetNumber: 3, Name: "South", X: 200, Y: 90, Owner: 0},
+	}
+	var preds []*Prediction
+	for _, f := range [][2]int{{2, 5}, {1, 9}, {2, 1}, {1, 4}} {
+		fleet := &store.FleetEntity{Owner: f[0], FleetNumber: f[1], X: 100, Y: 100, DeltaX: 50, Warp: 5}
+		pred := predictFleet(fleet, nil, 0, planets, 0, DefaultPredictOptions())
+		require.NotNil(t, pred)
+		require.Len(t, pred.Destinations, 2)
+		assert.Equal(t, "South", pred.Destinations[0].Planet.Name, "lower planet number first")
+		preds = append(preds, pred)
+	}
+
+	sortPredictions(preds)
+	var order [][2]int
+	for _, p := range preds {
+		order = append(order, [2]int{p.Fleet.Owner, p.Fleet.FleetNumber})
+	}
+	assert.Equal(t, [][2]int{{1, 4}, {1, 9}, {2, 1}, {2, 5}}, order)
+}
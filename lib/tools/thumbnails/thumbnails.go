@@ -0,0 +1,148 @@
+// Package thumbnails renders small galaxy map thumbnails, one per turn,
+// for embedding in forum posts.
+//
+// Thumbnails are written under a directory with a stable layout that
+// doubles as the URL scheme when the directory is served over HTTP:
+//
+//	GAMEID/YEAR.png    the map of one turn
+//	GAMEID/latest.png  the map of the latest turn given to Write
+//
+// A thumbnail that already exists is not rendered again, so the same
+// directory can be refreshed cheaply every time a turn is generated.
+//
+// Example usage:
+//
+//	written, err := thumbnails.Write("thumbnails", stores, nil) // one GameStore per turn
+package thumbnails
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/lib/tools/maprenderer"
+	"github.com/neper-stars/houston/store"
+)
+
+// ErrNoTurns is returned when there is nothing to render.
+var ErrNoTurns = errors.New("no turns to render")
+
+// Options controls thumbnail rendering.
+type Options struct {
+	Width  int  // Thumbnail width in pixels (default: 200)
+	Height int  // Thumbnail height in pixels (default: 150)
+	Force  bool // Render thumbnails that already exist again
+
+	// Map overrides the map rendering options; its size is replaced by
+	// Width and Height. If nil, see MapOptions.
+	Map *maprenderer.RenderOptions
+}
+
+// DefaultOptions returns default thumbnail options.
+func DefaultOptions() *Options {
+	return &Options{Width: 200, Height: 150}
+}
+
+// MapOptions returns the map options of thumbnails: planets and the year
+// only, with little padding, as fleets and names are unreadable at that
+// size.
+func MapOptions(width, height int) *maprenderer.RenderOptions {
+	return maprenderer.NewOptions(
+		maprenderer.WithSize(width, height),
+		maprenderer.WithOnlyLayers(),
+		maprenderer.WithPadding(4),
+	)
+}
+
+// Path returns the path of the thumbnail of a turn, relative to the
+// thumbnail directory, with forward slashes.
+func Path(gameID uint32, year int) string {
+	return path.Join(fmt.Sprint(gameID), fmt.Sprintf("%d.png", year))
+}
+
+// LatestPath returns the path of the thumbnail of the latest turn of a
+// game, relative to the thumbnail directory, with forward slashes.
+func LatestPath(gameID uint32) string {
+	return path.Join(fmt.Sprint(gameID), "latest.png")
+}
+
+// Write renders the thumbnail of each turn into dir and updates the latest
+// thumbnail of each game. Turns whose thumbnail exists are skipped unless
+// opts.Force is set. It returns the paths written, relative to dir.
+func Write(dir string, turns []*store.GameStore, opts *Options) ([]string, error) {
+	if len(turns) == 0 {
+		return nil, ErrNoTurns
+	}
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	mapOpts := MapOptions(opts.Width, opts.Height)
+	if opts.Map != nil {
+		o := *opts.Map
+		o.Width, o.Height = opts.Width, opts.Height
+		mapOpts = &o
+	}
+
+	latest := make(map[uint32]*store.GameStore)
+	for _, gs := range turns {
+		if l, ok := latest[gs.GameID]; !ok || gs.Turn > l.Turn {
+			latest[gs.GameID] = gs
+		}
+	}
+
+	var written []string
+	for _, gs := range turns {
+		year := int(gs.Turn) + blocks.StarsBaseYear
+		name := Path(gs.GameID, year)
+		latestName := LatestPath(gs.GameID)
+		isLatest := latest[gs.GameID] == gs
+
+		var targets []string
+		if opts.Force || !exists(filepath.Join(dir, name)) {
+			targets = append(targets, name)
+		}
+		if isLatest && (len(targets) > 0 || !exists(filepath.Join(dir, latestName))) {
+			targets = append(targets, latestName)
+		}
+		if len(targets) == 0 {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := maprenderer.NewFromStore(gs).WritePNG(&buf, mapOpts); err != nil {
+			return written, fmt.Errorf("failed to render %d: %w", year, err)
+		}
+		for _, target := range targets {
+			if err := writeFile(filepath.Join(dir, filepath.FromSlash(target)), buf.Bytes()); err != nil {
+				return written, err
+			}
+			written = append(written, target)
+		}
+	}
+	return written, nil
+}
+
+func exists(filename string) bool {
+	_, err := os.Stat(filename)
+	return err == nil
+}
+
+// writeFile writes a thumbnail through a temporary file, so a thumbnail
+// being served is never seen half written.
+func writeFile(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package thumbnails
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func loadTurns(t *testing.T, years ...int) []*store.GameStore {
+	t.Helper()
+	var stores []*store.GameStore
+	for _, year := range years {
+		gs := store.New()
+		filename := filepath.Join("../../../testdata/scenario-map/history", fmt.Sprintf("game-%d.m1", year))
+		require.NoError(t, gs.AddFileWithXY(filename))
+		stores = append(stores, gs)
+	}
+	return stores
+}
+
+func TestWrite(t *testing.T) {
+	turns := loadTurns(t, 2441, 2440)
+	gameID := turns[0].GameID
+	dir := t.TempDir()
+
+	written, err := Write(dir, turns, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{Path(gameID, 2440), Path(gameID, 2441), LatestPath(gameID)}, written)
+
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(LatestPath(gameID))))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	cfg, err := png.DecodeConfig(f)
+	require.NoError(t, err)
+	assert.Equal(t, 200, cfg.Width)
+	assert.Equal(t, 150, cfg.Height)
+
+	// Cached thumbnails are not rendered again
+	written, err = Write(dir, turns, nil)
+	require.NoError(t, err)
+	assert.Empty(t, written)
+
+	// A new turn updates the latest thumbnail
+	written, err = Write(dir, append(turns, loadTurns(t, 2442)...), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{Path(gameID, 2442), LatestPath(gameID)}, written)
+
+	written, err = Write(dir, turns[:1], &Options{Width: 100, Height: 75, Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{Path(gameID, 2441), LatestPath(gameID)}, written, "latest follows the turns given")
+}
+
+func TestWriteNoTurns(t *testing.T) {
+	_, err := Write(t.TempDir(), nil, nil)
+	assert.ErrorIs(t, err, ErrNoTurns)
+}
+
+func TestPath(t *testing.T) {
+	assert.Equal(t, "1234/2450.png", Path(1234, 2450))
+	assert.Equal(t, "1234/latest.png", LatestPath(1234))
+}
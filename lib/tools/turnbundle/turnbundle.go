@@ -0,0 +1,199 @@
+// Package turnbundle packages the files of a turn with a manifest of their
+// hashes, so that corruption during email or cloud transfer is caught by the
+// receiver rather than by the Stars! client.
+//
+// A bundle is a ZIP archive holding the game files and a manifest.json
+// listing each file with its size and SHA-256 hash. It can be loaded as is by
+// the store (see store.AddZip), which ignores the manifest.
+//
+//	var buf bytes.Buffer
+//	manifest, err := turnbundle.Create(&buf, 1,
+//		turnbundle.File{Name: "game.xy", Data: xy},
+//		turnbundle.File{Name: "game.m1", Data: m1},
+//	)
+//
+//	// On the receiving side
+//	manifest, files, err := turnbundle.Verify(buf.Bytes())
+package turnbundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/neper-stars/houston/filenames"
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/reproducible"
+	"github.com/neper-stars/houston/store"
+)
+
+// ManifestName is the name of the manifest in a bundle.
+const ManifestName = "manifest.json"
+
+var (
+	ErrNoFiles        = errors.New("no files to bundle")
+	ErrGameIDMismatch = errors.New("files are from different games")
+	ErrNoManifest     = errors.New("bundle has no manifest")
+	ErrMissingFile    = errors.New("file missing from bundle")
+	ErrSizeMismatch   = errors.New("file size does not match the manifest")
+	ErrHashMismatch   = errors.New("file hash does not match the manifest")
+	ErrUnlistedFile   = errors.New("file not listed in the manifest")
+)
+
+// File is a file to bundle, or read from a bundle.
+type File struct {
+	Name string // Name in the bundle, without directory
+	Data []byte
+}
+
+// Entry describes a file of the bundle in the manifest.
+type Entry struct {
+	Name   string `json:"name"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest lists the files of a bundle.
+type Manifest struct {
+	GameID  uint32    `json:"game_id"`
+	Year    int       `json:"year"`
+	Player  int       `json:"player,omitempty"` // Player number (1-16), 0 when not for a player
+	Created time.Time `json:"created"`
+	Files   []Entry   `json:"files"`
+}
+
+// Create writes a bundle of the files, for the given player number (1-16,
+// or 0), to w. The files must be Stars! game files of the same game; the
+// year of the bundle is the latest of their years.
+func Create(w io.Writer, player int, files ...File) (*Manifest, error) {
+	if len(files) == 0 {
+		return nil, ErrNoFiles
+	}
+
+	manifest := &Manifest{Player: player, Created: reproducible.Now().UTC()}
+	for _, f := range files {
+		header, err := parser.FileData(f.Data).FileHeader()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		switch {
+		case manifest.GameID == 0:
+			manifest.GameID = header.GameID
+		case header.GameID != manifest.GameID:
+			return nil, fmt.Errorf("%w: %s", ErrGameIDMismatch, f.Name)
+		}
+		if filenames.KindOf(f.Name) != filenames.XY {
+			manifest.Year = max(manifest.Year, 2400+int(header.Turn))
+		}
+		manifest.Files = append(manifest.Files, Entry{Name: f.Name, Size: len(f.Data), SHA256: hash(f.Data)})
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	zw := zip.NewWriter(w)
+	for _, f := range append([]File{{Name: ManifestName, Data: manifestData}}, files...) {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     f.Name,
+			Method:   zip.Deflate,
+			Modified: manifest.Created,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", f.Name, err)
+		}
+		if _, err := fw.Write(f.Data); err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", f.Name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return manifest, nil
+}
+
+// Verify checks a bundle against its manifest. It returns the manifest and
+// the files listed in it, or an error joining every problem found.
+func Verify(data []byte) (*Manifest, []File, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+
+	members := make(map[string]*zip.File)
+	for _, member := range zr.File {
+		if !member.FileInfo().IsDir() {
+			members[member.Name] = member
+		}
+	}
+	manifestFile, ok := members[ManifestName]
+	if !ok {
+		return nil, nil, ErrNoManifest
+	}
+	manifestData, err := readMember(manifestFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	delete(members, ManifestName)
+
+	var files []File
+	var errs []error
+	for _, entry := range manifest.Files {
+		member, ok := members[entry.Name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrMissingFile, entry.Name))
+			continue
+		}
+		delete(members, entry.Name)
+		content, err := readMember(member)
+		switch {
+		case err != nil:
+			errs = append(errs, err)
+		case len(content) != entry.Size:
+			errs = append(errs, fmt.Errorf("%w: %s is %d bytes, expected %d", ErrSizeMismatch, entry.Name, len(content), entry.Size))
+		case hash(content) != entry.SHA256:
+			errs = append(errs, fmt.Errorf("%w: %s", ErrHashMismatch, entry.Name))
+		default:
+			files = append(files, File{Name: entry.Name, Data: content})
+		}
+	}
+	for _, member := range zr.File {
+		if _, ok := members[member.Name]; ok {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrUnlistedFile, member.Name))
+		}
+	}
+	return &manifest, files, errors.Join(errs...)
+}
+
+// readMember reads a bundle member, within the size limit of the store.
+func readMember(member *zip.File) ([]byte, error) {
+	if member.UncompressedSize64 > store.MaxArchiveMember {
+		return nil, fmt.Errorf("%w: %s", store.ErrArchiveMemberTooLarge, member.Name)
+	}
+	rc, err := member.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", member.Name, err)
+	}
+	defer func() { _ = rc.Close() }()
+	content, err := io.ReadAll(io.LimitReader(rc, store.MaxArchiveMember))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", member.Name, err)
+	}
+	return content, nil
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
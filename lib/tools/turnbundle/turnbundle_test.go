@@ -0,0 +1,135 @@
+package turnbundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/reproducible"
+	"github.com/neper-stars/houston/store"
+)
+
+const gameDir = "../../../testdata/scenario-diplomacy-3way/1/side3/"
+
+func gameFiles(t *testing.T) []File {
+	t.Helper()
+	var files []File
+	for _, name := range []string{"game.xy", "game.m3"} {
+		data, err := os.ReadFile(gameDir + name)
+		require.NoError(t, err)
+		files = append(files, File{Name: name, Data: data})
+	}
+	return files
+}
+
+func TestCreateVerify(t *testing.T) {
+	files := gameFiles(t)
+	var buf bytes.Buffer
+	manifest, err := Create(&buf, 3, files...)
+	require.NoError(t, err)
+	assert.Equal(t, 2401, manifest.Year)
+	assert.Equal(t, 3, manifest.Player)
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, "game.m3", manifest.Files[1].Name)
+	assert.Len(t, manifest.Files[1].SHA256, 64)
+
+	verified, got, err := Verify(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, manifest.GameID, verified.GameID)
+	assert.Equal(t, files, got)
+
+	// Bundles load in the store as any ZIP archive
+	gs := store.New()
+	require.NoError(t, gs.AddZip("turn.zip", buf.Bytes()))
+	assert.Equal(t, 2, gs.SourceCount())
+}
+
+func TestCreate_Reproducible(t *testing.T) {
+	reproducible.Enable()
+	defer reproducible.Disable()
+
+	var a, b bytes.Buffer
+	_, err := Create(&a, 3, gameFiles(t)...)
+	require.NoError(t, err)
+	_, err = Create(&b, 3, gameFiles(t)...)
+	require.NoError(t, err)
+	assert.Equal(t, a.Bytes(), b.Bytes())
+}
+
+// rewrite returns the bundle with a member replaced, removed (nil) or added.
+func rewrite(t *testing.T, bundle []byte, name string, data []byte) []byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	found := false
+	for _, member := range zr.File {
+		content := readAll(t, member)
+		if member.Name == name {
+			found = true
+			if data == nil {
+				continue
+			}
+			content = data
+		}
+		w, err := zw.Create(member.Name)
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+	}
+	if !found {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func readAll(t *testing.T, member *zip.File) []byte {
+	t.Helper()
+	content, err := readMember(member)
+	require.NoError(t, err)
+	return content
+}
+
+func TestVerify_Problems(t *testing.T) {
+	var buf bytes.Buffer
+	files := gameFiles(t)
+	_, err := Create(&buf, 3, files...)
+	require.NoError(t, err)
+
+	corrupted := append([]byte(nil), files[1].Data...)
+	corrupted[len(corrupted)/2] ^= 0xff
+	_, _, err = Verify(rewrite(t, buf.Bytes(), "game.m3", corrupted))
+	assert.ErrorIs(t, err, ErrHashMismatch)
+
+	_, _, err = Verify(rewrite(t, buf.Bytes(), "game.m3", corrupted[:100]))
+	assert.ErrorIs(t, err, ErrSizeMismatch)
+
+	_, got, err := Verify(rewrite(t, buf.Bytes(), "game.xy", nil))
+	assert.ErrorIs(t, err, ErrMissingFile)
+	assert.Len(t, got, 1)
+
+	_, _, err = Verify(rewrite(t, buf.Bytes(), "game.x3", []byte("orders")))
+	assert.ErrorIs(t, err, ErrUnlistedFile)
+
+	_, _, err = Verify(rewrite(t, buf.Bytes(), ManifestName, nil))
+	assert.ErrorIs(t, err, ErrNoManifest)
+}
+
+func TestCreate_Errors(t *testing.T) {
+	_, err := Create(&bytes.Buffer{}, 0)
+	assert.ErrorIs(t, err, ErrNoFiles)
+
+	other, err := os.ReadFile("../../../testdata/scenario-orders/waypoint-load/game.m1")
+	require.NoError(t, err)
+	_, err = Create(&bytes.Buffer{}, 0, append(gameFiles(t), File{Name: "game.m1", Data: other})...)
+	assert.ErrorIs(t, err, ErrGameIDMismatch)
+}
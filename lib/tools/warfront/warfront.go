@@ -0,0 +1,407 @@
+// Package warfront tracks contested systems across turns.
+//
+// It compares consecutive turn snapshots to find ownership changes, battles
+// and bombings, marks every planet within a radius of such an incident as
+// contested, and summarizes territorial gains and losses for each pair of
+// warring players.
+//
+// Example usage:
+//
+//	var snapshots []*warfront.Snapshot
+//	for _, gs := range storesByTurn {
+//	    snapshots = append(snapshots, warfront.SnapshotFromStore(gs))
+//	}
+//	report := warfront.Analyze(snapshots, warfront.DefaultRadius)
+//	for _, war := range report.Wars {
+//	    fmt.Printf("%d vs %d: net %+d\n", war.PlayerA, war.PlayerB, war.NetGain())
+//	}
+package warfront
+
+import (
+	"sort"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/geom"
+	"github.com/neper-stars/houston/store"
+)
+
+// DefaultRadius is the default distance (in light years) around an incident
+// within which planets are considered contested.
+const DefaultRadius = 50.0
+
+// IncidentKind identifies the type of hostile activity.
+type IncidentKind int
+
+const (
+	IncidentCapture IncidentKind = iota // Planet changed hands between two players
+	IncidentBattle                      // Battle reported at or near a planet
+	IncidentBombing                     // Population lost to bombs or packets
+)
+
+// String returns a human-readable name for the incident kind.
+func (k IncidentKind) String() string {
+	switch k {
+	case IncidentCapture:
+		return "capture"
+	case IncidentBattle:
+		return "battle"
+	case IncidentBombing:
+		return "bombing"
+	default:
+		return "unknown"
+	}
+}
+
+// PlanetState is the per-turn state of a planet needed for front tracking.
+type PlanetState struct {
+	Number     int
+	Name       string
+	X, Y       int
+	Owner      int // -1 = unowned
+	Population int64
+}
+
+// FleetState is the per-turn state of a fleet needed to attribute bombings.
+type FleetState struct {
+	Owner    int
+	X, Y     int
+	HasBombs bool
+}
+
+// Incident is a single hostile event at a location.
+type Incident struct {
+	Kind         IncidentKind
+	Year         int
+	PlanetNumber int
+	X, Y         int
+	Attacker     int // -1 if unknown
+	Defender     int // -1 if unknown
+}
+
+// Snapshot is the state of the galaxy for a single turn.
+type Snapshot struct {
+	Year    int
+	Planets map[int]PlanetState
+	Fleets  []FleetState
+
+	// Incidents reported directly by the turn files (battles, packet bombardments).
+	// Captures and fleet bombings are derived by Analyze.
+	Incidents []Incident
+}
+
+// SnapshotFromStore builds a Snapshot from a GameStore holding a single turn.
+func SnapshotFromStore(gs *store.GameStore) *Snapshot {
+	snap := &Snapshot{
+		Year:    int(gs.Turn) + blocks.StarsBaseYear,
+		Planets: make(map[int]PlanetState),
+	}
+
+	for _, p := range gs.AllPlanets() {
+		snap.Planets[p.PlanetNumber] = PlanetState{
+			Number:     p.PlanetNumber,
+			Name:       p.Name,
+			X:          p.X,
+			Y:          p.Y,
+			Owner:      p.Owner,
+			Population: p.Population,
+		}
+	}
+
+	for _, f := range gs.AllFleets() {
+		hasBombs := false
+		for _, d := range f.GetDesigns(gs) {
+			if d.Design.HasBombs() {
+				hasBombs = true
+				break
+			}
+		}
+		snap.Fleets = append(snap.Fleets, FleetState{Owner: f.Owner, X: f.X, Y: f.Y, HasBombs: hasBombs})
+	}
+
+	// A battle is reported in the file of every player in it: count it once
+	seen := make(map[battleKey]bool)
+	for _, evt := range gs.AllEvents() {
+		viewer := -1
+		if evt.Source != nil {
+			viewer = evt.Source.PlayerIndex
+		}
+		for _, b := range evt.Battles {
+			p, ok := snap.Planets[b.PlanetID]
+			if !ok {
+				continue
+			}
+			key := newBattleKey(snap.Year, evt.Source, b, viewer)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			snap.Incidents = append(snap.Incidents, Incident{
+				Kind:         IncidentBattle,
+				Year:         snap.Year,
+				PlanetNumber: b.PlanetID,
+				X:            p.X,
+				Y:            p.Y,
+				Attacker:     b.EnemyPlayer,
+				Defender:     viewer,
+			})
+		}
+		for _, pb := range evt.PacketBombardments {
+			p, ok := snap.Planets[pb.PlanetID]
+			if !ok {
+				continue
+			}
+			snap.Incidents = append(snap.Incidents, Incident{
+				Kind:         IncidentBombing,
+				Year:         snap.Year,
+				PlanetNumber: pb.PlanetID,
+				X:            p.X,
+				Y:            p.Y,
+				Attacker:     -1,
+				Defender:     p.Owner,
+			})
+		}
+	}
+
+	return snap
+}
+
+// battleKey identifies a battle of a turn: by the ID of its recording, or
+// when it has none by its planet and the two players, as a planet sees one
+// battle a turn.
+type battleKey struct {
+	year, id      int
+	planet        int
+	first, second int
+}
+
+// newBattleKey returns the key of a battle reported to viewer, taking the
+// ID from the recording in the same file.
+func newBattleKey(year int, source *store.FileSource, b blocks.BattleEvent, viewer int) battleKey {
+	if source != nil && viewer >= 0 && b.EnemyPlayer >= 0 {
+		players := uint16(1)<<viewer | uint16(1)<<b.EnemyPlayer
+		for _, block := range source.Blocks {
+			if bb, ok := block.(blocks.BattleBlock); ok && bb.PlanetID == b.PlanetID && bb.PlayerBitmask&players == players {
+				return battleKey{year: year, id: bb.BattleID, planet: -1, first: -1, second: -1}
+			}
+		}
+	}
+	return battleKey{year: year, id: -1, planet: b.PlanetID,
+		first: min(viewer, b.EnemyPlayer), second: max(viewer, b.EnemyPlayer)}
+}
+
+// ContestedSystem is a planet close to hostile activity.
+type ContestedSystem struct {
+	PlanetNumber int
+	Name         string
+	X, Y         int
+	Owner        int // Owner in the latest snapshot (-1 = unowned)
+	Incidents    int // Number of incidents within the radius
+	Kinds        map[IncidentKind]int
+	LastYear     int // Year of the most recent nearby incident
+}
+
+// Change is a planet changing hands between two players.
+type Change struct {
+	Year         int
+	PlanetNumber int
+	Name         string
+	From, To     int
+}
+
+// War summarizes hostilities between two players. PlayerA is always
+// the lower player number.
+type War struct {
+	PlayerA, PlayerB int
+	Battles          int
+	Bombings         int
+	GainsA           []Change // Planets PlayerA took from PlayerB
+	GainsB           []Change // Planets PlayerB took from PlayerA
+}
+
+// NetGain returns the number of planets PlayerA gained from PlayerB minus
+// those lost to PlayerB.
+func (w *War) NetGain() int {
+	return len(w.GainsA) - len(w.GainsB)
+}
+
+// Report is the result of a front line analysis.
+type Report struct {
+	FirstYear, LastYear int
+	Incidents           []Incident
+	Contested           []*ContestedSystem // Sorted by incident count, descending
+	Wars                []*War             // Sorted by total activity, descending
+}
+
+// Analyze compares snapshots (in any order) and returns contested systems
+// and war summaries. Planets within radius light years of an incident are
+// marked contested.
+func Analyze(snapshots []*Snapshot, radius float64) *Report {
+	report := &Report{}
+	if len(snapshots) == 0 {
+		return report
+	}
+
+	sorted := make([]*Snapshot, len(snapshots))
+	copy(sorted, snapshots)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Year < sorted[j].Year })
+
+	report.FirstYear = sorted[0].Year
+	report.LastYear = sorted[len(sorted)-1].Year
+
+	var changes []Change
+	for i, snap := range sorted {
+		report.Incidents = append(report.Incidents, snap.Incidents...)
+		if i == 0 {
+			continue
+		}
+		prev := sorted[i-1]
+		for num, cur := range snap.Planets {
+			old, ok := prev.Planets[num]
+			if !ok {
+				continue
+			}
+			if old.Owner >= 0 && cur.Owner >= 0 && old.Owner != cur.Owner {
+				changes = append(changes, Change{
+					Year:         snap.Year,
+					PlanetNumber: num,
+					Name:         cur.Name,
+					From:         old.Owner,
+					To:           cur.Owner,
+				})
+				report.Incidents = append(report.Incidents, Incident{
+					Kind:         IncidentCapture,
+					Year:         snap.Year,
+					PlanetNumber: num,
+					X:            cur.X,
+					Y:            cur.Y,
+					Attacker:     cur.Owner,
+					Defender:     old.Owner,
+				})
+				continue
+			}
+			if cur.Owner >= 0 && cur.Owner == old.Owner && cur.Population < old.Population {
+				if bomber := bomberAt(snap, cur); bomber >= 0 {
+					report.Incidents = append(report.Incidents, Incident{
+						Kind:         IncidentBombing,
+						Year:         snap.Year,
+						PlanetNumber: num,
+						X:            cur.X,
+						Y:            cur.Y,
+						Attacker:     bomber,
+						Defender:     cur.Owner,
+					})
+				}
+			}
+		}
+	}
+
+	report.Contested = contestedSystems(sorted[len(sorted)-1], report.Incidents, radius)
+	report.Wars = summarizeWars(report.Incidents, changes)
+	return report
+}
+
+// bomberAt returns the owner of an enemy bomber fleet orbiting the planet, or -1.
+func bomberAt(snap *Snapshot, planet PlanetState) int {
+	for _, f := range snap.Fleets {
+		if f.HasBombs && f.Owner != planet.Owner && f.X == planet.X && f.Y == planet.Y {
+			return f.Owner
+		}
+	}
+	return -1
+}
+
+func contestedSystems(latest *Snapshot, incidents []Incident, radius float64) []*ContestedSystem {
+	var result []*ContestedSystem
+	for _, p := range latest.Planets {
+		var cs *ContestedSystem
+		for _, inc := range incidents {
+			if geom.Distance(p.X, p.Y, inc.X, inc.Y) > radius {
+				continue
+			}
+			if cs == nil {
+				cs = &ContestedSystem{
+					PlanetNumber: p.Number,
+					Name:         p.Name,
+					X:            p.X,
+					Y:            p.Y,
+					Owner:        p.Owner,
+					Kinds:        make(map[IncidentKind]int),
+				}
+			}
+			cs.Incidents++
+			cs.Kinds[inc.Kind]++
+			if inc.Year > cs.LastYear {
+				cs.LastYear = inc.Year
+			}
+		}
+		if cs != nil {
+			result = append(result, cs)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Incidents != result[j].Incidents {
+			return result[i].Incidents > result[j].Incidents
+		}
+		return result[i].PlanetNumber < result[j].PlanetNumber
+	})
+	return result
+}
+
+func summarizeWars(incidents []Incident, changes []Change) []*War {
+	type pair struct{ a, b int }
+	wars := make(map[pair]*War)
+	get := func(p1, p2 int) *War {
+		a, b := p1, p2
+		if b < a {
+			a, b = b, a
+		}
+		key := pair{a, b}
+		w, ok := wars[key]
+		if !ok {
+			w = &War{PlayerA: a, PlayerB: b}
+			wars[key] = w
+		}
+		return w
+	}
+
+	for _, inc := range incidents {
+		if inc.Attacker < 0 || inc.Defender < 0 || inc.Attacker == inc.Defender {
+			continue
+		}
+		switch inc.Kind {
+		case IncidentBattle:
+			get(inc.Attacker, inc.Defender).Battles++
+		case IncidentBombing:
+			get(inc.Attacker, inc.Defender).Bombings++
+		}
+	}
+
+	for _, c := range changes {
+		w := get(c.From, c.To)
+		if c.To == w.PlayerA {
+			w.GainsA = append(w.GainsA, c)
+		} else {
+			w.GainsB = append(w.GainsB, c)
+		}
+	}
+
+	result := make([]*War, 0, len(wars))
+	for _, w := range wars {
+		result = append(result, w)
+	}
+	activity := func(w *War) int {
+		return w.Battles + w.Bombings + len(w.GainsA) + len(w.GainsB)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		ai, aj := activity(result[i]), activity(result[j])
+		if ai != aj {
+			return ai > aj
+		}
+		if result[i].PlayerA != result[j].PlayerA {
+			return result[i].PlayerA < result[j].PlayerA
+		}
+		return result[i].PlayerB < result[j].PlayerB
+	})
+	return result
+}
@@ -0,0 +1,113 @@
+package warfront
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func twoTurnSnapshots() []*Snapshot {
+	before := &Snapshot{
+		Year: 2450,
+		Planets: map[int]PlanetState{
+			1: {Number: 1, Name: "Alpha", X: 100, Y: 100, Owner: 0, Population: 50000},
+			2: {Number: 2, Name: "Beta", X: 130, Y: 100, Owner: 1, Population: 40000},
+			3: {Number: 3, Name: "Gamma", X: 500, Y: 500, Owner: 2, Population: 30000},
+			4: {Number: 4, Name: "Delta", X: 160, Y: 100, Owner: 1, Population: 20000},
+		},
+	}
+	after := &Snapshot{
+		Year: 2451,
+		Planets: map[int]PlanetState{
+			1: {Number: 1, Name: "Alpha", X: 100, Y: 100, Owner: 0, Population: 52000},
+			2: {Number: 2, Name: "Beta", X: 130, Y: 100, Owner: 0, Population: 1000},
+			3: {Number: 3, Name: "Gamma", X: 500, Y: 500, Owner: 2, Population: 31000},
+			4: {Number: 4, Name: "Delta", X: 160, Y: 100, Owner: 1, Population: 12000},
+		},
+		Fleets: []FleetState{
+			{Owner: 0, X: 160, Y: 100, HasBombs: true},
+		},
+		Incidents: []Incident{
+			{Kind: IncidentBattle, Year: 2451, PlanetNumber: 2, X: 130, Y: 100, Attacker: 0, Defender: 1},
+		},
+	}
+	// Deliberately out of order: Analyze sorts by year
+	return []*Snapshot{after, before}
+}
+
+func TestAnalyze_Wars(t *testing.T) {
+	report := Analyze(twoTurnSnapshots(), DefaultRadius)
+
+	assert.Equal(t, 2450, report.FirstYear)
+	assert.Equal(t, 2451, report.LastYear)
+
+	require.Len(t, report.Wars, 1)
+	war := report.Wars[0]
+	assert.Equal(t, 0, war.PlayerA)
+	assert.Equal(t, 1, war.PlayerB)
+	assert.Equal(t, 1, war.Battles)
+	assert.Equal(t, 1, war.Bombings, "bomber orbiting Delta should be detected")
+	require.Len(t, war.GainsA, 1)
+	assert.Equal(t, "Beta", war.GainsA[0].Name)
+	assert.Empty(t, war.GainsB)
+	assert.Equal(t, 1, war.NetGain())
+}
+
+func TestAnalyze_Contested(t *testing.T) {
+	report := Analyze(twoTurnSnapshots(), DefaultRadius)
+
+	contested := make(map[string]*ContestedSystem)
+	for _, cs := range report.Contested {
+		contested[cs.Name] = cs
+	}
+
+	assert.Contains(t, contested, "Alpha")
+	assert.Contains(t, contested, "Beta")
+	assert.Contains(t, contested, "Delta")
+	assert.NotContains(t, contested, "Gamma", "distant planet should not be contested")
+
+	beta := contested["Beta"]
+	assert.Equal(t, 0, beta.Owner)
+	assert.Equal(t, 1, beta.Kinds[IncidentCapture])
+	assert.Equal(t, 1, beta.Kinds[IncidentBattle])
+	assert.Equal(t, 2451, beta.LastYear)
+}
+
+func TestSnapshotFromStore_BattleSeenByBothPlayers(t *testing.T) {
+	// Both players report the battle at planet 392 in their turn files
+	gs := store.New()
+	for _, name := range []string{"game-2480.m1", "game-2480.m2"} {
+		require.NoError(t, gs.AddFileWithXY("../../../testdata/scenario-map/history/"+name))
+	}
+	snap := SnapshotFromStore(gs)
+
+	var battles []Incident
+	for _, inc := range snap.Incidents {
+		if inc.Kind == IncidentBattle {
+			battles = append(battles, inc)
+		}
+	}
+	require.Len(t, battles, 1)
+	assert.Equal(t, 392, battles[0].PlanetNumber)
+	assert.Equal(t, 2480, battles[0].Year)
+
+	report := Analyze([]*Snapshot{snap}, DefaultRadius)
+	require.Len(t, report.Wars, 1)
+	assert.Equal(t, 1, report.Wars[0].Battles)
+}
+
+func TestAnalyze_Empty(t *testing.T) {
+	report := Analyze(nil, DefaultRadius)
+	assert.Empty(t, report.Contested)
+	assert.Empty(t, report.Wars)
+}
+
+func TestIncidentKind_String(t *testing.T) {
+	assert.Equal(t, "capture", IncidentCapture.String())
+	assert.Equal(t, "battle", IncidentBattle.String())
+	assert.Equal(t, "bombing", IncidentBombing.String())
+	assert.Equal(t, "unknown", IncidentKind(99).String())
+}
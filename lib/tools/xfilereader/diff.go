@@ -0,0 +1,119 @@
+package xfilereader
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/neper-stars/houston/blocks"
+)
+
+// ChangeKind tells how an order differs between two X files.
+type ChangeKind int
+
+const (
+	OrderAdded ChangeKind = iota
+	OrderRemoved
+	OrderChanged
+)
+
+// String returns the sign of the change in a diff: "+", "-" or "~".
+func (k ChangeKind) String() string {
+	switch k {
+	case OrderAdded:
+		return "+"
+	case OrderRemoved:
+		return "-"
+	}
+	return "~"
+}
+
+// Change is an order that differs between two X files.
+type Change struct {
+	Kind ChangeKind
+	Old  *Order // Nil for an added order
+	New  *Order // Nil for a removed order
+}
+
+// String describes the change.
+func (c Change) String() string {
+	switch c.Kind {
+	case OrderAdded:
+		return "+ " + c.New.Description
+	case OrderRemoved:
+		return "- " + c.Old.Description
+	}
+	if c.Old.Description == c.New.Description {
+		return "~ " + c.New.Description + " (details changed)"
+	}
+	return fmt.Sprintf("~ %s -> %s", c.Old.Description, c.New.Description)
+}
+
+// Diff returns the orders added, removed or changed from one X file to
+// another, such as a resubmission of a turn, in the order of the new file
+// followed by the removed orders.
+//
+// Orders are matched by what they apply to: the same fleet waypoint, planet,
+// design or minefield, or the research settings. Orders applying to the same
+// thing more than once are matched in file order.
+func Diff(before, after *FileInfo) []Change {
+	byTarget := make(map[string][]*Order)
+	for i := range before.Orders {
+		o := &before.Orders[i]
+		key := orderTarget(o)
+		byTarget[key] = append(byTarget[key], o)
+	}
+
+	var changes []Change
+	matched := make(map[*Order]bool)
+	for i := range after.Orders {
+		n := &after.Orders[i]
+		key := orderTarget(n)
+		candidates := byTarget[key]
+		if len(candidates) == 0 {
+			changes = append(changes, Change{Kind: OrderAdded, New: n})
+			continue
+		}
+		o := candidates[0]
+		byTarget[key] = candidates[1:]
+		matched[o] = true
+		if !bytes.Equal(o.Block.DecryptedData(), n.Block.DecryptedData()) {
+			changes = append(changes, Change{Kind: OrderChanged, Old: o, New: n})
+		}
+	}
+	for i := range before.Orders {
+		if o := &before.Orders[i]; !matched[o] {
+			changes = append(changes, Change{Kind: OrderRemoved, Old: o})
+		}
+	}
+	return changes
+}
+
+// orderTarget returns the type of an order and what it applies to.
+func orderTarget(o *Order) string {
+	switch b := o.Block.(type) {
+	case blocks.WaypointAddBlock:
+		return fmt.Sprintf("%s/%d/%d", o.Type, b.FleetNumber, b.WaypointIndex)
+	case blocks.WaypointDeleteBlock:
+		return fmt.Sprintf("%s/%d/%d", o.Type, b.FleetNumber, b.WaypointNumber)
+	case blocks.WaypointChangeTaskBlock:
+		return fmt.Sprintf("%s/%d/%d", o.Type, b.FleetNumber, b.WaypointIndex)
+	case blocks.ProductionQueueChangeBlock:
+		return fmt.Sprintf("%s/%d", o.Type, b.PlanetId)
+	case blocks.PlanetChangeBlock:
+		return fmt.Sprintf("%s/%d", o.Type, b.PlanetId)
+	case blocks.DesignChangeBlock:
+		switch {
+		case b.IsDelete:
+			return fmt.Sprintf("%s/delete/%t/%d", o.Type, b.IsStarbase, b.DesignToDelete)
+		case b.Design != nil:
+			return fmt.Sprintf("%s/%t/%d", o.Type, b.Design.IsStarbase, b.Design.DesignNumber)
+		}
+	case blocks.FleetSplitBlock:
+		return fmt.Sprintf("%s/%d", o.Type, b.FleetNumber)
+	case blocks.FleetsMergeBlock:
+		return fmt.Sprintf("%s/%d", o.Type, b.FleetNumber)
+	case blocks.ObjectBlock:
+		return fmt.Sprintf("%s/%d", o.Type, b.Number)
+	}
+	return o.Type
+}
@@ -0,0 +1,115 @@
+package xfilereader
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+)
+
+func readX(t *testing.T, path string) *FileInfo {
+	t.Helper()
+	info, err := ReadFile("../../../testdata/" + path)
+	require.NoError(t, err)
+	return info
+}
+
+func changeStrings(changes []Change) []string {
+	var s []string
+	for _, c := range changes {
+		s = append(s, c.String())
+	}
+	return s
+}
+
+func TestDiffAddedAndRemoved(t *testing.T) {
+	// The same turn submitted twice, the second time with a longer queue
+	first := readX(t, "scenario-ziporders/year2-neworders/game.x1")
+	second := readX(t, "scenario-ziporders/year2-otherorders/game.x1")
+
+	changes := Diff(first, second)
+	require.Len(t, changes, 2)
+	assert.Equal(t, OrderAdded, changes[0].Kind)
+	assert.Nil(t, changes[0].Old)
+	assert.Same(t, &second.Orders[2], changes[0].New)
+	assert.Equal(t, []string{
+		"+ Planet 34: update production queue (14 items)",
+		"+ Turn submitted",
+	}, changeStrings(changes))
+
+	changes = Diff(second, first)
+	require.Len(t, changes, 2)
+	assert.Equal(t, OrderRemoved, changes[0].Kind)
+	assert.Nil(t, changes[0].New)
+	assert.Same(t, &second.Orders[2], changes[0].Old)
+	assert.Equal(t, []string{
+		"- Planet 34: update production queue (14 items)",
+		"- Turn submitted",
+	}, changeStrings(changes))
+
+	assert.Empty(t, Diff(first, first))
+}
+
+func TestDiffDesigns(t *testing.T) {
+	before := readX(t, "scenario-singleplayer/2483/Game.x1")
+	after := readX(t, "scenario-singleplayer/2483-orders-given/Game.x1")
+
+	changes := Diff(before, after)
+	var kinds []ChangeKind
+	for _, c := range changes {
+		kinds = append(kinds, c.Kind)
+	}
+	// Same research settings; three designs updated twice and a new queue
+	assert.Equal(t, slices.Repeat([]ChangeKind{OrderAdded}, 7), kinds)
+	assert.Equal(t, "+ Planet 217: update production queue (12 items)", changes[6].String())
+}
+
+func TestDiffChanged(t *testing.T) {
+	before := readX(t, "scenario-ziporders/year2-neworders/game.x1")
+	other := readX(t, "scenario-ziporders/year2-otherorders/game.x1")
+
+	// The queue of planet 34 edited in place
+	after := *before
+	after.Orders = []Order{other.Orders[2], before.Orders[1]}
+	changes := Diff(before, &after)
+	require.Len(t, changes, 1)
+	assert.Equal(t, OrderChanged, changes[0].Kind)
+	assert.Same(t, &before.Orders[0], changes[0].Old)
+	assert.Same(t, &after.Orders[0], changes[0].New)
+	assert.Equal(t, "~ Planet 34: update production queue (1 items) -> Planet 34: update production queue (14 items)",
+		changes[0].String())
+
+	// The research budget edited: same description, other data
+	research := readX(t, "scenario-singleplayer/2483/Game.x1")
+	edited := *research
+	block := research.Orders[0].Block.(blocks.ResearchChangeBlock)
+	block.Decrypted = slices.Clone(block.Decrypted)
+	block.Decrypted[0]++
+	edited.Orders = []Order{{Type: "ResearchChange", Description: research.Orders[0].Description, Block: block}}
+	changes = Diff(research, &edited)
+	require.Len(t, changes, 1)
+	assert.Equal(t, OrderChanged, changes[0].Kind)
+	assert.Equal(t, "~ Change research priority (details changed)", changes[0].String())
+}
+
+func TestDiffMatchesSameTargetInOrder(t *testing.T) {
+	// Planet 34 queued twice: the first one matches, the second is added
+	before := readX(t, "scenario-ziporders/year2-neworders/game.x1")
+	after := readX(t, "scenario-ziporders/year2-otherorders/game.x1")
+	after.Orders = []Order{after.Orders[0], after.Orders[2]}
+	before.Orders = before.Orders[:1]
+
+	changes := Diff(before, after)
+	require.Len(t, changes, 1)
+	assert.Equal(t, OrderAdded, changes[0].Kind)
+	assert.Same(t, &after.Orders[1], changes[0].New)
+}
+
+func TestChangeKindString(t *testing.T) {
+	assert.Equal(t, "+", OrderAdded.String())
+	assert.Equal(t, "-", OrderRemoved.String())
+	assert.Equal(t, "~", OrderChanged.String())
+}
@@ -19,10 +19,9 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/filenames"
 	"github.com/neper-stars/houston/parser"
 )
 
@@ -50,8 +49,7 @@ type Order struct {
 // ReadFile reads an X file and returns its contents.
 func ReadFile(filename string) (*FileInfo, error) {
 	// Validate file extension
-	ext := strings.ToLower(filepath.Ext(filename))
-	if len(ext) < 2 || ext[1] != 'x' {
+	if filenames.KindOf(filename) != filenames.X {
 		return nil, fmt.Errorf("%s does not appear to be an X file", filename)
 	}
 
@@ -170,9 +168,14 @@ func extractOrder(block blocks.Block) *Order {
 		}
 
 	case blocks.ProductionQueueChangeBlock:
+		description := fmt.Sprintf("Planet %d: update production queue (%d items)", b.PlanetId, len(b.Items))
+		if packets := blocks.PacketCount(b.Items); packets > 0 {
+			description = fmt.Sprintf("Planet %d: update production queue (%d items, %d packets to launch)",
+				b.PlanetId, len(b.Items), packets)
+		}
 		return &Order{
 			Type:        "ProductionQueueChange",
-			Description: fmt.Sprintf("Planet %d: update production queue (%d items)", b.PlanetId, len(b.Items)),
+			Description: description,
 			Block:       block,
 		}
 
@@ -208,6 +211,31 @@ func extractOrder(block blocks.Block) *Order {
 			Block:       block,
 		}
 
+	case blocks.MoveShipsBlock:
+		return &Order{
+			Type:        "MoveShips",
+			Description: fmt.Sprintf("Move ships between fleets %d and %d", b.SourceFleetNumber, b.DestFleetNumber),
+			Block:       block,
+		}
+
+	case blocks.BattlePlanBlock:
+		description := fmt.Sprintf("Battle plan %d: %s", b.PlanId, b.Name)
+		if b.Deleted {
+			description = fmt.Sprintf("Battle plan %d: delete", b.PlanId)
+		}
+		return &Order{
+			Type:        "BattlePlan",
+			Description: description,
+			Block:       block,
+		}
+
+	case blocks.SetFleetBattlePlanBlock:
+		return &Order{
+			Type:        "SetFleetBattlePlan",
+			Description: fmt.Sprintf("Fleet %d: use battle plan %d", b.FleetNumber, b.BattlePlanIndex),
+			Block:       block,
+		}
+
 	case blocks.ResearchChangeBlock:
 		return &Order{
 			Type:        "ResearchChange",
@@ -216,9 +244,14 @@ func extractOrder(block blocks.Block) *Order {
 		}
 
 	case blocks.PlanetChangeBlock:
+		description := fmt.Sprintf("Planet %d: change planet settings", b.PlanetId)
+		if target, ok := b.MassDriverTarget(); ok {
+			description = fmt.Sprintf("Planet %d: fling packets to planet %d at warp %d",
+				b.PlanetId, target, b.PacketWarp())
+		}
 		return &Order{
 			Type:        "PlanetChange",
-			Description: "Change planet settings",
+			Description: description,
 			Block:       block,
 		}
 
@@ -236,6 +269,20 @@ func extractOrder(block blocks.Block) *Order {
 			Block:       block,
 		}
 
+	case blocks.ObjectBlock:
+		if !b.IsMinefield() {
+			return nil
+		}
+		description := fmt.Sprintf("Minefield #%d: stop detonating", b.Number)
+		if b.Detonating {
+			description = fmt.Sprintf("Minefield #%d: detonate", b.Number)
+		}
+		return &Order{
+			Type:        "MinefieldDetonate",
+			Description: description,
+			Block:       block,
+		}
+
 	case blocks.SaveAndSubmitBlock:
 		return &Order{
 			Type:        "SaveAndSubmit",
@@ -267,6 +314,12 @@ func getBlockTypeName(block blocks.Block) string {
 		return "FleetSplit"
 	case blocks.FleetsMergeBlock:
 		return "FleetsMerge"
+	case blocks.MoveShipsBlock:
+		return "MoveShips"
+	case blocks.BattlePlanBlock:
+		return "BattlePlan"
+	case blocks.SetFleetBattlePlanBlock:
+		return "SetFleetBattlePlan"
 	case blocks.ResearchChangeBlock:
 		return "ResearchChange"
 	case blocks.PlanetChangeBlock:
@@ -275,6 +328,8 @@ func getBlockTypeName(block blocks.Block) string {
 		return "ChangePassword"
 	case blocks.PlayersRelationChangeBlock:
 		return "PlayersRelationChange"
+	case blocks.ObjectBlock:
+		return "Object"
 	case blocks.SaveAndSubmitBlock:
 		return "SaveAndSubmit"
 	default:
@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/neper-stars/houston/blocks"
+)
+
+// cacheMagic starts every cache entry. Bump the version when the entry
+// layout changes so that old entries are ignored.
+var cacheMagic = []byte("HBC\x01")
+
+var errBadCacheEntry = errors.New("bad cache entry")
+
+// Cache keeps the decrypted blocks of parsed files on disk, keyed by the
+// SHA-256 of the file contents, so that repeated runs over the same files
+// skip decryption. Only decrypted payloads are stored: typed blocks are
+// rebuilt from them on every load, so entries stay valid when block
+// decoding changes.
+//
+// A nil *Cache is valid and parses without caching. The cache is best
+// effort: unreadable entries are ignored and write errors are not reported.
+//
+// Example usage:
+//
+//	cache, err := parser.NewCache(dir)
+//	if err != nil {
+//	    return err
+//	}
+//	blockList, err := cache.BlockList(data)
+type Cache struct {
+	dir string
+}
+
+// DefaultCacheDir returns the default cache directory, under the user's
+// cache directory (e.g. ~/.cache/houston/blocks on Linux).
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "houston", "blocks"), nil
+}
+
+// NewCache returns a cache storing its entries in dir, creating it if needed.
+func NewCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Dir returns the directory holding the cache entries.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// BlockList is FileData.BlockList, reusing the decrypted blocks cached for
+// identical file contents.
+func (c *Cache) BlockList(data []byte) ([]blocks.Block, error) {
+	decrypted, err := c.DecryptedBlocks(data)
+	if err != nil {
+		return nil, err
+	}
+	return BuildBlockList(decrypted)
+}
+
+// DecryptedBlocks is FileData.DecryptedBlocks, reusing the blocks cached for
+// identical file contents and caching them otherwise.
+func (c *Cache) DecryptedBlocks(data []byte) ([]DecryptedBlock, error) {
+	fd := FileData(data)
+	if c == nil {
+		return fd.DecryptedBlocks()
+	}
+
+	sum := sha256.Sum256(data)
+	key := hex.EncodeToString(sum[:])
+	path := filepath.Join(c.dir, key[:2], key+".blk")
+
+	if entry, err := os.ReadFile(path); err == nil {
+		if list, err := decodeCacheEntry(entry, fd); err == nil {
+			return list, nil
+		}
+	}
+
+	list, err := fd.DecryptedBlocks()
+	if err != nil {
+		return nil, err
+	}
+	c.write(path, encodeCacheEntry(list))
+	return list, nil
+}
+
+// write stores an entry atomically, so that concurrent runs never read a
+// partial entry.
+func (c *Cache) write(path string, entry []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(entry)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+// encodeCacheEntry serializes decrypted blocks. Encrypted data and planet
+// trailers are stored as offsets into the file, which is at hand when the
+// entry is read back.
+//
+// Layout (little endian): magic, uint32 block count, then per block uint8
+// type, uint32 offset, uint16 size, uint32 trailer length, uint16 decrypted
+// length and the decrypted bytes.
+func encodeCacheEntry(list []DecryptedBlock) []byte {
+	var buf bytes.Buffer
+	buf.Write(cacheMagic)
+	buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(list))))
+
+	offset := 0
+	for _, b := range list {
+		var h []byte
+		h = append(h, byte(b.Type))
+		h = binary.LittleEndian.AppendUint32(h, uint32(offset))
+		h = binary.LittleEndian.AppendUint16(h, uint16(b.Size))
+		h = binary.LittleEndian.AppendUint32(h, uint32(len(b.Trailer)))
+		h = binary.LittleEndian.AppendUint16(h, uint16(len(b.Decrypted)))
+		buf.Write(h)
+		buf.Write(b.Decrypted)
+		offset += 2 + int(b.Size) + len(b.Trailer)
+	}
+	return buf.Bytes()
+}
+
+// decodeCacheEntry is the reverse of encodeCacheEntry.
+func decodeCacheEntry(entry []byte, fd FileData) ([]DecryptedBlock, error) {
+	if !bytes.HasPrefix(entry, cacheMagic) || len(entry) < len(cacheMagic)+4 {
+		return nil, errBadCacheEntry
+	}
+	entry = entry[len(cacheMagic):]
+	count := int(binary.LittleEndian.Uint32(entry))
+	entry = entry[4:]
+
+	const headerSize = 1 + 4 + 2 + 4 + 2
+	list := make([]DecryptedBlock, 0, min(count, len(entry)/headerSize))
+	for range count {
+		if len(entry) < headerSize {
+			return nil, errBadCacheEntry
+		}
+		typeID := blocks.BlockTypeID(entry[0])
+		offset := int(binary.LittleEndian.Uint32(entry[1:]))
+		size := int(binary.LittleEndian.Uint16(entry[5:]))
+		trailer := int(binary.LittleEndian.Uint32(entry[7:]))
+		decrypted := int(binary.LittleEndian.Uint16(entry[11:]))
+		entry = entry[headerSize:]
+
+		end := offset + 2 + size
+		if len(entry) < decrypted || end+trailer > len(fd) {
+			return nil, errBadCacheEntry
+		}
+
+		b := DecryptedBlock{
+			GenericBlock: blocks.GenericBlock{
+				Type:      typeID,
+				Size:      blocks.BlockSize(size),
+				Data:      blocks.BlockData(fd[offset+2 : end]),
+				Decrypted: blocks.DecryptedData(entry[:decrypted]),
+			},
+		}
+		// Match the parser, which leaves empty payloads nil
+		if size == 0 {
+			b.Data = nil
+		}
+		if decrypted == 0 {
+			b.Decrypted = nil
+		}
+		if trailer > 0 {
+			b.Trailer = fd[end : end+trailer]
+		}
+		entry = entry[decrypted:]
+		list = append(list, b)
+	}
+	if len(entry) != 0 {
+		return nil, errBadCacheEntry
+	}
+	return list, nil
+}
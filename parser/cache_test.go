@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/encoding"
+)
+
+func cacheEntries(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := filepath.Glob(filepath.Join(dir, "*", "*.blk"))
+	require.NoError(t, err)
+	return entries
+}
+
+func TestCacheMatchesParser(t *testing.T) {
+	cache, err := NewCache(t.TempDir())
+	require.NoError(t, err)
+
+	files := []string{
+		"../testdata/scenario-map/history/game-2471.m1",
+		"../testdata/scenario-map/history/game-2471.hst",
+		"../testdata/scenario-map/history/game-2471.xy",
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		require.NoError(t, err)
+
+		want, err := FileData(data).BlockList()
+		require.NoError(t, err)
+
+		// First call fills the cache, second one reads it back
+		for range 2 {
+			got, err := cache.BlockList(data)
+			require.NoError(t, err, f)
+			assert.Equal(t, want, got, f)
+		}
+	}
+	assert.NotEmpty(t, cacheEntries(t, cache.Dir()))
+}
+
+func TestCacheIgnoresBadEntries(t *testing.T) {
+	cache, err := NewCache(t.TempDir())
+	require.NoError(t, err)
+
+	data := encoding.HexToByteArray(testXFileHex)
+	want, err := FileData(data).BlockList()
+	require.NoError(t, err)
+
+	_, err = cache.BlockList(data)
+	require.NoError(t, err)
+	entries := cacheEntries(t, cache.Dir())
+	require.Len(t, entries, 1)
+
+	// A truncated entry is ignored and rewritten
+	require.NoError(t, os.WriteFile(entries[0], []byte("HBC\x01\x04"), 0o644))
+	got, err := cache.BlockList(data)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	entry, err := os.ReadFile(entries[0])
+	require.NoError(t, err)
+	_, err = decodeCacheEntry(entry, data)
+	assert.NoError(t, err)
+}
+
+func TestNilCache(t *testing.T) {
+	var cache *Cache
+	data := encoding.HexToByteArray(testXFileHex)
+
+	want, err := FileData(data).BlockList()
+	require.NoError(t, err)
+	got, err := cache.BlockList(data)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
@@ -71,9 +71,66 @@ func (fd FileData) ParseBlock(offset int) (*blocks.GenericBlock, error) {
 	}, nil
 }
 
+// DecryptedBlock is a block with its payload decrypted, before decoding into
+// a typed block. Trailer holds the unencrypted planet data that follows a
+// PlanetsBlock.
+type DecryptedBlock struct {
+	blocks.GenericBlock
+	Trailer []byte
+}
+
 // BlockList parses all blocks in the file data and returns them as a list
 func (fd FileData) BlockList() ([]blocks.Block, error) {
-	var blockList []blocks.Block
+	decrypted, err := fd.DecryptedBlocks()
+	if err != nil {
+		return nil, err
+	}
+	return BuildBlockList(decrypted)
+}
+
+// BlockListWith is BlockList with the decryption parameters of the header
+// changed by override (see DecryptedBlocksWith).
+func (fd FileData) BlockListWith(override func(*DecryptionParams)) ([]blocks.Block, error) {
+	decrypted, err := fd.DecryptedBlocksWith(override)
+	if err != nil {
+		return nil, err
+	}
+	return BuildBlockList(decrypted)
+}
+
+// DecryptionParams are the values from the file header that seed the
+// decryption of the blocks that follow it.
+type DecryptionParams struct {
+	Salt        int
+	GameID      int
+	Turn        int
+	PlayerIndex int
+	Shareware   int
+}
+
+// HeaderDecryptionParams returns the decryption parameters of a header.
+func HeaderDecryptionParams(header *blocks.FileHeader) DecryptionParams {
+	return DecryptionParams{
+		Salt:        header.Salt(),
+		GameID:      int(header.GameID),
+		Turn:        int(header.Turn),
+		PlayerIndex: header.PlayerIndex(),
+		Shareware:   header.SharewareFlag(),
+	}
+}
+
+// DecryptedBlocks splits the file data into blocks and decrypts them, without
+// decoding them into typed blocks (see BuildBlockList).
+func (fd FileData) DecryptedBlocks() ([]DecryptedBlock, error) {
+	return fd.DecryptedBlocksWith(nil)
+}
+
+// DecryptedBlocksWith is DecryptedBlocks with the decryption parameters of
+// the header changed by override, when not nil. It recovers files whose
+// header is damaged, when the right values are known from sibling files of
+// the same game and turn. The header block itself is returned unchanged.
+func (fd FileData) DecryptedBlocksWith(override func(*DecryptionParams)) ([]DecryptedBlock, error) {
+	var list []DecryptedBlock
 	decryptor := crypto.NewDecryptor()
 
 	offset := 0
@@ -84,40 +141,78 @@ func (fd FileData) BlockList() ([]blocks.Block, error) {
 		}
 
 		offset += int(block.Size) + 2
-		var item blocks.Block
+		item := DecryptedBlock{GenericBlock: *block}
 
-		// type 8
 		switch block.Type {
 		case blocks.FileHeaderBlockType:
 			header, err := blocks.NewFileHeader(*block)
 			if err != nil {
 				return nil, err
 			}
-			var sw int
-			if header.Crippled() {
-				sw = 1
+			params := HeaderDecryptionParams(header)
+			if override != nil {
+				override(&params)
 			}
-			decryptor.InitDecryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), sw)
-			item = *header
+			decryptor.InitDecryption(params.Salt, params.GameID, params.Turn, params.PlayerIndex, params.Shareware)
 		case blocks.FileFooterBlockType:
 			// File footer is NOT encrypted
-			block.Decrypted = blocks.DecryptedData(block.Data)
+			item.Decrypted = blocks.DecryptedData(block.Data)
+		default:
+			item.Decrypted = decryptor.DecryptBytes(block.Data)
+
+			if block.Type == blocks.PlanetsBlockType {
+				// PlanetsBlock is an exception in that it has more data tacked onto the end
+				// 4 bytes per planet
+				length := blocks.NewPlanetsBlock(item.GenericBlock).GetPlanetCount() * 4
+				if offset+length > len(fd) {
+					return nil, &ErrMalformedBlock{
+						Msg: fmt.Sprintf("malformed planets block, %d bytes of planet data past the end of the file", offset+length-len(fd)),
+					}
+				}
+				item.Trailer = fd[offset : offset+length]
+				// Adjust our offset to after the planet data
+				offset += length
+			}
+		}
+
+		list = append(list, item)
+	}
+
+	return list, nil
+}
+
+// BuildBlockList decodes decrypted blocks into typed blocks.
+func BuildBlockList(decrypted []DecryptedBlock) ([]blocks.Block, error) {
+	blockList := make([]blocks.Block, 0, len(decrypted))
+
+	for i := range decrypted {
+		block := &decrypted[i].GenericBlock
+		var item blocks.Block
+
+		switch block.Type {
+		case blocks.FileHeaderBlockType:
+			header, err := blocks.NewFileHeader(*block)
+			if err != nil {
+				return nil, err
+			}
+			item = *header
+		case blocks.FileFooterBlockType:
 			item = *blocks.NewFileFooterBlock(*block)
 		default:
-			block.Decrypted = decryptor.DecryptBytes(block.Data)
+			if factory, ok := registeredFactory(block.Type); ok {
+				decoded, err := factory(*block)
+				if err != nil {
+					return nil, fmt.Errorf("failed to decode block type %d: %w", block.Type, err)
+				}
+				item = decoded
+				break
+			}
 
 			switch block.Type {
 			case blocks.PlanetsBlockType:
 				// PlanetsBlock is an exception in that it has more data tacked onto the end
 				planetBlock := blocks.NewPlanetsBlock(*block)
-
-				// A bunch of planets data is tacked onto the end of this block
-				// We need to determine how much and parse it
-				// 4 bytes per planet
-				length := planetBlock.GetPlanetCount() * 4
-				planetBlock.ParsePlanetsData(fd[offset : offset+length])
-				// Adjust our offset to after the planet data
-				offset += length
+				planetBlock.ParsePlanetsData(decrypted[i].Trailer)
 				item = *planetBlock
 
 			case blocks.PlayerBlockType:
@@ -143,3 +143,33 @@ func TestEmptyFileData(t *testing.T) {
 		t.Errorf("Expected 0 blocks, got %d", len(blockList))
 	}
 }
+
+// TestDecryptedBlocksWith decrypts a file whose header lost its salt with the
+// salt of the intact file.
+func TestDecryptedBlocksWith(t *testing.T) {
+	fd := FileData(encoding.HexToByteArray(testXFileHex))
+	header, err := fd.FileHeader()
+	require.NoError(t, err)
+	params := HeaderDecryptionParams(header)
+	want, err := fd.DecryptedBlocks()
+	require.NoError(t, err)
+
+	// Flip a salt bit (bytes 12-13 of the header data hold salt and player)
+	damaged := FileData(append([]byte(nil), fd...))
+	damaged[2+13] ^= 0x40
+	garbled, err := damaged.DecryptedBlocks()
+	require.NoError(t, err)
+	require.NotEqual(t, want[2].Decrypted, garbled[2].Decrypted)
+
+	got, err := damaged.DecryptedBlocksWith(func(p *DecryptionParams) { p.Salt = params.Salt })
+	require.NoError(t, err)
+	require.Len(t, got, len(want))
+	for i := 1; i < len(want); i++ {
+		require.Equal(t, want[i].Decrypted, got[i].Decrypted, "block %d", i)
+	}
+
+	list, err := damaged.BlockListWith(func(p *DecryptionParams) { p.Salt = params.Salt })
+	require.NoError(t, err)
+	_, ok := list[2].(blocks.ProductionQueueChangeBlock)
+	require.True(t, ok, "got %T", list[2])
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/neper-stars/houston/blocks"
+)
+
+// BlockFactory decodes a decrypted block into a typed block. The block
+// returned should keep the raw and decrypted data of the generic block
+// (typically by embedding it) so that files can still be written back.
+type BlockFactory func(block blocks.GenericBlock) (blocks.Block, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[blocks.BlockTypeID]BlockFactory)
+)
+
+// RegisterBlockType registers an external decoder for a block type, so
+// that decoders of unknown or modded blocks can live outside this module.
+// Blocks of the type are then decoded by factory in BlockList and
+// BuildBlockList, ahead of any built-in decoder, and reach the store and
+// the block dumps like the built-in blocks.
+//
+// It is meant to be called from an init function and panics if factory is
+// nil, if the type is already registered, or for the file header, file
+// footer and planets blocks, which the parser decodes itself to read the
+// rest of the file.
+func RegisterBlockType(id blocks.BlockTypeID, factory BlockFactory) {
+	if factory == nil {
+		panic("parser: RegisterBlockType factory is nil")
+	}
+	switch id {
+	case blocks.FileHeaderBlockType, blocks.FileFooterBlockType, blocks.PlanetsBlockType:
+		panic(fmt.Sprintf("parser: block type %d (%s) cannot be registered", id, blocks.BlockTypeName(id)))
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[id]; dup {
+		panic(fmt.Sprintf("parser: RegisterBlockType called twice for block type %d", id))
+	}
+	registry[id] = factory
+}
+
+// registeredFactory returns the external decoder of a block type, if any.
+func registeredFactory(id blocks.BlockTypeID) (BlockFactory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	factory, ok := registry[id]
+	return factory, ok
+}
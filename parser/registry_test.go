@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+)
+
+// moddedBlockType is a block type unused by Stars!.
+const moddedBlockType blocks.BlockTypeID = 63
+
+type moddedBlock struct {
+	blocks.GenericBlock
+	Value byte
+}
+
+func registerModdedBlock(t *testing.T, factory BlockFactory) {
+	t.Helper()
+	RegisterBlockType(moddedBlockType, factory)
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, moddedBlockType)
+		registryMu.Unlock()
+	})
+}
+
+func TestRegisterBlockType(t *testing.T) {
+	registerModdedBlock(t, func(block blocks.GenericBlock) (blocks.Block, error) {
+		if len(block.Decrypted) < 1 {
+			return nil, errors.New("too short")
+		}
+		return moddedBlock{GenericBlock: block, Value: block.Decrypted[0]}, nil
+	})
+
+	decrypted := []DecryptedBlock{
+		{GenericBlock: blocks.GenericBlock{Type: moddedBlockType, Size: 1, Decrypted: []byte{42}}},
+		{GenericBlock: blocks.GenericBlock{Type: blocks.UnknownBlock22BlockType}},
+	}
+	list, err := BuildBlockList(decrypted)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	modded, ok := list[0].(moddedBlock)
+	require.True(t, ok, "registered type decoded by its factory")
+	assert.Equal(t, byte(42), modded.Value)
+	assert.Equal(t, blocks.DecryptedData{42}, modded.DecryptedData())
+	assert.IsType(t, blocks.GenericBlock{}, list[1], "other unknown types stay generic")
+
+	// Decoding errors are reported
+	decrypted[0].Decrypted = nil
+	_, err = BuildBlockList(decrypted)
+	assert.ErrorContains(t, err, "too short")
+
+	// Registering a type twice panics
+	assert.Panics(t, func() {
+		RegisterBlockType(moddedBlockType, func(block blocks.GenericBlock) (blocks.Block, error) { return block, nil })
+	})
+}
+
+func TestRegisterBlockTypeReserved(t *testing.T) {
+	factory := func(block blocks.GenericBlock) (blocks.Block, error) { return block, nil }
+	for _, id := range []blocks.BlockTypeID{blocks.FileHeaderBlockType, blocks.FileFooterBlockType, blocks.PlanetsBlockType} {
+		assert.Panics(t, func() { RegisterBlockType(id, factory) })
+	}
+	assert.Panics(t, func() { RegisterBlockType(moddedBlockType, nil) })
+}
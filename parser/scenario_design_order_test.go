@@ -113,6 +113,9 @@ func TestScenarioDesignOrder_DesignChangeBlocks(t *testing.T) {
 				assert.Equal(t, exp.SlotCount, dcb.Design.SlotCount, "Slot count should match")
 				assert.True(t, dcb.Design.IsFullDesign, "Should be a full design")
 
+				// Changes encode back to what Stars! wrote
+				assert.Equal(t, []byte(dcb.Decrypted), dcb.Encode(), "Encoded change should match")
+
 				// Component slots
 				require.Equal(t, len(exp.Slots), len(dcb.Design.Slots), "Should have %d slots", len(exp.Slots))
 				for j, expSlot := range exp.Slots {
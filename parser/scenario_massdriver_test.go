@@ -127,3 +127,42 @@ func TestScenarioMassDriver_Destinations(t *testing.T) {
 		})
 	}
 }
+
+func TestScenarioMassDriver_TargetOrder(t *testing.T) {
+	expected := loadMassDriverExpected(t)
+	exp := expected.MassDriverDestinations[0]
+	_, blockList := loadMassDriverFile(t, "game.x1")
+
+	var order *blocks.PlanetChangeBlock
+	for _, block := range blockList {
+		if pcb, ok := block.(blocks.PlanetChangeBlock); ok {
+			order = &pcb
+		}
+	}
+	require.NotNil(t, order, "X file should contain a PlanetChangeBlock")
+
+	assert.Equal(t, exp.PlanetNumber, order.PlanetId)
+	target, ok := order.MassDriverTarget()
+	require.True(t, ok, "Mass driver destination should be set")
+	assert.Equal(t, exp.DestinationNumber, target)
+	assert.Equal(t, 7, order.PacketWarp(), "Screenshot shows packets flung at warp 7")
+
+	// Building the same order from scratch yields identical bytes
+	built := blocks.NewMassDriverTargetOrder(exp.PlanetNumber, exp.DestinationNumber, 7)
+	assert.Equal(t, []byte(order.DecryptedData()), []byte(built.DecryptedData()))
+}
+
+func TestScenarioMassDriver_PlanetPacketWarp(t *testing.T) {
+	expected := loadMassDriverExpected(t)
+	exp := expected.MassDriverDestinations[0]
+	_, blockList := loadMassDriverFile(t, "game.m1")
+
+	for _, block := range blockList {
+		if pb, ok := block.(blocks.PlanetBlock); ok && pb.PlanetNumber == exp.PlanetNumber {
+			assert.Equal(t, exp.DestinationNumber+1, pb.MassDriverDest)
+			assert.Equal(t, 7, pb.PacketWarpSpeed+blocks.PacketWarpOffset)
+			return
+		}
+	}
+	t.Fatalf("planet %d not found", exp.PlanetNumber)
+}
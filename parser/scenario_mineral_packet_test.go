@@ -218,3 +218,54 @@ func TestScenarioMineralPacketBombardment(t *testing.T) {
 		})
 	}
 }
+
+func TestScenarioMineralPacket_LaunchOrders(t *testing.T) {
+	expectedData, err := os.ReadFile("../testdata/scenario-mineral-packet/expected.json")
+	require.NoError(t, err, "failed to read expected.json")
+
+	var expected mineralPacketExpected
+	require.NoError(t, json.Unmarshal(expectedData, &expected), "failed to parse expected.json")
+
+	data, err := os.ReadFile("../testdata/scenario-mineral-packet/game.x1")
+	require.NoError(t, err, "failed to read game.x1")
+
+	blockList, err := parser.FileData(data).BlockList()
+	require.NoError(t, err, "failed to parse block list")
+
+	var planetChange *blocks.PlanetChangeBlock
+	var queueChange *blocks.ProductionQueueChangeBlock
+	for _, block := range blockList {
+		switch b := block.(type) {
+		case blocks.PlanetChangeBlock:
+			planetChange = &b
+		case blocks.ProductionQueueChangeBlock:
+			queueChange = &b
+		}
+	}
+	require.NotNil(t, planetChange, "expected a PlanetChangeBlock")
+	require.NotNil(t, queueChange, "expected a ProductionQueueChangeBlock")
+
+	t.Run("DriverTarget", func(t *testing.T) {
+		assert.Equal(t, expected.PacketEvent.SourcePlanetID, planetChange.PlanetId)
+		target, ok := planetChange.MassDriverTarget()
+		require.True(t, ok, "mass driver destination should be set")
+		assert.Equal(t, expected.PacketEvent.DestinationPlanetID, target)
+		assert.Equal(t, expected.PacketObject.WarpSpeed, planetChange.PacketWarp())
+	})
+
+	t.Run("PacketQueueItem", func(t *testing.T) {
+		assert.Equal(t, expected.PacketEvent.SourcePlanetID, queueChange.PlanetId)
+		first := queueChange.GetItem(0)
+		require.NotNil(t, first)
+		assert.True(t, first.IsPacket(), "first queue item should launch packets")
+		assert.Equal(t, blocks.ProductionItemPacketMixed, first.ItemId)
+		assert.Equal(t, 2, blocks.PacketCount(queueChange.Items))
+	})
+
+	t.Run("RebuildQueueOrder", func(t *testing.T) {
+		items := append([]blocks.QueueItem{blocks.NewPacketQueueItem(blocks.ProductionItemPacketMixed, 2)},
+			queueChange.Items[1:]...)
+		built := blocks.NewProductionQueueOrder(queueChange.PlanetId, items)
+		assert.Equal(t, []byte(queueChange.DecryptedData()), []byte(built.DecryptedData()))
+	})
+}
@@ -58,3 +58,34 @@ func TestScenarioResearchNextChange(t *testing.T) {
 		}
 	})
 }
+
+func TestScenarioResearchNextChange_PlayerBlock(t *testing.T) {
+	data, err := os.ReadFile("../testdata/scenario-research-next-change/game.m1")
+	if err != nil {
+		t.Fatalf("Failed to read M file: %v", err)
+	}
+
+	blockList, err := parser.FileData(data).BlockList()
+	if err != nil {
+		t.Fatalf("Failed to parse blocks: %v", err)
+	}
+
+	for _, block := range blockList {
+		pb, ok := block.(blocks.PlayerBlock)
+		if !ok || !pb.FullDataFlag {
+			continue
+		}
+		// Before the order: researching Biotechnology, next field left at "same field"
+		if pb.ResearchPercentage != 15 {
+			t.Errorf("Expected budget 15%%, got %d%%", pb.ResearchPercentage)
+		}
+		if pb.CurrentResearchField != blocks.ResearchFieldBiotechnology {
+			t.Errorf("Expected current field Biotechnology (5), got %d", pb.CurrentResearchField)
+		}
+		if pb.NextResearchField != blocks.ResearchFieldSameField {
+			t.Errorf("Expected next field <Same Field> (6), got %d", pb.NextResearchField)
+		}
+		return
+	}
+	t.Fatal("PlayerBlock with full data not found")
+}
@@ -0,0 +1,173 @@
+package password
+
+import (
+	"math"
+	"math/bits"
+)
+
+// keyspace is the ordered set of passwords a search tries, which workers
+// split in chunks.
+type keyspace interface {
+	size() uint64
+	// cursor returns a cursor on the password at index
+	cursor(index uint64) cursor
+}
+
+// cursor walks a keyspace from a password to the next.
+type cursor interface {
+	password() []byte
+	next()
+}
+
+// masks is the keyspace of passwords of a sequence of masks, one after
+// the other.
+type masks []Mask
+
+func (m masks) size() uint64 {
+	var total uint64
+	for _, mask := range m {
+		total = addSaturating(total, mask.Size())
+	}
+	return total
+}
+
+func (m masks) cursor(index uint64) cursor {
+	c := &maskCursor{masks: m}
+	for c.mask < len(m)-1 && index >= m[c.mask].Size() {
+		index -= m[c.mask].Size()
+		c.mask++
+	}
+	c.reset()
+	mask := m[c.mask]
+	for i := len(mask) - 1; i >= 0; i-- {
+		n := uint64(len(mask[i]))
+		c.digits[i] = int(index % n)
+		c.buf[i] = mask[i][c.digits[i]]
+		index /= n
+	}
+	return c
+}
+
+// maskCursor is a password of a mask, with the indices of its characters
+// in the charsets of their positions.
+type maskCursor struct {
+	masks  masks
+	mask   int
+	digits []int
+	buf    []byte
+}
+
+// reset moves the cursor to the first password of its mask.
+func (c *maskCursor) reset() {
+	mask := c.masks[c.mask]
+	c.digits = make([]int, len(mask))
+	c.buf = make([]byte, len(mask))
+	for i, chars := range mask {
+		c.buf[i] = chars[0]
+	}
+}
+
+func (c *maskCursor) password() []byte {
+	return c.buf
+}
+
+func (c *maskCursor) next() {
+	mask := c.masks[c.mask]
+	for i := len(mask) - 1; i >= 0; i-- {
+		c.digits[i]++
+		if c.digits[i] < len(mask[i]) {
+			c.buf[i] = mask[i][c.digits[i]]
+			return
+		}
+		c.digits[i] = 0
+		c.buf[i] = mask[i][0]
+	}
+	if c.mask < len(c.masks)-1 {
+		c.mask++
+		c.reset()
+	}
+}
+
+// wordlist is the keyspace of the words of a list, each mutated by every
+// rule in turn, and completed with every password of a suffix mask.
+type wordlist struct {
+	words  []string
+	rules  []Rule
+	suffix Mask
+}
+
+func (w *wordlist) size() uint64 {
+	hi, lo := bits.Mul64(uint64(len(w.words)), uint64(len(w.rules)))
+	if hi != 0 {
+		return math.MaxUint64
+	}
+	hi, lo = bits.Mul64(lo, w.suffix.Size())
+	if hi != 0 {
+		return math.MaxUint64
+	}
+	return lo
+}
+
+func (w *wordlist) cursor(index uint64) cursor {
+	suffixes := w.suffix.Size()
+	variant := index / suffixes
+	c := &wordCursor{
+		list: w,
+		word: int(variant / uint64(len(w.rules))),
+		rule: int(variant % uint64(len(w.rules))),
+	}
+	c.mutate()
+	suffix := masks{w.suffix}.cursor(index % suffixes)
+	c.suffix = suffix.(*maskCursor)
+	return c
+}
+
+// wordCursor is a word mutated by a rule, completed with a suffix.
+type wordCursor struct {
+	list       *wordlist
+	word, rule int
+	mutated    []byte
+	suffix     *maskCursor
+	buf        []byte
+}
+
+// mutate applies the cursor's rule to its word.
+func (c *wordCursor) mutate() {
+	if c.word < len(c.list.words) {
+		c.mutated = c.list.rules[c.rule].Apply([]byte(c.list.words[c.word]))
+	}
+}
+
+func (c *wordCursor) password() []byte {
+	c.buf = append(append(c.buf[:0], c.mutated...), c.suffix.password()...)
+	return c.buf
+}
+
+func (c *wordCursor) next() {
+	c.suffix.next()
+	if !allZero(c.suffix.digits) {
+		return
+	}
+	if c.rule++; c.rule == len(c.list.rules) {
+		c.rule = 0
+		c.word++
+	}
+	c.mutate()
+}
+
+func allZero(digits []int) bool {
+	for _, d := range digits {
+		if d != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func addSaturating(a, b uint64) uint64 {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return math.MaxUint64
+	}
+	return sum
+}
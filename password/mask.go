@@ -0,0 +1,88 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+var ErrInvalidMask = errors.New("invalid mask")
+
+// Charsets of the hashcat-style mask placeholders.
+const (
+	LowerChars   = "abcdefghijklmnopqrstuvwxyz"
+	UpperChars   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	DigitChars   = "0123456789"
+	SpecialChars = " !\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+)
+
+// Mask is a set of passwords of a fixed length, given the characters each
+// position can take.
+type Mask [][]byte
+
+// ParseMask parses a hashcat-style mask: ?l, ?u, ?d and ?s stand for a
+// lowercase letter, an uppercase letter, a digit and a special character,
+// ?a for any of them and ?? for a question mark. Other characters stand
+// for themselves.
+//
+//	mask, err := password.ParseMask("?u?l?l?l?d?d") // Abcd12
+func ParseMask(s string) (Mask, error) {
+	var mask Mask
+	for i := 0; i < len(s); i++ {
+		if s[i] != '?' {
+			mask = append(mask, []byte{s[i]})
+			continue
+		}
+		if i++; i == len(s) {
+			return nil, fmt.Errorf("%w: %q ends with ?", ErrInvalidMask, s)
+		}
+		var chars string
+		switch s[i] {
+		case 'l':
+			chars = LowerChars
+		case 'u':
+			chars = UpperChars
+		case 'd':
+			chars = DigitChars
+		case 's':
+			chars = SpecialChars
+		case 'a':
+			chars = LowerChars + UpperChars + DigitChars + SpecialChars
+		case '?':
+			chars = "?"
+		default:
+			return nil, fmt.Errorf("%w: unknown placeholder ?%c", ErrInvalidMask, s[i])
+		}
+		mask = append(mask, []byte(chars))
+	}
+	return mask, nil
+}
+
+// Size returns the number of passwords of the mask, saturating at
+// math.MaxUint64.
+func (m Mask) Size() uint64 {
+	size := uint64(1)
+	for _, chars := range m {
+		hi, lo := bits.Mul64(size, uint64(len(chars)))
+		if hi != 0 {
+			return math.MaxUint64
+		}
+		size = lo
+	}
+	return size
+}
+
+// bruteForce returns the masks of every password of 1 to maxLength
+// characters of charset, shortest first.
+func bruteForce(charset string, maxLength int) []Mask {
+	var masks []Mask
+	for length := 1; length <= maxLength; length++ {
+		mask := make(Mask, length)
+		for i := range mask {
+			mask[i] = []byte(charset)
+		}
+		masks = append(masks, mask)
+	}
+	return masks
+}
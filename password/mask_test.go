@@ -0,0 +1,45 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMask(t *testing.T) {
+	mask, err := ParseMask("?u?l-?d??")
+	require.NoError(t, err)
+	require.Len(t, mask, 5)
+	assert.Equal(t, []byte(UpperChars), mask[0])
+	assert.Equal(t, []byte(LowerChars), mask[1])
+	assert.Equal(t, []byte("-"), mask[2])
+	assert.Equal(t, []byte(DigitChars), mask[3])
+	assert.Equal(t, []byte("?"), mask[4])
+	assert.Equal(t, uint64(26*26*10), mask.Size())
+
+	mask, err = ParseMask("?a")
+	require.NoError(t, err)
+	assert.Len(t, mask[0], 95, "every printable ASCII character")
+
+	_, err = ParseMask("?x")
+	assert.ErrorIs(t, err, ErrInvalidMask)
+	_, err = ParseMask("ab?")
+	assert.ErrorIs(t, err, ErrInvalidMask)
+}
+
+func TestMasksKeyspace(t *testing.T) {
+	keys := masks(bruteForce("ab", 3))
+	require.Equal(t, SearchSpace(2, 3), keys.size())
+
+	var all []string
+	c := keys.cursor(0)
+	for range keys.size() {
+		all = append(all, string(c.password()))
+		c.next()
+	}
+	assert.Equal(t, []string{"a", "b", "aa", "ab", "ba", "bb", "aaa", "aab", "aba", "abb", "baa", "bab", "bba", "bbb"}, all)
+	for i, want := range all {
+		assert.Equal(t, want, string(keys.cursor(uint64(i)).password()), "cursor at %d", i)
+	}
+}
@@ -1,9 +1,9 @@
 package password
 
 import (
-	"runtime"
-	"sync"
-	"sync/atomic"
+	"context"
+	"math"
+	"math/bits"
 
 	"github.com/neper-stars/houston/log"
 )
@@ -11,29 +11,6 @@ import (
 // AsciiString contains all ASCII characters for password guessing
 var AsciiString string
 
-func generateCombinations(charset string, maxLength int) <-chan string {
-	ch := make(chan string)
-
-	go func() {
-		defer close(ch)
-		generate("", charset, maxLength, ch)
-	}()
-
-	return ch
-}
-
-func generate(prefix string, charset string, maxLength int, ch chan<- string) {
-	if maxLength == 0 {
-		return
-	}
-
-	for _, char := range charset {
-		newCombination := prefix + string(char)
-		ch <- newCombination
-		generate(newCombination, charset, maxLength-1, ch)
-	}
-}
-
 // HashRacePassword computes the weak hash of a race password
 func HashRacePassword(inputString string) uint32 {
 	return HashRacePasswordBytes([]byte(inputString))
@@ -79,30 +56,49 @@ func HashRacePasswordBytes(charList []byte) uint32 {
 // matchesAllowed = quit after this many matches are found
 //
 // charset = array of ascii values to produce combinations from
+//
+// The search runs on all CPU cores; Search gives control over it.
 func GuessRacePassword(hash uint32, maxLength int, matchesAllowed int, charset string, verbose bool) []string {
-	var matches []string
-
-	for combination := range generateCombinations(charset, maxLength) {
-		possibleHash := HashRacePassword(combination)
-		if possibleHash == hash {
-			matches = append(matches, combination)
-			if verbose {
-				log.Debug("found password", log.F("password", combination))
-			}
-		}
-		if len(matches) == matchesAllowed {
-			return matches
+	cp, _ := Search(context.Background(), hash, SearchOptions{
+		Charset:   charset,
+		MaxLength: maxLength,
+		Matches:   matchesAllowed,
+	})
+	if verbose {
+		for _, match := range cp.Matches {
+			log.Debug("found password", log.F("password", match))
 		}
 	}
-
-	return matches
+	return cp.Matches
 }
 
 // ProgressCallback is called periodically with the number of passwords tried.
 type ProgressCallback func(tried uint64)
 
+// SearchSpace returns the number of passwords a brute force search tries
+// when nothing matches: every string of 1 to maxLength characters from a
+// charset of charsetLen characters. It saturates at math.MaxUint64.
+func SearchSpace(charsetLen, maxLength int) uint64 {
+	if charsetLen <= 0 {
+		return 0
+	}
+	var total, perLength uint64 = 0, 1
+	for length := 1; length <= maxLength; length++ {
+		hi, lo := bits.Mul64(perLength, uint64(charsetLen))
+		if hi != 0 {
+			return math.MaxUint64
+		}
+		perLength = lo
+		sum, carry := bits.Add64(total, perLength, 0)
+		if carry != 0 {
+			return math.MaxUint64
+		}
+		total = sum
+	}
+	return total
+}
+
 // GuessRacePasswordParallel guesses a race file's password using parallel workers.
-// This is significantly faster than GuessRacePassword on multi-core systems.
 //
 // Parameters:
 //   - hash: the target hash to find collisions for
@@ -112,143 +108,18 @@ type ProgressCallback func(tried uint64)
 //   - workers: number of parallel workers (0 = use all CPU cores)
 //   - progress: optional callback for progress reporting (can be nil)
 //
-// Returns a slice of matching passwords.
+// Returns a slice of matching passwords. It is Search without cancellation
+// nor checkpoints.
 func GuessRacePasswordParallel(hash uint32, maxLength, matchesAllowed int,
 	charset string, workers int, progress ProgressCallback) []string {
-	if workers <= 0 {
-		workers = runtime.NumCPU()
-	}
-
-	charsetBytes := []byte(charset)
-	charsetLen := len(charsetBytes)
-
-	if charsetLen == 0 || maxLength == 0 {
-		return nil
-	}
-
-	// Channel for results
-	resultCh := make(chan string, 100)
-
-	// Atomic counters
-	var matchCount atomic.Int64
-	var triedCount atomic.Uint64
-	var done atomic.Bool
-
-	// WaitGroup for workers
-	var wg sync.WaitGroup
-
-	// Partition work by first character
-	// Each worker handles passwords starting with specific first characters
-	charsPerWorker := (charsetLen + workers - 1) / workers
-
-	for w := 0; w < workers; w++ {
-		startIdx := w * charsPerWorker
-		endIdx := startIdx + charsPerWorker
-		if endIdx > charsetLen {
-			endIdx = charsetLen
-		}
-		if startIdx >= charsetLen {
-			break
-		}
-
-		wg.Add(1)
-		go func(firstCharStart, firstCharEnd int) {
-			defer wg.Done()
-
-			// Local buffer for password building (avoids allocations)
-			buf := make([]byte, maxLength)
-
-			// Process each first character assigned to this worker
-			for firstIdx := firstCharStart; firstIdx < firstCharEnd; firstIdx++ {
-				if done.Load() {
-					return
-				}
-
-				buf[0] = charsetBytes[firstIdx]
-
-				// Generate all combinations with this first character
-				workerGenerate(buf, 1, maxLength, charsetBytes, hash,
-					matchesAllowed, &matchCount, &triedCount, &done, resultCh)
-			}
-		}(startIdx, endIdx)
-	}
-
-	// Progress reporter goroutine
-	var progressWg sync.WaitGroup
-	if progress != nil {
-		progressWg.Add(1)
-		go func() {
-			defer progressWg.Done()
-			for !done.Load() {
-				progress(triedCount.Load())
-				runtime.Gosched()
-			}
-		}()
-	}
-
-	// Collector goroutine
-	var matches []string
-	var collectorWg sync.WaitGroup
-	collectorWg.Add(1)
-	go func() {
-		defer collectorWg.Done()
-		for match := range resultCh {
-			matches = append(matches, match)
-		}
-	}()
-
-	// Wait for all workers to finish
-	wg.Wait()
-	done.Store(true)
-	close(resultCh)
-
-	// Wait for collector
-	collectorWg.Wait()
-	progressWg.Wait()
-
-	// Final progress update
-	if progress != nil {
-		progress(triedCount.Load())
-	}
-
-	return matches
-}
-
-// workerGenerate recursively generates and tests password combinations.
-// It uses a pre-allocated buffer to avoid string allocations.
-func workerGenerate(buf []byte, pos, maxLen int, charset []byte, targetHash uint32,
-	matchesAllowed int, matchCount *atomic.Int64, triedCount *atomic.Uint64,
-	done *atomic.Bool, resultCh chan<- string) {
-	// Test current password (length = pos)
-	if pos > 0 {
-		password := buf[:pos]
-		triedCount.Add(1)
-
-		if HashRacePasswordBytes(password) == targetHash {
-			// Found a match
-			count := matchCount.Add(1)
-			resultCh <- string(password)
-
-			if matchesAllowed > 0 && int(count) >= matchesAllowed {
-				done.Store(true)
-				return
-			}
-		}
-	}
-
-	// Generate longer passwords
-	if pos >= maxLen || done.Load() {
-		return
-	}
-
-	for _, c := range charset {
-		if done.Load() {
-			return
-		}
-		buf[pos] = c
-		workerGenerate(buf, pos+1, maxLen, charset, targetHash,
-			matchesAllowed, matchCount, triedCount, done, resultCh)
-	}
+	cp, _ := Search(context.Background(), hash, SearchOptions{
+		Charset:   charset,
+		MaxLength: maxLength,
+		Matches:   matchesAllowed,
+		Workers:   workers,
+		Progress:  progress,
+	})
+	return cp.Matches
 }
 
 func init() {
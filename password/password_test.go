@@ -2,6 +2,7 @@ package password
 
 import (
 	"encoding/binary"
+	"math"
 	"runtime"
 	"testing"
 
@@ -133,6 +134,19 @@ func TestGuessRacePasswordParallelWithProgress(t *testing.T) {
 	assert.Greater(t, lastCount, uint64(0), "should have tried some passwords")
 }
 
+func TestSearchSpace(t *testing.T) {
+	assert.Equal(t, uint64(0), SearchSpace(0, 8))
+	assert.Equal(t, uint64(0), SearchSpace(26, 0))
+	assert.Equal(t, uint64(26), SearchSpace(26, 1))
+	assert.Equal(t, uint64(26+26*26+26*26*26), SearchSpace(26, 3))
+	assert.Equal(t, uint64(math.MaxUint64), SearchSpace(95, 20), "saturates")
+
+	// An exhaustive search tries exactly the search space
+	var tried uint64
+	GuessRacePasswordParallel(0, 3, 0, "abc", 2, func(n uint64) { tried = n })
+	assert.Equal(t, SearchSpace(3, 3), tried)
+}
+
 func TestGuessRacePasswordParallelRealFile(t *testing.T) {
 	// Test against a real race file with known password "f00ls"
 	// The hash is extracted from the PlayerBlock in the race file
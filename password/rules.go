@@ -0,0 +1,203 @@
+package password
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidRule = errors.New("invalid rule")
+
+// Rule mutates the words of a wordlist, for a dictionary search to try
+// the variations people make of a word: "password", "Password",
+// "password1"... The zero value keeps the words as they are.
+type Rule struct {
+	source string
+	funcs  []func([]byte) []byte
+}
+
+// ParseRule parses a rule in the syntax of hashcat, a sequence of
+// functions applied one after the other. The functions supported are:
+//
+//	:    keep the word as is
+//	l u  lowercase, uppercase all letters
+//	c C  capitalize the first letter and lowercase the rest, or the reverse
+//	t    toggle the case of all letters
+//	TN   toggle the case of the letter at position N (0-9)
+//	r    reverse the word
+//	d    duplicate the word
+//	f    append the word reversed
+//	$X   append the character X
+//	^X   prepend the character X
+//	[ ]  delete the first, the last character
+//	sXY  replace every X by Y
+//
+// Spaces between functions are ignored.
+//
+//	rule, err := password.ParseRule("c $1 $2") // password -> Password12
+func ParseRule(s string) (Rule, error) {
+	var rule []func([]byte) []byte
+	arg := func(i, n int) (string, error) {
+		if i+n >= len(s) {
+			return "", fmt.Errorf("%w: %q: %c needs %d characters", ErrInvalidRule, s, s[i], n)
+		}
+		return s[i+1 : i+1+n], nil
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case ' ', ':':
+		case 'l':
+			rule = append(rule, bytes.ToLower)
+		case 'u':
+			rule = append(rule, bytes.ToUpper)
+		case 'c':
+			rule = append(rule, capitalize)
+		case 'C':
+			rule = append(rule, func(w []byte) []byte { return toggle(capitalize(w)) })
+		case 't':
+			rule = append(rule, toggle)
+		case 'r':
+			rule = append(rule, func(w []byte) []byte { slices.Reverse(w); return w })
+		case 'd':
+			rule = append(rule, func(w []byte) []byte { return append(w, w...) })
+		case 'f':
+			rule = append(rule, func(w []byte) []byte {
+				reversed := slices.Clone(w)
+				slices.Reverse(reversed)
+				return append(w, reversed...)
+			})
+		case '[':
+			rule = append(rule, func(w []byte) []byte {
+				if len(w) == 0 {
+					return w
+				}
+				return w[1:]
+			})
+		case ']':
+			rule = append(rule, func(w []byte) []byte {
+				if len(w) == 0 {
+					return w
+				}
+				return w[:len(w)-1]
+			})
+		case 'T', '$', '^':
+			a, err := arg(i, 1)
+			if err != nil {
+				return Rule{}, err
+			}
+			i++
+			x := a[0]
+			switch c {
+			case 'T':
+				if x < '0' || x > '9' {
+					return Rule{}, fmt.Errorf("%w: %q: T needs a position 0-9", ErrInvalidRule, s)
+				}
+				rule = append(rule, func(w []byte) []byte {
+					if n := int(x - '0'); n < len(w) {
+						w[n] = toggleCase(w[n])
+					}
+					return w
+				})
+			case '$':
+				rule = append(rule, func(w []byte) []byte { return append(w, x) })
+			case '^':
+				rule = append(rule, func(w []byte) []byte { return append([]byte{x}, w...) })
+			}
+		case 's':
+			a, err := arg(i, 2)
+			if err != nil {
+				return Rule{}, err
+			}
+			i += 2
+			from, to := a[0], a[1]
+			rule = append(rule, func(w []byte) []byte {
+				for j := range w {
+					if w[j] == from {
+						w[j] = to
+					}
+				}
+				return w
+			})
+		default:
+			return Rule{}, fmt.Errorf("%w: %q: unknown function %c", ErrInvalidRule, s, c)
+		}
+	}
+	return Rule{source: s, funcs: rule}, nil
+}
+
+// ParseRules parses one rule per line, skipping empty lines and comments
+// starting with #.
+func ParseRules(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule, err := ParseRule(line)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, scanner.Err()
+}
+
+// Apply returns the word mutated by the rule. The word is not modified.
+func (r Rule) Apply(word []byte) []byte {
+	w := slices.Clone(word)
+	for _, f := range r.funcs {
+		w = f(w)
+	}
+	return w
+}
+
+// String returns the rule as parsed.
+func (r Rule) String() string {
+	if r.source == "" {
+		return ":"
+	}
+	return r.source
+}
+
+// ReadWords reads a wordlist, one word per line. Empty lines are skipped.
+func ReadWords(r io.Reader) ([]string, error) {
+	var words []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if word := strings.TrimRight(scanner.Text(), "\r"); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words, scanner.Err()
+}
+
+func capitalize(w []byte) []byte {
+	w = bytes.ToLower(w)
+	if len(w) > 0 {
+		w[0] = toggleCase(w[0])
+	}
+	return w
+}
+
+func toggle(w []byte) []byte {
+	for i := range w {
+		w[i] = toggleCase(w[i])
+	}
+	return w
+}
+
+func toggleCase(c byte) byte {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return c - 'a' + 'A'
+	case c >= 'A' && c <= 'Z':
+		return c - 'A' + 'a'
+	}
+	return c
+}
@@ -0,0 +1,52 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRule(t *testing.T) {
+	for rule, want := range map[string]string{
+		":":       "pAssword",
+		"l":       "password",
+		"u":       "PASSWORD",
+		"c":       "Password",
+		"C":       "pASSWORD",
+		"t":       "PaSSWORD",
+		"T0":      "PAssword",
+		"r":       "drowssAp",
+		"d":       "pAsswordpAssword",
+		"f":       "pAssworddrowssAp",
+		"$1 $2":   "pAssword12",
+		"^!":      "!pAssword",
+		"[]":      "Asswor",
+		"so0 sa@": "pAssw0rd",
+		"c $1":    "Password1",
+	} {
+		r, err := ParseRule(rule)
+		require.NoError(t, err, rule)
+		word := []byte("pAssword")
+		assert.Equal(t, want, string(r.Apply(word)), rule)
+		assert.Equal(t, "pAssword", string(word), "%s leaves the word as is", rule)
+		assert.Equal(t, rule, r.String())
+	}
+
+	for _, rule := range []string{"x", "$", "s1", "Tx"} {
+		_, err := ParseRule(rule)
+		assert.ErrorIs(t, err, ErrInvalidRule, rule)
+	}
+}
+
+func TestParseRulesAndWords(t *testing.T) {
+	rules, err := ParseRules(strings.NewReader("# capitalized\nc\n\n:\n$1\n"))
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+	assert.Equal(t, "c", rules[0].String())
+
+	words, err := ReadWords(strings.NewReader("secret\r\n\nhunter2\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"secret", "hunter2"}, words)
+}
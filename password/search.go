@@ -0,0 +1,308 @@
+package password
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrCheckpointMismatch is returned when resuming a search from the
+// checkpoint of another one.
+var ErrCheckpointMismatch = errors.New("checkpoint is for another search")
+
+const (
+	// chunkSize is the number of passwords a worker takes from the
+	// keyspace at a time
+	chunkSize = 1 << 16
+	// progressInterval is the interval between two progress calls
+	progressInterval = 100 * time.Millisecond
+)
+
+// SearchOptions tunes a password search.
+//
+// The passwords tried are those of Words when set, each mutated by every
+// rule of Rules and completed by every password of Mask; otherwise those of
+// Mask when set; otherwise every password of 1 to MaxLength characters of
+// Charset.
+type SearchOptions struct {
+	Charset   string // Characters of the passwords tried by brute force
+	MaxLength int    // Longest password tried by brute force
+	Mask      string // Hashcat-style mask of the passwords tried, see ParseMask
+	Words     []string
+	Rules     []Rule // Rules mutating the words, the words as is when empty
+	Matches   int    // Stop after this many matches, 0 for all of them
+	Workers   int    // Parallel workers, all CPU cores when 0
+
+	// Progress is called periodically with the number of passwords tried,
+	// counting those tried before the search was resumed. Can be nil.
+	Progress ProgressCallback
+
+	// Resume continues the search from a checkpoint. Can be nil.
+	Resume *Checkpoint
+
+	// OnCheckpoint is called every CheckpointInterval (one minute when
+	// zero) with the state of the search, to be saved for resuming it
+	// after a crash. Can be nil.
+	OnCheckpoint       func(Checkpoint)
+	CheckpointInterval time.Duration
+}
+
+// Checkpoint is the state of a search, from which it can be resumed. It
+// marshals to JSON.
+//
+// The keyspace of a brute force search is every password of 1 to MaxLength
+// characters of Charset, shortest first and in the order of Charset for a
+// given length. A mask's passwords come in the order of the charsets of its
+// positions, the last one changing first; a wordlist's come word by word,
+// rule by rule, suffix by suffix. Every password before Next has been
+// tried.
+type Checkpoint struct {
+	Hash      uint32   `json:"hash"`
+	Charset   string   `json:"charset,omitempty"`
+	MaxLength int      `json:"maxLength,omitempty"`
+	Mask      string   `json:"mask,omitempty"`
+	Wordlist  string   `json:"wordlist,omitempty"` // SHA-256 of the words and rules
+	Next      uint64   `json:"next"`
+	Matches   []string `json:"matches"` // In keyspace order
+	Done      bool     `json:"done"`    // The keyspace is exhausted or enough matches were found
+}
+
+// Search searches the passwords of a hash by brute force, with workers
+// taking chunks of the keyspace in parallel. Matches are returned in
+// keyspace order, so the result does not depend on the number of workers.
+//
+// When ctx is cancelled, Search returns the checkpoint reached, from which
+// the search can be resumed, with the context's error.
+func Search(ctx context.Context, hash uint32, opts SearchOptions) (*Checkpoint, error) {
+	keys, cp, err := opts.keyspace()
+	if err != nil {
+		return nil, err
+	}
+	cp.Hash = hash
+	if r := opts.Resume; r != nil {
+		if r.Hash != hash || r.Mask != cp.Mask || r.Wordlist != cp.Wordlist || r.Charset != cp.Charset ||
+			r.MaxLength > cp.MaxLength { // The brute force keyspace only grows at its end with longer passwords
+			return nil, ErrCheckpointMismatch
+		}
+		cp.Next = r.Next
+		cp.Matches = slices.Clone(r.Matches)
+	}
+	total := keys.size()
+	if opts.Matches > 0 && len(cp.Matches) >= opts.Matches {
+		cp.Matches = cp.Matches[:opts.Matches]
+		cp.Done = true
+	}
+	if cp.Done || cp.Next >= total {
+		cp.Done = true
+		return cp, nil
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	interval := opts.CheckpointInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	s := &search{
+		hash:    hash,
+		keys:    keys,
+		base:    cp.Next,
+		total:   total,
+		results: make(chan chunkResult),
+	}
+	s.tried.Store(cp.Next)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work()
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(s.results)
+	}()
+
+	progress := time.NewTicker(progressInterval)
+	defer progress.Stop()
+	checkpoint := time.NewTicker(interval)
+	defer checkpoint.Stop()
+
+	// Chunks are done out of order: the watermark is the end of the chunks
+	// all done, and only the matches before it are sure to be the first.
+	var pending []match
+	done := make(map[uint64]bool)
+	nextChunk := uint64(0)
+	watermark := cp.Next
+	confirm := func() {
+		for done[nextChunk] {
+			delete(done, nextChunk)
+			nextChunk++
+			watermark = min(s.base+nextChunk*chunkSize, total)
+		}
+		slices.SortFunc(pending, func(a, b match) int { return cmp.Compare(a.index, b.index) })
+		for len(pending) > 0 && pending[0].index < watermark {
+			cp.Matches = append(cp.Matches, pending[0].password)
+			cp.Next = pending[0].index + 1
+			pending = pending[1:]
+			if opts.Matches > 0 && len(cp.Matches) == opts.Matches {
+				cp.Done = true
+				s.stop.Store(true)
+				return
+			}
+		}
+		cp.Next = watermark
+		if watermark >= total {
+			cp.Done = true
+		}
+	}
+
+	cancelled := ctx.Done()
+	for running := true; running; {
+		select {
+		case r, ok := <-s.results:
+			if !ok {
+				running = false
+				break
+			}
+			if cp.Done {
+				continue
+			}
+			done[r.chunk] = true
+			pending = append(pending, r.matches...)
+			confirm()
+		case <-cancelled:
+			err = ctx.Err()
+			s.stop.Store(true)
+			cancelled = nil
+		case <-progress.C:
+			if opts.Progress != nil {
+				opts.Progress(s.tried.Load())
+			}
+		case <-checkpoint.C:
+			if opts.OnCheckpoint != nil {
+				opts.OnCheckpoint(cp.clone())
+			}
+		}
+	}
+
+	if opts.Progress != nil {
+		opts.Progress(s.tried.Load())
+	}
+	if opts.OnCheckpoint != nil {
+		opts.OnCheckpoint(cp.clone())
+	}
+	if cp.Done {
+		err = nil
+	}
+	return cp, err
+}
+
+// Space returns the number of passwords a search with the options tries
+// when nothing matches, saturating at math.MaxUint64.
+func (o SearchOptions) Space() (uint64, error) {
+	keys, _, err := o.keyspace()
+	if err != nil {
+		return 0, err
+	}
+	return keys.size(), nil
+}
+
+// keyspace returns the keyspace of the passwords the options search, and
+// the checkpoint of its start.
+func (o SearchOptions) keyspace() (keyspace, *Checkpoint, error) {
+	var mask Mask
+	if o.Mask != "" {
+		var err error
+		if mask, err = ParseMask(o.Mask); err != nil {
+			return nil, nil, err
+		}
+	}
+	if o.Words == nil {
+		if o.Mask != "" {
+			return masks{mask}, &Checkpoint{Mask: o.Mask}, nil
+		}
+		return masks(bruteForce(o.Charset, o.MaxLength)), &Checkpoint{Charset: o.Charset, MaxLength: o.MaxLength}, nil
+	}
+
+	rules := o.Rules
+	if len(rules) == 0 {
+		rules = []Rule{{}}
+	}
+	h := sha256.New()
+	for _, word := range o.Words {
+		h.Write([]byte(word + "\n"))
+	}
+	for _, rule := range rules {
+		h.Write([]byte(rule.String() + "\n"))
+	}
+	return &wordlist{words: o.Words, rules: rules, suffix: mask},
+		&Checkpoint{Mask: o.Mask, Wordlist: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+func (c *Checkpoint) clone() Checkpoint {
+	clone := *c
+	clone.Matches = slices.Clone(c.Matches)
+	return clone
+}
+
+// search is the state the workers of a Search share.
+type search struct {
+	hash  uint32
+	keys  keyspace
+	base  uint64 // Keyspace index of the first chunk
+	total uint64
+
+	chunks  atomic.Uint64 // Next chunk to take
+	tried   atomic.Uint64
+	stop    atomic.Bool
+	results chan chunkResult
+}
+
+type match struct {
+	index    uint64
+	password string
+}
+
+type chunkResult struct {
+	chunk   uint64
+	matches []match
+}
+
+// work searches chunks of the keyspace until it is exhausted or the search
+// is stopped.
+func (s *search) work() {
+	for !s.stop.Load() {
+		chunk := s.chunks.Add(1) - 1
+		if chunk > (s.total-s.base)/chunkSize {
+			return
+		}
+		start := s.base + chunk*chunkSize
+		if start >= s.total {
+			return
+		}
+		end := min(start+chunkSize, s.total)
+
+		c := s.keys.cursor(start)
+		var matches []match
+		for index := start; index < end; index++ {
+			if password := c.password(); HashRacePasswordBytes(password) == s.hash {
+				matches = append(matches, match{index, string(password)})
+			}
+			c.next()
+		}
+		s.tried.Add(end - start)
+		s.results <- chunkResult{chunk, matches}
+	}
+}
@@ -0,0 +1,123 @@
+package password
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const aToZ = "abcdefghijklmnopqrstuvwxyz"
+
+func TestSearchKeyspaceOrder(t *testing.T) {
+	hash := HashRacePassword("azert")
+	var first []string
+	for _, workers := range []int{1, 3, 8} {
+		cp, err := Search(context.Background(), hash, SearchOptions{
+			Charset: aToZ, MaxLength: 5, Matches: 3, Workers: workers,
+		})
+		require.NoError(t, err)
+		assert.True(t, cp.Done)
+		require.Len(t, cp.Matches, 3)
+		assert.Equal(t, "azert", cp.Matches[0], "shortest first, then in charset order")
+		if first == nil {
+			first = cp.Matches
+		}
+		assert.Equal(t, first, cp.Matches, "the matches do not depend on the workers")
+	}
+}
+
+func TestSearchResume(t *testing.T) {
+	hash := HashRacePassword("azert")
+	all, err := Search(context.Background(), hash, SearchOptions{Charset: aToZ, MaxLength: 5})
+	require.NoError(t, err)
+	require.Greater(t, len(all.Matches), 2)
+
+	// Stop after the first match, then resume for the rest
+	cp, err := Search(context.Background(), hash, SearchOptions{Charset: aToZ, MaxLength: 5, Matches: 1})
+	require.NoError(t, err)
+	assert.Equal(t, all.Matches[:1], cp.Matches)
+
+	data, err := json.Marshal(cp)
+	require.NoError(t, err)
+	var saved Checkpoint
+	require.NoError(t, json.Unmarshal(data, &saved))
+
+	var tried uint64
+	rest, err := Search(context.Background(), hash, SearchOptions{
+		Charset: aToZ, MaxLength: 5, Resume: &saved,
+		Progress: func(n uint64) { tried = n },
+	})
+	require.NoError(t, err)
+	assert.True(t, rest.Done)
+	assert.Equal(t, all.Matches, rest.Matches)
+	assert.Equal(t, SearchSpace(26, 5), tried, "tried counts the passwords before the checkpoint")
+
+	// A checkpoint only resumes the same search, possibly with longer passwords
+	_, err = Search(context.Background(), hash, SearchOptions{Charset: "abc", MaxLength: 5, Resume: &saved})
+	assert.ErrorIs(t, err, ErrCheckpointMismatch)
+	_, err = Search(context.Background(), hash, SearchOptions{Charset: aToZ, MaxLength: 4, Resume: &saved})
+	assert.ErrorIs(t, err, ErrCheckpointMismatch)
+}
+
+func TestSearchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var checkpoints []Checkpoint
+	time.AfterFunc(50*time.Millisecond, cancel)
+	cp, err := Search(ctx, 0, SearchOptions{
+		Charset: AsciiString, MaxLength: 8, Workers: 2,
+		OnCheckpoint:       func(c Checkpoint) { checkpoints = append(checkpoints, c) },
+		CheckpointInterval: 10 * time.Millisecond,
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	require.NotNil(t, cp)
+	assert.False(t, cp.Done)
+	assert.Positive(t, cp.Next)
+	require.NotEmpty(t, checkpoints)
+	assert.Equal(t, *cp, checkpoints[len(checkpoints)-1], "the last checkpoint is the final state")
+}
+
+func TestSearchMask(t *testing.T) {
+	hash := HashRacePassword("Zeta42")
+	cp, err := Search(context.Background(), hash, SearchOptions{Mask: "?u?l?l?l?d?d"})
+	require.NoError(t, err)
+	require.NotEmpty(t, cp.Matches)
+	assert.Contains(t, cp.Matches, "Zeta42")
+	assert.Equal(t, "?u?l?l?l?d?d", cp.Mask)
+	space, err := SearchOptions{Mask: "?u?l?l?l?d?d"}.Space()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(26*26*26*26*100), space)
+
+	_, err = Search(context.Background(), hash, SearchOptions{Mask: "?q"})
+	assert.ErrorIs(t, err, ErrInvalidMask)
+}
+
+func TestSearchWords(t *testing.T) {
+	words := []string{"apple", "banana", "cherry"}
+	capitalize, err := ParseRule("c")
+	require.NoError(t, err)
+	rules := []Rule{{}, capitalize}
+
+	cp, err := Search(context.Background(), HashRacePassword("Banana"), SearchOptions{Words: words, Rules: rules})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Banana"}, cp.Matches)
+	assert.Equal(t, uint64(6), cp.Next)
+	assert.NotEmpty(t, cp.Wordlist)
+
+	// Hybrid: words completed by a mask
+	cp, err = Search(context.Background(), HashRacePassword("Cherry07"), SearchOptions{
+		Words: words, Rules: rules, Mask: "?d?d", Matches: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, cp.Matches, 1)
+	assert.Equal(t, HashRacePassword("Cherry07"), HashRacePassword(cp.Matches[0]))
+
+	// Checkpoints are tied to the words and rules
+	_, err = Search(context.Background(), HashRacePassword("Cherry07"), SearchOptions{
+		Words: words, Mask: "?d?d", Resume: cp,
+	})
+	assert.ErrorIs(t, err, ErrCheckpointMismatch)
+}
@@ -25,11 +25,16 @@ type Builder struct {
 	race *Race
 }
 
-// New creates a new race builder with default Humanoid values.
-func New() *Builder {
-	return &Builder{
+// New creates a new race builder with default Humanoid values, changed by
+// the given options (see Option).
+func New(opts ...Option) *Builder {
+	b := &Builder{
 		race: Default(),
 	}
+	for _, opt := range opts {
+		opt(b.race)
+	}
+	return b
 }
 
 // recalculate computes points and validation, returning a TransientRace.
@@ -256,6 +261,9 @@ func (b *Builder) MineCount(count int) *TransientRace {
 }
 
 // Factories sets all factory parameters at once (convenience method).
+//
+// Deprecated: use Apply with WithFactoryOutput, WithFactoryCost,
+// WithFactoryCount and WithCheapGermaniumFactories.
 func (b *Builder) Factories(output, cost, count int, useLessGerm bool) *TransientRace {
 	b.race.FactoryOutput = output
 	b.race.FactoryCost = cost
@@ -265,6 +273,8 @@ func (b *Builder) Factories(output, cost, count int, useLessGerm bool) *Transien
 }
 
 // Mines sets all mine parameters at once (convenience method).
+//
+// Deprecated: use Apply with WithMineOutput, WithMineCost and WithMineCount.
 func (b *Builder) Mines(output, cost, count int) *TransientRace {
 	b.race.MineOutput = output
 	b.race.MineCost = cost
@@ -325,6 +335,8 @@ func (b *Builder) TechsStartHigh(startHigh bool) *TransientRace {
 
 // Research sets research cost levels for all fields at once (convenience method).
 // Use ResearchCostExtra (0), ResearchCostStandard (1), or ResearchCostLess (2).
+//
+// Deprecated: use Apply with the With*Research options.
 func (b *Builder) Research(energy, weapons, propulsion, construction, electronics, biotech int) *TransientRace {
 	b.race.ResearchEnergy = energy
 	b.race.ResearchWeapons = weapons
@@ -212,3 +212,39 @@ func TestBuilderChaining(t *testing.T) {
 		t.Error("chaining didn't preserve growth rate")
 	}
 }
+
+func TestBuilderOptions(t *testing.T) {
+	b := New(
+		WithName("Warrior", "Warriors"),
+		WithPRT(PRTWarMonger),
+		WithLRTs(LRTImprovedFuelEfficiency, LRTCheapEngines),
+		WithGravityImmune(),
+		WithFactoryOutput(12),
+		WithCheapGermaniumFactories(),
+		WithBiotechResearch(ResearchCostExtra),
+	)
+	result := b.Get()
+	if result.Race.PluralName != "Warriors" || result.Race.PRT != PRTWarMonger {
+		t.Errorf("options not applied: %+v", result.Race)
+	}
+	if result.Race.LRT != LRTs(LRTImprovedFuelEfficiency, LRTCheapEngines) {
+		t.Errorf("expected IFE and CE, got LRT bitmask %d", result.Race.LRT)
+	}
+	if !result.Race.GravityImmune || result.Race.FactoryOutput != 12 || !result.Race.FactoriesUseLessGerm {
+		t.Errorf("options not applied: %+v", result.Race)
+	}
+	if result.Race.ResearchBiotech != ResearchCostExtra {
+		t.Errorf("expected biotech research extra, got %d", result.Race.ResearchBiotech)
+	}
+	if result.Race.MineOutput != Default().MineOutput {
+		t.Error("settings left out should keep the defaults")
+	}
+
+	result = b.Apply(WithoutLRTs(LRTCheapEngines), WithGravity(40, 20))
+	if result.Race.LRT != LRTs(LRTImprovedFuelEfficiency) {
+		t.Errorf("expected IFE only, got LRT bitmask %d", result.Race.LRT)
+	}
+	if result.Race.GravityImmune || result.Race.GravityCenter != 40 || result.Race.GravityWidth != 20 {
+		t.Errorf("expected gravity 40±20, got %+v", result.Race)
+	}
+}
@@ -0,0 +1,192 @@
+package race
+
+// Option sets race settings when building a race:
+//
+//	b := race.New(
+//		race.WithName("Rabbitoid", "Rabbitoids"),
+//		race.WithPRT(race.PRTInterstellarTraveler),
+//		race.WithLRTs(race.LRTImprovedFuelEfficiency, race.LRTTotalTerraforming),
+//		race.WithFactoryOutput(10),
+//	)
+//
+// Options are applied in order; settings left out keep the Humanoid defaults.
+type Option func(*Race)
+
+// Apply applies options to the race being built.
+func (b *Builder) Apply(opts ...Option) *TransientRace {
+	for _, opt := range opts {
+		opt(b.race)
+	}
+	return b.recalculate()
+}
+
+// --- Identity ---
+
+// WithName sets the singular and plural race names.
+func WithName(singular, plural string) Option {
+	return func(r *Race) {
+		r.SingularName = singular
+		r.PluralName = plural
+	}
+}
+
+// WithPassword sets the race password.
+func WithPassword(password string) Option {
+	return func(r *Race) { r.Password = password }
+}
+
+// WithIcon sets the race icon/logo (0-31).
+func WithIcon(icon int) Option {
+	return func(r *Race) { r.Icon = icon }
+}
+
+// --- Traits ---
+
+// WithPRT sets the Primary Race Trait (0-9).
+func WithPRT(prt int) Option {
+	return func(r *Race) { r.PRT = prt }
+}
+
+// WithLRTs adds Lesser Race Traits by index (0-13).
+func WithLRTs(lrtIndices ...int) Option {
+	return func(r *Race) { r.LRT |= LRTs(lrtIndices...) }
+}
+
+// WithoutLRTs removes Lesser Race Traits by index (0-13).
+func WithoutLRTs(lrtIndices ...int) Option {
+	return func(r *Race) { r.LRT &^= LRTs(lrtIndices...) }
+}
+
+// --- Habitability ---
+
+// WithGravity sets the ideal gravity (0-100) and its tolerance half-range (0-50).
+func WithGravity(center, width int) Option {
+	return func(r *Race) {
+		r.GravityCenter = center
+		r.GravityWidth = width
+		r.GravityImmune = false
+	}
+}
+
+// WithGravityImmune makes the race immune to gravity.
+func WithGravityImmune() Option {
+	return func(r *Race) { r.GravityImmune = true }
+}
+
+// WithTemperature sets the ideal temperature (0-100) and its tolerance half-range (0-50).
+func WithTemperature(center, width int) Option {
+	return func(r *Race) {
+		r.TemperatureCenter = center
+		r.TemperatureWidth = width
+		r.TemperatureImmune = false
+	}
+}
+
+// WithTemperatureImmune makes the race immune to temperature.
+func WithTemperatureImmune() Option {
+	return func(r *Race) { r.TemperatureImmune = true }
+}
+
+// WithRadiation sets the ideal radiation (0-100) and its tolerance half-range (0-50).
+func WithRadiation(center, width int) Option {
+	return func(r *Race) {
+		r.RadiationCenter = center
+		r.RadiationWidth = width
+		r.RadiationImmune = false
+	}
+}
+
+// WithRadiationImmune makes the race immune to radiation.
+func WithRadiationImmune() Option {
+	return func(r *Race) { r.RadiationImmune = true }
+}
+
+// --- Growth and economy ---
+
+// WithGrowthRate sets the maximum colonist growth rate per year (1-20%).
+func WithGrowthRate(rate int) Option {
+	return func(r *Race) { r.GrowthRate = rate }
+}
+
+// WithColonistsPerResource sets how many colonists generate one resource (700-2500).
+func WithColonistsPerResource(cpr int) Option {
+	return func(r *Race) { r.ColonistsPerResource = cpr }
+}
+
+// WithFactoryOutput sets resources produced per 10 factories (5-15).
+func WithFactoryOutput(output int) Option {
+	return func(r *Race) { r.FactoryOutput = output }
+}
+
+// WithFactoryCost sets resources required to build one factory (5-25).
+func WithFactoryCost(cost int) Option {
+	return func(r *Race) { r.FactoryCost = cost }
+}
+
+// WithFactoryCount sets max factories per 10,000 colonists (5-25).
+func WithFactoryCount(count int) Option {
+	return func(r *Race) { r.FactoryCount = count }
+}
+
+// WithCheapGermaniumFactories makes factories cost 1kT less Germanium.
+func WithCheapGermaniumFactories() Option {
+	return func(r *Race) { r.FactoriesUseLessGerm = true }
+}
+
+// WithMineOutput sets kT of each mineral produced per 10 mines (5-25).
+func WithMineOutput(output int) Option {
+	return func(r *Race) { r.MineOutput = output }
+}
+
+// WithMineCost sets resources required to build one mine (2-15).
+func WithMineCost(cost int) Option {
+	return func(r *Race) { r.MineCost = cost }
+}
+
+// WithMineCount sets max mines per 10,000 colonists (5-25).
+func WithMineCount(count int) Option {
+	return func(r *Race) { r.MineCount = count }
+}
+
+// --- Research ---
+
+// WithEnergyResearch sets the Energy research cost level.
+// Use ResearchCostExtra, ResearchCostStandard or ResearchCostLess.
+func WithEnergyResearch(cost int) Option {
+	return func(r *Race) { r.ResearchEnergy = cost }
+}
+
+// WithWeaponsResearch sets the Weapons research cost level.
+func WithWeaponsResearch(cost int) Option {
+	return func(r *Race) { r.ResearchWeapons = cost }
+}
+
+// WithPropulsionResearch sets the Propulsion research cost level.
+func WithPropulsionResearch(cost int) Option {
+	return func(r *Race) { r.ResearchPropulsion = cost }
+}
+
+// WithConstructionResearch sets the Construction research cost level.
+func WithConstructionResearch(cost int) Option {
+	return func(r *Race) { r.ResearchConstruction = cost }
+}
+
+// WithElectronicsResearch sets the Electronics research cost level.
+func WithElectronicsResearch(cost int) Option {
+	return func(r *Race) { r.ResearchElectronics = cost }
+}
+
+// WithBiotechResearch sets the Biotechnology research cost level.
+func WithBiotechResearch(cost int) Option {
+	return func(r *Race) { r.ResearchBiotech = cost }
+}
+
+// WithTechsStartHigh makes the "Costs 75% extra" research fields start at Tech 4.
+func WithTechsStartHigh() Option {
+	return func(r *Race) { r.TechsStartHigh = true }
+}
+
+// WithLeftoverPointsOn sets where to spend leftover advantage points.
+func WithLeftoverPointsOn(option LeftoverPointsOption) Option {
+	return func(r *Race) { r.LeftoverPointsOn = option }
+}
@@ -0,0 +1,99 @@
+package race
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/crypto"
+	"github.com/neper-stars/houston/encoding"
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/password"
+)
+
+var (
+	ErrNotRaceFile   = errors.New("not a race file")
+	ErrNoPlayerBlock = errors.New("no player block found in race file")
+	ErrNoFooter      = errors.New("no footer found in race file")
+)
+
+// SetPassword returns a copy of race file data protected by a password,
+// replacing the password it had. An empty password removes it.
+//
+// Stars! only stores a hash of the password in the player block, at bytes
+// 12-15 of its decrypted data. The player block is encrypted again and the
+// footer checksum, which covers the hash, is recomputed so the file still
+// opens in Stars!. Other files, such as turn files, which have player
+// blocks too, are rejected with ErrNotRaceFile.
+func SetPassword(data []byte, pass string) ([]byte, error) {
+	blockList, err := parser.FileData(data).BlockList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse blocks: %w", err)
+	}
+
+	var header *blocks.FileHeader
+	var player *blocks.PlayerBlock
+	for _, block := range blockList {
+		switch b := block.(type) {
+		case blocks.FileHeader:
+			header = &b
+		case *blocks.FileHeader:
+			header = b
+		case blocks.PlayerBlock:
+			player = &b
+		case *blocks.PlayerBlock:
+			player = b
+		}
+	}
+	if header == nil {
+		return nil, parser.ErrNoFileHeaderFound
+	}
+	if header.FileType != blocks.FileTypeRace || header.PlayerIndex() != blocks.RaceFilePlayerIndex {
+		return nil, fmt.Errorf("%w: %s file of player %d", ErrNotRaceFile, header.FileTypeName(), header.PlayerIndex()+1)
+	}
+	if player == nil || len(player.DecryptedData()) < 16 {
+		return nil, ErrNoPlayerBlock
+	}
+
+	decrypted := make([]byte, len(player.DecryptedData()))
+	copy(decrypted, player.DecryptedData())
+	var hash uint32
+	if pass != "" {
+		hash = password.HashRacePassword(pass)
+	}
+	encoding.Write32(decrypted, 12, hash)
+
+	encryptor := crypto.NewEncryptor()
+	encryptor.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn),
+		header.PlayerIndex(), header.SharewareFlag())
+	encrypted := encryptor.EncryptBytes(decrypted)
+	footer := blocks.ComputeRaceFooter(decrypted, player.NameSingular, player.NamePlural)
+
+	out := make([]byte, len(data))
+	copy(out, data)
+	var playerDone, footerDone bool
+	for offset := 0; offset+2 <= len(out); {
+		blockHeader := encoding.Read16(out, offset)
+		blockType := blocks.BlockTypeID(blockHeader >> 10)
+		size := int(blockHeader & 0x3FF)
+		if offset+2+size > len(out) {
+			break
+		}
+		switch {
+		case blockType == blocks.PlayerBlockType && !playerDone:
+			copy(out[offset+2:offset+2+size], encrypted)
+			playerDone = true
+		case blockType == blocks.FileFooterBlockType && size == 2:
+			encoding.Write16(out, offset+2, footer)
+			footerDone = true
+		}
+		offset += 2 + size
+	}
+	if !playerDone {
+		return nil, ErrNoPlayerBlock
+	}
+	if !footerDone {
+		return nil, ErrNoFooter
+	}
+	return out, nil
+}
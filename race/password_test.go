@@ -0,0 +1,108 @@
+package race
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/encoding"
+	"github.com/neper-stars/houston/parser"
+	"github.com/neper-stars/houston/password"
+)
+
+func readRaceFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("../testdata/scenario-racefiles/" + name)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", name, err)
+	}
+	return data
+}
+
+func playerBlockOf(t *testing.T, data []byte) *blocks.PlayerBlock {
+	t.Helper()
+	blockList, err := parser.FileData(data).BlockList()
+	if err != nil {
+		t.Fatalf("failed to parse blocks: %v", err)
+	}
+	for _, block := range blockList {
+		switch b := block.(type) {
+		case blocks.PlayerBlock:
+			return &b
+		case *blocks.PlayerBlock:
+			return b
+		}
+	}
+	t.Fatal("no player block")
+	return nil
+}
+
+// checkFooter checks the footer checksum, the last block of a race file.
+func checkFooter(t *testing.T, data []byte) {
+	t.Helper()
+	pb := playerBlockOf(t, data)
+	want := blocks.ComputeRaceFooter(pb.DecryptedData(), pb.NameSingular, pb.NamePlural)
+	if got := encoding.Read16(data, len(data)-2); got != want {
+		t.Errorf("expected footer 0x%04X, got 0x%04X", want, got)
+	}
+}
+
+func TestSetPassword(t *testing.T) {
+	noPass := readRaceFile(t, "race1-nopassword.r2")
+	withPass := readRaceFile(t, "race1-password.r2")
+
+	out, err := SetPassword(noPass, "race1")
+	if err != nil {
+		t.Fatalf("SetPassword failed: %v", err)
+	}
+	pb := playerBlockOf(t, out)
+	if got := pb.HashedPass().Uint32(); got != password.HashRacePassword("race1") {
+		t.Errorf("expected the hash of race1, got 0x%08X", got)
+	}
+	if !bytes.Equal(pb.DecryptedData(), playerBlockOf(t, withPass).DecryptedData()) {
+		t.Error("expected the race Stars! saved with the password race1")
+	}
+	checkFooter(t, out)
+	if !bytes.Equal(noPass, readRaceFile(t, "race1-nopassword.r2")) {
+		t.Error("SetPassword must not modify its input")
+	}
+}
+
+func TestSetPassword_Remove(t *testing.T) {
+	out, err := SetPassword(readRaceFile(t, "race1-password.r2"), "")
+	if err != nil {
+		t.Fatalf("SetPassword failed: %v", err)
+	}
+	pb := playerBlockOf(t, out)
+	if pb.HasPassword() {
+		t.Error("expected the password to be removed")
+	}
+	if !bytes.Equal(pb.DecryptedData(), playerBlockOf(t, readRaceFile(t, "race1-nopassword.r2")).DecryptedData()) {
+		t.Error("expected the race Stars! saved without password")
+	}
+	checkFooter(t, out)
+}
+
+func TestSetPassword_Invalid(t *testing.T) {
+	if _, err := SetPassword([]byte{1, 2, 3}, "secret"); err == nil {
+		t.Error("expected an error for data that is not a race file")
+	}
+}
+
+func TestSetPassword_NotRaceFile(t *testing.T) {
+	// Turn and host files have player blocks holding a password hash too
+	for _, name := range []string{
+		"../testdata/scenario-singleplayer/2499/Game.m1",
+		"../testdata/scenario-singleplayer/2499/Game.hst",
+	} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if _, err := SetPassword(data, "secret"); !errors.Is(err, ErrNotRaceFile) {
+			t.Errorf("%s: expected ErrNotRaceFile, got %v", name, err)
+		}
+	}
+}
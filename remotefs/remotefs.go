@@ -0,0 +1,200 @@
+// Package remotefs reads and writes Stars! files kept in cloud folders.
+//
+// Many play-by-email games exchange turns through a shared Dropbox, Google
+// Drive or OneDrive folder. Rather than linking each provider's SDK, remotefs
+// drives rclone (https://rclone.org), which supports them all: a path such as
+// "gdrive:stars/game.m1" names the file stars/game.m1 of the rclone remote
+// "gdrive", set up once with "rclone config".
+//
+//	fsys := remotefs.FS{Remote: remotefs.NewRclone()}
+//	data, err := fsys.ReadFile("gdrive:stars/game.m1")
+//
+// FS reads local paths from the local file system, so the same code handles
+// both; it implements store.FileSystem.
+package remotefs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ErrNoRemote is returned by FS for remote paths when it has no Remote.
+var ErrNoRemote = errors.New("remote paths are not supported")
+
+// IsRemote returns true if the path names a file of an rclone remote
+// ("remote:path"). Windows drive letters ("C:\games") are not remotes.
+func IsRemote(path string) bool {
+	i := strings.IndexByte(path, ':')
+	if i < 2 {
+		return false
+	}
+	for _, c := range path[:i] {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '_', c == '-', c == '.', c == ' ':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Rclone accesses remote files with the rclone command.
+type Rclone struct {
+	// Binary is the rclone command, "rclone" (found in PATH) by default.
+	Binary string
+
+	// Args are passed to every rclone call, such as "--config" and its path.
+	Args []string
+
+	// run runs rclone with the arguments, feeding it stdin, and returns its
+	// output; replaced in tests.
+	run func(stdin []byte, args ...string) ([]byte, error)
+}
+
+// NewRclone returns an Rclone using the rclone command found in PATH.
+func NewRclone() *Rclone {
+	return &Rclone{Binary: "rclone"}
+}
+
+func (r *Rclone) exec(stdin []byte, args ...string) ([]byte, error) {
+	args = append(append([]string(nil), r.Args...), args...)
+	if r.run != nil {
+		return r.run(stdin, args...)
+	}
+
+	binary := r.Binary
+	if binary == "" {
+		binary = "rclone"
+	}
+	cmd := exec.Command(binary, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("rclone %s: %w: %s", args[len(r.Args)], err, msg)
+		}
+		return nil, fmt.Errorf("rclone %s: %w", args[len(r.Args)], err)
+	}
+	return out, nil
+}
+
+// ReadFile returns the content of a remote file.
+func (r *Rclone) ReadFile(path string) ([]byte, error) {
+	return r.exec(nil, "cat", path)
+}
+
+// WriteFile replaces the content of a remote file.
+func (r *Rclone) WriteFile(path string, data []byte) error {
+	if data == nil {
+		data = []byte{}
+	}
+	_, err := r.exec(data, "rcat", path)
+	return err
+}
+
+// Stat returns true if the remote file exists.
+func (r *Rclone) Stat(path string) (bool, error) {
+	if _, err := r.exec(nil, "lsjson", "--stat", "--no-modtime", path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ReadDir returns the names of the files in a remote directory, sorted.
+func (r *Rclone) ReadDir(dir string) ([]string, error) {
+	out, err := r.exec(nil, "lsf", "--files-only", dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			names = append(names, line)
+		}
+	}
+	return names, nil
+}
+
+// FS reads and writes local paths on the local file system and remote paths
+// (see IsRemote) with Remote.
+type FS struct {
+	Remote *Rclone
+}
+
+func (fs FS) remote(path string) (*Rclone, error) {
+	if fs.Remote == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoRemote, path)
+	}
+	return fs.Remote, nil
+}
+
+// ReadFile returns the content of a file.
+func (fs FS) ReadFile(path string) ([]byte, error) {
+	if !IsRemote(path) {
+		return os.ReadFile(path)
+	}
+	r, err := fs.remote(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReadFile(path)
+}
+
+// WriteFile replaces the content of a file; local files are created with
+// mode 0644.
+func (fs FS) WriteFile(path string, data []byte) error {
+	if !IsRemote(path) {
+		return os.WriteFile(path, data, 0644)
+	}
+	r, err := fs.remote(path)
+	if err != nil {
+		return err
+	}
+	return r.WriteFile(path, data)
+}
+
+// Stat returns true if the file exists.
+func (fs FS) Stat(path string) (bool, error) {
+	if !IsRemote(path) {
+		if _, err := os.Stat(path); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	r, err := fs.remote(path)
+	if err != nil {
+		return false, err
+	}
+	return r.Stat(path)
+}
+
+// ReadDir returns the names of the files in a directory, sorted.
+func (fs FS) ReadDir(dir string) ([]string, error) {
+	if !IsRemote(dir) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+		return names, nil
+	}
+	r, err := fs.remote(dir)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReadDir(dir)
+}
@@ -0,0 +1,86 @@
+package remotefs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRemote(t *testing.T) {
+	assert.True(t, IsRemote("gdrive:stars/game.m1"))
+	assert.True(t, IsRemote("my-dropbox:game.xy"))
+	assert.False(t, IsRemote("game.m1"))
+	assert.False(t, IsRemote("/games/stars/game.m1"))
+	assert.False(t, IsRemote(`C:\games\game.m1`))
+	assert.False(t, IsRemote("turns/2401:game.m1"))
+}
+
+// fakeRclone records rclone calls and serves files from memory.
+func fakeRclone(files map[string][]byte) (*Rclone, *[]string) {
+	var calls []string
+	r := &Rclone{Args: []string{"--config", "test.conf"}}
+	r.run = func(stdin []byte, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		args = args[2:]
+		path := args[len(args)-1]
+		switch args[0] {
+		case "cat", "lsjson":
+			data, ok := files[path]
+			if !ok {
+				return nil, errors.New("object not found")
+			}
+			return data, nil
+		case "rcat":
+			files[path] = stdin
+			return nil, nil
+		case "lsf":
+			return []byte("game.m1\ngame.xy\n"), nil
+		}
+		return nil, errors.New("unexpected command")
+	}
+	return r, &calls
+}
+
+func TestFS_Remote(t *testing.T) {
+	files := map[string][]byte{"gdrive:stars/game.m1": []byte("turn")}
+	r, calls := fakeRclone(files)
+	fsys := FS{Remote: r}
+
+	data, err := fsys.ReadFile("gdrive:stars/game.m1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("turn"), data)
+
+	require.NoError(t, fsys.WriteFile("gdrive:stars/game.x1", []byte("orders")))
+	assert.Equal(t, []byte("orders"), files["gdrive:stars/game.x1"])
+
+	ok, _ := fsys.Stat("gdrive:stars/game.xy")
+	assert.False(t, ok)
+
+	names, err := fsys.ReadDir("gdrive:stars")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"game.m1", "game.xy"}, names)
+
+	assert.Equal(t, "--config test.conf cat gdrive:stars/game.m1", (*calls)[0])
+}
+
+func TestFS_Local(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.m1")
+	fsys := FS{}
+
+	require.NoError(t, fsys.WriteFile(path, []byte("turn")))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("turn"), data)
+
+	ok, err := fsys.Stat(path)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = fsys.ReadFile("gdrive:stars/game.m1")
+	assert.ErrorIs(t, err, ErrNoRemote)
+}
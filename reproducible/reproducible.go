@@ -0,0 +1,56 @@
+// Package reproducible switches the writers of the library to a mode where
+// identical inputs give byte-identical outputs.
+//
+// By default, new race files get a random encryption salt and reports are
+// stamped with the current time. Once Enable is called, salts are fixed and
+// timestamps come from the SOURCE_DATE_EPOCH environment variable (see
+// https://reproducible-builds.org/specs/source-date-epoch/), or the Unix
+// epoch when it is unset. This is meant for golden tests and for hosts
+// regenerating files to check them against the ones they received.
+package reproducible
+
+import (
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+var enabled atomic.Bool
+
+// Enable turns the reproducible mode on for the whole process.
+func Enable() {
+	enabled.Store(true)
+}
+
+// Disable turns the reproducible mode off.
+func Disable() {
+	enabled.Store(false)
+}
+
+// Enabled returns true in reproducible mode.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Now returns the current time in UTC, or the fixed time of the
+// reproducible mode.
+func Now() time.Time {
+	if !Enabled() {
+		return time.Now().UTC()
+	}
+	if epoch, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+		return time.Unix(epoch, 0).UTC()
+	}
+	return time.Unix(0, 0).UTC()
+}
+
+// Salt returns a random value in [0, n), such as an encryption salt, or 0 in
+// reproducible mode.
+func Salt(n int) int {
+	if Enabled() {
+		return 0
+	}
+	return rand.IntN(n)
+}
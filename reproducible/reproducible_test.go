@@ -0,0 +1,24 @@
+package reproducible
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReproducible(t *testing.T) {
+	t.Cleanup(Disable)
+
+	assert.False(t, Enabled())
+	assert.WithinDuration(t, time.Now(), Now(), time.Minute)
+
+	Enable()
+	assert.True(t, Enabled())
+	assert.Zero(t, Salt(2048))
+
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	assert.Equal(t, time.Unix(0, 0).UTC(), Now())
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), Now())
+}
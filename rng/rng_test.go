@@ -0,0 +1,60 @@
+package rng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reference computes the sequence with plain 64-bit modular arithmetic
+// instead of the Schrage decomposition used by Source.
+func reference(seedA, seedB int64, n int) []uint32 {
+	values := make([]uint32, n)
+	for i := range values {
+		seedA = seedA * MultiplierA % ModulusA
+		seedB = seedB * MultiplierB % ModulusB
+		values[i] = uint32(seedA - seedB)
+	}
+	return values
+}
+
+func TestSource_MatchesReference(t *testing.T) {
+	seeds := [][2]int{
+		{3, 279},                     // primes used by the file encryption
+		{1, 1},                       // smallest seeds
+		{ModulusA - 1, ModulusB - 1}, // largest seeds
+		{123456789, 987654321},
+	}
+	for _, seed := range seeds {
+		r := New(seed[0], seed[1])
+		for i, want := range reference(int64(seed[0]), int64(seed[1]), 1000) {
+			require.Equal(t, want, r.Next(), "seeds %v, value %d", seed, i)
+		}
+	}
+}
+
+func TestSource_StateRestore(t *testing.T) {
+	r := New(17, 31)
+	r.Skip(5)
+	assert.Equal(t, 5, r.Drawn())
+
+	saved := r.State()
+	first := []uint32{r.Next(), r.Next(), r.Next()}
+
+	r.Restore(saved)
+	assert.Equal(t, 5, r.Drawn())
+	assert.Equal(t, first, []uint32{r.Next(), r.Next(), r.Next()})
+}
+
+func TestSource_Intn(t *testing.T) {
+	r := New(17, 31)
+	raw := New(17, 31)
+	for i := 0; i < 1000; i++ {
+		v := r.Intn(6)
+		assert.GreaterOrEqual(t, v, 0)
+		assert.Less(t, v, 6)
+		assert.Equal(t, int(raw.Next()%6), v)
+	}
+	assert.Panics(t, func() { r.Intn(0) })
+}
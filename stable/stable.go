@@ -0,0 +1,279 @@
+// Package stable is the stable API of the library, for programs that only
+// need to read games: who plays, which planets they hold and where their
+// fleets are.
+//
+//	import "github.com/neper-stars/houston/stable"
+//
+//	game, err := stable.Open("game.xy", "game.m1")
+//	for _, planet := range game.PlanetsOf(0) {
+//		fmt.Println(planet.Name, planet.Population)
+//	}
+//
+// The types of this package are plain values copied out of the lower-level
+// blocks and store packages. Those packages follow the file format and change
+// whenever a block is better understood; the names and signatures here only
+// ever grow. Programs needing more than this package offers can reach the
+// underlying store through Game.Store, without the same guarantee.
+package stable
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/store"
+)
+
+// ErrNoFiles is returned by Open when no file is given.
+var ErrNoFiles = errors.New("no game files")
+
+// Game is the state of a game as seen through its files.
+type Game struct {
+	gs *store.GameStore
+}
+
+// Open reads game files (XY, M, H, X or HST) of a single game. The XY file
+// of an M or H file is read as well when it sits next to it.
+func Open(paths ...string) (*Game, error) {
+	if len(paths) == 0 {
+		return nil, ErrNoFiles
+	}
+	gs := store.New()
+	for _, path := range paths {
+		if err := gs.AddFileWithXY(path); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+	return &Game{gs: gs}, nil
+}
+
+// Read adds a game file read from r; name is only used for its extension
+// and in error messages.
+func (g *Game) Read(name string, r io.Reader) error {
+	if err := g.gs.AddFileReader(name, r); err != nil {
+		return fmt.Errorf("reading %s: %w", name, err)
+	}
+	return nil
+}
+
+// New returns a game with no file read yet, to be filled with Read.
+func New() *Game {
+	return &Game{gs: store.New()}
+}
+
+// Store returns the store behind the game. Its API follows the file format
+// and is not covered by the stability promise of this package.
+func (g *Game) Store() *store.GameStore {
+	return g.gs
+}
+
+// ID returns the game ID shared by all files of the game.
+func (g *Game) ID() uint32 {
+	return g.gs.GameID
+}
+
+// Name returns the name of the game, if the files carry it.
+func (g *Game) Name() string {
+	return g.gs.GameName
+}
+
+// Turn returns the turn of the files, starting from 0.
+func (g *Game) Turn() int {
+	return int(g.gs.Turn)
+}
+
+// Year returns the game year of the files, starting from 2400.
+func (g *Game) Year() int {
+	return 2400 + int(g.gs.Turn)
+}
+
+// Tech holds the six research fields.
+type Tech struct {
+	Energy, Weapons, Propulsion, Construction, Electronics, Biotechnology int
+}
+
+// Player is a race playing the game.
+type Player struct {
+	Number   int // 0-based; Stars! shows Number+1
+	Name     string
+	Singular string
+	PRT      string // Primary racial trait code: "HE", "SS", ...
+	Tech     Tech
+	Score    int // Score from the score screen, or 0 if unknown
+	Rank     int // 1 for the leader, 0 if unknown
+}
+
+// Players returns the players of the game, sorted by number.
+func (g *Game) Players() []Player {
+	all := g.gs.AllPlayers()
+	players := make([]Player, 0, len(all))
+	for _, p := range all {
+		players = append(players, g.player(p))
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].Number < players[j].Number })
+	return players
+}
+
+// Player returns the player of the given 0-based number.
+func (g *Game) Player(number int) (Player, bool) {
+	p, ok := g.gs.Player(number)
+	if !ok {
+		return Player{}, false
+	}
+	return g.player(p), true
+}
+
+func (g *Game) player(p *store.PlayerEntity) Player {
+	player := Player{
+		Number:   p.PlayerNumber,
+		Name:     p.NamePlural,
+		Singular: p.NameSingular,
+		Tech: Tech{
+			Energy:        p.Tech.Energy,
+			Weapons:       p.Tech.Weapons,
+			Propulsion:    p.Tech.Propulsion,
+			Construction:  p.Tech.Construction,
+			Electronics:   p.Tech.Electronics,
+			Biotechnology: p.Tech.Biotech,
+		},
+		Rank: p.Rank,
+	}
+	if prt := data.GetPRT(p.PRT); prt != nil {
+		player.PRT = prt.Code
+	}
+	if score := g.gs.PlayerScore(p.PlayerNumber); score != nil {
+		player.Score = score.Score
+		player.Rank = score.Rank
+	}
+	return player
+}
+
+// Minerals holds amounts of the three minerals, in kT.
+type Minerals struct {
+	Ironium, Boranium, Germanium int64
+}
+
+// Planet is a planet as last seen by the owner of the files.
+type Planet struct {
+	Number    int
+	Name      string
+	X, Y      int
+	Owner     int // Player number, -1 when unowned or unknown
+	Homeworld bool
+	Starbase  bool
+
+	Population int64 // Colonists
+	Mines      int
+	Factories  int
+	Defenses   int
+	Minerals   Minerals // On the surface
+}
+
+// Planets returns the planets of the game, sorted by number.
+func (g *Game) Planets() []Planet {
+	return g.planets(g.gs.AllPlanets())
+}
+
+// PlanetsOf returns the planets owned by a player, sorted by number.
+func (g *Game) PlanetsOf(player int) []Planet {
+	return g.planets(g.gs.PlanetsByOwner(player))
+}
+
+// Planet returns the planet of the given number.
+func (g *Game) Planet(number int) (Planet, bool) {
+	p, ok := g.gs.Planet(number)
+	if !ok {
+		return Planet{}, false
+	}
+	return g.planet(p), true
+}
+
+func (g *Game) planets(all []*store.PlanetEntity) []Planet {
+	planets := make([]Planet, 0, len(all))
+	for _, p := range all {
+		planets = append(planets, g.planet(p))
+	}
+	sort.Slice(planets, func(i, j int) bool { return planets[i].Number < planets[j].Number })
+	return planets
+}
+
+func (g *Game) planet(p *store.PlanetEntity) Planet {
+	return Planet{
+		Number:     p.PlanetNumber,
+		Name:       g.gs.PlanetName(p.PlanetNumber),
+		X:          p.X,
+		Y:          p.Y,
+		Owner:      p.Owner,
+		Homeworld:  p.IsHomeworld,
+		Starbase:   p.HasStarbase,
+		Population: p.Population,
+		Mines:      p.Mines,
+		Factories:  p.Factories,
+		Defenses:   p.Defenses,
+		Minerals:   Minerals{Ironium: p.Ironium, Boranium: p.Boranium, Germanium: p.Germanium},
+	}
+}
+
+// Fleet is a fleet as last seen by the owner of the files.
+type Fleet struct {
+	Number    int // 0-based; Stars! shows Number+1
+	Owner     int // Player number
+	Name      string
+	X, Y      int
+	Ships     int
+	Warp      int
+	Cargo     Minerals
+	Colonists int64
+	Fuel      int64 // mg
+}
+
+// Fleets returns the fleets of the game, sorted by owner and number.
+func (g *Game) Fleets() []Fleet {
+	return fleets(g.gs.AllFleets())
+}
+
+// FleetsOf returns the fleets of a player, sorted by number.
+func (g *Game) FleetsOf(player int) []Fleet {
+	return fleets(g.gs.FleetsByOwner(player))
+}
+
+// Fleet returns a fleet of a player by its 0-based number.
+func (g *Game) Fleet(owner, number int) (Fleet, bool) {
+	f, ok := g.gs.Fleet(owner, number)
+	if !ok {
+		return Fleet{}, false
+	}
+	return fleet(f), true
+}
+
+func fleets(all []*store.FleetEntity) []Fleet {
+	fleets := make([]Fleet, 0, len(all))
+	for _, f := range all {
+		fleets = append(fleets, fleet(f))
+	}
+	sort.Slice(fleets, func(i, j int) bool {
+		if fleets[i].Owner != fleets[j].Owner {
+			return fleets[i].Owner < fleets[j].Owner
+		}
+		return fleets[i].Number < fleets[j].Number
+	})
+	return fleets
+}
+
+func fleet(f *store.FleetEntity) Fleet {
+	cargo := f.GetCargo()
+	return Fleet{
+		Number:    f.FleetNumber,
+		Owner:     f.Owner,
+		Name:      f.Name(),
+		X:         f.X,
+		Y:         f.Y,
+		Ships:     f.TotalShips(),
+		Warp:      f.Warp,
+		Cargo:     Minerals{Ironium: cargo.Ironium, Boranium: cargo.Boranium, Germanium: cargo.Germanium},
+		Colonists: cargo.Population,
+		Fuel:      cargo.Fuel,
+	}
+}
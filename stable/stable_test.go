@@ -0,0 +1,67 @@
+package stable_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/stable"
+)
+
+const side3 = "../testdata/scenario-diplomacy-3way/1/side3/"
+
+func TestOpen(t *testing.T) {
+	_, err := stable.Open()
+	assert.ErrorIs(t, err, stable.ErrNoFiles)
+	_, err = stable.Open(side3 + "missing.m3")
+	assert.Error(t, err)
+
+	// The XY file next to the M file is read too
+	game, err := stable.Open(side3 + "game.m3")
+	require.NoError(t, err)
+	assert.NotZero(t, game.ID())
+	assert.Equal(t, 2400+game.Turn(), game.Year())
+
+	player, ok := game.Player(2)
+	require.True(t, ok)
+	assert.Equal(t, 2, player.Number)
+	assert.Equal(t, "IT", player.PRT)
+	assert.NotEmpty(t, player.Name)
+
+	players := game.Players()
+	require.NotEmpty(t, players)
+	for i := 1; i < len(players); i++ {
+		assert.Less(t, players[i-1].Number, players[i].Number)
+	}
+
+	home, ok := game.Planet(9)
+	require.True(t, ok)
+	assert.Equal(t, 2, home.Owner)
+	assert.True(t, home.Starbase)
+	assert.NotEmpty(t, home.Name)
+	assert.Positive(t, home.Population)
+	assert.Contains(t, game.PlanetsOf(2), home)
+
+	fleets := game.FleetsOf(2)
+	require.NotEmpty(t, fleets)
+	for _, f := range fleets {
+		assert.Equal(t, 2, f.Owner)
+		assert.Positive(t, f.Ships)
+		got, ok := game.Fleet(f.Owner, f.Number)
+		require.True(t, ok)
+		assert.Equal(t, f, got)
+	}
+}
+
+func TestRead(t *testing.T) {
+	game := stable.New()
+	for _, name := range []string{"game.xy", "game.m3"} {
+		f, err := os.Open(side3 + name)
+		require.NoError(t, err)
+		require.NoError(t, game.Read(name, f))
+		require.NoError(t, f.Close())
+	}
+	assert.Len(t, game.Planets(), int(game.Store().PlanetCount))
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"math"
+
+	"github.com/neper-stars/houston/blocks"
+)
+
+// This file gathers the special rules of Alternate Reality (AR) races, which
+// live in their orbital starbases rather than on planets:
+//
+//   - Colonists only live on planets holding one of the race's starbases,
+//     whose hull sets the planet's capacity.
+//   - Resources come from the population and the Energy tech level; AR races
+//     build no factories, mines or planetary defenses.
+//   - Destroying the starbase of a planet kills its whole population.
+//   - Remote miners may mine the race's own inhabited planets.
+//
+// The population, resource and score calculations ask these functions
+// instead of testing the PRT themselves.
+
+// IsAlternateReality returns true if the player's race is Alternate Reality.
+func (p *PlayerEntity) IsAlternateReality() bool {
+	return p.PRT == blocks.PRTAlternateReality
+}
+
+// BuildsInstallations returns true if the player's planets can hold
+// factories, mines and planetary defenses, which AR races never build.
+func (p *PlayerEntity) BuildsInstallations() bool {
+	return !p.IsAlternateReality()
+}
+
+// arMaxPopulation returns the population, in colonists, an AR race can hold
+// at a planet: none without one of its starbases in orbit.
+func (gs *GameStore) arMaxPopulation(planet *PlanetEntity, player *PlayerEntity) int {
+	if planet.Owner != player.PlayerNumber || !planet.HasStarbase {
+		return 0
+	}
+	// Max pop = starbase hull capacity × 4 (in file units)
+	// We need the starbase design to get hull capacity
+	if design, ok := gs.StarbaseDesign(player.PlayerNumber, planet.StarbaseDesign); ok {
+		if hull := design.Hull(); hull != nil {
+			// Hull BaseCapacity is stored with an offset of 0x20 in the original
+			// The formula is: (hull.baseCapacity - 0x20) * 4
+			// For simplicity, we use the hull's cargo capacity as a proxy
+			// Note: This may need adjustment based on actual hull data structure
+			// Multiply by 100 to convert from file units to actual colonists
+			return hull.CargoCapacity * 4 * 100
+		}
+	}
+	return 0
+}
+
+// arResources returns the resources of an AR planet:
+// floor(sqrt((energyTech × population) / popEfficiency)), the population in
+// file units (100s of colonists).
+func arResources(player *PlayerEntity, effectivePop, popEfficiency int) int {
+	energyTech := player.Tech.Energy
+	if energyTech < 1 {
+		energyTech = 1
+	}
+	if popEfficiency <= 0 {
+		return 0
+	}
+	return int(math.Sqrt(float64(energyTech) * float64(effectivePop) / float64(popEfficiency)))
+}
+
+// PopulationLostWithStarbase returns the colonists killed if the starbase
+// orbiting the planet is destroyed: all of them when an AR race owns the
+// planet, none otherwise.
+func (gs *GameStore) PopulationLostWithStarbase(planet *PlanetEntity) int64 {
+	if !planet.IsOwned() || !planet.HasStarbase {
+		return 0
+	}
+	if owner, ok := gs.Player(planet.Owner); ok && owner.IsAlternateReality() {
+		return planet.Population
+	}
+	return 0
+}
+
+// CanRemoteMine returns true if the player's remote miners can mine the
+// planet: any uninhabited planet, and for AR races their own planets too.
+func (gs *GameStore) CanRemoteMine(planet *PlanetEntity, player *PlayerEntity) bool {
+	if !planet.IsOwned() {
+		return true
+	}
+	return planet.Owner == player.PlayerNumber && player.IsAlternateReality()
+}
@@ -0,0 +1,52 @@
+package store_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+func TestAlternateReality(t *testing.T) {
+	// Player 2 of this game is an AR race living on its homeworld starbase
+	gs := store.New()
+	for _, name := range []string{"game-2401.xy", "game-2401.m2"} {
+		data, err := os.ReadFile("../testdata/scenario-cloaking-visibility/game01/historic-backup/" + name)
+		require.NoError(t, err)
+		require.NoError(t, gs.AddFile(name, data))
+	}
+
+	ar, ok := gs.Player(1)
+	require.True(t, ok)
+	require.True(t, ar.IsAlternateReality())
+	assert.False(t, ar.BuildsInstallations())
+
+	planets := gs.PlanetsByOwner(1)
+	require.Len(t, planets, 1)
+	home := planets[0]
+	require.True(t, home.HasStarbase)
+
+	assert.Zero(t, gs.MaxFactories(home, ar))
+	assert.Zero(t, home.MaxMines(gs, ar))
+	assert.Zero(t, gs.MaxDefenses(home, ar))
+	assert.Positive(t, gs.MaxPopulation(home, ar))
+	assert.Positive(t, gs.CResourcesAtPlanet(home, ar))
+	assert.Equal(t, home.Population, gs.PopulationLostWithStarbase(home), "the colonists live in the starbase")
+
+	assert.True(t, gs.CanRemoteMine(home, ar), "AR races remote mine their own planets")
+	other := &store.PlayerEntity{PlayerNumber: 1, PRT: blocks.PRTJackOfAllTrades}
+	assert.False(t, other.IsAlternateReality())
+	assert.True(t, other.BuildsInstallations())
+	assert.False(t, gs.CanRemoteMine(home, other))
+	assert.True(t, gs.CanRemoteMine(&store.PlanetEntity{Owner: -1}, other))
+
+	// Without its starbase, the planet holds no AR colonists
+	bare := *home
+	bare.HasStarbase = false
+	assert.Zero(t, gs.MaxPopulation(&bare, ar))
+	assert.Zero(t, gs.PopulationLostWithStarbase(&bare))
+}
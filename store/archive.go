@@ -0,0 +1,87 @@
+package store
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+
+	"github.com/neper-stars/houston/filenames"
+)
+
+// MaxArchiveMember is the largest file read from an archive. Stars! files
+// are at most a few hundred KB; larger members are rejected rather than
+// decompressed in memory.
+const MaxArchiveMember = 16 << 20
+
+// ErrArchiveMemberTooLarge is returned for archive members over MaxArchiveMember.
+var ErrArchiveMemberTooLarge = errors.New("archive member too large")
+
+// ArchiveFile is a Stars! file read from an archive.
+type ArchiveFile struct {
+	Name string // Path inside the archive
+	Data []byte
+}
+
+// ReadZip returns the Stars! game files of a ZIP archive: universe files
+// first, then the others by name. Race files and other members are skipped.
+func ReadZip(data []byte) ([]ArchiveFile, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ZIP archive: %w", err)
+	}
+
+	var files []ArchiveFile
+	for _, member := range zr.File {
+		kind := filenames.KindOf(member.Name)
+		if member.FileInfo().IsDir() || kind == filenames.Unknown || kind == filenames.R {
+			continue
+		}
+		if member.UncompressedSize64 > MaxArchiveMember {
+			return nil, fmt.Errorf("%w: %s", ErrArchiveMemberTooLarge, member.Name)
+		}
+		rc, err := member.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", member.Name, err)
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, MaxArchiveMember+1))
+		_ = rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", member.Name, err)
+		}
+		if len(content) > MaxArchiveMember {
+			return nil, fmt.Errorf("%w: %s", ErrArchiveMemberTooLarge, member.Name)
+		}
+		files = append(files, ArchiveFile{Name: member.Name, Data: content})
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		xyI := filenames.KindOf(files[i].Name) == filenames.XY
+		xyJ := filenames.KindOf(files[j].Name) == filenames.XY
+		if xyI != xyJ {
+			return xyI
+		}
+		return files[i].Name < files[j].Name
+	})
+	return files, nil
+}
+
+// AddZip adds the Stars! files of a ZIP archive (see ReadZip). Each file's
+// source ID is the archive name followed by its path in the archive, such
+// as "turns.zip/game.m1".
+func (gs *GameStore) AddZip(name string, data []byte) error {
+	files, err := ReadZip(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	for _, f := range files {
+		id := path.Join(name, f.Name)
+		if err := gs.AddFile(id, f.Data); err != nil {
+			return fmt.Errorf("failed to load %s: %w", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package store_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+const archiveDir = "../testdata/scenario-diplomacy-3way/1/side3"
+
+// zipFiles returns a ZIP archive of the named files of archiveDir, plus a
+// readme that is not a Stars! file.
+func zipFiles(t *testing.T, names ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range append(names, "readme.txt") {
+		data := []byte("not a game file")
+		if name != "readme.txt" {
+			var err error
+			data, err = os.ReadFile(filepath.Join(archiveDir, name))
+			require.NoError(t, err)
+		}
+		w, err := zw.Create("turn/" + name)
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestReadZip(t *testing.T) {
+	files, err := store.ReadZip(zipFiles(t, "game.m3", "game.r3", "game.xy"))
+	require.NoError(t, err)
+
+	require.Len(t, files, 2, "race files and other members are skipped")
+	assert.Equal(t, "turn/game.xy", files[0].Name, "the universe comes first")
+	assert.Equal(t, "turn/game.m3", files[1].Name)
+}
+
+func TestReadZip_NotZip(t *testing.T) {
+	_, err := store.ReadZip([]byte("not a zip"))
+	assert.Error(t, err)
+}
+
+func TestGameStore_AddFileWithXY_Zip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "turn.zip")
+	require.NoError(t, os.WriteFile(path, zipFiles(t, "game.m3", "game.xy"), 0o644))
+
+	fromZip := store.New()
+	require.NoError(t, fromZip.AddFileWithXY(path))
+
+	unpacked := store.New()
+	require.NoError(t, unpacked.AddFileWithXY(filepath.Join(archiveDir, "game.m3")))
+
+	assert.Equal(t, 2, fromZip.SourceCount())
+	assert.Equal(t, path+"/turn/game.xy", fromZip.Sources()[0].ID)
+	assert.Equal(t, unpacked.GameID, fromZip.GameID)
+	assert.Equal(t, unpacked.Turn, fromZip.Turn)
+	assert.Equal(t, len(unpacked.AllPlanets()), len(fromZip.AllPlanets()))
+}
@@ -1,10 +1,24 @@
 package store
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+
 	"github.com/neper-stars/houston/blocks"
 	"github.com/neper-stars/houston/data"
 )
 
+// Errors returned by DesignEntity.SetSlot.
+var (
+	ErrNoDesignComponents = errors.New("design components not known")
+	ErrUnknownHull        = errors.New("unknown hull")
+	ErrDesignInUse        = errors.New("design has ships in existence")
+	ErrInvalidSlot        = errors.New("invalid slot")
+	ErrSlotItem           = errors.New("item does not fit the slot")
+	ErrSlotCount          = errors.New("invalid item count for the slot")
+)
+
 // DesignEntity represents a ship or starbase design.
 type DesignEntity struct {
 	meta EntityMeta
@@ -40,16 +54,14 @@ func (d *DesignEntity) SetDirty() {
 	d.meta.Dirty = true
 }
 
-// newDesignEntityFromBlock creates a DesignEntity from a DesignBlock.
-// The owner is taken from the source file's player index.
-func newDesignEntityFromBlock(db *blocks.DesignBlock, source *FileSource) *DesignEntity {
+// newDesignEntityFromBlock creates a DesignEntity of a player from a
+// DesignBlock.
+func newDesignEntityFromBlock(db *blocks.DesignBlock, owner int, source *FileSource) *DesignEntity {
 	entityType := EntityTypeDesign
 	if db.IsStarbase {
 		entityType = EntityTypeStarbaseDesign
 	}
 
-	owner := source.PlayerIndex
-
 	// Full designs (with component info) have higher quality than partial designs
 	quality := QualityFull
 	if !db.IsFullDesign {
@@ -133,6 +145,16 @@ func (d *DesignEntity) HasScanner() bool {
 	return normal > 0 || pen > 0
 }
 
+// ShipCounts returns the number of ships of this design built since it was
+// designed, and of those still in existence. Stars! only counts them for the
+// designs of the player a file is for; both are 0 for the others.
+func (d *DesignEntity) ShipCounts() (built, remaining int64) {
+	if d.designBlock == nil {
+		return 0, 0
+	}
+	return d.designBlock.TotalBuilt, d.designBlock.TotalRemaining
+}
+
 // Hull returns the hull definition for this design.
 // Returns nil if the hull ID is unknown.
 func (d *DesignEntity) Hull() *data.Hull {
@@ -147,6 +169,34 @@ type EquippedItem struct {
 	Count     int    // Number of items in this slot
 }
 
+// slotItemCategories maps slot categories (blocks.ItemCategory*) to the
+// data package item categories used for name lookups.
+var slotItemCategories = map[uint16]data.ItemCategory{
+	blocks.ItemCategoryEngine:      data.CategoryEngine,
+	blocks.ItemCategoryScanner:     data.CategoryScanner,
+	blocks.ItemCategoryShield:      data.CategoryShield,
+	blocks.ItemCategoryArmor:       data.CategoryArmor,
+	blocks.ItemCategoryBeamWeapon:  data.CategoryBeamWeapon,
+	blocks.ItemCategoryTorpedo:     data.CategoryTorpedo,
+	blocks.ItemCategoryBomb:        data.CategoryBomb,
+	blocks.ItemCategoryMiningRobot: data.CategoryMiningRobo,
+	blocks.ItemCategoryMineLayer:   data.CategoryMineLayer,
+	blocks.ItemCategoryOrbital:     data.CategoryOrbital,
+	blocks.ItemCategoryPlanetary:   data.CategoryPlanetary,
+	blocks.ItemCategoryElectrical:  data.CategoryElectrical,
+	blocks.ItemCategoryMechanical:  data.CategoryMechanical,
+}
+
+// Name returns the display name of the equipped item, or an empty string
+// if the item is unknown.
+func (e EquippedItem) Name() string {
+	category, ok := slotItemCategories[e.Category]
+	if !ok {
+		return ""
+	}
+	return data.GetItemName(category, e.ItemID)
+}
+
 // EquippedItems returns all non-empty slots in this design.
 func (d *DesignEntity) EquippedItems() []EquippedItem {
 	if d.designBlock == nil {
@@ -168,6 +218,92 @@ func (d *DesignEntity) EquippedItems() []EquippedItem {
 	return items
 }
 
+// SetSlot equips a hull slot with count items (0 empties it), checking the
+// item against the slot: its category must be accepted, the count must not
+// exceed the slot capacity, and engine slots must be filled. Only designs
+// without ships in existence can be changed, as in the Stars! ship designer.
+//
+// The armor and slot count stored in the design are recomputed and the
+// design is marked dirty; ChangeOrder gives the order saving it in an X
+// file. The armor written is that of the hull and
+// components: Stars! applies race modifiers itself.
+func (d *DesignEntity) SetSlot(slotIndex int, item data.ItemInfo, count int) error {
+	if d.designBlock == nil || !d.designBlock.IsFullDesign {
+		return fmt.Errorf("%w: %s", ErrNoDesignComponents, d.Name)
+	}
+	hull := d.Hull()
+	if hull == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownHull, d.HullId)
+	}
+	if d.designBlock.TotalRemaining > 0 {
+		return fmt.Errorf("%w: %s (%d)", ErrDesignInUse, d.Name, d.designBlock.TotalRemaining)
+	}
+	if slotIndex < 0 || slotIndex >= len(hull.Slots) {
+		return fmt.Errorf("%w: %d (%s has %d slots)", ErrInvalidSlot, slotIndex, hull.Name, len(hull.Slots))
+	}
+	hullSlot := hull.Slots[slotIndex]
+
+	// Stars! writes empty ship slots as zeroes, and keeps the slot category
+	// of empty starbase slots
+	slot := blocks.DesignSlot{}
+	if d.IsStarbase {
+		slot.Category = hullSlot.Category
+	}
+	if count != 0 {
+		category, ok := slotCategory(item.Category)
+		if !ok {
+			return fmt.Errorf("%w: %s items go in no hull slot", ErrSlotItem, data.CategoryNames[item.Category])
+		}
+		if _, _, _, ok := componentInfo(category, item.ItemID); !ok {
+			return fmt.Errorf("%w: unknown %s item %d", ErrSlotItem, data.CategoryNames[item.Category], item.ItemID)
+		}
+		name := data.GetItemName(item.Category, item.ItemID)
+		if !hullSlot.Accepts(category) {
+			return fmt.Errorf("%w: %s in slot %d", ErrSlotItem, name, slotIndex)
+		}
+		if count < 0 || count > hullSlot.MaxItems {
+			return fmt.Errorf("%w: %d %s in slot %d (at most %d)", ErrSlotCount, count, name, slotIndex, hullSlot.MaxItems)
+		}
+		if category == blocks.ItemCategoryEngine && count != hullSlot.MaxItems {
+			return fmt.Errorf("%w: engine slot %d takes %d engines", ErrSlotCount, slotIndex, hullSlot.MaxItems)
+		}
+		slot = blocks.DesignSlot{Category: category, ItemId: item.ItemID - 1, Count: count}
+	}
+
+	slots := make([]blocks.DesignSlot, len(hull.Slots))
+	copy(slots, d.designBlock.Slots)
+	slots[slotIndex] = slot
+	d.designBlock.Slots = slots
+	d.designBlock.SlotCount = len(slots)
+	d.designBlock.Armor = d.GetTotalArmorValue()
+	d.designBlock.Refresh()
+
+	d.SetDirty()
+	return nil
+}
+
+// ChangeOrder returns the order saving the design in an X file, to give
+// to orders.Builder.Add after changing its slots.
+func (d *DesignEntity) ChangeOrder() (*blocks.DesignChangeBlock, error) {
+	if d.designBlock == nil || !d.designBlock.IsFullDesign {
+		return nil, fmt.Errorf("%w: %s", ErrNoDesignComponents, d.Name)
+	}
+	design := *d.designBlock
+	design.Slots = slices.Clone(design.Slots)
+	return blocks.NewDesignChangeOrder(&design), nil
+}
+
+// slotCategory returns the slot category (blocks.ItemCategory*) of a data
+// package item category.
+func slotCategory(category data.ItemCategory) (uint16, bool) {
+	for slotCat, itemCat := range slotItemCategories {
+		if itemCat == category {
+			return slotCat, true
+		}
+	}
+	return 0, false
+}
+
 // ItemsByCategory returns all equipped items of a specific category.
 func (d *DesignEntity) ItemsByCategory(category uint16) []EquippedItem {
 	if d.designBlock == nil {
@@ -338,6 +474,18 @@ func (d *DesignEntity) GetEngine() *data.Engine {
 	return nil
 }
 
+// GetMassDriverWarp returns the warp rating of the best mass driver on this
+// design, or 0 if it has none.
+func (d *DesignEntity) GetMassDriverWarp() int {
+	best := 0
+	for _, item := range d.ItemsByCategory(blocks.ItemCategoryOrbital) {
+		if orbital := data.GetOrbital(item.ItemID); orbital != nil && orbital.IsMassDriver {
+			best = max(best, orbital.WarpSpeed)
+		}
+	}
+	return best
+}
+
 // GetTotalShieldValue returns the total shield strength for this design.
 // This is the sum of (shield value * count) for all equipped shields.
 func (d *DesignEntity) GetTotalShieldValue() int {
@@ -605,5 +753,128 @@ func (d *DesignEntity) HasBombs() bool {
 	return k > 0
 }
 
+// GetCost returns the total build cost of this design: hull plus all
+// equipped components. Ship hull costs are not yet in the data tables,
+// so only components are counted for ship designs.
+func (d *DesignEntity) GetCost() data.Cost {
+	var total data.Cost
+	if hull := d.Hull(); hull != nil {
+		total = hull.Cost
+	}
+	for _, item := range d.EquippedItems() {
+		cost, _, _, ok := componentInfo(item.Category, item.ItemID)
+		if !ok {
+			continue
+		}
+		total.Resources += cost.Resources * item.Count
+		total.Ironium += cost.Ironium * item.Count
+		total.Boranium += cost.Boranium * item.Count
+		total.Germanium += cost.Germanium * item.Count
+	}
+	return total
+}
+
+// GetMass returns the mass of one ship of this design (kT). Designs seen
+// without their components carry their mass; full designs add up the hull
+// and components.
+func (d *DesignEntity) GetMass() int {
+	if d.designBlock != nil && d.designBlock.Mass > 0 {
+		return d.designBlock.Mass
+	}
+	total := 0
+	if hull := d.Hull(); hull != nil {
+		total = hull.Mass
+	}
+	for _, item := range d.EquippedItems() {
+		if _, _, mass, ok := componentInfo(item.Category, item.ItemID); ok {
+			total += mass * item.Count
+		}
+	}
+	return total
+}
+
+// GetTechRequirements returns the minimum tech levels needed to build this
+// design (the per-field maximum over the hull and all components).
+func (d *DesignEntity) GetTechRequirements() data.TechRequirements {
+	var req data.TechRequirements
+	if hull := d.Hull(); hull != nil {
+		req = hull.Tech
+	}
+	for _, item := range d.EquippedItems() {
+		_, tech, _, ok := componentInfo(item.Category, item.ItemID)
+		if !ok {
+			continue
+		}
+		req.Energy = max(req.Energy, tech.Energy)
+		req.Weapons = max(req.Weapons, tech.Weapons)
+		req.Propulsion = max(req.Propulsion, tech.Propulsion)
+		req.Construction = max(req.Construction, tech.Construction)
+		req.Electronics = max(req.Electronics, tech.Electronics)
+		req.Biotech = max(req.Biotech, tech.Biotech)
+	}
+	return req
+}
+
+// CanBuildWith returns true if the design can be built with the given tech levels.
+func (d *DesignEntity) CanBuildWith(tech data.TechRequirements) bool {
+	return d.GetTechRequirements().CanBuildWith(tech)
+}
+
+// componentInfo looks up the cost, tech requirements and mass of a component
+// by slot category (blocks.ItemCategory*) and 1-indexed item ID.
+func componentInfo(category uint16, itemID int) (data.Cost, data.TechRequirements, int, bool) {
+	switch category {
+	case blocks.ItemCategoryEngine:
+		if it := data.GetEngine(itemID); it != nil {
+			return it.Cost, it.Tech, it.Mass, true
+		}
+	case blocks.ItemCategoryScanner:
+		if it := data.GetScanner(itemID); it != nil {
+			return it.Cost, it.Tech, it.Mass, true
+		}
+	case blocks.ItemCategoryShield:
+		if it := data.GetShield(itemID); it != nil {
+			return it.Cost, it.Tech, it.Mass, true
+		}
+	case blocks.ItemCategoryArmor:
+		if it := data.GetArmor(itemID); it != nil {
+			return it.Cost, it.Tech, it.Mass, true
+		}
+	case blocks.ItemCategoryBeamWeapon:
+		if it := data.GetBeamWeapon(itemID); it != nil {
+			return it.Cost, it.Tech, it.Mass, true
+		}
+	case blocks.ItemCategoryTorpedo:
+		if it := data.GetTorpedo(itemID); it != nil {
+			return it.Cost, it.Tech, it.Mass, true
+		}
+	case blocks.ItemCategoryBomb:
+		if it := data.GetBomb(itemID); it != nil {
+			return it.Cost, it.Tech, it.Mass, true
+		}
+	case blocks.ItemCategoryMiningRobot:
+		if it := data.GetMiningRobot(itemID); it != nil {
+			return it.Cost, it.Tech, it.Mass, true
+		}
+	case blocks.ItemCategoryMineLayer:
+		if it := data.GetMineLayer(itemID); it != nil {
+			return it.Cost, it.Tech, it.Mass, true
+		}
+	case blocks.ItemCategoryOrbital:
+		if it := data.GetOrbital(itemID); it != nil {
+			return it.Cost, it.Tech, it.Mass, true
+		}
+	case blocks.ItemCategoryElectrical:
+		if it := data.GetElectrical(itemID); it != nil {
+			return it.Cost, it.Tech, it.Mass, true
+		}
+	case blocks.ItemCategoryMechanical:
+		if it := data.GetMechanical(itemID); it != nil {
+			return it.Cost, it.Tech, it.Mass, true
+		}
+	}
+	return data.Cost{}, data.TechRequirements{}, 0, false
+}
+
 // DesignMap is a convenience type for looking up designs by slot.
 type DesignMap map[int]*DesignEntity
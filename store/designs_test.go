@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
 	"github.com/neper-stars/houston/store"
 )
 
@@ -228,3 +229,106 @@ func TestDesignEntity_Capabilities(t *testing.T) {
 		assert.True(t, hasScanner, "Scout should have scanner")
 	})
 }
+
+func TestDesignEntity_CostAndTech(t *testing.T) {
+	fileData, err := os.ReadFile("../testdata/scenario-map/history/game-2471.m1")
+	require.NoError(t, err)
+
+	gs := store.New()
+	err = gs.AddFile("game-2471.m1", fileData)
+	require.NoError(t, err)
+
+	// "Starbase": Space Station hull with 32 Lasers and 32 Mole-skin Shields
+	sb, ok := gs.StarbaseDesign(0, 0)
+	require.True(t, ok)
+	assert.Equal(t, data.Cost{Resources: 888, Ironium: 152, Boranium: 272, Germanium: 282}, sb.GetCost())
+	assert.Equal(t, data.TechRequirements{}, sb.GetTechRequirements())
+	assert.True(t, sb.CanBuildWith(data.TechRequirements{}))
+
+	// "Starbase MD ST" adds a Mass Driver 7 and a Stargate 100/250
+	sb, ok = gs.StarbaseDesign(0, 2)
+	require.True(t, ok)
+	assert.Equal(t, data.Cost{Resources: 2312, Ironium: 452, Boranium: 512, Germanium: 522}, sb.GetCost())
+	assert.Equal(t, data.TechRequirements{Energy: 9, Propulsion: 5, Construction: 5}, sb.GetTechRequirements())
+	assert.False(t, sb.CanBuildWith(data.TechRequirements{Energy: 8, Propulsion: 5, Construction: 5}))
+	assert.True(t, sb.CanBuildWith(data.TechRequirements{Energy: 9, Propulsion: 5, Construction: 5}))
+}
+
+func TestDesignEntity_GetMassDriverWarp(t *testing.T) {
+	fileData, err := os.ReadFile("../testdata/scenario-map/history/game-2471.m1")
+	require.NoError(t, err)
+
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game-2471.m1", fileData))
+
+	sb, ok := gs.StarbaseDesign(0, 0)
+	require.True(t, ok)
+	assert.Zero(t, sb.GetMassDriverWarp(), "\"Starbase\" has no mass driver")
+
+	sb, ok = gs.StarbaseDesign(0, 1)
+	require.True(t, ok)
+	assert.Equal(t, 7, sb.GetMassDriverWarp(), "\"Starbase MD\" has a Mass Driver 7")
+}
+
+func TestEquippedItem_Name(t *testing.T) {
+	assert.Equal(t, "Laser", store.EquippedItem{Category: blocks.ItemCategoryBeamWeapon, ItemID: 1, Count: 8}.Name())
+	assert.Equal(t, "Mass Driver 7", store.EquippedItem{Category: blocks.ItemCategoryOrbital, ItemID: 10, Count: 1}.Name())
+	assert.Equal(t, "", store.EquippedItem{Category: 0x8000, ItemID: 1}.Name())
+}
+
+func TestDesignEntity_SetSlot(t *testing.T) {
+	raw, err := os.ReadFile("../testdata/Game.m1")
+	require.NoError(t, err)
+	gs := store.New()
+	require.NoError(t, gs.AddFile("Game.m1", raw))
+
+	var rogue, probe *store.DesignEntity
+	for _, d := range gs.ShipDesignsByOwner(0) {
+		switch d.Name {
+		case "Rogue":
+			rogue = d
+		case "Smaugarian Peeping Tom":
+			probe = d
+		}
+	}
+	require.NotNil(t, rogue)
+	require.NotNil(t, probe)
+
+	kelarium, ok := data.GetItemInfo("Kelarium")
+	require.True(t, ok)
+	engine, ok := data.GetItemInfo("Trans-Star 10")
+	require.True(t, ok)
+
+	// Designs with ships in existence cannot be changed
+	assert.ErrorIs(t, probe.SetSlot(1, kelarium, 1), store.ErrDesignInUse)
+
+	assert.ErrorIs(t, rogue.SetSlot(9, kelarium, 1), store.ErrInvalidSlot)
+	assert.ErrorIs(t, rogue.SetSlot(2, kelarium, 1), store.ErrSlotItem, "scanner and electrical slot")
+	assert.ErrorIs(t, rogue.SetSlot(1, kelarium, 4), store.ErrSlotCount)
+	assert.ErrorIs(t, rogue.SetSlot(0, engine, 1), store.ErrSlotCount, "engine slots must be full")
+	assert.ErrorIs(t, rogue.SetSlot(1, data.ItemInfo{Category: data.CategoryArmor, ItemID: 99}, 1), store.ErrSlotItem)
+	assert.False(t, rogue.Meta().Dirty)
+
+	armor := rogue.GetTotalArmorValue()
+	require.NoError(t, rogue.SetSlot(1, kelarium, 3))
+	assert.True(t, rogue.Meta().Dirty)
+	assert.Greater(t, rogue.GetTotalArmorValue(), armor)
+	assert.Contains(t, rogue.ItemsByCategory(blocks.ItemCategoryArmor),
+		store.EquippedItem{SlotIndex: 1, Category: blocks.ItemCategoryArmor, ItemID: data.ArmorKelarium, Count: 3})
+
+	equipped := len(rogue.EquippedItems())
+	require.NoError(t, rogue.SetSlot(1, kelarium, 0))
+	assert.Len(t, rogue.EquippedItems(), equipped-1)
+
+	// The change order carries the recomputed design
+	require.NoError(t, rogue.SetSlot(1, kelarium, 2))
+	order, err := rogue.ChangeOrder()
+	require.NoError(t, err)
+	decoded, err := blocks.NewDesignChangeBlock(order.GenericBlock)
+	require.NoError(t, err)
+	require.NotNil(t, decoded.Design)
+	assert.Equal(t, rogue.DesignNumber, decoded.Design.DesignNumber)
+	assert.Equal(t, "Rogue", decoded.Design.Name)
+	assert.Equal(t, rogue.GetTotalArmorValue(), decoded.Design.Armor)
+	assert.Equal(t, blocks.DesignSlot{Category: blocks.ItemCategoryArmor, ItemId: data.ArmorKelarium - 1, Count: 2}, decoded.Design.Slots[1])
+}
@@ -54,6 +54,12 @@ func (e *BlockEncoder) EncodeFleetBlock(fleet *FleetEntity) ([]byte, error) {
 		fleet.fleetBlock.Germanium = fleet.germanium
 		fleet.fleetBlock.Population = fleet.population
 		fleet.fleetBlock.Fuel = fleet.fuel
+		fleet.fleetBlock.X = fleet.X
+		fleet.fleetBlock.Y = fleet.Y
+		fleet.fleetBlock.PositionObjectId = fleet.PositionObjectId
+		if fleet.waypointsChanged {
+			fleet.fleetBlock.WaypointCount = len(fleet.Waypoints)
+		}
 		return fleet.fleetBlock.Encode(), nil
 	}
 
@@ -159,6 +165,8 @@ func (e *BlockEncoder) EncodePlanetBlock(planet *PlanetEntity) ([]byte, error) {
 
 		// Starbase
 		pb.StarbaseDesign = planet.StarbaseDesign
+		pb.MassDriverDest = planet.MassDriverDest
+		pb.PacketWarpSpeed = planet.PacketWarpSpeed
 
 		// Route
 		pb.RouteTarget = planet.RouteTarget
@@ -229,6 +237,8 @@ func (e *BlockEncoder) EncodePlanetBlockFromSource(sourceBlock *blocks.PartialPl
 
 	// Starbase
 	pb.StarbaseDesign = planet.StarbaseDesign
+	pb.MassDriverDest = planet.MassDriverDest
+	pb.PacketWarpSpeed = planet.PacketWarpSpeed
 
 	// Route
 	pb.RouteTarget = planet.RouteTarget
@@ -432,8 +442,11 @@ func (e *BlockEncoder) encodePlanetBlockInPlace(pb *blocks.PartialPlanetBlock) (
 			if index+4 <= len(data) {
 				// Clear design bits (low nibble) and set new design
 				data[index] = (data[index] & 0xF0) | byte(pb.StarbaseDesign&0x0F)
-				// Update mass driver destination if set
-				data[index+2] = byte(pb.MassDriverDest & 0xFF)
+				// Update mass driver destination and packet warp
+				// (bits 14-15 of the settings word are preserved)
+				driver := uint16(pb.MassDriverDest&0x3FF) | uint16(pb.PacketWarpSpeed&0x0F)<<10
+				driver |= encoding.Read16(data, index+2) & 0xC000
+				encoding.Write16(data, index+2, driver)
 				debugLog("  updated starbase design=%d at index %d\n", pb.StarbaseDesign, index)
 				index += 4
 			}
@@ -443,8 +456,8 @@ func (e *BlockEncoder) encodePlanetBlockInPlace(pb *blocks.PartialPlanetBlock) (
 			starbaseBytes := make([]byte, 4)
 			starbaseBytes[0] = byte(pb.StarbaseDesign & 0x0F) // Design in low nibble, no damage
 			starbaseBytes[1] = 0                              // StarbaseInfo
-			starbaseBytes[2] = byte(pb.MassDriverDest & 0xFF) // Mass driver dest
-			starbaseBytes[3] = 0                              // StarbaseInfo
+			// Mass driver destination and packet warp
+			encoding.Write16(starbaseBytes, 2, uint16(pb.MassDriverDest&0x3FF)|uint16(pb.PacketWarpSpeed&0x0F)<<10)
 
 			// Insert starbase bytes into data
 			newData := make([]byte, len(data)+4)
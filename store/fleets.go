@@ -59,6 +59,10 @@ type FleetEntity struct {
 	PrimaryDesign *DesignEntity
 	Waypoints     []*WaypointEntity // Associated waypoints in order
 
+	// waypointsChanged is set when the waypoints are replaced, so that files
+	// are written with the new ones
+	waypointsChanged bool
+
 	// Raw blocks (preserved for re-encoding)
 	fleetBlock *blocks.PartialFleetBlock
 	nameBlock  *blocks.FleetNameBlock
@@ -95,6 +99,16 @@ func (f *FleetEntity) ClearWaypoints() {
 	f.Waypoints = nil
 }
 
+// SetWaypoints replaces the waypoints of the fleet, the first one being
+// where the fleet is (marks dirty). Regenerated HST files hold the new
+// waypoints.
+func (f *FleetEntity) SetWaypoints(waypoints []*WaypointEntity) {
+	f.Waypoints = waypoints
+	f.WaypointCount = len(waypoints)
+	f.waypointsChanged = true
+	f.SetDirty()
+}
+
 // SetDirty marks the entity as modified.
 func (f *FleetEntity) SetDirty() {
 	f.meta.Dirty = true
@@ -0,0 +1,228 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/neper-stars/houston/blocks"
+)
+
+// ErrNoHostFile is returned when a host file is written from a store that
+// holds no host file to take the file header from.
+var ErrNoHostFile = errors.New("no host file loaded")
+
+// CreateHostFile serializes the host state of a store into a complete .hst
+// file. Unlike RegenerateHSTFile, which rewrites the blocks of the loaded
+// host file, it writes the entities of the store, so that fleets, designs,
+// objects and battle plans added or removed since loading are saved too.
+//
+// The blocks are written in the order of Stars!: the players, the planets
+// each followed by its production queue, the ship designs of every player,
+// the fleets with their waypoints and names, the starbase designs, the
+// objects after their count, and the battle plans. The design and fleet
+// counts of the player blocks are updated to match.
+//
+// The file header is the one of the loaded host file, which must be in
+// the store. Entities are written from their full blocks: those only seen
+// in player files cannot be written.
+func CreateHostFile(gs *GameStore) ([]byte, error) {
+	var source *FileSource
+	for _, s := range gs.Sources() {
+		if s.Type == SourceTypeHSTFile {
+			source = s
+			break
+		}
+	}
+	if source == nil {
+		return nil, ErrNoHostFile
+	}
+	header := source.Header
+	if header == nil {
+		return nil, ErrNoHeader
+	}
+
+	writer := NewFileWriter()
+	result := writer.WriteHeader(header)
+	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), header.SharewareFlag())
+	write := func(typeID blocks.BlockTypeID, data []byte) {
+		result = append(result, writer.WriteEncryptedBlock(typeID, data)...)
+	}
+
+	players := sortedByKey(gs.Players.All())
+	designs := sortedByKey(gs.Designs.All())
+	fleets := sortedByKey(gs.Fleets.All())
+	plans := sortedByKey(gs.BattlePlans.All())
+
+	// Every planet has a block, in number order
+	var planets []*PlanetEntity
+	for _, planet := range gs.Planets.All() {
+		if saved, ok := gs.PlanetForSave(planet.PlanetNumber); ok && saved == planet {
+			planets = append(planets, planet)
+		}
+	}
+	slices.SortFunc(planets, func(a, b *PlanetEntity) int { return a.PlanetNumber - b.PlanetNumber })
+
+	// Players
+	for _, player := range players {
+		data, err := encodeHostPlayer(player, designs, fleets)
+		if err != nil {
+			return nil, fmt.Errorf("player %d: %w", player.PlayerNumber+1, err)
+		}
+		write(blocks.PlayerBlockType, data)
+	}
+
+	// Planets, each followed by its production queue
+	for _, planet := range planets {
+		number := planet.PlanetNumber
+		if planet.planetBlock == nil {
+			return nil, fmt.Errorf("planet %d: %w", number, ErrNoRawBlockData)
+		}
+		data := planet.planetBlock.DecryptedData()
+		if planet.Meta().Dirty {
+			encoded, err := writer.encoder.EncodePlanetBlockFromSource(planet.planetBlock, planet)
+			if err != nil {
+				return nil, fmt.Errorf("planet %d: %w", number, err)
+			}
+			data = encoded
+		}
+		write(blocks.PlanetBlockType, data)
+
+		if pq, ok := gs.ProductionQueue(number); ok && len(pq.Items) > 0 {
+			encoded, err := writer.encoder.EncodeProductionQueueBlock(pq)
+			if err != nil {
+				return nil, fmt.Errorf("production queue of planet %d: %w", number, err)
+			}
+			write(blocks.ProductionQueueBlockType, encoded)
+		}
+	}
+
+	// Ship designs
+	for _, design := range hostDesigns(designs, false) {
+		write(blocks.DesignBlockType, design.encode())
+	}
+
+	// Fleets, each followed by its waypoints and name
+	for _, fleet := range fleets {
+		if fleet.fleetBlock == nil {
+			return nil, fmt.Errorf("fleet %d of player %d: %w", fleet.FleetNumber+1, fleet.Owner+1, ErrNoRawBlockData)
+		}
+		data, err := writer.encoder.EncodeFleetBlock(fleet)
+		if err != nil {
+			return nil, fmt.Errorf("fleet %d of player %d: %w", fleet.FleetNumber+1, fleet.Owner+1, err)
+		}
+		write(fleet.fleetBlock.BlockTypeID(), data)
+		for _, wp := range fleet.Waypoints {
+			raw := wp.RawBlocks()
+			if len(raw) == 1 && !wp.Meta().Dirty {
+				write(raw[0].BlockTypeID(), raw[0].DecryptedData())
+			} else {
+				write(wp.encodeBlock())
+			}
+		}
+		if fleet.nameBlock != nil && fleet.nameBlock.Name == fleet.CustomName {
+			write(blocks.FleetNameBlockType, fleet.nameBlock.DecryptedData())
+		} else if fleet.HasCustomName {
+			name := blocks.FleetNameBlock{Name: fleet.CustomName}
+			write(blocks.FleetNameBlockType, name.Encode())
+		}
+	}
+
+	// Starbase designs
+	for _, design := range hostDesigns(designs, true) {
+		write(blocks.DesignBlockType, design.encode())
+	}
+
+	// Objects, after the count object
+	var objects []*ObjectEntity
+	for _, object := range gs.Objects.All() {
+		if object.objectBlock != nil {
+			objects = append(objects, object)
+		}
+	}
+	if len(objects) > 0 {
+		count := blocks.ObjectBlock{IsCountObject: true, Count: len(objects)}
+		write(blocks.ObjectBlockType, count.Encode())
+	}
+	for _, object := range objects {
+		write(blocks.ObjectBlockType, object.objectBlock.DecryptedData())
+	}
+
+	// Battle plans
+	for _, plan := range plans {
+		data, err := writer.encoder.EncodeBattlePlanBlock(plan)
+		if err != nil {
+			return nil, fmt.Errorf("battle plan %q of player %d: %w", plan.Name, plan.Owner+1, err)
+		}
+		write(blocks.BattlePlanBlockType, data)
+	}
+
+	result = append(result, writer.WriteFooter(true, hstFileFooterData(header))...)
+	return result, nil
+}
+
+// encodeHostPlayer encodes the block of a player with the counts of its
+// designs and fleets taken from the store.
+func encodeHostPlayer(player *PlayerEntity, designs []*DesignEntity, fleets []*FleetEntity) ([]byte, error) {
+	if player.playerBlock == nil {
+		return nil, ErrNoRawBlockData
+	}
+	pb := *player.playerBlock
+	pb.ShipDesignCount, pb.StarbaseDesignCount, pb.Fleets = 0, 0, 0
+	for _, design := range hostDesigns(designs, false) {
+		if design.Owner == player.PlayerNumber {
+			pb.ShipDesignCount++
+		}
+	}
+	for _, design := range hostDesigns(designs, true) {
+		if design.Owner == player.PlayerNumber {
+			pb.StarbaseDesignCount++
+		}
+	}
+	for _, fleet := range fleets {
+		if fleet.Owner == player.PlayerNumber {
+			pb.Fleets++
+		}
+	}
+
+	original := player.playerBlock
+	if !player.Meta().Dirty && pb.ShipDesignCount == original.ShipDesignCount &&
+		pb.StarbaseDesignCount == original.StarbaseDesignCount &&
+		pb.Fleets == original.Fleets {
+		return original.DecryptedData(), nil
+	}
+	return pb.Encode()
+}
+
+// hostDesigns returns the full ship or starbase designs of the players.
+func hostDesigns(designs []*DesignEntity, starbases bool) []*DesignEntity {
+	var result []*DesignEntity
+	for _, design := range designs {
+		if design.IsStarbase == starbases && design.designBlock != nil && design.designBlock.IsFullDesign &&
+			design.Owner >= 0 && design.Owner < blocks.RaceFilePlayerIndex {
+			result = append(result, design)
+		}
+	}
+	return result
+}
+
+// encode returns the block data of a design, re-encoded when it changed.
+func (d *DesignEntity) encode() []byte {
+	if d.Meta().Dirty {
+		return d.designBlock.Encode()
+	}
+	return d.designBlock.DecryptedData()
+}
+
+// sortedByKey returns entities ordered by owner, then number.
+func sortedByKey[T Entity](entities []T) []T {
+	sorted := slices.Clone(entities)
+	slices.SortStableFunc(sorted, func(a, b T) int {
+		ka, kb := a.Meta().Key, b.Meta().Key
+		if ka.Owner != kb.Owner {
+			return ka.Owner - kb.Owner
+		}
+		return ka.Number - kb.Number
+	})
+	return sorted
+}
@@ -0,0 +1,71 @@
+package store_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func TestCreateHostFile_RoundTrip(t *testing.T) {
+	for _, path := range []string{
+		"../testdata/scenario-singleplayer/2499/Game.hst",
+		"../testdata/scenario-cloaking-visibility/game01/historic-backup/game-2435.hst",
+	} {
+		t.Run(path, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			gs := store.New()
+			require.NoError(t, gs.AddFile("game.hst", data))
+
+			created, err := store.CreateHostFile(gs)
+			require.NoError(t, err)
+			assert.Equal(t, data, created, "an unchanged game is written as it was read")
+		})
+	}
+}
+
+func TestCreateHostFile_Changes(t *testing.T) {
+	data, err := os.ReadFile("../testdata/scenario-cloaking-visibility/game01/historic-backup/game-2435.hst")
+	require.NoError(t, err)
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game.hst", data))
+
+	// Designs are owned as counted by the player blocks
+	require.Len(t, gs.ShipDesignsByOwner(1), 2)
+	require.Len(t, gs.StarbaseDesignsByOwner(1), 2)
+	assert.Len(t, gs.ShipDesignsByOwner(0), 3)
+
+	fleets := gs.Fleets.ByOwner(1)
+	require.NotEmpty(t, fleets)
+	removed := fleets[0].Meta().Key
+	require.True(t, gs.Fleets.Remove(removed))
+
+	created, err := store.CreateHostFile(gs)
+	require.NoError(t, err)
+	reloaded := store.New()
+	require.NoError(t, reloaded.AddFile("game.hst", created))
+
+	_, ok := reloaded.Fleets.Get(removed)
+	assert.False(t, ok)
+	before, _ := gs.Player(1)
+	after, ok := reloaded.Player(1)
+	require.True(t, ok)
+	assert.Equal(t, before.FleetCount-1, after.FleetCount, "the player block counts the fleets written")
+	assert.Equal(t, gs.Fleets.Count(), reloaded.Fleets.Count())
+	assert.Len(t, reloaded.ShipDesignsByOwner(1), 2)
+	assert.Equal(t, gs.Objects.Count(), reloaded.Objects.Count())
+}
+
+func TestCreateHostFile_NoHostFile(t *testing.T) {
+	data, err := os.ReadFile("../testdata/scenario-singleplayer/2499/Game.m1")
+	require.NoError(t, err)
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game.m1", data))
+
+	_, err = store.CreateHostFile(gs)
+	assert.ErrorIs(t, err, store.ErrNoHostFile)
+}
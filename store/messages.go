@@ -9,6 +9,7 @@ type MessageEntity struct {
 	// Message data
 	SenderId   int    // Sender player number (0-15)
 	ReceiverId int    // Receiver: 0=everyone, 1-16=specific player
+	InReplyTo  int    // Index of the message replied to in the sender's message list, 0 if not a reply
 	Message    string // Message text
 
 	// Raw block (preserved for re-encoding)
@@ -38,6 +39,11 @@ func (m *MessageEntity) IsBroadcast() bool {
 	return m.ReceiverId == 0
 }
 
+// IsReply returns true if the message replies to another.
+func (m *MessageEntity) IsReply() bool {
+	return m.InReplyTo > 0
+}
+
 // newMessageEntityFromBlock creates a MessageEntity from a MessageBlock.
 func newMessageEntityFromBlock(mb *blocks.MessageBlock, index int, source *FileSource) *MessageEntity {
 	entity := &MessageEntity{
@@ -53,6 +59,7 @@ func newMessageEntityFromBlock(mb *blocks.MessageBlock, index int, source *FileS
 		},
 		SenderId:     mb.SenderId,
 		ReceiverId:   mb.ReceiverId,
+		InReplyTo:    mb.InReplyTo,
 		Message:      mb.Message,
 		messageBlock: mb,
 	}
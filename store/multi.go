@@ -0,0 +1,142 @@
+package store
+
+import (
+	"io"
+	"sort"
+
+	"github.com/neper-stars/houston/parser"
+)
+
+// MultiStore holds several games side by side, one GameStore per game ID.
+// Files are routed to their game by the ID in their header, so files from
+// concurrent games can be loaded in any order through one store.
+type MultiStore struct {
+	games    map[uint32]*GameStore
+	resolver func() ConflictResolver
+	cache    *parser.Cache
+}
+
+// NewMulti creates an empty MultiStore whose games use default conflict resolution.
+func NewMulti() *MultiStore {
+	return NewMultiWithResolver(func() ConflictResolver { return &DefaultResolver{} })
+}
+
+// NewMultiWithResolver creates a MultiStore calling newResolver once per game.
+func NewMultiWithResolver(newResolver func() ConflictResolver) *MultiStore {
+	return &MultiStore{
+		games:    make(map[uint32]*GameStore),
+		resolver: newResolver,
+	}
+}
+
+// SetCache makes the store and its games reuse the blocks cached for files
+// already parsed (see parser.Cache). A nil cache disables caching.
+func (ms *MultiStore) SetCache(cache *parser.Cache) {
+	ms.cache = cache
+	for _, gs := range ms.games {
+		gs.SetCache(cache)
+	}
+}
+
+// AddFile parses a file and merges it into the store of its game,
+// creating that store on first use.
+func (ms *MultiStore) AddFile(name string, data []byte) error {
+	source, err := ParseSourceCached(name, data, ms.cache)
+	if err != nil {
+		return err
+	}
+	gs, err := ms.gameFor(source)
+	if err != nil {
+		return err
+	}
+	return gs.addSource(source)
+}
+
+// AddFileReader adds from an io.Reader.
+func (ms *MultiStore) AddFileReader(name string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return ms.AddFile(name, data)
+}
+
+// AddFileWithXY loads a game file into the store of its game, along with
+// the companion XY file for M and H files (see GameStore.AddFileWithXY).
+func (ms *MultiStore) AddFileWithXY(filename string) error {
+	return ms.AddFileWithXYFromFS(filename, osFS{})
+}
+
+// AddFileWithXYFromFS loads a file with optional companion XY file using a filesystem interface.
+func (ms *MultiStore) AddFileWithXYFromFS(filename string, fs FileSystem) error {
+	data, err := fs.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	source, err := ParseSourceCached(filename, data, ms.cache)
+	if err != nil {
+		return err
+	}
+	gs, err := ms.gameFor(source)
+	if err != nil {
+		return err
+	}
+
+	if xyFile := findCompanionXYFile(filename, fs); xyFile != "" {
+		if xyData, err := fs.ReadFile(xyFile); err == nil {
+			// Ignore errors - just try to load
+			_ = gs.AddFile(xyFile, xyData)
+		}
+	}
+	return gs.addSource(source)
+}
+
+// gameFor returns the store of the source's game, creating it if needed.
+func (ms *MultiStore) gameFor(source *FileSource) (*GameStore, error) {
+	if source.Header == nil {
+		return nil, ErrNoHeader
+	}
+	gs, ok := ms.games[source.GameID]
+	if !ok {
+		gs = NewWithResolver(ms.resolver())
+		gs.SetCache(ms.cache)
+		ms.games[source.GameID] = gs
+	}
+	return gs, nil
+}
+
+// Game returns the store of a game.
+func (ms *MultiStore) Game(gameID uint32) (*GameStore, bool) {
+	gs, ok := ms.games[gameID]
+	return gs, ok
+}
+
+// GameIDs returns the IDs of all loaded games in ascending order.
+func (ms *MultiStore) GameIDs() []uint32 {
+	ids := make([]uint32, 0, len(ms.games))
+	for id := range ms.games {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Games returns the stores of all loaded games, ordered by game ID.
+func (ms *MultiStore) Games() []*GameStore {
+	ids := ms.GameIDs()
+	result := make([]*GameStore, len(ids))
+	for i, id := range ids {
+		result[i] = ms.games[id]
+	}
+	return result
+}
+
+// Len returns the number of loaded games.
+func (ms *MultiStore) Len() int {
+	return len(ms.games)
+}
+
+// RemoveGame drops a game and all its files from the store.
+func (ms *MultiStore) RemoveGame(gameID uint32) {
+	delete(ms.games, gameID)
+}
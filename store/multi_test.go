@@ -0,0 +1,60 @@
+package store_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func TestMultiStore_SeparatesGames(t *testing.T) {
+	ms := store.NewMulti()
+
+	files := []string{
+		"../testdata/scenario-basic/game.m1",
+		"../testdata/scenario-packet-driver-destination/game.m1",
+		"../testdata/scenario-map/game.m1",
+		"../testdata/scenario-mineral-packet/game.m1", // Same game as the packet driver scenario
+	}
+	for _, f := range files {
+		require.NoError(t, ms.AddFileWithXY(f), f)
+	}
+
+	assert.Equal(t, 3, ms.Len())
+	assert.Equal(t, []uint32{1098388264, 1161673766, 1169067313}, ms.GameIDs())
+
+	gs, ok := ms.Game(1169067313)
+	require.True(t, ok)
+	assert.Equal(t, uint32(1169067313), gs.GameID)
+	assert.Equal(t, uint16(65), gs.Turn, "newest turn of the two files wins")
+	assert.NotEmpty(t, gs.PlanetName(105), "companion XY file is loaded into the right game")
+
+	games := ms.Games()
+	require.Len(t, games, 3)
+	for i, id := range ms.GameIDs() {
+		assert.Equal(t, id, games[i].GameID)
+	}
+
+	ms.RemoveGame(1098388264)
+	_, ok = ms.Game(1098388264)
+	assert.False(t, ok)
+	assert.Equal(t, 2, ms.Len())
+}
+
+func TestMultiStore_AddFile(t *testing.T) {
+	data, err := os.ReadFile("../testdata/scenario-basic/game.m1")
+	require.NoError(t, err)
+
+	ms := store.NewMulti()
+	require.NoError(t, ms.AddFile("game.m1", data))
+
+	gs, ok := ms.Game(1098388264)
+	require.True(t, ok)
+	_, ok = gs.Source("game.m1")
+	assert.True(t, ok)
+
+	assert.Error(t, ms.AddFile("garbage.m1", []byte{0x01, 0x02}))
+}
@@ -4,6 +4,7 @@ import (
 	"math"
 
 	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
 )
 
 // Object type constants
@@ -116,6 +117,15 @@ func (o *ObjectEntity) Radius() float64 {
 	return math.Sqrt(float64(o.MineCount))
 }
 
+// DetonateOrder returns the order setting or clearing the detonate flag of
+// this minefield, or nil if the entity was not loaded from a minefield block.
+func (o *ObjectEntity) DetonateOrder(detonate bool) *blocks.ObjectBlock {
+	if o.objectBlock == nil || !o.objectBlock.IsMinefield() {
+		return nil
+	}
+	return blocks.NewMinefieldDetonateOrder(o.objectBlock, detonate)
+}
+
 // GetCargo returns the cargo for packets/salvage as a Cargo struct.
 func (o *ObjectEntity) GetCargo() Cargo {
 	return Cargo{
@@ -125,6 +135,35 @@ func (o *ObjectEntity) GetCargo() Cargo {
 	}
 }
 
+// PacketWarp returns the warp speed of a mineral packet.
+func (o *ObjectEntity) PacketWarp() int {
+	return (o.PacketSpeed >> 2) - 44
+}
+
+// PacketScanRange returns the penetrating scanner range of a mineral packet,
+// 0 unless its owner is a Packet Physics race (see data.PRT.PacketScanRange).
+func (o *ObjectEntity) PacketScanRange(gs *GameStore) int {
+	if !o.IsPacket() || o.IsSalvage || o.Owner < 0 {
+		return 0
+	}
+	player, ok := gs.Player(o.Owner)
+	if !ok {
+		return 0
+	}
+	prt := data.GetPRT(player.PRT)
+	if prt == nil {
+		return 0
+	}
+	return prt.PacketScanRange(o.PacketWarp())
+}
+
+// objectKeyNumber returns the key number of an object. Each object type
+// numbers its objects separately (a minefield and a trader can both be #0),
+// so the type is folded into the key. Minefields keep their plain number.
+func objectKeyNumber(objectType, number int) int {
+	return objectType<<16 | number
+}
+
 // newObjectEntityFromBlock creates an ObjectEntity from an ObjectBlock.
 func newObjectEntityFromBlock(ob *blocks.ObjectBlock, source *FileSource) *ObjectEntity {
 	// Skip count objects
@@ -137,7 +176,7 @@ func newObjectEntityFromBlock(ob *blocks.ObjectBlock, source *FileSource) *Objec
 			Key: EntityKey{
 				Type:   EntityTypeObject,
 				Owner:  ob.Owner,
-				Number: ob.Number,
+				Number: objectKeyNumber(ob.ObjectType, ob.Number),
 			},
 			BestSource: source,
 			Quality:    QualityFull,
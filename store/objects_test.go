@@ -75,3 +75,22 @@ func TestObjectEntity_Radius(t *testing.T) {
 	// Ensure all expected minefields were found
 	assert.Empty(t, expectedByPos, "some expected minefields were not found in game data")
 }
+
+func TestObjectKeys_TypesNumberedSeparately(t *testing.T) {
+	// The trader #0 shares its owner and number with minefield #0
+	gs := New()
+	require.NoError(t, gs.AddFileWithXY(filepath.Join("..", "testdata", "scenario-mysterytrader", "game.m1")))
+
+	assert.Len(t, gs.Minefields(), 2)
+	traders := gs.MysteryTraders()
+	require.Len(t, traders, 1)
+	assert.Equal(t, 1182, traders[0].X)
+	assert.Equal(t, 1127, traders[0].Y)
+
+	mf, ok := gs.Object(0, 0)
+	require.True(t, ok)
+	assert.True(t, mf.IsMinefield())
+	trader, ok := gs.ObjectOfType(ObjectTypeTrader, 0, 0)
+	require.True(t, ok)
+	assert.True(t, trader.IsTrader())
+}
@@ -23,6 +23,9 @@ type PlanetEntity struct {
 	// Name (from PlanetsBlock)
 	Name string
 	X, Y int
+	// PositionEstimated is set when X and Y were guessed by LocatePlanets
+	// because no XY file gave the planet's coordinates.
+	PositionEstimated bool
 
 	// Detection level (bits 0-6 of flags word)
 	// Determines what information is available about this planet.
@@ -68,6 +71,10 @@ type PlanetEntity struct {
 	// Starbase design slot (if HasStarbase)
 	StarbaseDesign int
 
+	// Mass driver settings (if HasStarbase, full planet data only)
+	MassDriverDest  int // Destination display ID (planet number + 1, 0 = none)
+	PacketWarpSpeed int // Raw packet fling warp (warp - blocks.PacketWarpOffset)
+
 	// Route (if has route)
 	RouteTarget int
 
@@ -291,15 +298,42 @@ func newPlanetEntityFromBlock(pb *blocks.PartialPlanetBlock, source *FileSource)
 		InstArtifact:     pb.InstArtifact,
 		NoResearch:       pb.NoResearch,
 		// Population in Stars! files is stored in 100s of colonists
-		Population:     pb.Population * 100,
-		StarbaseDesign: pb.StarbaseDesign,
-		RouteTarget:    pb.RouteTarget,
-		planetBlock:    pb,
+		Population:      pb.Population * 100,
+		StarbaseDesign:  pb.StarbaseDesign,
+		MassDriverDest:  pb.MassDriverDest,
+		PacketWarpSpeed: pb.PacketWarpSpeed,
+		RouteTarget:     pb.RouteTarget,
+		planetBlock:     pb,
 	}
 	entity.meta.AddSource(source)
 	return entity
 }
 
+// MassDriverTarget returns the planet number packets are flung to,
+// or false if no destination is set.
+func (p *PlanetEntity) MassDriverTarget() (int, bool) {
+	if p.MassDriverDest == 0 {
+		return 0, false
+	}
+	return p.MassDriverDest - 1, true
+}
+
+// PacketWarp returns the warp speed packets are flung at.
+func (p *PlanetEntity) PacketWarp() int {
+	return p.PacketWarpSpeed + blocks.PacketWarpOffset
+}
+
+// SetMassDriverTarget sets the planet number and warp packets are flung at
+// (marks dirty). A negative planet number clears the destination.
+func (p *PlanetEntity) SetMassDriverTarget(planetNumber, warp int) {
+	p.MassDriverDest = 0
+	if planetNumber >= 0 {
+		p.MassDriverDest = planetNumber + 1
+	}
+	p.PacketWarpSpeed = max(warp-blocks.PacketWarpOffset, 0)
+	p.SetDirty()
+}
+
 // SetPopulation sets the planet's population (in actual colonists, not file units).
 func (p *PlanetEntity) SetPopulation(pop int64) {
 	p.Population = pop
@@ -359,7 +393,7 @@ func (p *PlanetEntity) MaxFactories(gs *GameStore, player *PlayerEntity) int {
 // Formula: max(10, (MaxPopulation × MinesOperate) / 10000)
 // Where MaxPopulation is in actual colonists and MinesOperate is per 10k colonists.
 func (p *PlanetEntity) MaxMines(gs *GameStore, player *PlayerEntity) int {
-	if player.PRT == blocks.PRTAlternateReality {
+	if !player.BuildsInstallations() {
 		return 0 // AR races can't have mines
 	}
 	maxPop := p.MaxPopulation(gs, player)
@@ -9,26 +9,9 @@ import "github.com/neper-stars/houston/blocks"
 // Returns the value in actual colonists (same scale as PlanetEntity.Population).
 // This replicates PLANET::CalcPlanetMaxPop at MEMORY_PLANET:0x7096.
 func (gs *GameStore) MaxPopulation(planet *PlanetEntity, player *PlayerEntity) int {
-	prt := player.PRT
-
 	// AR races can only have population at planets with their own starbases
-	if prt == blocks.PRTAlternateReality {
-		if planet.Owner != player.PlayerNumber || !planet.HasStarbase {
-			return 0
-		}
-		// Max pop = starbase hull capacity × 4 (in file units)
-		// We need the starbase design to get hull capacity
-		if design, ok := gs.StarbaseDesign(player.PlayerNumber, planet.StarbaseDesign); ok {
-			if hull := design.Hull(); hull != nil {
-				// Hull BaseCapacity is stored with an offset of 0x20 in the original
-				// The formula is: (hull.baseCapacity - 0x20) * 4
-				// For simplicity, we use the hull's cargo capacity as a proxy
-				// Note: This may need adjustment based on actual hull data structure
-				// Multiply by 100 to convert from file units to actual colonists
-				return hull.CargoCapacity * 4 * 100
-			}
-		}
-		return 0
+	if player.IsAlternateReality() {
+		return gs.arMaxPopulation(planet, player)
 	}
 
 	// Standard race calculation (in file units, then converted)
@@ -42,7 +25,7 @@ func (gs *GameStore) MaxPopulation(planet *PlanetEntity, player *PlayerEntity) i
 	}
 
 	// PRT Modifiers
-	switch prt {
+	switch player.PRT {
 	case blocks.PRTHyperExpansion:
 		// HE: -50% capacity
 		maxPop -= maxPop / 2
@@ -67,7 +50,7 @@ func (gs *GameStore) MaxPopulation(planet *PlanetEntity, player *PlayerEntity) i
 // Where MaxPopulation is in actual colonists and FactoriesOperate is per 10k colonists.
 func (gs *GameStore) MaxFactories(planet *PlanetEntity, player *PlayerEntity) int {
 	// AR races can't have factories
-	if player.PRT == blocks.PRTAlternateReality {
+	if !player.BuildsInstallations() {
 		return 0
 	}
 
@@ -96,7 +79,7 @@ func (gs *GameStore) MaxFactories(planet *PlanetEntity, player *PlayerEntity) in
 // AR races return 0 (no planetary defenses).
 func (gs *GameStore) MaxDefenses(planet *PlanetEntity, player *PlayerEntity) int {
 	// AR races can't have planetary defenses
-	if player.PRT == blocks.PRTAlternateReality {
+	if !player.BuildsInstallations() {
 		return 0
 	}
 
@@ -124,7 +107,7 @@ func (gs *GameStore) MaxDefenses(planet *PlanetEntity, player *PlayerEntity) int
 // AR races return 0 (no planetary defenses).
 func (gs *GameStore) MaxOperableDefenses(planet *PlanetEntity, player *PlayerEntity) int {
 	// AR races can't have planetary defenses
-	if player.PRT == blocks.PRTAlternateReality {
+	if !player.BuildsInstallations() {
 		return 0
 	}
 
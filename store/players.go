@@ -156,9 +156,19 @@ type PlayerEntity struct {
 	Tech        TechLevels // Current tech levels
 	PRT         int        // Primary Race Trait (0-9, see blocks.PRT* constants)
 	LRT         uint16     // Lesser Race Traits bitmask (see blocks.LRT* constants)
+	MTItems     uint16     // Mystery Trader items owned (see blocks.TraderItem* constants)
 
 	// Production settings (economy parameters)
-	Production blocks.ProductionSettings
+	Production             blocks.ProductionSettings
+	FactoriesCost1LessGerm bool // Factories cost 3 kT of germanium instead of 4
+
+	// Research settings (if full data available)
+	ResearchPercentage   int                  // Share of resources spent on research
+	CurrentResearchField int                  // Field being researched (blocks.ResearchField*)
+	NextResearchField    int                  // Field researched next (blocks.ResearchField*)
+	ResearchCost         blocks.ResearchCosts // Cost modifier of each field (blocks.ResearchCost*)
+	TechProgress         blocks.TechPoints    // Resources spent toward the next level of each field
+	ResearchLastYear     int                  // Resources spent on research last year
 
 	// Habitability settings (environment preferences)
 	Hab blocks.Habitability
@@ -365,6 +375,92 @@ func (p *PlayerEntity) ChangeToAI(expertType AIExpertType) error {
 	return nil
 }
 
+// MaxTechLevel is the highest level of a technology field.
+const MaxTechLevel = 26
+
+// SharewareMaxTechLevel is the highest level of a technology field for the
+// players of an unregistered shareware copy, and for those caught cheating.
+const SharewareMaxTechLevel = 9
+
+// TechCap returns the highest tech level the player can research:
+// SharewareMaxTechLevel for crippled and cheater players, else MaxTechLevel.
+func (p *PlayerEntity) TechCap() int {
+	if p.playerBlock != nil && (p.playerBlock.Flags.Crippled || p.playerBlock.Flags.Cheater) {
+		return SharewareMaxTechLevel
+	}
+	return MaxTechLevel
+}
+
+// SetTechLevels sets the player's tech levels (0 up to TechCap in each
+// field). The research progress toward the next levels is kept.
+func (p *PlayerEntity) SetTechLevels(tech TechLevels) error {
+	if p.playerBlock == nil || !p.HasFullData {
+		return fmt.Errorf("no full player data available")
+	}
+	techCap := p.TechCap()
+	for _, level := range []int{tech.Energy, tech.Weapons, tech.Propulsion, tech.Construction, tech.Electronics, tech.Biotech} {
+		if level < 0 || level > techCap {
+			return fmt.Errorf("invalid tech level %d (must be 0-%d)", level, techCap)
+		}
+	}
+
+	p.Tech = tech
+	p.playerBlock.Tech = blocks.TechLevels{
+		Energy:       tech.Energy,
+		Weapons:      tech.Weapons,
+		Propulsion:   tech.Propulsion,
+		Construction: tech.Construction,
+		Electronics:  tech.Electronics,
+		Biotech:      tech.Biotech,
+	}
+
+	p.SetDirty()
+	return nil
+}
+
+// SetResearch sets the share of resources the player spends on research and
+// the fields researched now and next (blocks.ResearchField*).
+func (p *PlayerEntity) SetResearch(percentage, currentField, nextField int) error {
+	if p.playerBlock == nil || !p.HasFullData {
+		return fmt.Errorf("no full player data available")
+	}
+	if percentage < 0 || percentage > 100 {
+		return fmt.Errorf("invalid research percentage %d (must be 0-100)", percentage)
+	}
+	if currentField < blocks.ResearchFieldEnergy || currentField > blocks.ResearchFieldBiotechnology {
+		return fmt.Errorf("invalid research field %d", currentField)
+	}
+	if nextField < blocks.ResearchFieldEnergy || nextField > blocks.ResearchFieldSameField {
+		return fmt.Errorf("invalid next research field %d", nextField)
+	}
+
+	p.ResearchPercentage = percentage
+	p.CurrentResearchField = currentField
+	p.NextResearchField = nextField
+	p.playerBlock.ResearchPercentage = percentage
+	p.playerBlock.CurrentResearchField = currentField
+	p.playerBlock.NextResearchField = nextField
+
+	p.SetDirty()
+	return nil
+}
+
+// SetTechProgress sets the resources spent toward the next level of each
+// field and those spent on research over the last year.
+func (p *PlayerEntity) SetTechProgress(progress blocks.TechPoints, spentLastYear int) error {
+	if p.playerBlock == nil || !p.HasFullData {
+		return fmt.Errorf("no full player data available")
+	}
+
+	p.TechProgress = progress
+	p.ResearchLastYear = spentLastYear
+	p.playerBlock.TechProgress = progress
+	p.playerBlock.ResearchPointsPrevYear = uint32(spentLastYear)
+
+	p.SetDirty()
+	return nil
+}
+
 // newPlayerEntityFromBlock creates a PlayerEntity from a PlayerBlock.
 func newPlayerEntityFromBlock(pb *blocks.PlayerBlock, source *FileSource) *PlayerEntity {
 	entity := &PlayerEntity{
@@ -398,12 +494,20 @@ func newPlayerEntityFromBlock(pb *blocks.PlayerBlock, source *FileSource) *Playe
 			Electronics:  pb.Tech.Electronics,
 			Biotech:      pb.Tech.Biotech,
 		},
-		PRT:             pb.PRT,
-		LRT:             pb.LRT,
-		Production:      pb.Production,
-		Hab:             pb.Hab,
-		PlayerRelations: pb.PlayerRelations,
-		playerBlock:     pb,
+		PRT:                    pb.PRT,
+		LRT:                    pb.LRT,
+		MTItems:                pb.MTItems,
+		Production:             pb.Production,
+		FactoriesCost1LessGerm: pb.FactoriesCost1LessGerm,
+		ResearchPercentage:     pb.ResearchPercentage,
+		CurrentResearchField:   pb.CurrentResearchField,
+		NextResearchField:      pb.NextResearchField,
+		ResearchCost:           pb.ResearchCost,
+		TechProgress:           pb.TechProgress,
+		ResearchLastYear:       int(pb.ResearchPointsPrevYear),
+		Hab:                    pb.Hab,
+		PlayerRelations:        pb.PlayerRelations,
+		playerBlock:            pb,
 	}
 	entity.meta.AddSource(source)
 	return entity
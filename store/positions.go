@@ -0,0 +1,143 @@
+package store
+
+import (
+	"sort"
+
+	"github.com/neper-stars/houston/blocks"
+)
+
+const (
+	// minCoordinate is the smallest coordinate of a Stars! universe.
+	minCoordinate = 1000
+
+	// planetSpacing is the X distance in light years assumed between two
+	// consecutive planet numbers when no planet is known to measure it.
+	planetSpacing = 10
+
+	// noPositionObject is the PositionObjectId of a fleet in deep space.
+	noPositionObject = 0xFFFF
+)
+
+// LocatePlanets gives coordinates to the planets that have none, as when an
+// M or H file is loaded without its XY file, which alone holds the planet
+// positions.
+//
+// A planet orbited by a fleet, or the target of a waypoint, is put at the
+// position of the fleet or waypoint: these are exact. The other planets get
+// estimated positions, marked with PositionEstimated: planet numbers are
+// given in increasing X order, so X is interpolated between the planets
+// located around them, and Y is put midway across the located planets.
+//
+// Estimated positions are worked out again at each call, and replaced by
+// the real ones when the XY file is loaded later. LocatePlanets returns the
+// number of planets placed exactly and of planets estimated.
+func (gs *GameStore) LocatePlanets() (located, estimated int) {
+	var missing []*PlanetEntity
+	for _, planet := range gs.Planets.All() {
+		if planet.PositionEstimated || (planet.X == 0 && planet.Y == 0) {
+			missing = append(missing, planet)
+		}
+	}
+	if len(missing) == 0 {
+		return 0, 0
+	}
+
+	seen := gs.seenPlanetPositions()
+	var rest []*PlanetEntity
+	for _, planet := range missing {
+		if pos, ok := seen[planet.PlanetNumber]; ok {
+			planet.X, planet.Y = pos[0], pos[1]
+			planet.PositionEstimated = false
+			located++
+		} else {
+			rest = append(rest, planet)
+		}
+	}
+	if len(rest) == 0 {
+		return located, 0
+	}
+
+	anchors, y := gs.planetAnchors()
+	for _, planet := range rest {
+		planet.X = max(estimateX(anchors, planet.PlanetNumber), minCoordinate)
+		planet.Y = y
+		planet.PositionEstimated = true
+	}
+	return located, len(rest)
+}
+
+// seenPlanetPositions returns the positions of the planets orbited by a
+// fleet or targeted by a waypoint, by planet number.
+func (gs *GameStore) seenPlanetPositions() map[int][2]int {
+	seen := make(map[int][2]int)
+	for _, fleet := range gs.Fleets.All() {
+		if fleet.PositionObjectId != noPositionObject && (fleet.X != 0 || fleet.Y != 0) {
+			seen[fleet.PositionObjectId] = [2]int{fleet.X, fleet.Y}
+		}
+		for _, wp := range fleet.Waypoints {
+			if wp.PositionObjectType&0x0F == blocks.WaypointTargetPlanet && (wp.X != 0 || wp.Y != 0) {
+				seen[wp.PositionObject] = [2]int{wp.X, wp.Y}
+			}
+		}
+	}
+	return seen
+}
+
+// anchor is a planet whose position is known.
+type anchor struct {
+	number, x int
+}
+
+// planetAnchors returns the planets with a known position sorted by number,
+// and the Y midway across them.
+func (gs *GameStore) planetAnchors() ([]anchor, int) {
+	byNumber := make(map[int]int)
+	minY, maxY := 0, 0
+	for _, planet := range gs.Planets.All() {
+		if planet.PositionEstimated || (planet.X == 0 && planet.Y == 0) {
+			continue
+		}
+		if len(byNumber) == 0 || planet.Y < minY {
+			minY = planet.Y
+		}
+		if len(byNumber) == 0 || planet.Y > maxY {
+			maxY = planet.Y
+		}
+		byNumber[planet.PlanetNumber] = planet.X
+	}
+	if len(byNumber) == 0 {
+		return nil, minCoordinate
+	}
+
+	anchors := make([]anchor, 0, len(byNumber))
+	for number, x := range byNumber {
+		anchors = append(anchors, anchor{number, x})
+	}
+	sort.Slice(anchors, func(i, j int) bool { return anchors[i].number < anchors[j].number })
+	return anchors, (minY + maxY) / 2
+}
+
+// estimateX returns the X of a planet number, interpolated between the
+// anchors around it, or extrapolated from the nearest one.
+func estimateX(anchors []anchor, number int) int {
+	if len(anchors) == 0 {
+		return minCoordinate + planetSpacing*number
+	}
+	i := sort.Search(len(anchors), func(i int) bool { return anchors[i].number > number })
+	if i > 0 && i < len(anchors) {
+		a, b := anchors[i-1], anchors[i]
+		return a.x + (b.x-a.x)*(number-a.number)/(b.number-a.number)
+	}
+
+	// Outside the anchors: go on at the mean spacing of the planets known
+	first, last := anchors[0], anchors[len(anchors)-1]
+	spacing := float64(planetSpacing)
+	if last.number > first.number {
+		spacing = float64(last.x-first.x) / float64(last.number-first.number)
+	}
+	nearest := first
+	if i > 0 {
+		nearest = last
+	}
+	return nearest.x + int(spacing*float64(number-nearest.number))
+}
@@ -0,0 +1,62 @@
+package store_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func TestLocatePlanets(t *testing.T) {
+	load := func(names ...string) *store.GameStore {
+		gs := store.New()
+		for _, name := range names {
+			data, err := os.ReadFile("../testdata/scenario-map/" + name)
+			require.NoError(t, err)
+			require.NoError(t, gs.AddFile(name, data))
+		}
+		return gs
+	}
+	real := load("game.xy", "game.m1")
+	gs := load("game.m1")
+
+	located, estimated := gs.LocatePlanets()
+	require.NotZero(t, located)
+	assert.Equal(t, len(gs.AllPlanets()), located+estimated)
+
+	for _, planet := range gs.AllPlanets() {
+		want, ok := real.Planet(planet.PlanetNumber)
+		require.True(t, ok)
+		assert.GreaterOrEqual(t, planet.X, 1000, planet.Name)
+		if !planet.PositionEstimated {
+			assert.Equal(t, [2]int{want.X, want.Y}, [2]int{planet.X, planet.Y}, planet.Name)
+		}
+	}
+
+	// Loading the XY file afterwards replaces the estimates
+	data, err := os.ReadFile("../testdata/scenario-map/game.xy")
+	require.NoError(t, err)
+	require.NoError(t, gs.AddFile("game.xy", data))
+	for _, planet := range gs.AllPlanets() {
+		want, _ := real.Planet(planet.PlanetNumber)
+		assert.False(t, planet.PositionEstimated, planet.Name)
+		assert.Equal(t, [2]int{want.X, want.Y}, [2]int{planet.X, planet.Y}, planet.Name)
+	}
+	assert.Len(t, gs.AllPlanets(), len(real.AllPlanets()))
+}
+
+func TestAddFileWithXYLocatesPlanets(t *testing.T) {
+	dir := t.TempDir()
+	data, err := os.ReadFile("../testdata/scenario-map/game.m1")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dir+"/game.m1", data, 0o600))
+
+	gs := store.New()
+	require.NoError(t, gs.AddFileWithXY(dir+"/game.m1"))
+	for _, planet := range gs.AllPlanets() {
+		assert.False(t, planet.X == 0 && planet.Y == 0, planet.Name)
+	}
+}
@@ -81,6 +81,12 @@ func (pq *ProductionQueueEntity) Clear() {
 	pq.SetDirty()
 }
 
+// SetItems replaces the items of the queue (marks dirty).
+func (pq *ProductionQueueEntity) SetItems(items []ProductionItem) {
+	pq.Items = items
+	pq.SetDirty()
+}
+
 // newProductionQueueEntityFromBlock creates a ProductionQueueEntity from a ProductionQueueBlock.
 func newProductionQueueEntityFromBlock(pqb *blocks.ProductionQueueBlock, planetNumber int, source *FileSource) *ProductionQueueEntity {
 	items := make([]ProductionItem, len(pqb.Items))
@@ -10,7 +10,7 @@ import (
 )
 
 var (
-	ErrNotRaceFile   = errors.New("not a race file")
+	ErrNotRaceFile   = race.ErrNotRaceFile
 	ErrNoPlayerBlock = errors.New("no player block found in race file")
 )
 
@@ -1,14 +1,37 @@
 package store
 
 import (
+	"bytes"
 	"os"
 	"testing"
 
 	"github.com/neper-stars/houston/blocks"
 	"github.com/neper-stars/houston/parser"
 	"github.com/neper-stars/houston/race"
+	"github.com/neper-stars/houston/reproducible"
 )
 
+func TestCreateRaceFile_Reproducible(t *testing.T) {
+	reproducible.Enable()
+	t.Cleanup(reproducible.Disable)
+
+	r, err := race.New(race.WithName("Klingon", "Klingons")).Finish()
+	if err != nil {
+		t.Fatalf("Failed to finish race: %v", err)
+	}
+	first, err := CreateRaceFile(r, 1)
+	if err != nil {
+		t.Fatalf("Failed to create race file: %v", err)
+	}
+	second, err := CreateRaceFile(r, 1)
+	if err != nil {
+		t.Fatalf("Failed to create race file: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("race files of the same race differ in reproducible mode")
+	}
+}
+
 func TestCreateRaceFile(t *testing.T) {
 	// Create a race using the builder - use default Humanoid which should be valid
 	builder := race.New()
@@ -124,7 +124,6 @@ func (gs *GameStore) CResourcesAtPlanet(planet *PlanetEntity, player *PlayerEnti
 	// factoriesOperate = rgAttr[3] - Factories operable per 100 colonists
 	popEfficiency := player.Production.ResourcePerColonist
 	factEfficiency := player.Production.FactoryProduction
-	prt := player.PRT
 
 	// Convert population to file units (100s of colonists) for calculation
 	// The original game stores and calculates with this scale
@@ -142,17 +141,10 @@ func (gs *GameStore) CResourcesAtPlanet(planet *PlanetEntity, player *PlayerEnti
 	var resources int
 
 	// Step 4: Resource Calculation (Two Paths)
-	if prt == blocks.PRTAlternateReality {
+	if player.IsAlternateReality() {
 		// Path A: Alternate Reality (AR) Race
 		// AR races don't use factories - they use orbital bases instead
-		// resources = floor(sqrt((energyTech × population) / popEfficiency))
-		energyTech := player.Tech.Energy
-		if energyTech < 1 {
-			energyTech = 1
-		}
-		if popEfficiency > 0 {
-			resources = int(math.Sqrt(float64(energyTech) * float64(effectivePop) / float64(popEfficiency)))
-		}
+		resources = arResources(player, effectivePop, popEfficiency)
 	} else {
 		// Path B: Standard Races (All Other PRTs)
 		// popContribution = population / popEfficiency
@@ -196,7 +188,7 @@ func (gs *GameStore) CResourcesAtPlanet(planet *PlanetEntity, player *PlayerEnti
 // Note: Population is in file units (100s of colonists) for this calculation.
 func (gs *GameStore) CMaxOperableFactories(planet *PlanetEntity, player *PlayerEntity) int {
 	// AR races can't operate factories
-	if player.PRT == blocks.PRTAlternateReality {
+	if !player.BuildsInstallations() {
 		return 0
 	}
 
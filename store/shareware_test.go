@@ -0,0 +1,103 @@
+package store
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+)
+
+// sharewareCopy returns a file rewritten as a shareware copy of Stars!
+// would have written it: same blocks, crippled header, shareware key.
+func sharewareCopy(t *testing.T, name string, original []byte, generate func(*GameStore) ([]byte, error)) []byte {
+	t.Helper()
+	gs := New()
+	require.NoError(t, gs.AddFile(name, original))
+	gs.Sources()[0].Header.SetCrippled(true)
+	data, err := generate(gs)
+	require.NoError(t, err)
+	return data
+}
+
+func TestShareware_MFile(t *testing.T) {
+	original, err := os.ReadFile("../testdata/scenario-basic/game.m1")
+	require.NoError(t, err)
+	shareware := sharewareCopy(t, "game.m1", original, func(gs *GameStore) ([]byte, error) {
+		return gs.GenerateMFile(0)
+	})
+
+	// The shareware flag is part of the encryption key
+	require.Len(t, shareware, len(original))
+	assert.NotEqual(t, original[18:], shareware[18:])
+
+	gs := New()
+	require.NoError(t, gs.AddFile("game.m1", shareware))
+	header := gs.Sources()[0].Header
+	assert.True(t, header.Crippled())
+	assert.Equal(t, 1, header.SharewareFlag())
+
+	reference := New()
+	require.NoError(t, reference.AddFile("game.m1", original))
+	assert.Equal(t, len(reference.AllPlanets()), len(gs.AllPlanets()))
+	assert.Equal(t, len(reference.AllFleets()), len(gs.AllFleets()))
+	player, ok := gs.Player(0)
+	require.True(t, ok)
+	want, _ := reference.Player(0)
+	assert.Equal(t, want.NamePlural, player.NamePlural)
+	assert.Equal(t, want.Tech, player.Tech)
+
+	// Shareware files round-trip, and clearing the flag gives the original
+	again, err := gs.GenerateMFile(0)
+	require.NoError(t, err)
+	assert.Equal(t, shareware, again)
+
+	header.SetCrippled(false)
+	registered, err := gs.GenerateMFile(0)
+	require.NoError(t, err)
+	assert.Equal(t, original, registered)
+}
+
+func TestShareware_RFile(t *testing.T) {
+	original, err := os.ReadFile("../testdata/scenario-racefiles/race1-nopassword.r2")
+	require.NoError(t, err)
+	shareware := sharewareCopy(t, "race1.r2", original, func(gs *GameStore) ([]byte, error) {
+		return gs.GenerateRFile(blocks.RaceFilePlayerIndex)
+	})
+	assert.NotEqual(t, original, shareware)
+
+	gs := New()
+	require.NoError(t, gs.AddFile("race1.r2", shareware))
+	assert.True(t, gs.Sources()[0].Header.Crippled())
+
+	pb, ok := gs.Sources()[0].Blocks[1].(blocks.PlayerBlock)
+	require.True(t, ok)
+	assert.Equal(t, "race1", pb.NameSingular)
+	assert.Equal(t, "race1s", pb.NamePlural)
+}
+
+func TestPlayerEntity_TechCap(t *testing.T) {
+	data, err := os.ReadFile("../testdata/scenario-basic/game.m1")
+	require.NoError(t, err)
+	gs := New()
+	require.NoError(t, gs.AddFile("game.m1", data))
+	player, ok := gs.Player(0)
+	require.True(t, ok)
+	require.True(t, player.HasFullData)
+	assert.Equal(t, MaxTechLevel, player.TechCap())
+
+	player.playerBlock.Flags.Crippled = true
+	assert.Equal(t, SharewareMaxTechLevel, player.TechCap())
+
+	tech := player.Tech
+	tech.Energy = SharewareMaxTechLevel + 1
+	assert.Error(t, player.SetTechLevels(tech))
+	tech.Energy = SharewareMaxTechLevel
+	assert.NoError(t, player.SetTechLevels(tech))
+
+	player.playerBlock.Flags.Crippled = false
+	player.playerBlock.Flags.Cheater = true
+	assert.Equal(t, SharewareMaxTechLevel, player.TechCap())
+}
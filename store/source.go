@@ -1,10 +1,10 @@
 package store
 
 import (
-	"strings"
 	"time"
 
 	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/filenames"
 	"github.com/neper-stars/houston/parser"
 )
 
@@ -56,42 +56,32 @@ type FileSource struct {
 
 // DetectFileType determines the file type from the filename.
 func DetectFileType(filename string) FileSourceType {
-	lower := strings.ToLower(filename)
-
-	// Check for .xy and .hst first (before .x* and .h* checks)
-	if strings.HasSuffix(lower, ".xy") {
+	switch filenames.KindOf(filename) {
+	case filenames.M:
+		return SourceTypeMFile
+	case filenames.X:
+		return SourceTypeXFile
+	case filenames.H:
+		return SourceTypeHFile
+	case filenames.XY:
 		return SourceTypeXYFile
-	}
-	if strings.HasSuffix(lower, ".hst") {
+	case filenames.R:
+		return SourceTypeRFile
+	case filenames.HST:
 		return SourceTypeHSTFile
 	}
-
-	// Check for .m*, .x*, .h*, .r* patterns
-	for i := len(lower) - 1; i >= 0; i-- {
-		if lower[i] == '.' {
-			if i+1 < len(lower) {
-				switch lower[i+1] {
-				case 'm':
-					return SourceTypeMFile
-				case 'x':
-					return SourceTypeXFile
-				case 'h':
-					return SourceTypeHFile
-				case 'r':
-					return SourceTypeRFile
-				}
-			}
-			break
-		}
-	}
-
 	return SourceTypeUnknown
 }
 
 // ParseSource parses raw file data into a FileSource.
 func ParseSource(id string, data []byte) (*FileSource, error) {
-	fd := parser.FileData(data)
-	blockList, err := fd.BlockList()
+	return ParseSourceCached(id, data, nil)
+}
+
+// ParseSourceCached is ParseSource reusing the blocks cached for identical
+// file contents. A nil cache parses without caching.
+func ParseSourceCached(id string, data []byte, cache *parser.Cache) (*FileSource, error) {
+	blockList, err := cache.BlockList(data)
 	if err != nil {
 		return nil, err
 	}
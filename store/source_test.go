@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/neper-stars/houston/blocks"
 	"github.com/neper-stars/houston/store"
 )
 
@@ -107,3 +108,16 @@ func TestHSTFile_ParseAndRegenerate(t *testing.T) {
 	assert.Equal(t, len(sources[0].Blocks), len(sources2[0].Blocks),
 		"regenerated file should have the same number of blocks")
 }
+
+func TestBlocksOfType(t *testing.T) {
+	gs := store.New()
+	require.NoError(t, gs.AddFileWithXY("../testdata/scenario-map/history/game-2440.m1"))
+	require.NoError(t, gs.AddFileWithXY("../testdata/scenario-map/history/game-2440.m2"))
+
+	headers := gs.BlocksOfType(blocks.FileHeaderBlockType)
+	require.Len(t, headers, 3, "one header per M file and one for the XY file")
+	for _, b := range headers {
+		assert.IsType(t, blocks.FileHeader{}, b)
+	}
+	assert.Empty(t, gs.BlocksOfType(blocks.UnknownBlock15BlockType))
+}
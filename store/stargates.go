@@ -0,0 +1,115 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+)
+
+// ErrNoStargate is returned when a planet of a gate trip has no stargate.
+var ErrNoStargate = errors.New("no stargate")
+
+// GetStargate returns the stargate of a starbase design, or nil if it has none.
+func (d *DesignEntity) GetStargate() *data.Orbital {
+	for _, item := range d.ItemsByCategory(blocks.ItemCategoryOrbital) {
+		if orbital := data.GetOrbital(item.ItemID); orbital != nil && orbital.IsStargate {
+			return orbital
+		}
+	}
+	return nil
+}
+
+// Stargate returns the stargate orbiting a planet, or nil if there is none.
+func (gs *GameStore) Stargate(planet *PlanetEntity) *data.Orbital {
+	if !planet.HasStarbase || !planet.IsOwned() {
+		return nil
+	}
+	design, ok := gs.StarbaseDesign(planet.Owner, planet.StarbaseDesign)
+	if !ok {
+		return nil
+	}
+	return design.GetStargate()
+}
+
+// GateTrip is a fleet jump between two stargates.
+type GateTrip struct {
+	Mass     int // Heaviest ship of the fleet (kT)
+	Distance int // Light years between the planets
+
+	// Limits of the trip: the mass limit of the source gate and the larger
+	// range of the two gates; -1 when unlimited.
+	MassLimit  int
+	RangeLimit int
+
+	// CargoBlocked is set when the fleet carries cargo and its race cannot
+	// gate with cargo (only Interstellar Travelers can).
+	CargoBlocked bool
+
+	// SafetyBonus is set for Interstellar Travelers, whose ships are less
+	// likely to be lost when exceeding the limits.
+	SafetyBonus bool
+}
+
+// MassOverload returns how many times the mass limit the heaviest ship
+// weighs, or 0 within the limit.
+func (t GateTrip) MassOverload() float64 {
+	if t.MassLimit < 0 || t.Mass <= t.MassLimit {
+		return 0
+	}
+	return float64(t.Mass) / float64(t.MassLimit)
+}
+
+// RangeOverload returns how many times the range limit the trip covers, or
+// 0 within the limit.
+func (t GateTrip) RangeOverload() float64 {
+	if t.RangeLimit < 0 || t.Distance <= t.RangeLimit {
+		return 0
+	}
+	return float64(t.Distance) / float64(t.RangeLimit)
+}
+
+// Overgating returns true if the trip exceeds a limit of the gates, risking
+// the loss of ships.
+func (t GateTrip) Overgating() bool {
+	return t.MassOverload() > 0 || t.RangeOverload() > 0
+}
+
+// PlanGateTrip describes the jump of a fleet from one stargate to another.
+func (gs *GameStore) PlanGateTrip(f *FleetEntity, from, to *PlanetEntity) (GateTrip, error) {
+	source, target := gs.Stargate(from), gs.Stargate(to)
+	if source == nil {
+		return GateTrip{}, fmt.Errorf("%w at %s", ErrNoStargate, gs.PlanetName(from.PlanetNumber))
+	}
+	if target == nil {
+		return GateTrip{}, fmt.Errorf("%w at %s", ErrNoStargate, gs.PlanetName(to.PlanetNumber))
+	}
+
+	trip := GateTrip{
+		Distance:  int(math.Round(math.Hypot(float64(to.X-from.X), float64(to.Y-from.Y)))),
+		MassLimit: source.MassLimit,
+	}
+	switch {
+	case source.RangeLimit < 0 || target.RangeLimit < 0:
+		trip.RangeLimit = -1
+	default:
+		trip.RangeLimit = max(source.RangeLimit, target.RangeLimit)
+	}
+	for _, info := range f.GetDesigns(gs) {
+		if info.Design != nil && info.Count > 0 {
+			trip.Mass = max(trip.Mass, info.Design.GetMass())
+		}
+	}
+
+	if player, ok := gs.Player(f.Owner); ok {
+		if prt := data.GetPRT(player.PRT); prt != nil {
+			cargo := f.GetCargo()
+			carrying := cargo.Ironium+cargo.Boranium+cargo.Germanium+cargo.Population > 0
+			trip.CargoBlocked = carrying && !prt.CanGateCargo
+			trip.SafetyBonus = prt.StargateSafetyBonus
+		}
+	}
+	return trip, nil
+}
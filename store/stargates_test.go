@@ -0,0 +1,101 @@
+package store_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/store"
+)
+
+func TestPlanGateTrip(t *testing.T) {
+	// Player 3 is an Interstellar Traveler with a Stargate 100/250 at home
+	gs := store.New()
+	for _, name := range []string{"game.xy", "game.m3"} {
+		data, err := os.ReadFile("../testdata/scenario-diplomacy-3way/1/side3/" + name)
+		require.NoError(t, err)
+		require.NoError(t, gs.AddFile(name, data))
+	}
+
+	home, ok := gs.Planet(9)
+	require.True(t, ok)
+	gate := gs.Stargate(home)
+	require.NotNil(t, gate)
+	assert.Equal(t, "Stargate 100/250", gate.Name)
+
+	fleets := make(map[string]*store.FleetEntity)
+	for _, f := range gs.FleetsByOwner(2) {
+		for _, info := range f.GetDesigns(gs) {
+			fleets[info.Design.Name] = f
+		}
+	}
+
+	scout := fleets["Smaugarian Peeping Tom"]
+	require.NotNil(t, scout)
+	trip, err := gs.PlanGateTrip(scout, home, home)
+	require.NoError(t, err)
+	assert.Equal(t, 100, trip.MassLimit)
+	assert.Equal(t, 250, trip.RangeLimit)
+	assert.Equal(t, 30, trip.Mass)
+	assert.False(t, trip.Overgating())
+	assert.True(t, trip.SafetyBonus)
+
+	warship := fleets["Stalwart Defender"]
+	require.NotNil(t, warship)
+	trip, err = gs.PlanGateTrip(warship, home, home)
+	require.NoError(t, err)
+	assert.True(t, trip.Overgating())
+	assert.InDelta(t, 1.91, trip.MassOverload(), 0.001)
+	assert.Zero(t, trip.RangeOverload())
+
+	// IT fleets may gate with cargo, other races may not
+	scout.SetCargo(store.Cargo{Ironium: 10})
+	trip, err = gs.PlanGateTrip(scout, home, home)
+	require.NoError(t, err)
+	assert.False(t, trip.CargoBlocked)
+	player, ok := gs.Player(2)
+	require.True(t, ok)
+	player.PRT = blocks.PRTJackOfAllTrades
+	trip, err = gs.PlanGateTrip(scout, home, home)
+	require.NoError(t, err)
+	assert.True(t, trip.CargoBlocked)
+	assert.False(t, trip.SafetyBonus)
+
+	other, ok := gs.Planet(0)
+	require.True(t, ok)
+	_, err = gs.PlanGateTrip(scout, home, other)
+	assert.ErrorIs(t, err, store.ErrNoStargate)
+
+	// None of these designs carries a thief scanner
+	for _, f := range gs.FleetsByOwner(2) {
+		fleets, planets := gs.TheftTargets(f)
+		assert.Empty(t, fleets)
+		assert.Empty(t, planets)
+	}
+}
+
+func TestPacketScanRange(t *testing.T) {
+	// The packet owner is a JOAT race: its packets carry no scanner
+	data, err := os.ReadFile("../testdata/scenario-mineral-packet/game.m1")
+	require.NoError(t, err)
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game.m1", data))
+
+	var packet *store.ObjectEntity
+	for _, o := range gs.Objects.All() {
+		if o.IsPacket() {
+			packet = o
+		}
+	}
+	require.NotNil(t, packet)
+	assert.Equal(t, 7, packet.PacketWarp())
+	assert.Zero(t, packet.PacketScanRange(gs))
+
+	player, ok := gs.Player(packet.Owner)
+	require.True(t, ok)
+	player.PRT = blocks.PRTPacketPhysics
+	assert.Equal(t, 49, packet.PacketScanRange(gs))
+}
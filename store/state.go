@@ -0,0 +1,855 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/neper-stars/houston/blocks"
+)
+
+// StateVersion is the version of the GameState format written by
+// ExportState.
+const StateVersion = 1
+
+// ErrStateVersion is returned when importing a state written by a newer
+// version of the format.
+var ErrStateVersion = errors.New("unsupported game state version")
+
+// GameState is the game data of a store as plain values, for tools and web
+// frontends that read game data as JSON rather than parsing Stars! files.
+// Players are numbered from 0, as in the store.
+type GameState struct {
+	Version    int              `json:"version"`
+	GameID     uint32           `json:"game_id"`
+	GameName   string           `json:"game_name,omitempty"`
+	Turn       uint16           `json:"turn"`
+	Year       int              `json:"year"` // Informative, derived from Turn
+	Universe   UniverseState    `json:"universe"`
+	Players    []PlayerState    `json:"players"`
+	Planets    []PlanetState    `json:"planets"`
+	Fleets     []FleetState     `json:"fleets"`
+	Designs    []DesignState    `json:"designs"`
+	Minefields []MinefieldState `json:"minefields"`
+	Wormholes  []WormholeState  `json:"wormholes"`
+	Messages   []MessageState   `json:"messages"`
+}
+
+// UniverseState holds the game settings of the planet table.
+type UniverseState struct {
+	Size             uint16 `json:"size"`    // 0=Tiny to 4=Huge
+	Density          uint16 `json:"density"` // 0=Sparse to 3=Packed
+	PlayerCount      uint16 `json:"player_count"`
+	PlanetCount      uint16 `json:"planet_count"`
+	StartingDistance uint16 `json:"starting_distance"`
+	GameSettings     uint16 `json:"game_settings"` // blocks.GameSetting* bitmask
+}
+
+// TechFields holds a value for each research field.
+type TechFields struct {
+	Energy       int `json:"energy"`
+	Weapons      int `json:"weapons"`
+	Propulsion   int `json:"propulsion"`
+	Construction int `json:"construction"`
+	Electronics  int `json:"electronics"`
+	Biotech      int `json:"biotech"`
+}
+
+// HabState holds the habitability ranges of a race. 255 centers are immune.
+type HabState struct {
+	GravityCenter     int `json:"gravity_center"`
+	GravityLow        int `json:"gravity_low"`
+	GravityHigh       int `json:"gravity_high"`
+	TemperatureCenter int `json:"temperature_center"`
+	TemperatureLow    int `json:"temperature_low"`
+	TemperatureHigh   int `json:"temperature_high"`
+	RadiationCenter   int `json:"radiation_center"`
+	RadiationLow      int `json:"radiation_low"`
+	RadiationHigh     int `json:"radiation_high"`
+}
+
+// ProductionState holds the economy settings of a race.
+type ProductionState struct {
+	ResourcePerColonist int `json:"resource_per_colonist"`
+	FactoryProduction   int `json:"factory_production"`
+	FactoryCost         int `json:"factory_cost"`
+	FactoriesOperate    int `json:"factories_operate"`
+	MineProduction      int `json:"mine_production"`
+	MineCost            int `json:"mine_cost"`
+	MinesOperate        int `json:"mines_operate"`
+}
+
+// ScoreState is the score of a player as computed by Stars!.
+type ScoreState struct {
+	Score        int   `json:"score"`
+	Resources    int64 `json:"resources"`
+	Planets      int   `json:"planets"`
+	Starbases    int   `json:"starbases"`
+	UnarmedShips int   `json:"unarmed_ships"`
+	EscortShips  int   `json:"escort_ships"`
+	CapitalShips int   `json:"capital_ships"`
+	TechLevels   int   `json:"tech_levels"`
+	Rank         int   `json:"rank"`
+	Turn         int   `json:"turn"`
+}
+
+// PlayerState is a player and, when known, its race.
+type PlayerState struct {
+	Number              int         `json:"number"`
+	Quality             DataQuality `json:"quality"`
+	NamePlural          string      `json:"name_plural"`
+	NameSingular        string      `json:"name_singular"`
+	Logo                int         `json:"logo"`
+	ShipDesignCount     int         `json:"ship_design_count"`
+	StarbaseDesignCount int         `json:"starbase_design_count"`
+	PlanetCount         int         `json:"planet_count"`
+	FleetCount          int         `json:"fleet_count"`
+	HomePlanet          int         `json:"home_planet"`
+	Rank                int         `json:"rank"`
+	Relations           []int       `json:"relations,omitempty"` // 0 neutral, 1 friend, 2 enemy
+
+	// Race and research, for players with full data
+	FullData             bool            `json:"full_data"`
+	GrowthRate           int             `json:"growth_rate,omitempty"`
+	PRT                  int             `json:"prt,omitempty"`
+	LRT                  uint16          `json:"lrt,omitempty"`
+	MTItems              uint16          `json:"mt_items,omitempty"`
+	Tech                 TechFields      `json:"tech"`
+	Hab                  HabState        `json:"hab"`
+	Production           ProductionState `json:"production"`
+	ResearchPercentage   int             `json:"research_percentage,omitempty"`
+	CurrentResearchField int             `json:"current_research_field,omitempty"`
+	NextResearchField    int             `json:"next_research_field,omitempty"`
+	ResearchCost         TechFields      `json:"research_cost"`
+	TechProgress         TechFields      `json:"tech_progress"`
+	ResearchLastYear     int             `json:"research_last_year,omitempty"`
+
+	Score *ScoreState `json:"score,omitempty"`
+}
+
+// PlanetState is a planet as seen in the loaded files.
+type PlanetState struct {
+	Number         int         `json:"number"`
+	Quality        DataQuality `json:"quality"`
+	Name           string      `json:"name"`
+	X              int         `json:"x"`
+	Y              int         `json:"y"`
+	Estimated      bool        `json:"position_estimated,omitempty"`
+	Owner          int         `json:"owner"` // -1 when unowned
+	Homeworld      bool        `json:"homeworld,omitempty"`
+	DetectionLevel int         `json:"detection_level"`
+
+	Include          bool `json:"include,omitempty"`
+	HasStarbase      bool `json:"has_starbase,omitempty"`
+	HasArtifact      bool `json:"has_artifact,omitempty"`
+	Terraformed      bool `json:"terraformed,omitempty"`
+	HasInstallations bool `json:"has_installations,omitempty"`
+	FirstYear        bool `json:"first_year,omitempty"`
+
+	IroniumConc     int `json:"ironium_conc"`
+	BoraniumConc    int `json:"boranium_conc"`
+	GermaniumConc   int `json:"germanium_conc"`
+	Gravity         int `json:"gravity"`
+	Temperature     int `json:"temperature"`
+	Radiation       int `json:"radiation"`
+	OrigGravity     int `json:"orig_gravity,omitempty"`
+	OrigTemperature int `json:"orig_temperature,omitempty"`
+	OrigRadiation   int `json:"orig_radiation,omitempty"`
+
+	Ironium    int64 `json:"ironium"`
+	Boranium   int64 `json:"boranium"`
+	Germanium  int64 `json:"germanium"`
+	Population int64 `json:"population"`
+
+	Mines        int  `json:"mines"`
+	Factories    int  `json:"factories"`
+	Defenses     int  `json:"defenses"`
+	DeltaPop     int  `json:"delta_pop,omitempty"`
+	ScannerID    int  `json:"scanner_id,omitempty"`
+	InstArtifact bool `json:"inst_artifact,omitempty"`
+	NoResearch   bool `json:"no_research,omitempty"`
+
+	StarbaseDesign  int `json:"starbase_design,omitempty"`
+	MassDriverDest  int `json:"mass_driver_dest,omitempty"` // Planet number + 1, 0 for none
+	PacketWarpSpeed int `json:"packet_warp_speed,omitempty"`
+	RouteTarget     int `json:"route_target,omitempty"`
+}
+
+// FleetState is a fleet and its orders.
+type FleetState struct {
+	Number           int         `json:"number"`
+	Owner            int         `json:"owner"`
+	Quality          DataQuality `json:"quality"`
+	Name             string      `json:"name,omitempty"` // Custom name only
+	X                int         `json:"x"`
+	Y                int         `json:"y"`
+	PositionObjectID int         `json:"position_object_id"`
+	ShipCounts       [16]int     `json:"ship_counts"` // By design slot
+
+	Ironium    int64 `json:"ironium"`
+	Boranium   int64 `json:"boranium"`
+	Germanium  int64 `json:"germanium"`
+	Population int64 `json:"population"` // Colonists
+	Fuel       int64 `json:"fuel"`
+
+	DeltaX             int   `json:"delta_x,omitempty"`
+	DeltaY             int   `json:"delta_y,omitempty"`
+	Warp               int   `json:"warp"`
+	Mass               int64 `json:"mass,omitempty"`
+	DirectionValid     bool  `json:"direction_valid,omitempty"`
+	CompositionChanged bool  `json:"composition_changed,omitempty"`
+	Targeted           bool  `json:"targeted,omitempty"`
+	Skipped            bool  `json:"skipped,omitempty"`
+
+	DamagedShipTypes uint16     `json:"damaged_ship_types,omitempty"`
+	DamagedShipInfo  [16]uint16 `json:"damaged_ship_info"`
+	BattlePlan       int        `json:"battle_plan"`
+	WaypointCount    int        `json:"waypoint_count"`
+	Include          bool       `json:"include,omitempty"`
+	RepeatOrders     bool       `json:"repeat_orders,omitempty"`
+	Dead             bool       `json:"dead,omitempty"`
+
+	Waypoints []WaypointState `json:"waypoints,omitempty"`
+}
+
+// WaypointState is a waypoint of a fleet.
+type WaypointState struct {
+	X               int                                                 `json:"x"`
+	Y               int                                                 `json:"y"`
+	PositionObject  int                                                 `json:"position_object"`
+	Warp            int                                                 `json:"warp"`
+	Task            int                                                 `json:"task"` // blocks.WaypointTask*
+	TransportOrders [blocks.TransportCargoTypeCount]TransportOrderState `json:"transport_orders"`
+	TaskData        []byte                                              `json:"task_data,omitempty"`
+}
+
+// TransportOrderState is the transport order of a cargo type.
+type TransportOrderState struct {
+	Action int `json:"action"` // blocks.TransportTask*
+	Value  int `json:"value"`
+}
+
+// DesignState is a ship or starbase design. Designs of other players seen in
+// scans carry no components.
+type DesignState struct {
+	Number         int               `json:"number"`
+	Owner          int               `json:"owner"`
+	Quality        DataQuality       `json:"quality"`
+	Starbase       bool              `json:"starbase,omitempty"`
+	Name           string            `json:"name"`
+	Hull           int               `json:"hull"`
+	Picture        int               `json:"picture"`
+	Full           bool              `json:"full"`
+	Transferred    bool              `json:"transferred,omitempty"`
+	Mass           int               `json:"mass,omitempty"` // Designs without components
+	Armor          int               `json:"armor,omitempty"`
+	TurnDesigned   int               `json:"turn_designed,omitempty"`
+	TotalBuilt     int64             `json:"total_built,omitempty"`
+	TotalRemaining int64             `json:"total_remaining,omitempty"`
+	Slots          []DesignSlotState `json:"slots,omitempty"`
+}
+
+// DesignSlotState is a hull slot of a design and what it holds.
+type DesignSlotState struct {
+	Category uint16 `json:"category"` // blocks.ItemCategory* of the item, or of the empty slot
+	Item     int    `json:"item"`
+	Count    int    `json:"count"`
+}
+
+// MinefieldState is a minefield.
+type MinefieldState struct {
+	Number     int         `json:"number"`
+	Owner      int         `json:"owner"`
+	Quality    DataQuality `json:"quality"`
+	X          int         `json:"x"`
+	Y          int         `json:"y"`
+	MineCount  int64       `json:"mine_count"`
+	Type       int         `json:"type"` // 0 standard, 1 heavy, 2 speed bump
+	Detonating bool        `json:"detonating,omitempty"`
+	SeeBits    uint16      `json:"see_bits"`
+}
+
+// WormholeState is a wormhole.
+type WormholeState struct {
+	Number         int         `json:"number"`
+	Quality        DataQuality `json:"quality"`
+	X              int         `json:"x"`
+	Y              int         `json:"y"`
+	WormholeID     int         `json:"wormhole_id"`
+	TargetID       int         `json:"target_id"`
+	CanSeeBits     uint16      `json:"can_see_bits"`
+	BeenThrough    uint16      `json:"been_through_bits"`
+	Stability      int         `json:"stability"`
+	TurnsSinceMove int         `json:"turns_since_move"`
+	DestKnown      bool        `json:"dest_known,omitempty"`
+	Include        bool        `json:"include,omitempty"`
+}
+
+// MessageState is a player message.
+type MessageState struct {
+	Number    int    `json:"number"`
+	Sender    int    `json:"sender"`
+	Receiver  int    `json:"receiver"` // 0 for everyone, else player number + 1
+	InReplyTo int    `json:"in_reply_to,omitempty"`
+	Text      string `json:"text"`
+}
+
+// MarshalJSON encodes the store as its GameState.
+func (gs *GameStore) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gs.ExportState())
+}
+
+// UnmarshalJSON replaces the store with the GameState encoded in data (see
+// ImportState).
+func (gs *GameStore) UnmarshalJSON(data []byte) error {
+	var state GameState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	imported, err := ImportState(&state)
+	if err != nil {
+		return err
+	}
+	*gs = *imported
+	return nil
+}
+
+// ExportState returns the planets, fleets, designs, players, minefields,
+// wormholes and messages of the store.
+func (gs *GameStore) ExportState() *GameState {
+	state := &GameState{
+		Version:  StateVersion,
+		GameID:   gs.GameID,
+		GameName: strings.TrimRight(gs.GameName, "\x00 "),
+		Turn:     gs.Turn,
+		Year:     int(gs.Turn) + blocks.StarsBaseYear,
+		Universe: UniverseState{
+			Size:             gs.UniverseSize,
+			Density:          gs.Density,
+			PlayerCount:      gs.PlayerCount,
+			PlanetCount:      gs.PlanetCount,
+			StartingDistance: gs.StartingDistance,
+			GameSettings:     gs.GameSettings,
+		},
+		Players:    make([]PlayerState, 0, len(gs.Players.All())),
+		Planets:    make([]PlanetState, 0, len(gs.Planets.All())),
+		Fleets:     make([]FleetState, 0, len(gs.Fleets.All())),
+		Designs:    make([]DesignState, 0, len(gs.Designs.All())),
+		Minefields: make([]MinefieldState, 0),
+		Wormholes:  make([]WormholeState, 0),
+		Messages:   make([]MessageState, 0, len(gs.Messages)),
+	}
+	for _, p := range gs.Players.All() {
+		state.Players = append(state.Players, exportPlayer(p))
+	}
+	for _, p := range gs.Planets.All() {
+		state.Planets = append(state.Planets, exportPlanet(p))
+	}
+	for _, f := range gs.Fleets.All() {
+		state.Fleets = append(state.Fleets, exportFleet(f))
+	}
+	for _, d := range gs.Designs.All() {
+		state.Designs = append(state.Designs, exportDesign(d))
+	}
+	for _, o := range gs.Minefields() {
+		state.Minefields = append(state.Minefields, MinefieldState{
+			Number:     o.Number,
+			Owner:      o.Owner,
+			Quality:    o.meta.Quality,
+			X:          o.X,
+			Y:          o.Y,
+			MineCount:  o.MineCount,
+			Type:       o.MinefieldType,
+			Detonating: o.Detonating,
+			SeeBits:    o.MineCurrentSeeBits,
+		})
+	}
+	for _, o := range gs.Wormholes() {
+		state.Wormholes = append(state.Wormholes, WormholeState{
+			Number:         o.Number,
+			Quality:        o.meta.Quality,
+			X:              o.X,
+			Y:              o.Y,
+			WormholeID:     o.WormholeId,
+			TargetID:       o.TargetId,
+			CanSeeBits:     o.CanSeeBits,
+			BeenThrough:    o.BeenThroughBits,
+			Stability:      o.StabilityIndex,
+			TurnsSinceMove: o.TurnsSinceMove,
+			DestKnown:      o.DestKnown,
+			Include:        o.IncludeInDisplay,
+		})
+	}
+	for _, m := range gs.Messages {
+		state.Messages = append(state.Messages, MessageState{
+			Number:    m.meta.Key.Number,
+			Sender:    m.SenderId,
+			Receiver:  m.ReceiverId,
+			InReplyTo: m.InReplyTo,
+			Text:      m.Message,
+		})
+	}
+	return state
+}
+
+// ImportState returns a store holding the game data of a state. The store
+// has no source files: it reads like the store the state was exported from,
+// but cannot regenerate game files.
+func ImportState(state *GameState) (*GameStore, error) {
+	if state.Version < 1 || state.Version > StateVersion {
+		return nil, fmt.Errorf("%w: %d", ErrStateVersion, state.Version)
+	}
+
+	gs := New()
+	gs.GameID = state.GameID
+	gs.GameName = state.GameName
+	gs.Turn = state.Turn
+	gs.UniverseSize = state.Universe.Size
+	gs.Density = state.Universe.Density
+	gs.PlayerCount = state.Universe.PlayerCount
+	gs.PlanetCount = state.Universe.PlanetCount
+	gs.StartingDistance = state.Universe.StartingDistance
+	gs.GameSettings = state.Universe.GameSettings
+
+	for _, p := range state.Players {
+		gs.Players.Add(importPlayer(p, state.Turn))
+	}
+	for _, p := range state.Planets {
+		gs.Planets.Add(importPlanet(p, state.Turn))
+		if p.Name != "" {
+			gs.planetNames[p.Number] = p.Name
+		}
+	}
+	for _, d := range state.Designs {
+		gs.Designs.Add(importDesign(d, state.Turn))
+	}
+	for _, f := range state.Fleets {
+		fleet := importFleet(f, state.Turn)
+		if slot := getPrimaryDesignSlot(fleet.ShipTypes); slot >= 0 {
+			fleet.PrimaryDesign, _ = gs.Design(fleet.Owner, slot)
+		}
+		gs.Fleets.Add(fleet)
+	}
+	for _, m := range state.Minefields {
+		gs.Objects.Add(&ObjectEntity{
+			meta:               importedMeta(EntityTypeObject, m.Owner, objectKeyNumber(ObjectTypeMinefield, m.Number), m.Quality, state.Turn),
+			Number:             m.Number,
+			Owner:              m.Owner,
+			ObjectType:         ObjectTypeMinefield,
+			X:                  m.X,
+			Y:                  m.Y,
+			MineCount:          m.MineCount,
+			MinefieldType:      m.Type,
+			Detonating:         m.Detonating,
+			MineCurrentSeeBits: m.SeeBits,
+		})
+	}
+	for _, w := range state.Wormholes {
+		gs.Objects.Add(&ObjectEntity{
+			meta:             importedMeta(EntityTypeObject, -1, objectKeyNumber(ObjectTypeWormhole, w.Number), w.Quality, state.Turn),
+			Number:           w.Number,
+			Owner:            -1,
+			ObjectType:       ObjectTypeWormhole,
+			X:                w.X,
+			Y:                w.Y,
+			WormholeId:       w.WormholeID,
+			TargetId:         w.TargetID,
+			CanSeeBits:       w.CanSeeBits,
+			BeenThroughBits:  w.BeenThrough,
+			StabilityIndex:   w.Stability,
+			TurnsSinceMove:   w.TurnsSinceMove,
+			DestKnown:        w.DestKnown,
+			IncludeInDisplay: w.Include,
+		})
+	}
+	for _, m := range state.Messages {
+		gs.Messages = append(gs.Messages, &MessageEntity{
+			meta:       importedMeta(EntityTypeMessage, m.Sender, m.Number, QualityFull, state.Turn),
+			SenderId:   m.Sender,
+			ReceiverId: m.Receiver,
+			InReplyTo:  m.InReplyTo,
+			Message:    m.Text,
+		})
+	}
+	return gs, nil
+}
+
+// importedMeta returns the metadata of an entity imported from a state.
+func importedMeta(entityType EntityType, owner, number int, quality DataQuality, turn uint16) EntityMeta {
+	return EntityMeta{
+		Key:     EntityKey{Type: entityType, Owner: owner, Number: number},
+		Quality: quality,
+		Turn:    turn,
+	}
+}
+
+func exportPlayer(p *PlayerEntity) PlayerState {
+	state := PlayerState{
+		Number:               p.PlayerNumber,
+		Quality:              p.meta.Quality,
+		NamePlural:           p.NamePlural,
+		NameSingular:         p.NameSingular,
+		Logo:                 p.Logo,
+		ShipDesignCount:      p.ShipDesignCount,
+		StarbaseDesignCount:  p.StarbaseDesignCount,
+		PlanetCount:          p.PlanetCount,
+		FleetCount:           p.FleetCount,
+		HomePlanet:           p.HomePlanetID,
+		Rank:                 p.Rank,
+		FullData:             p.HasFullData,
+		GrowthRate:           p.GrowthRate,
+		PRT:                  p.PRT,
+		LRT:                  p.LRT,
+		MTItems:              p.MTItems,
+		Tech:                 techFields(p.Tech.Energy, p.Tech.Weapons, p.Tech.Propulsion, p.Tech.Construction, p.Tech.Electronics, p.Tech.Biotech),
+		ResearchPercentage:   p.ResearchPercentage,
+		CurrentResearchField: p.CurrentResearchField,
+		NextResearchField:    p.NextResearchField,
+		ResearchCost: techFields(p.ResearchCost.Energy, p.ResearchCost.Weapons, p.ResearchCost.Propulsion,
+			p.ResearchCost.Construction, p.ResearchCost.Electronics, p.ResearchCost.Biotech),
+		TechProgress: techFields(int(p.TechProgress.Energy), int(p.TechProgress.Weapons), int(p.TechProgress.Propulsion),
+			int(p.TechProgress.Construction), int(p.TechProgress.Electronics), int(p.TechProgress.Biotech)),
+		ResearchLastYear: p.ResearchLastYear,
+		Hab: HabState{
+			GravityCenter:     p.Hab.GravityCenter,
+			GravityLow:        p.Hab.GravityLow,
+			GravityHigh:       p.Hab.GravityHigh,
+			TemperatureCenter: p.Hab.TemperatureCenter,
+			TemperatureLow:    p.Hab.TemperatureLow,
+			TemperatureHigh:   p.Hab.TemperatureHigh,
+			RadiationCenter:   p.Hab.RadiationCenter,
+			RadiationLow:      p.Hab.RadiationLow,
+			RadiationHigh:     p.Hab.RadiationHigh,
+		},
+		Production: ProductionState(p.Production),
+	}
+	for _, r := range p.PlayerRelations {
+		state.Relations = append(state.Relations, int(r))
+	}
+	if s := p.StoredScore; s != nil {
+		score := ScoreState(*s)
+		state.Score = &score
+	}
+	return state
+}
+
+func importPlayer(s PlayerState, turn uint16) *PlayerEntity {
+	p := &PlayerEntity{
+		meta:                 importedMeta(EntityTypePlayer, s.Number, s.Number, s.Quality, turn),
+		PlayerNumber:         s.Number,
+		NamePlural:           s.NamePlural,
+		NameSingular:         s.NameSingular,
+		Logo:                 s.Logo,
+		ShipDesignCount:      s.ShipDesignCount,
+		StarbaseDesignCount:  s.StarbaseDesignCount,
+		PlanetCount:          s.PlanetCount,
+		FleetCount:           s.FleetCount,
+		HomePlanetID:         s.HomePlanet,
+		Rank:                 s.Rank,
+		HasFullData:          s.FullData,
+		GrowthRate:           s.GrowthRate,
+		PRT:                  s.PRT,
+		LRT:                  s.LRT,
+		MTItems:              s.MTItems,
+		Tech:                 TechLevels(s.Tech),
+		ResearchPercentage:   s.ResearchPercentage,
+		CurrentResearchField: s.CurrentResearchField,
+		NextResearchField:    s.NextResearchField,
+		ResearchCost:         blocks.ResearchCosts(s.ResearchCost),
+		TechProgress: blocks.TechPoints{
+			Energy:       uint32(s.TechProgress.Energy),
+			Weapons:      uint32(s.TechProgress.Weapons),
+			Propulsion:   uint32(s.TechProgress.Propulsion),
+			Construction: uint32(s.TechProgress.Construction),
+			Electronics:  uint32(s.TechProgress.Electronics),
+			Biotech:      uint32(s.TechProgress.Biotech),
+		},
+		ResearchLastYear: s.ResearchLastYear,
+		Hab: blocks.Habitability{
+			GravityCenter:     s.Hab.GravityCenter,
+			GravityLow:        s.Hab.GravityLow,
+			GravityHigh:       s.Hab.GravityHigh,
+			TemperatureCenter: s.Hab.TemperatureCenter,
+			TemperatureLow:    s.Hab.TemperatureLow,
+			TemperatureHigh:   s.Hab.TemperatureHigh,
+			RadiationCenter:   s.Hab.RadiationCenter,
+			RadiationLow:      s.Hab.RadiationLow,
+			RadiationHigh:     s.Hab.RadiationHigh,
+		},
+		Production: blocks.ProductionSettings(s.Production),
+	}
+	for _, r := range s.Relations {
+		p.PlayerRelations = append(p.PlayerRelations, byte(r))
+	}
+	if s.Score != nil {
+		score := StoredScore(*s.Score)
+		p.StoredScore = &score
+	}
+	return p
+}
+
+func techFields(energy, weapons, propulsion, construction, electronics, biotech int) TechFields {
+	return TechFields{
+		Energy:       energy,
+		Weapons:      weapons,
+		Propulsion:   propulsion,
+		Construction: construction,
+		Electronics:  electronics,
+		Biotech:      biotech,
+	}
+}
+
+func exportPlanet(p *PlanetEntity) PlanetState {
+	return PlanetState{
+		Number:           p.PlanetNumber,
+		Quality:          p.meta.Quality,
+		Name:             p.Name,
+		X:                p.X,
+		Y:                p.Y,
+		Estimated:        p.PositionEstimated,
+		Owner:            p.Owner,
+		Homeworld:        p.IsHomeworld,
+		DetectionLevel:   p.DetectionLevel,
+		Include:          p.Include,
+		HasStarbase:      p.HasStarbase,
+		HasArtifact:      p.HasArtifact,
+		Terraformed:      p.IsTerraformed,
+		HasInstallations: p.HasInstallations,
+		FirstYear:        p.FirstYear,
+		IroniumConc:      p.IroniumConc,
+		BoraniumConc:     p.BoraniumConc,
+		GermaniumConc:    p.GermaniumConc,
+		Gravity:          p.Gravity,
+		Temperature:      p.Temperature,
+		Radiation:        p.Radiation,
+		OrigGravity:      p.OrigGravity,
+		OrigTemperature:  p.OrigTemperature,
+		OrigRadiation:    p.OrigRadiation,
+		Ironium:          p.Ironium,
+		Boranium:         p.Boranium,
+		Germanium:        p.Germanium,
+		Population:       p.Population,
+		Mines:            p.Mines,
+		Factories:        p.Factories,
+		Defenses:         p.Defenses,
+		DeltaPop:         p.DeltaPop,
+		ScannerID:        p.ScannerID,
+		InstArtifact:     p.InstArtifact,
+		NoResearch:       p.NoResearch,
+		StarbaseDesign:   p.StarbaseDesign,
+		MassDriverDest:   p.MassDriverDest,
+		PacketWarpSpeed:  p.PacketWarpSpeed,
+		RouteTarget:      p.RouteTarget,
+	}
+}
+
+func importPlanet(s PlanetState, turn uint16) *PlanetEntity {
+	return &PlanetEntity{
+		meta:             importedMeta(EntityTypePlanet, s.Owner, s.Number, s.Quality, turn),
+		PlanetNumber:     s.Number,
+		Owner:            s.Owner,
+		IsHomeworld:      s.Homeworld,
+		Name:             s.Name,
+		X:                s.X,
+		Y:                s.Y,
+		DetectionLevel:   s.DetectionLevel,
+		Include:          s.Include,
+		HasStarbase:      s.HasStarbase,
+		HasArtifact:      s.HasArtifact,
+		IsTerraformed:    s.Terraformed,
+		HasInstallations: s.HasInstallations,
+		FirstYear:        s.FirstYear,
+		IroniumConc:      s.IroniumConc,
+		BoraniumConc:     s.BoraniumConc,
+		GermaniumConc:    s.GermaniumConc,
+		Gravity:          s.Gravity,
+		Temperature:      s.Temperature,
+		Radiation:        s.Radiation,
+		OrigGravity:      s.OrigGravity,
+		OrigTemperature:  s.OrigTemperature,
+		OrigRadiation:    s.OrigRadiation,
+		Ironium:          s.Ironium,
+		Boranium:         s.Boranium,
+		Germanium:        s.Germanium,
+		Mines:            s.Mines,
+		Factories:        s.Factories,
+		Defenses:         s.Defenses,
+		DeltaPop:         s.DeltaPop,
+		ScannerID:        s.ScannerID,
+		InstArtifact:     s.InstArtifact,
+		NoResearch:       s.NoResearch,
+		Population:       s.Population,
+		StarbaseDesign:   s.StarbaseDesign,
+		MassDriverDest:   s.MassDriverDest,
+		PacketWarpSpeed:  s.PacketWarpSpeed,
+		RouteTarget:      s.RouteTarget,
+	}
+}
+
+func exportFleet(f *FleetEntity) FleetState {
+	state := FleetState{
+		Number:             f.FleetNumber,
+		Owner:              f.Owner,
+		Quality:            f.meta.Quality,
+		X:                  f.X,
+		Y:                  f.Y,
+		PositionObjectID:   f.PositionObjectId,
+		ShipCounts:         f.ShipCounts,
+		Ironium:            f.ironium,
+		Boranium:           f.boranium,
+		Germanium:          f.germanium,
+		Population:         f.population,
+		Fuel:               f.fuel,
+		DeltaX:             f.DeltaX,
+		DeltaY:             f.DeltaY,
+		Warp:               f.Warp,
+		Mass:               f.Mass,
+		DirectionValid:     f.DirectionValid,
+		CompositionChanged: f.CompositionChanged,
+		Targeted:           f.Targeted,
+		Skipped:            f.Skipped,
+		DamagedShipTypes:   f.DamagedShipTypes,
+		DamagedShipInfo:    f.DamagedShipInfo,
+		BattlePlan:         f.BattlePlan,
+		WaypointCount:      f.WaypointCount,
+		Include:            f.Include,
+		RepeatOrders:       f.RepeatOrders,
+		Dead:               f.IsDead,
+	}
+	if f.HasCustomName {
+		state.Name = f.CustomName
+	}
+	for _, w := range f.Waypoints {
+		wp := WaypointState{
+			X:              w.X,
+			Y:              w.Y,
+			PositionObject: w.PositionObject,
+			Warp:           w.Warp,
+			Task:           w.Task,
+			TaskData:       w.AdditionalBytes,
+		}
+		for i, o := range w.TransportOrders {
+			wp.TransportOrders[i] = TransportOrderState(o)
+		}
+		state.Waypoints = append(state.Waypoints, wp)
+	}
+	return state
+}
+
+func importFleet(s FleetState, turn uint16) *FleetEntity {
+	f := &FleetEntity{
+		meta:               importedMeta(EntityTypeFleet, s.Owner, s.Number, s.Quality, turn),
+		FleetNumber:        s.Number,
+		Owner:              s.Owner,
+		X:                  s.X,
+		Y:                  s.Y,
+		PositionObjectId:   s.PositionObjectID,
+		ShipCounts:         s.ShipCounts,
+		ironium:            s.Ironium,
+		boranium:           s.Boranium,
+		germanium:          s.Germanium,
+		population:         s.Population,
+		fuel:               s.Fuel,
+		DeltaX:             s.DeltaX,
+		DeltaY:             s.DeltaY,
+		Warp:               s.Warp,
+		Mass:               s.Mass,
+		DirectionValid:     s.DirectionValid,
+		CompositionChanged: s.CompositionChanged,
+		Targeted:           s.Targeted,
+		Skipped:            s.Skipped,
+		DamagedShipTypes:   s.DamagedShipTypes,
+		DamagedShipInfo:    s.DamagedShipInfo,
+		BattlePlan:         s.BattlePlan,
+		WaypointCount:      s.WaypointCount,
+		Include:            s.Include,
+		RepeatOrders:       s.RepeatOrders,
+		IsDead:             s.Dead,
+		CustomName:         s.Name,
+		HasCustomName:      s.Name != "",
+	}
+	for i, count := range s.ShipCounts {
+		if count > 0 {
+			f.ShipTypes |= 1 << i
+		}
+	}
+	for i, wp := range s.Waypoints {
+		w := &WaypointEntity{
+			meta:            importedMeta(EntityTypeWaypoint, s.Owner, s.Number*100+i, QualityFull, turn),
+			X:               wp.X,
+			Y:               wp.Y,
+			PositionObject:  wp.PositionObject,
+			Warp:            wp.Warp,
+			Task:            wp.Task,
+			AdditionalBytes: wp.TaskData,
+		}
+		for j, o := range wp.TransportOrders {
+			w.TransportOrders[j] = blocks.TransportOrder(o)
+		}
+		f.Waypoints = append(f.Waypoints, w)
+	}
+	return f
+}
+
+func exportDesign(d *DesignEntity) DesignState {
+	state := DesignState{
+		Number:   d.DesignNumber,
+		Owner:    d.Owner,
+		Quality:  d.meta.Quality,
+		Starbase: d.IsStarbase,
+		Name:     d.Name,
+		Hull:     d.HullId,
+	}
+	if db := d.designBlock; db != nil {
+		state.Picture = db.Pic
+		state.Full = db.IsFullDesign
+		state.Transferred = db.IsTransferred
+		state.Armor = db.Armor
+		state.TurnDesigned = db.TurnDesigned
+		state.TotalBuilt = db.TotalBuilt
+		state.TotalRemaining = db.TotalRemaining
+		if !db.IsFullDesign {
+			state.Mass = db.Mass
+		}
+		for _, slot := range db.Slots {
+			state.Slots = append(state.Slots, DesignSlotState{Category: slot.Category, Item: slot.ItemId, Count: slot.Count})
+		}
+	}
+	return state
+}
+
+func importDesign(s DesignState, turn uint16) *DesignEntity {
+	entityType := EntityTypeDesign
+	if s.Starbase {
+		entityType = EntityTypeStarbaseDesign
+	}
+	db := &blocks.DesignBlock{
+		IsFullDesign:   s.Full,
+		IsTransferred:  s.Transferred,
+		IsStarbase:     s.Starbase,
+		DesignNumber:   s.Number,
+		HullId:         s.Hull,
+		Pic:            s.Picture,
+		Mass:           s.Mass,
+		Armor:          s.Armor,
+		TurnDesigned:   s.TurnDesigned,
+		TotalBuilt:     s.TotalBuilt,
+		TotalRemaining: s.TotalRemaining,
+		Name:           s.Name,
+	}
+	for _, slot := range s.Slots {
+		db.Slots = append(db.Slots, blocks.DesignSlot{Category: slot.Category, ItemId: slot.Item, Count: slot.Count})
+	}
+	// Encode and decode the design, so it reads as one loaded from a file
+	db.Refresh()
+	return &DesignEntity{
+		meta:         importedMeta(entityType, s.Owner, s.Number, s.Quality, turn),
+		DesignNumber: s.Number,
+		Owner:        s.Owner,
+		IsStarbase:   s.Starbase,
+		Name:         s.Name,
+		HullId:       s.Hull,
+		designBlock:  db,
+	}
+}
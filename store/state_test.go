@@ -0,0 +1,101 @@
+package store_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/store"
+)
+
+func loadScenario(t *testing.T, dir string, files ...string) *store.GameStore {
+	t.Helper()
+	gs := store.New()
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join("../testdata", dir, name))
+		require.NoError(t, err)
+		require.NoError(t, gs.AddFile(name, data))
+	}
+	return gs
+}
+
+func TestGameStateRoundTrip(t *testing.T) {
+	scenarios := []struct {
+		dir   string
+		files []string
+	}{
+		{"scenario-basic", []string{"game.xy", "game.m1", "game.m2"}},
+		{"scenario-minefield", []string{"game.xy", "game.m1", "game.h1"}},
+		{"scenario-wormhole", []string{"game.xy", "game.m1"}},
+		{"scenario-message", []string{"game.xy", "game.m1"}},
+	}
+	for _, sc := range scenarios {
+		t.Run(sc.dir, func(t *testing.T) {
+			gs := loadScenario(t, sc.dir, sc.files...)
+
+			data, err := json.Marshal(gs)
+			require.NoError(t, err)
+
+			var imported store.GameStore
+			require.NoError(t, json.Unmarshal(data, &imported))
+			assert.Equal(t, gs.ExportState(), imported.ExportState())
+
+			again, err := json.Marshal(&imported)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(data), string(again))
+
+			// The imported store answers the usual queries
+			assert.Equal(t, gs.GameID, imported.GameID)
+			assert.Equal(t, gs.PlanetCount, imported.PlanetCount)
+			assert.Len(t, imported.Planets.All(), len(gs.Planets.All()))
+			for _, p := range gs.AllPlanets() {
+				assert.Equal(t, gs.PlanetName(p.PlanetNumber), imported.PlanetName(p.PlanetNumber))
+			}
+			for _, f := range gs.Fleets.All() {
+				got, ok := imported.Fleet(f.Owner, f.FleetNumber)
+				require.True(t, ok)
+				assert.Equal(t, f.GetCargo(), got.GetCargo())
+				if f.PrimaryDesign != nil {
+					assert.Equal(t, f.Name(), got.Name())
+				}
+				assert.Len(t, got.Waypoints, len(f.Waypoints))
+			}
+			for _, d := range gs.Designs.All() {
+				got, ok := imported.Design(d.Owner, d.DesignNumber)
+				if d.IsStarbase {
+					got, ok = imported.StarbaseDesign(d.Owner, d.DesignNumber)
+				}
+				require.True(t, ok)
+				assert.Equal(t, d.EquippedItems(), got.EquippedItems())
+				assert.Equal(t, d.GetCloakPercent(), got.GetCloakPercent())
+			}
+			assert.Len(t, imported.Minefields(), len(gs.Minefields()))
+			assert.Len(t, imported.Wormholes(), len(gs.Wormholes()))
+			assert.Len(t, imported.Messages, len(gs.Messages))
+		})
+	}
+}
+
+func TestGameStateContents(t *testing.T) {
+	state := loadScenario(t, "scenario-minefield", "game.xy", "game.m1").ExportState()
+
+	assert.Equal(t, store.StateVersion, state.Version)
+	assert.Equal(t, int(state.Turn)+2400, state.Year)
+	assert.NotEmpty(t, state.Planets)
+	assert.NotEmpty(t, state.Fleets)
+	assert.NotEmpty(t, state.Designs)
+	assert.NotEmpty(t, state.Minefields)
+	assert.True(t, state.Players[0].FullData)
+}
+
+func TestImportStateRejectsUnknownVersion(t *testing.T) {
+	_, err := store.ImportState(&store.GameState{Version: store.StateVersion + 1})
+	assert.ErrorIs(t, err, store.ErrStateVersion)
+
+	var gs store.GameStore
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"version":0}`), &gs), store.ErrStateVersion)
+}
@@ -2,17 +2,25 @@ package store
 
 import (
 	"errors"
+	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+	"github.com/neper-stars/houston/filenames"
+	"github.com/neper-stars/houston/parser"
 )
 
 var (
-	ErrGameIDMismatch = errors.New("game ID mismatch")
-	ErrNoHeader       = errors.New("file has no header block")
+	ErrGameIDMismatch    = errors.New("game ID mismatch")
+	ErrNoHeader          = errors.New("file has no header block")
+	ErrPlanetNotFound    = errors.New("planet not found")
+	ErrUnknownPlanetName = errors.New("not a Stars! planet name")
+	ErrPlanetNameInUse   = errors.New("planet name already in use")
 )
 
 // GameStore aggregates game state from multiple Stars! files.
@@ -28,6 +36,10 @@ type GameStore struct {
 
 	// Conflict resolution
 	resolver ConflictResolver
+	warnings []Warning // Inconsistencies found between sources
+
+	// Optional cache of parsed files
+	cache *parser.Cache
 
 	// Universe data (from PlanetsBlock)
 	planetNames      map[int]string // Planet number -> name
@@ -77,22 +89,33 @@ func NewWithResolver(resolver ConflictResolver) *GameStore {
 	}
 }
 
+// SetCache makes the store reuse the blocks cached for files it has
+// already parsed (see parser.Cache). A nil cache disables caching.
+func (gs *GameStore) SetCache(cache *parser.Cache) {
+	gs.cache = cache
+}
+
 // AddFile parses and merges data from a file.
 func (gs *GameStore) AddFile(name string, data []byte) error {
-	source, err := ParseSource(name, data)
+	source, err := ParseSourceCached(name, data, gs.cache)
 	if err != nil {
 		return err
 	}
+	return gs.addSource(source)
+}
 
+// addSource validates and merges an already parsed source.
+func (gs *GameStore) addSource(source *FileSource) error {
 	if err := gs.validateSource(source); err != nil {
 		return err
 	}
+	gs.checkFileName(source)
 
 	// Store the source
-	if _, exists := gs.sources[name]; !exists {
-		gs.sourceOrder = append(gs.sourceOrder, name)
+	if _, exists := gs.sources[source.ID]; !exists {
+		gs.sourceOrder = append(gs.sourceOrder, source.ID)
 	}
-	gs.sources[name] = source
+	gs.sources[source.ID] = source
 
 	// Update game info from first file
 	if gs.GameID == 0 && source.Header != nil {
@@ -119,13 +142,23 @@ func (gs *GameStore) AddFileReader(name string, r io.Reader) error {
 }
 
 // AddFileWithXY loads a game file and automatically loads the companion XY file
-// if the input is an M or H file (to get planet coordinates).
+// if the input is an M, H or HST file (to get planet coordinates). A ZIP
+// archive is loaded with all the Stars! files it holds.
 func (gs *GameStore) AddFileWithXY(filename string) error {
 	return gs.AddFileWithXYFromFS(filename, osFS{})
 }
 
 // AddFileWithXYFromFS loads a file with optional companion XY file using a filesystem interface.
+// ZIP archives are loaded with AddZip.
 func (gs *GameStore) AddFileWithXYFromFS(filename string, fs FileSystem) error {
+	if filenames.IsZip(filename) {
+		data, err := fs.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		return gs.AddZip(filename, data)
+	}
+
 	// First, try to load companion XY file for M/H files
 	xyFile := findCompanionXYFile(filename, fs)
 	if xyFile != "" {
@@ -142,7 +175,15 @@ func (gs *GameStore) AddFileWithXYFromFS(filename string, fs FileSystem) error {
 	if err != nil {
 		return err
 	}
-	return gs.AddFile(filename, data)
+	if err := gs.AddFile(filename, data); err != nil {
+		return err
+	}
+
+	// Without the XY file, place the planets from what the file shows
+	if xyFile == "" {
+		gs.LocatePlanets()
+	}
+	return nil
 }
 
 // FileSystem interface for abstracting file operations.
@@ -166,40 +207,23 @@ func (osFS) Stat(filename string) (bool, error) {
 	return true, nil
 }
 
-// findCompanionXYFile finds the XY file for a given M or H file.
-// Returns empty string if not found or not applicable.
+// findCompanionXYFile finds the XY file for a given M, H or HST file,
+// whatever the case of its extension. Returns empty string if not found or
+// not applicable.
 func findCompanionXYFile(filename string, fs FileSystem) string {
-	ext := filepath.Ext(filename)
-	if ext == "" {
-		return ""
-	}
-
-	// Check if this is an M or H file (e.g., .m1, .h1, .M1, .H1)
-	extLower := strings.ToLower(ext)
-	if len(extLower) < 2 {
-		return ""
-	}
-
-	fileType := extLower[1] // 'm', 'h', 'x', etc.
-	if fileType != 'm' && fileType != 'h' {
-		return "" // Only M and H files need companion XY
-	}
-
-	// Build the XY filename
-	baseName := strings.TrimSuffix(filename, ext)
-	xyFile := baseName + ".xy"
-
-	// Check if it exists
-	if ok, _ := fs.Stat(xyFile); ok {
-		return xyFile
+	switch filenames.KindOf(filename) {
+	case filenames.M, filenames.H, filenames.HST:
+	default:
+		return "" // Only these files need companion XY
 	}
 
-	// Try uppercase
-	xyFile = baseName + ".XY"
-	if ok, _ := fs.Stat(xyFile); ok {
-		return xyFile
+	xyFile := filenames.Companion(filename, filenames.XY, 0)
+	baseName := strings.TrimSuffix(xyFile, filepath.Ext(xyFile))
+	for _, candidate := range []string{xyFile, baseName + ".xy", baseName + ".XY"} {
+		if ok, _ := fs.Stat(candidate); ok {
+			return candidate
+		}
 	}
-
 	return ""
 }
 
@@ -222,6 +246,20 @@ func (gs *GameStore) validateSource(source *FileSource) error {
 	return nil
 }
 
+// nextDesignOwner takes the owner of the next full design of a host file
+// from the owners of the ship or starbase designs still to come, keeping
+// the given owner when the player blocks counted fewer designs.
+func nextDesignOwner(b *blocks.DesignBlock, owner int, shipOwners, starbaseOwners []int) (int, []int, []int) {
+	if b.IsStarbase {
+		if len(starbaseOwners) > 0 {
+			owner, starbaseOwners = starbaseOwners[0], starbaseOwners[1:]
+		}
+	} else if len(shipOwners) > 0 {
+		owner, shipOwners = shipOwners[0], shipOwners[1:]
+	}
+	return owner, shipOwners, starbaseOwners
+}
+
 // mergeSource extracts and merges entities from a source.
 func (gs *GameStore) mergeSource(source *FileSource) error {
 	// First pass: Extract planet names from PlanetsBlock, designs, players, battle plans, messages, and events
@@ -229,6 +267,10 @@ func (gs *GameStore) mergeSource(source *FileSource) error {
 	// and we'll associate them with the correct owner when processing enemy fleets.
 	briefDesigns := make(map[int]*blocks.DesignBlock) // keyed by design slot
 	messageIndex := 0
+	// Host files hold the designs of every player: the ship designs in
+	// player order, then after the fleets the starbase designs in player
+	// order, as many per player as its player block counts
+	var shipOwners, starbaseOwners []int
 	for _, block := range source.Blocks {
 		switch b := block.(type) {
 		case blocks.PlanetsBlock:
@@ -236,13 +278,25 @@ func (gs *GameStore) mergeSource(source *FileSource) error {
 		case blocks.DesignBlock:
 			if b.IsFullDesign {
 				// Full designs belong to the file's player
-				gs.mergeDesign(&b, source)
+				owner := source.PlayerIndex
+				if source.Type == SourceTypeHSTFile {
+					owner, shipOwners, starbaseOwners = nextDesignOwner(&b, owner, shipOwners, starbaseOwners)
+				}
+				gs.mergeDesign(&b, owner, source)
 			} else {
 				// Brief designs are for enemy ships - defer ownership assignment
 				briefDesigns[b.DesignNumber] = &b
 			}
 		case blocks.PlayerBlock:
 			gs.mergePlayer(&b, source)
+			if source.Type == SourceTypeHSTFile {
+				for range b.ShipDesignCount {
+					shipOwners = append(shipOwners, b.PlayerNumber)
+				}
+				for range b.StarbaseDesignCount {
+					starbaseOwners = append(starbaseOwners, b.PlayerNumber)
+				}
+			}
 		case blocks.BattlePlanBlock:
 			gs.mergeBattlePlan(&b, source)
 		case blocks.MessageBlock:
@@ -382,11 +436,12 @@ func (gs *GameStore) mergePlanetsBlock(pb *blocks.PlanetsBlock, source *FileSour
 			Number: planet.ID,
 		}
 
-		if existing, ok := gs.Planets.Get(key); ok {
-			// Update existing planet with coordinates if missing
-			if existing.X == 0 && existing.Y == 0 {
+		if existing, ok := gs.Planet(planet.ID); ok {
+			// Update existing planet with coordinates if missing or estimated
+			if existing.PositionEstimated || (existing.X == 0 && existing.Y == 0) {
 				existing.X = int(planet.X)
 				existing.Y = int(planet.Y)
+				existing.PositionEstimated = false
 			}
 			if existing.Name == "" {
 				existing.Name = planet.Name
@@ -412,12 +467,13 @@ func (gs *GameStore) mergePlanetsBlock(pb *blocks.PlanetsBlock, source *FileSour
 	}
 }
 
-// mergeDesign merges a design into the store.
-func (gs *GameStore) mergeDesign(db *blocks.DesignBlock, source *FileSource) {
-	entity := newDesignEntityFromBlock(db, source)
+// mergeDesign merges a design of a player into the store.
+func (gs *GameStore) mergeDesign(db *blocks.DesignBlock, owner int, source *FileSource) {
+	entity := newDesignEntityFromBlock(db, owner, source)
 	key := entity.Meta().Key
 
 	if existing, ok := gs.Designs.Get(key); ok {
+		gs.checkDesign(existing, entity, source)
 		if gs.resolver.ShouldReplace(existing, entity) {
 			existing.Meta().AddSource(source)
 			gs.Designs.Add(entity)
@@ -463,6 +519,7 @@ func (gs *GameStore) mergeDesignForOwner(db *blocks.DesignBlock, owner int, sour
 
 	key := entity.Meta().Key
 	if existing, ok := gs.Designs.Get(key); ok {
+		gs.checkDesign(existing, entity, source)
 		if gs.resolver.ShouldReplace(existing, entity) {
 			existing.Meta().AddSource(source)
 			gs.Designs.Add(entity)
@@ -525,6 +582,8 @@ func (gs *GameStore) mergePlanet(pb *blocks.PartialPlanetBlock, source *FileSour
 
 	// Try to find existing planet by number (owner may differ)
 	// First check with exact key, then search by number
+	gs.checkPlanet(entity, source)
+
 	key := entity.Meta().Key
 	var existing *PlanetEntity
 	var found bool
@@ -544,6 +603,7 @@ func (gs *GameStore) mergePlanet(pb *blocks.PartialPlanetBlock, source *FileSour
 		if entity.X == 0 && entity.Y == 0 && (existing.X != 0 || existing.Y != 0) {
 			entity.X = existing.X
 			entity.Y = existing.Y
+			entity.PositionEstimated = existing.PositionEstimated
 		}
 		// Preserve name if the new entity doesn't have it
 		if entity.Name == "" && existing.Name != "" {
@@ -576,6 +636,7 @@ func (gs *GameStore) mergePlayer(pb *blocks.PlayerBlock, source *FileSource) {
 	key := entity.Meta().Key
 
 	if existing, ok := gs.Players.Get(key); ok {
+		gs.checkPlayer(existing, entity, source)
 		if gs.resolver.ShouldReplace(existing, entity) {
 			existing.Meta().AddSource(source)
 			gs.Players.Add(entity)
@@ -662,11 +723,96 @@ func (gs *GameStore) SourceCount() int {
 	return len(gs.sources)
 }
 
+// BlocksOfType returns the blocks of a type from every source, in add
+// order. The store only turns known blocks into entities; this gives access
+// to the others, such as blocks decoded by parser.RegisterBlockType.
+func (gs *GameStore) BlocksOfType(id blocks.BlockTypeID) []blocks.Block {
+	var result []blocks.Block
+	for _, source := range gs.Sources() {
+		for _, block := range source.Blocks {
+			if block.BlockTypeID() == id {
+				result = append(result, block)
+			}
+		}
+	}
+	return result
+}
+
 // PlanetName returns the name of a planet by number.
 func (gs *GameStore) PlanetName(planetNumber int) string {
 	return gs.planetNames[planetNumber]
 }
 
+// RenamePlanet gives a planet another name from the Stars! planet name list.
+// The planet table of every loaded XY and M file is updated, so files
+// regenerated from the store carry the new name. Planet names must stay unique.
+func (gs *GameStore) RenamePlanet(planetNumber int, name string) error {
+	return gs.RenamePlanets(map[int]string{planetNumber: name})
+}
+
+// RenamePlanets renames several planets at once, keyed by planet number.
+// Names must be unique once every planet is renamed, so planets can swap
+// names. Nothing is renamed if any name is rejected or the planet table of
+// any source cannot take it.
+func (gs *GameStore) RenamePlanets(names map[int]string) error {
+	nameIDs := make(map[int]uint32, len(names))
+	final := maps.Clone(gs.planetNames)
+	for planetNumber, name := range names {
+		nameID, ok := data.PlanetNameID(name)
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownPlanetName, name)
+		}
+		if _, ok := gs.planetNames[planetNumber]; !ok {
+			return fmt.Errorf("%w: %d", ErrPlanetNotFound, planetNumber)
+		}
+		nameIDs[planetNumber] = nameID
+		final[planetNumber] = data.PlanetNames[nameID]
+	}
+	holders := make(map[string]int, len(final))
+	for number, name := range final {
+		if other, ok := holders[name]; ok && (names[number] != "" || names[other] != "") {
+			return fmt.Errorf("%w: %s", ErrPlanetNameInUse, name)
+		}
+		holders[name] = number
+	}
+
+	// Rename in copies of the planet tables first, so a table that fails
+	// leaves every source as it was
+	type renamedBlock struct {
+		source *FileSource
+		index  int
+		block  blocks.PlanetsBlock
+	}
+	var renamed []renamedBlock
+	for _, source := range gs.sources {
+		for i, block := range source.Blocks {
+			pb, ok := block.(blocks.PlanetsBlock)
+			if !ok || !pb.Valid {
+				continue
+			}
+			for planetNumber, nameID := range nameIDs {
+				if err := pb.SetPlanetName(planetNumber, nameID); err != nil {
+					return fmt.Errorf("failed to rename planet in %s: %w", source.ID, err)
+				}
+			}
+			renamed = append(renamed, renamedBlock{source, i, pb})
+		}
+	}
+	for _, r := range renamed {
+		r.source.Blocks[r.index] = r.block
+	}
+
+	for planetNumber := range nameIDs {
+		gs.planetNames[planetNumber] = final[planetNumber]
+	}
+	for _, planet := range gs.Planets.All() {
+		if _, ok := nameIDs[planet.PlanetNumber]; ok {
+			planet.Name = final[planet.PlanetNumber]
+		}
+	}
+	return nil
+}
+
 // HasChanges returns true if any entity has been modified.
 func (gs *GameStore) HasChanges() bool {
 	if len(gs.Fleets.DirtyEntities()) > 0 {
@@ -891,9 +1037,16 @@ func (gs *GameStore) AllPlayers() []*PlayerEntity {
 	return gs.Players.All()
 }
 
-// Object returns an object by owner and number.
+// Object returns a minefield by owner and number. Use ObjectOfType for the
+// other object types, which have their own numbering.
 func (gs *GameStore) Object(owner, number int) (*ObjectEntity, bool) {
-	return gs.Objects.GetByOwnerAndNumber(EntityTypeObject, owner, number)
+	return gs.ObjectOfType(ObjectTypeMinefield, owner, number)
+}
+
+// ObjectOfType returns an object by type (ObjectType* constants), owner and
+// number.
+func (gs *GameStore) ObjectOfType(objectType, owner, number int) (*ObjectEntity, bool) {
+	return gs.Objects.GetByOwnerAndNumber(EntityTypeObject, owner, objectKeyNumber(objectType, number))
 }
 
 // ObjectsByOwner returns all objects owned by a player.
@@ -939,6 +1092,17 @@ func (gs *GameStore) Packets() []*ObjectEntity {
 	return result
 }
 
+// MysteryTraders returns all mystery trader objects.
+func (gs *GameStore) MysteryTraders() []*ObjectEntity {
+	var result []*ObjectEntity
+	for _, obj := range gs.Objects.All() {
+		if obj.IsTrader() {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
+
 // Salvage returns all salvage objects.
 func (gs *GameStore) Salvage() []*ObjectEntity {
 	var result []*ObjectEntity
@@ -970,6 +1134,25 @@ func (gs *GameStore) ProductionQueue(planetNumber int) (*ProductionQueueEntity,
 	return gs.ProductionQueues.GetByOwnerAndNumber(EntityTypeProductionQueue, -1, planetNumber)
 }
 
+// SetProductionQueue replaces the production queue of a planet, creating
+// it when the planet has none.
+func (gs *GameStore) SetProductionQueue(planetNumber int, items []ProductionItem) {
+	if pq, ok := gs.ProductionQueue(planetNumber); ok {
+		pq.SetItems(items)
+		return
+	}
+	pq := &ProductionQueueEntity{
+		meta: EntityMeta{
+			Key:     EntityKey{Type: EntityTypeProductionQueue, Owner: -1, Number: planetNumber},
+			Quality: QualityFull,
+			Turn:    gs.Turn,
+		},
+		PlanetNumber: planetNumber,
+	}
+	pq.SetItems(items)
+	gs.ProductionQueues.Add(pq)
+}
+
 // AllProductionQueues returns all production queues in the store.
 func (gs *GameStore) AllProductionQueues() []*ProductionQueueEntity {
 	return gs.ProductionQueues.All()
@@ -1031,6 +1214,15 @@ func (gs *GameStore) DensityName() string {
 	return "Unknown"
 }
 
+// StartingDistanceName returns the human-readable name for the player positions setting.
+func (gs *GameStore) StartingDistanceName() string {
+	names := []string{"Close", "Moderate", "Farther", "Distant"}
+	if int(gs.StartingDistance) < len(names) {
+		return names[gs.StartingDistance]
+	}
+	return "Unknown"
+}
+
 // mergePlayerScores associates PlayerScoresBlock data with the appropriate player.
 func (gs *GameStore) mergePlayerScores(psb *blocks.PlayerScoresBlock, source *FileSource) {
 	player, ok := gs.Player(psb.PlayerID)
@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/parser"
 	"github.com/neper-stars/houston/store"
 )
 
@@ -25,6 +27,29 @@ func TestGameStore_AddFile(t *testing.T) {
 	assert.NotZero(t, gs.GameID)
 }
 
+func TestGameStore_SetCache(t *testing.T) {
+	data, err := os.ReadFile("../testdata/scenario-orders/fleetnames/results/game.m1")
+	require.NoError(t, err)
+
+	cache, err := parser.NewCache(t.TempDir())
+	require.NoError(t, err)
+
+	plain := store.New()
+	require.NoError(t, plain.AddFile("game.m1", data))
+
+	// Load twice so that the second store reads from the cache
+	for range 2 {
+		gs := store.New()
+		gs.SetCache(cache)
+		require.NoError(t, gs.AddFile("game.m1", data))
+
+		assert.Equal(t, plain.GameID, gs.GameID)
+		assert.Equal(t, len(plain.AllFleets()), len(gs.AllFleets()))
+		assert.Equal(t, len(plain.AllPlanets()), len(gs.AllPlanets()))
+		assert.Equal(t, len(plain.AllDesigns()), len(gs.AllDesigns()))
+	}
+}
+
 func TestGameStore_Fleets(t *testing.T) {
 	data, err := os.ReadFile("../testdata/scenario-orders/fleetnames/results/game.m1")
 	require.NoError(t, err)
@@ -267,6 +292,38 @@ func TestGameStore_RegenerateMFile(t *testing.T) {
 	assert.Equal(t, len(gs.AllFleets()), len(gs2.AllFleets()))
 }
 
+func TestGameStore_RegenerateMFile_MassDriverTarget(t *testing.T) {
+	originalData, err := os.ReadFile("../testdata/scenario-packet-driver-destination/game.m1")
+	require.NoError(t, err)
+
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game.m1", originalData))
+
+	// Hurl flings packets to Purgatory at warp 7
+	hurl, ok := gs.Planet(105)
+	require.True(t, ok)
+	target, ok := hurl.MassDriverTarget()
+	require.True(t, ok)
+	assert.Equal(t, 109, target)
+	assert.Equal(t, 7, hurl.PacketWarp())
+
+	// Retarget to planet 300, which needs the high bits of the settings word
+	hurl.SetMassDriverTarget(300, 6)
+
+	regeneratedData, err := gs.RegenerateMFile(0)
+	require.NoError(t, err)
+
+	gs2 := store.New()
+	require.NoError(t, gs2.AddFile("regenerated.m1", regeneratedData))
+
+	hurl, ok = gs2.Planet(105)
+	require.True(t, ok)
+	target, ok = hurl.MassDriverTarget()
+	require.True(t, ok)
+	assert.Equal(t, 300, target)
+	assert.Equal(t, 6, hurl.PacketWarp())
+}
+
 func TestFileWriter_EncodeBlock(t *testing.T) {
 	// Test block encoding creates valid block structure
 	encoder := store.NewBlockEncoder()
@@ -678,3 +735,241 @@ func TestDirtyFleetRegeneration(t *testing.T) {
 	assert.Equal(t, originalCargo.Boranium+50, newCargo.Boranium, "boranium should be updated")
 	assert.Equal(t, originalCargo.Germanium+25, newCargo.Germanium, "germanium should be updated")
 }
+
+// unusedPlanetName returns a planet name no planet of the store carries.
+func unusedPlanetName(gs *store.GameStore) string {
+	used := make(map[string]bool)
+	for i := 0; i < int(gs.PlanetCount); i++ {
+		used[gs.PlanetName(i)] = true
+	}
+	for _, name := range []string{"Red Storm", "Redemption", "Zulu", "Zucchini", "Zippy"} {
+		if !used[name] {
+			return name
+		}
+	}
+	panic("no unused planet name")
+}
+
+func TestGameStore_RenamePlanet(t *testing.T) {
+	data, err := os.ReadFile("../testdata/scenario-basic/game.xy")
+	require.NoError(t, err)
+
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game.xy", data))
+
+	otherName := gs.PlanetName(1)
+	planet, ok := gs.Planet(0)
+	require.True(t, ok)
+	x, y := planet.X, planet.Y
+
+	assert.ErrorIs(t, gs.RenamePlanet(0, "Not A Planet"), store.ErrUnknownPlanetName)
+	assert.ErrorIs(t, gs.RenamePlanet(0, otherName), store.ErrPlanetNameInUse)
+	assert.ErrorIs(t, gs.RenamePlanet(int(gs.PlanetCount), "Zulu"), store.ErrPlanetNotFound)
+
+	newName := unusedPlanetName(gs)
+	require.NoError(t, gs.RenamePlanet(0, strings.ToLower(newName)))
+	assert.Equal(t, newName, gs.PlanetName(0), "name is matched ignoring case")
+	assert.Equal(t, newName, planet.Name)
+
+	regenerated, err := gs.GenerateXYFile()
+	require.NoError(t, err)
+	assert.Len(t, regenerated, len(data))
+
+	gs2 := store.New()
+	require.NoError(t, gs2.AddFile("game.xy", regenerated))
+	assert.Equal(t, newName, gs2.PlanetName(0))
+	assert.Equal(t, otherName, gs2.PlanetName(1))
+	planet2, ok := gs2.Planet(0)
+	require.True(t, ok)
+	assert.Equal(t, x, planet2.X)
+	assert.Equal(t, y, planet2.Y)
+}
+
+func TestGameStore_RenamePlanets(t *testing.T) {
+	data, err := os.ReadFile("../testdata/scenario-basic/game.xy")
+	require.NoError(t, err)
+
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game.xy", data))
+
+	first, second, third := gs.PlanetName(0), gs.PlanetName(1), gs.PlanetName(2)
+	assert.ErrorIs(t, gs.RenamePlanets(map[int]string{0: second, 2: "Not A Planet"}), store.ErrUnknownPlanetName)
+	assert.Equal(t, first, gs.PlanetName(0), "nothing renamed on error")
+	assert.ErrorIs(t, gs.RenamePlanets(map[int]string{0: second}), store.ErrPlanetNameInUse)
+
+	require.NoError(t, gs.RenamePlanets(map[int]string{0: second, 1: first}))
+	assert.Equal(t, second, gs.PlanetName(0))
+	assert.Equal(t, first, gs.PlanetName(1))
+	assert.Equal(t, third, gs.PlanetName(2))
+
+	regenerated, err := gs.GenerateXYFile()
+	require.NoError(t, err)
+	gs2 := store.New()
+	require.NoError(t, gs2.AddFile("game.xy", regenerated))
+	assert.Equal(t, second, gs2.PlanetName(0))
+	assert.Equal(t, first, gs2.PlanetName(1))
+}
+
+func TestGameStore_RenamePlanets_FailingSource(t *testing.T) {
+	// The universe loaded twice, from the game and from an archive
+	data, err := os.ReadFile("../testdata/scenario-basic/game.xy")
+	require.NoError(t, err)
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game.xy", data))
+	require.NoError(t, gs.AddFile("archive.zip/game.xy", data))
+
+	// The table of the last source is too short for the last planet
+	last := int(gs.PlanetCount) - 1
+	sources := gs.Sources()
+	planetTables := func(source *store.FileSource) []int {
+		var indexes []int
+		for i, block := range source.Blocks {
+			if pb, ok := block.(blocks.PlanetsBlock); ok && pb.Valid {
+				indexes = append(indexes, i)
+			}
+		}
+		return indexes
+	}
+	truncated := sources[len(sources)-1]
+	require.NotEmpty(t, planetTables(truncated))
+	for _, i := range planetTables(truncated) {
+		pb := truncated.Blocks[i].(blocks.PlanetsBlock)
+		pb.Planets = pb.Planets[:last]
+		truncated.Blocks[i] = pb
+	}
+
+	before := gs.PlanetName(last)
+	err = gs.RenamePlanet(last, unusedPlanetName(gs))
+	assert.ErrorIs(t, err, blocks.ErrInvalidPlanetID)
+	assert.Equal(t, before, gs.PlanetName(last))
+	for _, source := range sources[:len(sources)-1] {
+		for _, i := range planetTables(source) {
+			pb := source.Blocks[i].(blocks.PlanetsBlock)
+			assert.Equal(t, before, pb.Planets[last].Name, "%s left as it was", source.ID)
+		}
+	}
+}
+
+func TestGameStore_EditStartingState(t *testing.T) {
+	// The planet table lives in the XY file, planet environments in the HST file
+	dir := "../testdata/scenario-cloaking-visibility/game01/historic-backup/"
+	gs := store.New()
+	for _, name := range []string{"game-2400.xy", "game-2400.hst"} {
+		data, err := os.ReadFile(dir + name)
+		require.NoError(t, err)
+		require.NoError(t, gs.AddFile(name, data))
+	}
+
+	planet, ok := gs.Planet(3)
+	require.True(t, ok)
+	require.True(t, planet.CanSeeEnvironment())
+
+	newName := unusedPlanetName(gs)
+	require.NoError(t, gs.RenamePlanet(3, newName))
+	planet.SetMineralConcentrations(90, 5, 60)
+	planet.SetHabitability(50, 45, 55)
+
+	xy, err := gs.GenerateXYFile()
+	require.NoError(t, err)
+	hst, err := gs.RegenerateHSTFile()
+	require.NoError(t, err)
+
+	gs2 := store.New()
+	require.NoError(t, gs2.AddFile("game.xy", xy))
+	require.NoError(t, gs2.AddFile("game.hst", hst))
+	assert.Equal(t, newName, gs2.PlanetName(3))
+
+	edited, ok := gs2.Planet(3)
+	require.True(t, ok)
+	assert.Equal(t, newName, edited.Name)
+	assert.Equal(t, []int{90, 5, 60}, []int{edited.IroniumConc, edited.BoraniumConc, edited.GermaniumConc})
+	assert.Equal(t, []int{50, 45, 55}, []int{edited.Gravity, edited.Temperature, edited.Radiation})
+}
+
+func TestGameStore_RegenerateHSTFile_Orders(t *testing.T) {
+	dir := "../testdata/scenario-singleplayer/2484/"
+	gs := store.New()
+	for _, name := range []string{"Game.xy", "Game.hst"} {
+		data, err := os.ReadFile(dir + name)
+		require.NoError(t, err)
+		require.NoError(t, gs.AddFile(name, data))
+	}
+
+	fleet, ok := gs.Fleet(0, 9)
+	require.True(t, ok)
+	fleet.X, fleet.Y = 1423, 1335
+	fleet.SetWaypoints([]*store.WaypointEntity{
+		{X: 1423, Y: 1335, PositionObjectType: 0x14},
+		{X: 1371, Y: 1378, PositionObject: 183, PositionObjectType: 0x11, Warp: 2},
+	})
+
+	queue := []store.ProductionItem{{ItemId: blocks.ProductionItemMine, Count: 5, ItemType: blocks.ProductionItemTypeStandard}}
+	gs.SetProductionQueue(121, queue)
+
+	player, ok := gs.Player(0)
+	require.True(t, ok)
+	require.NoError(t, player.SetResearch(20, blocks.ResearchFieldWeapons, blocks.ResearchFieldSameField))
+	assert.Error(t, player.SetResearch(101, 0, 0))
+
+	hst, err := gs.RegenerateHSTFile()
+	require.NoError(t, err)
+
+	gs2 := store.New()
+	require.NoError(t, gs2.AddFile("game.hst", hst))
+
+	fleet, ok = gs2.Fleet(0, 9)
+	require.True(t, ok)
+	assert.Equal(t, []int{1423, 1335}, []int{fleet.X, fleet.Y})
+	require.Len(t, fleet.Waypoints, 2)
+	assert.Equal(t, 183, fleet.Waypoints[1].PositionObject)
+	assert.Equal(t, 2, fleet.Waypoints[1].Warp)
+
+	pq, ok := gs2.ProductionQueue(121)
+	require.True(t, ok)
+	require.Len(t, pq.Items, 1)
+	assert.Equal(t, queue[0].ItemId, pq.Items[0].ItemId)
+	assert.Equal(t, 5, pq.Items[0].Count)
+
+	player, ok = gs2.Player(0)
+	require.True(t, ok)
+	assert.Equal(t, 20, player.ResearchPercentage)
+	assert.Equal(t, blocks.ResearchFieldWeapons, player.CurrentResearchField)
+}
+
+func TestGameStore_HSTDesignOwners(t *testing.T) {
+	data, err := os.ReadFile("../testdata/scenario-singleplayer/2499/Game.hst")
+	require.NoError(t, err)
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game.hst", data))
+
+	// Designs belong to the players counting them, not to the host index
+	assert.NotEmpty(t, gs.ShipDesignsByOwner(0))
+	assert.NotEmpty(t, gs.StarbaseDesignsByOwner(0))
+	assert.Empty(t, gs.DesignsByOwner(blocks.RaceFilePlayerIndex))
+}
+
+func TestGameStore_HSTDesignOwners_Multiplayer(t *testing.T) {
+	data, err := os.ReadFile("../testdata/scenario-map/history/game-2470.hst")
+	require.NoError(t, err)
+	gs := store.New()
+	require.NoError(t, gs.AddFile("game.hst", data))
+
+	// Both players' designs come after the last player block
+	names := func(designs []*store.DesignEntity) []string {
+		var result []string
+		for _, d := range designs {
+			result = append(result, d.Name)
+		}
+		return result
+	}
+	hobbit, halfling := gs.ShipDesignsByOwner(0), gs.ShipDesignsByOwner(1)
+	assert.Len(t, hobbit, 11)
+	assert.Contains(t, names(hobbit), "Cruiser")
+	assert.Len(t, halfling, 6)
+	assert.Contains(t, names(halfling), "Armed Probe")
+	assert.Contains(t, names(halfling), "Cotton Picker")
+	assert.NotContains(t, names(halfling), "Cruiser")
+
+	assert.ElementsMatch(t, []string{"Starbase", "Starbase MD", "Starbase MD ST"}, names(gs.StarbaseDesignsByOwner(0)))
+	assert.Equal(t, []string{"Starbase"}, names(gs.StarbaseDesignsByOwner(1)))
+}
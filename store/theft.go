@@ -0,0 +1,65 @@
+package store
+
+import (
+	"github.com/neper-stars/houston/blocks"
+	"github.com/neper-stars/houston/data"
+)
+
+// Super Stealth races can equip two thief scanners: the Pick Pocket steals
+// minerals from enemy fleets at the same location, the Robber Baron from
+// enemy fleets and planets.
+
+// CanStealFromFleets returns true if the design carries a thief scanner.
+func (d *DesignEntity) CanStealFromFleets() bool {
+	for _, item := range d.ItemsByCategory(blocks.ItemCategoryScanner) {
+		if scanner := data.GetScanner(item.ItemID); scanner != nil && scanner.StealsCargo {
+			return true
+		}
+	}
+	return false
+}
+
+// CanStealFromPlanets returns true if the design carries a Robber Baron scanner.
+func (d *DesignEntity) CanStealFromPlanets() bool {
+	for _, item := range d.ItemsByCategory(blocks.ItemCategoryScanner) {
+		if item.ItemID == data.ScannerRobberBaron {
+			return true
+		}
+	}
+	return false
+}
+
+// TheftTargets returns the fleets and planets of other players the fleet can
+// steal minerals from: those at its location carrying minerals.
+func (gs *GameStore) TheftTargets(f *FleetEntity) (fleets []*FleetEntity, planets []*PlanetEntity) {
+	var fromFleets, fromPlanets bool
+	for _, info := range f.GetDesigns(gs) {
+		if info.Design == nil || info.Count <= 0 {
+			continue
+		}
+		fromFleets = fromFleets || info.Design.CanStealFromFleets()
+		fromPlanets = fromPlanets || info.Design.CanStealFromPlanets()
+	}
+
+	if fromFleets {
+		for _, other := range gs.AllFleets() {
+			if other.Owner == f.Owner || other.X != f.X || other.Y != f.Y {
+				continue
+			}
+			if c := other.GetCargo(); c.Ironium+c.Boranium+c.Germanium > 0 {
+				fleets = append(fleets, other)
+			}
+		}
+	}
+	if fromPlanets {
+		for _, planet := range gs.AllPlanets() {
+			if !planet.IsOwned() || planet.Owner == f.Owner || planet.X != f.X || planet.Y != f.Y {
+				continue
+			}
+			if m := planet.GetMinerals(); m.Ironium+m.Boranium+m.Germanium > 0 {
+				planets = append(planets, planet)
+			}
+		}
+	}
+	return fleets, planets
+}
@@ -0,0 +1,158 @@
+package store
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/neper-stars/houston/filenames"
+)
+
+// WarningKind identifies the kind of inconsistency found while merging.
+type WarningKind int
+
+const (
+	WarningPlanetConflict WarningKind = iota // Sources disagree on a planet
+	WarningDesignMismatch                    // Sources hold different designs in the same slot
+	WarningPlayerConflict                    // Sources disagree on a player
+	WarningFileName                          // A file is named after another player than its header's
+)
+
+// String returns a human-readable warning kind.
+func (k WarningKind) String() string {
+	switch k {
+	case WarningPlanetConflict:
+		return "planet conflict"
+	case WarningDesignMismatch:
+		return "design slot mismatch"
+	case WarningPlayerConflict:
+		return "player conflict"
+	case WarningFileName:
+		return "file name mismatch"
+	default:
+		return "unknown"
+	}
+}
+
+// Warning records two sources of the same turn disagreeing on an entity.
+// The merge still keeps one of the two versions (see ConflictResolver);
+// warnings only make the choice visible. WarningFileName warnings are about
+// a single source and have no Other.
+type Warning struct {
+	Kind    WarningKind
+	Key     EntityKey // Entity the sources disagree on
+	Source  string    // ID of the source being merged
+	Other   string    // ID of the source the entity came from so far, if any
+	Message string    // What differs
+}
+
+// String returns the warning as a single line.
+func (w Warning) String() string {
+	if w.Other == "" {
+		return fmt.Sprintf("%s: %s (%s)", w.Kind, w.Message, filepath.Base(w.Source))
+	}
+	return fmt.Sprintf("%s: %s (%s vs %s)", w.Kind, w.Message,
+		filepath.Base(w.Source), filepath.Base(w.Other))
+}
+
+// Warnings returns the inconsistencies found between sources so far, in
+// the order they were found.
+func (gs *GameStore) Warnings() []Warning {
+	return gs.warnings
+}
+
+// warn records a warning when entity from source disagrees with existing,
+// which came from another source of the same turn. History files are not
+// compared: they hold planets as last seen, not as of their turn.
+func (gs *GameStore) warn(kind WarningKind, existing Entity, source *FileSource, format string, args ...any) {
+	other := existing.Meta().BestSource
+	if other == nil || other.ID == source.ID || other.Turn != source.Turn ||
+		other.Type == SourceTypeHFile || source.Type == SourceTypeHFile {
+		return
+	}
+	gs.warnings = append(gs.warnings, Warning{
+		Kind:    kind,
+		Key:     existing.Meta().Key,
+		Source:  source.ID,
+		Other:   other.ID,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// checkFileName warns when the player number in the name of a source
+// differs from the player index of its header, usually a file copied or
+// renamed by hand.
+func (gs *GameStore) checkFileName(source *FileSource) {
+	if source.Header == nil || filenames.Parse(source.ID).MatchesPlayer(source.PlayerIndex) {
+		return
+	}
+	gs.warnings = append(gs.warnings, Warning{
+		Kind:    WarningFileName,
+		Source:  source.ID,
+		Message: fmt.Sprintf("file holds player %d's data", source.PlayerIndex+1),
+	})
+}
+
+// checkPlanet warns when a planet differs from the version already merged,
+// which may be stored under another owner. Only fields both sources can
+// see are compared.
+func (gs *GameStore) checkPlanet(entity *PlanetEntity, source *FileSource) {
+	if entity.meta.Quality < QualityPartial {
+		return
+	}
+	for _, existing := range gs.Planets.All() {
+		if existing.PlanetNumber != entity.PlanetNumber || existing.meta.Quality < QualityPartial {
+			continue
+		}
+		name := planetLabel(entity)
+		switch {
+		case existing.Owner != entity.Owner:
+			gs.warn(WarningPlanetConflict, existing, source, "%s owned by player %d, was player %d",
+				name, entity.Owner, existing.Owner)
+		case existing.HasStarbase != entity.HasStarbase:
+			gs.warn(WarningPlanetConflict, existing, source, "%s starbase %t, was %t",
+				name, entity.HasStarbase, existing.HasStarbase)
+		case existing.meta.Quality == QualityFull && entity.meta.Quality == QualityFull:
+			if existing.Population != entity.Population {
+				gs.warn(WarningPlanetConflict, existing, source, "%s population %d, was %d",
+					name, entity.Population, existing.Population)
+			} else if existing.Mines != entity.Mines || existing.Factories != entity.Factories || existing.Defenses != entity.Defenses {
+				gs.warn(WarningPlanetConflict, existing, source, "%s installations %d/%d/%d, were %d/%d/%d",
+					name, entity.Mines, entity.Factories, entity.Defenses,
+					existing.Mines, existing.Factories, existing.Defenses)
+			}
+		}
+	}
+}
+
+// checkDesign warns when a design slot holds another design than the one
+// already merged.
+func (gs *GameStore) checkDesign(existing, entity *DesignEntity, source *FileSource) {
+	if existing.Name != entity.Name || existing.HullId != entity.HullId {
+		gs.warn(WarningDesignMismatch, existing, source, "player %d slot %d holds %q (hull %d), was %q (hull %d)",
+			entity.Owner, entity.DesignNumber, entity.Name, entity.HullId, existing.Name, existing.HullId)
+	}
+}
+
+// checkPlayer warns when a player differs from the version already merged.
+// Race details are compared only when both sources have full data.
+func (gs *GameStore) checkPlayer(existing, entity *PlayerEntity, source *FileSource) {
+	switch {
+	case existing.NamePlural != entity.NamePlural || existing.NameSingular != entity.NameSingular:
+		gs.warn(WarningPlayerConflict, existing, source, "player %d named %q, was %q",
+			entity.PlayerNumber, entity.NamePlural, existing.NamePlural)
+	case !existing.HasFullData || !entity.HasFullData:
+	case existing.PRT != entity.PRT || existing.LRT != entity.LRT:
+		gs.warn(WarningPlayerConflict, existing, source, "player %d race traits PRT %d LRT %#x, were PRT %d LRT %#x",
+			entity.PlayerNumber, entity.PRT, entity.LRT, existing.PRT, existing.LRT)
+	case existing.Tech != entity.Tech:
+		gs.warn(WarningPlayerConflict, existing, source, "player %d tech %v, was %v",
+			entity.PlayerNumber, entity.Tech, existing.Tech)
+	}
+}
+
+func planetLabel(p *PlanetEntity) string {
+	if p.Name != "" {
+		return fmt.Sprintf("planet #%d (%s)", p.PlanetNumber, p.Name)
+	}
+	return fmt.Sprintf("planet #%d", p.PlanetNumber)
+}
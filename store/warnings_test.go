@@ -0,0 +1,92 @@
+package store
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neper-stars/houston/blocks"
+)
+
+func TestWarnings_DesignMismatch(t *testing.T) {
+	// Player 1 reused design slots after player 0 last scanned them
+	gs := New()
+	for _, name := range []string{"game-2482.m1", "game-2482.m2"} {
+		data, err := os.ReadFile("../testdata/scenario-map/history/" + name)
+		require.NoError(t, err)
+		require.NoError(t, gs.AddFile(name, data))
+	}
+
+	var found bool
+	for _, w := range gs.Warnings() {
+		assert.Equal(t, WarningDesignMismatch, w.Kind)
+		if w.Key.Owner == 1 && w.Key.Number == 3 {
+			found = true
+			assert.Equal(t, "game-2482.m2", w.Source)
+			assert.Equal(t, "game-2482.m1", w.Other)
+			assert.Contains(t, w.String(), `"Teamster"`)
+		}
+	}
+	assert.True(t, found, "slot 3 of player 1 should be reported")
+
+	// The full design still wins
+	design, ok := gs.Designs.GetByOwnerAndNumber(EntityTypeDesign, 1, 3)
+	require.True(t, ok)
+	assert.Equal(t, "Teamster", design.Name)
+}
+
+func TestWarnings_PlanetConflict(t *testing.T) {
+	gs := New()
+	m1 := &FileSource{ID: "game.m1", Type: SourceTypeMFile, PlayerIndex: 0, Turn: 10}
+	m2 := &FileSource{ID: "game.m2", Type: SourceTypeMFile, PlayerIndex: 1, Turn: 10}
+
+	gs.mergePlanet(&blocks.PartialPlanetBlock{PlanetNumber: 7, Owner: 0, DetectionLevel: blocks.DetNormalScan}, m1)
+	gs.mergePlanet(&blocks.PartialPlanetBlock{PlanetNumber: 7, Owner: 0, DetectionLevel: blocks.DetNormalScan}, m2)
+	assert.Empty(t, gs.Warnings(), "agreeing sources")
+
+	gs.mergePlanet(&blocks.PartialPlanetBlock{PlanetNumber: 7, Owner: 1, DetectionLevel: blocks.DetNormalScan}, m2)
+	require.Len(t, gs.Warnings(), 1)
+	w := gs.Warnings()[0]
+	assert.Equal(t, WarningPlanetConflict, w.Kind)
+	assert.Equal(t, "planet conflict: planet #7 owned by player 1, was player 0 (game.m2 vs game.m1)", w.String())
+
+	// Other turns and history files are not compared
+	h := &FileSource{ID: "game.h1", Type: SourceTypeHFile, Turn: 10}
+	m3 := &FileSource{ID: "game.m3", Type: SourceTypeMFile, PlayerIndex: 2, Turn: 11}
+	gs.mergePlanet(&blocks.PartialPlanetBlock{PlanetNumber: 7, Owner: 2, DetectionLevel: blocks.DetNormalScan}, h)
+	gs.mergePlanet(&blocks.PartialPlanetBlock{PlanetNumber: 7, Owner: 3, DetectionLevel: blocks.DetNormalScan}, m3)
+	assert.Len(t, gs.Warnings(), 1)
+}
+
+func TestWarnings_PlayerConflict(t *testing.T) {
+	gs := New()
+	m1 := &FileSource{ID: "game.m1", Type: SourceTypeMFile, Turn: 3}
+	hst := &FileSource{ID: "game.hst", Type: SourceTypeHSTFile, Turn: 3}
+
+	gs.mergePlayer(&blocks.PlayerBlock{PlayerNumber: 0, NamePlural: "Humanoids", NameSingular: "Humanoid", FullDataFlag: true, PRT: blocks.PRTJackOfAllTrades}, m1)
+	gs.mergePlayer(&blocks.PlayerBlock{PlayerNumber: 0, NamePlural: "Humanoids", NameSingular: "Humanoid", FullDataFlag: true, PRT: blocks.PRTHyperExpansion}, hst)
+
+	require.Len(t, gs.Warnings(), 1)
+	assert.Equal(t, WarningPlayerConflict, gs.Warnings()[0].Kind)
+	assert.Contains(t, gs.Warnings()[0].Message, "race traits")
+}
+
+func TestWarnings_FileName(t *testing.T) {
+	data, err := os.ReadFile("../testdata/scenario-map/history/game-2440.m1")
+	require.NoError(t, err)
+
+	gs := New()
+	require.NoError(t, gs.AddFile("turns/game.m2", data))
+	require.Len(t, gs.Warnings(), 1)
+	assert.Equal(t, "file name mismatch: file holds player 1's data (game.m2)", gs.Warnings()[0].String())
+}
+
+func TestWarningKind_String(t *testing.T) {
+	assert.Equal(t, "planet conflict", WarningPlanetConflict.String())
+	assert.Equal(t, "design slot mismatch", WarningDesignMismatch.String())
+	assert.Equal(t, "player conflict", WarningPlayerConflict.String())
+	assert.Equal(t, "file name mismatch", WarningFileName.String())
+	assert.Equal(t, "unknown", WarningKind(99).String())
+}
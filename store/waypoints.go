@@ -21,8 +21,9 @@ type WaypointEntity struct {
 	meta EntityMeta
 
 	// Position
-	X, Y           int
-	PositionObject int // Object ID at position
+	X, Y               int
+	PositionObject     int // Object ID at position
+	PositionObjectType int // Kind of position (blocks.WaypointChangeTaskBlock TargetType, with bit 4 set)
 
 	// Movement
 	Warp int // Warp factor (0-15)
@@ -92,6 +93,33 @@ func (w *WaypointEntity) TaskName() string {
 	}
 }
 
+// waypointTaskDataSize is the size of the task data of waypoints with a
+// task, whatever the task.
+const waypointTaskDataSize = 10
+
+// encodeBlock encodes the waypoint from its current values. Waypoints with
+// a task are written as WaypointTaskBlocks, the others as WaypointBlocks.
+func (w *WaypointEntity) encodeBlock() (blocks.BlockTypeID, []byte) {
+	wb := blocks.WaypointBlock{
+		X:                  w.X,
+		Y:                  w.Y,
+		PositionObject:     w.PositionObject,
+		Warp:               w.Warp,
+		WaypointTask:       w.Task,
+		PositionObjectType: w.PositionObjectType,
+		TransportOrders:    w.TransportOrders,
+		AdditionalBytes:    w.AdditionalBytes,
+	}
+	if w.Task == WaypointTaskNone {
+		wb.AdditionalBytes = nil
+		return blocks.WaypointBlockType, wb.Encode()
+	}
+	if len(wb.AdditionalBytes) == 0 {
+		wb.AdditionalBytes = make([]byte, waypointTaskDataSize)
+	}
+	return blocks.WaypointTaskBlockType, wb.Encode()
+}
+
 // newWaypointEntityFromBlock creates a WaypointEntity from a WaypointBlock.
 func newWaypointEntityFromBlock(wb *blocks.WaypointBlock, fleetOwner, fleetNumber, waypointIndex int, source *FileSource) *WaypointEntity {
 	entity := &WaypointEntity{
@@ -105,14 +133,15 @@ func newWaypointEntityFromBlock(wb *blocks.WaypointBlock, fleetOwner, fleetNumbe
 			Quality:    QualityFull,
 			Turn:       source.Turn,
 		},
-		X:               wb.X,
-		Y:               wb.Y,
-		PositionObject:  wb.PositionObject,
-		Warp:            wb.Warp,
-		Task:            wb.WaypointTask,
-		TransportOrders: wb.TransportOrders,
-		AdditionalBytes: wb.AdditionalBytes,
-		waypointBlock:   wb,
+		X:                  wb.X,
+		Y:                  wb.Y,
+		PositionObject:     wb.PositionObject,
+		PositionObjectType: wb.PositionObjectType,
+		Warp:               wb.Warp,
+		Task:               wb.WaypointTask,
+		TransportOrders:    wb.TransportOrders,
+		AdditionalBytes:    wb.AdditionalBytes,
+		waypointBlock:      wb,
 	}
 	entity.meta.AddSource(source)
 	return entity
@@ -131,14 +160,15 @@ func newWaypointEntityFromTaskBlock(wtb *blocks.WaypointTaskBlock, fleetOwner, f
 			Quality:    QualityFull,
 			Turn:       source.Turn,
 		},
-		X:               wtb.X,
-		Y:               wtb.Y,
-		PositionObject:  wtb.PositionObject,
-		Warp:            wtb.Warp,
-		Task:            wtb.WaypointTask,
-		TransportOrders: wtb.TransportOrders,
-		AdditionalBytes: wtb.AdditionalBytes,
-		taskBlock:       wtb,
+		X:                  wtb.X,
+		Y:                  wtb.Y,
+		PositionObject:     wtb.PositionObject,
+		PositionObjectType: wtb.PositionObjectType,
+		Warp:               wtb.Warp,
+		Task:               wtb.WaypointTask,
+		TransportOrders:    wtb.TransportOrders,
+		AdditionalBytes:    wtb.AdditionalBytes,
+		taskBlock:          wtb,
 	}
 	entity.meta.AddSource(source)
 	return entity
@@ -62,7 +62,7 @@ func (w *FileWriter) WriteEncryptedBlock(typeID blocks.BlockTypeID, decryptedDat
 func (gs *GameStore) GenerateMFile(playerIndex int) ([]byte, error) {
 	// Find the source file for this player to get encryption parameters
 	var sourceFile *FileSource
-	for _, source := range gs.sources {
+	for _, source := range gs.Sources() {
 		if source.PlayerIndex == playerIndex && source.Type == SourceTypeMFile {
 			sourceFile = source
 			break
@@ -81,7 +81,7 @@ func (gs *GameStore) GenerateMFile(playerIndex int) ([]byte, error) {
 func (gs *GameStore) GenerateXFile(playerIndex int) ([]byte, error) {
 	// Find a source file for this player to get encryption parameters
 	var sourceFile *FileSource
-	for _, source := range gs.sources {
+	for _, source := range gs.Sources() {
 		if source.PlayerIndex == playerIndex {
 			sourceFile = source
 			break
@@ -99,7 +99,7 @@ func (gs *GameStore) GenerateXFile(playerIndex int) ([]byte, error) {
 func (gs *GameStore) GenerateXYFile() ([]byte, error) {
 	// Find an XY file source
 	var sourceFile *FileSource
-	for _, source := range gs.sources {
+	for _, source := range gs.Sources() {
 		if source.Type == SourceTypeXYFile {
 			sourceFile = source
 			break
@@ -117,7 +117,7 @@ func (gs *GameStore) GenerateXYFile() ([]byte, error) {
 func (gs *GameStore) GenerateHFile(playerIndex int) ([]byte, error) {
 	// Find an H file source for this player
 	var sourceFile *FileSource
-	for _, source := range gs.sources {
+	for _, source := range gs.Sources() {
 		if source.PlayerIndex == playerIndex && source.Type == SourceTypeHFile {
 			sourceFile = source
 			break
@@ -136,7 +136,7 @@ func (gs *GameStore) GenerateHFile(playerIndex int) ([]byte, error) {
 func (gs *GameStore) GenerateRFile(playerSlot int) ([]byte, error) {
 	// Find an R file source for this player slot
 	var sourceFile *FileSource
-	for _, source := range gs.sources {
+	for _, source := range gs.Sources() {
 		if source.PlayerIndex == playerSlot && source.Type == SourceTypeRFile {
 			sourceFile = source
 			break
@@ -166,11 +166,7 @@ func (gs *GameStore) generateFileFromSource(source *FileSource) ([]byte, error)
 	result = append(result, writer.WriteHeader(header)...)
 
 	// Initialize encryption
-	shareware := 0
-	if header.Crippled() {
-		shareware = 1
-	}
-	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), shareware)
+	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), header.SharewareFlag())
 
 	// Track current fleet and planet for association
 	var currentFleetKey *EntityKey
@@ -319,11 +315,7 @@ func (gs *GameStore) generateXFileFromSource(source *FileSource) ([]byte, error)
 	result = append(result, writer.WriteHeader(header)...)
 
 	// Initialize encryption
-	shareware := 0
-	if header.Crippled() {
-		shareware = 1
-	}
-	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), shareware)
+	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), header.SharewareFlag())
 
 	// If source is already an X file, preserve all blocks for round-trip
 	// Otherwise, filter to command blocks only and add SaveAndSubmit
@@ -377,11 +369,7 @@ func (gs *GameStore) generateXYFileFromSource(source *FileSource) ([]byte, error
 	result = append(result, writer.WriteHeader(header)...)
 
 	// Initialize encryption
-	shareware := 0
-	if header.Crippled() {
-		shareware = 1
-	}
-	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), shareware)
+	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), header.SharewareFlag())
 
 	// Find PlayerCount from PlanetsBlock for footer data
 	var playerCount uint16
@@ -433,11 +421,7 @@ func (gs *GameStore) generateHFileFromSource(source *FileSource) ([]byte, error)
 	result = append(result, writer.WriteHeader(header)...)
 
 	// Initialize encryption
-	shareware := 0
-	if header.Crippled() {
-		shareware = 1
-	}
-	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), shareware)
+	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), header.SharewareFlag())
 
 	// Write all blocks from source
 	for _, block := range source.Blocks {
@@ -474,7 +458,7 @@ func (gs *GameStore) generateRFileFromSource(source *FileSource) ([]byte, error)
 	result = append(result, writer.WriteHeader(header)...)
 
 	// Initialize encryption for race files: gameId=0, turn=0, playerIndex=31
-	writer.InitEncryption(header.Salt(), 0, 0, blocks.RaceFilePlayerIndex, 0)
+	writer.InitEncryption(header.Salt(), 0, 0, blocks.RaceFilePlayerIndex, header.SharewareFlag())
 
 	// Find the PlayerBlock and track decrypted data for footer computation
 	var decryptedPlayerBlockData []byte
@@ -562,7 +546,7 @@ func rFileFooterData(decryptedPlayerBlockData []byte, singularName, pluralName s
 func (gs *GameStore) RegenerateMFile(playerIndex int) ([]byte, error) {
 	// Find the source file for this player
 	var sourceFile *FileSource
-	for _, source := range gs.sources {
+	for _, source := range gs.Sources() {
 		if source.PlayerIndex == playerIndex && source.Type == SourceTypeMFile {
 			sourceFile = source
 			break
@@ -590,18 +574,15 @@ func (gs *GameStore) regenerateWithChanges(source *FileSource) ([]byte, error) {
 	result = append(result, writer.WriteHeader(header)...)
 
 	// Initialize encryption
-	shareware := 0
-	if header.Crippled() {
-		shareware = 1
-	}
-	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), shareware)
+	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), header.SharewareFlag())
 
-	// Track which entities we've replaced
-	replacedFleets := make(map[EntityKey]bool)
-	replacedQueues := make(map[EntityKey]bool)
+	// Fleets whose waypoints changed are written with the new waypoints in
+	// place of those of the source
+	skipWaypoints := false
+	lastPlanetNumber := -1
 
 	// Process all blocks from the source
-	for _, block := range source.Blocks {
+	for i, block := range source.Blocks {
 		typeID := block.BlockTypeID()
 
 		// Skip header and footer
@@ -610,34 +591,35 @@ func (gs *GameStore) regenerateWithChanges(source *FileSource) ([]byte, error) {
 		}
 
 		var decrypted []byte
+		var waypoints []*WaypointEntity
+		var queue *ProductionQueueEntity
 
 		switch b := block.(type) {
 		case blocks.FleetBlock:
-			key := EntityKey{Type: EntityTypeFleet, Owner: b.Owner, Number: b.FleetNumber}
-			if fleet, ok := gs.Fleets.Get(key); ok && fleet.Meta().Dirty {
-				encoded, err := writer.encoder.EncodeFleetBlock(fleet)
-				if err == nil {
-					decrypted = encoded
-					replacedFleets[key] = true
-				}
-			}
+			decrypted, waypoints = gs.encodeDirtyFleet(writer, &b.PartialFleetBlock)
+			skipWaypoints = waypoints != nil
+			lastPlanetNumber = -1
 		case blocks.PartialFleetBlock:
-			key := EntityKey{Type: EntityTypeFleet, Owner: b.Owner, Number: b.FleetNumber}
-			if fleet, ok := gs.Fleets.Get(key); ok && fleet.Meta().Dirty {
-				encoded, err := writer.encoder.EncodeFleetBlock(fleet)
-				if err == nil {
-					decrypted = encoded
-					replacedFleets[key] = true
-				}
+			decrypted, waypoints = gs.encodeDirtyFleet(writer, &b)
+			skipWaypoints = waypoints != nil
+			lastPlanetNumber = -1
+		case blocks.WaypointBlock, blocks.WaypointTaskBlock:
+			if skipWaypoints {
+				continue
 			}
 		case blocks.PlanetBlock:
+			skipWaypoints = false
+			lastPlanetNumber = b.PlanetNumber
 			if planet, ok := gs.PlanetForSave(b.PlanetNumber); ok && planet.Meta().Dirty {
 				// Use source block structure with entity values
 				if encoded, err := writer.encoder.EncodePlanetBlockFromSource(&b.PartialPlanetBlock, planet); err == nil {
 					decrypted = encoded
 				}
 			}
+			queue = gs.newQueue(source.Blocks, i, b.PlanetNumber)
 		case blocks.PartialPlanetBlock:
+			skipWaypoints = false
+			lastPlanetNumber = b.PlanetNumber
 			if planet, ok := gs.PlanetForSave(b.PlanetNumber); ok && planet.Meta().Dirty {
 				// Use source block structure with entity values
 				if encoded, err := writer.encoder.EncodePlanetBlockFromSource(&b, planet); err == nil {
@@ -645,8 +627,26 @@ func (gs *GameStore) regenerateWithChanges(source *FileSource) ([]byte, error) {
 				}
 			}
 		case blocks.ProductionQueueBlock:
-			// Production queues need special handling - we need to know the planet number
-			// For now, use original data
+			// The queue follows the block of its planet
+			if lastPlanetNumber >= 0 {
+				if pq, ok := gs.ProductionQueue(lastPlanetNumber); ok && pq.Meta().Dirty {
+					if len(pq.Items) == 0 {
+						continue
+					}
+					if encoded, err := writer.encoder.EncodeProductionQueueBlock(pq); err == nil {
+						decrypted = encoded
+					}
+				}
+			}
+		case blocks.PlayerBlock:
+			// Only the player of the file has full data to re-encode
+			if player, ok := gs.Player(b.PlayerNumber); ok && player.Meta().Dirty && b.PlayerNumber == source.PlayerIndex {
+				if encoded, err := writer.encoder.EncodePlayerBlock(player); err == nil {
+					decrypted = encoded
+				}
+			}
+		default:
+			skipWaypoints = skipWaypoints && typeID == blocks.FleetNameBlockType
 		}
 
 		// Use original data if not replaced
@@ -656,6 +656,16 @@ func (gs *GameStore) regenerateWithChanges(source *FileSource) ([]byte, error) {
 
 		result = append(result, writer.WriteEncryptedBlock(typeID, decrypted)...)
 
+		for _, wp := range waypoints {
+			wpType, wpData := wp.encodeBlock()
+			result = append(result, writer.WriteEncryptedBlock(wpType, wpData)...)
+		}
+		if queue != nil {
+			if encoded, err := writer.encoder.EncodeProductionQueueBlock(queue); err == nil {
+				result = append(result, writer.WriteEncryptedBlock(blocks.ProductionQueueBlockType, encoded)...)
+			}
+		}
+
 		// Handle PlanetsBlock trailing data (stored unencrypted in file format)
 		if pb, ok := block.(blocks.PlanetsBlock); ok {
 			if pb.Valid && len(pb.RawPlanetsData) > 0 {
@@ -664,8 +674,6 @@ func (gs *GameStore) regenerateWithChanges(source *FileSource) ([]byte, error) {
 		}
 	}
 
-	_ = replacedQueues // silence unused warning
-
 	// Write footer with turn number as footer data
 	footerData := mFileFooterData(header)
 	result = append(result, writer.WriteFooter(true, footerData)...)
@@ -677,7 +685,7 @@ func (gs *GameStore) regenerateWithChanges(source *FileSource) ([]byte, error) {
 func (gs *GameStore) RegenerateHFile(playerIndex int) ([]byte, error) {
 	// Find the source file for this player
 	var sourceFile *FileSource
-	for _, source := range gs.sources {
+	for _, source := range gs.Sources() {
 		if source.PlayerIndex == playerIndex && source.Type == SourceTypeHFile {
 			sourceFile = source
 			break
@@ -705,11 +713,7 @@ func (gs *GameStore) regenerateHFileWithChanges(source *FileSource) ([]byte, err
 	result = append(result, writer.WriteHeader(header)...)
 
 	// Initialize encryption
-	shareware := 0
-	if header.Crippled() {
-		shareware = 1
-	}
-	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), shareware)
+	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), header.SharewareFlag())
 
 	// Process all blocks from the source
 	for _, block := range source.Blocks {
@@ -776,7 +780,7 @@ func (gs *GameStore) regenerateHFileWithChanges(source *FileSource) ([]byte, err
 func (gs *GameStore) GenerateHSTFile() ([]byte, error) {
 	// Find an HST file source
 	var sourceFile *FileSource
-	for _, source := range gs.sources {
+	for _, source := range gs.Sources() {
 		if source.Type == SourceTypeHSTFile {
 			sourceFile = source
 			break
@@ -806,11 +810,7 @@ func (gs *GameStore) generateHSTFileFromSource(source *FileSource) ([]byte, erro
 	result = append(result, writer.WriteHeader(header)...)
 
 	// Initialize encryption
-	shareware := 0
-	if header.Crippled() {
-		shareware = 1
-	}
-	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), shareware)
+	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), header.SharewareFlag())
 
 	// Track current fleet and planet for association
 	var currentFleetKey *EntityKey
@@ -953,7 +953,7 @@ func (gs *GameStore) generateHSTFileFromSource(source *FileSource) ([]byte, erro
 func (gs *GameStore) RegenerateHSTFile() ([]byte, error) {
 	// Find the HST source file
 	var sourceFile *FileSource
-	for _, source := range gs.sources {
+	for _, source := range gs.Sources() {
 		if source.Type == SourceTypeHSTFile {
 			sourceFile = source
 			break
@@ -981,17 +981,15 @@ func (gs *GameStore) regenerateHSTWithChanges(source *FileSource) ([]byte, error
 	result = append(result, writer.WriteHeader(header)...)
 
 	// Initialize encryption
-	shareware := 0
-	if header.Crippled() {
-		shareware = 1
-	}
-	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), shareware)
+	writer.InitEncryption(header.Salt(), int(header.GameID), int(header.Turn), header.PlayerIndex(), header.SharewareFlag())
 
-	// Track which entities we've replaced
-	replacedFleets := make(map[EntityKey]bool)
+	// Fleets whose waypoints changed are written with the new waypoints in
+	// place of those of the source
+	skipWaypoints := false
+	lastPlanetNumber := -1
 
 	// Process all blocks from the source
-	for _, block := range source.Blocks {
+	for i, block := range source.Blocks {
 		typeID := block.BlockTypeID()
 
 		// Skip header and footer
@@ -1000,27 +998,25 @@ func (gs *GameStore) regenerateHSTWithChanges(source *FileSource) ([]byte, error
 		}
 
 		var decrypted []byte
+		var waypoints []*WaypointEntity
+		var queue *ProductionQueueEntity
 
 		switch b := block.(type) {
 		case blocks.FleetBlock:
-			key := EntityKey{Type: EntityTypeFleet, Owner: b.Owner, Number: b.FleetNumber}
-			if fleet, ok := gs.Fleets.Get(key); ok && fleet.Meta().Dirty {
-				encoded, err := writer.encoder.EncodeFleetBlock(fleet)
-				if err == nil {
-					decrypted = encoded
-					replacedFleets[key] = true
-				}
-			}
+			decrypted, waypoints = gs.encodeDirtyFleet(writer, &b.PartialFleetBlock)
+			skipWaypoints = waypoints != nil
+			lastPlanetNumber = -1
 		case blocks.PartialFleetBlock:
-			key := EntityKey{Type: EntityTypeFleet, Owner: b.Owner, Number: b.FleetNumber}
-			if fleet, ok := gs.Fleets.Get(key); ok && fleet.Meta().Dirty {
-				encoded, err := writer.encoder.EncodeFleetBlock(fleet)
-				if err == nil {
-					decrypted = encoded
-					replacedFleets[key] = true
-				}
+			decrypted, waypoints = gs.encodeDirtyFleet(writer, &b)
+			skipWaypoints = waypoints != nil
+			lastPlanetNumber = -1
+		case blocks.WaypointBlock, blocks.WaypointTaskBlock:
+			if skipWaypoints {
+				continue
 			}
 		case blocks.PlanetBlock:
+			skipWaypoints = false
+			lastPlanetNumber = b.PlanetNumber
 			if planet, ok := gs.PlanetForSave(b.PlanetNumber); ok && planet.Meta().Dirty {
 				// Use source block structure with entity values
 				encoded, err := writer.encoder.EncodePlanetBlockFromSource(&b.PartialPlanetBlock, planet)
@@ -1028,7 +1024,10 @@ func (gs *GameStore) regenerateHSTWithChanges(source *FileSource) ([]byte, error
 					decrypted = encoded
 				}
 			}
+			queue = gs.newQueue(source.Blocks, i, b.PlanetNumber)
 		case blocks.PartialPlanetBlock:
+			skipWaypoints = false
+			lastPlanetNumber = b.PlanetNumber
 			if planet, ok := gs.PlanetForSave(b.PlanetNumber); ok && planet.Meta().Dirty {
 				// Use source block structure with entity values
 				encoded, err := writer.encoder.EncodePlanetBlockFromSource(&b, planet)
@@ -1036,9 +1035,19 @@ func (gs *GameStore) regenerateHSTWithChanges(source *FileSource) ([]byte, error
 					decrypted = encoded
 				}
 			}
+			queue = gs.newQueue(source.Blocks, i, b.PlanetNumber)
 		case blocks.ProductionQueueBlock:
-			// Production queues need special handling - we need to know the planet number
-			// For now, use original data
+			// The queue follows the block of its planet
+			if lastPlanetNumber >= 0 {
+				if pq, ok := gs.ProductionQueue(lastPlanetNumber); ok && pq.Meta().Dirty {
+					if len(pq.Items) == 0 {
+						continue
+					}
+					if encoded, err := writer.encoder.EncodeProductionQueueBlock(pq); err == nil {
+						decrypted = encoded
+					}
+				}
+			}
 
 		case blocks.PlayerBlock:
 			// Handle dirty player entities (e.g., changed to AI or human)
@@ -1048,6 +1057,8 @@ func (gs *GameStore) regenerateHSTWithChanges(source *FileSource) ([]byte, error
 					decrypted = encoded
 				}
 			}
+		default:
+			skipWaypoints = skipWaypoints && typeID == blocks.FleetNameBlockType
 		}
 
 		// Use original data if not replaced
@@ -1057,6 +1068,16 @@ func (gs *GameStore) regenerateHSTWithChanges(source *FileSource) ([]byte, error
 
 		result = append(result, writer.WriteEncryptedBlock(typeID, decrypted)...)
 
+		for _, wp := range waypoints {
+			wpType, wpData := wp.encodeBlock()
+			result = append(result, writer.WriteEncryptedBlock(wpType, wpData)...)
+		}
+		if queue != nil {
+			if encoded, err := writer.encoder.EncodeProductionQueueBlock(queue); err == nil {
+				result = append(result, writer.WriteEncryptedBlock(blocks.ProductionQueueBlockType, encoded)...)
+			}
+		}
+
 		// Handle PlanetsBlock trailing data
 		if pb, ok := block.(blocks.PlanetsBlock); ok {
 			if pb.Valid && len(pb.RawPlanetsData) > 0 {
@@ -1065,8 +1086,6 @@ func (gs *GameStore) regenerateHSTWithChanges(source *FileSource) ([]byte, error
 		}
 	}
 
-	_ = replacedFleets // silence unused warning
-
 	// Write footer with turn/year number as footer data
 	footerData := hstFileFooterData(header)
 	result = append(result, writer.WriteFooter(true, footerData)...)
@@ -1074,6 +1093,37 @@ func (gs *GameStore) regenerateHSTWithChanges(source *FileSource) ([]byte, error
 	return result, nil
 }
 
+// encodeDirtyFleet re-encodes the fleet of a source block when it is dirty.
+// It returns nil data for fleets to copy from the source, and the waypoints
+// to write after a full fleet when they changed.
+func (gs *GameStore) encodeDirtyFleet(writer *FileWriter, fb *blocks.PartialFleetBlock) ([]byte, []*WaypointEntity) {
+	fleet, ok := gs.Fleets.Get(EntityKey{Type: EntityTypeFleet, Owner: fb.Owner, Number: fb.FleetNumber})
+	if !ok || !fleet.Meta().Dirty {
+		return nil, nil
+	}
+	encoded, err := writer.encoder.EncodeFleetBlock(fleet)
+	if err != nil {
+		return nil, nil
+	}
+	if fleet.waypointsChanged && fb.KindByte == blocks.FleetKindFull {
+		return encoded, fleet.Waypoints
+	}
+	return encoded, nil
+}
+
+// newQueue returns the production queue to write after the planet block at
+// index i when the planet had none in the source and was given one.
+func (gs *GameStore) newQueue(sourceBlocks []blocks.Block, i, planetNumber int) *ProductionQueueEntity {
+	if i+1 < len(sourceBlocks) && sourceBlocks[i+1].BlockTypeID() == blocks.ProductionQueueBlockType {
+		return nil
+	}
+	pq, ok := gs.ProductionQueue(planetNumber)
+	if !ok || !pq.Meta().Dirty || len(pq.Items) == 0 {
+		return nil
+	}
+	return pq
+}
+
 // hstFileFooterData returns the footer data for an HST file.
 // HST file footer data = Turn/Year number (from header).
 func hstFileFooterData(header *blocks.FileHeader) uint16 {
@@ -3,6 +3,8 @@ package visibility
 
 import (
 	"math"
+
+	"github.com/neper-stars/houston/geom"
 )
 
 // CloakPerKTToPercent converts cloak units per kiloton to a cloaking percentage.
@@ -61,7 +63,5 @@ func EffectiveScannerRange(scannerRange int, cloakPercent float64) float64 {
 
 // Distance calculates the Euclidean distance between two points.
 func Distance(x1, y1, x2, y2 int) float64 {
-	dx := float64(x2 - x1)
-	dy := float64(y2 - y1)
-	return math.Sqrt(dx*dx + dy*dy)
+	return geom.Distance(x1, y1, x2, y2)
 }